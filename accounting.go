@@ -0,0 +1,189 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// UsageKey 是用量聚合的维度
+type UsageKey struct {
+	Account string // 由 AccountKey 计算的计费账户
+	Method  string
+	Route   string // 路由模板, 例如 /users/:id; 未匹配到路由时为空
+}
+
+// UsageRecord 是一个聚合周期内某个维度的用量
+type UsageRecord struct {
+	UsageKey
+	Requests      int64
+	RequestBytes  int64
+	ResponseBytes int64
+}
+
+// UsageSink 接收周期性刷出的用量记录, 例如写入数据库或计费系统
+type UsageSink interface {
+	FlushUsage(ctx context.Context, records []UsageRecord) error
+}
+
+// UsageSinkFunc 是 UsageSink 的函数适配器
+type UsageSinkFunc func(ctx context.Context, records []UsageRecord) error
+
+func (f UsageSinkFunc) FlushUsage(ctx context.Context, records []UsageRecord) error {
+	return f(ctx, records)
+}
+
+// UsageAccountingOptions 配置用量计量
+type UsageAccountingOptions struct {
+	// Sink 接收用量记录, 必填
+	Sink UsageSink
+	// AccountKey 计算请求所属的计费账户, 例如 API Key 或租户 ID; 为 nil 时所有请求归入空账户
+	AccountKey func(c *Context) string
+	// FlushInterval 为刷出周期, 默认 1 分钟
+	FlushInterval time.Duration
+	// OnError 在刷出失败时调用, 失败的记录会合并到下一个周期重试
+	OnError func(err error)
+}
+
+// UsageAccountant 按路由与账户聚合请求数、请求体与响应体字节数, 并定期刷出到 UsageSink
+type UsageAccountant struct {
+	opts UsageAccountingOptions
+
+	mu      sync.Mutex
+	records map[UsageKey]*UsageRecord
+
+	flushMu   sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewUsageAccountant 创建用量计量器并启动周期刷出, 不再使用时应调用 Close
+func NewUsageAccountant(opts UsageAccountingOptions) *UsageAccountant {
+	if opts.Sink == nil {
+		panic("touka: usage sink must not be nil")
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Minute
+	}
+	a := &UsageAccountant{
+		opts:    opts,
+		records: make(map[UsageKey]*UsageRecord),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go a.loop()
+	return a
+}
+
+// Middleware 返回记录用量的中间件
+func (a *UsageAccountant) Middleware() HandlerFunc {
+	return func(c *Context) {
+		var body *countingReadCloser
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body = &countingReadCloser{ReadCloser: c.Request.Body}
+			c.Request.Body = body
+		}
+
+		c.Next()
+
+		var reqBytes int64
+		if body != nil {
+			reqBytes = body.n
+		}
+		// 处理器未读取完请求体时, 以声明的长度计费
+		if c.Request.ContentLength > reqBytes {
+			reqBytes = c.Request.ContentLength
+		}
+		respBytes := int64(max(c.Writer.Size(), 0))
+
+		key := UsageKey{Method: c.Request.Method, Route: c.FullPath()}
+		if a.opts.AccountKey != nil {
+			key.Account = a.opts.AccountKey(c)
+		}
+		a.add(key, 1, reqBytes, respBytes)
+	}
+}
+
+func (a *UsageAccountant) add(key UsageKey, requests, reqBytes, respBytes int64) {
+	a.mu.Lock()
+	rec, ok := a.records[key]
+	if !ok {
+		rec = &UsageRecord{UsageKey: key}
+		a.records[key] = rec
+	}
+	rec.Requests += requests
+	rec.RequestBytes += reqBytes
+	rec.ResponseBytes += respBytes
+	a.mu.Unlock()
+}
+
+// Flush 立即刷出当前聚合的用量. 刷出失败时记录会保留到下一次刷出
+func (a *UsageAccountant) Flush(ctx context.Context) error {
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+
+	a.mu.Lock()
+	if len(a.records) == 0 {
+		a.mu.Unlock()
+		return nil
+	}
+	pending := a.records
+	a.records = make(map[UsageKey]*UsageRecord, len(pending))
+	a.mu.Unlock()
+
+	records := make([]UsageRecord, 0, len(pending))
+	for _, rec := range pending {
+		records = append(records, *rec)
+	}
+	if err := a.opts.Sink.FlushUsage(ctx, records); err != nil {
+		for _, rec := range records {
+			a.add(rec.UsageKey, rec.Requests, rec.RequestBytes, rec.ResponseBytes)
+		}
+		return err
+	}
+	return nil
+}
+
+// Close 停止周期刷出并刷出剩余的用量
+func (a *UsageAccountant) Close(ctx context.Context) error {
+	a.closeOnce.Do(func() {
+		close(a.stop)
+	})
+	<-a.done
+	return a.Flush(ctx)
+}
+
+func (a *UsageAccountant) loop() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.Flush(context.Background()); err != nil && a.opts.OnError != nil {
+				a.opts.OnError(err)
+			}
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// countingReadCloser 统计读取的字节数
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
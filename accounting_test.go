@@ -0,0 +1,66 @@
+package touka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUsageAccountantAggregatesByRouteAndAccount(t *testing.T) {
+	var mu sync.Mutex
+	var flushed []UsageRecord
+	fail := true
+	accountant := NewUsageAccountant(UsageAccountingOptions{
+		Sink: UsageSinkFunc(func(_ context.Context, records []UsageRecord) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if fail {
+				fail = false
+				return errors.New("sink unavailable")
+			}
+			flushed = append(flushed, records...)
+			return nil
+		}),
+		AccountKey:    func(c *Context) string { return c.GetReqHeader("X-Api-Key") },
+		FlushInterval: time.Hour,
+	})
+
+	engine := New()
+	engine.Use(accountant.Middleware())
+	engine.POST("/items/:id", func(c *Context) {
+		io.Copy(io.Discard, c.Request.Body)
+		c.String(http.StatusOK, "ok")
+	})
+
+	headers := http.Header{"X-Api-Key": []string{"acct-1"}}
+	PerformRequest(engine, http.MethodPost, "/items/1", strings.NewReader("12345"), headers)
+	PerformRequest(engine, http.MethodPost, "/items/2", strings.NewReader("123"), headers)
+
+	if err := accountant.Flush(context.Background()); err == nil {
+		t.Fatal("expected first flush to fail")
+	}
+	if err := accountant.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(flushed) != 1 {
+		t.Fatalf("expected a single aggregated record, got %+v", flushed)
+	}
+	rec := flushed[0]
+	want := UsageRecord{
+		UsageKey:      UsageKey{Account: "acct-1", Method: http.MethodPost, Route: "/items/:id"},
+		Requests:      2,
+		RequestBytes:  8,
+		ResponseBytes: 4,
+	}
+	if rec != want {
+		t.Fatalf("expected %+v, got %+v", want, rec)
+	}
+}
@@ -0,0 +1,121 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// acmeChallengePrefix 是 ACME HTTP-01 验证请求的路径前缀 (RFC 8555 8.3)
+const acmeChallengePrefix = "/.well-known/acme-challenge/"
+
+// ErrACMETokenNotFound 表示没有找到 ACME 验证令牌
+var ErrACMETokenNotFound = errors.New("acme: challenge token not found")
+
+// ACMETokenStore 根据令牌返回 key authorization, 适用于证书管理器将令牌写入共享存储的场景.
+// 令牌不存在时返回 ErrACMETokenNotFound
+type ACMETokenStore interface {
+	KeyAuthorization(ctx context.Context, token string) (string, error)
+}
+
+// ACMETokenStoreFunc 是 ACMETokenStore 的函数适配器
+type ACMETokenStoreFunc func(ctx context.Context, token string) (string, error)
+
+func (f ACMETokenStoreFunc) KeyAuthorization(ctx context.Context, token string) (string, error) {
+	return f(ctx, token)
+}
+
+// ACMEChallengeOptions 配置 ACME HTTP-01 验证的来源, Dir、Store 与 Upstream 必须且只能设置一个
+type ACMEChallengeOptions struct {
+	// Dir 为存放令牌文件的目录, 例如 certbot webroot 模式下的 <webroot>/.well-known/acme-challenge
+	Dir string
+	// Store 从外部存储读取令牌
+	Store ACMETokenStore
+	// Upstream 将验证请求转发给外部证书管理器, 例如 http://127.0.0.1:8402
+	Upstream string
+}
+
+// ACMEChallenge 注册 /.well-known/acme-challenge/:token, 使外部证书管理器在 touka 占用 80 端口时也能完成 HTTP-01 验证.
+// 使用 WithHTTPRedirect 时, 重定向服务器也会将验证请求交给该处理器而不是重定向到 HTTPS
+func (engine *Engine) ACMEChallenge(opts ACMEChallengeOptions) {
+	sources := 0
+	for _, set := range []bool{opts.Dir != "", opts.Store != nil, opts.Upstream != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		panic("touka: ACMEChallenge requires exactly one of Dir, Store or Upstream")
+	}
+
+	var handler HandlerFunc
+	switch {
+	case opts.Upstream != "":
+		target, err := url.Parse(opts.Upstream)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			panic("touka: invalid ACME challenge upstream " + opts.Upstream)
+		}
+		handler = ReverseProxy(ReverseProxyConfig{Target: target, PreserveHost: true})
+	case opts.Dir != "":
+		handler = acmeChallengeHandler(ACMETokenStoreFunc(func(_ context.Context, token string) (string, error) {
+			data, err := os.ReadFile(filepath.Join(opts.Dir, token))
+			if errors.Is(err, os.ErrNotExist) {
+				return "", ErrACMETokenNotFound
+			}
+			return strings.TrimSpace(string(data)), err
+		}))
+	default:
+		handler = acmeChallengeHandler(opts.Store)
+	}
+
+	engine.acmeChallenge = true
+	engine.WellKnown("acme-challenge/:token", func(c *Context) {
+		if !validACMEToken(c.Param("token")) {
+			c.ErrorUseHandle(http.StatusNotFound, ErrACMETokenNotFound)
+			return
+		}
+		handler(c)
+	})
+}
+
+func acmeChallengeHandler(store ACMETokenStore) HandlerFunc {
+	return func(c *Context) {
+		keyAuth, err := store.KeyAuthorization(c.Context(), c.Param("token"))
+		if errors.Is(err, ErrACMETokenNotFound) {
+			c.ErrorUseHandle(http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+		c.SetHeader("Content-Type", "application/octet-stream")
+		c.SetHeader("Cache-Control", "no-store")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.Write([]byte(keyAuth))
+	}
+}
+
+// validACMEToken 报告 token 是否为 base64url 字符组成, 拒绝路径穿越等非法令牌
+func validACMEToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	for i := 0; i < len(token); i++ {
+		b := token[i]
+		if (b >= '0' && b <= '9') || (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || b == '-' || b == '_' {
+			continue
+		}
+		return false
+	}
+	return true
+}
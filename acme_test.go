@@ -0,0 +1,78 @@
+package touka
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestACMEChallengeFromDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tok_EN-1"), []byte("tok_EN-1.thumbprint\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	engine := New()
+	engine.ACMEChallenge(ACMEChallengeOptions{Dir: dir})
+
+	w := PerformRequest(engine, http.MethodGet, "/.well-known/acme-challenge/tok_EN-1", nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "tok_EN-1.thumbprint" {
+		t.Fatalf("unexpected challenge response %d %q", w.Code, w.Body.String())
+	}
+	for _, path := range []string{"/.well-known/acme-challenge/missing", "/.well-known/acme-challenge/..%2Fsecret"} {
+		if w := PerformRequest(engine, http.MethodGet, path, nil, nil); w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, w.Code)
+		}
+	}
+
+	// 重定向服务器放行验证请求, 其他请求仍然重定向
+	server, err := buildRedirectServer(engine, runConfig{addr: ":443", httpRedirectAddr: ":80"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	server.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/.well-known/acme-challenge/tok_EN-1", nil))
+	if rr.Code != http.StatusOK || rr.Body.String() != "tok_EN-1.thumbprint" {
+		t.Fatalf("redirect server should serve the challenge, got %d %q", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	server.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/other", nil))
+	if rr.Code != http.StatusMovedPermanently {
+		t.Fatalf("other paths should still redirect, got %d", rr.Code)
+	}
+}
+
+func TestACMEChallengeFromStoreAndUpstream(t *testing.T) {
+	engine := New()
+	engine.ACMEChallenge(ACMEChallengeOptions{Store: ACMETokenStoreFunc(func(_ context.Context, token string) (string, error) {
+		if token == "abc" {
+			return "abc.key", nil
+		}
+		return "", ErrACMETokenNotFound
+	})})
+	if w := PerformRequest(engine, http.MethodGet, "/.well-known/acme-challenge/abc", nil, nil); w.Body.String() != "abc.key" {
+		t.Fatalf("unexpected store response %q", w.Body.String())
+	}
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied " + r.URL.Path))
+	}))
+	defer upstream.Close()
+	engine = New()
+	engine.ACMEChallenge(ACMEChallengeOptions{Upstream: upstream.URL})
+	w := PerformRequest(engine, http.MethodGet, "/.well-known/acme-challenge/xyz", nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "proxied /.well-known/acme-challenge/xyz" {
+		t.Fatalf("unexpected upstream response %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestACMEChallengeRequiresSingleSource(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic when multiple sources are configured")
+		}
+	}()
+	New().ACMEChallenge(ACMEChallengeOptions{Dir: "/tmp", Upstream: "http://127.0.0.1:1"})
+}
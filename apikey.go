@@ -0,0 +1,160 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAPIKeyMissing 表示请求没有携带 API Key
+	ErrAPIKeyMissing = errors.New("api key missing")
+	// ErrAPIKeyInvalid 表示 API Key 不匹配任何已配置的密钥, 或已过期
+	ErrAPIKeyInvalid = errors.New("api key invalid")
+)
+
+// apiKeyNameKey 是匹配到的密钥名称在 Context.Keys 中的键
+const apiKeyNameKey = "touka.apikey"
+
+// APIKey 是一个具名的静态密钥
+type APIKey struct {
+	// Name 标识密钥的持有方, 用于审计与限流, 必填且不能重复
+	Name string
+	// Key 为密钥原文, 必填
+	Key string
+	// Expires 非零时, 到期后密钥失效; 轮换时可以让新旧密钥并存一段时间
+	Expires time.Time
+	// RateLimit 为每个 RateWindow 允许的请求数, 0 表示不限制. 通过引擎的 Limiter 计数
+	RateLimit int
+	// RateWindow 为限流窗口, 默认 1 分钟
+	RateWindow time.Duration
+}
+
+// APIKeyAuthOptions 配置 APIKeyAuth
+type APIKeyAuthOptions struct {
+	// Keys 为允许的密钥, 至少一个
+	Keys []APIKey
+	// Header 为携带密钥的请求头. 默认依次读取 Authorization: Bearer 与 X-API-Key
+	Header string
+	// ScopeLogger 为 true 时, 当前请求的日志会附加 [apikey=<name>] 前缀
+	ScopeLogger bool
+	// Audit 在每次认证成功后调用, 用于记录调用方; 也可以在后续处理器中通过 c.APIKeyName() 获取
+	Audit func(c *Context, name string)
+}
+
+type apiKeyEntry struct {
+	APIKey
+	sum [sha256.Size]byte
+}
+
+// APIKeyAuth 返回以静态密钥认证机器间调用的中间件, 适用于不值得引入完整 JWT 体系的内部接口:
+//
+//	internal := r.Group("/internal", touka.APIKeyAuth(touka.APIKeyAuthOptions{
+//	    Keys: []touka.APIKey{
+//	        {Name: "billing", Key: os.Getenv("BILLING_KEY"), RateLimit: 600},
+//	        {Name: "billing-old", Key: os.Getenv("BILLING_KEY_OLD"), Expires: rotationDeadline},
+//	    },
+//	}))
+//
+// 密钥以常量时间比较, 且总是与全部密钥比较, 响应时间不会暴露匹配到哪一个.
+// 缺失或无效时返回 401, 超出该密钥的配额时返回 429. 密钥配置无效时 panic
+func APIKeyAuth(opts APIKeyAuthOptions) HandlerFunc {
+	if len(opts.Keys) == 0 {
+		panic("touka: api key auth requires at least one key")
+	}
+	entries := make([]apiKeyEntry, len(opts.Keys))
+	names := make(map[string]bool, len(opts.Keys))
+	for i, k := range opts.Keys {
+		if k.Name == "" || k.Key == "" {
+			panic("touka: api key requires Name and Key")
+		}
+		if names[k.Name] {
+			panic("touka: duplicate api key name " + k.Name)
+		}
+		names[k.Name] = true
+		if k.RateWindow <= 0 {
+			k.RateWindow = time.Minute
+		}
+		entries[i] = apiKeyEntry{APIKey: k, sum: sha256.Sum256([]byte(k.Key))}
+	}
+
+	return func(c *Context) {
+		presented := presentedAPIKey(c, opts.Header)
+		if presented == "" {
+			apiKeyUnauthorized(c, ErrAPIKeyMissing)
+			return
+		}
+		key := matchAPIKey(entries, presented)
+		if key == nil {
+			apiKeyUnauthorized(c, ErrAPIKeyInvalid)
+			return
+		}
+
+		if key.RateLimit > 0 {
+			result, err := c.Limit("apikey:"+key.Name, key.RateLimit, key.RateWindow)
+			if err != nil {
+				// 与 RateLimit 的默认行为一致, 限流器出错时放行
+				c.AddError(fmt.Errorf("rate limiter: %w", err))
+			} else if !applyLimitResult(c, result) {
+				return
+			}
+		}
+
+		c.Set(apiKeyNameKey, key.Name)
+		if opts.ScopeLogger {
+			c.SetLogger(&prefixLogger{Logger: c.GetLogger(), prefix: "[apikey=" + strings.ReplaceAll(key.Name, "%", "%%") + "] "})
+		}
+		if opts.Audit != nil {
+			opts.Audit(c, key.Name)
+		}
+		c.Next()
+	}
+}
+
+// APIKeyName 返回 APIKeyAuth 匹配到的密钥名称, 未经认证时返回空字符串
+func (c *Context) APIKeyName() string {
+	if v, ok := c.Get(apiKeyNameKey); ok {
+		name, _ := v.(string)
+		return name
+	}
+	return ""
+}
+
+// presentedAPIKey 取出请求携带的密钥
+func presentedAPIKey(c *Context, header string) string {
+	if header != "" {
+		return strings.TrimSpace(c.Request.Header.Get(header))
+	}
+	if token := bearerToken(c.Request.Header.Get("Authorization")); token != "" {
+		return token
+	}
+	return strings.TrimSpace(c.Request.Header.Get("X-API-Key"))
+}
+
+// matchAPIKey 以常量时间比较摘要, 遍历全部密钥后返回匹配且未过期的一个
+func matchAPIKey(entries []apiKeyEntry, presented string) *apiKeyEntry {
+	sum := sha256.Sum256([]byte(presented))
+	now := time.Now()
+	var matched *apiKeyEntry
+	for i := range entries {
+		e := &entries[i]
+		if subtle.ConstantTimeCompare(sum[:], e.sum[:]) == 1 && (e.Expires.IsZero() || now.Before(e.Expires)) {
+			matched = e
+		}
+	}
+	return matched
+}
+
+func apiKeyUnauthorized(c *Context, err error) {
+	c.SetHeader("WWW-Authenticate", `Bearer realm="api"`)
+	c.AddClientError(err)
+	c.ErrorUseHandle(http.StatusUnauthorized, err)
+}
@@ -0,0 +1,84 @@
+package touka
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	var audited []string
+	engine := New()
+	engine.Use(APIKeyAuth(APIKeyAuthOptions{
+		Keys: []APIKey{
+			{Name: "billing", Key: "k-billing"},
+			{Name: "retired", Key: "k-retired", Expires: time.Now().Add(-time.Hour)},
+		},
+		Audit: func(c *Context, name string) { audited = append(audited, name) },
+	}))
+	engine.GET("/internal", func(c *Context) { c.String(http.StatusOK, "%s", c.APIKeyName()) })
+
+	cases := []struct {
+		headers http.Header
+		want    int
+	}{
+		{http.Header{"Authorization": {"Bearer k-billing"}}, http.StatusOK},
+		{http.Header{"X-Api-Key": {"k-billing"}}, http.StatusOK},
+		{nil, http.StatusUnauthorized},
+		{http.Header{"X-Api-Key": {"k-guess"}}, http.StatusUnauthorized},
+		{http.Header{"X-Api-Key": {"k-retired"}}, http.StatusUnauthorized},
+	}
+	for i, tc := range cases {
+		w := PerformRequest(engine, http.MethodGet, "/internal", nil, tc.headers)
+		if w.Code != tc.want {
+			t.Errorf("case %d: expected %d, got %d", i, tc.want, w.Code)
+		}
+		if tc.want == http.StatusOK && w.Body.String() != "billing" {
+			t.Errorf("case %d: expected the key name, got %q", i, w.Body.String())
+		}
+		if tc.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("case %d: expected WWW-Authenticate", i)
+		}
+	}
+	if len(audited) != 2 || audited[0] != "billing" {
+		t.Fatalf("unexpected audit log %v", audited)
+	}
+}
+
+func TestAPIKeyAuthRateLimit(t *testing.T) {
+	engine := New()
+	engine.Use(APIKeyAuth(APIKeyAuthOptions{
+		Header: "X-Internal-Token",
+		Keys: []APIKey{
+			{Name: "cron", Key: "k-cron", RateLimit: 2},
+			{Name: "ops", Key: "k-ops"},
+		},
+	}))
+	engine.GET("/internal", func(c *Context) { c.Status(http.StatusNoContent) })
+
+	cron := http.Header{"X-Internal-Token": {"k-cron"}}
+	for i := range 2 {
+		if w := PerformRequest(engine, http.MethodGet, "/internal", nil, cron); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: unexpected status %d", i, w.Code)
+		}
+	}
+	w := PerformRequest(engine, http.MethodGet, "/internal", nil, cron)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected the key quota to be enforced, got %d", w.Code)
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/internal", nil, http.Header{"X-Internal-Token": {"k-ops"}}); w.Code != http.StatusNoContent {
+		t.Fatalf("other keys must not share the quota, got %d", w.Code)
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/internal", nil, http.Header{"Authorization": {"Bearer k-ops"}}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("a custom header must replace the defaults, got %d", w.Code)
+	}
+}
+
+func TestAPIKeyAuthInvalidConfig(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected duplicate names to panic")
+		}
+	}()
+	APIKeyAuth(APIKeyAuthOptions{Keys: []APIKey{{Name: "a", Key: "1"}, {Name: "a", Key: "2"}}})
+}
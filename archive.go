@@ -0,0 +1,218 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// ArchiveEntry 是流式归档中的一个条目. 内容由 Open 或 Write 提供, 二者都为空或 Name 以 / 结尾时为目录
+type ArchiveEntry struct {
+	// Name 为归档内的路径, 使用 / 分隔, 不能以 / 开头或包含 ..
+	Name    string
+	ModTime time.Time // 为零时使用当前时间
+	Mode    fs.FileMode
+	// Size 为内容长度, 未知时为 -1. tar 条目需要预先知道长度, 未知长度的内容会先缓冲到内存
+	Size int64
+	// Open 打开条目的内容, 适用于已有文件
+	Open func() (io.ReadCloser, error)
+	// Write 将条目的内容写入 w, 适用于动态生成的内容
+	Write func(w io.Writer) error
+}
+
+func (e *ArchiveEntry) isDir() bool {
+	return strings.HasSuffix(e.Name, "/") || (e.Open == nil && e.Write == nil)
+}
+
+// writeTo 将条目的内容写入 w
+func (e *ArchiveEntry) writeTo(w io.Writer) error {
+	if e.Write != nil {
+		return e.Write(w)
+	}
+	rc, err := e.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = io.Copy(w, rc)
+	return err
+}
+
+// validArchiveName 拒绝绝对路径与跳出归档根目录的路径, 防止解压时覆盖任意文件 (zip slip)
+func validArchiveName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, `\`) {
+		return false
+	}
+	for part := range strings.SplitSeq(strings.TrimSuffix(name, "/"), "/") {
+		if part == ".." || part == "." || part == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ZipStream 以 zip 格式流式输出归档, 不会在磁盘或内存中生成完整的归档文件, 适用于 "全部下载" 接口.
+// produce 通过 add 逐个加入条目, 语义与 NDJSONStream 相同: 第一个条目写出前返回的错误交给 ErrorHandler,
+// 之后的错误只记录到 c.Errors, 客户端收到不完整的归档. filename 非空时设置 Content-Disposition:
+//
+//	c.ZipStream("photos.zip", touka.FSArchiveEntries(os.DirFS(dir), "."))
+func (c *Context) ZipStream(filename string, produce func(add func(ArchiveEntry) error) error) error {
+	c.SetHeader("Content-Type", "application/zip")
+	if filename != "" {
+		c.SetHeader("Content-Disposition", attachmentDisposition(filename))
+	}
+	zw := zip.NewWriter(c.Writer)
+	s := &rowStream{c: c, flush: zw.Flush}
+	err := produce(func(e ArchiveEntry) error {
+		if !validArchiveName(e.Name) {
+			return fmt.Errorf("archive: invalid entry name %q", e.Name)
+		}
+		if err := s.begin(); err != nil {
+			return err
+		}
+		header := &zip.FileHeader{Name: e.Name, Method: zip.Deflate, Modified: e.ModTime}
+		if header.Modified.IsZero() {
+			header.Modified = time.Now()
+		}
+		if e.isDir() {
+			header.Name = strings.TrimSuffix(e.Name, "/") + "/"
+			header.Method = zip.Store
+			header.SetMode(fs.ModeDir | 0o755)
+		} else if e.Mode != 0 {
+			header.SetMode(e.Mode)
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if !e.isDir() {
+			if err := e.writeTo(w); err != nil {
+				return err
+			}
+		}
+		return s.wrote()
+	})
+	// 写出结尾 (zip 的中央目录 / tar 的结束块), 没有任何条目时输出空归档
+	if err == nil {
+		if err = s.begin(); err == nil {
+			err = zw.Close()
+		}
+	}
+	return s.finish(err)
+}
+
+// TarStream 以 tar 格式流式输出归档, 用法与 ZipStream 相同.
+// tar 头部需要内容长度, Size 未知 (-1) 的条目会先缓冲到内存, 大文件应提供 Size
+func (c *Context) TarStream(filename string, produce func(add func(ArchiveEntry) error) error) error {
+	c.SetHeader("Content-Type", "application/x-tar")
+	if filename != "" {
+		c.SetHeader("Content-Disposition", attachmentDisposition(filename))
+	}
+	tw := tar.NewWriter(c.Writer)
+	s := &rowStream{c: c, flush: tw.Flush}
+	err := produce(func(e ArchiveEntry) error {
+		if !validArchiveName(e.Name) {
+			return fmt.Errorf("archive: invalid entry name %q", e.Name)
+		}
+		if err := s.begin(); err != nil {
+			return err
+		}
+		header := &tar.Header{Name: e.Name, ModTime: e.ModTime, Mode: int64(e.Mode.Perm()), Format: tar.FormatPAX}
+		if header.ModTime.IsZero() {
+			header.ModTime = time.Now()
+		}
+		if e.isDir() {
+			header.Typeflag = tar.TypeDir
+			header.Name = strings.TrimSuffix(e.Name, "/") + "/"
+			if header.Mode == 0 {
+				header.Mode = 0o755
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			return s.wrote()
+		}
+
+		header.Typeflag = tar.TypeReg
+		if header.Mode == 0 {
+			header.Mode = 0o644
+		}
+		var buffered *bytes.Buffer
+		if e.Size < 0 {
+			buffered = new(bytes.Buffer)
+			if err := e.writeTo(buffered); err != nil {
+				return err
+			}
+			header.Size = int64(buffered.Len())
+		} else {
+			header.Size = e.Size
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if buffered != nil {
+			if _, err := tw.Write(buffered.Bytes()); err != nil {
+				return err
+			}
+		} else if err := e.writeTo(tw); err != nil {
+			if errors.Is(err, tar.ErrWriteTooLong) {
+				return fmt.Errorf("archive: entry %q is larger than its Size: %w", e.Name, err)
+			}
+			return err
+		}
+		return s.wrote()
+	})
+	// 写出结尾 (zip 的中央目录 / tar 的结束块), 没有任何条目时输出空归档
+	if err == nil {
+		if err = s.begin(); err == nil {
+			err = tw.Close()
+		}
+	}
+	return s.finish(err)
+}
+
+// FSArchiveEntries 返回遍历 fsys 中 root 目录的条目生产者, 用于 ZipStream 与 TarStream.
+// 条目名相对于 root, 只包含目录与普通文件, 符号链接等特殊文件会被跳过
+func FSArchiveEntries(fsys fs.FS, root string) func(add func(ArchiveEntry) error) error {
+	return func(add func(ArchiveEntry) error) error {
+		return fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			name := strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+			if root == "." {
+				name = p
+			}
+			if name == "" || name == "." {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return add(ArchiveEntry{Name: name + "/", ModTime: info.ModTime(), Mode: info.Mode()})
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			return add(ArchiveEntry{
+				Name:    name,
+				ModTime: info.ModTime(),
+				Mode:    info.Mode(),
+				Size:    info.Size(),
+				Open:    func() (io.ReadCloser, error) { return fsys.Open(p) },
+			})
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package touka
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"testing/fstest"
+)
+
+func TestZipStream(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/a.txt":     {Data: []byte("hello")},
+		"docs/sub/b.txt": {Data: []byte("world")},
+	}
+	engine := New()
+	engine.GET("/all.zip", func(c *Context) {
+		c.ZipStream("all.zip", func(add func(ArchiveEntry) error) error {
+			if err := FSArchiveEntries(fsys, "docs")(add); err != nil {
+				return err
+			}
+			return add(ArchiveEntry{Name: "report.txt", Size: -1, Write: func(w io.Writer) error {
+				_, err := io.WriteString(w, "generated")
+				return err
+			}})
+		})
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/all.zip", nil, nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	got := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		got[f.Name] = string(data)
+	}
+	want := map[string]string{"a.txt": "hello", "sub/": "", "sub/b.txt": "world", "report.txt": "generated"}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("entry %q = %q, want %q", name, got[name], content)
+		}
+	}
+}
+
+func TestTarStream(t *testing.T) {
+	engine := New()
+	engine.GET("/all.tar", func(c *Context) {
+		c.TarStream("", func(add func(ArchiveEntry) error) error {
+			if err := add(ArchiveEntry{Name: "known.txt", Size: 3, Write: func(w io.Writer) error {
+				_, err := io.WriteString(w, "abc")
+				return err
+			}}); err != nil {
+				return err
+			}
+			return add(ArchiveEntry{Name: "unknown.txt", Size: -1, Write: func(w io.Writer) error {
+				_, err := io.WriteString(w, "defgh")
+				return err
+			}})
+		})
+	})
+	engine.GET("/bad.tar", func(c *Context) {
+		c.TarStream("", func(add func(ArchiveEntry) error) error {
+			return add(ArchiveEntry{Name: "../etc/passwd", Size: 0, Write: func(io.Writer) error { return nil }})
+		})
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/all.tar", nil, nil)
+	tr := tar.NewReader(bytes.NewReader(w.Body.Bytes()))
+	var names []string
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid tar: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		names = append(names, h.Name+"="+string(data))
+	}
+	if len(names) != 2 || names[0] != "known.txt=abc" || names[1] != "unknown.txt=defgh" {
+		t.Fatalf("unexpected entries %v", names)
+	}
+
+	if w := PerformRequest(engine, http.MethodGet, "/bad.tar", nil, nil); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected traversal entry to be rejected, got %d", w.Code)
+	}
+}
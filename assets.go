@@ -0,0 +1,210 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// errAssetsNotMounted 表示模板在 MountAssets 之前使用了 asset 或 sriAttr
+var errAssetsNotMounted = errors.New("assets: no assets mounted, call MountAssets first")
+
+// AssetManifest 记录静态资源的指纹与子资源完整性 (SRI) 摘要. 内容在构建时读入内存,
+// 提供的文件与计算摘要时的内容始终一致
+type AssetManifest struct {
+	prefix string                // 资源的 URL 前缀, 例如 /assets/
+	assets map[string]*assetFile // 逻辑名 (例如 js/app.js) -> 资源
+	byURL  map[string]*assetFile // 带指纹的文件名 (例如 js/app.3f2a9c1b.js) -> 资源
+}
+
+type assetFile struct {
+	name        string
+	fingerprint string // 带指纹的文件名
+	integrity   string // sha384-<base64>
+	etag        string
+	data        []byte
+	modTime     time.Time
+}
+
+// NewAssetManifest 读取 fsys 中的全部文件, 计算指纹与 SRI 摘要. prefix 为提供这些资源的 URL 前缀
+func NewAssetManifest(fsys fs.FS, prefix string) (*AssetManifest, error) {
+	m := &AssetManifest{
+		prefix: strings.TrimSuffix(prefix, "/") + "/",
+		assets: make(map[string]*assetFile),
+		byURL:  make(map[string]*assetFile),
+	}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var modTime time.Time
+		if info, err := d.Info(); err == nil {
+			modTime = info.ModTime()
+		}
+		sum := sha256.Sum256(data)
+		sri := sha512.Sum384(data)
+		ext := path.Ext(name)
+		a := &assetFile{
+			name:        name,
+			fingerprint: strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:4]) + ext,
+			integrity:   "sha384-" + base64.StdEncoding.EncodeToString(sri[:]),
+			etag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+			data:        data,
+			modTime:     modTime,
+		}
+		m.assets[name] = a
+		m.byURL[a.fingerprint] = a
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("assets: build manifest: %w", err)
+	}
+	return m, nil
+}
+
+func (m *AssetManifest) lookup(name string) (*assetFile, error) {
+	a, ok := m.assets[strings.TrimPrefix(name, "/")]
+	if !ok {
+		return nil, fmt.Errorf("assets: unknown asset %q", name)
+	}
+	return a, nil
+}
+
+// Path 返回资源带指纹的 URL, 例如 /assets/js/app.3f2a9c1b.js
+func (m *AssetManifest) Path(name string) (string, error) {
+	a, err := m.lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return m.prefix + a.fingerprint, nil
+}
+
+// Integrity 返回资源的 SRI 摘要, 例如 sha384-oqVuAfXRKap7fdgcCY5uykM6+R9GqQ8K/uxy9rx7HNQlGYl1kPzQho1wx4JwY8wC
+func (m *AssetManifest) Integrity(name string) (string, error) {
+	a, err := m.lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return a.integrity, nil
+}
+
+// SRIAttr 返回可直接写入 script 或 link 标签的 integrity 与 crossorigin 属性
+func (m *AssetManifest) SRIAttr(name string) (template.HTMLAttr, error) {
+	a, err := m.lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return template.HTMLAttr(`integrity="` + a.integrity + `" crossorigin="anonymous"`), nil
+}
+
+// MountAssets 在 relativePath 下提供 fsys 中的静态资源, 并为模板启用 asset 与 sriAttr 函数:
+//
+//	r.MountAssets("/assets", assetsFS)
+//
+//	<script src="{{asset "js/app.js"}}" {{sriAttr "js/app.js"}}></script>
+//
+// 带指纹的地址以一年的 immutable 缓存提供, 原始文件名仍可访问但每次需要验证.
+// 资源在注册时读入内存, 每个引擎只能注册一次, 构建失败时 panic
+func (engine *Engine) MountAssets(relativePath string, fsys fs.FS) {
+	mountAssets(engine, engine, relativePath, fsys)
+}
+
+// MountAssets 在路由组下提供静态资源, 参见 Engine.MountAssets
+func (group *RouterGroup) MountAssets(relativePath string, fsys fs.FS) {
+	mountAssets(group.engine, group, relativePath, fsys)
+}
+
+func mountAssets(engine *Engine, router Router, relativePath string, fsys fs.FS) {
+	if engine.assets != nil {
+		panic("touka: assets already mounted")
+	}
+	mount := strings.TrimSuffix(path.Clean("/"+relativePath), "/")
+	base := "/"
+	if group, ok := router.(*RouterGroup); ok {
+		base = group.basePath
+	}
+	prefix := resolveRoutePath(base, mount+"/")
+	m, err := NewAssetManifest(fsys, prefix)
+	if err != nil {
+		panic("touka: " + err.Error())
+	}
+	engine.assets = m
+
+	handler := func(c *Context) { m.serve(c, strings.TrimPrefix(c.Param("filepath"), "/")) }
+	pattern := mount + "/*filepath"
+	router.GET(pattern, handler)
+	router.HEAD(pattern, handler)
+}
+
+// serve 提供资源: 带指纹的地址可以永久缓存, 原始文件名通过 ETag 验证
+func (m *AssetManifest) serve(c *Context, name string) {
+	a, fingerprinted := m.byURL[name]
+	if !fingerprinted {
+		var ok bool
+		if a, ok = m.assets[name]; !ok {
+			c.ErrorUseHandle(http.StatusNotFound, fmt.Errorf("assets: unknown asset %q", name))
+			return
+		}
+	}
+	h := c.Writer.Header()
+	if ct := mime.TypeByExtension(path.Ext(a.name)); ct != "" {
+		h.Set("Content-Type", ct)
+	}
+	if fingerprinted {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		h.Set("Cache-Control", "no-cache")
+	}
+	h.Set("ETag", a.etag)
+	http.ServeContent(c.Writer, c.Request, a.name, a.modTime, bytes.NewReader(a.data))
+}
+
+// Assets 返回通过 MountAssets 注册的资源清单, 未注册时返回 nil
+func (engine *Engine) Assets() *AssetManifest {
+	return engine.assets
+}
+
+// assetFuncMap 返回引用引擎资源清单的模板函数, 在执行时查找, 因此 LoadHTMLGlob 可以先于 MountAssets 调用
+func (engine *Engine) assetFuncMap() template.FuncMap {
+	manifest := func() (*AssetManifest, error) {
+		if engine.assets == nil {
+			return nil, errAssetsNotMounted
+		}
+		return engine.assets, nil
+	}
+	return template.FuncMap{
+		"asset": func(name string) (string, error) {
+			m, err := manifest()
+			if err != nil {
+				return "", err
+			}
+			return m.Path(name)
+		},
+		"sriAttr": func(name string) (template.HTMLAttr, error) {
+			m, err := manifest()
+			if err != nil {
+				return "", err
+			}
+			return m.SRIAttr(name)
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package touka
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMountAssetsSRI(t *testing.T) {
+	js := []byte("console.log('hi')")
+	engine := New()
+	api := engine.Group("/static").(*RouterGroup)
+	api.MountAssets("/assets", fstest.MapFS{
+		"js/app.js": {Data: js},
+	})
+	engine.HTMLRender = template.Must(template.New("page").Funcs(engine.assetFuncMap()).Parse(`<script src="{{asset "js/app.js"}}" {{sriAttr "js/app.js"}}></script>`))
+	engine.GET("/", func(c *Context) { c.HTMLBuf(http.StatusOK, "page", nil) })
+
+	sum := sha512.Sum384(js)
+	integrity := "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+	if got, err := engine.Assets().Integrity("js/app.js"); err != nil || got != integrity {
+		t.Fatalf("Integrity = %q, %v", got, err)
+	}
+	src, err := engine.Assets().Path("js/app.js")
+	if err != nil || !strings.HasPrefix(src, "/static/assets/js/app.") || !strings.HasSuffix(src, ".js") {
+		t.Fatalf("Path = %q, %v", src, err)
+	}
+
+	w := PerformRequest(engine, http.MethodGet, "/", nil, nil)
+	want := `<script src="` + src + `" integrity="` + integrity + `" crossorigin="anonymous"></script>`
+	if w.Body.String() != want {
+		t.Fatalf("body = %q, want %q", w.Body.String(), want)
+	}
+
+	w = PerformRequest(engine, http.MethodGet, src, nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != string(js) {
+		t.Fatalf("fingerprinted asset: %d %q", w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Fatalf("unexpected Cache-Control %q", cc)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, "javascript") {
+		t.Fatalf("unexpected Content-Type %q", ct)
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/static/assets/js/app.js", nil, nil)
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("plain asset: %d %q", w.Code, w.Header().Get("Cache-Control"))
+	}
+	w = PerformRequest(engine, http.MethodGet, "/static/assets/js/missing.js", nil, nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing asset: %d", w.Code)
+	}
+
+	if r := catchPanic(func() { engine.MountAssets("/again", fstest.MapFS{}) }); r == nil {
+		t.Fatal("mounting assets twice should panic")
+	}
+}
+
+func TestAssetFuncsWithoutManifest(t *testing.T) {
+	engine := New()
+	tpl := template.Must(template.New("page").Funcs(engine.assetFuncMap()).Parse(`{{sriAttr "app.js"}}`))
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, nil); err == nil || !strings.Contains(err.Error(), "MountAssets") {
+		t.Fatalf("expected error about missing assets, got %v", err)
+	}
+}
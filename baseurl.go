@@ -0,0 +1,48 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SetCanonicalURL 设置站点的规范地址, 例如 https://example.com 或 https://example.com/app.
+// 设置后 c.BaseURL 与 c.AbsoluteURL 总是使用该地址, 不再依赖请求的 Host 与代理头部; 传入空字符串清除
+func (engine *Engine) SetCanonicalURL(base string) error {
+	if base == "" {
+		engine.canonicalURL = ""
+		return nil
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return fmt.Errorf("invalid canonical URL %q: %w", base, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid canonical URL %q: must be an http(s) URL without query or fragment", base)
+	}
+	engine.canonicalURL = u.Scheme + "://" + u.Host + strings.TrimSuffix(u.EscapedPath(), "/")
+	return nil
+}
+
+// BaseURL 返回站点的基础地址 (不以 / 结尾), 用于在邮件、Location 头部与 sitemap 中生成链接.
+// 设置了 SetCanonicalURL 时返回该地址; 否则由 ForwardedProto 与 ForwardedHost 组成,
+// 因此位于可信代理之后时使用客户端实际访问的协议与主机
+func (c *Context) BaseURL() string {
+	if c.engine != nil && c.engine.canonicalURL != "" {
+		return c.engine.canonicalURL
+	}
+	return c.ForwardedProto() + "://" + c.ForwardedHost()
+}
+
+// AbsoluteURL 将站内路径转换为绝对地址, 例如 c.AbsoluteURL("/orders/42") 得到 https://example.com/orders/42.
+// 路径可以带查询参数与片段; 已经是绝对地址 (带 scheme) 的参数原样返回
+func (c *Context) AbsoluteURL(path string) string {
+	if u, err := url.Parse(path); err == nil && u.Scheme != "" {
+		return path
+	}
+	return c.BaseURL() + "/" + strings.TrimPrefix(path, "/")
+}
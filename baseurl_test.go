@@ -0,0 +1,52 @@
+package touka
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBaseURLAndAbsoluteURL(t *testing.T) {
+	engine := New()
+	if err := engine.SetTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	engine.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "%s %s", c.BaseURL(), c.AbsoluteURL("orders/42?tab=items"))
+	})
+	serve := func() string {
+		req := httptest.NewRequest(http.MethodGet, "http://internal:8080/", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "shop.example")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	if got := serve(); got != "https://shop.example https://shop.example/orders/42?tab=items" {
+		t.Fatalf("behind trusted proxy: %q", got)
+	}
+	if err := engine.SetCanonicalURL("https://www.example.com/app/"); err != nil {
+		t.Fatal(err)
+	}
+	if got := serve(); got != "https://www.example.com/app https://www.example.com/app/orders/42?tab=items" {
+		t.Fatalf("with canonical URL: %q", got)
+	}
+	for _, bad := range []string{"ftp://example.com", "/relative", "https://example.com/?q=1"} {
+		if err := engine.SetCanonicalURL(bad); err == nil {
+			t.Errorf("expected error for canonical URL %q", bad)
+		}
+	}
+}
+
+func TestAbsoluteURLKeepsAbsoluteInput(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	c, _ := CreateTestContextWithRequest(nil, req)
+	if got := c.AbsoluteURL("https://cdn.example/x.js"); got != "https://cdn.example/x.js" {
+		t.Fatalf("absolute input should be returned unchanged, got %q", got)
+	}
+	if got := c.AbsoluteURL("/a"); got != "http://example.com/a" {
+		t.Fatalf("unexpected %q", got)
+	}
+}
@@ -0,0 +1,24 @@
+#!/bin/sh
+# 运行基准测试套件并与基线比较, 回归超过阈值时以非零状态退出.
+# 用法: benchmarks/bench.sh [-update]
+#   -update  将本次结果保存为基线 benchmarks/baseline.txt
+# 本次结果写入 benchmarks/bench_output.txt (已在 .gitignore 中忽略)
+# 环境变量: BENCH_COUNT (默认 6), BENCH_THRESHOLD (默认 10, 单位 %), BENCH_FILTER (默认 .)
+set -eu
+
+cd "$(dirname "$0")/.."
+baseline=benchmarks/baseline.txt
+output=benchmarks/bench_output.txt
+
+go test -run '^$' -bench "${BENCH_FILTER:-.}" -benchmem -count "${BENCH_COUNT:-6}" ./benchmarks | tee "$output"
+
+if [ "${1:-}" = "-update" ]; then
+	cp "$output" "$baseline"
+	echo "baseline saved to $baseline"
+	exit 0
+fi
+if [ ! -f "$baseline" ]; then
+	echo "no baseline at $baseline, run benchmarks/bench.sh -update on the reference revision first" >&2
+	exit 2
+fi
+go run ./benchmarks/cmd/benchcheck -threshold "${BENCH_THRESHOLD:-10}" "$baseline" "$output"
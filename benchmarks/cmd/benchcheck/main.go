@@ -0,0 +1,175 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+
+// Command benchcheck 比较两份 go test -bench -benchmem 的输出, 任一基准的 ns/op 中位数回归超过阈值,
+// 或 allocs/op 中位数增加时以状态 1 退出:
+//
+//	go run ./benchmarks/cmd/benchcheck -threshold 10 baseline.txt new.txt
+//
+// 只在一份输出中出现的基准会列出但不视为回归
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// samples 是同一基准多次运行的结果
+type samples struct {
+	nsPerOp     []float64
+	allocsPerOp []float64
+}
+
+func main() {
+	threshold := flag.Float64("threshold", 10, "允许的 ns/op 回归百分比")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: benchcheck [-threshold percent] baseline.txt new.txt")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	base, err := parseFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "benchcheck:", err)
+		os.Exit(2)
+	}
+	cur, err := parseFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "benchcheck:", err)
+		os.Exit(2)
+	}
+	if n := compare(os.Stdout, base, cur, *threshold); n > 0 {
+		fmt.Fprintf(os.Stderr, "benchcheck: %d benchmark(s) regressed\n", n)
+		os.Exit(1)
+	}
+}
+
+func parseFile(name string) (map[string]*samples, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	results, err := parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%s: no benchmark results", name)
+	}
+	return results, nil
+}
+
+// parse 读取 go test -bench 的输出, 忽略非结果行. 基准名称去掉 GOMAXPROCS 后缀 (例如 -8)
+func parse(r io.Reader) (map[string]*samples, error) {
+	results := make(map[string]*samples)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		name := trimProcs(fields[0])
+		s := results[name]
+		if s == nil {
+			s = &samples{}
+			results[name] = s
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid value %q", name, fields[i])
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				s.nsPerOp = append(s.nsPerOp, v)
+			case "allocs/op":
+				s.allocsPerOp = append(s.allocsPerOp, v)
+			}
+		}
+	}
+	return results, scanner.Err()
+}
+
+func trimProcs(name string) string {
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return name
+	}
+	if _, err := strconv.Atoi(name[i+1:]); err != nil {
+		return name
+	}
+	return name[:i]
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Sorted(slices.Values(values))
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// compare 输出两份结果的对比表格, 返回回归的基准数量
+func compare(w io.Writer, base, cur map[string]*samples, threshold float64) int {
+	names := make([]string, 0, len(base)+len(cur))
+	for name := range base {
+		names = append(names, name)
+	}
+	for name := range cur {
+		if _, ok := base[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "benchmark\told ns/op\tnew ns/op\tdelta\told allocs\tnew allocs\t")
+	regressed := 0
+	for _, name := range names {
+		old, ok1 := base[name]
+		now, ok2 := cur[name]
+		switch {
+		case !ok1:
+			fmt.Fprintf(tw, "%s\t-\t%.1f\tnew\t-\t%.0f\t\n", name, median(now.nsPerOp), median(now.allocsPerOp))
+			continue
+		case !ok2:
+			fmt.Fprintf(tw, "%s\t%.1f\t-\tremoved\t%.0f\t-\t\n", name, median(old.nsPerOp), median(old.allocsPerOp))
+			continue
+		}
+		oldNs, newNs := median(old.nsPerOp), median(now.nsPerOp)
+		oldAllocs, newAllocs := median(old.allocsPerOp), median(now.allocsPerOp)
+		delta := 0.0
+		if oldNs > 0 {
+			delta = (newNs - oldNs) / oldNs * 100
+		}
+		status := ""
+		if delta > threshold || newAllocs > oldAllocs {
+			status = "REGRESSION"
+			regressed++
+		}
+		fmt.Fprintf(tw, "%s\t%.1f\t%.1f\t%+.2f%%\t%.0f\t%.0f\t%s\n", name, oldNs, newNs, delta, oldAllocs, newAllocs, status)
+	}
+	tw.Flush()
+	return regressed
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const baseOutput = `goos: linux
+pkg: github.com/infinite-iroha/touka/benchmarks
+BenchmarkRouting/Static-8         	 1000000	       100.0 ns/op	       0 B/op	       0 allocs/op
+BenchmarkRouting/Static-8         	 1000000	       110.0 ns/op	       0 B/op	       0 allocs/op
+BenchmarkRouting/Static-8         	 1000000	       300.0 ns/op	       0 B/op	       0 allocs/op
+BenchmarkRouting/Param-8          	 1000000	       200.0 ns/op	       0 B/op	       0 allocs/op
+BenchmarkJSONRender/small-8       	  500000	      1000 ns/op	  19.25 MB/s	     176 B/op	       3 allocs/op
+BenchmarkOld-8                    	  500000	      1000 ns/op
+PASS
+`
+
+func TestParse(t *testing.T) {
+	results, err := parse(strings.NewReader(baseOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("parsed %d benchmarks, want 4", len(results))
+	}
+	static := results["BenchmarkRouting/Static"]
+	if static == nil || len(static.nsPerOp) != 3 || median(static.nsPerOp) != 110 {
+		t.Fatalf("BenchmarkRouting/Static = %+v", static)
+	}
+	if got := median(results["BenchmarkJSONRender/small"].allocsPerOp); got != 3 {
+		t.Fatalf("allocs/op = %v, want 3", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base, _ := parse(strings.NewReader(baseOutput))
+	cur, _ := parse(strings.NewReader(`
+BenchmarkRouting/Static-4         	 1000000	       115.0 ns/op	       0 B/op	       0 allocs/op
+BenchmarkRouting/Param-4          	 1000000	       250.0 ns/op	       0 B/op	       0 allocs/op
+BenchmarkJSONRender/small-4       	  500000	       900 ns/op	     200 B/op	       4 allocs/op
+BenchmarkNew-4                    	  500000	      1000 ns/op
+`))
+
+	var out strings.Builder
+	if n := compare(&out, base, cur, 10); n != 2 {
+		t.Fatalf("regressions = %d, want 2 (Param slower, JSONRender allocates more)\n%s", n, out.String())
+	}
+	for _, want := range []string{"BenchmarkNew", "new", "BenchmarkOld", "removed", "+25.00%"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if n := compare(&out, base, cur, 30); n != 1 {
+		t.Fatalf("regressions with 30%% threshold = %d, want 1", n)
+	}
+}
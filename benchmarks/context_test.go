@@ -0,0 +1,39 @@
+package benchmarks
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/infinite-iroha/touka"
+)
+
+// BenchmarkContextPool 测量带中间件链的请求处理, 包括 Context 的获取、重置与归还
+func BenchmarkContextPool(b *testing.B) {
+	engine := newEngine()
+	for range 5 {
+		engine.Use(func(c *touka.Context) { c.Next() })
+	}
+	engine.GET("/users/:id", func(c *touka.Context) {
+		c.Set("user", c.Param("id"))
+		if _, ok := c.Get("user"); !ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	b.Run("Serial", func(b *testing.B) {
+		benchRequest(b, engine, newRequest(b, http.MethodGet, "/users/42"), http.StatusNoContent)
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			req, _ := http.NewRequest(http.MethodGet, "/users/42", nil)
+			w := newDiscardWriter()
+			for pb.Next() {
+				w.reset()
+				engine.ServeHTTP(w, req)
+			}
+		})
+	})
+}
@@ -0,0 +1,16 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+
+// Package benchmarks 是 touka 的基准测试套件, 通过公开 API 覆盖路由匹配 (静态、参数、通配与深层路由)、
+// Context 复用、JSON 渲染与绑定、gzip 响应、SSE 与文件服务, 用于在修改热点路径时发现性能回归.
+//
+// bench.sh 运行套件并与基线比较, 任一基准的 ns/op 中位数回归超过阈值或 allocs/op 增加时失败:
+//
+//	git stash && benchmarks/bench.sh -update   # 在修改前的代码上生成基线
+//	git stash pop && benchmarks/bench.sh       # 与基线比较
+//
+// BENCH_COUNT (默认 6)、BENCH_THRESHOLD (默认 10, 单位 %) 与 BENCH_FILTER (默认 .) 环境变量控制运行次数、
+// 阈值与要运行的基准. 基线与机器相关, 不纳入版本控制
+package benchmarks
@@ -0,0 +1,48 @@
+package benchmarks
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func BenchmarkFileServing(b *testing.B) {
+	dir := b.TempDir()
+	files := map[string]int{"small.txt": 4 << 10, "large.bin": 1 << 20}
+	for name, size := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), bytes.Repeat([]byte("t"), size), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	engine := newEngine()
+	engine.StaticDir("/assets", dir)
+	engine.SetStaticMmap(1 << 20)
+	engine.StaticDir("/mapped", dir)
+	engine.StaticFile("/favicon.txt", filepath.Join(dir, "small.txt"))
+
+	b.Run("StaticFile", func(b *testing.B) {
+		benchRequest(b, engine, newRequest(b, http.MethodGet, "/favicon.txt"), http.StatusOK)
+	})
+	b.Run("StaticDirSmall", func(b *testing.B) {
+		benchRequest(b, engine, newRequest(b, http.MethodGet, "/assets/small.txt"), http.StatusOK)
+	})
+	b.Run("StaticDirLarge", func(b *testing.B) {
+		benchRequest(b, engine, newRequest(b, http.MethodGet, "/assets/large.bin"), http.StatusOK)
+	})
+	b.Run("StaticDirLargeMmap", func(b *testing.B) {
+		benchRequest(b, engine, newRequest(b, http.MethodGet, "/mapped/large.bin"), http.StatusOK)
+	})
+	b.Run("Range", func(b *testing.B) {
+		req := newRequest(b, http.MethodGet, "/assets/large.bin")
+		req.Header.Set("Range", "bytes=0-65535")
+		benchRequest(b, engine, req, http.StatusPartialContent)
+	})
+	b.Run("NotModified", func(b *testing.B) {
+		req := newRequest(b, http.MethodGet, "/assets/small.txt")
+		req.Header.Set("If-Modified-Since", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		benchRequest(b, engine, req, http.StatusNotModified)
+	})
+}
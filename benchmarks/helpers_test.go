@@ -0,0 +1,92 @@
+package benchmarks
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/infinite-iroha/touka"
+)
+
+// discardWriter 丢弃响应体并复用 Header, 使基准只测量框架本身的开销
+type discardWriter struct {
+	header http.Header
+	code   int
+	n      int
+}
+
+func newDiscardWriter() *discardWriter {
+	return &discardWriter{header: make(http.Header)}
+}
+
+func (w *discardWriter) Header() http.Header { return w.header }
+
+func (w *discardWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	w.n += len(p)
+	return len(p), nil
+}
+
+func (w *discardWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *discardWriter) Flush() {}
+
+func (w *discardWriter) reset() {
+	clear(w.header)
+	w.code, w.n = 0, 0
+}
+
+func newEngine() *touka.Engine {
+	engine := touka.New()
+	engine.SetRedirectTrailingSlash(false)
+	return engine
+}
+
+// benchRequest 反复处理同一请求, 先确认响应状态为 wantCode
+func benchRequest(b *testing.B, engine *touka.Engine, req *http.Request, wantCode int) {
+	b.Helper()
+	benchRequestBody(b, engine, req, nil, wantCode)
+}
+
+// benchRequestBody 与 benchRequest 相同, body 不为 nil 时每次处理前重置为请求体
+func benchRequestBody(b *testing.B, engine *touka.Engine, req *http.Request, body []byte, wantCode int) {
+	b.Helper()
+	w := newDiscardWriter()
+	r := bytes.NewReader(body)
+	serve := func() {
+		if body != nil {
+			r.Reset(body)
+			req.Body = io.NopCloser(r)
+			req.ContentLength = int64(len(body))
+		}
+		w.reset()
+		engine.ServeHTTP(w, req)
+	}
+
+	serve()
+	if w.code != wantCode {
+		b.Fatalf("%s %s: status = %d, want %d", req.Method, req.URL.Path, w.code, wantCode)
+	}
+	b.SetBytes(int64(w.n))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		serve()
+	}
+}
+
+func newRequest(b *testing.B, method, target string) *http.Request {
+	b.Helper()
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return req
+}
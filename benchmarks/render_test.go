@@ -0,0 +1,115 @@
+package benchmarks
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/infinite-iroha/touka"
+)
+
+type benchUser struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Email   string   `json:"email"`
+	Active  bool     `json:"active"`
+	Roles   []string `json:"roles"`
+	Balance float64  `json:"balance"`
+}
+
+func benchUsers(n int) []benchUser {
+	users := make([]benchUser, n)
+	for i := range users {
+		users[i] = benchUser{ID: i, Name: "user", Email: "user@example.com", Active: i%2 == 0, Roles: []string{"admin", "dev"}, Balance: 12.5}
+	}
+	return users
+}
+
+func BenchmarkJSONRender(b *testing.B) {
+	small := benchUsers(1)[0]
+	large := benchUsers(500)
+	engine := newEngine()
+	engine.GET("/small", func(c *touka.Context) { c.JSON(http.StatusOK, small) })
+	engine.GET("/large", func(c *touka.Context) { c.JSON(http.StatusOK, large) })
+	engine.GET("/buffered", func(c *touka.Context) { c.JSONBuf(http.StatusOK, large) })
+
+	for _, name := range []string{"small", "large", "buffered"} {
+		b.Run(name, func(b *testing.B) {
+			benchRequest(b, engine, newRequest(b, http.MethodGet, "/"+name), http.StatusOK)
+		})
+	}
+}
+
+func BenchmarkJSONBind(b *testing.B) {
+	engine := newEngine()
+	engine.POST("/users", func(c *touka.Context) {
+		var u benchUser
+		if err := c.ShouldBindJSON(&u); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine.POST("/auto", func(c *touka.Context) {
+		var u benchUser
+		if err := c.ShouldBind(&u); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	body, err := json.Marshal(benchUsers(1)[0])
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, path := range []string{"/users", "/auto"} {
+		b.Run(strings.TrimPrefix(path, "/"), func(b *testing.B) {
+			req := newRequest(b, http.MethodPost, path)
+			req.Header.Set("Content-Type", "application/json")
+			benchRequestBody(b, engine, req, body, http.StatusNoContent)
+		})
+	}
+}
+
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// gzipJSON 以 gzip 压缩输出 JSON, 与压缩中间件 (github.com/fenthope/gzip) 的写出路径相同:
+// 复用 gzip.Writer 并直接写入 c.Writer
+func gzipJSON(c *touka.Context, obj any) {
+	if !strings.Contains(c.GetReqHeader("Accept-Encoding"), "gzip") {
+		c.JSON(http.StatusOK, obj)
+		return
+	}
+	zw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(zw)
+	zw.Reset(c.Writer)
+	c.SetHeader("Content-Encoding", "gzip")
+	c.SetHeader("Vary", "Accept-Encoding")
+	c.SetHeader("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := json.NewEncoder(zw).Encode(obj); err != nil {
+		c.AddError(err)
+	}
+	if err := zw.Close(); err != nil {
+		c.AddError(err)
+	}
+}
+
+func BenchmarkGzip(b *testing.B) {
+	users := benchUsers(100)
+	engine := newEngine()
+	engine.GET("/users", func(c *touka.Context) { gzipJSON(c, users) })
+
+	b.Run("Compressed", func(b *testing.B) {
+		req := newRequest(b, http.MethodGet, "/users")
+		req.Header.Set("Accept-Encoding", "gzip")
+		benchRequest(b, engine, req, http.StatusOK)
+	})
+	b.Run("Identity", func(b *testing.B) {
+		benchRequest(b, engine, newRequest(b, http.MethodGet, "/users"), http.StatusOK)
+	})
+}
@@ -0,0 +1,72 @@
+package benchmarks
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/infinite-iroha/touka"
+)
+
+// apiResources 用于生成接近真实应用规模的路由树
+var apiResources = []string{"users", "repos", "orgs", "teams", "issues", "pulls", "gists", "events", "projects", "releases"}
+
+func noContent(c *touka.Context) { c.Status(http.StatusNoContent) }
+
+func buildRoutingEngine() *touka.Engine {
+	engine := newEngine()
+	engine.GET("/", noContent)
+	engine.GET("/health", noContent)
+	for _, res := range apiResources {
+		base := "/api/v1/" + res
+		engine.GET(base, noContent)
+		engine.POST(base, noContent)
+		engine.GET(base+"/:id", noContent)
+		engine.PUT(base+"/:id", noContent)
+		engine.DELETE(base+"/:id", noContent)
+		engine.GET(base+"/:id/comments", noContent)
+		engine.GET(base+"/:id/comments/:comment", noContent)
+	}
+	engine.GET("/repos/:owner/:repo/git/trees/:sha/entries/:path", noContent)
+	engine.GET("/static/*filepath", noContent)
+	return engine
+}
+
+func BenchmarkRouting(b *testing.B) {
+	engine := buildRoutingEngine()
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		code   int
+	}{
+		{"Static", http.MethodGet, "/health", http.StatusNoContent},
+		{"StaticNested", http.MethodGet, "/api/v1/releases", http.StatusNoContent},
+		{"Param", http.MethodGet, "/api/v1/users/42", http.StatusNoContent},
+		{"TwoParams", http.MethodGet, "/api/v1/issues/42/comments/7", http.StatusNoContent},
+		{"Deep", http.MethodGet, "/repos/infinite-iroha/touka/git/trees/abc123/entries/tree.go", http.StatusNoContent},
+		{"Wildcard", http.MethodGet, "/static/css/site/main.css", http.StatusNoContent},
+		{"NotFound", http.MethodGet, "/api/v2/users", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			benchRequest(b, engine, newRequest(b, tc.method, tc.path), tc.code)
+		})
+	}
+}
+
+// BenchmarkRoutingOptional 覆盖匹配后还需要拆分参数的路由: 扩展名与可选参数
+func BenchmarkRoutingOptional(b *testing.B) {
+	engine := newEngine()
+	engine.GET("/img/:name.:ext", noContent)
+	engine.GET("/archive/:year/:month?/:day?", noContent)
+
+	b.Run("Extension", func(b *testing.B) {
+		benchRequest(b, engine, newRequest(b, http.MethodGet, "/img/logo.png"), http.StatusNoContent)
+	})
+	b.Run("OptionalOmitted", func(b *testing.B) {
+		benchRequest(b, engine, newRequest(b, http.MethodGet, "/archive/2024"), http.StatusNoContent)
+	})
+	b.Run("OptionalPresent", func(b *testing.B) {
+		benchRequest(b, engine, newRequest(b, http.MethodGet, "/archive/2024/05/17"), http.StatusNoContent)
+	})
+}
@@ -0,0 +1,69 @@
+package benchmarks
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infinite-iroha/touka"
+)
+
+// wrappedWriter 模拟替换 c.Writer 的中间件: 只嵌入 touka.ResponseWriter, 不转发 ReadFrom
+type wrappedWriter struct {
+	touka.ResponseWriter
+}
+
+func wrapWriter(c *touka.Context) {
+	original := c.Writer
+	c.Writer = wrappedWriter{original}
+	defer func() { c.Writer = original }()
+	c.Next()
+}
+
+// BenchmarkSendfile 经由真实的 TCP 连接下载文件, 比较 c.Writer 未被包装 (ReadFrom/sendfile)
+// 与被中间件包装 (经由 Write 复制) 时的吞吐量
+func BenchmarkSendfile(b *testing.B) {
+	const size = 8 << 20
+	name := filepath.Join(b.TempDir(), "large.bin")
+	if err := os.WriteFile(name, bytes.Repeat([]byte("s"), size), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	engine := newEngine()
+	engine.GET("/file", func(c *touka.Context) { c.File(name) })
+	engine.GET("/body", func(c *touka.Context) { c.SetRespBodyFile(http.StatusOK, name) })
+	wrapped := engine.Group("/wrapped", wrapWriter)
+	wrapped.GET("/file", func(c *touka.Context) { c.File(name) })
+	wrapped.GET("/body", func(c *touka.Context) { c.SetRespBodyFile(http.StatusOK, name) })
+
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+	client := srv.Client()
+
+	cases := []struct{ name, path string }{
+		{"File", "/file"},
+		{"FileWrapped", "/wrapped/file"},
+		{"RespBodyFile", "/body"},
+		{"RespBodyFileWrapped", "/wrapped/body"},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			b.SetBytes(size)
+			b.ReportAllocs()
+			for b.Loop() {
+				resp, err := client.Get(srv.URL + tc.path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				n, err := io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				if err != nil || n != size {
+					b.Fatalf("read %d bytes (%v), want %d", n, err, size)
+				}
+			}
+		})
+	}
+}
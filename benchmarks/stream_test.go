@@ -0,0 +1,38 @@
+package benchmarks
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/infinite-iroha/touka"
+)
+
+// BenchmarkSSE 测量一次请求中写出 sseEvents 个事件的开销, 每个事件之后都会刷新
+func BenchmarkSSE(b *testing.B) {
+	const sseEvents = 100
+	engine := newEngine()
+	engine.GET("/events", func(c *touka.Context) {
+		i := 0
+		c.EventStream(func(w io.Writer) bool {
+			e := touka.Event{Id: strconv.Itoa(i), Event: "tick", Data: "payload line one\npayload line two"}
+			if err := e.Render(w); err != nil {
+				return false
+			}
+			i++
+			return i < sseEvents
+		})
+	})
+	benchRequest(b, engine, newRequest(b, http.MethodGet, "/events"), http.StatusOK)
+}
+
+func BenchmarkEventRender(b *testing.B) {
+	e := touka.Event{Id: "42", Event: "update", Data: "line one\nline two\nline three", Retry: "3000"}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := e.Render(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
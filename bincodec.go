@@ -0,0 +1,281 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// MsgPack 与 CBOR 编解码通过与 JSON 互相转换实现, 因此沿用 json 标签、SetJSONOptions 的选项与默认值等绑定行为.
+// 受 JSON 数据模型的限制: []byte 编码为 base64 字符串, 解码时二进制数据转换为 base64 字符串 (可以绑定到 []byte 字段),
+// time.Time 编码为 RFC 3339 字符串, 解码时 MsgPack 时间戳扩展与 CBOR 时间标签转换为 RFC 3339 字符串,
+// map 的键必须为字符串或整数
+
+// ErrBinaryCodec 表示 MsgPack 或 CBOR 数据无效, 或包含无法与 JSON 互相转换的值
+var ErrBinaryCodec = errors.New("invalid binary payload")
+
+// maxBinaryDepth 为解码时允许的最大嵌套深度
+const maxBinaryDepth = 1000
+
+// binaryFormat 是一种与 JSON 数据模型兼容的二进制格式
+type binaryFormat interface {
+	appendNull(b []byte) []byte
+	appendBool(b []byte, v bool) []byte
+	appendInt(b []byte, v int64) []byte
+	appendUint(b []byte, v uint64) []byte
+	appendFloat(b []byte, v float64) []byte
+	appendString(b []byte, s string) []byte
+	appendArray(b []byte, n int) []byte
+	appendMap(b []byte, n int) []byte
+	// decode 从 d 中读取一个值并以 JSON 形式写入 enc
+	decode(d *binaryReader, enc *jsontext.Encoder, depth int) error
+}
+
+// jsonToBinary 将 JSON 文本转换为二进制格式
+func jsonToBinary(data []byte, f binaryFormat) ([]byte, error) {
+	dec := jsontext.NewDecoder(bytes.NewReader(data))
+	out, err := transcodeJSONValue(dec, f, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.ReadToken(); err != io.EOF {
+		return nil, fmt.Errorf("%w: trailing data after JSON value", ErrBinaryCodec)
+	}
+	return out, nil
+}
+
+func transcodeJSONValue(dec *jsontext.Decoder, f binaryFormat, b []byte) ([]byte, error) {
+	switch dec.PeekKind() {
+	case '0':
+		raw, err := dec.ReadValue()
+		if err != nil {
+			return nil, err
+		}
+		s := string(raw)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return f.appendInt(b, n), nil
+		}
+		if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return f.appendUint(b, n), nil
+		}
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: number %s out of range", ErrBinaryCodec, s)
+		}
+		return f.appendFloat(b, n), nil
+	case '[', '{':
+		start, err := dec.ReadToken()
+		if err != nil {
+			return nil, err
+		}
+		// 二进制格式需要预先写出元素个数, 元素先编码到单独的缓冲区
+		var elems []byte
+		n := 0
+		for dec.PeekKind() != ']' && dec.PeekKind() != '}' {
+			if elems, err = transcodeJSONValue(dec, f, elems); err != nil {
+				return nil, err
+			}
+			n++
+		}
+		if _, err := dec.ReadToken(); err != nil {
+			return nil, err
+		}
+		if start.Kind() == '[' {
+			b = f.appendArray(b, n)
+		} else {
+			b = f.appendMap(b, n/2)
+		}
+		return append(b, elems...), nil
+	}
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.Kind() {
+	case 'n':
+		return f.appendNull(b), nil
+	case 't', 'f':
+		return f.appendBool(b, tok.Bool()), nil
+	case '"':
+		return f.appendString(b, tok.String()), nil
+	}
+	return nil, fmt.Errorf("%w: unexpected JSON token %v", ErrBinaryCodec, tok.Kind())
+}
+
+// binaryToJSON 将二进制格式的单个值转换为 JSON 文本
+func binaryToJSON(data []byte, f binaryFormat) ([]byte, error) {
+	var out bytes.Buffer
+	enc := jsontext.NewEncoder(&out, jsontext.AllowDuplicateNames(true))
+	d := &binaryReader{data: data}
+	if err := f.decode(d, enc, 0); err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrBinaryCodec, len(d.data)-d.pos)
+	}
+	return out.Bytes(), nil
+}
+
+// binaryReader 是带边界检查的字节读取器
+type binaryReader struct {
+	data []byte
+	pos  int
+}
+
+var errBinaryTruncated = fmt.Errorf("%w: unexpected end of data", ErrBinaryCodec)
+
+func (d *binaryReader) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errBinaryTruncated
+	}
+	c := d.data[d.pos]
+	d.pos++
+	return c, nil
+}
+
+func (d *binaryReader) peek() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errBinaryTruncated
+	}
+	return d.data[d.pos], nil
+}
+
+// bytes 读取 n 个字节, 返回的切片引用原始数据
+func (d *binaryReader) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errBinaryTruncated
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// uint 读取 size 字节的大端无符号整数
+func (d *binaryReader) uint(size int) (uint64, error) {
+	b, err := d.bytes(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// count 检查容器声明的元素个数, 每个元素至少占 minSize 字节, 避免按伪造的长度分配或循环
+func (d *binaryReader) count(n uint64, minSize uint64) (int, error) {
+	if n > uint64(len(d.data)-d.pos)/minSize {
+		return 0, errBinaryTruncated
+	}
+	return int(n), nil
+}
+
+// writeBinaryString 写入字符串, 非法 UTF-8 按错误处理
+func writeBinaryString(enc *jsontext.Encoder, b []byte) error {
+	if err := enc.WriteToken(jsontext.String(string(b))); err != nil {
+		return fmt.Errorf("%w: %v", ErrBinaryCodec, err)
+	}
+	return nil
+}
+
+// writeBinaryBytes 将二进制数据写为 base64 字符串
+func writeBinaryBytes(enc *jsontext.Encoder, b []byte) error {
+	return enc.WriteToken(jsontext.String(base64.StdEncoding.EncodeToString(b)))
+}
+
+func writeBinaryFloat(enc *jsontext.Encoder, v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("%w: %v cannot be represented", ErrBinaryCodec, v)
+	}
+	return enc.WriteToken(jsontext.Float(v))
+}
+
+func writeBinaryTime(enc *jsontext.Encoder, t time.Time) error {
+	return enc.WriteToken(jsontext.String(t.UTC().Format(time.RFC3339Nano)))
+}
+
+// writeBinaryKey 写入 map 的键, 整数键转换为十进制字符串
+func writeBinaryKey(enc *jsontext.Encoder, key any) error {
+	switch k := key.(type) {
+	case string:
+		return enc.WriteToken(jsontext.String(k))
+	case int64:
+		return enc.WriteToken(jsontext.String(strconv.FormatInt(k, 10)))
+	case uint64:
+		return enc.WriteToken(jsontext.String(strconv.FormatUint(k, 10)))
+	}
+	return fmt.Errorf("%w: map keys must be strings or integers", ErrBinaryCodec)
+}
+
+// renderBinary 以 JSON 编码 obj 后转换为二进制格式写入响应, 编码失败时通过 ErrorUseHandle 返回 500
+func (c *Context) renderBinary(code int, obj any, f binaryFormat, contentType, name string) {
+	var buf bytes.Buffer
+	bw := c.budgetBuffer(&buf)
+	defer bw.release()
+	if err := c.marshalJSON(bw, obj); err != nil {
+		c.renderError(fmt.Errorf("failed to encode %s: %w", name, err))
+		return
+	}
+	out, err := jsonToBinary(buf.Bytes(), f)
+	if err != nil {
+		c.renderError(fmt.Errorf("failed to encode %s: %w", name, err))
+		return
+	}
+	c.Writer.Header().Set("Content-Type", contentType)
+	c.Writer.WriteHeader(code)
+	c.writeResponseBody(out, "failed to write "+name+" response")
+}
+
+// shouldBindBinary 读取受 MaxRequestBodySize 限制的请求体, 转换为 JSON 后按 ShouldBindJSON 的规则绑定
+func (c *Context) shouldBindBinary(obj any, f binaryFormat, name string) error {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
+	data, err := c.readBindBody()
+	if err != nil {
+		return fmt.Errorf("%s binding error: %w", name, err)
+	}
+	js, err := binaryToJSON(data, f)
+	if err != nil {
+		return fmt.Errorf("%s binding error: %w", name, err)
+	}
+	if err := c.unmarshalJSON(bytes.NewReader(js), obj); err != nil {
+		return fmt.Errorf("%s binding error: %w", name, err)
+	}
+	return nil
+}
+
+// readBindBody 读取受 MaxRequestBodySize 与内存预算限制的完整请求体, 供需要整体解码的绑定使用
+func (c *Context) readBindBody() ([]byte, error) {
+	body := c.bindRequestBody()
+	if body == nil || body == http.NoBody {
+		return nil, errors.New("request body is empty")
+	}
+	data, err := c.readAllBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("request body is empty")
+	}
+	return data, nil
+}
@@ -0,0 +1,184 @@
+package touka
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type binaryPayload struct {
+	Name    string            `json:"name"`
+	Count   int64             `json:"count"`
+	Big     uint64            `json:"big"`
+	Ratio   float64           `json:"ratio"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Data    []byte            `json:"data"`
+	When    time.Time         `json:"when"`
+	Missing *int              `json:"missing"`
+}
+
+func binaryRequest(body []byte, contentType string) *Context {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+	return c
+}
+
+func TestMsgPackAndCBORRender(t *testing.T) {
+	tests := []struct {
+		name        string
+		render      func(c *Context)
+		contentType string
+		want        []byte
+	}{
+		{"msgpack", func(c *Context) { c.MsgPack(http.StatusCreated, H{"a": 1}) }, "application/msgpack", []byte{0x81, 0xa1, 'a', 0x01}},
+		{"msgpack negative", func(c *Context) { c.MsgPack(http.StatusCreated, []int{-1, -200, 300}) }, "application/msgpack", []byte{0x93, 0xff, 0xd1, 0xff, 0x38, 0xcd, 0x01, 0x2c}},
+		{"cbor", func(c *Context) { c.CBOR(http.StatusCreated, H{"a": 1}) }, "application/cbor", []byte{0xa1, 0x61, 'a', 0x01}},
+		{"cbor negative", func(c *Context) { c.CBOR(http.StatusCreated, []any{-1, -500, true, nil}) }, "application/cbor", []byte{0x84, 0x20, 0x39, 0x01, 0xf3, 0xf5, 0xf6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := CreateTestContext(w)
+			tt.render(c)
+			if w.Code != http.StatusCreated || w.Header().Get("Content-Type") != tt.contentType {
+				t.Fatalf("code=%d content-type=%q", w.Code, w.Header().Get("Content-Type"))
+			}
+			if !bytes.Equal(w.Body.Bytes(), tt.want) {
+				t.Errorf("body = % x, want % x", w.Body.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	in := binaryPayload{
+		Name:   "touka",
+		Count:  -42,
+		Big:    math.MaxUint64,
+		Ratio:  0.25,
+		Tags:   []string{"a", strings.Repeat("x", 300)},
+		Labels: map[string]string{"env": "prod"},
+		Data:   []byte{0, 1, 2},
+		When:   time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+	for _, format := range []struct {
+		render      func(c *Context, obj any)
+		contentType string
+	}{
+		{func(c *Context, obj any) { c.MsgPack(http.StatusOK, obj) }, "application/msgpack"},
+		{func(c *Context, obj any) { c.CBOR(http.StatusOK, obj) }, "application/cbor"},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := CreateTestContext(w)
+		format.render(c, in)
+
+		var out binaryPayload
+		if err := binaryRequest(w.Body.Bytes(), format.contentType).ShouldBind(&out); err != nil {
+			t.Fatalf("%s: %v", format.contentType, err)
+		}
+		if out.Name != in.Name || out.Count != in.Count || out.Big != in.Big || out.Ratio != in.Ratio ||
+			len(out.Tags) != 2 || out.Tags[1] != in.Tags[1] || out.Labels["env"] != "prod" ||
+			!bytes.Equal(out.Data, in.Data) || !out.When.Equal(in.When) || out.Missing != nil {
+			t.Errorf("%s: round trip mismatch: %+v", format.contentType, out)
+		}
+	}
+}
+
+func TestShouldBindBinaryNativeTypes(t *testing.T) {
+	type payload struct {
+		N    []float64         `json:"n"`
+		Blob []byte            `json:"blob"`
+		When time.Time         `json:"when"`
+		Keys map[int]string    `json:"keys"`
+		Any  map[string]string `json:"any"`
+	}
+	want := time.Unix(1700000000, 0)
+
+	// {"n": [1.5 (half), 2 (uint)], "blob": h'0102', "when": 1(1700000000), "keys": {1: "one"}, "any": {_ "k": (_ "v", "w")}}
+	cborBody := []byte{0xa5,
+		0x61, 'n', 0x9f, 0xf9, 0x3e, 0x00, 0x02, 0xff,
+		0x64, 'b', 'l', 'o', 'b', 0x42, 0x01, 0x02,
+		0x64, 'w', 'h', 'e', 'n', 0xc1, 0x1a, 0x65, 0x53, 0xf1, 0x00,
+		0x64, 'k', 'e', 'y', 's', 0xa1, 0x01, 0x63, 'o', 'n', 'e',
+		0x63, 'a', 'n', 'y', 0xbf, 0x61, 'k', 0x7f, 0x61, 'v', 0x61, 'w', 0xff, 0xff,
+	}
+	var got payload
+	if err := binaryRequest(cborBody, "application/cbor").ShouldBindCBOR(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.N) != 2 || got.N[0] != 1.5 || got.N[1] != 2 || !bytes.Equal(got.Blob, []byte{1, 2}) ||
+		!got.When.Equal(want) || got.Keys[1] != "one" || got.Any["k"] != "vw" {
+		t.Errorf("cbor: %+v", got)
+	}
+
+	// {"when": timestamp32(1700000000), "blob": bin8(01 02)}
+	msgpackBody := []byte{0x82,
+		0xa4, 'w', 'h', 'e', 'n', 0xd6, 0xff, 0x65, 0x53, 0xf1, 0x00,
+		0xa4, 'b', 'l', 'o', 'b', 0xc4, 0x02, 0x01, 0x02,
+	}
+	got = payload{}
+	if err := binaryRequest(msgpackBody, "application/x-msgpack").ShouldBind(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.When.Equal(want) || !bytes.Equal(got.Blob, []byte{1, 2}) {
+		t.Errorf("msgpack: %+v", got)
+	}
+}
+
+func TestShouldBindBinaryErrors(t *testing.T) {
+	var v map[string]any
+	tests := []struct {
+		name string
+		bind func(c *Context) error
+		body []byte
+	}{
+		{"truncated msgpack", func(c *Context) error { return c.ShouldBindMsgPack(&v) }, []byte{0xa5, 'a'}},
+		{"oversized msgpack count", func(c *Context) error { return c.ShouldBindMsgPack(&v) }, []byte{0xdf, 0xff, 0xff, 0xff, 0xff}},
+		{"trailing msgpack", func(c *Context) error { return c.ShouldBindMsgPack(&v) }, []byte{0x80, 0xc0}},
+		{"unsupported msgpack ext", func(c *Context) error { return c.ShouldBindMsgPack(&v) }, []byte{0xd4, 0x05, 0x00}},
+		{"cbor array key", func(c *Context) error { return c.ShouldBindCBOR(&v) }, []byte{0xa1, 0x80, 0x01}},
+		{"cbor NaN", func(c *Context) error { return c.ShouldBindCBOR(&v) }, []byte{0xa1, 0x61, 'a', 0xf9, 0x7e, 0x00}},
+		{"cbor invalid utf-8", func(c *Context) error { return c.ShouldBindCBOR(&v) }, []byte{0xa1, 0x61, 'a', 0x61, 0xff}},
+		{"cbor unterminated", func(c *Context) error { return c.ShouldBindCBOR(&v) }, []byte{0x9f, 0x01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.bind(binaryRequest(tt.body, "application/octet-stream"))
+			if !errors.Is(err, ErrBinaryCodec) {
+				t.Errorf("expected ErrBinaryCodec, got %v", err)
+			}
+		})
+	}
+
+	deep := append(bytes.Repeat([]byte{0x81}, maxBinaryDepth+2), 0x01)
+	if err := binaryRequest(deep, "application/cbor").ShouldBindCBOR(&v); !errors.Is(err, ErrBinaryCodec) {
+		t.Errorf("deep nesting: expected ErrBinaryCodec, got %v", err)
+	}
+}
+
+func TestShouldBindMsgPackHonorsMaxRequestBodySize(t *testing.T) {
+	c := binaryRequest([]byte{0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa6, 'a', 'b', 'c', 'd', 'e', 'f'}, "application/msgpack")
+	c.SetMaxRequestBodySize(8)
+	var payload struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindMsgPack(&payload); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestMsgPackRenderErrorUsesErrorHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.MsgPack(http.StatusOK, H{"bad": math.NaN()})
+	if w.Code != http.StatusInternalServerError || c.ErrorCount() == 0 {
+		t.Errorf("code=%d errors=%v", w.Code, c.GetErrors())
+	}
+}
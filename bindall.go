@@ -0,0 +1,56 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+)
+
+// BindAll 从多个来源填充同一个结构体, 按以下顺序绑定, 后者覆盖前者:
+//
+//  1. 请求体: 按 Content-Type 分发给 ShouldBind, 未设置 Content-Type 时按 JSON 解析
+//  2. 查询参数: `query:"name"` 标签
+//  3. 请求头: `header:"X-Name"` 标签 (不区分大小写)
+//  4. 路径参数: `uri:"name"` 标签
+//
+// 路径参数优先级最高, 请求体中的字段无法覆盖资源标识. 只应来自查询参数、请求头或路径的字段
+// 建议同时标记 `json:"-"`, 以免在这些来源缺失时被请求体填充.
+//
+//	type UpdateOrder struct {
+//	    ID      string `uri:"id" json:"-"`
+//	    DryRun  bool   `query:"dry_run" json:"-"`
+//	    Tenant  string `header:"X-Tenant-ID" json:"-"`
+//	    Status  string `json:"status"`
+//	}
+func (c *Context) BindAll(obj any) error {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
+
+	if hasRequestBody(c.Request) {
+		var err error
+		if c.Request.Header.Get("Content-Type") == "" {
+			err = c.ShouldBindJSON(obj)
+		} else {
+			err = c.ShouldBind(obj)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// 默认值已在解析请求体之前填入, 之后的来源不再填入, 以免覆盖请求体中显式的零值
+	query := c.queryValues()
+	if err := (bindSource{tagName: "query", lookup: func(name string) []string { return query[name] }, skipDefaults: true}).bind(obj); err != nil {
+		return fmt.Errorf("query binding error: %w", err)
+	}
+	if err := (bindSource{tagName: "header", lookup: c.Request.Header.Values, skipDefaults: true}).bind(obj); err != nil {
+		return fmt.Errorf("header binding error: %w", err)
+	}
+	if err := (bindSource{tagName: "uri", lookup: c.paramValues, skipDefaults: true}).bind(obj); err != nil {
+		return fmt.Errorf("uri binding error: %w", err)
+	}
+	return nil
+}
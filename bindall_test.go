@@ -0,0 +1,72 @@
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type updateOrder struct {
+	ID     int      `uri:"id" json:"id"`
+	DryRun bool     `query:"dry_run" json:"-"`
+	Fields []string `query:"field" json:"-"`
+	Tenant string   `header:"X-Tenant-ID" json:"-"`
+	Status string   `json:"status"`
+	Note   string   `json:"note" default:"none"`
+}
+
+func TestBindAll(t *testing.T) {
+	engine := New()
+	var got updateOrder
+	engine.PUT("/orders/:id", func(c *Context) {
+		got = updateOrder{}
+		if err := c.BindAll(&got); err != nil {
+			c.String(http.StatusBadRequest, "%s", err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := PerformRequest(engine, http.MethodPut, "/orders/42?dry_run=true&field=a&field=b",
+		strings.NewReader(`{"id":7,"status":"paid"}`), http.Header{"X-Tenant-Id": {"acme"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected %d %s", w.Code, w.Body.String())
+	}
+	want := updateOrder{ID: 42, DryRun: true, Fields: []string{"a", "b"}, Tenant: "acme", Status: "paid", Note: "none"}
+	if got.ID != want.ID || got.DryRun != want.DryRun || strings.Join(got.Fields, ",") != "a,b" ||
+		got.Tenant != want.Tenant || got.Status != want.Status || got.Note != want.Note {
+		t.Fatalf("unexpected result %+v", got)
+	}
+
+	w = PerformRequest(engine, http.MethodPut, "/orders/x", nil, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "uri binding error") {
+		t.Fatalf("expected uri binding error, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestBindAllExplicitZeroBeatsDefault(t *testing.T) {
+	type settings struct {
+		Enabled bool   `json:"enabled" default:"true"`
+		Limit   int    `json:"limit" default:"10"`
+		Label   string `json:"label" default:"none"`
+		Page    int    `query:"page" json:"-" default:"1"`
+	}
+	engine := New()
+	var got settings
+	engine.POST("/settings", func(c *Context) {
+		got = settings{}
+		if err := c.BindAll(&got); err != nil {
+			c.String(http.StatusBadRequest, "%s", err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := PerformRequest(engine, http.MethodPost, "/settings", strings.NewReader(`{"enabled":false,"limit":0,"label":""}`), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected %d %s", w.Code, w.Body.String())
+	}
+	if got.Enabled || got.Limit != 0 || got.Label != "" || got.Page != 1 {
+		t.Fatalf("expected explicit zero values to beat defaults, got %+v", got)
+	}
+}
@@ -0,0 +1,62 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Binding 将请求体解码到对象, 用于为 ShouldBind 注册自定义的请求体格式
+type Binding interface {
+	// Name 返回格式名称, 用于错误信息
+	Name() string
+	// Bind 解码 req.Body 到 obj. 请求体已按 MaxRequestBodySize 限制, obj 中的 default 标签已经应用
+	Bind(req *http.Request, obj any) error
+}
+
+// RegisterBinding 为一个或多个媒体类型注册 Binding, ShouldBind (以及 BindAll、类型化处理器) 遇到这些
+// Content-Type 时交给它解码. 注册的 Binding 优先于内置的 JSON、表单、WANF 与 GOB 绑定, 因此也可以替换它们.
+// 应在启动服务器之前调用:
+//
+//	r.RegisterBinding(csvBinding{}, "text/csv")
+func (engine *Engine) RegisterBinding(b Binding, mediaTypes ...string) {
+	if b == nil || len(mediaTypes) == 0 {
+		panic("touka: RegisterBinding requires a binding and at least one media type")
+	}
+	if engine.bindings == nil {
+		engine.bindings = make(map[string]Binding)
+	}
+	for _, mt := range mediaTypes {
+		mediaType, _, err := mime.ParseMediaType(mt)
+		if err != nil {
+			panic("touka: invalid media type " + mt)
+		}
+		engine.bindings[strings.ToLower(mediaType)] = b
+	}
+}
+
+// ShouldBindWith 使用指定的 Binding 解码请求体, 不检查 Content-Type
+func (c *Context) ShouldBindWith(obj any, b Binding) error {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
+	c.bindRequestBody()
+	if err := b.Bind(c.Request, obj); err != nil {
+		return fmt.Errorf("%s binding error: %w", b.Name(), err)
+	}
+	return nil
+}
+
+// lookupBinding 返回为媒体类型注册的 Binding
+func (c *Context) lookupBinding(mediaType string) (Binding, bool) {
+	if c.engine == nil || c.engine.bindings == nil {
+		return nil, false
+	}
+	b, ok := c.engine.bindings[mediaType]
+	return b, ok
+}
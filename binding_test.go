@@ -0,0 +1,147 @@
+package touka
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindAddress struct {
+	City string `form:"city" query:"city"`
+	Zip  string `form:"zip" query:"zip"`
+}
+
+type BindPaging struct {
+	Page int `query:"page" default:"1"`
+}
+
+func TestShouldBindQuery(t *testing.T) {
+	var q struct {
+		BindPaging
+		Status  []string     `query:"status"`
+		IDs     []int        `query:"ids"`
+		Since   time.Time    `query:"since"`
+		Address *bindAddress `query:"address"`
+		Missing *bindAddress `query:"missing"`
+		Lines   []bindAddress
+	}
+	req := httptest.NewRequest(http.MethodGet, "/users?status=active&status=locked&ids[]=1&ids[]=2"+
+		"&since=2026-01-02T03:04:05Z&address.city=Kyoto&Lines[0].city=Osaka&Lines[1].zip=100", nil)
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+	if err := c.ShouldBindQuery(&q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Page != 1 || strings.Join(q.Status, ",") != "active,locked" || len(q.IDs) != 2 || q.IDs[1] != 2 || q.Since.Year() != 2026 {
+		t.Fatalf("unexpected scalar fields %+v", q)
+	}
+	if q.Address == nil || q.Address.City != "Kyoto" || q.Missing != nil {
+		t.Fatalf("unexpected nested structs %+v %+v", q.Address, q.Missing)
+	}
+	if len(q.Lines) != 2 || q.Lines[0].City != "Osaka" || q.Lines[1].Zip != "100" {
+		t.Fatalf("unexpected struct slice %+v", q.Lines)
+	}
+
+	var bad struct {
+		Page int `query:"page"`
+	}
+	c, _ = CreateTestContextWithRequest(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?page=x", nil))
+	if err := c.ShouldBindQuery(&bad); err == nil || !strings.Contains(err.Error(), "field Page") {
+		t.Fatalf("expected a field error, got %v", err)
+	}
+}
+
+func TestShouldBindFormNestedAndCached(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("name=iroha&ship.city=Kyoto&tags=a&tags=b"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+
+	if c.PostForm("name") != "iroha" {
+		t.Fatal("expected PostForm to parse the body")
+	}
+	var form struct {
+		Name string      `form:"name"`
+		Ship bindAddress `form:"ship"`
+		Tags []string    `form:"tags"`
+	}
+	// 请求体已被 PostForm 读取, ShouldBindForm 必须复用解析结果
+	if err := c.ShouldBindForm(&form); err != nil {
+		t.Fatal(err)
+	}
+	if form.Name != "iroha" || form.Ship.City != "Kyoto" || strings.Join(form.Tags, ",") != "a,b" {
+		t.Fatalf("unexpected form %+v", form)
+	}
+}
+
+func TestShouldBindUri(t *testing.T) {
+	type orderURI struct {
+		Tenant string `uri:"tenant"`
+		ID     int64  `uri:"id"`
+		Draft  bool   `uri:"draft" default:"false"`
+	}
+	engine := New()
+	engine.GET("/:tenant/orders/:id", func(c *Context) {
+		var uri orderURI
+		if err := c.ShouldBindUri(&uri); err != nil {
+			c.String(http.StatusBadRequest, "%v", err)
+			return
+		}
+		c.String(http.StatusOK, "%s/%d", uri.Tenant, uri.ID)
+	})
+
+	if w := PerformRequest(engine, http.MethodGet, "/acme/orders/42", nil, nil); w.Code != http.StatusOK || w.Body.String() != "acme/42" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	w := PerformRequest(engine, http.MethodGet, "/acme/orders/x", nil, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "field ID") {
+		t.Fatalf("expected a conversion error, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+// csvBinding 解码第一行 CSV 到 *[]string
+type csvBinding struct{}
+
+func (csvBinding) Name() string { return "csv" }
+
+func (csvBinding) Bind(req *http.Request, obj any) error {
+	records, err := csv.NewReader(req.Body).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New("empty csv")
+	}
+	*obj.(*[]string) = records[0]
+	return nil
+}
+
+func TestRegisterBinding(t *testing.T) {
+	engine := New()
+	engine.RegisterBinding(csvBinding{}, "text/csv", "application/CSV")
+	engine.POST("/import", func(c *Context) {
+		var row []string
+		if err := c.ShouldBind(&row); err != nil {
+			c.String(http.StatusBadRequest, "%v", err)
+			return
+		}
+		c.String(http.StatusOK, "%s", strings.Join(row, "|"))
+	})
+
+	for _, ct := range []string{"text/csv; charset=utf-8", "application/csv"} {
+		w := PerformRequest(engine, http.MethodPost, "/import", strings.NewReader("a,b,c\n"), http.Header{"Content-Type": {ct}})
+		if w.Code != http.StatusOK || w.Body.String() != "a|b|c" {
+			t.Fatalf("%s: unexpected response %d %q", ct, w.Code, w.Body.String())
+		}
+	}
+	w := PerformRequest(engine, http.MethodPost, "/import", strings.NewReader(""), http.Header{"Content-Type": {"text/csv"}})
+	if w.Code != http.StatusBadRequest || w.Body.String() != "csv binding error: empty csv" {
+		t.Fatalf("unexpected error response %d %q", w.Code, w.Body.String())
+	}
+	w = PerformRequest(engine, http.MethodPost, "/import", strings.NewReader("a"), http.Header{"Content-Type": {"text/tab-separated-values"}})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unsupported content type") {
+		t.Fatalf("unregistered types must still be rejected, got %d %q", w.Code, w.Body.String())
+	}
+}
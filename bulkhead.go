@@ -0,0 +1,154 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBulkheadFull 表示隔舱的处理名额与等待队列都已占满
+var ErrBulkheadFull = errors.New("bulkhead full")
+
+// BulkheadOptions 配置 Bulkhead
+type BulkheadOptions struct {
+	// Workers 为隔舱内同时处理的请求数上限, 必填
+	Workers int
+	// QueueSize 为等待名额的请求数上限, 默认等于 Workers; 设为负数表示不排队
+	QueueSize int
+	// QueueTimeout 为最长排队时间, 默认 1 秒
+	QueueTimeout time.Duration
+	// Timeout 非零时为请求的 context 设置期限, 配合 context 的处理函数在超时后尽快返回并归还名额
+	Timeout time.Duration
+}
+
+// BulkheadStats 是隔舱的统计
+type BulkheadStats struct {
+	Active   int    // 当前处理中的请求数
+	Queued   int    // 当前排队数
+	Admitted uint64 // 累计获得处理的请求数
+	Rejected uint64 // 累计因队列已满而被拒绝的请求数
+	TimedOut uint64 // 累计排队超时或客户端取消的请求数
+	Panics   uint64 // 累计在隔舱内恢复的 panic 数
+}
+
+// Bulkhead 将一组路由隔离在独立的并发名额内: 其中的处理函数阻塞、泄漏或崩溃时,
+// 最多只会占满本隔舱的名额, 不会拖垮服务器上的其他路由.
+// 隔舱内的 panic 会被恢复并以 *PanicError 交给 ErrorHandler (500)
+type Bulkhead struct {
+	slots        chan struct{}
+	queueSize    int64
+	queueTimeout time.Duration
+	timeout      time.Duration
+
+	queued   atomic.Int64
+	admitted atomic.Uint64
+	rejected atomic.Uint64
+	timedOut atomic.Uint64
+	panics   atomic.Uint64
+}
+
+// NewBulkhead 创建隔舱, 通过 Handler 作为路由组中间件使用:
+//
+//	reports := r.Group("/reports", touka.NewBulkhead(touka.BulkheadOptions{Workers: 8}).Handler())
+func NewBulkhead(opts BulkheadOptions) *Bulkhead {
+	if opts.Workers <= 0 {
+		panic("touka: bulkhead Workers must be positive")
+	}
+	if opts.QueueSize == 0 {
+		opts.QueueSize = opts.Workers
+	}
+	if opts.QueueTimeout <= 0 {
+		opts.QueueTimeout = time.Second
+	}
+	return &Bulkhead{
+		slots:        make(chan struct{}, opts.Workers),
+		queueSize:    int64(max(opts.QueueSize, 0)),
+		queueTimeout: opts.QueueTimeout,
+		timeout:      opts.Timeout,
+	}
+}
+
+// Handler 返回执行隔离的中间件, 无法获得名额时返回 503 并设置 Retry-After
+func (b *Bulkhead) Handler() HandlerFunc {
+	return func(c *Context) {
+		if err := b.acquire(c.Context()); err != nil {
+			c.SetHeader("Retry-After", "1")
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusServiceUnavailable, err)
+			return
+		}
+		defer func() { <-b.slots }()
+
+		if b.timeout > 0 {
+			ctx, cancel := context.WithTimeout(c.ctx, b.timeout)
+			defer cancel()
+			origCtx, origReq := c.ctx, c.Request
+			c.ctx, c.Request = ctx, c.Request.WithContext(ctx)
+			defer func() { c.ctx, c.Request = origCtx, origReq }()
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+				b.panics.Add(1)
+				panicErr := NewPanicError(r)
+				c.AddError(panicErr)
+				c.Errorf("bulkhead: recovered panic: %v\n%s", r, panicErr.Stack)
+				if !c.Writer.Written() {
+					c.ErrorUseHandle(http.StatusInternalServerError, panicErr)
+				}
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
+
+// Stats 返回隔舱的统计
+func (b *Bulkhead) Stats() BulkheadStats {
+	return BulkheadStats{
+		Active:   len(b.slots),
+		Queued:   int(b.queued.Load()),
+		Admitted: b.admitted.Load(),
+		Rejected: b.rejected.Load(),
+		TimedOut: b.timedOut.Load(),
+		Panics:   b.panics.Load(),
+	}
+}
+
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		b.admitted.Add(1)
+		return nil
+	default:
+	}
+	if b.queued.Add(1) > b.queueSize {
+		b.queued.Add(-1)
+		b.rejected.Add(1)
+		return ErrBulkheadFull
+	}
+	defer b.queued.Add(-1)
+
+	timer := time.NewTimer(b.queueTimeout)
+	defer timer.Stop()
+	select {
+	case b.slots <- struct{}{}:
+		b.admitted.Add(1)
+		return nil
+	case <-timer.C:
+		b.timedOut.Add(1)
+		return ErrBulkheadFull
+	case <-ctx.Done():
+		b.timedOut.Add(1)
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,67 @@
+package touka
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBulkheadIsolatesBlockedRoutes(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadOptions{Workers: 1, QueueSize: -1})
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	engine := New()
+	slow := engine.Group("/slow", bulkhead.Handler())
+	slow.GET("/block", func(c *Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/fast", func(c *Context) { c.Status(http.StatusNoContent) })
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow/block", nil))
+		done <- w.Code
+	}()
+	<-started
+
+	if w := PerformRequest(engine, http.MethodGet, "/slow/block", nil, nil); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected the full bulkhead to reject, got %d", w.Code)
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/fast", nil, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("routes outside the bulkhead must not be affected, got %d", w.Code)
+	}
+	if st := bulkhead.Stats(); st.Active != 1 || st.Rejected != 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusNoContent {
+		t.Fatalf("unexpected status for the blocked request %d", code)
+	}
+}
+
+func TestBulkheadQueueAndPanic(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadOptions{Workers: 1, QueueTimeout: 20 * time.Millisecond, Timeout: 10 * time.Millisecond})
+	engine := New()
+	engine.Use(bulkhead.Handler())
+	engine.GET("/panic", func(c *Context) { panic("boom") })
+	engine.GET("/deadline", func(c *Context) {
+		<-c.Context().Done()
+		c.Status(http.StatusGatewayTimeout)
+	})
+
+	if w := PerformRequest(engine, http.MethodGet, "/panic", nil, nil); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected recovered panic to return 500, got %d", w.Code)
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/deadline", nil, nil); w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected the request context to carry the bulkhead deadline, got %d", w.Code)
+	}
+	if st := bulkhead.Stats(); st.Panics != 1 || st.Active != 0 || st.Admitted != 2 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
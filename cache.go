@@ -0,0 +1,218 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"sync"
+	"time"
+)
+
+// CacheStore 是引擎级的键值缓存存储, 供片段缓存等功能共用.
+// 默认使用进程内实现, 集群部署时可通过 engine.SetCacheStore 替换为 Redis 等共享实现
+type CacheStore interface {
+	// Get 返回 key 对应的值, 不存在或已过期时 ok 为 false
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+type cacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// MemoryCacheStore 是进程内的 CacheStore 实现
+type MemoryCacheStore struct {
+	mu        sync.Mutex
+	entries   map[string]cacheEntry
+	nextSweep time.Time
+}
+
+// NewMemoryCacheStore 创建进程内缓存存储
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{entries: make(map[string]cacheEntry)}
+}
+
+func (s *MemoryCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || !time.Now().Before(e.expires) {
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+func (s *MemoryCacheStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 定期清理过期条目, 避免每次写入都遍历全部缓存
+	if now.After(s.nextSweep) {
+		for k, e := range s.entries {
+			if !now.Before(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(time.Minute)
+	}
+	s.entries[key] = cacheEntry{value: value, expires: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryCacheStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// SetCacheStore 设置引擎的缓存存储
+func (engine *Engine) SetCacheStore(store CacheStore) {
+	if store == nil {
+		panic("touka: cache store must not be nil")
+	}
+	engine.cacheStore = store
+}
+
+// fragmentKeyPrefix 为片段缓存在 CacheStore 与 Locker 中的键前缀
+const fragmentKeyPrefix = "fragment:"
+
+// FragmentCache 缓存渲染好的页面片段, 通过 engine.Cache() 或 c.Cache() 获取.
+// 同一键的并发未命中只会调用一次 fill: 进程内的等待者直接共享结果,
+// 跨实例的请求通过引擎的 Locker 串行化, 并在获取锁后重新检查缓存
+type FragmentCache struct {
+	engine *Engine
+
+	mu    sync.Mutex
+	calls map[string]*fragmentCall
+}
+
+type fragmentCall struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// Cache 返回引擎的片段缓存
+func (engine *Engine) Cache() *FragmentCache {
+	return engine.fragments
+}
+
+// Cache 返回引擎的片段缓存
+func (c *Context) Cache() *FragmentCache {
+	return c.engine.fragments
+}
+
+// Fetch 返回 key 的缓存内容, 未命中时调用 fill 生成并缓存 ttl. fill 返回错误时不会缓存.
+//
+//	html, err := c.Cache().Fetch(c.Context(), "sidebar:"+user.ID, 5*time.Minute, func() (string, error) {
+//	    return renderSidebar(user)
+//	})
+func (f *FragmentCache) Fetch(ctx context.Context, key string, ttl time.Duration, fill func() (string, error)) (string, error) {
+	store := f.engine.cacheStore
+	storeKey := fragmentKeyPrefix + key
+	if v, ok, err := store.Get(ctx, storeKey); err == nil && ok {
+		return string(v), nil
+	}
+
+	f.mu.Lock()
+	if call, ok := f.calls[key]; ok {
+		f.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.value, call.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	call := &fragmentCall{done: make(chan struct{})}
+	f.calls[key] = call
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		delete(f.calls, key)
+		f.mu.Unlock()
+		close(call.done)
+	}()
+	call.value, call.err = f.fill(ctx, store, storeKey, ttl, fill)
+	return call.value, call.err
+}
+
+func (f *FragmentCache) fill(ctx context.Context, store CacheStore, storeKey string, ttl time.Duration, fill func() (string, error)) (value string, err error) {
+	unlock, err := f.engine.locker.Lock(ctx, storeKey, 30*time.Second)
+	if err != nil {
+		return "", fmt.Errorf("fragment cache lock: %w", err)
+	}
+	defer unlock()
+	// 其他实例可能已在等待锁期间写入缓存
+	if v, ok, err := store.Get(ctx, storeKey); err == nil && ok {
+		return string(v), nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("fragment cache fill panic: %v", r)
+		}
+	}()
+	value, err = fill()
+	if err != nil {
+		return "", err
+	}
+	// 写入失败只影响后续命中, 不影响本次结果
+	_ = store.Set(ctx, storeKey, []byte(value), ttl)
+	return value, nil
+}
+
+// Delete 使 key 的缓存失效
+func (f *FragmentCache) Delete(ctx context.Context, key string) error {
+	return f.engine.cacheStore.Delete(ctx, fragmentKeyPrefix+key)
+}
+
+// errFragmentNoTemplate 表示 fragment 模板函数找不到可用的模板
+var errFragmentNoTemplate = errors.New("fragment: engine has no html/template renderer")
+
+// renderFragment 渲染模板 name 并缓存, 供模板函数 fragment 使用
+func (c *Context) renderFragment(key, ttl, name string, data any) (template.HTML, error) {
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return "", fmt.Errorf("fragment %q: invalid ttl: %w", key, err)
+	}
+	out, err := c.Cache().Fetch(c.Context(), key, d, func() (string, error) {
+		tpl, err := c.engine.htmlTemplate()
+		if err != nil {
+			return "", err
+		}
+		if tpl == nil {
+			return "", errFragmentNoTemplate
+		}
+		var buf bytes.Buffer
+		if err := tpl.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	})
+	// 内容由 html/template 渲染, 已经过转义
+	return template.HTML(out), err
+}
+
+// CacheFuncMap 返回片段缓存的模板函数, LoadHTMLGlob 会自动注册它:
+//
+//	{{fragment .ctx "sidebar:v1" "5m" "sidebar.html" .sidebar}}
+//
+// 未命中时渲染模板 sidebar.html 并缓存 5 分钟
+func CacheFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"fragment": func(c *Context, key, ttl, name string, data any) (template.HTML, error) {
+			return c.renderFragment(key, ttl, name, data)
+		},
+	}
+}
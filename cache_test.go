@@ -0,0 +1,113 @@
+package touka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFragmentCacheFetch(t *testing.T) {
+	engine := New()
+	cache := engine.Cache()
+	ctx := context.Background()
+
+	var fills atomic.Int32
+	fill := func() (string, error) {
+		fills.Add(1)
+		return "v", nil
+	}
+	for range 2 {
+		if v, err := cache.Fetch(ctx, "k", time.Minute, fill); err != nil || v != "v" {
+			t.Fatalf("unexpected %q %v", v, err)
+		}
+	}
+	if fills.Load() != 1 {
+		t.Fatalf("expected a single fill, got %d", fills.Load())
+	}
+
+	if err := cache.Delete(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	cache.Fetch(ctx, "k", time.Minute, fill)
+	if fills.Load() != 2 {
+		t.Fatal("Delete should invalidate the fragment")
+	}
+
+	boom := errors.New("boom")
+	if _, err := cache.Fetch(ctx, "bad", time.Minute, func() (string, error) { return "", boom }); !errors.Is(err, boom) {
+		t.Fatalf("expected fill error, got %v", err)
+	}
+	if v, _ := cache.Fetch(ctx, "bad", time.Minute, fill); v != "v" {
+		t.Fatal("errors must not be cached")
+	}
+}
+
+func TestFragmentCacheStampedeProtection(t *testing.T) {
+	engine := New()
+	var fills atomic.Int32
+	release := make(chan struct{})
+
+	const n = 10
+	var wg sync.WaitGroup
+	results := make(chan string, n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, _ := engine.Cache().Fetch(context.Background(), "hot", time.Minute, func() (string, error) {
+				fills.Add(1)
+				<-release
+				return "rendered", nil
+			})
+			results <- v
+		}()
+	}
+	for fills.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if fills.Load() != 1 {
+		t.Fatalf("expected a single fill under contention, got %d", fills.Load())
+	}
+	for v := range results {
+		if v != "rendered" {
+			t.Fatalf("unexpected result %q", v)
+		}
+	}
+}
+
+func TestFragmentTemplateFunc(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"page.html":    `<main>{{fragment .ctx "side" "1m" "sidebar.html" .name}}</main>`,
+		"sidebar.html": `<aside>{{.}}</aside>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	engine := New()
+	engine.LoadHTMLGlob(filepath.Join(dir, "*.html"))
+	engine.GET("/", func(c *Context) {
+		c.HTMLBuf(http.StatusOK, "page.html", H{"ctx": c, "name": c.Query("name")})
+	})
+
+	if w := PerformRequest(engine, http.MethodGet, "/?name=<a>", nil, nil); w.Body.String() != "<main><aside>&lt;a&gt;</aside></main>" {
+		t.Fatalf("unexpected render %q", w.Body.String())
+	}
+	// 第二次请求命中缓存
+	if w := PerformRequest(engine, http.MethodGet, "/?name=b", nil, nil); w.Body.String() != "<main><aside>&lt;a&gt;</aside></main>" {
+		t.Fatalf("expected cached fragment, got %q", w.Body.String())
+	}
+}
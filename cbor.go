@@ -0,0 +1,332 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// CBOR 向响应写入 CBOR (RFC 8949) 数据, Content-Type 为 application/cbor.
+// 对象先按 JSON 规则编码 (json 标签与 SetJSONOptions 同样生效), 编码失败时通过 ErrorUseHandle 返回 500
+func (c *Context) CBOR(code int, obj any) {
+	c.renderBinary(code, obj, cborFormat{}, "application/cbor", "CBOR")
+}
+
+// ShouldBindCBOR 将 CBOR 格式的请求体绑定到对象, 请求体大小受 MaxRequestBodySize 限制,
+// 字段匹配与默认值规则与 ShouldBindJSON 相同
+func (c *Context) ShouldBindCBOR(obj any) error {
+	return c.shouldBindBinary(obj, cborFormat{}, "cbor")
+}
+
+// CBOR 主类型
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+
+	cborIndefinite = 31
+	cborBreak      = 0xff
+)
+
+// cborFormat 编码时使用定长形式, 解码时同时支持不定长的字符串与容器
+type cborFormat struct{}
+
+// appendCBORHead 写入主类型与参数, 参数使用最短编码
+func appendCBORHead(b []byte, major byte, v uint64) []byte {
+	major <<= 5
+	switch {
+	case v < 24:
+		return append(b, major|byte(v))
+	case v <= math.MaxUint8:
+		return append(b, major|24, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), v)
+}
+
+func (cborFormat) appendNull(b []byte) []byte { return append(b, 0xf6) }
+
+func (cborFormat) appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xf5)
+	}
+	return append(b, 0xf4)
+}
+
+func (cborFormat) appendInt(b []byte, v int64) []byte {
+	if v >= 0 {
+		return appendCBORHead(b, cborUint, uint64(v))
+	}
+	return appendCBORHead(b, cborNegInt, uint64(-1-v))
+}
+
+func (cborFormat) appendUint(b []byte, v uint64) []byte {
+	return appendCBORHead(b, cborUint, v)
+}
+
+func (cborFormat) appendFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xfb), math.Float64bits(v))
+}
+
+func (cborFormat) appendString(b []byte, s string) []byte {
+	return append(appendCBORHead(b, cborText, uint64(len(s))), s...)
+}
+
+func (cborFormat) appendArray(b []byte, n int) []byte {
+	return appendCBORHead(b, cborArray, uint64(n))
+}
+
+func (cborFormat) appendMap(b []byte, n int) []byte {
+	return appendCBORHead(b, cborMap, uint64(n))
+}
+
+// readHead 读取数据项头部, 返回主类型、附加信息与参数; 附加信息为 31 时表示不定长
+func (cborFormat) readHead(d *binaryReader) (major, info byte, arg uint64, err error) {
+	c, err := d.byte()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = c>>5, c&0x1f
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		arg, err = d.uint(1 << (info - 24))
+	case info == cborIndefinite && major >= cborBytes && major <= cborMap:
+	case info == cborIndefinite && major == cborSimple:
+		err = fmt.Errorf("%w: unexpected break", ErrBinaryCodec)
+	default:
+		err = fmt.Errorf("%w: invalid additional information %d", ErrBinaryCodec, info)
+	}
+	return major, info, arg, err
+}
+
+func (f cborFormat) decode(d *binaryReader, enc *jsontext.Encoder, depth int) error {
+	if depth > maxBinaryDepth {
+		return fmt.Errorf("%w: nesting exceeds %d levels", ErrBinaryCodec, maxBinaryDepth)
+	}
+	major, info, arg, err := f.readHead(d)
+	if err != nil {
+		return err
+	}
+	switch major {
+	case cborUint:
+		return enc.WriteToken(jsontext.Uint(arg))
+	case cborNegInt:
+		if arg <= math.MaxInt64 {
+			return enc.WriteToken(jsontext.Int(-1 - int64(arg)))
+		}
+		n := new(big.Int).SetUint64(arg)
+		n.Add(n, big.NewInt(1)).Neg(n)
+		return enc.WriteValue(jsontext.Value(n.String()))
+	case cborBytes, cborText:
+		data, err := f.readString(d, major, info, arg)
+		if err != nil {
+			return err
+		}
+		if major == cborText {
+			return writeBinaryString(enc, data)
+		}
+		return writeBinaryBytes(enc, data)
+	case cborArray:
+		if err := enc.WriteToken(jsontext.BeginArray); err != nil {
+			return err
+		}
+		if err := f.decodeItems(d, info, arg, 1, func() error { return f.decode(d, enc, depth+1) }); err != nil {
+			return err
+		}
+		return enc.WriteToken(jsontext.EndArray)
+	case cborMap:
+		if err := enc.WriteToken(jsontext.BeginObject); err != nil {
+			return err
+		}
+		err := f.decodeItems(d, info, arg, 2, func() error {
+			key, err := f.decodeKey(d)
+			if err != nil {
+				return err
+			}
+			if err := writeBinaryKey(enc, key); err != nil {
+				return err
+			}
+			return f.decode(d, enc, depth+1)
+		})
+		if err != nil {
+			return err
+		}
+		return enc.WriteToken(jsontext.EndObject)
+	case cborTag:
+		return f.decodeTag(d, enc, arg, depth)
+	}
+
+	switch info {
+	case 20, 21:
+		return enc.WriteToken(jsontext.Bool(info == 21))
+	case 22, 23: // null 与 undefined
+		return enc.WriteToken(jsontext.Null)
+	case 25:
+		return writeBinaryFloat(enc, cborHalfFloat(uint16(arg)))
+	case 26:
+		return writeBinaryFloat(enc, float64(math.Float32frombits(uint32(arg))))
+	case 27:
+		return writeBinaryFloat(enc, math.Float64frombits(arg))
+	}
+	return fmt.Errorf("%w: unsupported simple value %d", ErrBinaryCodec, arg)
+}
+
+// decodeItems 对定长或不定长容器的每个元素调用 fn, 每个元素至少占 minSize 字节
+func (cborFormat) decodeItems(d *binaryReader, info byte, arg uint64, minSize uint64, fn func() error) error {
+	if info != cborIndefinite {
+		n, err := d.count(arg, minSize)
+		if err != nil {
+			return err
+		}
+		for range n {
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if c == cborBreak {
+			d.pos++
+			return nil
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+}
+
+// readString 读取定长或由定长分块组成的不定长字符串
+func (f cborFormat) readString(d *binaryReader, major, info byte, arg uint64) ([]byte, error) {
+	if info != cborIndefinite {
+		return d.bytes(arg)
+	}
+	var out []byte
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return nil, err
+		}
+		if c == cborBreak {
+			d.pos++
+			return out, nil
+		}
+		m, ci, n, err := f.readHead(d)
+		if err != nil {
+			return nil, err
+		}
+		if m != major || ci == cborIndefinite {
+			return nil, fmt.Errorf("%w: invalid chunk in indefinite-length string", ErrBinaryCodec)
+		}
+		chunk, err := d.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+	}
+}
+
+// decodeKey 读取 map 的键, 只接受字符串与整数
+func (f cborFormat) decodeKey(d *binaryReader) (any, error) {
+	major, info, arg, err := f.readHead(d)
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborText:
+		s, err := f.readString(d, major, info, arg)
+		return string(s), err
+	case cborUint:
+		return arg, nil
+	case cborNegInt:
+		if arg <= math.MaxInt64 {
+			return -1 - int64(arg), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: map keys must be strings or integers", ErrBinaryCodec)
+}
+
+// decodeTag 处理标签: 0 (日期时间字符串) 与 1 (Unix 时间戳) 转换为 RFC 3339 字符串, 其余标签忽略并解码其内容
+func (f cborFormat) decodeTag(d *binaryReader, enc *jsontext.Encoder, tag uint64, depth int) error {
+	if tag != 1 {
+		return f.decode(d, enc, depth+1)
+	}
+	major, info, arg, err := f.readHead(d)
+	if err != nil {
+		return err
+	}
+	var t time.Time
+	switch {
+	case major == cborUint:
+		if arg > math.MaxInt64 {
+			return fmt.Errorf("%w: timestamp out of range", ErrBinaryCodec)
+		}
+		t = time.Unix(int64(arg), 0)
+	case major == cborNegInt && arg < math.MaxInt64:
+		t = time.Unix(-1-int64(arg), 0)
+	case major == cborSimple && info >= 25 && info <= 27:
+		var v float64
+		switch info {
+		case 25:
+			v = cborHalfFloat(uint16(arg))
+		case 26:
+			v = float64(math.Float32frombits(uint32(arg)))
+		default:
+			v = math.Float64frombits(arg)
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) > 1<<62 {
+			return fmt.Errorf("%w: invalid timestamp %s", ErrBinaryCodec, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		sec, frac := math.Modf(v)
+		t = time.Unix(int64(sec), int64(frac*1e9))
+	default:
+		return fmt.Errorf("%w: invalid timestamp", ErrBinaryCodec)
+	}
+	return writeBinaryTime(enc, t)
+}
+
+// cborHalfFloat 将 IEEE 754 半精度浮点数转换为 float64
+func cborHalfFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
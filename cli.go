@@ -0,0 +1,267 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-json-experiment/json"
+)
+
+// 以下 CLI 辅助函数供应用的命令行入口使用, 例如在 main 中实现 "app routes" 与 "app check" 子命令:
+//
+//	switch os.Args[1] {
+//	case "routes":
+//		touka.CLIListRoutes(r, os.Stdout)
+//	case "check":
+//		if err := touka.CLIValidateConfig(r, os.Stderr, opts...); err != nil {
+//			os.Exit(1)
+//		}
+//	case "openapi":
+//		touka.CLIGenerateOpenAPI(r, "openapi.json")
+//	}
+
+// CLIListRoutes 以表格形式将已注册的路由按路径与方法排序后写入 w, POLICY 列为路由上声明的 CORS、认证与限流策略
+func CLIListRoutes(engine *Engine, w io.Writer) error {
+	routes := sortedRoutes(engine)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER\tGROUP\tPOLICY\tSOURCE")
+	for _, r := range routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Method, r.Path, r.Handler, cmp.Or(r.Group, "/"), cmp.Or(policySummary(r.Meta), "-"), r.Source)
+	}
+	return tw.Flush()
+}
+
+// CLIValidateConfig 调用 engine.Validate 检查引擎配置与启动选项, 每个问题与 ConfigWarnings 的警告写为 w 中的一行.
+// 存在问题时返回汇总的错误, 命令行入口可以据此以非零状态退出
+func CLIValidateConfig(engine *Engine, w io.Writer, opts ...RunOption) error {
+	err := engine.Validate(opts...)
+	for _, warning := range engine.ConfigWarnings() {
+		fmt.Fprintf(w, "warning: %s\n", warning)
+	}
+	if err == nil {
+		_, werr := fmt.Fprintln(w, "ok: configuration is valid")
+		return werr
+	}
+	problems := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		problems = joined.Unwrap()
+	}
+	for _, p := range problems {
+		fmt.Fprintf(w, "error: %v\n", p)
+	}
+	return err
+}
+
+// CLIGenerateOpenAPI 根据已注册的路由生成 OpenAPI 3.1 文档骨架并写入 path, path 为 "-" 时写入标准输出.
+// 文档包含每个路由的路径参数、通过 Consumes 声明的请求体媒体类型以及通过 Describe 附加的说明与示例,
+// 请求与响应的结构需要另行补充
+func CLIGenerateOpenAPI(engine *Engine, path string) error {
+	data, err := json.Marshal(openAPIDocument(engine), json.Deterministic(true))
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// sortedRoutes 返回按路径与方法排序的路由副本
+func sortedRoutes(engine *Engine) []RouteInfo {
+	routes := slices.Clone(engine.GetRouterInfo())
+	slices.SortStableFunc(routes, func(a, b RouteInfo) int {
+		return cmp.Or(strings.Compare(a.Path, b.Path), strings.Compare(a.Method, b.Method))
+	})
+	return routes
+}
+
+// openAPIMethods 为 OpenAPI Path Item 支持的方法
+var openAPIMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+func openAPIDocument(engine *Engine) map[string]any {
+	title := "API"
+	if len(os.Args) > 0 {
+		title = filepath.Base(os.Args[0])
+	}
+	version := "0.0.0"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+
+	paths := make(map[string]any)
+	for _, r := range sortedRoutes(engine) {
+		if !slices.Contains(openAPIMethods, r.Method) {
+			continue
+		}
+		op := map[string]any{
+			"x-handler": r.Handler,
+			"responses": map[string]any{
+				"default": map[string]any{"description": "response"},
+			},
+		}
+		doc, hasDoc := r.Doc()
+		content := make(map[string]any)
+		if mediaTypes, ok := r.Meta[MetaConsumes].([]string); ok {
+			for _, mt := range mediaTypes {
+				content[mt] = map[string]any{}
+			}
+		}
+		if hasDoc && doc.Request != nil {
+			content[doc.Request.contentType()] = openAPIExample(*doc.Request)
+		}
+		types, hasTypes := r.Types()
+		if hasTypes && openAPIHasBody(r.Method) {
+			openAPISetSchema(content, types.Request)
+		}
+		if len(content) > 0 {
+			body := map[string]any{"required": true, "content": content}
+			if hasDoc && doc.Request != nil && doc.Request.Description != "" {
+				body["description"] = doc.Request.Description
+			}
+			op["requestBody"] = body
+		}
+		if hasDoc {
+			if doc.Summary != "" {
+				op["summary"] = doc.Summary
+			}
+			if doc.Description != "" {
+				op["description"] = doc.Description
+			}
+			if len(doc.Tags) > 0 {
+				op["tags"] = doc.Tags
+			}
+			if len(doc.Responses) > 0 {
+				responses := make(map[string]any, len(doc.Responses))
+				for code, ex := range doc.Responses {
+					resp := map[string]any{"description": cmp.Or(ex.Description, http.StatusText(code), "response")}
+					if ex.Value != nil {
+						resp["content"] = map[string]any{ex.contentType(): openAPIExample(ex)}
+					}
+					responses[strconv.Itoa(code)] = resp
+				}
+				op["responses"] = responses
+			}
+		}
+		if hasTypes && types.Status != http.StatusNoContent {
+			responses := op["responses"].(map[string]any)
+			key := strconv.Itoa(types.Status)
+			resp, _ := responses[key].(map[string]any)
+			if resp == nil {
+				resp = map[string]any{"description": cmp.Or(http.StatusText(types.Status), "response")}
+				responses[key] = resp
+			}
+			respContent, _ := resp["content"].(map[string]any)
+			if respContent == nil {
+				respContent = make(map[string]any)
+			}
+			if openAPISetSchema(respContent, types.Response) {
+				resp["content"] = respContent
+			}
+		}
+		// 含可选参数的路由按展开后的每个路径分别列出
+		routePaths, _ := expandOptionalParams(r.Path)
+		if routePaths == nil {
+			routePaths = []string{r.Path}
+		}
+		for _, routePath := range routePaths {
+			path, params := openAPIPath(routePath)
+			op := op
+			if len(params) > 0 {
+				op = maps.Clone(op)
+				op["parameters"] = params
+			}
+			item, _ := paths[path].(map[string]any)
+			if item == nil {
+				item = make(map[string]any)
+				paths[path] = item
+			}
+			item[strings.ToLower(r.Method)] = op
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info":    map[string]any{"title": title, "version": version},
+		"paths":   paths,
+	}
+}
+
+// openAPIHasBody 报告类型化处理器在该方法下是否从请求体绑定, 其余方法从查询参数绑定
+func openAPIHasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// openAPISetSchema 将 t 的 schema 写入 content 的 application/json 媒体类型, 保留已有示例.
+// t 为空结构体时不写入并返回 false
+func openAPISetSchema(content map[string]any, t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t.NumField() == 0 {
+		return false
+	}
+	media, _ := content["application/json"].(map[string]any)
+	if media == nil {
+		media = make(map[string]any)
+	} else {
+		media = maps.Clone(media)
+	}
+	media["schema"] = jsonSchemaForType(t, make(map[reflect.Type]bool))
+	content["application/json"] = media
+	return true
+}
+
+// openAPIExample 返回 Media Type 对象, 示例为空时只声明媒体类型
+func openAPIExample(ex RouteExample) map[string]any {
+	if ex.Value == nil {
+		return map[string]any{}
+	}
+	return map[string]any{"example": ex.Value}
+}
+
+// openAPIPath 将 /users/:id/*file 形式的路由路径转换为 /users/{id}/{file}, 并返回对应的路径参数
+func openAPIPath(path string) (string, []any) {
+	segments := strings.Split(path, "/")
+	var params []any
+	for i, seg := range segments {
+		if seg == "" || (seg[0] != ':' && seg[0] != '*') {
+			continue
+		}
+		name := seg[1:]
+		if name == "" {
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		param := map[string]any{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		}
+		if seg[0] == '*' {
+			param["description"] = "remaining path, may contain /"
+		}
+		params = append(params, param)
+	}
+	return strings.Join(segments, "/"), params
+}
@@ -0,0 +1,261 @@
+package touka
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+)
+
+func cliTestHandler(c *Context) {}
+
+func TestCLIListRoutes(t *testing.T) {
+	r := New()
+	r.GET("/users/:id", cliTestHandler)
+	r.Group("/api").POST("/items", cliTestHandler)
+
+	var buf bytes.Buffer
+	if err := CLIListRoutes(r, &buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "METHOD") {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[1], "/api/items") || !strings.Contains(lines[1], "cliTestHandler") {
+		t.Errorf("routes should be sorted by path, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "GET") || !strings.Contains(lines[2], "/users/:id") {
+		t.Errorf("unexpected row %q", lines[2])
+	}
+}
+
+func TestCLIValidateConfig(t *testing.T) {
+	r := New()
+	r.GET("/", cliTestHandler)
+	var buf bytes.Buffer
+	if err := CLIValidateConfig(r, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "warning: ForwardByClientIP") || !strings.HasSuffix(buf.String(), "ok: configuration is valid\n") {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	if err := r.SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := CLIValidateConfig(r, &buf); err != nil || !strings.HasPrefix(buf.String(), "ok:") {
+		t.Errorf("output = %q (%v)", buf.String(), err)
+	}
+
+	r.GET("/broken", nil)
+	r.unMatchFS.ServeUnmatchedAsFS = true
+	buf.Reset()
+	err := CLIValidateConfig(r, &buf)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if n := strings.Count(buf.String(), "error: "); n != 2 {
+		t.Errorf("expected 2 reported problems, got %d:\n%s", n, buf.String())
+	}
+	if !strings.Contains(buf.String(), "/broken") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestCLIGenerateOpenAPI(t *testing.T) {
+	r := New()
+	r.GET("/files/*path", cliTestHandler)
+	r.GET("/users/:id", cliTestHandler)
+	r.WithMeta(Consumes("application/json")).PUT("/users/:id", cliTestHandler)
+	r.GET("/archive/:year/:month?", cliTestHandler)
+	r.Handle("PROPFIND", "/dav", cliTestHandler)
+
+	path := filepath.Join(t.TempDir(), "openapi.json")
+	if err := CLIGenerateOpenAPI(r, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			Parameters []struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+			RequestBody *struct {
+				Content map[string]any `json:"content"`
+			} `json:"requestBody"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" || len(doc.Paths) != 4 {
+		t.Fatalf("unexpected document: %s", data)
+	}
+	user := doc.Paths["/users/{id}"]
+	if len(user) != 2 || len(user["get"].Parameters) != 1 || user["get"].Parameters[0].Name != "id" {
+		t.Errorf("unexpected /users/{id} item: %+v", user)
+	}
+	if user["get"].RequestBody != nil || user["put"].RequestBody == nil || user["put"].RequestBody.Content["application/json"] == nil {
+		t.Errorf("requestBody should follow Consumes metadata: %+v", user)
+	}
+	if p := doc.Paths["/files/{path}"][strings.ToLower(http.MethodGet)].Parameters; len(p) != 1 || p[0].Name != "path" {
+		t.Errorf("catch-all parameter = %+v", p)
+	}
+	if p := doc.Paths["/archive/{year}/{month}"]["get"].Parameters; len(p) != 2 || p[1].Name != "month" {
+		t.Errorf("optional parameter = %+v", p)
+	}
+	if p := doc.Paths["/archive/{year}"]["get"].Parameters; len(p) != 1 {
+		t.Errorf("optional parameter omitted = %+v", p)
+	}
+}
+
+func TestCLIGenerateOpenAPIRouteDoc(t *testing.T) {
+	r := New()
+	r.WithMeta(Describe(RouteDoc{
+		Summary: "create user",
+		Tags:    []string{"users"},
+		Request: &RouteExample{Description: "new user", Value: H{"name": "alice"}},
+		Responses: map[int]RouteExample{
+			http.StatusCreated:  {Value: H{"id": 1}},
+			http.StatusConflict: {Description: "name taken"},
+		},
+	})).POST("/users", cliTestHandler)
+
+	if doc, ok := r.GetRouterInfo()[0].Doc(); !ok || doc.Summary != "create user" {
+		t.Fatalf("RouteInfo.Doc() = %+v, %v", doc, ok)
+	}
+
+	path := filepath.Join(t.TempDir(), "openapi.json")
+	if err := CLIGenerateOpenAPI(r, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Summary     string   `json:"summary"`
+			Tags        []string `json:"tags"`
+			RequestBody struct {
+				Description string `json:"description"`
+				Content     map[string]struct {
+					Example map[string]any `json:"example"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Description string `json:"description"`
+				Content     map[string]struct {
+					Example map[string]any `json:"example"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	op := doc.Paths["/users"]["post"]
+	if op.Summary != "create user" || len(op.Tags) != 1 || op.RequestBody.Description != "new user" {
+		t.Errorf("unexpected operation: %s", data)
+	}
+	if op.RequestBody.Content["application/json"].Example["name"] != "alice" {
+		t.Errorf("request example missing: %s", data)
+	}
+	if op.Responses["201"].Description != "Created" || op.Responses["201"].Content["application/json"].Example["id"] != 1.0 {
+		t.Errorf("201 response = %+v", op.Responses["201"])
+	}
+	if op.Responses["409"].Description != "name taken" || op.Responses["409"].Content != nil {
+		t.Errorf("409 response = %+v", op.Responses["409"])
+	}
+	if _, ok := op.Responses["default"]; ok {
+		t.Error("documented responses should replace the default response")
+	}
+}
+
+func TestCLIGenerateOpenAPITypedHandler(t *testing.T) {
+	type createReq struct {
+		Name string `json:"name"`
+	}
+	type createResp struct {
+		ID int `json:"id"`
+	}
+	r := New()
+	create := JSONHandlerWithStatus(http.StatusCreated, func(c *Context, req createReq) (createResp, error) {
+		return createResp{ID: 1}, nil
+	})
+	r.WithMeta(Describe(RouteDoc{
+		Responses: map[int]RouteExample{http.StatusCreated: {Value: H{"id": 1}}},
+	})).POST("/users", create)
+	r.GET("/users", JSONHandler(func(c *Context, req struct{}) ([]createResp, error) {
+		return nil, nil
+	}))
+
+	var types RouteTypes
+	var ok bool
+	for _, info := range r.GetRouterInfo() {
+		if info.Method == http.MethodPost {
+			types, ok = info.Types()
+		}
+	}
+	if !ok || types.Request != reflect.TypeFor[createReq]() || types.Response != reflect.TypeFor[createResp]() || types.Status != http.StatusCreated {
+		t.Fatalf("RouteInfo.Types() = %+v, %v", types, ok)
+	}
+	if _, err := json.Marshal(r.GetRouterInfo()); err != nil {
+		t.Fatalf("route info with types should marshal: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "openapi.json")
+	if err := CLIGenerateOpenAPI(r, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type media struct {
+		Schema  *JSONSchema    `json:"schema"`
+		Example map[string]any `json:"example"`
+	}
+	var doc struct {
+		Paths map[string]map[string]struct {
+			RequestBody *struct {
+				Content map[string]media `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Content map[string]media `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	post := doc.Paths["/users"]["post"]
+	if post.RequestBody == nil || post.RequestBody.Content["application/json"].Schema.Properties["name"] == nil {
+		t.Errorf("request schema missing: %s", data)
+	}
+	created := post.Responses["201"].Content["application/json"]
+	if created.Schema == nil || created.Schema.Properties["id"] == nil || created.Example["id"] != 1.0 {
+		t.Errorf("201 response should carry both schema and example: %s", data)
+	}
+
+	get := doc.Paths["/users"]["get"]
+	if get.RequestBody != nil {
+		t.Errorf("GET should not have a request body: %s", data)
+	}
+	if s := get.Responses["200"].Content["application/json"].Schema; s == nil || s.Type != "array" {
+		t.Errorf("200 response schema = %+v", s)
+	}
+}
@@ -0,0 +1,94 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"sync"
+)
+
+// clientGoneWatch 保存一次请求的断开回调. 每个请求单独分配, 不随 Context 复用,
+// 因此请求结束后迟到的触发不会影响下一个请求
+type clientGoneWatch struct {
+	mu      sync.Mutex
+	fns     []func()
+	fired   bool
+	stopped bool
+	stop    func() bool
+}
+
+// fire 执行所有回调, 只生效一次; 请求已结束时不执行
+func (w *clientGoneWatch) fire() {
+	w.mu.Lock()
+	if w.fired || w.stopped {
+		w.mu.Unlock()
+		return
+	}
+	w.fired = true
+	fns := w.fns
+	w.fns = nil
+	w.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// close 在请求结束时停止监听
+func (w *clientGoneWatch) close() {
+	w.mu.Lock()
+	w.stopped = true
+	w.fns = nil
+	w.mu.Unlock()
+	w.stop()
+}
+
+// OnClientGone 注册客户端断开时执行的回调, 用于及时取消报表生成、上游请求等耗时工作:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	c.OnClientGone(cancel)
+//	report, err := generate(ctx)
+//
+// 请求 context 被取消 (连接断开、HTTP/2 流被重置或服务器关闭) 与写入响应失败都视为客户端断开.
+// 回调至多执行一次, 可能在其他 goroutine 中与处理器并发执行, 应尽快返回; 处理器正常返回后不再执行.
+// 注册时客户端已断开的, 回调会立即在新的 goroutine 中执行
+func (c *Context) OnClientGone(fn func()) {
+	if fn == nil {
+		return
+	}
+	rw := c.rootWriter
+	if rw == nil {
+		// 未经 reset 的 Context (例如手动构造) 只能监听请求 context
+		context.AfterFunc(c.Request.Context(), fn)
+		return
+	}
+	if rw.gone == nil {
+		w := &clientGoneWatch{}
+		w.stop = context.AfterFunc(c.Request.Context(), w.fire)
+		rw.gone = w
+	}
+	w := rw.gone
+	w.mu.Lock()
+	if w.fired || w.stopped {
+		w.mu.Unlock()
+		if w.fired {
+			go fn()
+		}
+		return
+	}
+	w.fns = append(w.fns, fn)
+	w.mu.Unlock()
+}
+
+// ClientGone 返回客户端是否已经断开 (请求 context 已取消或响应写入失败)
+func (c *Context) ClientGone() bool {
+	if rw := c.rootWriter; rw != nil && rw.gone != nil {
+		rw.gone.mu.Lock()
+		defer rw.gone.mu.Unlock()
+		if rw.gone.fired {
+			return true
+		}
+	}
+	return c.Request.Context().Err() != nil
+}
@@ -0,0 +1,76 @@
+package touka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingWriter 模拟连接已断开的响应写入器
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write: broken pipe")
+}
+
+func TestOnClientGoneContextCanceled(t *testing.T) {
+	engine := New()
+	gone := make(chan struct{})
+	engine.GET("/report", func(c *Context) {
+		c.OnClientGone(func() { close(gone) })
+		select {
+		case <-gone:
+		case <-time.After(time.Second):
+			t.Error("callback did not run after the request context was canceled")
+		}
+		if !c.ClientGone() {
+			t.Error("expected ClientGone to report the disconnect")
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/report", nil).WithContext(ctx)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestOnClientGoneWriteError(t *testing.T) {
+	engine := New()
+	var calls atomic.Int32
+	engine.GET("/stream", func(c *Context) {
+		c.OnClientGone(func() { calls.Add(1) })
+		c.OnClientGone(func() { calls.Add(1) })
+		_, _ = c.Writer.Write([]byte("a"))
+		_, _ = c.Writer.Write([]byte("b"))
+		if !c.ClientGone() {
+			t.Error("expected a failed write to mark the client as gone")
+		}
+	})
+	engine.ServeHTTP(failingWriter{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected each callback to run once, got %d calls", n)
+	}
+}
+
+func TestOnClientGoneNotCalledAfterReturn(t *testing.T) {
+	engine := New()
+	var called atomic.Bool
+	engine.GET("/ok", func(c *Context) {
+		c.OnClientGone(func() { called.Store(true) })
+		c.String(http.StatusOK, "done")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil).WithContext(ctx))
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	if called.Load() {
+		t.Fatal("callback must not run once the handler has returned")
+	}
+}
@@ -0,0 +1,93 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETagMatch 设置 ETag 响应头并评估 If-None-Match.
+// 对 GET/HEAD 请求匹配时写入 304 并中止处理链; 对其他方法匹配时写入 412.
+// 返回 true 表示响应已写出, 处理器应直接返回:
+//
+//	if c.ETagMatch(etag) {
+//		return
+//	}
+//	c.JSON(http.StatusOK, resource)
+//
+// etag 未加引号时会自动加上, 弱校验器可传入 W/"..." 形式
+func (c *Context) ETagMatch(etag string) bool {
+	etag = quoteETag(etag)
+	c.SetHeader("ETag", etag)
+
+	ifNoneMatch := c.GetReqHeader("If-None-Match")
+	if ifNoneMatch == "" || !etagListMatches(ifNoneMatch, etag) {
+		return false
+	}
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		c.writeNotModified()
+	} else {
+		c.AbortWithStatus(http.StatusPreconditionFailed)
+	}
+	return true
+}
+
+// LastModified 设置 Last-Modified 响应头并评估 If-Modified-Since.
+// 仅对 GET/HEAD 生效, 且请求携带 If-None-Match 时忽略 If-Modified-Since (RFC 9110 13.1.3).
+// 资源未修改时写入 304 并中止处理链, 返回 true
+func (c *Context) LastModified(t time.Time) bool {
+	if t.IsZero() || t.Equal(time.Unix(0, 0)) {
+		return false
+	}
+	t = t.UTC().Truncate(time.Second)
+	c.SetHeader("Last-Modified", t.Format(http.TimeFormat))
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	if c.GetReqHeader("If-None-Match") != "" {
+		return false
+	}
+	ims := c.GetReqHeader("If-Modified-Since")
+	if ims == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil || t.After(since) {
+		return false
+	}
+	c.writeNotModified()
+	return true
+}
+
+// writeNotModified 写入 304, 并移除与响应体相关的头部
+func (c *Context) writeNotModified() {
+	h := c.Writer.Header()
+	delete(h, "Content-Type")
+	delete(h, "Content-Length")
+	delete(h, "Content-Encoding")
+	c.AbortWithStatus(http.StatusNotModified)
+}
+
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// etagListMatches 以弱比较判断 If-None-Match 列表中是否包含 etag
+func etagListMatches(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
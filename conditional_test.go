@@ -0,0 +1,56 @@
+package touka
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestETagMatch(t *testing.T) {
+	engine := New()
+	handler := func(c *Context) {
+		if c.ETagMatch("v1") {
+			return
+		}
+		c.String(http.StatusOK, "resource")
+	}
+	engine.GET("/item", handler)
+	engine.PUT("/item", handler)
+
+	rr := PerformRequest(engine, http.MethodGet, "/item", nil, nil)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"v1"` {
+		t.Fatalf("expected 200 with ETag, got %d %q", rr.Code, rr.Header().Get("ETag"))
+	}
+
+	rr = PerformRequest(engine, http.MethodGet, "/item", nil, http.Header{"If-None-Match": []string{`"v0", W/"v1"`}})
+	if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Fatalf("expected 304 without body, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodPut, "/item", nil, http.Header{"If-None-Match": []string{"*"}})
+	if rr.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for unsafe method, got %d", rr.Code)
+	}
+}
+
+func TestLastModified(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC)
+	engine := New()
+	engine.GET("/item", func(c *Context) {
+		if c.LastModified(modified) {
+			return
+		}
+		c.String(http.StatusOK, "resource")
+	})
+
+	rr := PerformRequest(engine, http.MethodGet, "/item", nil, http.Header{"If-Modified-Since": []string{modified.Format(http.TimeFormat)}})
+	if rr.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", rr.Code)
+	}
+
+	earlier := modified.Add(-time.Hour).Format(http.TimeFormat)
+	rr = PerformRequest(engine, http.MethodGet, "/item", nil, http.Header{"If-Modified-Since": []string{earlier}})
+	if rr.Code != http.StatusOK || rr.Header().Get("Last-Modified") != modified.Format(http.TimeFormat) {
+		t.Fatalf("expected 200 with Last-Modified, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,148 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ErrTooManyConns 表示客户端 IP 的打开连接数超过了上限
+var ErrTooManyConns = errors.New("too many open connections from client")
+
+// ConnStats 是引擎服务器连接状态的快照
+type ConnStats struct {
+	Open     int    // 当前打开的连接数 (新建、活跃与空闲)
+	Active   int    // 正在处理请求的连接数
+	Idle     int    // keep-alive 空闲连接数
+	Accepted uint64 // 累计接受的连接数
+	Hijacked uint64 // 累计被劫持 (WebSocket 等) 的连接数, 劫持后不再计入 Open
+	Closed   uint64 // 累计关闭的连接数
+}
+
+// connTracker 通过 http.Server.ConnState 统计连接状态
+type connTracker struct {
+	mu     sync.Mutex
+	states map[net.Conn]http.ConnState
+	perIP  map[string]int
+	stats  ConnStats
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{
+		states: make(map[net.Conn]http.ConnState),
+		perIP:  make(map[string]int),
+	}
+}
+
+func (t *connTracker) track(conn net.Conn, state http.ConnState) {
+	ip := connIP(conn)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, known := t.states[conn]
+	if known {
+		t.adjust(prev, -1)
+	}
+	switch state {
+	case http.StateNew:
+		t.stats.Accepted++
+		t.perIP[ip]++
+	case http.StateHijacked, http.StateClosed:
+		if state == http.StateHijacked {
+			t.stats.Hijacked++
+		} else {
+			t.stats.Closed++
+		}
+		delete(t.states, conn)
+		if known {
+			if t.perIP[ip]--; t.perIP[ip] <= 0 {
+				delete(t.perIP, ip)
+			}
+		}
+		return
+	}
+	t.states[conn] = state
+	t.adjust(state, 1)
+}
+
+func (t *connTracker) adjust(state http.ConnState, delta int) {
+	t.stats.Open += delta
+	switch state {
+	case http.StateActive:
+		t.stats.Active += delta
+	case http.StateIdle:
+		t.stats.Idle += delta
+	}
+}
+
+func (t *connTracker) snapshot() ConnStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+func (t *connTracker) fromIP(ip string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.perIP[ip]
+}
+
+func connIP(conn net.Conn) string {
+	addr := conn.RemoteAddr()
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// TrackConns 让引擎统计 srv 的连接状态, 并保留 srv 上已有的 ConnState 回调.
+// Run 会自动为主服务器调用; 自行创建 http.Server 时需要手动调用
+func (engine *Engine) TrackConns(srv *http.Server) {
+	next := srv.ConnState
+	srv.ConnState = func(conn net.Conn, state http.ConnState) {
+		engine.conns.track(conn, state)
+		if next != nil {
+			next(conn, state)
+		}
+	}
+}
+
+// ConnStats 返回当前的连接统计
+func (engine *Engine) ConnStats() ConnStats {
+	return engine.conns.snapshot()
+}
+
+// ConnsFromIP 返回来自 ip 的打开连接数. ip 为 TCP 对端地址, 不经过 X-Forwarded-For 等头部解析
+func (engine *Engine) ConnsFromIP(ip string) int {
+	return engine.conns.fromIP(ip)
+}
+
+// LimitConnsPerIP 返回在同一对端 IP 的打开连接数超过 limit 时以 503 拒绝请求并关闭连接的中间件.
+// 计数基于 TCP 对端地址, 位于反向代理之后时所有连接都来自代理, 此时不应使用
+func LimitConnsPerIP(limit int) HandlerFunc {
+	if limit <= 0 {
+		panic("touka: LimitConnsPerIP limit must be positive")
+	}
+	return func(c *Context) {
+		ip, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+		if err != nil {
+			ip = c.Request.RemoteAddr
+		}
+		if c.engine.ConnsFromIP(ip) > limit {
+			c.SetHeader("Connection", "close")
+			c.AddError(ErrTooManyConns)
+			c.ErrorUseHandle(http.StatusServiceUnavailable, ErrTooManyConns)
+			return
+		}
+		c.Next()
+	}
+}
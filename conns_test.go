@@ -0,0 +1,101 @@
+package touka
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitConnStats(t *testing.T, engine *Engine, cond func(ConnStats) bool) ConnStats {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stats := engine.ConnStats()
+		if cond(stats) {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("connection stats did not converge: %+v", stats)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConnStatsTracksServerConns(t *testing.T) {
+	engine := New()
+	engine.GET("/", func(c *Context) { c.String(http.StatusOK, "ok") })
+	engine.GET("/hijack", func(c *Context) {
+		conn, _, err := c.Writer.Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	})
+
+	var seen atomic.Int32
+	srv := httptest.NewUnstartedServer(engine)
+	srv.Config.ConnState = func(net.Conn, http.ConnState) { seen.Add(1) }
+	engine.TrackConns(srv.Config)
+	srv.Start()
+	defer srv.Close()
+
+	client := srv.Client()
+	resp, err := client.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	stats := waitConnStats(t, engine, func(s ConnStats) bool { return s.Idle == 1 })
+	if stats.Open != 1 || stats.Active != 0 || stats.Accepted != 1 {
+		t.Fatalf("unexpected stats with idle keep-alive conn: %+v", stats)
+	}
+	if engine.ConnsFromIP("127.0.0.1") != 1 {
+		t.Fatalf("expected one conn from 127.0.0.1, got %d", engine.ConnsFromIP("127.0.0.1"))
+	}
+	if seen.Load() == 0 {
+		t.Fatal("existing ConnState hook was not preserved")
+	}
+
+	client.CloseIdleConnections()
+	waitConnStats(t, engine, func(s ConnStats) bool { return s.Open == 0 && s.Closed == 1 })
+	if engine.ConnsFromIP("127.0.0.1") != 0 {
+		t.Fatalf("per-IP count should drop to zero, got %d", engine.ConnsFromIP("127.0.0.1"))
+	}
+
+	if resp, err := http.Get(srv.URL + "/hijack"); err == nil {
+		resp.Body.Close()
+	}
+	waitConnStats(t, engine, func(s ConnStats) bool { return s.Hijacked == 1 && s.Open == 0 })
+}
+
+func TestLimitConnsPerIP(t *testing.T) {
+	engine := New()
+	engine.Use(LimitConnsPerIP(1))
+	engine.GET("/", func(c *Context) { c.String(http.StatusOK, "ok") })
+
+	c1, _ := net.Pipe()
+	c2, _ := net.Pipe()
+	for _, c := range []net.Conn{c1, c2} {
+		engine.conns.track(c, http.StateNew)
+	}
+	// net.Pipe 的地址为 "pipe", 作为同一来源计数
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "pipe"
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Connection") != "close" {
+		t.Fatalf("expected 503 with Connection: close, got %d %v", w.Code, w.Header())
+	}
+
+	engine.conns.track(c2, http.StateClosed)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 after a conn closed, got %d", w.Code)
+	}
+}
@@ -0,0 +1,80 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MetaConsumes 是声明路由接受的请求 Content-Type 的元数据键, 值为 []string
+const MetaConsumes = "touka.consumes"
+
+// Consumes 返回声明路由接受的请求 Content-Type 的元数据, 用于 WithMeta:
+//
+//	r.WithMeta(touka.Consumes("application/json", "application/*+json")).POST("/users", createUser)
+//
+// 媒体类型支持 type/* 与 */* 通配, 以及 application/*+json 形式的后缀通配
+func Consumes(mediaTypes ...string) (string, any) {
+	normalized := make([]string, 0, len(mediaTypes))
+	for _, mt := range mediaTypes {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(mt)))
+	}
+	return MetaConsumes, normalized
+}
+
+// EnforceContentType 返回一个中间件, 按路由元数据中通过 Consumes 声明的媒体类型检查请求体的 Content-Type,
+// 不匹配时返回 415. 对声明了媒体类型的 POST/PATCH 路由, 响应会携带 Accept-Post/Accept-Patch 头部.
+// 没有请求体或未声明媒体类型的请求不受影响, 可以作为全局中间件使用
+func EnforceContentType() HandlerFunc {
+	return func(c *Context) {
+		v, ok := c.RouteMetaValue(MetaConsumes)
+		allowed, _ := v.([]string)
+		if !ok || len(allowed) == 0 {
+			c.Next()
+			return
+		}
+
+		accept := strings.Join(allowed, ", ")
+		switch c.Request.Method {
+		case http.MethodPost:
+			c.SetHeader("Accept-Post", accept)
+		case http.MethodPatch:
+			c.SetHeader("Accept-Patch", accept)
+		}
+
+		if !hasRequestBody(c.Request) {
+			c.Next()
+			return
+		}
+		contentType := c.GetReqHeader("Content-Type")
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !mediaTypeAllowed(mediaType, allowed) {
+			c.ErrorUseHandle(http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %q, accepted: %s", contentType, accept))
+			return
+		}
+		c.Next()
+	}
+}
+
+// mediaTypeAllowed 判断 mediaType 是否匹配允许列表中的任一模式
+func mediaTypeAllowed(mediaType string, allowed []string) bool {
+	typ, sub, _ := strings.Cut(mediaType, "/")
+	for _, pattern := range allowed {
+		ptyp, psub, _ := strings.Cut(pattern, "/")
+		if ptyp != "*" && ptyp != typ {
+			continue
+		}
+		switch {
+		case psub == "*" || psub == sub:
+			return true
+		case strings.HasPrefix(psub, "*+") && strings.HasSuffix(sub, psub[1:]):
+			return true
+		}
+	}
+	return false
+}
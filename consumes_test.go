@@ -0,0 +1,83 @@
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEnforceContentType(t *testing.T) {
+	engine := New()
+	engine.Use(EnforceContentType())
+	api := engine.Group("/api").(MetaRouter).WithMeta(Consumes("application/json", "application/*+json"))
+	api.POST("/users", func(c *Context) { c.Status(http.StatusCreated) })
+	api.PATCH("/users/:id", func(c *Context) { c.Status(http.StatusNoContent) })
+	engine.POST("/upload", func(c *Context) { c.Status(http.StatusCreated) })
+
+	tests := []struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+		code        int
+	}{
+		{http.MethodPost, "/api/users", "application/json; charset=utf-8", "{}", http.StatusCreated},
+		{http.MethodPost, "/api/users", "application/merge-patch+json", "{}", http.StatusCreated},
+		{http.MethodPost, "/api/users", "text/plain", "x", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/api/users", "", "x", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/api/users", "", "", http.StatusCreated},
+		{http.MethodPatch, "/api/users/1", "application/xml", "<a/>", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/upload", "image/png", "x", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		headers := http.Header{}
+		if tt.contentType != "" {
+			headers.Set("Content-Type", tt.contentType)
+		}
+		w := PerformRequest(engine, tt.method, tt.path, strings.NewReader(tt.body), headers)
+		if w.Code != tt.code {
+			t.Errorf("%s %s (%q): expected %d, got %d", tt.method, tt.path, tt.contentType, tt.code, w.Code)
+		}
+	}
+
+	w := PerformRequest(engine, http.MethodPost, "/api/users", strings.NewReader("x"), http.Header{"Content-Type": {"text/plain"}})
+	if got := w.Header().Get("Accept-Post"); got != "application/json, application/*+json" {
+		t.Fatalf("unexpected Accept-Post %q", got)
+	}
+	w = PerformRequest(engine, http.MethodPatch, "/api/users/1", nil, nil)
+	if got := w.Header().Get("Accept-Patch"); got != "application/json, application/*+json" {
+		t.Fatalf("unexpected Accept-Patch %q", got)
+	}
+}
+
+func TestRouteMetaInheritance(t *testing.T) {
+	engine := New()
+	v1 := engine.Group("/v1").(MetaRouter).WithMeta("auth", "required")
+	v1.Group("/admin").(MetaRouter).WithMeta("role", "admin").GET("/stats", func(c *Context) {
+		c.JSON(http.StatusOK, c.RouteMeta())
+	})
+	v1.GET("/me", func(c *Context) {
+		if _, ok := c.RouteMetaValue("role"); ok {
+			t.Error("sibling routes must not see child group metadata")
+		}
+		c.Status(http.StatusOK)
+	})
+	engine.GET("/plain", func(c *Context) {
+		if c.RouteMeta() != nil {
+			t.Error("routes without metadata should return nil")
+		}
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/v1/admin/stats", nil, nil)
+	if body := w.Body.String(); !strings.Contains(body, `"auth":"required"`) || !strings.Contains(body, `"role":"admin"`) {
+		t.Fatalf("expected inherited metadata, got %s", body)
+	}
+	PerformRequest(engine, http.MethodGet, "/v1/me", nil, nil)
+	PerformRequest(engine, http.MethodGet, "/plain", nil, nil)
+
+	for _, info := range engine.GetRouterInfo() {
+		if info.Path == "/v1/admin/stats" && info.Meta["role"] != "admin" {
+			t.Fatalf("route info should expose metadata, got %v", info.Meta)
+		}
+	}
+}
@@ -10,25 +10,25 @@ import (
 	"encoding/gob"
 	"errors"
 	"fmt"
-	"html/template"
 	"io"
 	"math"
 	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/netip"
 	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/WJQSERVER/wanf"
-	"github.com/go-json-experiment/json"
 
-	"github.com/WJQSERVER-STUDIO/go-utils/iox"
 	"github.com/WJQSERVER-STUDIO/httpc"
 )
 
@@ -78,6 +78,22 @@ type Context struct {
 
 	allowedMethodsBuf []string
 	allowHeaderBuf    []byte
+
+	// logger 为请求级日志器, 为 nil 时使用引擎的日志器
+	logger Logger
+
+	// fullPath 为匹配到的路由模板, 例如 /users/:id
+	fullPath string
+
+	// rootWriter 为 reset 时的底层响应写入器, 中间件替换 c.Writer 后仍可据此检测写入错误
+	rootWriter *responseWriterImpl
+
+	// 请求的内存预算与已预留的字节数, memLimit 为 0 表示不限制
+	memLimit int64
+	memUsed  atomic.Int64
+
+	// observed 缓存 ObservabilityFilter 对请求的判定: 0 为尚未判定, 1 为记录, -1 为不记录
+	observed int8
 }
 
 // --- Context 相关方法实现 ---
@@ -88,8 +104,13 @@ func (c *Context) reset(w http.ResponseWriter, req *http.Request) {
 
 	if rw, ok := c.Writer.(*responseWriterImpl); ok && !rw.IsHijacked() {
 		rw.reset(w)
+		// 复用时 rootWriter 通常就是 rw, 跳过重复赋值以免在 GC 期间触发写屏障
+		if c.rootWriter != rw {
+			c.rootWriter = rw
+		}
 	} else {
 		c.Writer = newResponseWriter(w)
+		c.rootWriter, _ = c.Writer.(*responseWriterImpl)
 	}
 
 	c.Request = req
@@ -110,6 +131,19 @@ func (c *Context) reset(w http.ResponseWriter, req *http.Request) {
 	c.sameSite = http.SameSiteDefaultMode // 默认 SameSite 模式
 	c.MaxRequestBodySize = c.engine.GlobalMaxRequestBodySize
 	c.requestBodyPrepared = false
+	// 以下字段通常已为零值, 跳过写入以免在 GC 期间触发写屏障
+	if c.logger != nil {
+		c.logger = nil
+	}
+	if c.fullPath != "" {
+		c.fullPath = ""
+	}
+	c.memLimit = c.engine.memoryBudget
+	// 原子写入带有内存屏障, 只在上个请求预留过内存时清零
+	if c.memUsed.Load() != 0 {
+		c.memUsed.Store(0)
+	}
+	c.observed = 0
 
 	if cap(c.SkippedNodes) > 0 {
 		c.SkippedNodes = c.SkippedNodes[:0]
@@ -282,10 +316,15 @@ func (c *Context) prepareRequestBody() io.ReadCloser {
 // Query 从 URL 查询参数中获取值
 // 懒加载解析查询参数，并进行缓存
 func (c *Context) Query(key string) string {
+	return c.queryValues().Get(key)
+}
+
+// queryValues 返回解析后的查询参数, 首次访问时解析并缓存
+func (c *Context) queryValues() url.Values {
 	if c.queryCache == nil {
-		c.queryCache = c.Request.URL.Query() // 首次访问时解析并缓存
+		c.queryCache = c.Request.URL.Query()
 	}
-	return c.queryCache.Get(key)
+	return c.queryCache
 }
 
 // DefaultQuery 从 URL 查询参数中获取值，如果不存在则返回默认值
@@ -299,43 +338,55 @@ func (c *Context) DefaultQuery(key, defaultValue string) string {
 // PostForm 从 POST 请求体中获取表单值
 // 懒加载解析表单数据，并进行缓存
 func (c *Context) PostForm(key string) string {
-	if c.formCache == nil {
-		if c.MaxRequestBodySize > 0 {
-			c.prepareRequestBody()
+	return c.postFormValues().Get(key)
+}
+
+// postFormValues 返回解析后的请求体表单, 首次访问时解析并缓存; 解析失败时记录错误并返回空表单
+func (c *Context) postFormValues() url.Values {
+	if c.formCache != nil {
+		return c.formCache
+	}
+	c.bindRequestBody()
+	contentType := c.Request.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		c.AddError(fmt.Errorf("parse form error: %w", err))
+		c.formCache = make(url.Values)
+		return c.formCache
+	}
+
+	switch mediaType {
+	case "multipart/form-data":
+		if err := c.parseMultipartForm(); err != nil {
+			c.AddError(fmt.Errorf("parse form error: %w", err))
+			c.formCache = make(url.Values)
+			return c.formCache
 		}
-		contentType := c.Request.Header.Get("Content-Type")
-		mediaType, _, err := mime.ParseMediaType(contentType)
-		if err != nil {
+	case "application/x-www-form-urlencoded":
+		if err := c.Request.ParseForm(); err != nil {
 			c.AddError(fmt.Errorf("parse form error: %w", err))
 			c.formCache = make(url.Values)
-			return ""
+			return c.formCache
 		}
-
-		switch mediaType {
-		case "multipart/form-data":
-			if err := c.Request.ParseMultipartForm(defaultMemory); err != nil {
-				c.AddError(fmt.Errorf("parse form error: %w", err))
-				c.formCache = make(url.Values)
-				return ""
-			}
-		case "application/x-www-form-urlencoded":
-			if err := c.Request.ParseForm(); err != nil {
+	default:
+		if err := c.parseMultipartForm(); err != nil {
+			if !errors.Is(err, http.ErrNotMultipart) {
 				c.AddError(fmt.Errorf("parse form error: %w", err))
 				c.formCache = make(url.Values)
-				return ""
-			}
-		default:
-			if err := c.Request.ParseMultipartForm(defaultMemory); err != nil {
-				if !errors.Is(err, http.ErrNotMultipart) {
-					c.AddError(fmt.Errorf("parse form error: %w", err))
-					c.formCache = make(url.Values)
-					return ""
-				}
+				return c.formCache
 			}
 		}
-		c.formCache = c.Request.PostForm
 	}
-	return c.formCache.Get(key)
+	if err := c.reserveForm(); err != nil {
+		c.AddClientError(fmt.Errorf("parse form error: %w", err))
+		c.formCache = make(url.Values)
+		return c.formCache
+	}
+	c.formCache = c.Request.PostForm
+	if c.formCache == nil {
+		c.formCache = make(url.Values)
+	}
+	return c.formCache
 }
 
 // DefaultPostForm 从 POST 请求体中获取表单值，如果不存在则返回默认值
@@ -352,6 +403,11 @@ func (c *Context) Param(key string) string {
 	return c.Params.ByName(key)
 }
 
+// FullPath 返回匹配到的路由模板, 例如 /users/:id; 未匹配到路由时返回空字符串
+func (c *Context) FullPath() string {
+	return c.fullPath
+}
+
 // Raw 向响应写入bytes
 func (c *Context) Raw(code int, contentType string, data []byte) {
 	c.Writer.Header().Set("Content-Type", contentType)
@@ -377,13 +433,13 @@ func (c *Context) FileText(code int, filePath string) {
 	// 清理path
 	cleanPath := filepath.Clean(filePath)
 	if !filepath.IsAbs(cleanPath) {
-		c.AddError(fmt.Errorf("relative path not allowed: %s", cleanPath))
+		c.AddClientError(fmt.Errorf("relative path not allowed: %s", cleanPath))
 		c.ErrorUseHandle(http.StatusBadRequest, fmt.Errorf("relative path not allowed"))
 		return
 	}
 	// 检查文件是否存在
 	if _, err := os.Stat(cleanPath); os.IsNotExist(err) {
-		c.AddError(fmt.Errorf("file not found: %s", cleanPath))
+		c.AddClientError(fmt.Errorf("file not found: %s", cleanPath))
 		c.ErrorUseHandle(http.StatusNotFound, fmt.Errorf("file not found"))
 		return
 	}
@@ -406,7 +462,7 @@ func (c *Context) FileText(code int, filePath string) {
 	}
 	// 判断是否是dir
 	if fileInfo.IsDir() {
-		c.AddError(fmt.Errorf("path is a directory, not a file: %s", cleanPath))
+		c.AddClientError(fmt.Errorf("path is a directory, not a file: %s", cleanPath))
 		c.ErrorUseHandle(http.StatusBadRequest, fmt.Errorf("path is a directory"))
 		return
 	}
@@ -414,7 +470,7 @@ func (c *Context) FileText(code int, filePath string) {
 	c.SetHeader("Content-Type", "text/plain; charset=utf-8")
 	c.Writer.Header().Set("Content-Length", fmt.Sprintf("%d", fileInfo.Size()))
 	c.Writer.WriteHeader(code)
-	if _, err := iox.Copy(c.Writer, file); err != nil {
+	if _, err := c.copyBody(file); err != nil {
 		c.AddError(fmt.Errorf("failed to write file %s to response: %w", cleanPath, err))
 	}
 }
@@ -426,12 +482,12 @@ func (c *Context) FileTextSafeDir(code int, filePath string, safeDir string) {
 	// 清理path
 	cleanPath := path.Clean(filePath)
 	if !filepath.IsAbs(cleanPath) {
-		c.AddError(fmt.Errorf("relative path not allowed: %s", cleanPath))
+		c.AddClientError(fmt.Errorf("relative path not allowed: %s", cleanPath))
 		c.ErrorUseHandle(http.StatusBadRequest, fmt.Errorf("relative path not allowed"))
 		return
 	}
 	if strings.Contains(cleanPath, "..") {
-		c.AddError(fmt.Errorf("path traversal attempt detected: %s", cleanPath))
+		c.AddClientError(fmt.Errorf("path traversal attempt detected: %s", cleanPath))
 		c.ErrorUseHandle(http.StatusBadRequest, fmt.Errorf("path traversal attempt detected"))
 		return
 	}
@@ -439,7 +495,7 @@ func (c *Context) FileTextSafeDir(code int, filePath string, safeDir string) {
 	// 判断filePath是否包含在safeDir内, 防止路径穿越
 	relPath, err := filepath.Rel(safeDir, cleanPath)
 	if err != nil {
-		c.AddError(fmt.Errorf("failed to get relative path: %w", err))
+		c.AddClientError(fmt.Errorf("failed to get relative path: %w", err))
 		c.ErrorUseHandle(http.StatusBadRequest, fmt.Errorf("failed to get relative path: %w", err))
 		return
 	}
@@ -447,7 +503,7 @@ func (c *Context) FileTextSafeDir(code int, filePath string, safeDir string) {
 
 	// 检查文件是否存在
 	if _, err := os.Stat(cleanPath); os.IsNotExist(err) {
-		c.AddError(fmt.Errorf("file not found: %s", cleanPath))
+		c.AddClientError(fmt.Errorf("file not found: %s", cleanPath))
 		c.ErrorUseHandle(http.StatusNotFound, fmt.Errorf("file not found"))
 		return
 	}
@@ -470,7 +526,7 @@ func (c *Context) FileTextSafeDir(code int, filePath string, safeDir string) {
 	}
 	// 判断是否是dir
 	if fileInfo.IsDir() {
-		c.AddError(fmt.Errorf("path is a directory, not a file: %s", cleanPath))
+		c.AddClientError(fmt.Errorf("path is a directory, not a file: %s", cleanPath))
 		c.ErrorUseHandle(http.StatusBadRequest, fmt.Errorf("path is a directory"))
 		return
 	}
@@ -486,7 +542,7 @@ func (c *Context) FileTextSafeDir(code int, filePath string, safeDir string) {
 func (c *Context) JSON(code int, obj any) {
 	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
 	c.Writer.WriteHeader(code)
-	if err := json.MarshalWrite(c.Writer, obj); err != nil {
+	if err := c.marshalJSON(c.Writer, obj); err != nil {
 		c.AddError(fmt.Errorf("failed to marshal JSON: %w", err))
 		c.Errorf("failed to marshal JSON: %s", err)
 		c.ErrorUseHandle(http.StatusInternalServerError, fmt.Errorf("failed to marshal JSON: %w", err))
@@ -498,10 +554,10 @@ func (c *Context) JSON(code int, obj any) {
 // 与 JSON 相比，编码失败时可以正确返回 500 状态码，代价是多一次内存分配.
 func (c *Context) JSONBuf(code int, obj any) {
 	var buf bytes.Buffer
-	if err := json.MarshalWrite(&buf, obj); err != nil {
-		errMsg := fmt.Errorf("failed to marshal JSON: %w", err)
-		c.AddError(errMsg)
-		c.ErrorUseHandle(http.StatusInternalServerError, errMsg)
+	bw := c.budgetBuffer(&buf)
+	defer bw.release()
+	if err := c.marshalJSON(bw, obj); err != nil {
+		c.renderError(fmt.Errorf("failed to marshal JSON: %w", err))
 		return
 	}
 
@@ -527,11 +583,11 @@ func (c *Context) GOB(code int, obj any) {
 // GOBBuf 先将 GOB 编码到 buffer, 成功后再写入状态码和响应体.
 func (c *Context) GOBBuf(code int, obj any) {
 	var buf bytes.Buffer
-	encoder := gob.NewEncoder(&buf)
+	bw := c.budgetBuffer(&buf)
+	defer bw.release()
+	encoder := gob.NewEncoder(bw)
 	if err := encoder.Encode(obj); err != nil {
-		errMsg := fmt.Errorf("failed to encode GOB: %w", err)
-		c.AddError(errMsg)
-		c.ErrorUseHandle(http.StatusInternalServerError, errMsg)
+		c.renderError(fmt.Errorf("failed to encode GOB: %w", err))
 		return
 	}
 	c.Writer.Header().Set("Content-Type", "application/octet-stream")
@@ -556,11 +612,11 @@ func (c *Context) WANF(code int, obj any) {
 // WANFBuf 先将 WANF 编码到 buffer, 成功后再写入状态码和响应体.
 func (c *Context) WANFBuf(code int, obj any) {
 	var buf bytes.Buffer
-	encoder := wanf.NewStreamEncoder(&buf)
+	bw := c.budgetBuffer(&buf)
+	defer bw.release()
+	encoder := wanf.NewStreamEncoder(bw)
 	if err := encoder.Encode(obj); err != nil {
-		errMsg := fmt.Errorf("failed to encode WANF: %w", err)
-		c.AddError(errMsg)
-		c.ErrorUseHandle(http.StatusInternalServerError, errMsg)
+		c.renderError(fmt.Errorf("failed to encode WANF: %w", err))
 		return
 	}
 	c.Writer.Header().Set("Content-Type", "application/vnd.wjqserver.wanf; charset=utf-8")
@@ -569,24 +625,24 @@ func (c *Context) WANFBuf(code int, obj any) {
 }
 
 // HTML 渲染 HTML 模板
-// 如果 Engine 配置了 HTMLRender，则使用它进行渲染
+// 如果 Engine 配置了 HTMLRender (*template.Template 或 HTMLRenderer)，则使用它进行渲染
 // 否则，会进行简单的字符串输出
-// 预留接口，可以扩展为支持多种模板引擎
 func (c *Context) HTML(code int, name string, obj any) {
 	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
 	c.Writer.WriteHeader(code)
 
 	if c.engine != nil && c.engine.HTMLRender != nil {
-		// 假设 HTMLRender 是一个 *template.Template 实例
-		if tpl, ok := c.engine.HTMLRender.(*template.Template); ok {
-			err := tpl.ExecuteTemplate(c.Writer, name, obj)
+		r, err := c.engine.htmlRenderer()
+		if err == nil && r != nil {
+			err = r.Render(c, c.renderWriter(c.Writer), name, obj)
+		}
+		if err != nil || r != nil {
 			if err != nil {
 				c.AddError(fmt.Errorf("failed to render HTML template '%s': %w", name, err))
 				c.ErrorUseHandle(http.StatusInternalServerError, fmt.Errorf("failed to render HTML template '%s': %w", name, err))
 			}
 			return
 		}
-		// 可以扩展支持其他渲染器接口
 	}
 	// 默认简单输出，用于未配置 HTMLRender 的情况
 	c.writeResponseBody(fmt.Appendf(nil, "<!-- HTML rendered for %s -->\n<pre>%v</pre>", name, obj), "failed to write HTML response")
@@ -601,14 +657,17 @@ func (c *Context) HTMLBuf(code int, name string, obj any) {
 		return
 	}
 
-	if tpl, ok := c.engine.HTMLRender.(*template.Template); ok {
+	r, err := c.engine.htmlRenderer()
+	if err != nil || r != nil {
 		var buf bytes.Buffer
-		err := tpl.ExecuteTemplate(&buf, name, obj)
+		bw := c.budgetBuffer(&buf)
+		defer bw.release()
+		if err == nil {
+			err = r.Render(c, c.renderWriter(bw), name, obj)
+		}
 		if err != nil {
-			// 渲染失败，记录错误并返回 500，不写入任何内容
-			errMsg := fmt.Errorf("failed to render HTML template '%s': %w", name, err)
-			c.AddError(errMsg)
-			c.ErrorUseHandle(http.StatusInternalServerError, errMsg)
+			// 渲染失败，记录错误并返回 500 (超出内存预算时为 507)，不写入任何内容
+			c.renderError(fmt.Errorf("failed to render HTML template '%s': %w", name, err))
 			return
 		}
 		// 渲染成功，写入响应
@@ -633,17 +692,19 @@ func (c *Context) Redirect(code int, location string) {
 }
 
 // ShouldBindJSON 尝试将请求体绑定到 JSON 对象
+// 引擎开启 RejectUnknownFields 或路由声明了 StrictJSON 时, 等同于 ShouldBindJSONStrict
 func (c *Context) ShouldBindJSON(obj any) error {
-	var body io.ReadCloser
-	if c.MaxRequestBodySize > 0 {
-		body = c.prepareRequestBody()
-	} else {
-		body = c.Request.Body
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
+	if c.strictJSONBinding() {
+		return c.ShouldBindJSONStrict(obj)
 	}
+	body := c.bindRequestBody()
 	if body == nil {
 		return errors.New("request body is empty")
 	}
-	err := json.UnmarshalRead(body, obj)
+	err := c.unmarshalJSON(body, obj)
 	if err != nil {
 		return fmt.Errorf("json binding error: %w", err)
 	}
@@ -652,12 +713,10 @@ func (c *Context) ShouldBindJSON(obj any) error {
 
 // ShouldBindWANF 尝试将 WANF 格式的请求体绑定到对象
 func (c *Context) ShouldBindWANF(obj any) error {
-	var body io.ReadCloser
-	if c.MaxRequestBodySize > 0 {
-		body = c.prepareRequestBody()
-	} else {
-		body = c.Request.Body
+	if err := applyDefaults(obj); err != nil {
+		return err
 	}
+	body := c.bindRequestBody()
 	if body == nil {
 		return errors.New("request body is empty")
 	}
@@ -674,12 +733,10 @@ func (c *Context) ShouldBindWANF(obj any) error {
 
 // ShouldBindGOB 尝试将 GOB 格式的请求体绑定到对象
 func (c *Context) ShouldBindGOB(obj any) error {
-	var body io.ReadCloser
-	if c.MaxRequestBodySize > 0 {
-		body = c.prepareRequestBody()
-	} else {
-		body = c.Request.Body
+	if err := applyDefaults(obj); err != nil {
+		return err
 	}
+	body := c.bindRequestBody()
 	if body == nil {
 		return errors.New("request body is empty")
 	}
@@ -693,13 +750,56 @@ func (c *Context) ShouldBindGOB(obj any) error {
 // bindForm 将 url.Values 绑定到结构体
 // 支持 form tag 标签，如 `form:"field_name"`
 func bindForm(values url.Values, obj any) error {
+	return bindTag(obj, "form", true, func(name string) []string { return values[name] })
+}
+
+// bindTag 按 tag 标签从 lookup 中取值并绑定到结构体
+// fallbackToName 为 true 时, 没有该标签的字段使用字段名; 否则跳过这些字段.
+// 嵌套结构体字段使用 "<name>." 前缀 (例如 address.city), 嵌入的匿名结构体与外层共用前缀;
+// 结构体切片使用 "<name>[<i>]." 前缀 (例如 items[0].sku), 从 0 开始直到某个下标没有任何值
+func bindTag(obj any, tagName string, fallbackToName bool, lookup func(name string) []string) error {
+	return bindSource{tagName: tagName, fallbackToName: fallbackToName, lookup: lookup}.bind(obj)
+}
+
+// bindSource 描述一次结构体绑定的取值来源
+type bindSource struct {
+	tagName        string
+	fallbackToName bool
+	lookup         func(name string) []string
+	// files 非 nil 时, *multipart.FileHeader 与 []*multipart.FileHeader 字段从中取上传的文件
+	files func(name string) []*multipart.FileHeader
+	// skipDefaults 为 true 时不填入 `default` 标签的默认值, 用于已由调用方填入默认值的多来源绑定
+	skipDefaults bool
+}
+
+func (src bindSource) bind(obj any) error {
 	val := reflect.ValueOf(obj)
 	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Struct {
 		return errors.New("obj must be a pointer to struct")
 	}
 
-	val = val.Elem()
+	if !src.skipDefaults {
+		if err := applyDefaults(obj); err != nil {
+			return err
+		}
+	}
+
+	_, err := src.bindStruct(val.Elem(), "")
+	return err
+}
+
+// maxBindSliceLen 限制结构体切片绑定的元素个数
+const maxBindSliceLen = 1000
+
+var (
+	fileHeaderType      = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeaderSliceType = reflect.TypeFor[[]*multipart.FileHeader]()
+)
+
+// bindStruct 绑定结构体的各个字段, 返回是否有字段取到了值
+func (src bindSource) bindStruct(val reflect.Value, prefix string) (bool, error) {
 	typ := val.Type()
+	bound := false
 
 	for i := 0; i < val.NumField(); i++ {
 		field := val.Field(i)
@@ -709,24 +809,119 @@ func bindForm(values url.Values, obj any) error {
 			continue
 		}
 
-		tag := fieldType.Tag.Get("form")
+		tag := fieldType.Tag.Get(src.tagName)
+		if tag == "-" {
+			continue
+		}
+		if tag == "" && fieldType.Anonymous && isBindStruct(fieldType.Type) {
+			ok, err := src.bindNested(field, prefix)
+			if err != nil {
+				return bound, err
+			}
+			bound = bound || ok
+			continue
+		}
 		if tag == "" {
+			if !src.fallbackToName {
+				continue
+			}
 			tag = fieldType.Name
 		}
-		if tag == "-" {
+		name := prefix + tag
+
+		if fieldType.Type == fileHeaderType || fieldType.Type == fileHeaderSliceType {
+			if src.files == nil {
+				continue
+			}
+			files := src.files(name)
+			if len(files) == 0 {
+				continue
+			}
+			if fieldType.Type == fileHeaderType {
+				field.Set(reflect.ValueOf(files[0]))
+			} else {
+				field.Set(reflect.ValueOf(files))
+			}
+			bound = true
+			continue
+		}
+		if isBindStruct(fieldType.Type) {
+			ok, err := src.bindNested(field, name+".")
+			if err != nil {
+				return bound, err
+			}
+			bound = bound || ok
+			continue
+		}
+		if fieldType.Type.Kind() == reflect.Slice && isBindStruct(fieldType.Type.Elem()) {
+			ok, err := src.bindStructSlice(field, name)
+			if err != nil {
+				return bound, err
+			}
+			bound = bound || ok
 			continue
 		}
 
-		formValues := values[tag]
+		formValues := src.lookup(name)
+		if len(formValues) == 0 && fieldType.Type.Kind() == reflect.Slice {
+			// 兼容 jQuery 等客户端的 ids[]=1&ids[]=2 写法
+			formValues = src.lookup(name + "[]")
+		}
 		if len(formValues) == 0 {
 			continue
 		}
 
 		if err := setFieldValue(field, formValues); err != nil {
-			return fmt.Errorf("field %s: %w", fieldType.Name, err)
+			return bound, &formFieldError{Field: fieldType.Name, Name: name, Err: err}
 		}
+		bound = true
 	}
-	return nil
+	return bound, nil
+}
+
+// isBindStruct 报告 t 是否按嵌套结构体绑定. time.Time 等按单个值解析的类型除外
+func isBindStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// bindNested 绑定嵌套结构体, 指针字段只在有值时分配
+func (src bindSource) bindNested(field reflect.Value, prefix string) (bool, error) {
+	if field.Kind() != reflect.Pointer {
+		return src.bindStruct(field, prefix)
+	}
+	target := field
+	if field.IsNil() {
+		target = reflect.New(field.Type().Elem())
+	}
+	ok, err := src.bindStruct(target.Elem(), prefix)
+	if ok && field.IsNil() {
+		field.Set(target)
+	}
+	return ok, err
+}
+
+// bindStructSlice 按 name[0].、name[1]. ... 依次绑定结构体切片的元素
+func (src bindSource) bindStructSlice(field reflect.Value, name string) (bool, error) {
+	slice := reflect.MakeSlice(field.Type(), 0, 0)
+	for i := 0; i < maxBindSliceLen; i++ {
+		elem := reflect.New(field.Type().Elem()).Elem()
+		ok, err := src.bindNested(elem, name+"["+strconv.Itoa(i)+"].")
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			break
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	if slice.Len() == 0 {
+		return false, nil
+	}
+	field.Set(slice)
+	return true, nil
 }
 
 // setFieldValue 将字符串值设置到反射值
@@ -737,6 +932,29 @@ func setFieldValue(field reflect.Value, values []string) error {
 
 	value := values[0]
 
+	switch field.Type() {
+	case durationType:
+		if value == "" {
+			return nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case timeType:
+		if value == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
 	switch field.Kind() {
 	case reflect.String:
 		field.SetString(value)
@@ -798,11 +1016,11 @@ func setFieldValue(field reflect.Value, values []string) error {
 }
 
 // ShouldBindForm 尝试将表单数据绑定到结构体
-// 支持 application/x-www-form-urlencoded 和 multipart/form-data
+// 支持 application/x-www-form-urlencoded 和 multipart/form-data.
+// multipart 表单中上传的文件绑定到 *multipart.FileHeader (取第一个文件) 或 []*multipart.FileHeader 类型的字段,
+// 文件大小超过 SetUploadLimits 设置的限制时返回包装了 ErrUploadTooLarge 的错误
 func (c *Context) ShouldBindForm(obj any) error {
-	if c.MaxRequestBodySize > 0 {
-		c.prepareRequestBody()
-	}
+	c.bindRequestBody()
 
 	contentType := c.Request.Header.Get("Content-Type")
 	mediaType, _, err := mime.ParseMediaType(contentType)
@@ -811,33 +1029,98 @@ func (c *Context) ShouldBindForm(obj any) error {
 	}
 
 	switch mediaType {
-	case "multipart/form-data":
-		if err := c.Request.ParseMultipartForm(defaultMemory); err != nil {
-			return fmt.Errorf("parse multipart form error: %w", err)
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+	default:
+		return fmt.Errorf("unsupported form content type: %s", mediaType)
+	}
+	// PostForm 或之前的 ShouldBindForm 已经解析过表单时直接复用, 不重复读取请求体与计入内存预算
+	if c.formCache == nil || c.Request.Form == nil {
+		if mediaType == "multipart/form-data" {
+			if err := c.parseMultipartForm(); err != nil {
+				return fmt.Errorf("parse multipart form error: %w", err)
+			}
+		} else if err := c.Request.ParseForm(); err != nil {
+			return fmt.Errorf("parse form error: %w", err)
 		}
-	case "application/x-www-form-urlencoded":
-		if err := c.Request.ParseForm(); err != nil {
+		if err := c.reserveForm(); err != nil {
 			return fmt.Errorf("parse form error: %w", err)
 		}
-	default:
-		return fmt.Errorf("unsupported form content type: %s", mediaType)
 	}
 
-	if err := bindForm(c.Request.Form, obj); err != nil {
+	if err := c.checkUploads(); err != nil {
+		return fmt.Errorf("form binding error: %w", err)
+	}
+
+	src := bindSource{tagName: "form", fallbackToName: true, lookup: func(name string) []string { return c.Request.Form[name] }}
+	if mf := c.Request.MultipartForm; mf != nil {
+		src.files = func(name string) []*multipart.FileHeader { return mf.File[name] }
+	}
+	if err := src.bind(obj); err != nil {
 		return fmt.Errorf("form binding error: %w", err)
 	}
 	c.formCache = c.Request.PostForm
 	return nil
 }
 
+// ShouldBindQuery 将 URL 查询参数绑定到结构体
+// 使用 `query:"name"` 标签, 没有该标签的字段使用字段名; 嵌套结构体与切片的写法与 ShouldBindForm 相同:
+//
+//	type ListUsers struct {
+//	    Page   int      `query:"page" default:"1"`
+//	    Status []string `query:"status"`      // ?status=active&status=locked 或 ?status[]=active
+//	    Range  struct {
+//	        From time.Time `query:"from"`      // ?range.from=2026-01-01T00:00:00Z
+//	    } `query:"range"`
+//	}
+func (c *Context) ShouldBindQuery(obj any) error {
+	values := c.queryValues()
+	if err := bindTag(obj, "query", true, func(name string) []string { return values[name] }); err != nil {
+		return fmt.Errorf("query binding error: %w", err)
+	}
+	return nil
+}
+
+// ShouldBindUri 将路径参数绑定到结构体中带有 `uri:"name"` 标签的字段, 并按字段类型转换:
+//
+//	type OrderURI struct {
+//	    Tenant string `uri:"tenant"`
+//	    ID     int64  `uri:"id"`
+//	}
+//
+//	r.GET("/:tenant/orders/:id", func(c *touka.Context) {
+//	    var uri OrderURI
+//	    if err := c.ShouldBindUri(&uri); err != nil {
+//	        ...
+//	    }
+//	})
+func (c *Context) ShouldBindUri(obj any) error {
+	if err := bindTag(obj, "uri", false, c.paramValues); err != nil {
+		return fmt.Errorf("uri binding error: %w", err)
+	}
+	return nil
+}
+
+// paramValues 以 bindTag 需要的形式返回路径参数
+func (c *Context) paramValues(name string) []string {
+	if v, ok := c.Params.Get(name); ok {
+		return []string{v}
+	}
+	return nil
+}
+
 // ShouldBind 尝试根据 Content-Type 将请求体绑定到结构体
-// 支持的类型：application/json, application/x-www-form-urlencoded, multipart/form-data, application/wanf, application/vnd.wjqserver.wanf, application/gob
+// 支持的类型：application/json, application/x-www-form-urlencoded, multipart/form-data, application/wanf, application/vnd.wjqserver.wanf, application/gob,
+// application/msgpack, application/cbor, application/xml, text/xml, application/x-protobuf (obj 须实现 proto.Message),
+// 以及通过 engine.RegisterBinding 注册的类型
 func (c *Context) ShouldBind(obj any) error {
 	contentType := c.Request.Header.Get("Content-Type")
 	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
 		return fmt.Errorf("invalid content type: %w", err)
 	}
+	if b, ok := c.lookupBinding(mediaType); ok {
+		return c.ShouldBindWith(obj, b)
+	}
 
 	switch mediaType {
 	case "application/json":
@@ -848,6 +1131,14 @@ func (c *Context) ShouldBind(obj any) error {
 		return c.ShouldBindWANF(obj)
 	case "application/gob":
 		return c.ShouldBindGOB(obj)
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		return c.ShouldBindMsgPack(obj)
+	case "application/cbor":
+		return c.ShouldBindCBOR(obj)
+	case "application/xml", "text/xml":
+		return c.ShouldBindXML(obj)
+	case "application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf":
+		return c.shouldBindProtobufAny(obj)
 	default:
 		return fmt.Errorf("unsupported content type: %s", mediaType)
 	}
@@ -855,13 +1146,32 @@ func (c *Context) ShouldBind(obj any) error {
 
 // AddError 添加一个错误到 Context
 // 允许在处理请求过程中收集多个错误
+// 这是一个线程安全的操作，可以在处理函数启动的 goroutine 中调用
 func (c *Context) AddError(err error) {
+	if c.engine != nil {
+		c.engine.errorCounters[ErrorClassOf(err)].Add(1)
+	}
+	c.mu.Lock()
 	c.Errors = append(c.Errors, err)
+	c.mu.Unlock()
 }
 
-// Errors 返回 Context 中收集的所有错误
+// GetErrors 返回 Context 中收集的所有错误的快照
+// 返回的切片是副本，之后添加的错误不会反映在其中
 func (c *Context) GetErrors() []error {
-	return c.Errors
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.Errors) == 0 {
+		return nil
+	}
+	return slices.Clone(c.Errors)
+}
+
+// ErrorCount 返回 Context 中收集的错误数量
+func (c *Context) ErrorCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.Errors)
 }
 
 // Client 返回当前请求的 HTTPClient
@@ -936,7 +1246,7 @@ func (c *Context) WriteStream(reader io.Reader) (written int64, err error) {
 		c.Writer.WriteHeader(http.StatusOK) // 默认 200 OK
 	}
 
-	written, err = iox.Copy(c.Writer, reader) // 从 reader 读取并写入 ResponseWriter
+	written, err = c.copyBody(reader) // 从 reader 读取并写入 ResponseWriter
 	if err != nil {
 		c.AddError(fmt.Errorf("failed to write stream: %w", err))
 	}
@@ -969,7 +1279,7 @@ func (c *Context) GetReqBodyFull() ([]byte, error) {
 		}
 	}()
 
-	data, err := io.ReadAll(body)
+	data, err := c.readAllBody(body)
 	if err != nil {
 		c.AddError(fmt.Errorf("failed to read request body: %w", err))
 		return nil, fmt.Errorf("failed to read request body: %w", err)
@@ -990,7 +1300,7 @@ func (c *Context) GetReqBodyBuffer() (*bytes.Buffer, error) {
 		}
 	}()
 
-	data, err := io.ReadAll(body)
+	data, err := c.readAllBody(body)
 	if err != nil {
 		c.AddError(fmt.Errorf("failed to read request body: %w", err))
 		return nil, fmt.Errorf("failed to read request body: %w", err)
@@ -999,11 +1309,18 @@ func (c *Context) GetReqBodyBuffer() (*bytes.Buffer, error) {
 }
 
 // RequestIP 返回客户端的 IP 地址
-// 它会根据 Engine 的配置 (ForwardByClientIP) 尝试从 X-Forwarded-For 或 X-Real-IP 等头部获取，
-// 否则回退到 Request.RemoteAddr
+// 它会根据 Engine 的配置 (ForwardByClientIP) 尝试从 X-Forwarded-For、X-Real-IP 或 Forwarded 等头部获取，
+// 否则回退到 Request.RemoteAddr. 设置了 TrustedProxies 时, 只采信来自可信代理的头部
 func (c *Context) RequestIP() string {
-	if c.engine.ForwardByClientIP {
+	if c.engine.ForwardByClientIP && (len(c.engine.trustedProxies) == 0 || c.FromTrustedProxy()) {
 		for _, headerName := range c.engine.RemoteIPHeaders {
+			if strings.EqualFold(headerName, "Forwarded") {
+				// RFC 7239 的结构化头部, 从右向左跳过可信代理, 取离可信代理最近的 for= 地址
+				if ip := forwardedClientIP(c.Request.Header.Values("Forwarded"), c.engine.isTrustedProxy); ip != "" {
+					return ip
+				}
+				continue
+			}
 			ipValue := c.Request.Header.Get(headerName)
 			if ipValue == "" {
 				continue // 头部为空, 继续检查下一个
@@ -1060,6 +1377,32 @@ func (c *Context) RequestIP() string {
 	return ""
 }
 
+// FromTrustedProxy 报告请求的直接对端是否在 SetTrustedProxies 配置的可信代理列表中.
+// 未配置可信代理时总是返回 false
+func (c *Context) FromTrustedProxy() bool {
+	if len(c.engine.trustedProxies) == 0 {
+		return false
+	}
+	var addr netip.Addr
+	if addrp, err := netip.ParseAddrPort(c.Request.RemoteAddr); err == nil {
+		addr = addrp.Addr()
+	} else if addr, err = netip.ParseAddr(c.Request.RemoteAddr); err != nil {
+		return false
+	}
+	return c.engine.isTrustedProxy(addr)
+}
+
+// isTrustedProxy 报告 addr 是否在可信代理列表中
+func (engine *Engine) isTrustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range engine.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
 // ClientIP 返回客户端的 IP 地址
 // 这是一个别名，与 RequestIP 功能相同
 func (c *Context) ClientIP() string {
@@ -1133,7 +1476,13 @@ func (c *Context) GetAllReqHeader() http.Header {
 }
 
 // 使用定义的errorHandle来处理error并结束当前handle
+// code 为 0 时根据收集的错误推导状态码 (参见 ErrorStatus), 没有错误时为 500
 func (c *Context) ErrorUseHandle(code int, err error) {
+	if code == 0 {
+		if code = c.ErrorStatus(); code == 0 {
+			code = http.StatusInternalServerError
+		}
+	}
 	if c.engine != nil && c.engine.errorHandle.handler != nil {
 		c.engine.errorHandle.handler(c, code, err)
 		c.Abort()
@@ -1149,11 +1498,20 @@ func (c *Context) GetProtocol() string {
 	return c.Request.Proto
 }
 
-// GetLogger 获取engine的Logger接口
+// GetLogger 获取当前请求的Logger接口, 未设置请求级日志器时返回engine的Logger
 func (c *Context) GetLogger() Logger {
+	if c.logger != nil {
+		return c.logger
+	}
 	return c.engine.logger
 }
 
+// SetLogger 为当前请求设置日志器, 之后 c.Debugf 等方法都会使用它
+// 常用于为日志附加请求级信息 (例如租户)
+func (c *Context) SetLogger(logger Logger) {
+	c.logger = logger
+}
+
 // GetReqQueryString
 // GetReqQueryString 返回请求的原始查询字符串
 func (c *Context) GetReqQueryString() string {
@@ -1176,9 +1534,8 @@ func (c *Context) SetBodyStream(reader io.Reader, contentSize int) {
 		c.Writer.WriteHeader(http.StatusOK) // 默认 200 OK
 	}
 
-	// 将 reader 的内容直接复制到 ResponseWriter
-	// ResponseWriter 实现了 io.Writer 接口
-	_, err := iox.Copy(c.Writer, reader)
+	// 将 reader 的内容直接复制到 ResponseWriter, *os.File 可以使用 sendfile
+	_, err := c.copyBody(reader)
 	if err != nil {
 		c.AddError(fmt.Errorf("failed to write stream: %w", err))
 		// 注意：这里可能无法设置错误状态码，因为头部可能已经发送
@@ -1237,7 +1594,7 @@ func (c *Context) SetRespBodyFile(code int, filePath string) {
 	c.Writer.WriteHeader(code)
 
 	// 将文件内容写入响应体
-	_, err = iox.Copy(c.Writer, file)
+	_, err = c.copyBody(file)
 	if err != nil {
 		c.AddError(fmt.Errorf("failed to write file %s to response: %w", cleanPath, err))
 		// 注意：这里可能无法设置错误状态码，因为头部可能已经发送
@@ -1310,25 +1667,25 @@ func (c *Context) DeleteCookie(name string) {
 
 // === 日志记录 ===
 func (c *Context) Debugf(format string, args ...any) {
-	c.engine.logger.Debugf(format, args...)
+	c.GetLogger().Debugf(format, args...)
 }
 
 func (c *Context) Infof(format string, args ...any) {
-	c.engine.logger.Infof(format, args...)
+	c.GetLogger().Infof(format, args...)
 }
 
 func (c *Context) Warnf(format string, args ...any) {
-	c.engine.logger.Warnf(format, args...)
+	c.GetLogger().Warnf(format, args...)
 }
 
 func (c *Context) Errorf(format string, args ...any) {
-	c.engine.logger.Errorf(format, args...)
+	c.GetLogger().Errorf(format, args...)
 }
 
 func (c *Context) Fatalf(format string, args ...any) {
-	c.engine.logger.Fatalf(format, args...)
+	c.GetLogger().Fatalf(format, args...)
 }
 
 func (c *Context) Panicf(format string, args ...any) {
-	c.engine.logger.Panicf(format, args...)
+	c.GetLogger().Panicf(format, args...)
 }
@@ -6,6 +6,7 @@ package touka
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/WJQSERVER-STUDIO/httpc"
 )
@@ -13,46 +14,73 @@ import (
 // contextHTTPClient 包装 httpc.Client，自动关联请求的 Context
 // 当请求被取消时，出站 HTTP 请求也会自动取消
 type contextHTTPClient struct {
-	client *httpc.Client
-	ctx    context.Context
+	client  *httpc.Client
+	ctx     context.Context
+	headers http.Header // 附加到每个出站请求的头部
+}
+
+// WithHeaders 返回一个为每个出站请求附加 headers 的客户端, 请求构建器上设置的同名头部会覆盖它们.
+// 常与 ForwardingHeaders 配合, 向下游传递转发链、请求 ID 与追踪信息:
+//
+//	c.HTTPC().WithHeaders(c.ForwardingHeaders(touka.ForwardingOptions{})).GET(url).Execute()
+func (c *contextHTTPClient) WithHeaders(headers http.Header) *contextHTTPClient {
+	merged := c.headers.Clone()
+	if merged == nil {
+		merged = make(http.Header, len(headers))
+	}
+	for k, v := range headers {
+		merged[k] = append([]string(nil), v...)
+	}
+	return &contextHTTPClient{client: c.client, ctx: c.ctx, headers: merged}
+}
+
+// prepare 关联请求 Context 并附加头部
+func (c *contextHTTPClient) prepare(rb *httpc.RequestBuilder) *httpc.RequestBuilder {
+	rb = rb.WithContext(c.ctx)
+	for k, values := range c.headers {
+		for _, v := range values {
+			rb.AddHeader(k, v)
+		}
+	}
+	return rb
 }
 
 // NewRequestBuilder 创建请求构建器，自动关联请求 Context
 func (c *contextHTTPClient) NewRequestBuilder(method, urlStr string) *httpc.RequestBuilder {
-	return c.client.NewRequestBuilder(method, urlStr).WithContext(c.ctx)
+	return c.prepare(c.client.NewRequestBuilder(method, urlStr))
 }
 
 // GET 创建 GET 请求构建器
 func (c *contextHTTPClient) GET(urlStr string) *httpc.RequestBuilder {
-	return c.client.GET(urlStr).WithContext(c.ctx)
+	return c.prepare(c.client.GET(urlStr))
 }
 
 // POST 创建 POST 请求构建器
 func (c *contextHTTPClient) POST(urlStr string) *httpc.RequestBuilder {
-	return c.client.POST(urlStr).WithContext(c.ctx)
+	return c.prepare(c.client.POST(urlStr))
 }
 
 // PUT 创建 PUT 请求构建器
 func (c *contextHTTPClient) PUT(urlStr string) *httpc.RequestBuilder {
-	return c.client.PUT(urlStr).WithContext(c.ctx)
+	return c.prepare(c.client.PUT(urlStr))
 }
 
 // DELETE 创建 DELETE 请求构建器
 func (c *contextHTTPClient) DELETE(urlStr string) *httpc.RequestBuilder {
-	return c.client.DELETE(urlStr).WithContext(c.ctx)
+	return c.prepare(c.client.DELETE(urlStr))
 }
 
 // PATCH 创建 PATCH 请求构建器
 func (c *contextHTTPClient) PATCH(urlStr string) *httpc.RequestBuilder {
-	return c.client.PATCH(urlStr).WithContext(c.ctx)
+	return c.prepare(c.client.PATCH(urlStr))
 }
 
 // HEAD 创建 HEAD 请求构建器
 func (c *contextHTTPClient) HEAD(urlStr string) *httpc.RequestBuilder {
-	return c.client.HEAD(urlStr).WithContext(c.ctx)
+	return c.prepare(c.client.HEAD(urlStr))
 }
 
 // OPTIONS 创建 OPTIONS 请求构建器
 func (c *contextHTTPClient) OPTIONS(urlStr string) *httpc.RequestBuilder {
-	return c.client.OPTIONS(urlStr).WithContext(c.ctx)
+	return c.prepare(c.client.OPTIONS(urlStr))
 }
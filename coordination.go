@@ -0,0 +1,155 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Locker 提供按键互斥的锁. 默认使用进程内实现, 集群部署时可通过 engine.SetLocker 替换为 Redis 等分布式实现
+type Locker interface {
+	// Lock 阻塞直到获取 key 的锁或 ctx 结束, 返回的 unlock 用于释放锁.
+	// ttl 为锁的租约时长, 分布式实现应在持有者崩溃后据此自动释放; 进程内实现忽略该参数
+	Lock(ctx context.Context, key string, ttl time.Duration) (unlock func(), err error)
+}
+
+// Limiter 提供按键的限流. 默认使用进程内实现, 集群部署时可通过 engine.SetLimiter 替换为分布式实现
+type Limiter interface {
+	// Limit 在 key 的当前窗口内计入一次请求, 并返回是否允许
+	Limit(ctx context.Context, key string, limit int, window time.Duration) (LimitResult, error)
+}
+
+// LimitResult 是一次限流判定的结果
+type LimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // 距离当前窗口重置的时间
+}
+
+// LocalLocker 是进程内的 Locker 实现
+type LocalLocker struct {
+	mu    sync.Mutex
+	locks map[string]*localLock
+}
+
+type localLock struct {
+	ch      chan struct{} // 容量为 1 的信号量
+	waiters int
+}
+
+// NewLocalLocker 创建进程内锁
+func NewLocalLocker() *LocalLocker {
+	return &LocalLocker{locks: make(map[string]*localLock)}
+}
+
+func (l *LocalLocker) Lock(ctx context.Context, key string, _ time.Duration) (func(), error) {
+	l.mu.Lock()
+	lk, ok := l.locks[key]
+	if !ok {
+		lk = &localLock{ch: make(chan struct{}, 1)}
+		l.locks[key] = lk
+	}
+	lk.waiters++
+	l.mu.Unlock()
+
+	select {
+	case lk.ch <- struct{}{}:
+	case <-ctx.Done():
+		l.done(key, lk)
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-lk.ch
+			l.done(key, lk)
+		})
+	}, nil
+}
+
+// done 在没有等待者时清理锁
+func (l *LocalLocker) done(key string, lk *localLock) {
+	l.mu.Lock()
+	lk.waiters--
+	if lk.waiters == 0 {
+		delete(l.locks, key)
+	}
+	l.mu.Unlock()
+}
+
+// LocalLimiter 是进程内的固定窗口 Limiter 实现
+type LocalLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*limitWindow
+	lastSweep time.Time
+}
+
+type limitWindow struct {
+	count int
+	reset time.Time
+}
+
+// NewLocalLimiter 创建进程内限流器
+func NewLocalLimiter() *LocalLimiter {
+	return &LocalLimiter{windows: make(map[string]*limitWindow)}
+}
+
+func (l *LocalLimiter) Limit(_ context.Context, key string, limit int, window time.Duration) (LimitResult, error) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= time.Minute {
+		for k, w := range l.windows {
+			if !now.Before(w.reset) {
+				delete(l.windows, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.reset) {
+		w = &limitWindow{reset: now.Add(window)}
+		l.windows[key] = w
+	}
+	result := LimitResult{Limit: limit, ResetAfter: w.reset.Sub(now)}
+	if w.count < limit {
+		w.count++
+		result.Allowed = true
+	}
+	result.Remaining = max(limit-w.count, 0)
+	return result, nil
+}
+
+// SetLocker 设置引擎的 Locker, 供 Idempotency、Singleflight 等中间件以及 c.Lock 使用
+func (engine *Engine) SetLocker(locker Locker) {
+	if locker == nil {
+		panic("touka: locker must not be nil")
+	}
+	engine.locker = locker
+}
+
+// SetLimiter 设置引擎的 Limiter, 供 RateLimit 中间件以及 c.Limit 使用
+func (engine *Engine) SetLimiter(limiter Limiter) {
+	if limiter == nil {
+		panic("touka: limiter must not be nil")
+	}
+	engine.limiter = limiter
+}
+
+// Lock 通过引擎的 Locker 获取 key 的锁, 等待会随请求取消而结束
+func (c *Context) Lock(key string, ttl time.Duration) (unlock func(), err error) {
+	return c.engine.locker.Lock(c.Context(), key, ttl)
+}
+
+// Limit 通过引擎的 Limiter 对 key 计入一次请求
+func (c *Context) Limit(key string, limit int, window time.Duration) (LimitResult, error) {
+	return c.engine.limiter.Limit(c.Context(), key, limit, window)
+}
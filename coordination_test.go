@@ -0,0 +1,193 @@
+package touka
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLocalLocker(t *testing.T) {
+	l := NewLocalLocker()
+	unlock, err := l.Lock(context.Background(), "k", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Lock(ctx, "k", time.Second); err == nil {
+		t.Fatal("expected lock to be held")
+	}
+	if _, err := l.Lock(context.Background(), "other", time.Second); err != nil {
+		t.Fatalf("different keys should not block: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock2, err := l.Lock(context.Background(), "k", time.Second)
+		if err == nil {
+			close(acquired)
+			unlock2()
+		}
+	}()
+	unlock()
+	unlock() // 重复释放无副作用
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("waiter should acquire the lock after release")
+	}
+}
+
+func TestLocalLimiter(t *testing.T) {
+	l := NewLocalLimiter()
+	for i := range 3 {
+		res, _ := l.Limit(context.Background(), "k", 2, time.Hour)
+		if res.Allowed != (i < 2) {
+			t.Fatalf("request %d: unexpected Allowed=%v", i, res.Allowed)
+		}
+		if res.Remaining != max(1-i, 0) {
+			t.Fatalf("request %d: unexpected Remaining=%d", i, res.Remaining)
+		}
+	}
+	if res, _ := l.Limit(context.Background(), "fresh", 1, time.Hour); !res.Allowed {
+		t.Fatal("independent keys should have their own window")
+	}
+
+	// 窗口结束后计数重置
+	l.Limit(context.Background(), "short", 1, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if res, _ := l.Limit(context.Background(), "short", 1, 10*time.Millisecond); !res.Allowed {
+		t.Fatal("counter should reset after the window elapses")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	engine := New()
+	engine.Use(RateLimit(RateLimitOptions{Limit: 2, Window: time.Minute, KeyFunc: func(c *Context) string {
+		return c.GetReqHeader("X-User")
+	}}))
+	engine.GET("/", func(c *Context) { c.Status(http.StatusOK) })
+
+	headers := http.Header{"X-User": {"alice"}}
+	for i := range 2 {
+		w := PerformRequest(engine, http.MethodGet, "/", nil, headers)
+		if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Remaining") != strconv.Itoa(1-i) {
+			t.Fatalf("request %d: unexpected %d remaining=%q", i, w.Code, w.Header().Get("X-RateLimit-Remaining"))
+		}
+	}
+	w := PerformRequest(engine, http.MethodGet, "/", nil, headers)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After, got %d", w.Code)
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/", nil, http.Header{"X-User": {"bob"}}); w.Code != http.StatusOK {
+		t.Fatalf("other keys should not be limited, got %d", w.Code)
+	}
+}
+
+type recordingLocker struct {
+	Locker
+	keys []string
+	mu   sync.Mutex
+}
+
+func (l *recordingLocker) Lock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	l.mu.Lock()
+	l.keys = append(l.keys, key)
+	l.mu.Unlock()
+	return l.Locker.Lock(ctx, key, ttl)
+}
+
+func TestIdempotencyWaitInFlight(t *testing.T) {
+	engine := New()
+	locker := &recordingLocker{Locker: NewLocalLocker()}
+	engine.SetLocker(locker)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	engine.POST("/charges", Idempotency(IdempotencyOptions{WaitInFlight: true}), func(c *Context) {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+		c.String(http.StatusCreated, "charged")
+	})
+
+	headers := func() http.Header { return http.Header{"Idempotency-Key": {"abc"}} }
+	first := make(chan int)
+	go func() {
+		first <- PerformRequest(engine, http.MethodPost, "/charges", strings.NewReader("{}"), headers()).Code
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	second := make(chan *int)
+	go func() {
+		w := PerformRequest(engine, http.MethodPost, "/charges", strings.NewReader("{}"), headers())
+		code := w.Code
+		if w.Header().Get("Idempotent-Replayed") != "true" {
+			code = -1
+		}
+		second <- &code
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if code := <-first; code != http.StatusCreated {
+		t.Fatalf("first request: expected 201, got %d", code)
+	}
+	if code := <-second; *code != http.StatusCreated {
+		t.Fatalf("second request should wait and replay, got %d", *code)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler should run once, ran %d times", calls.Load())
+	}
+	if len(locker.keys) != 2 || locker.keys[0] != "idempotency:abc" {
+		t.Fatalf("expected engine locker to be used, got %v", locker.keys)
+	}
+}
+
+func TestSingleflightCoalescesRequests(t *testing.T) {
+	engine := New()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	engine.GET("/report", Singleflight(SingleflightOptions{Distributed: true}), func(c *Context) {
+		calls.Add(1)
+		<-release
+		c.SetHeader("X-Report", "v1")
+		c.String(http.StatusOK, "report")
+	})
+
+	const n = 5
+	var wg sync.WaitGroup
+	results := make(chan string, n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := PerformRequest(engine, http.MethodGet, "/report", nil, nil)
+			results <- strconv.Itoa(w.Code) + " " + w.Header().Get("X-Report") + " " + w.Body.String()
+		}()
+	}
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for r := range results {
+		if r != "200 v1 report" {
+			t.Fatalf("unexpected shared response %q", r)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls.Load())
+	}
+}
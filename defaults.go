@@ -0,0 +1,91 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	timeType     = reflect.TypeFor[time.Time]()
+)
+
+// defaultTagCache 缓存结构体类型是否 (直接或在嵌套结构体中) 含有 default 标签
+var defaultTagCache sync.Map // reflect.Type -> bool
+
+// applyDefaults 为 obj 中带有 `default:"value"` 标签且当前为零值的字段填入默认值.
+// 绑定器在解码之前调用它, 请求中出现的字段随后会覆盖默认值.
+// 切片的默认值以逗号分隔, 例如 `default:"a,b"`; time.Duration 使用 time.ParseDuration 的格式
+func applyDefaults(obj any) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return nil
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct || !typeHasDefaults(v.Type()) {
+		return nil
+	}
+	return setDefaults(v)
+}
+
+func typeHasDefaults(t reflect.Type) bool {
+	if cached, ok := defaultTagCache.Load(t); ok {
+		return cached.(bool)
+	}
+	// 先写入 false, 避免自引用类型无限递归
+	defaultTagCache.Store(t, false)
+	has := false
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if _, ok := sf.Tag.Lookup("default"); ok {
+			has = true
+			break
+		}
+		if sf.Type.Kind() == reflect.Struct && sf.Type != timeType && typeHasDefaults(sf.Type) {
+			has = true
+			break
+		}
+	}
+	defaultTagCache.Store(t, has)
+	return has
+}
+
+func setDefaults(v reflect.Value) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		if tag, ok := sf.Tag.Lookup("default"); ok {
+			if !field.IsZero() {
+				continue
+			}
+			values := []string{tag}
+			if field.Kind() == reflect.Slice {
+				values = strings.Split(tag, ",")
+				for j := range values {
+					values[j] = strings.TrimSpace(values[j])
+				}
+			}
+			if err := setFieldValue(field, values); err != nil {
+				return fmt.Errorf("field %s: invalid default %q: %w", sf.Name, tag, err)
+			}
+			continue
+		}
+		if field.Kind() == reflect.Struct && field.Type() != timeType && typeHasDefaults(field.Type()) {
+			if err := setDefaults(field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
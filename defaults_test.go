@@ -0,0 +1,71 @@
+package touka
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type listOptions struct {
+	Page    int           `json:"page" form:"page" default:"1"`
+	Size    int           `json:"size" form:"size" default:"20"`
+	Sort    string        `json:"sort" form:"sort" default:"created_at"`
+	Timeout time.Duration `json:"timeout" form:"timeout" default:"5s"`
+	Tags    []string      `json:"tags" form:"tags" default:"a, b"`
+	Filter  struct {
+		Status string `json:"status" form:"status" default:"active"`
+	} `json:"filter"`
+	Note string `json:"note" form:"note"`
+}
+
+func TestDefaultTagsJSON(t *testing.T) {
+	c, _ := CreateTestContextWithRequest(nil, mustNewRequest(t, `{"size":50,"tags":["x"]}`))
+	var opts listOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Page != 1 || opts.Size != 50 || opts.Sort != "created_at" || opts.Timeout != 5*time.Second ||
+		!slices.Equal(opts.Tags, []string{"x"}) || opts.Filter.Status != "active" || opts.Note != "" {
+		t.Fatalf("unexpected result %+v", opts)
+	}
+}
+
+func TestDefaultTagsFormAndQuery(t *testing.T) {
+	engine := New()
+	handler := func(c *Context) {
+		var opts listOptions
+		if err := c.ShouldBindForm(&opts); err != nil {
+			c.String(http.StatusBadRequest, "%s", err)
+			return
+		}
+		c.String(http.StatusOK, "%d %d %s %s %v", opts.Page, opts.Size, opts.Timeout, strings.Join(opts.Tags, "|"), opts.Filter.Status)
+	}
+	engine.POST("/", handler)
+	engine.GET("/typed", JSONHandler(func(c *Context, opts listOptions) (string, error) {
+		return fmt.Sprintf("%d %s %v", opts.Page, opts.Timeout, opts.Tags), nil
+	}))
+
+	w := PerformRequest(engine, http.MethodPost, "/", strings.NewReader(url.Values{"page": {"3"}, "timeout": {"1m"}}.Encode()),
+		http.Header{"Content-Type": {"application/x-www-form-urlencoded"}})
+	if w.Body.String() != "3 20 1m0s a|b active" {
+		t.Fatalf("unexpected form result %q", w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/typed?tags=q", nil, nil)
+	if w.Body.String() != `"1 5s [q]"` {
+		t.Fatalf("unexpected query result %s", w.Body.String())
+	}
+}
+
+func TestInvalidDefaultTag(t *testing.T) {
+	var bad struct {
+		N int `default:"x"`
+	}
+	if err := applyDefaults(&bad); err == nil || !strings.Contains(err.Error(), `invalid default "x"`) {
+		t.Fatalf("expected invalid default error, got %v", err)
+	}
+}
@@ -0,0 +1,348 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrDigestMissing  = errors.New("digest: request digest missing or unsupported")
+	ErrDigestMismatch = errors.New("digest: payload does not match the declared digest")
+)
+
+// digestAlgorithms 是支持的摘要算法, 键为 RFC 9530 中的小写名称
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+	"md5":     md5.New,
+}
+
+// DigestOptions 配置 Digest 中间件
+type DigestOptions struct {
+	// RequireRequestDigest 为 true 时, 携带请求体的请求必须提供 Content-Digest、Digest 或 Content-MD5, 否则返回 400
+	RequireRequestDigest bool
+	// Algorithms 为响应总是附带的 Content-Digest 算法 (sha-256、sha-512);
+	// 为空时只在请求携带 Want-Content-Digest 或 Want-Digest 时生成
+	Algorithms []string
+	// MaxBufferSize 为在响应头中附带摘要时缓冲的响应体上限, 默认 8MB.
+	// 超出或调用 Flush 后改为以 trailer 发送 Content-Digest (仅对未设置 Content-Length 的响应有效)
+	MaxBufferSize int
+}
+
+// Digest 返回校验请求体摘要并为响应生成摘要的中间件, 支持 RFC 9530 的 Content-Digest / Want-Content-Digest、
+// RFC 3230 的 Digest / Want-Digest 与 Content-MD5.
+//
+// 请求摘要在处理器读取请求体时边读边算, 读到末尾时若不匹配, 读取返回 ErrDigestMismatch,
+// 绑定等操作随之失败. 未读完请求体的处理器不会触发校验
+func Digest(opts DigestOptions) HandlerFunc {
+	if opts.MaxBufferSize <= 0 {
+		opts.MaxBufferSize = 8 << 20
+	}
+	for _, alg := range opts.Algorithms {
+		if _, ok := digestAlgorithms[strings.ToLower(alg)]; !ok {
+			panic("touka: unsupported digest algorithm " + alg)
+		}
+	}
+
+	return func(c *Context) {
+		if hasRequestBody(c.Request) {
+			expected := requestDigests(c.Request.Header)
+			if len(expected) == 0 {
+				if opts.RequireRequestDigest {
+					c.AddClientError(ErrDigestMissing)
+					c.ErrorUseHandle(http.StatusBadRequest, ErrDigestMissing)
+					return
+				}
+			} else {
+				c.Request.Body = newDigestVerifyReader(c.Request.Body, expected)
+			}
+		}
+
+		legacy, algs := responseDigestAlgorithms(c.Request.Header, opts.Algorithms)
+		if len(algs) == 0 || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &digestWriter{ResponseWriter: c.Writer, limit: c.bufferLimit(opts.MaxBufferSize), legacy: legacy}
+		for _, alg := range algs {
+			w.algs = append(w.algs, alg)
+			w.hashes = append(w.hashes, digestAlgorithms[alg]())
+		}
+		original := c.Writer
+		c.Writer = w
+		defer func() {
+			c.Writer = original
+			if err := w.finish(); err != nil {
+				c.AddError(err)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// requestDigests 解析请求中声明的摘要, 忽略不支持的算法
+func requestDigests(h http.Header) map[string][]byte {
+	digests := make(map[string][]byte)
+	add := func(alg, value string) {
+		alg = strings.ToLower(strings.TrimSpace(alg))
+		if _, ok := digestAlgorithms[alg]; !ok {
+			return
+		}
+		if sum, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value)); err == nil {
+			digests[alg] = sum
+		}
+	}
+	// Content-Digest: sha-256=:<base64>:, sha-512=:<base64>:
+	for _, v := range h.Values("Content-Digest") {
+		for item := range strings.SplitSeq(v, ",") {
+			alg, value, ok := strings.Cut(item, "=")
+			if ok {
+				add(alg, strings.Trim(strings.TrimSpace(value), ":"))
+			}
+		}
+	}
+	// Digest: SHA-256=<base64>,MD5=<base64>
+	for _, v := range h.Values("Digest") {
+		for item := range strings.SplitSeq(v, ",") {
+			alg, value, ok := strings.Cut(item, "=")
+			if ok {
+				add(alg, value)
+			}
+		}
+	}
+	if v := h.Get("Content-MD5"); v != "" {
+		add("md5", v)
+	}
+	return digests
+}
+
+// responseDigestAlgorithms 根据 Want-Content-Digest / Want-Digest 与默认配置选择响应摘要算法.
+// legacy 为 true 时使用 RFC 3230 的 Digest 头
+func responseDigestAlgorithms(h http.Header, defaults []string) (legacy bool, algs []string) {
+	if v := h.Get("Want-Content-Digest"); v != "" {
+		// Want-Content-Digest: sha-512=3, sha-256=10
+		if alg := preferredDigest(v, "="); alg != "" {
+			return false, []string{alg}
+		}
+	}
+	if v := h.Get("Want-Digest"); v != "" {
+		// Want-Digest: SHA-256;q=0.3, MD5
+		if alg := preferredDigest(v, ";q="); alg != "" {
+			return true, []string{alg}
+		}
+	}
+	for _, alg := range defaults {
+		algs = append(algs, strings.ToLower(alg))
+	}
+	return false, algs
+}
+
+// preferredDigest 返回偏好列表中权重最高且受支持的算法
+func preferredDigest(v, sep string) string {
+	best, bestWeight := "", 0.0
+	for item := range strings.SplitSeq(v, ",") {
+		alg, weight, ok := strings.Cut(strings.TrimSpace(item), sep)
+		alg = strings.ToLower(strings.TrimSpace(alg))
+		w := 1.0
+		if ok {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+			if err != nil {
+				continue
+			}
+			w = parsed
+		}
+		if _, supported := digestAlgorithms[alg]; supported && w > bestWeight {
+			best, bestWeight = alg, w
+		}
+	}
+	return best
+}
+
+// digestVerifyReader 在读取请求体的同时计算摘要, 读到末尾时校验
+type digestVerifyReader struct {
+	io.ReadCloser
+	expected map[string][]byte
+	hashes   map[string]hash.Hash
+	err      error
+}
+
+func newDigestVerifyReader(body io.ReadCloser, expected map[string][]byte) *digestVerifyReader {
+	hashes := make(map[string]hash.Hash, len(expected))
+	for alg := range expected {
+		hashes[alg] = digestAlgorithms[alg]()
+	}
+	return &digestVerifyReader{ReadCloser: body, expected: expected, hashes: hashes}
+}
+
+func (r *digestVerifyReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.ReadCloser.Read(p)
+	for _, h := range r.hashes {
+		h.Write(p[:n])
+	}
+	if err == io.EOF {
+		for alg, h := range r.hashes {
+			if subtle.ConstantTimeCompare(h.Sum(nil), r.expected[alg]) != 1 {
+				r.err = ErrDigestMismatch
+				return n, r.err
+			}
+		}
+	}
+	return n, err
+}
+
+// digestWriter 缓冲响应以便在响应头中附带摘要, 超出上限时改为透传并以 trailer 发送
+type digestWriter struct {
+	ResponseWriter
+	algs        []string
+	hashes      []hash.Hash
+	legacy      bool
+	buf         bytes.Buffer
+	status      int
+	limit       int
+	passthrough bool
+	hijacked    bool
+}
+
+func (w *digestWriter) WriteHeader(code int) {
+	if w.passthrough || (code >= 100 && code < 200) {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *digestWriter) Write(b []byte) (int, error) {
+	for _, h := range w.hashes {
+		h.Write(b)
+	}
+	if !w.passthrough {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if w.buf.Len()+len(b) <= w.limit {
+			return w.buf.Write(b)
+		}
+		if err := w.startPassthrough(); err != nil {
+			return 0, err
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// startPassthrough 声明摘要 trailer 并写出已缓冲的内容, 之后的写入直接透传
+func (w *digestWriter) startPassthrough() error {
+	if w.passthrough {
+		return nil
+	}
+	w.passthrough = true
+	if !w.legacy && w.Header().Get("Content-Length") == "" {
+		w.Header().Add("Trailer", "Content-Digest")
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() > 0 {
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+		return err
+	}
+	return nil
+}
+
+func (w *digestWriter) Flush() {
+	_ = w.startPassthrough()
+	w.ResponseWriter.Flush()
+}
+
+func (w *digestWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.passthrough = true
+	w.hijacked = true
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *digestWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *digestWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	return w.buf.Len()
+}
+
+func (w *digestWriter) Written() bool {
+	if w.passthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.status != 0
+}
+
+// value 返回摘要头的值
+func (w *digestWriter) value() string {
+	parts := make([]string, len(w.algs))
+	for i, alg := range w.algs {
+		sum := base64.StdEncoding.EncodeToString(w.hashes[i].Sum(nil))
+		if w.legacy {
+			parts[i] = strings.ToUpper(alg) + "=" + sum
+		} else {
+			parts[i] = alg + "=:" + sum + ":"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// finish 附带摘要并写出缓冲的响应
+func (w *digestWriter) finish() error {
+	if w.hijacked {
+		return nil
+	}
+	if w.passthrough {
+		if headerValuesContainToken(w.Header().Values("Trailer"), "Content-Digest") {
+			w.Header().Set("Content-Digest", w.value())
+		}
+		return nil
+	}
+	if w.status == 0 {
+		return nil
+	}
+	w.passthrough = true
+	if w.status != http.StatusNoContent && w.status != http.StatusNotModified {
+		if w.legacy {
+			w.Header().Set("Digest", w.value())
+		} else {
+			w.Header().Set("Content-Digest", w.value())
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	return err
+}
@@ -0,0 +1,104 @@
+package touka
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sha256B64(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestDigestVerifiesRequestBody(t *testing.T) {
+	engine := New()
+	engine.Use(Digest(DigestOptions{RequireRequestDigest: true}))
+	engine.POST("/", func(c *Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.ErrorUseHandle(http.StatusBadRequest, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	cases := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{"content-digest", http.Header{"Content-Digest": {"sha-256=:" + sha256B64("hello") + ":"}}, http.StatusNoContent},
+		{"legacy digest", http.Header{"Digest": {"SHA-256=" + sha256B64("hello")}}, http.StatusNoContent},
+		{"content-md5", http.Header{"Content-Md5": {"XUFAKrxLKna5cZ2REBfFkg=="}}, http.StatusNoContent},
+		{"mismatch", http.Header{"Content-Digest": {"sha-256=:" + sha256B64("other") + ":"}}, http.StatusBadRequest},
+		{"missing", http.Header{}, http.StatusBadRequest},
+		{"unsupported only", http.Header{"Content-Digest": {"crc32=:AAAA:"}}, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := PerformRequest(engine, http.MethodPost, "/", strings.NewReader("hello"), tc.header)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d %s", tc.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDigestResponseHeaders(t *testing.T) {
+	engine := New()
+	engine.GET("/default", Digest(DigestOptions{Algorithms: []string{"sha-256"}}), func(c *Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	engine.GET("/negotiated", Digest(DigestOptions{}), func(c *Context) {
+		c.String(http.StatusOK, "hello")
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/default", nil, nil)
+	if got := w.Header().Get("Content-Digest"); got != "sha-256=:"+sha256B64("hello")+":" || w.Body.String() != "hello" {
+		t.Fatalf("unexpected Content-Digest %q body %q", got, w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/negotiated", nil, http.Header{"Want-Content-Digest": {"sha-256=1, sha-512=5"}})
+	sum := sha512.Sum512([]byte("hello"))
+	if got := w.Header().Get("Content-Digest"); got != "sha-512=:"+base64.StdEncoding.EncodeToString(sum[:])+":" {
+		t.Fatalf("expected preferred sha-512, got %q", got)
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/negotiated", nil, http.Header{"Want-Digest": {"MD5;q=0.3, SHA-256;q=1"}})
+	if got := w.Header().Get("Digest"); got != "SHA-256="+sha256B64("hello") {
+		t.Fatalf("expected legacy Digest header, got %q", got)
+	}
+
+	if w := PerformRequest(engine, http.MethodGet, "/negotiated", nil, nil); w.Header().Get("Content-Digest") != "" {
+		t.Fatal("no digest should be emitted without preference or defaults")
+	}
+}
+
+func TestDigestTrailerForStreamingResponses(t *testing.T) {
+	engine := New()
+	engine.GET("/", Digest(DigestOptions{Algorithms: []string{"sha-256"}}), func(c *Context) {
+		c.Writer.Write([]byte("part1-"))
+		c.Writer.Flush()
+		c.Writer.Write([]byte("part2"))
+	})
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "part1-part2" {
+		t.Fatalf("unexpected body %q", body)
+	}
+	if got := resp.Trailer.Get("Content-Digest"); got != "sha-256=:"+sha256B64("part1-part2")+":" {
+		t.Fatalf("unexpected trailer %q", got)
+	}
+}
@@ -7,9 +7,11 @@ package touka
 import (
 	"bufio"
 	"errors"
+	"io"
 	"maps"
 	"net"
 	"net/http"
+	"slices"
 	"sync"
 )
 
@@ -24,11 +26,14 @@ type errorCapturingResponseWriter struct {
 	headerSnapshot      http.Header         // FileServer 在调用 WriteHeader 前可能设置的头部快照
 	capturedErrorSignal bool                // 标记 FileServer 是否意图发送一个错误状态码 (>=400)
 	responseStarted     bool                // 标记包装器是否已经向原始 w 发送过任何数据
+	fallback            *fsFallback         // 文件服务器返回 404 时的回退处理 (SPA index / 自定义 404 页面), 可以为 nil
+	intercept           []int               // 需要拦截的状态码, 为空时拦截所有 >= 400 的状态码
 }
 
 // errorResponseWriterPool 是用于复用 errorCapturingResponseWriter 实例的对象池
 var errorResponseWriterPool = sync.Pool{
 	New: func() any {
+		ecwPoolCounters.news.Add(1)
 		return &errorCapturingResponseWriter{
 			headerSnapshot: make(http.Header), // 预先初始化 map, 减少 reset 时的分配
 		}
@@ -43,17 +48,29 @@ func (ecw *errorCapturingResponseWriter) reset(w http.ResponseWriter, r *http.Re
 	ecw.errorHandlerFunc = eh
 	ecw.statusCode = 0
 	// 清空 headerSnapshot, 但保留底层容量, 避免再次分配
-	for k := range ecw.headerSnapshot {
-		delete(ecw.headerSnapshot, k)
+	clear(ecw.headerSnapshot)
+	// 以当前已设置的头部为起点, FileServer 可以读取到中间件设置的头部 (例如 Content-Type)
+	if w != nil {
+		for k, v := range w.Header() {
+			ecw.headerSnapshot[k] = append([]string(nil), v...)
+		}
+	}
+	ecw.intercept = nil
+	if ctx != nil && ctx.engine != nil {
+		ecw.intercept = ctx.engine.fileServerIntercept
 	}
 	ecw.capturedErrorSignal = false
 	ecw.responseStarted = false
+	ecw.fallback = nil
 }
 
 // AcquireErrorCapturingResponseWriter 从对象池获取一个 errorCapturingResponseWriter 实例
 // 必须在处理完成后调用 ReleaseErrorCapturingResponseWriter
 func AcquireErrorCapturingResponseWriter(c *Context) *errorCapturingResponseWriter {
 	ecw := errorResponseWriterPool.Get().(*errorCapturingResponseWriter)
+	if poolStatsEnabled.Load() {
+		ecwPoolCounters.gets.Add(1)
+	}
 	ecw.reset(c.Writer, c.Request, c, c.engine.errorHandle.handler) // 传入 Touka Context 的 Writer
 	return ecw
 }
@@ -61,42 +78,58 @@ func AcquireErrorCapturingResponseWriter(c *Context) *errorCapturingResponseWrit
 // ReleaseErrorCapturingResponseWriter 将一个 errorCapturingResponseWriter 实例返回到对象池
 func ReleaseErrorCapturingResponseWriter(ecw *errorCapturingResponseWriter) {
 	ecw.reset(nil, nil, nil, nil) // 清空敏感信息
+	if poolStatsEnabled.Load() {
+		ecwPoolCounters.puts.Add(1)
+	}
 	errorResponseWriterPool.Put(ecw)
 }
 
 // Header 返回一个 http.Header
-// 如果捕获到错误信号, 则操作内部的快照头部, 因为这些头部可能不会被发送, 或者会被 ErrorHandler 覆盖
-// 否则, 代理到原始 ResponseWriter 的 Header()
+// 响应开始之前, FileServer 设置的头部都写入快照: 成功响应时快照整体替换原始 ResponseWriter 的头部,
+// 被拦截的错误响应则丢弃快照, 避免 Content-Range、ETag 等头部泄漏到 ErrorHandler 的响应中
 func (ecw *errorCapturingResponseWriter) Header() http.Header {
-	if ecw.capturedErrorSignal {
-		return ecw.headerSnapshot
+	if ecw.responseStarted {
+		return ecw.w.Header()
 	}
-	// 返回原始 ResponseWriter 的 Header(), 确保 FileServer 设置的头部直接作用于最终响应
-	return ecw.w.Header()
+	return ecw.headerSnapshot
+}
+
+// intercepts 判断状态码是否需要交给 ErrorHandler 处理
+func (ecw *errorCapturingResponseWriter) intercepts(statusCode int) bool {
+	if statusCode < 400 {
+		return false
+	}
+	return len(ecw.intercept) == 0 || slices.Contains(ecw.intercept, statusCode)
+}
+
+// commitHeader 将快照中的头部 (包括 FileServer 删除的头部) 同步到原始 ResponseWriter 并发送状态码
+func (ecw *errorCapturingResponseWriter) commitHeader(statusCode int) {
+	h := ecw.w.Header()
+	for k := range h {
+		if _, ok := ecw.headerSnapshot[k]; !ok {
+			delete(h, k)
+		}
+	}
+	maps.Copy(h, ecw.headerSnapshot)
+	ecw.w.WriteHeader(statusCode)
+	ecw.responseStarted = true
 }
 
 // WriteHeader 记录状态码
-// 如果状态码表示错误 (>=400), 则激活 capturedErrorSignal 并不将状态码传递给原始 ResponseWriter
-// 如果状态码表示成功, 则将快照中的头部（如果有）复制到原始 w, 然后调用原始 w.WriteHeader
+// 如果状态码需要拦截 (默认为 >=400), 则激活 capturedErrorSignal 并不将状态码传递给原始 ResponseWriter
+// 否则 (包括 3xx 重定向与 304) 将快照中的头部同步到原始 w, 然后调用原始 w.WriteHeader
 func (ecw *errorCapturingResponseWriter) WriteHeader(statusCode int) {
-	if ecw.responseStarted {
-		return // 响应已开始, 忽略后续的 WriteHeader 调用
+	if ecw.responseStarted || ecw.capturedErrorSignal {
+		return // 响应已开始或已被拦截, 忽略后续的 WriteHeader 调用
 	}
 	ecw.statusCode = statusCode
 
-	if ecw.Status() >= 400 {
+	if ecw.intercepts(statusCode) {
+		// 激活错误信号, 不会将这个 WriteHeader 传递给原始的 w, 等待 processAfterFileServer 处理
 		ecw.capturedErrorSignal = true
-		// 是一个错误状态码 (>=400), 激活错误信号
-		// 不会将这个 WriteHeader 传递给原始的 w, 等待 processAfterFileServer 处理
-	} else {
-		// 是成功状态码
-		// 将 ecw.headerSnapshot 中（由 FileServer 在此之前通过 ecw.Header() 设置的）
-		// 任何头部直接复制到原始的 w.Header(), 确保多值头部正确传递
-		// 直接赋值 []string, 保留所有值
-		maps.Copy(ecw.w.Header(), ecw.headerSnapshot)
-		ecw.w.WriteHeader(statusCode) // 实际写入状态码到原始 ResponseWriter
-		ecw.responseStarted = true    // 标记成功响应已开始
+		return
 	}
+	ecw.commitHeader(statusCode)
 }
 
 // Write 将数据写入响应
@@ -111,15 +144,30 @@ func (ecw *errorCapturingResponseWriter) Write(data []byte) (int, error) {
 		if ecw.statusCode == 0 { // 如果 statusCode 仍为0 (WriteHeader 从未被显式调用)
 			ecw.statusCode = http.StatusOK // 隐式 200 OK
 		}
-		// 将 headerSnapshot 中的头部复制到原始 ResponseWriter 的 Header
-		// 直接赋值 []string, 保留所有值
-		maps.Copy(ecw.w.Header(), ecw.headerSnapshot)
-		ecw.w.WriteHeader(ecw.Status()) // 发送实际的状态码 (可能是 200 或之前设置的 2xx)
-		ecw.responseStarted = true
+		ecw.commitHeader(ecw.statusCode)
 	}
 	return ecw.w.Write(data) // 写入数据到原始 ResponseWriter
 }
 
+// ReadFrom 使 http.ServeContent 发送内存映射的文件 (见 MmapDir) 时直接写出映射的内存;
+// 成功路径上的其他来源交给原始 ResponseWriter 的 ReadFrom, 保留 *os.File 的 sendfile 路径,
+// 原始 ResponseWriter 不支持时按 io.Copy 的方式经由 Write 复制
+func (ecw *errorCapturingResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if n, ok, err := readFromMapped(ecw, r); ok {
+		return n, err
+	}
+	if rf, ok := ecw.w.(io.ReaderFrom); ok && !ecw.capturedErrorSignal {
+		if !ecw.responseStarted {
+			if ecw.statusCode == 0 {
+				ecw.statusCode = http.StatusOK
+			}
+			ecw.commitHeader(ecw.statusCode)
+		}
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(struct{ io.Writer }{ecw}, r)
+}
+
 // Flush 尝试刷新缓冲的数据到客户端
 // 仅当未捕获错误且响应已开始, 并且原始 ResponseWriter 支持 http.Flusher 时才执行
 func (ecw *errorCapturingResponseWriter) Flush() {
@@ -135,6 +183,11 @@ func (ecw *errorCapturingResponseWriter) Flush() {
 // 它将调用配置的 ErrorHandlerFunc 来处理错误
 func (ecw *errorCapturingResponseWriter) processAfterFileServer() {
 	if ecw.capturedErrorSignal && !ecw.responseStarted {
+		// 显式配置的回退 (SPA index / 自定义 404 页面) 优先于 noRoute
+		if ecw.statusCode == http.StatusNotFound && ecw.fallback != nil && ecw.fallback.serve(ecw.ctx) {
+			ecw.ctx.Abort()
+			return
+		}
 		if ecw.ctx.engine.noRoute != nil {
 			ecw.ctx.Next()
 		} else {
@@ -0,0 +1,51 @@
+package touka
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFileServerPassesThroughRedirectAndNotModified(t *testing.T) {
+	modTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := http.FS(fstest.MapFS{
+		"docs/index.html": {Data: []byte("docs"), ModTime: modTime},
+		"a.txt":           {Data: []byte("hello"), ModTime: modTime},
+	})
+	engine := New()
+	engine.StaticFS("/files", fsys)
+
+	w := PerformRequest(engine, http.MethodGet, "/files/docs", nil, nil)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "docs/" {
+		t.Fatalf("expected redirect to pass through, got %d %v", w.Code, w.Header())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/files/a.txt", nil, http.Header{"If-Modified-Since": {modTime.Format(http.TimeFormat)}})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected 304 to pass through, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestFileServerErrorDoesNotLeakHeaders(t *testing.T) {
+	fsys := http.FS(fstest.MapFS{"a.txt": {Data: []byte("hello")}})
+	engine := New()
+	engine.StaticFS("/files", fsys)
+
+	w := PerformRequest(engine, http.MethodGet, "/files/a.txt", nil, http.Header{"Range": {"bytes=100-200"}})
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("expected 416, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Range") != "" || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("file server headers leaked into the error response: %v", w.Header())
+	}
+
+	engine.SetFileServerInterceptStatuses(http.StatusNotFound)
+	w = PerformRequest(engine, http.MethodGet, "/files/a.txt", nil, http.Header{"Range": {"bytes=100-200"}})
+	if w.Code != http.StatusRequestedRangeNotSatisfiable || w.Header().Get("Content-Range") != "bytes */5" {
+		t.Fatalf("expected the original 416 response, got %d %v", w.Code, w.Header())
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/files/missing.txt", nil, nil); w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("expected 404 to still use the error handler, got %v", w.Header())
+	}
+}
@@ -7,15 +7,22 @@ package touka
 import (
 	"context"
 	"errors"
+	"fmt"
+	"html/template"
 	"io"
+	"os"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"unicode/utf8"
 
 	"net/http"
+	"net/netip"
 
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/WJQSERVER-STUDIO/httpc"
 	"github.com/fenthope/reco"
@@ -36,7 +43,9 @@ func (c HandlersChain) Last() HandlerFunc {
 type Engine struct {
 	methodTrees methodTrees // 存储所有HTTP方法的路由树
 
-	pool sync.Pool // Context Pool 用于复用 Context 对象,提高性能
+	pool            sync.Pool    // Context Pool 用于复用 Context 对象,提高性能
+	ctxPoolCounters poolCounters // Context Pool 的使用统计
+	poolMaxSliceCap int          // 放回 Context Pool 时保留的切片容量上限, 0 表示不限制
 
 	globalHandlers HandlersChain // 全局中间件,应用于所有路由
 
@@ -48,7 +57,9 @@ type Engine struct {
 	HandleMethodNotAllowed bool     // 是否启用 MethodNotAllowed 处理器
 	ForwardByClientIP      bool     // 是否信任 X-Forwarded-For 等头部获取客户端 IP
 	RemoteIPHeaders        []string // 用于获取客户端 IP 的头部列表,例如 {"X-Forwarded-For", "X-Real-IP"}
-	// TrustedProxies        []string // 可信代理 IP 列表,用于判断是否使用 X-Forwarded-For 等头部 (预留接口)
+
+	trustedProxies []netip.Prefix // 可信代理网段, 通过 SetTrustedProxies 设置
+	canonicalURL   string         // 通过 SetCanonicalURL 设置的站点规范地址, 不以 / 结尾
 
 	HTTPClient *httpc.Client // 用于在此上下文中执行出站 HTTP 请求
 
@@ -60,7 +71,7 @@ type Engine struct {
 	// 优先级: logger > LogReco
 	logger Logger
 
-	HTMLRender any // 用于 HTML 模板渲染,可以设置为 *template.Template 或自定义渲染器接口
+	HTMLRender any // 用于 HTML 模板渲染,可以设置为 *template.Template 或 HTMLRenderer
 
 	routesInfo []RouteInfo // 存储所有注册的路由信息
 
@@ -91,6 +102,74 @@ type Engine struct {
 	// GlobalMaxRequestBodySize 全局请求体Body大小限制
 	GlobalMaxRequestBodySize int64
 
+	maxUploadFileSize  int64 // 单个上传文件的大小上限, 通过 SetUploadLimits 设置
+	maxUploadTotalSize int64 // 单个请求中上传文件的总大小上限
+	multipartMemory    int64 // 解析 multipart 表单时保留在内存中的上限, 0 表示默认的 32MB
+
+	bindTimeout   time.Duration // 绑定时读取请求体的累计时间上限, 通过 SetBindTimeout 设置
+	renderTimeout time.Duration // 渲染模板的时间上限, 通过 SetRenderTimeout 设置
+
+	featureFlags   FeatureFlagProvider           // 功能开关提供者
+	featureContext func(*Context) FeatureContext // 构建功能开关的求值上下文
+
+	signalMu       sync.Mutex
+	signalHandlers map[os.Signal][]SignalHandler // 通过 OnSignal 注册的信号处理器
+
+	mode           Mode   // 运行模式, 通过 SetMode 设置
+	environment    string // 部署环境, 通过 SetEnvironment 或 TOUKA_ENV 环境变量设置
+	templateReload bool   // 是否在每次渲染时重新解析模板
+	htmlGlob       string // LoadHTMLGlob 使用的模板路径模式
+
+	routeErrors []error // 注册路由时发现的问题, 由 Validate 汇总报告
+
+	smugglingGuard *SmugglingGuard // 请求走私防护, 在路由之前检查请求
+
+	pathPolicy PathPolicy // 路由前的路径规范化策略
+
+	routeMeta     map[routeKey]RouteMeta       // 通过 WithMeta 附加的路由元数据
+	namedRoutes   map[string]string            // 通过 RouteName 命名的路由 -> 路径
+	routeVariants map[routeKey][]*routeVariant // 路由树路径 -> 注册路径不同的路由, 例如 /img/:name.:ext 与 /archive/:year/:month?
+
+	observeFilter *ObservabilityFilter // 观测中间件共用的过滤规则, nil 时记录全部请求
+
+	locker  Locker  // 按键互斥的锁, 默认为进程内实现
+	limiter Limiter // 按键限流, 默认为进程内实现
+
+	flashStore FlashStore       // 闪现消息存储, 默认保存在 cookie 中
+	funcMap    template.FuncMap // LoadHTMLGlob 解析模板时注册的函数
+	tplFuncs   template.FuncMap // 通过 UseTemplateFuncs 启用的函数库
+	assets     *AssetManifest   // 通过 MountAssets 注册的静态资源清单
+	sanitizer  *Sanitizer       // 通过 SetSanitizer 设置的 HTML 清理器, nil 时使用默认策略
+
+	cacheStore CacheStore     // 引擎级缓存存储, 默认为进程内实现
+	fragments  *FragmentCache // 基于 cacheStore 的片段缓存
+
+	memoStore MemoStore        // Memoize 使用的存储, 默认为进程内实现
+	memos     map[string]*Memo // 通过 Memoize 注册的记忆值
+
+	jsonConfig       *jsonConfig // 通过 SetJSONOptions 设置的 JSON 编解码选项, nil 时使用默认行为
+	secureJSONPrefix string      // SecureJSON 的响应前缀, 为空时使用 DefaultSecureJSONPrefix
+
+	conns *connTracker // 通过 ConnState 统计的服务器连接状态
+
+	acmeChallenge bool // 是否注册了 ACMEChallenge, 重定向服务器据此放行验证请求
+
+	errorCounters [2]atomic.Uint64 // 按 ErrorClass 统计的 AddError 次数
+	panicAsError  bool             // 默认 Recovery 是否将 panic 转换为 *PanicError 交给 ErrorHandler
+
+	fileServerIntercept []int // 文件服务器中交给 ErrorHandler 处理的状态码, 为空时为所有 >= 400 的状态码
+	staticMmapMinSize   int64 // StaticDir 与 StaticFile 使用内存映射的文件大小下限, 0 表示不使用
+
+	allowedOrigins []string // 通过 SetAllowedOrigins 设置的跨域来源, 为空时只允许同源
+
+	memoryBudget int64 // 通过 SetMemoryBudget 设置的每请求内存预算, 0 表示不限制
+
+	bindings map[string]Binding // 通过 RegisterBinding 注册的请求体格式, 键为小写的媒体类型
+
+	started      time.Time       // 引擎创建时间, 用于 Stats 的 Uptime
+	requests     requestCounters // 处理中与按方法的累计请求数
+	statsEnabled bool            // 通过 EnableStats 开启请求数与对象池取用次数的统计
+
 	notFoundChain            HandlersChain
 	notFoundNoMethodChain    HandlersChain
 	unmatchedFSChain         HandlersChain
@@ -167,8 +246,8 @@ func writeDefaultErrorJSON(c *Context, code int, body []byte) {
 
 var methodNotAllowedHandler HandlerFunc = func(c *Context) {
 	httpMethod := c.Request.Method
-	requestPath := routeLookupPath(c.Request)
 	engine := c.engine
+	requestPath := engine.lookupPath(c.Request)
 	// 是否是OPTIONS方式
 	if httpMethod == http.MethodOptions {
 		// 如果是 OPTIONS 请求,尝试查找所有允许的方法
@@ -224,7 +303,7 @@ func defaultErrorHandle(c *Context, code int, err error) { // 检查客户端是
 		if c.Writer.Written() {
 			return
 		}
-		if len(c.Errors) == 0 {
+		if c.ErrorCount() == 0 {
 			switch {
 			case code == http.StatusNotFound && errors.Is(err, errNotFound):
 				writeDefaultErrorJSON(c, code, defaultNotFoundBody)
@@ -236,7 +315,11 @@ func defaultErrorHandle(c *Context, code int, err error) { // 检查客户端是
 		}
 		// 输出json 状态码与状态码对应描述
 		var errMsg string
-		if err != nil {
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			// panic 的值可能包含内部信息, 不返回给客户端
+			errMsg = errPanicInternal.Error()
+		} else if err != nil {
 			errMsg = err.Error()
 		}
 		c.JSON(code, defaultErrorResponse{Code: code, Message: http.StatusText(code), Error: errMsg})
@@ -259,10 +342,16 @@ func defaultErrorWarp(handler ErrorHandler) ErrorHandler {
 			}
 		}
 		// 查看context内有没有收集到error
-		if len(c.Errors) > 0 {
-			c.Errorf("errpage: context errors: %v, current error: %v", errors.Join(c.Errors...), err)
+		if errs := c.GetErrors(); len(errs) > 0 {
+			joined := errors.Join(errs...)
+			// 只有客户端错误时降低日志级别, 避免错误请求刷屏
+			if c.HasServerError() {
+				c.Errorf("errpage: context errors: %v, current error: %v", joined, err)
+			} else {
+				c.Warnf("errpage: context client errors: %v, current error: %v", joined, err)
+			}
 			if err == nil {
-				err = errors.Join(c.Errors...)
+				err = joined
 			}
 		}
 		// 如果客户端已经断开连接，则不尝试写入响应
@@ -280,6 +369,7 @@ func defaultErrorWarp(handler ErrorHandler) ErrorHandler {
 type UnMatchFS struct {
 	FSForUnmatched     http.FileSystem
 	ServeUnmatchedAsFS bool
+	Options            UnMatchFSOptions // 通过 SetUnMatchFSOptions 设置的回退选项
 }
 
 // ProtocolsConfig 协议版本配置结构体
@@ -313,7 +403,16 @@ func New() *Engine {
 		ServerConfigurator:       nil,
 		TLSServerConfigurator:    nil,
 		GlobalMaxRequestBodySize: -1,
+		locker:                   NewLocalLocker(),
+		limiter:                  NewLocalLimiter(),
+		flashStore:               &CookieFlashStore{},
+		cacheStore:               NewMemoryCacheStore(),
+		memoStore:                NewMemoryMemoStore(),
+		conns:                    newConnTracker(),
+		environment:              os.Getenv(EnvironmentVar),
+		started:                  time.Now(),
 	}
+	engine.fragments = &FragmentCache{engine: engine, calls: make(map[string]*fragmentCall)}
 	engine.rebuildFallbackChains()
 	engine.shutdownCtx, engine.shutdownCancel = context.WithCancel(context.Background())
 	//engine.SetProtocols(GetDefaultProtocolsConfig())
@@ -321,6 +420,7 @@ func New() *Engine {
 	engine.SetLoggerCfg(defaultLogRecoConfig)
 	// 初始化 Context Pool,为每个新 Context 实例提供一个构造函数
 	engine.pool.New = func() any {
+		engine.ctxPoolCounters.news.Add(1)
 		return &Context{
 			Writer:     newResponseWriter(nil),            // 初始时可以传入nil,在ServeHTTP中会重新设置实际的 http.ResponseWriter
 			Params:     make(Params, 0, engine.maxParams), // 预分配 Params 切片以减少内存分配
@@ -367,6 +467,13 @@ func (engine *Engine) SetRedirectFixedPath(enable bool) {
 	engine.RedirectFixedPath = enable
 }
 
+// 是否将 panic 转换为 *PanicError
+// 开启后默认的 Recovery 会把恢复的 panic 包装为携带堆栈的 *PanicError, 记录到 c.Errors 并作为 err 交给 ErrorHandler,
+// 使 panic、处理函数错误与绑定错误共用同一条错误处理链
+func (engine *Engine) SetPanicAsError(enable bool) {
+	engine.panicAsError = enable
+}
+
 // 是否开启MethodNotAllowed
 func (engine *Engine) SetHandleMethodNotAllowed(enable bool) {
 	engine.HandleMethodNotAllowed = enable
@@ -407,21 +514,39 @@ func (engine *Engine) GetDefaultErrHandler() ErrorHandler {
 	return defaultErrorHandle
 }
 
+// SetFileServerInterceptStatuses 设置文件服务器 (StaticDir、StaticFS、UnMatchFS 等) 中交给 ErrorHandler 处理的状态码.
+// 默认拦截所有 >= 400 的状态码; 指定后只拦截列出的状态码, 其余状态码 (例如 416) 按文件服务器的原始响应返回.
+// 不带参数调用时恢复默认行为. 3xx 重定向与 304 始终原样返回
+func (engine *Engine) SetFileServerInterceptStatuses(codes ...int) {
+	engine.fileServerIntercept = slices.Clone(codes)
+}
+
 func (engine *Engine) SetUnMatchFS(fs http.FileSystem, handlers ...HandlerFunc) {
 	engine.SetUnMatchFSChain(fs, handlers...)
 }
 
 func (engine *Engine) SetUnMatchFSChain(fs http.FileSystem, handlers ...HandlerFunc) {
+	engine.SetUnMatchFSOptions(fs, UnMatchFSOptions{}, handlers...)
+}
+
+// SetUnMatchFSOptions 与 SetUnMatchFS 相同, 并允许配置文件不存在时的回退:
+// 单页应用的 index 回退与自定义 404 页面, 参见 UnMatchFSOptions
+func (engine *Engine) SetUnMatchFSOptions(fs http.FileSystem, opts UnMatchFSOptions, handlers ...HandlerFunc) {
 	if fs != nil {
 		engine.unMatchFS.FSForUnmatched = fs
 		engine.unMatchFS.ServeUnmatchedAsFS = true
+		engine.unMatchFS.Options = opts
 		unMatchFileServer := GetStaticFSHandleFunc(http.FileServer(fs))
+		if opts.SPAIndex != "" || opts.NotFoundFile != "" {
+			unMatchFileServer = unmatchedFSHandleFunc(http.FileServer(fs), &fsFallback{fs: fs, opts: opts})
+		}
 		combinedChain := make(HandlersChain, len(handlers)+1)
 		copy(combinedChain, handlers)
 		combinedChain[len(handlers)] = unMatchFileServer
 		engine.UnMatchFSRoutes = combinedChain
 	} else {
 		engine.unMatchFS.ServeUnmatchedAsFS = false
+		engine.unMatchFS.Options = UnMatchFSOptions{}
 		engine.UnMatchFSRoutes = nil
 	}
 	engine.rebuildFallbackChains()
@@ -499,6 +624,32 @@ func (engine *Engine) SetForwardByClientIP(enable bool) {
 	engine.ForwardByClientIP = enable
 }
 
+// SetTrustedProxies 设置可信代理的 IP 或 CIDR 列表.
+// 设置后只有来自这些地址的请求才会按 RemoteIPHeaders 解析客户端 IP, 并且 X-Forwarded-Proto 等
+// 代理头部会被 c.IsTLS 等方法采信; 传入空列表恢复默认行为 (解析 IP 头部, 但不采信协议头部)
+func (engine *Engine) SetTrustedProxies(proxies []string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if strings.Contains(p, "/") {
+			prefix, err := netip.ParsePrefix(p)
+			if err != nil {
+				return fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(p)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	engine.trustedProxies = prefixes
+	return nil
+}
+
 // SetHTTPClient 设置 Engine 使用的 httpc.Client
 func (engine *Engine) SetHTTPClient(client *httpc.Client) {
 	if client != nil {
@@ -526,7 +677,10 @@ func (engine *Engine) registerMethodTree(method string) *node {
 // addRoute 将一个路由及处理函数链添加到路由树中
 // 这是框架内部路由注册的核心逻辑
 // groupPath 用于记录路由所属的分组路径
-func (engine *Engine) addRoute(method, absolutePath, groupPath string, handlers HandlersChain) { // relativePath 更名为 absolutePath
+func (engine *Engine) addRoute(method, absolutePath, groupPath string, handlers HandlersChain, meta RouteMeta) { // relativePath 更名为 absolutePath
+	site := registrationSite()
+	defer engine.annotateRoutePanic(method, absolutePath, site)
+
 	if absolutePath == "" {
 		panic("absolute path must not be empty")
 	}
@@ -534,17 +688,52 @@ func (engine *Engine) addRoute(method, absolutePath, groupPath string, handlers
 		panic("handlers must not be empty")
 	}
 
+	nilHandlers := 0
+	for _, h := range handlers {
+		if h == nil {
+			nilHandlers++
+		}
+	}
+	if nilHandlers > 0 {
+		engine.routeErrors = append(engine.routeErrors, fmt.Errorf("route %s %s has %d nil handler(s)", method, absolutePath, nilHandlers))
+	}
+
 	// 检查并更新 maxParams,使用 absolutePath
 	if n := countParams(absolutePath); n > engine.maxParams {
 		engine.maxParams = n
 	}
 
 	root := engine.registerMethodTree(method)
-	root.addRoute(absolutePath, handlers) // 调用 node 的 addRoute 方法将路由添加到树中
+	// 可选参数展开为多个路径, 省略的参数取空值
+	paths, optional := expandOptionalParams(absolutePath)
+	if paths == nil {
+		paths = []string{absolutePath}
+	}
+	for i, path := range paths {
+		// /img/:name.:ext 等路径在路由树中按 /img/:name 注册, 匹配后再拆分参数
+		treePath, segments := splitRoutePattern(path)
+		if treePath == absolutePath {
+			if _, ok := engine.routeVariants[routeKey{method: method, path: absolutePath}]; ok {
+				panic(fmt.Sprintf("touka: route %s conflicts with existing routes that capture extensions or optional parameters", absolutePath))
+			}
+			root.addRoute(absolutePath, handlers) // 调用 node 的 addRoute 方法将路由添加到树中
+			continue
+		}
+		variant := &routeVariant{pattern: absolutePath, segments: segments, absent: optional[i:], handlers: handlers}
+		if engine.addRouteVariant(method, treePath, variant) {
+			root.addRoute(treePath, handlers)
+		}
+	}
 
 	handlerName := "unknown"
 	if len(handlers) > 0 {
 		handlerName = getHandlerName(handlers.Last())
+		// 类型化处理器的请求与响应类型供 OpenAPI 导出使用, 显式设置的元数据优先
+		if types, ok := typedRouteTypes(handlers.Last()); ok {
+			if _, set := meta[MetaTypes]; !set {
+				meta = meta.with(MetaTypes, types)
+			}
+		}
 	}
 
 	engine.routesInfo = append(engine.routesInfo, RouteInfo{
@@ -552,7 +741,21 @@ func (engine *Engine) addRoute(method, absolutePath, groupPath string, handlers
 		Path:    absolutePath, // 使用完整的绝对路径
 		Handler: handlerName,
 		Group:   groupPath,
+		Meta:    meta,
+
+		Source:     site,
+		Wildcards:  routeWildcards(absolutePath),
+		Middleware: middlewareNames(handlers),
 	})
+	if len(meta) > 0 {
+		if engine.routeMeta == nil {
+			engine.routeMeta = make(map[routeKey]RouteMeta)
+		}
+		engine.routeMeta[routeKey{method: method, path: absolutePath}] = meta
+		if name, ok := meta[MetaRouteName].(string); ok {
+			engine.nameRoute(name, absolutePath)
+		}
+	}
 }
 
 // getHandlerName 辅助函数,用于获取 HandlerFunc 的名称
@@ -578,6 +781,7 @@ const MaxSkippedNodesCap = 256
 // TempSkippedNodesPool 存储 *[]skippedNode 以复用内存
 var TempSkippedNodesPool = sync.Pool{
 	New: func() any {
+		skippedNodesPoolCounters.news.Add(1)
 		// 返回一个指向容量为 256 的新切片的指针
 		s := make([]skippedNode, 0, MaxSkippedNodesCap)
 		return &s
@@ -587,6 +791,9 @@ var TempSkippedNodesPool = sync.Pool{
 // GetTempSkippedNodes 从 Pool 中获取一个 *[]skippedNode 指针
 func GetTempSkippedNodes() *[]skippedNode {
 	// 直接返回 Pool 中存储的指针
+	if poolStatsEnabled.Load() {
+		skippedNodesPoolCounters.gets.Add(1)
+	}
 	return TempSkippedNodesPool.Get().(*[]skippedNode)
 }
 
@@ -605,6 +812,9 @@ func PutTempSkippedNodes(skippedNodes *[]skippedNode) {
 	*skippedNodes = (*skippedNodes)[:0]
 
 	// 将指针存回 Pool
+	if poolStatsEnabled.Load() {
+		skippedNodesPoolCounters.puts.Add(1)
+	}
 	TempSkippedNodesPool.Put(skippedNodes)
 }
 
@@ -695,7 +905,7 @@ func (engine *Engine) Handle(httpMethod, relativePath string, handlers ...Handle
 	absolutePath := resolveRoutePath("/", relativePath)
 	// 修正：将全局中间件与此路由的处理函数合并
 	fullHandlers := engine.combineHandlers(engine.globalHandlers, handlers)
-	engine.addRoute(httpMethod, absolutePath, "/", fullHandlers)
+	engine.addRoute(httpMethod, absolutePath, "/", fullHandlers, nil)
 }
 
 // GET 注册 GET 方法的路由
@@ -765,6 +975,7 @@ type RouterGroup struct {
 	Handlers HandlersChain // 组中间件,仅应用于当前组及其子组的路由
 	basePath string        // 组路径前缀
 	engine   *Engine       // 指向 Engine 实例,用于注册路由到全局路由树
+	meta     RouteMeta     // 组元数据,由当前组及其子组的路由继承
 }
 
 // Use 将中间件应用于当前路由组
@@ -779,7 +990,7 @@ func (group *RouterGroup) Use(middleware ...HandlerFunc) Router {
 func (group *RouterGroup) Handle(httpMethod, relativePath string, handlers ...HandlerFunc) {
 	absolutePath := resolveRoutePath(group.basePath, relativePath)
 	fullHandlers := group.engine.combineHandlers(group.Handlers, handlers)
-	group.engine.addRoute(httpMethod, absolutePath, group.basePath, fullHandlers)
+	group.engine.addRoute(httpMethod, absolutePath, group.basePath, fullHandlers, group.meta)
 }
 
 // GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS, ANY 方法与 Engine 类似,只是通过 Group 的 Handle 方法注册
@@ -820,12 +1031,18 @@ func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) Ro
 		Handlers: group.engine.combineHandlers(group.Handlers, handlers),
 		basePath: resolveRoutePath(group.basePath, relativePath),
 		engine:   group.engine, // 指向 Engine 实例
+		meta:     group.meta,   // 继承组元数据
 	}
 }
 
 // ServeHTTP 实现了 http.Handler 接口,是 Engine 处理所有 HTTP 请求的入口
 // 每个传入的 HTTP 请求都会调用此方法
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if engine.statsEnabled {
+		engine.serveHTTPWithStats(w, req)
+		return
+	}
+
 	// 从 Context Pool 中获取一个 Context 对象进行复用
 	c := engine.pool.Get().(*Context)
 	c.reset(w, req) // 重置 Context 对象的状态以适应当前请求
@@ -834,19 +1051,43 @@ func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	engine.handleRequest(c)
 
 	// 将 Context 对象放回 Context Pool,以供下次复用
-	engine.pool.Put(c)
+	engine.releaseContext(c)
+}
+
+// serveHTTPWithStats 是开启 EnableStats 后的 ServeHTTP, 额外统计处理中与按方法的请求数
+func (engine *Engine) serveHTTPWithStats(w http.ResponseWriter, req *http.Request) {
+	engine.requests.begin()
+	defer engine.requests.end()
+
+	c := engine.pool.Get().(*Context)
+	engine.ctxPoolCounters.gets.Add(1)
+	c.reset(w, req)
+	if c.requestObserved() {
+		engine.requests.count(req.Method)
+	}
+	engine.handleRequest(c)
+	engine.releaseContext(c)
 }
 
 // handleRequest 负责根据请求查找路由并执行相应的处理函数链
 // 这是路由查找和执行的核心逻辑
 func (engine *Engine) handleRequest(c *Context) {
+	if engine.smugglingGuard != nil && engine.smugglingGuard.checkRequest(c) {
+		return
+	}
+
 	if isGeneralOptionsRequest(c.Request) {
 		engine.handleGeneralOptions(c)
 		return
 	}
 
+	if err := engine.checkPath(c.Request); err != nil {
+		c.ErrorUseHandle(http.StatusBadRequest, err)
+		return
+	}
+
 	httpMethod := c.Request.Method
-	requestPath := routeLookupPath(c.Request)
+	requestPath := engine.lookupPath(c.Request)
 
 	// 查找对应的路由树的根节点
 	rootNode := engine.methodTrees.get(httpMethod) // 这里获取到的 rootNode 已经是 *node 类型
@@ -856,10 +1097,12 @@ func (engine *Engine) handleRequest(c *Context) {
 		// skippedNodes 内部使用,因此无需从外部传入已分配的 slice
 		// 直接在 rootNode 上调用 getValue 方法
 		value := rootNode.getValue(requestPath, &c.Params, &c.SkippedNodes, true) // unescape=true 对路径参数进行 URL 解码
+		engine.resolveRouteVariant(httpMethod, &value, &c.Params)
 
 		if value.handlers != nil {
 			//c.handlers = engine.combineHandlers(engine.globalHandlers, value.handlers) // 组合全局中间件和路由处理函数
 			c.handlers = value.handlers
+			c.fullPath = value.fullPath
 			c.Next() // 执行处理函数链
 			//c.Writer.Flush() // 确保所有缓冲的响应数据被发送
 			return
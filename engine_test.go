@@ -4,9 +4,12 @@ import (
 	"bufio"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"html/template"
 	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -241,6 +244,47 @@ func TestCustomErrorHandlerStillOverridesDefaultFastPath(t *testing.T) {
 	}
 }
 
+func TestContextErrorsConcurrentAccess(t *testing.T) {
+	engine := New()
+	engine.SetErrorHandler(func(c *Context, code int, err error) {
+		c.String(code, "%v", err)
+	})
+	engine.GET("/errors", func(c *Context) {
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.AddError(fmt.Errorf("worker %d", i))
+				_ = c.GetErrors()
+			}()
+		}
+		wg.Wait()
+		if c.ErrorCount() != 8 {
+			t.Errorf("expected 8 errors, got %d", c.ErrorCount())
+		}
+		c.ErrorUseHandle(http.StatusInternalServerError, nil)
+	})
+
+	rr := PerformRequest(engine, http.MethodGet, "/errors", nil, nil)
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	if got := strings.Count(rr.Body.String(), "worker"); got != 8 {
+		t.Fatalf("expected joined errors in body, got %q", rr.Body.String())
+	}
+}
+
+func TestGetErrorsReturnsSnapshot(t *testing.T) {
+	c, _ := CreateTestContext(nil)
+	c.AddError(errors.New("first"))
+	errs := c.GetErrors()
+	c.AddError(errors.New("second"))
+	if len(errs) != 1 || c.ErrorCount() != 2 {
+		t.Fatalf("expected snapshot of 1 error and count 2, got %d and %d", len(errs), c.ErrorCount())
+	}
+}
+
 func TestResponseHelpersCaptureWriteErrors(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -304,3 +348,22 @@ func TestDefaultErrorFastPathCapturesWriteErrors(t *testing.T) {
 		t.Fatal("expected fast path to abort context")
 	}
 }
+
+func TestContextFullPath(t *testing.T) {
+	engine := New()
+	engine.GET("/users/:id/files/*path", func(c *Context) {
+		c.String(http.StatusOK, "%s", c.FullPath())
+	})
+	engine.NoRoute(func(c *Context) {
+		c.String(http.StatusNotFound, "[%s]", c.FullPath())
+	})
+
+	rr := PerformRequest(engine, http.MethodGet, "/users/7/files/a/b.txt", nil, nil)
+	if rr.Body.String() != "/users/:id/files/*path" {
+		t.Fatalf("unexpected full path %q", rr.Body.String())
+	}
+	rr = PerformRequest(engine, http.MethodGet, "/missing", nil, nil)
+	if rr.Body.String() != "[]" {
+		t.Fatalf("expected empty full path for unmatched route, got %q", rr.Body.String())
+	}
+}
@@ -0,0 +1,124 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorClass 区分错误由客户端还是服务器引起, 决定默认状态码与日志级别
+type ErrorClass int
+
+const (
+	// ErrorClassServer 表示服务器错误 (5xx), 未分类的错误均视为服务器错误
+	ErrorClassServer ErrorClass = iota
+	// ErrorClassClient 表示客户端错误 (4xx), 例如请求格式错误或参数校验失败
+	ErrorClassClient
+)
+
+func (class ErrorClass) String() string {
+	if class == ErrorClassClient {
+		return "client"
+	}
+	return "server"
+}
+
+// ClassifiedError 是带有分类的错误
+type ClassifiedError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// ClientError 将 err 标记为客户端错误, err 为 nil 时返回 nil
+func ClientError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{Class: ErrorClassClient, Err: err}
+}
+
+// ServerError 将 err 标记为服务器错误, err 为 nil 时返回 nil
+func ServerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{Class: ErrorClassServer, Err: err}
+}
+
+// ErrorClassOf 返回错误的分类: 优先使用 ClientError/ServerError 的标记,
+// 其次根据 *HTTPError 的状态码判断, 其他错误视为服务器错误
+func ErrorClassOf(err error) ErrorClass {
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code >= 400 && httpErr.Code < 500 {
+		return ErrorClassClient
+	}
+	return ErrorClassServer
+}
+
+// errorStatus 返回错误对应的默认状态码
+func errorStatus(err error) int {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code >= 400 {
+		return httpErr.Code
+	}
+	if ErrorClassOf(err) == ErrorClassClient {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// AddClientError 以客户端错误记录 err
+func (c *Context) AddClientError(err error) {
+	c.AddError(ClientError(err))
+}
+
+// AddServerError 以服务器错误记录 err
+func (c *Context) AddServerError(err error) {
+	c.AddError(ServerError(err))
+}
+
+// HasServerError 报告收集的错误中是否包含服务器错误
+func (c *Context) HasServerError() bool {
+	for _, err := range c.GetErrors() {
+		if ErrorClassOf(err) == ErrorClassServer {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorStatus 根据收集的错误推导响应状态码: 存在服务器错误时取第一个服务器错误的状态码,
+// 只有客户端错误时取第一个客户端错误的状态码 (*HTTPError 使用其状态码, 其他客户端错误为 400),
+// 没有错误时返回 0
+func (c *Context) ErrorStatus() int {
+	status := 0
+	for _, err := range c.GetErrors() {
+		if ErrorClassOf(err) == ErrorClassServer {
+			return errorStatus(err)
+		}
+		if status == 0 {
+			status = errorStatus(err)
+		}
+	}
+	return status
+}
+
+// ErrorCounts 返回自引擎创建以来按分类统计的 AddError 次数
+func (engine *Engine) ErrorCounts() (client, server uint64) {
+	return engine.errorCounters[ErrorClassClient].Load(), engine.errorCounters[ErrorClassServer].Load()
+}
@@ -0,0 +1,61 @@
+package touka
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestErrorClassOf(t *testing.T) {
+	base := errors.New("boom")
+	cases := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{base, ErrorClassServer},
+		{ClientError(base), ErrorClassClient},
+		{fmt.Errorf("wrapped: %w", ClientError(base)), ErrorClassClient},
+		{NewHTTPError(http.StatusNotFound, nil), ErrorClassClient},
+		{NewHTTPError(http.StatusBadGateway, nil), ErrorClassServer},
+		{ServerError(NewHTTPError(http.StatusNotFound, nil)), ErrorClassServer},
+	}
+	for _, tc := range cases {
+		if got := ErrorClassOf(tc.err); got != tc.want {
+			t.Errorf("ErrorClassOf(%v) = %s, want %s", tc.err, got, tc.want)
+		}
+	}
+	if ClientError(nil) != nil {
+		t.Fatal("ClientError(nil) must be nil")
+	}
+}
+
+func TestErrorUseHandleDerivesStatus(t *testing.T) {
+	engine := New()
+	engine.GET("/client", func(c *Context) {
+		c.AddClientError(errors.New("bad input"))
+		c.AddError(NewHTTPError(http.StatusConflict, nil))
+		c.ErrorUseHandle(0, nil)
+	})
+	engine.GET("/server", func(c *Context) {
+		c.AddClientError(errors.New("bad input"))
+		c.AddError(errors.New("db down"))
+		c.ErrorUseHandle(0, nil)
+	})
+	engine.GET("/none", func(c *Context) {
+		c.ErrorUseHandle(0, nil)
+	})
+
+	for path, want := range map[string]int{
+		"/client": http.StatusBadRequest,
+		"/server": http.StatusInternalServerError,
+		"/none":   http.StatusInternalServerError,
+	} {
+		if w := PerformRequest(engine, http.MethodGet, path, nil, nil); w.Code != want {
+			t.Errorf("%s: expected status %d, got %d", path, want, w.Code)
+		}
+	}
+	if client, server := engine.ErrorCounts(); client != 3 || server != 1 {
+		t.Fatalf("unexpected error counts client=%d server=%d", client, server)
+	}
+}
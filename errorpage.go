@@ -0,0 +1,141 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ErrorPageOptions 配置 ErrorPages
+type ErrorPageOptions struct {
+	// Statuses 为需要拦截的响应状态码, 例如 404、500、502, 必填
+	Statuses []int
+	// Templates 为各状态码使用的 HTML 模板名, 键 0 为默认模板. 模板以 ErrorPageData 渲染;
+	// 没有对应模板时交给 ErrorHandler
+	Templates map[int]string
+	// HTMLOnly 为 true 时只拦截 Accept 包含 text/html 的请求, API 客户端仍收到原始响应
+	HTMLOnly bool
+}
+
+// ErrorPageData 是错误页面模板的数据
+type ErrorPageData struct {
+	Code    int
+	Message string // 状态码对应的描述
+	Path    string
+}
+
+// ErrorPages 返回拦截指定状态码的中间件, 类似 nginx 的 error_page: 后续任何处理器 (包括反向代理与文件服务)
+// 写出被拦截的状态码时, 原始的响应头与响应体被丢弃, 改为渲染对应的模板或交给 ErrorHandler:
+//
+//	r.Use(touka.ErrorPages(touka.ErrorPageOptions{
+//	    Statuses:  []int{404, 500, 502},
+//	    Templates: map[int]string{404: "errors/404.html", 0: "errors/5xx.html"},
+//	    HTMLOnly:  true,
+//	}))
+//
+// 响应已经开始流式输出 (先写出了其他状态码) 时无法拦截
+func ErrorPages(opts ErrorPageOptions) HandlerFunc {
+	if len(opts.Statuses) == 0 {
+		panic("touka: error pages require at least one status")
+	}
+	statuses := slices.Clone(opts.Statuses)
+	return func(c *Context) {
+		if opts.HTMLOnly && !strings.Contains(c.Request.Header.Get("Accept"), "text/html") {
+			c.Next()
+			return
+		}
+		original := c.Writer
+		w := &statusInterceptWriter{ResponseWriter: original, statuses: statuses}
+		c.Writer = w
+		defer func() {
+			c.Writer = original
+		}()
+		c.Next()
+		c.Writer = original
+		if w.intercepted == 0 {
+			return
+		}
+
+		code := w.intercepted
+		// 丢弃原始响应中描述响应体的头部
+		for _, h := range []string{"Content-Length", "Content-Encoding", "Content-Range", "Content-Disposition", "ETag", "Last-Modified"} {
+			original.Header().Del(h)
+		}
+		name, ok := opts.Templates[code]
+		if !ok {
+			name, ok = opts.Templates[0]
+		}
+		if ok && c.engine != nil && c.engine.HTMLRender != nil {
+			c.HTMLBuf(code, name, ErrorPageData{Code: code, Message: http.StatusText(code), Path: c.Request.URL.Path})
+			return
+		}
+		c.ErrorUseHandle(code, NewHTTPError(code, nil))
+	}
+}
+
+// statusInterceptWriter 在写出被拦截的状态码时丢弃后续写入
+type statusInterceptWriter struct {
+	ResponseWriter
+	statuses    []int
+	intercepted int // 被拦截的状态码, 0 表示未拦截
+	passthrough bool
+}
+
+func (w *statusInterceptWriter) WriteHeader(code int) {
+	if w.intercepted != 0 {
+		return
+	}
+	if !w.passthrough && code >= 200 {
+		if slices.Contains(w.statuses, code) {
+			w.intercepted = code
+			return
+		}
+		w.passthrough = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusInterceptWriter) Write(b []byte) (int, error) {
+	if w.intercepted != 0 {
+		return len(b), nil
+	}
+	if !w.passthrough && !w.ResponseWriter.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusInterceptWriter) Flush() {
+	if w.intercepted == 0 {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *statusInterceptWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.passthrough = true
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *statusInterceptWriter) Status() int {
+	if w.intercepted != 0 {
+		return w.intercepted
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *statusInterceptWriter) Size() int {
+	if w.intercepted != 0 {
+		return 0
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *statusInterceptWriter) Written() bool {
+	return w.intercepted != 0 || w.ResponseWriter.Written()
+}
@@ -0,0 +1,61 @@
+package touka
+
+import (
+	"html/template"
+	"net/http"
+	"testing"
+)
+
+func TestErrorPages(t *testing.T) {
+	engine := New()
+	engine.HTMLRender = template.Must(template.New("404.html").Parse(`<h1>{{.Code}} {{.Path}}</h1>`))
+	engine.Use(ErrorPages(ErrorPageOptions{
+		Statuses:  []int{http.StatusNotFound, http.StatusBadGateway},
+		Templates: map[int]string{http.StatusNotFound: "404.html"},
+	}))
+	engine.GET("/item", func(c *Context) {
+		c.SetHeader("ETag", `"x"`)
+		c.String(http.StatusNotFound, "item missing")
+	})
+	engine.GET("/upstream", func(c *Context) {
+		c.String(http.StatusBadGateway, "bad gateway from upstream")
+	})
+	engine.GET("/ok", func(c *Context) {
+		c.String(http.StatusOK, "fine")
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/item", nil, nil)
+	if w.Code != http.StatusNotFound || w.Body.String() != "<h1>404 /item</h1>" || w.Header().Get("ETag") != "" {
+		t.Fatalf("expected the 404 template, got %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/upstream", nil, nil)
+	if w.Code != http.StatusBadGateway || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("expected statuses without a template to use the error handler, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/ok", nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "fine" {
+		t.Fatalf("unexpected passthrough response %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorPagesHTMLOnly(t *testing.T) {
+	engine := New()
+	engine.SetErrorHandler(func(c *Context, code int, err error) {
+		c.String(code, "page %d", code)
+	})
+	engine.Use(ErrorPages(ErrorPageOptions{Statuses: []int{http.StatusNotFound}, HTMLOnly: true}))
+	engine.GET("/api", func(c *Context) {
+		c.JSON(http.StatusNotFound, H{"error": "no such user"})
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/api", nil, http.Header{"Accept": {"application/json"}})
+	if w.Body.String() != `{"error":"no such user"}` {
+		t.Fatalf("expected API clients to receive the original response, got %q", w.Body.String())
+	}
+	w = PerformRequest(engine, http.MethodGet, "/api", nil, http.Header{"Accept": {"text/html"}})
+	if w.Code != http.StatusNotFound || w.Body.String() != "page 404" {
+		t.Fatalf("expected browsers to receive the error page, got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,137 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"sync"
+)
+
+// FeatureContext 是功能开关的求值上下文
+type FeatureContext struct {
+	UserID     string
+	TenantID   string
+	Attributes map[string]any
+}
+
+// FeatureFlagProvider 是功能开关的求值接口, 可以接入任意功能开关服务
+type FeatureFlagProvider interface {
+	FeatureEnabled(ctx context.Context, name string, fc FeatureContext) bool
+}
+
+// FeatureFlagProviderFunc 是 FeatureFlagProvider 的函数适配器
+type FeatureFlagProviderFunc func(ctx context.Context, name string, fc FeatureContext) bool
+
+func (f FeatureFlagProviderFunc) FeatureEnabled(ctx context.Context, name string, fc FeatureContext) bool {
+	return f(ctx, name, fc)
+}
+
+// SetFeatureFlagProvider 设置功能开关提供者
+func (engine *Engine) SetFeatureFlagProvider(provider FeatureFlagProvider) {
+	engine.featureFlags = provider
+}
+
+// SetFeatureContextFunc 设置构建求值上下文的函数, 例如从认证信息中取出用户 ID.
+// 未设置时求值上下文只包含 Tenancy 中间件解析出的租户
+func (engine *Engine) SetFeatureContextFunc(fn func(c *Context) FeatureContext) {
+	engine.featureContext = fn
+}
+
+// FeatureContext 返回当前请求的功能开关求值上下文
+func (c *Context) FeatureContext() FeatureContext {
+	var fc FeatureContext
+	if c.engine != nil && c.engine.featureContext != nil {
+		fc = c.engine.featureContext(c)
+	}
+	if fc.TenantID == "" {
+		if tenant := c.Tenant(); tenant != nil {
+			fc.TenantID = tenant.ID
+		}
+	}
+	return fc
+}
+
+// FeatureEnabled 判断功能开关对当前请求是否开启, 未设置提供者时始终返回 false
+func (c *Context) FeatureEnabled(name string) bool {
+	if c.engine == nil || c.engine.featureFlags == nil {
+		return false
+	}
+	return c.engine.featureFlags.FeatureEnabled(c.Context(), name, c.FeatureContext())
+}
+
+// FeatureFlag 描述一个功能开关的规则
+type FeatureFlag struct {
+	// Enabled 为 false 时开关对所有人关闭
+	Enabled bool
+	// Users 与 Tenants 为始终开启的白名单
+	Users   []string
+	Tenants []string
+	// Percentage 为按用户 (无用户时按租户) 灰度的百分比, 0 表示不按比例放量, 100 表示全量
+	Percentage int
+}
+
+// MemoryFeatureFlags 是进程内的 FeatureFlagProvider 实现, 支持白名单与按比例灰度
+type MemoryFeatureFlags struct {
+	mu    sync.RWMutex
+	flags map[string]FeatureFlag
+}
+
+// NewMemoryFeatureFlags 创建一个进程内功能开关提供者
+func NewMemoryFeatureFlags(flags map[string]FeatureFlag) *MemoryFeatureFlags {
+	m := &MemoryFeatureFlags{flags: make(map[string]FeatureFlag, len(flags))}
+	for name, flag := range flags {
+		m.flags[name] = flag
+	}
+	return m
+}
+
+// Set 设置或替换一个功能开关
+func (m *MemoryFeatureFlags) Set(name string, flag FeatureFlag) {
+	m.mu.Lock()
+	m.flags[name] = flag
+	m.mu.Unlock()
+}
+
+func (m *MemoryFeatureFlags) FeatureEnabled(_ context.Context, name string, fc FeatureContext) bool {
+	m.mu.RLock()
+	flag, ok := m.flags[name]
+	m.mu.RUnlock()
+	if !ok || !flag.Enabled {
+		return false
+	}
+	if fc.UserID != "" && slices.Contains(flag.Users, fc.UserID) {
+		return true
+	}
+	if fc.TenantID != "" && slices.Contains(flag.Tenants, fc.TenantID) {
+		return true
+	}
+	if flag.Percentage >= 100 {
+		return true
+	}
+	if flag.Percentage <= 0 {
+		// 没有放量比例时, 仅白名单生效; 没有白名单则视为全量开启
+		return len(flag.Users) == 0 && len(flag.Tenants) == 0
+	}
+
+	key := fc.UserID
+	if key == "" {
+		key = fc.TenantID
+	}
+	if key == "" {
+		return false
+	}
+	return featureBucket(name, key) < flag.Percentage
+}
+
+// featureBucket 将 (开关, 用户) 稳定地映射到 [0, 100) 区间, 同一用户的结果不随请求变化
+func featureBucket(name, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return int(h.Sum32() % 100)
+}
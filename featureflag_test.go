@@ -0,0 +1,63 @@
+package touka
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestFeatureEnabled(t *testing.T) {
+	engine := New()
+	engine.SetFeatureFlagProvider(NewMemoryFeatureFlags(map[string]FeatureFlag{
+		"new-ui":  {Enabled: true},
+		"beta":    {Enabled: true, Users: []string{"alice"}},
+		"rollout": {Enabled: true, Percentage: 50},
+		"off":     {Enabled: false, Users: []string{"alice"}},
+	}))
+	engine.SetFeatureContextFunc(func(c *Context) FeatureContext {
+		return FeatureContext{UserID: c.GetReqHeader("X-User")}
+	})
+	engine.GET("/flag/:name", func(c *Context) {
+		c.String(http.StatusOK, "%t", c.FeatureEnabled(c.Param("name")))
+	})
+
+	cases := []struct {
+		flag, user, want string
+	}{
+		{"new-ui", "", "true"},
+		{"beta", "alice", "true"},
+		{"beta", "bob", "false"},
+		{"off", "alice", "false"},
+		{"missing", "alice", "false"},
+	}
+	for _, tc := range cases {
+		rr := PerformRequest(engine, http.MethodGet, "/flag/"+tc.flag, nil, http.Header{"X-User": []string{tc.user}})
+		if rr.Body.String() != tc.want {
+			t.Fatalf("%s for %q: expected %s, got %s", tc.flag, tc.user, tc.want, rr.Body.String())
+		}
+	}
+
+	// 灰度结果对同一用户稳定, 且大致符合比例
+	enabled := 0
+	for i := range 1000 {
+		user := "user-" + strconv.Itoa(i)
+		first := PerformRequest(engine, http.MethodGet, "/flag/rollout", nil, http.Header{"X-User": []string{user}}).Body.String()
+		second := PerformRequest(engine, http.MethodGet, "/flag/rollout", nil, http.Header{"X-User": []string{user}}).Body.String()
+		if first != second {
+			t.Fatalf("rollout result for %s is not stable", user)
+		}
+		if first == "true" {
+			enabled++
+		}
+	}
+	if enabled < 400 || enabled > 600 {
+		t.Fatalf("expected roughly 50%% rollout, got %d/1000", enabled)
+	}
+}
+
+func TestFeatureEnabledWithoutProvider(t *testing.T) {
+	c, _ := CreateTestContext(nil)
+	if c.FeatureEnabled("anything") {
+		t.Fatal("expected flags to be disabled without a provider")
+	}
+}
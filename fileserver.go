@@ -41,6 +41,11 @@ func FileServer(fs http.FileSystem) HandlerFunc {
 }
 
 func FileServerHandleServe(c *Context, fsHandle http.Handler) {
+	fileServerHandleServe(c, fsHandle, nil)
+}
+
+// fileServerHandleServe 与 FileServerHandleServe 相同, fallback 非空时由它处理文件服务器的 404
+func fileServerHandleServe(c *Context, fsHandle http.Handler, fallback *fsFallback) {
 	if fsHandle == nil {
 		c.AddError(ErrInputFSisNil)
 		// 500
@@ -75,6 +80,7 @@ func FileServerHandleServe(c *Context, fsHandle http.Handler) {
 	// 使用自定义的 ResponseWriter 包装器来捕获 FileServer 可能返回的错误状态码
 	ecw := AcquireErrorCapturingResponseWriter(c)
 	defer ReleaseErrorCapturingResponseWriter(ecw)
+	ecw.fallback = fallback
 
 	// 调用 http.FileServer 处理请求
 	fsHandle.ServeHTTP(ecw, c.Request)
@@ -96,7 +102,7 @@ func (engine *Engine) StaticDir(relativePath, rootPath string) {
 	}
 
 	// 创建一个文件系统处理器
-	fileServer := http.FileServer(http.Dir(rootPath))
+	fileServer := http.FileServer(engine.staticFileSystem(rootPath))
 
 	// 注册一个捕获所有路径的路由,使用自定义处理器
 	// 注意：这里使用 ANY 方法,但 FileServer 通常只处理 GET 和 HEAD
@@ -116,7 +122,7 @@ func (group *RouterGroup) StaticDir(relativePath, rootPath string) {
 	}
 
 	// 创建一个文件系统处理器
-	fileServer := http.FileServer(http.Dir(rootPath))
+	fileServer := http.FileServer(group.engine.staticFileSystem(rootPath))
 
 	// 注册一个捕获所有路径的路由,使用自定义处理器
 	// 注意：这里使用 ANY 方法,但 FileServer 通常只处理 GET 和 HEAD
@@ -130,7 +136,7 @@ func (engine *Engine) GetStaticDirHandle(rootPath string) HandlerFunc {
 	rootPath = path.Clean(rootPath)
 
 	// 创建一个文件系统处理器
-	fileServer := http.FileServer(http.Dir(rootPath))
+	fileServer := http.FileServer(engine.staticFileSystem(rootPath))
 
 	return GetStaticDirHandleFunc(fileServer)
 }
@@ -198,7 +204,7 @@ func (engine *Engine) GetStaticFileHandle(filePath string) HandlerFunc {
 	// 创建一个文件系统处理器,指向包含目标文件的目录
 	dir := path.Dir(filePath)
 	fileName := path.Base(filePath)
-	fileServer := http.FileServer(http.Dir(dir))
+	fileServer := http.FileServer(engine.staticFileSystem(dir))
 
 	return GetStaticFileHandleFunc(fileServer, fileName)
 }
@@ -211,7 +217,7 @@ func (group *RouterGroup) GetStaticFileHandle(filePath string) HandlerFunc {
 	// 创建一个文件系统处理器,指向包含目标文件的目录
 	dir := path.Dir(filePath)
 	fileName := path.Base(filePath)
-	fileServer := http.FileServer(http.Dir(dir))
+	fileServer := http.FileServer(group.engine.staticFileSystem(dir))
 
 	return GetStaticFileHandleFunc(fileServer, fileName)
 }
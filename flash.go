@@ -0,0 +1,208 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+// 常用的闪现消息级别
+const (
+	FlashInfo    = "info"
+	FlashSuccess = "success"
+	FlashWarning = "warning"
+	FlashError   = "error"
+)
+
+// Flash 是一条只显示一次的消息, 常用于 POST-重定向-GET 流程
+type Flash struct {
+	Level   string `json:"l"`
+	Message string `json:"m"`
+}
+
+// FlashStore 保存闪现消息, 可以替换为基于会话的实现
+type FlashStore interface {
+	// AddFlash 追加一条消息, 在之后的请求中可以读取
+	AddFlash(c *Context, flash Flash) error
+	// TakeFlashes 返回所有未读消息并清除它们
+	TakeFlashes(c *Context) ([]Flash, error)
+}
+
+// SetFlashStore 设置闪现消息存储, 默认使用 CookieFlashStore
+func (engine *Engine) SetFlashStore(store FlashStore) {
+	if store == nil {
+		panic("touka: flash store must not be nil")
+	}
+	engine.flashStore = store
+}
+
+// Flash 添加一条闪现消息, 下一个调用 TakeFlashes 的请求 (通常是重定向后的页面) 会读取它
+func (c *Context) Flash(level, message string) {
+	if err := c.engine.flashStore.AddFlash(c, Flash{Level: level, Message: message}); err != nil {
+		c.AddError(err)
+	}
+}
+
+// TakeFlashes 返回并清除所有未读的闪现消息
+func (c *Context) TakeFlashes() []Flash {
+	flashes, err := c.engine.flashStore.TakeFlashes(c)
+	if err != nil {
+		c.AddError(err)
+	}
+	return flashes
+}
+
+// FlashFuncMap 返回显示闪现消息的模板函数, LoadHTMLGlob 会自动注册它们:
+//
+//	{{range flashes .ctx}}<div class="alert-{{.Level}}">{{.Message}}</div>{{end}}
+//	{{range flashes .ctx | flashesOf "error"}}...{{end}}
+func FlashFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"flashes": func(c *Context) []Flash {
+			return c.TakeFlashes()
+		},
+		"flashesOf": func(level string, flashes []Flash) []Flash {
+			var out []Flash
+			for _, f := range flashes {
+				if f.Level == level {
+					out = append(out, f)
+				}
+			}
+			return out
+		},
+	}
+}
+
+// maxFlashCookieSize 为闪现消息 cookie 的上限, 超出时丢弃最早的消息
+const maxFlashCookieSize = 3072
+
+// flashStateKey 是当前请求闪现消息在 Context.Keys 中的键
+const flashStateKey = "touka.flash"
+
+// CookieFlashStore 将闪现消息保存在 cookie 中, 零值可用
+type CookieFlashStore struct {
+	// Name 为 cookie 名称, 默认 touka_flash
+	Name string
+	// Secret 非空时对 cookie 进行 HMAC 签名, 拒绝被篡改的消息
+	Secret []byte
+	// Secure 设置 cookie 的 Secure 属性
+	Secure bool
+}
+
+func (s *CookieFlashStore) name() string {
+	if s.Name == "" {
+		return "touka_flash"
+	}
+	return s.Name
+}
+
+func (s *CookieFlashStore) AddFlash(c *Context, flash Flash) error {
+	flashes := append(s.state(c), flash)
+	for len(flashes) > 0 {
+		value, err := s.encode(flashes)
+		if err != nil {
+			return err
+		}
+		if len(value) <= maxFlashCookieSize {
+			c.Set(flashStateKey, flashes)
+			s.writeCookie(c, value, 0)
+			return nil
+		}
+		flashes = flashes[1:]
+	}
+	return nil
+}
+
+func (s *CookieFlashStore) TakeFlashes(c *Context) ([]Flash, error) {
+	flashes := s.state(c)
+	if len(flashes) > 0 {
+		c.Set(flashStateKey, []Flash{})
+		s.writeCookie(c, "", -1)
+	}
+	return flashes, nil
+}
+
+// state 返回当前请求可见的消息: 首次访问时从请求 cookie 中读取, 之后使用本请求内的记录
+func (s *CookieFlashStore) state(c *Context) []Flash {
+	if v, ok := c.Get(flashStateKey); ok {
+		flashes, _ := v.([]Flash)
+		return flashes
+	}
+	var flashes []Flash
+	if cookie, err := c.Request.Cookie(s.name()); err == nil {
+		flashes = s.decode(cookie.Value)
+	}
+	c.Set(flashStateKey, flashes)
+	return flashes
+}
+
+func (s *CookieFlashStore) encode(flashes []Flash) (string, error) {
+	data, err := json.Marshal(flashes)
+	if err != nil {
+		return "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(data)
+	if len(s.Secret) > 0 {
+		value += "." + s.sign(value)
+	}
+	return value, nil
+}
+
+func (s *CookieFlashStore) decode(value string) []Flash {
+	if len(s.Secret) > 0 {
+		payload, sig, ok := strings.Cut(value, ".")
+		if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(payload))) {
+			return nil
+		}
+		value = payload
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	var flashes []Flash
+	if json.Unmarshal(data, &flashes) != nil {
+		return nil
+	}
+	return flashes
+}
+
+func (s *CookieFlashStore) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// writeCookie 写入消息 cookie, 并替换本请求中先前写入的同名 cookie
+func (s *CookieFlashStore) writeCookie(c *Context, value string, maxAge int) {
+	name := s.name()
+	header := c.Writer.Header()
+	cookies := header["Set-Cookie"][:0:0]
+	for _, v := range header["Set-Cookie"] {
+		if !strings.HasPrefix(v, name+"=") {
+			cookies = append(cookies, v)
+		}
+	}
+	if len(cookies) == 0 {
+		header.Del("Set-Cookie")
+	} else {
+		header["Set-Cookie"] = cookies
+	}
+	c.SetCookieData(&http.Cookie{
+		Name:     name,
+		Value:    value,
+		MaxAge:   maxAge,
+		Secure:   s.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,75 @@
+package touka
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFlashPostRedirectGet(t *testing.T) {
+	engine := New()
+	engine.SetFlashStore(&CookieFlashStore{Secret: []byte("secret")})
+	engine.POST("/save", func(c *Context) {
+		c.Flash(FlashSuccess, "saved")
+		c.Flash(FlashError, "but slowly")
+		c.Redirect(http.StatusSeeOther, "/")
+	})
+	engine.GET("/", func(c *Context) {
+		var parts []string
+		for _, f := range c.TakeFlashes() {
+			parts = append(parts, f.Level+":"+f.Message)
+		}
+		c.String(http.StatusOK, "%s", strings.Join(parts, ","))
+	})
+
+	w := PerformRequest(engine, http.MethodPost, "/save", nil, nil)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected a single flash cookie, got %d", len(cookies))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Body.String() != "success:saved,error:but slowly" {
+		t.Fatalf("unexpected flashes %q", w.Body.String())
+	}
+	if got := w.Result().Cookies(); len(got) != 1 || got[0].MaxAge >= 0 {
+		t.Fatalf("flash cookie should be cleared after reading, got %v", got)
+	}
+
+	// 被篡改的 cookie 被忽略
+	tampered := *cookies[0]
+	tampered.Value = "W3sibCI6ImluZm8iLCJtIjoiaGkifV0" + tampered.Value[strings.Index(tampered.Value, "."):]
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&tampered)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Body.String() != "" {
+		t.Fatalf("tampered flashes should be ignored, got %q", w.Body.String())
+	}
+}
+
+func TestFlashTemplateFuncs(t *testing.T) {
+	dir := t.TempDir()
+	tpl := `{{range flashes .ctx | flashesOf "error"}}[{{.Message}}]{{end}}`
+	if err := os.WriteFile(filepath.Join(dir, "page.html"), []byte(tpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	engine := New()
+	engine.LoadHTMLGlob(filepath.Join(dir, "*.html"))
+	engine.GET("/", func(c *Context) {
+		c.Flash(FlashInfo, "hidden")
+		c.Flash(FlashError, "<boom>")
+		c.HTML(http.StatusOK, "page.html", H{"ctx": c})
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/", nil, nil)
+	if w.Body.String() != "[&lt;boom&gt;]" {
+		t.Fatalf("unexpected render %q", w.Body.String())
+	}
+}
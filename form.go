@@ -0,0 +1,139 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// formFieldError 是表单绑定时单个字段的类型转换错误
+type formFieldError struct {
+	Field string // 结构体字段名
+	Name  string // 表单字段名
+	Err   error
+}
+
+func (e *formFieldError) Error() string {
+	return "field " + e.Field + ": " + e.Err.Error()
+}
+
+func (e *formFieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors 是按表单字段名组织的错误信息, 键 "" 表示与具体字段无关的表单级错误.
+// Validator 返回 FieldErrors (或包装了它的错误) 时, BindFormOrRender 会把错误显示在对应字段旁
+type FieldErrors map[string]string
+
+// Add 记录字段错误, 同一字段只保留第一条
+func (e FieldErrors) Add(field, message string) {
+	if _, ok := e[field]; !ok {
+		e[field] = message
+	}
+}
+
+func (e FieldErrors) Error() string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		if k != "" {
+			sb.WriteString(k)
+			sb.WriteString(": ")
+		}
+		sb.WriteString(e[k])
+	}
+	return sb.String()
+}
+
+// FormData 是表单校验失败时传给模板的数据
+//
+//	<input name="email" value="{{.Value "email"}}">
+//	{{with .Error "email"}}<p class="error">{{.}}</p>{{end}}
+type FormData struct {
+	Form   any         // 已绑定 (可能不完整) 的表单对象
+	Values url.Values  // 用户提交的原始值, 用于回填
+	Errors FieldErrors // 字段错误
+	Data   any         // 调用方提供的其他模板数据
+}
+
+// Value 返回字段先前提交的值
+func (f FormData) Value(name string) string {
+	return f.Values.Get(name)
+}
+
+// Error 返回字段的错误信息, 没有错误时为空
+func (f FormData) Error(name string) string {
+	return f.Errors[name]
+}
+
+// HasError 报告字段是否有错误
+func (f FormData) HasError(name string) bool {
+	_, ok := f.Errors[name]
+	return ok
+}
+
+// BindFormOrRender 绑定并校验表单 (如果 obj 实现了 Validator).
+// 成功时返回 true; 失败时以 422 重新渲染模板 name, 模板数据为注入了字段错误与提交值的 FormData, 并返回 false.
+// 读取请求体超时 (ErrBindTimeout) 时不渲染模板, 而是以 408 交给 ErrorHandler.
+//
+//	r.POST("/signup", func(c *touka.Context) {
+//	    var form SignupForm
+//	    if !c.BindFormOrRender(&form, "signup.html", touka.H{"Title": "注册"}) {
+//	        return
+//	    }
+//	    c.Redirect(http.StatusSeeOther, "/welcome")
+//	})
+func (c *Context) BindFormOrRender(obj any, name string, data any) bool {
+	err := c.ShouldBindForm(obj)
+	if err == nil {
+		err = validateTypedRequest(obj)
+	}
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, ErrBindTimeout) {
+		c.AddClientError(err)
+		c.ErrorUseHandle(http.StatusRequestTimeout, err)
+		return false
+	}
+	c.AddError(err)
+	values := c.Request.PostForm
+	if values == nil {
+		values = url.Values{}
+	}
+	c.HTMLBuf(http.StatusUnprocessableEntity, name, FormData{
+		Form:   obj,
+		Values: values,
+		Errors: formErrors(err),
+		Data:   data,
+	})
+	c.Abort()
+	return false
+}
+
+// formErrors 将绑定或校验错误转换为字段错误
+func formErrors(err error) FieldErrors {
+	var fieldErrs FieldErrors
+	if errors.As(err, &fieldErrs) {
+		return fieldErrs
+	}
+	var bindErr *formFieldError
+	if errors.As(err, &bindErr) {
+		return FieldErrors{bindErr.Name: "invalid value"}
+	}
+	return FieldErrors{"": err.Error()}
+}
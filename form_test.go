@@ -0,0 +1,68 @@
+package touka
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type signupForm struct {
+	Email string `form:"email"`
+	Age   int    `form:"age"`
+}
+
+func (f signupForm) Validate() error {
+	errs := FieldErrors{}
+	if !strings.Contains(f.Email, "@") {
+		errs.Add("email", "invalid email")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func TestBindFormOrRender(t *testing.T) {
+	engine := New()
+	engine.HTMLRender = template.Must(template.New("signup").Parse(
+		`{{.Data}}|{{.Value "email"}}|{{.Error "email"}}|{{.Error "age"}}|{{.Error ""}}`))
+	engine.POST("/signup", func(c *Context) {
+		var form signupForm
+		if !c.BindFormOrRender(&form, "signup", "Sign up") {
+			return
+		}
+		c.String(http.StatusOK, "welcome %s", form.Email)
+	})
+
+	post := func(values url.Values, contentType string) (int, string) {
+		w := PerformRequest(engine, http.MethodPost, "/signup", strings.NewReader(values.Encode()),
+			http.Header{"Content-Type": {contentType}})
+		return w.Code, w.Body.String()
+	}
+	const formType = "application/x-www-form-urlencoded"
+
+	if code, body := post(url.Values{"email": {"a@b.c"}, "age": {"20"}}, formType); code != http.StatusOK || body != "welcome a@b.c" {
+		t.Fatalf("valid form: %d %q", code, body)
+	}
+	if code, body := post(url.Values{"email": {"<bad>"}}, formType); code != http.StatusUnprocessableEntity || body != "Sign up|&lt;bad&gt;|invalid email||" {
+		t.Fatalf("validation error: %d %q", code, body)
+	}
+	if code, body := post(url.Values{"email": {"a@b.c"}, "age": {"old"}}, formType); code != http.StatusUnprocessableEntity || body != "Sign up|a@b.c||invalid value|" {
+		t.Fatalf("bind error: %d %q", code, body)
+	}
+	if code, body := post(url.Values{}, "text/plain"); code != http.StatusUnprocessableEntity || !strings.HasSuffix(body, "unsupported form content type: text/plain") {
+		t.Fatalf("form-level error: %d %q", code, body)
+	}
+}
+
+func TestFieldErrorsError(t *testing.T) {
+	errs := FieldErrors{}
+	errs.Add("name", "required")
+	errs.Add("name", "ignored")
+	errs.Add("", "try again")
+	if got := errs.Error(); got != "try again; name: required" {
+		t.Fatalf("unexpected message %q", got)
+	}
+}
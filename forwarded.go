@@ -0,0 +1,215 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ForwardedElement 是 Forwarded 头部 (RFC 7239) 中的一个转发元素, 对应一跳代理.
+// 各字段为解除引号后的原始值, For/By 可能是 IP、带端口的地址、"unknown" 或以 "_" 开头的混淆标识
+type ForwardedElement struct {
+	For   string
+	By    string
+	Host  string
+	Proto string
+}
+
+// ParseForwarded 解析 Forwarded 头部的所有值, 按出现顺序 (离客户端最近的代理在前) 返回转发元素.
+// 无法解析的元素会被跳过
+func ParseForwarded(values []string) []ForwardedElement {
+	var elements []ForwardedElement
+	for _, v := range values {
+		for _, raw := range splitForwarded(v, ',') {
+			var elem ForwardedElement
+			valid := false
+			for _, pair := range splitForwarded(raw, ';') {
+				name, value, ok := strings.Cut(pair, "=")
+				if !ok {
+					continue
+				}
+				value, ok = unquoteForwarded(strings.TrimSpace(value))
+				if !ok {
+					continue
+				}
+				switch strings.ToLower(strings.TrimSpace(name)) {
+				case "for":
+					elem.For = value
+				case "by":
+					elem.By = value
+				case "host":
+					elem.Host = value
+				case "proto":
+					elem.Proto = strings.ToLower(value)
+				default:
+					continue
+				}
+				valid = true
+			}
+			if valid {
+				elements = append(elements, elem)
+			}
+		}
+	}
+	return elements
+}
+
+// splitForwarded 按 sep 切分, 忽略引号内的分隔符
+func splitForwarded(s string, sep byte) []string {
+	var parts []string
+	inQuote, escaped, start := false, false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuote && s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			inQuote = !inQuote
+		case !inQuote && s[i] == sep:
+			if part := strings.TrimSpace(s[start:i]); part != "" {
+				parts = append(parts, part)
+			}
+			start = i + 1
+		}
+	}
+	if part := strings.TrimSpace(s[start:]); part != "" {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// unquoteForwarded 解除 quoted-string 的引号与转义, 未加引号的值原样返回.
+// 实践中不少代理发送未加引号的 IPv6 地址或端口, 因此不强制要求 token 字符集
+func unquoteForwarded(v string) (string, bool) {
+	if !strings.HasPrefix(v, `"`) {
+		return v, v != "" && !strings.ContainsAny(v, " \t\"")
+	}
+	if len(v) < 2 || !strings.HasSuffix(v, `"`) {
+		return "", false
+	}
+	v = v[1 : len(v)-1]
+	if !strings.Contains(v, `\`) {
+		return v, true
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && i+1 < len(v) {
+			i++
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String(), true
+}
+
+// forwardedNodeIP 从 for=/by= 的节点标识中取出 IP, "unknown" 与混淆标识返回 false
+func forwardedNodeIP(node string) (netip.Addr, bool) {
+	if strings.HasPrefix(node, "[") {
+		end := strings.IndexByte(node, ']')
+		if end < 0 {
+			return netip.Addr{}, false
+		}
+		addr, err := netip.ParseAddr(node[1:end])
+		return addr, err == nil && addr.Is6()
+	}
+	host, _, _ := strings.Cut(node, ":")
+	addr, err := netip.ParseAddr(host)
+	return addr, err == nil && addr.Is4()
+}
+
+// forwardedHop 返回离客户端最近的可信转发元素的下标.
+// 每个代理在末尾追加一个元素, 其 for= 为该代理的对端; 因此从右向左, for= 为可信代理的元素
+// 说明更靠左的元素同样由可信代理追加, 直到遇到 for= 不是可信代理的元素, 即可信链路最外侧的代理所记录的一跳.
+// 左侧更早的元素可能由客户端伪造. 没有元素时返回 -1
+func forwardedHop(elements []ForwardedElement, trusted func(netip.Addr) bool) int {
+	i := len(elements) - 1
+	for i > 0 {
+		addr, ok := forwardedNodeIP(elements[i].For)
+		if !ok || !trusted(addr) {
+			break
+		}
+		i--
+	}
+	return i
+}
+
+// forwardedClientIP 返回 Forwarded 头部中离可信代理最近的 for= 地址, 该地址无法解析为 IP 时返回空字符串
+func forwardedClientIP(values []string, trusted func(netip.Addr) bool) string {
+	if len(values) == 0 {
+		return ""
+	}
+	elements := ParseForwarded(values)
+	i := forwardedHop(elements, trusted)
+	if i < 0 {
+		return ""
+	}
+	if addr, ok := forwardedNodeIP(elements[i].For); ok {
+		return addr.String()
+	}
+	return ""
+}
+
+// lastHeaderValue 返回头部逗号分隔列表中的最后一个值, 即直接对端追加或设置的值
+func lastHeaderValue(h http.Header, key string) string {
+	values := h.Values(key)
+	if len(values) == 0 {
+		return ""
+	}
+	last := values[len(values)-1]
+	if i := strings.LastIndexByte(last, ','); i >= 0 {
+		last = last[i+1:]
+	}
+	return strings.TrimSpace(last)
+}
+
+// Forwarded 返回请求 Forwarded 头部中的转发元素. 头部可由客户端伪造, 只应在请求来自可信代理时采信
+func (c *Context) Forwarded() []ForwardedElement {
+	return ParseForwarded(c.Request.Header.Values("Forwarded"))
+}
+
+// trustedForwarded 返回可信代理记录的客户端一跳, 参见 forwardedHop
+func (c *Context) trustedForwarded() (ForwardedElement, bool) {
+	elements := c.Forwarded()
+	i := forwardedHop(elements, c.engine.isTrustedProxy)
+	if i < 0 {
+		return ForwardedElement{}, false
+	}
+	return elements[i], true
+}
+
+// ForwardedProto 返回客户端访问时使用的协议 ("http" 或 "https"), 用于生成规范 URL.
+// 请求来自可信代理 (参见 SetTrustedProxies) 时依次采信 Forwarded 中可信代理记录的 proto= 与
+// X-Forwarded-Proto 的最后一个值, 只接受 http 与 https; 否则取自当前连接
+func (c *Context) ForwardedProto() string {
+	if c.FromTrustedProxy() {
+		if elem, ok := c.trustedForwarded(); ok && (elem.Proto == "http" || elem.Proto == "https") {
+			return elem.Proto
+		}
+		if proto := strings.ToLower(lastHeaderValue(c.Request.Header, "X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			return proto
+		}
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// ForwardedHost 返回客户端访问时使用的主机名 (可能带端口), 用于生成规范 URL.
+// 请求来自可信代理时依次采信 Forwarded 中可信代理记录的 host= 与 X-Forwarded-Host 的最后一个值,
+// 否则返回 Request.Host
+func (c *Context) ForwardedHost() string {
+	if c.FromTrustedProxy() {
+		if elem, ok := c.trustedForwarded(); ok && elem.Host != "" {
+			return elem.Host
+		}
+		if host := lastHeaderValue(c.Request.Header, "X-Forwarded-Host"); host != "" {
+			return host
+		}
+	}
+	return c.Request.Host
+}
@@ -0,0 +1,102 @@
+package touka
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestParseForwarded(t *testing.T) {
+	got := ParseForwarded([]string{
+		`for="[2001:db8:cafe::17]:4711";proto=HTTPS;host="example.com", for=_hidden`,
+		`For=192.0.2.60;by=203.0.113.43;host="a\"b;c"`,
+		`garbage`,
+	})
+	want := []ForwardedElement{
+		{For: "[2001:db8:cafe::17]:4711", Proto: "https", Host: "example.com"},
+		{For: "_hidden"},
+		{For: "192.0.2.60", By: "203.0.113.43", Host: `a"b;c`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected elements:\n got %+v\nwant %+v", got, want)
+	}
+
+	trusted := func(addr netip.Addr) bool { return netip.MustParsePrefix("10.0.0.0/8").Contains(addr) }
+	for header, ip := range map[string]string{
+		`for=unknown, for="[2001:db8::1]:80"`: "2001:db8::1",
+		`for=_obf, for=192.0.2.1:8080`:        "192.0.2.1",
+		`for=unknown`:                         "",
+		// 客户端伪造的左侧元素被忽略, 跳过可信代理后取最近的一跳
+		`for=198.51.100.1, for=192.0.2.7, for=10.0.0.2`: "192.0.2.7",
+		`for=192.0.2.7, for=_obf, for=10.0.0.2`:         "",
+		`for=10.0.0.3, for=10.0.0.2`:                    "10.0.0.3",
+	} {
+		if got := forwardedClientIP([]string{header}, trusted); got != ip {
+			t.Errorf("forwardedClientIP(%q) = %q, want %q", header, got, ip)
+		}
+	}
+}
+
+func TestRequestIPFromForwarded(t *testing.T) {
+	engine := New()
+	engine.GET("/", func(c *Context) { c.String(http.StatusOK, "%s", c.ClientIP()) })
+	serve := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Forwarded", `for="[2001:db8::7]";proto=https`)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// Forwarded 默认不在 RemoteIPHeaders 中
+	if got := serve(); got != "192.0.2.1" {
+		t.Fatalf("expected Forwarded to be ignored by default, got %q", got)
+	}
+	engine.SetRemoteIPHeaders([]string{"Forwarded", "X-Forwarded-For", "X-Real-IP"})
+	if got := serve(); got != "2001:db8::7" {
+		t.Fatalf("expected client IP from Forwarded, got %q", got)
+	}
+}
+
+func TestForwardedProtoAndHost(t *testing.T) {
+	engine := New()
+	if err := engine.SetTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	engine.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "%s://%s %t", c.ForwardedProto(), c.ForwardedHost(), c.IsTLS())
+	})
+	serve := func(remoteAddr string, headers map[string]string) string {
+		req := httptest.NewRequest(http.MethodGet, "http://internal:8080/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	cases := []struct {
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"10.0.0.1:1", map[string]string{"Forwarded": `proto=https;host=shop.example`, "X-Forwarded-Proto": "http"}, "https://shop.example true"},
+		{"10.0.0.1:1", map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "evil.example, b.example"}, "https://b.example true"},
+		// 客户端伪造的元素在左侧, 采信可信代理追加的最后一个元素
+		{"10.0.0.1:1", map[string]string{"Forwarded": `proto=https;host=evil.example, for=192.0.2.7;proto=http;host=shop.example`}, "http://shop.example false"},
+		// 可信代理之间的元素被跳过, 取可信链路最外侧代理记录的一跳
+		{"10.0.0.1:1", map[string]string{"Forwarded": `for=192.0.2.7;proto=https;host=shop.example, for=10.0.0.1;proto=http;host=internal`}, "https://shop.example true"},
+		// 只接受 http 与 https
+		{"10.0.0.1:1", map[string]string{"Forwarded": `for=192.0.2.7;proto=javascript`, "X-Forwarded-Proto": "ftp"}, "http://internal:8080 false"},
+		{"192.0.2.9:1", map[string]string{"Forwarded": `proto=https;host=evil.example`}, "http://internal:8080 false"},
+	}
+	for _, tc := range cases {
+		if got := serve(tc.remote, tc.headers); got != tc.want {
+			t.Errorf("remote %s headers %v: got %q, want %q", tc.remote, tc.headers, got, tc.want)
+		}
+	}
+}
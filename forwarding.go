@@ -0,0 +1,140 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	principalKey   = "touka.principal"
+	requestIDKey   = "touka.request_id"
+	traceparentKey = "touka.traceparent"
+)
+
+// ForwardingOptions 配置 ForwardingHeaders 生成的出站头部
+type ForwardingOptions struct {
+	// RequestIDHeader 为请求 ID 的头部名, 默认 X-Request-ID
+	RequestIDHeader string
+	// PrincipalHeader 为传递认证主体的头部名, 为空时不传递.
+	// 下游服务只应在来自可信网关的请求中信任该头部
+	PrincipalHeader string
+	// DisableForwardedFor 为 true 时不生成 X-Forwarded-For/Host/Proto
+	DisableForwardedFor bool
+	// DisableTracing 为 true 时不传递 traceparent/tracestate
+	DisableTracing bool
+}
+
+// SetPrincipal 记录当前请求已认证的主体 (例如用户 ID), 供 ForwardingHeaders 传递给下游
+func (c *Context) SetPrincipal(principal string) {
+	c.Set(principalKey, principal)
+}
+
+// Principal 返回通过 SetPrincipal 记录的认证主体
+func (c *Context) Principal() string {
+	principal, _ := c.GetString(principalKey)
+	return principal
+}
+
+// RequestID 返回当前请求的 ID: 优先使用入站请求的 X-Request-ID, 否则生成一个并在本次请求内保持不变
+func (c *Context) RequestID() string {
+	return c.requestID("X-Request-ID")
+}
+
+func (c *Context) requestID(header string) string {
+	if id, ok := c.GetString(requestIDKey); ok {
+		return id
+	}
+	id := strings.TrimSpace(c.GetReqHeader(header))
+	if id == "" || len(id) > 128 {
+		id = randomHex(16)
+	}
+	c.Set(requestIDKey, id)
+	return id
+}
+
+// ForwardingHeaders 构建调用下游服务时应携带的头部:
+//   - X-Forwarded-For 在入站链后追加直连对端地址, X-Forwarded-Host/Proto 保留入站值或取自当前请求
+//   - X-Request-ID 沿用入站值, 没有时生成
+//   - traceparent/tracestate 沿用入站值; 入站请求没有有效的 traceparent 时开启新的追踪, 本次请求内的所有调用共用同一个
+//   - 设置了 PrincipalHeader 且通过 SetPrincipal 记录了主体时, 传递认证主体
+//
+// 返回的头部可以传给 c.HTTPC().WithHeaders, 也可以直接合并到 http.Request
+func (c *Context) ForwardingHeaders(opts ForwardingOptions) http.Header {
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = "X-Request-ID"
+	}
+	req := c.Request
+	out := make(http.Header)
+
+	if !opts.DisableForwardedFor {
+		if prior := req.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+			out["X-Forwarded-For"] = append([]string(nil), prior...)
+		}
+		appendXForwardedFor(out, reverseProxyClientIP(req.RemoteAddr))
+		if host := req.Header.Get("X-Forwarded-Host"); host != "" {
+			out.Set("X-Forwarded-Host", host)
+		} else if req.Host != "" {
+			out.Set("X-Forwarded-Host", req.Host)
+		}
+		if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+			out.Set("X-Forwarded-Proto", proto)
+		} else {
+			out.Set("X-Forwarded-Proto", reverseProxyRequestScheme(req))
+		}
+	}
+
+	out.Set(opts.RequestIDHeader, c.requestID(opts.RequestIDHeader))
+
+	if !opts.DisableTracing {
+		out.Set("traceparent", c.traceparent())
+		if state := req.Header.Get("tracestate"); state != "" && validTraceparent(req.Header.Get("traceparent")) {
+			out.Set("tracestate", state)
+		}
+	}
+
+	if opts.PrincipalHeader != "" {
+		if principal := c.Principal(); principal != "" {
+			out.Set(opts.PrincipalHeader, principal)
+		}
+	}
+	return out
+}
+
+// traceparent 返回传递给下游的 W3C traceparent
+func (c *Context) traceparent() string {
+	if tp, ok := c.GetString(traceparentKey); ok {
+		return tp
+	}
+	tp := strings.ToLower(strings.TrimSpace(c.GetReqHeader("traceparent")))
+	if !validTraceparent(tp) {
+		tp = "00-" + randomHex(16) + "-" + randomHex(8) + "-00"
+	}
+	c.Set(traceparentKey, tp)
+	return tp
+}
+
+// validTraceparent 校验 version 00 的 traceparent: 00-<32 hex trace-id>-<16 hex parent-id>-<2 hex flags>
+func validTraceparent(tp string) bool {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(tp)), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return false
+	}
+	for _, p := range parts[1:] {
+		if _, err := hex.DecodeString(p); err != nil {
+			return false
+		}
+	}
+	return strings.Trim(parts[1], "0") != "" && strings.Trim(parts[2], "0") != ""
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
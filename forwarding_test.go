@@ -0,0 +1,104 @@
+package touka
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestForwardingHeadersPropagatesIncoming(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.com/orders", nil)
+	req.RemoteAddr = "10.0.0.2:4321"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Request-ID", "req-123")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("tracestate", "vendor=1")
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+	c.SetPrincipal("user-42")
+
+	h := c.ForwardingHeaders(ForwardingOptions{PrincipalHeader: "X-Authenticated-User"})
+	want := map[string]string{
+		"X-Forwarded-For":      "203.0.113.7, 10.0.0.2",
+		"X-Forwarded-Host":     "api.example.com",
+		"X-Forwarded-Proto":    "https",
+		"X-Request-Id":         "req-123",
+		"Traceparent":          "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"Tracestate":           "vendor=1",
+		"X-Authenticated-User": "user-42",
+	}
+	for k, v := range want {
+		if got := h.Get(k); got != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got)
+		}
+	}
+	if req.Header.Get("X-Forwarded-For") != "203.0.113.7" {
+		t.Fatal("incoming request headers must not be modified")
+	}
+}
+
+func TestForwardingHeadersGeneratesStableIDs(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+
+	first := c.ForwardingHeaders(ForwardingOptions{DisableForwardedFor: true})
+	second := c.ForwardingHeaders(ForwardingOptions{DisableForwardedFor: true})
+	if first.Get("X-Forwarded-For") != "" {
+		t.Fatal("X-Forwarded-For should be disabled")
+	}
+	if id := first.Get("X-Request-ID"); id == "" || id != second.Get("X-Request-ID") || id != c.RequestID() {
+		t.Fatalf("request ID should be generated once per request, got %q and %q", id, second.Get("X-Request-ID"))
+	}
+	tp := first.Get("traceparent")
+	if !validTraceparent(tp) || strings.Contains(tp, "00000000000000000000000000000000") {
+		t.Fatalf("expected a new valid traceparent, got %q", tp)
+	}
+	if tp != second.Get("traceparent") {
+		t.Fatal("all upstream calls in a request should share one trace")
+	}
+	if first.Get("tracestate") != "" {
+		t.Fatal("tracestate must not be forwarded without a valid incoming traceparent")
+	}
+}
+
+func TestHTTPCWithHeaders(t *testing.T) {
+	var got http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	engine := New()
+	engine.GET("/", func(c *Context) {
+		resp, err := c.HTTPC().
+			WithHeaders(c.ForwardingHeaders(ForwardingOptions{})).
+			GET(upstream.URL).
+			SetHeader("X-Request-ID", "override").
+			Execute()
+		if err != nil {
+			c.String(http.StatusBadGateway, "%s", err.Error())
+			return
+		}
+		resp.Body.Close()
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got.Get("X-Forwarded-For") != "198.51.100.1, 192.0.2.1" {
+		t.Fatalf("expected forwarded chain to be extended, got %q", got.Get("X-Forwarded-For"))
+	}
+	if got.Get("X-Request-ID") != "override" {
+		t.Fatalf("builder headers should override forwarded headers, got %q", got.Get("X-Request-ID"))
+	}
+	if !validTraceparent(got.Get("traceparent")) {
+		t.Fatalf("expected traceparent upstream, got %q", got.Get("traceparent"))
+	}
+}
@@ -9,9 +9,8 @@ require (
 	github.com/fenthope/reco v0.0.5
 	github.com/go-json-experiment/json v0.0.0-20260214004413-d219187c3433
 	golang.org/x/net v0.53.0
+	golang.org/x/text v0.36.0
+	google.golang.org/protobuf v1.36.9
 )
 
-require (
-	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	golang.org/x/text v0.36.0 // indirect
-)
+require github.com/valyala/bytebufferpool v1.0.0 // indirect
@@ -0,0 +1,429 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-json-experiment/json"
+)
+
+// GraphQLRequest 是 GraphQL over HTTP 的标准请求体
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLError 是 GraphQL 响应中的错误项
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e GraphQLError) Error() string {
+	return e.Message
+}
+
+// GraphQLResponse 是 GraphQL over HTTP 的标准响应体
+type GraphQLResponse struct {
+	Data       any            `json:"data,omitempty"`
+	Errors     []GraphQLError `json:"errors,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLExecutor 由具体的 GraphQL 实现 (schema 执行器) 提供, touka 本身不绑定任何 GraphQL 库
+type GraphQLExecutor interface {
+	Execute(ctx context.Context, req GraphQLRequest) *GraphQLResponse
+}
+
+// GraphQLExecutorFunc 是 GraphQLExecutor 的函数适配器
+type GraphQLExecutorFunc func(ctx context.Context, req GraphQLRequest) *GraphQLResponse
+
+func (f GraphQLExecutorFunc) Execute(ctx context.Context, req GraphQLRequest) *GraphQLResponse {
+	return f(ctx, req)
+}
+
+// PersistedQueryStore 存储持久化查询 (sha256 哈希 -> 查询文本)
+type PersistedQueryStore interface {
+	Get(ctx context.Context, hash string) (string, bool)
+	Put(ctx context.Context, hash string, query string) error
+}
+
+// MemoryPersistedQueryStore 是进程内的 PersistedQueryStore 实现
+type MemoryPersistedQueryStore struct {
+	mu      sync.RWMutex
+	queries map[string]string
+}
+
+// NewMemoryPersistedQueryStore 创建一个进程内持久化查询存储
+func NewMemoryPersistedQueryStore() *MemoryPersistedQueryStore {
+	return &MemoryPersistedQueryStore{queries: make(map[string]string)}
+}
+
+func (s *MemoryPersistedQueryStore) Get(_ context.Context, hash string) (string, bool) {
+	s.mu.RLock()
+	query, ok := s.queries[hash]
+	s.mu.RUnlock()
+	return query, ok
+}
+
+func (s *MemoryPersistedQueryStore) Put(_ context.Context, hash string, query string) error {
+	s.mu.Lock()
+	s.queries[hash] = query
+	s.mu.Unlock()
+	return nil
+}
+
+// GraphQLOptions 配置 GraphQL 处理器
+type GraphQLOptions struct {
+	// MaxDepth 限制选择集的最大嵌套深度, 0 表示不限制
+	MaxDepth int
+	// MaxComplexity 限制查询的复杂度, 0 表示不限制
+	// 默认以字段选择的数量作为复杂度, 可通过 ComplexityFunc 自定义
+	MaxComplexity  int
+	ComplexityFunc func(query string) (int, error)
+
+	// PersistedQueries 启用自动持久化查询 (extensions.persistedQuery.sha256Hash)
+	PersistedQueries PersistedQueryStore
+
+	// Playground 为 true 时, 浏览器以 GET 访问 (Accept: text/html 且无 query 参数) 会返回 GraphiQL 页面
+	Playground bool
+	// PlaygroundTitle 为 Playground 页面标题
+	PlaygroundTitle string
+}
+
+var (
+	errGraphQLMissingQuery         = errors.New("graphql: must provide query string")
+	errGraphQLPersistedNotFound    = errors.New("PersistedQueryNotFound")
+	errGraphQLPersistedUnsupported = errors.New("PersistedQueryNotSupported")
+	errGraphQLHashMismatch         = errors.New("provided sha does not match query")
+	errGraphQLMutationOverGET      = errors.New("graphql: mutations are not allowed over GET")
+)
+
+// GraphQL 返回一个 GraphQL over HTTP 处理器, 支持 GET 与 POST.
+// 查询在执行前会进行深度与复杂度检查; 执行时使用请求的 Context, 客户端断开后执行器可感知取消.
+// 响应中的 GraphQL 错误会同步记录到 c.Errors.
+//
+//	r.GET("/graphql", touka.GraphQL(executor, touka.GraphQLOptions{MaxDepth: 10, Playground: true}))
+//	r.POST("/graphql", touka.GraphQL(executor, touka.GraphQLOptions{MaxDepth: 10}))
+func GraphQL(executor GraphQLExecutor, opts GraphQLOptions) HandlerFunc {
+	if executor == nil {
+		panic("touka: graphql executor must not be nil")
+	}
+	if opts.PlaygroundTitle == "" {
+		opts.PlaygroundTitle = "GraphiQL"
+	}
+	playground := []byte(fmt.Sprintf(graphQLPlaygroundHTML, html.EscapeString(opts.PlaygroundTitle)))
+
+	return func(c *Context) {
+		if opts.Playground && c.Request.Method == http.MethodGet && c.Query("query") == "" &&
+			strings.Contains(c.GetReqHeader("Accept"), "text/html") {
+			c.Raw(http.StatusOK, "text/html; charset=utf-8", playground)
+			return
+		}
+
+		req, status, err := parseGraphQLRequest(c)
+		if err != nil {
+			writeGraphQLError(c, status, err)
+			return
+		}
+
+		if err := resolvePersistedQuery(c, &req, opts.PersistedQueries); err != nil {
+			// APQ 协议约定以 200 返回, 客户端据此重新发送完整查询
+			writeGraphQLError(c, http.StatusOK, err)
+			return
+		}
+		if req.Query == "" {
+			writeGraphQLError(c, http.StatusBadRequest, errGraphQLMissingQuery)
+			return
+		}
+
+		doc := analyzeGraphQLQuery(req.Query, req.OperationName)
+		if c.Request.Method == http.MethodGet && doc.operation != "query" {
+			c.Writer.Header().Set("Allow", "POST")
+			writeGraphQLError(c, http.StatusMethodNotAllowed, errGraphQLMutationOverGET)
+			return
+		}
+		if opts.MaxDepth > 0 && doc.depth > opts.MaxDepth {
+			writeGraphQLError(c, http.StatusBadRequest, fmt.Errorf("graphql: query depth %d exceeds limit %d", doc.depth, opts.MaxDepth))
+			return
+		}
+		if opts.MaxComplexity > 0 {
+			complexity := doc.fields
+			if opts.ComplexityFunc != nil {
+				if complexity, err = opts.ComplexityFunc(req.Query); err != nil {
+					writeGraphQLError(c, http.StatusBadRequest, err)
+					return
+				}
+			}
+			if complexity > opts.MaxComplexity {
+				writeGraphQLError(c, http.StatusBadRequest, fmt.Errorf("graphql: query complexity %d exceeds limit %d", complexity, opts.MaxComplexity))
+				return
+			}
+		}
+
+		resp := executor.Execute(c.Context(), req)
+		if resp == nil {
+			resp = &GraphQLResponse{}
+		}
+		for _, gqlErr := range resp.Errors {
+			c.AddError(gqlErr)
+		}
+		if c.Err() != nil {
+			// 客户端已断开, 不再写入响应
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+func parseGraphQLRequest(c *Context) (GraphQLRequest, int, error) {
+	var req GraphQLRequest
+	switch c.Request.Method {
+	case http.MethodGet:
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if raw := c.Query("variables"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+				return req, http.StatusBadRequest, fmt.Errorf("graphql: invalid variables: %w", err)
+			}
+		}
+		if raw := c.Query("extensions"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &req.Extensions); err != nil {
+				return req, http.StatusBadRequest, fmt.Errorf("graphql: invalid extensions: %w", err)
+			}
+		}
+		return req, 0, nil
+	case http.MethodPost:
+		mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+		switch mediaType {
+		case "application/graphql":
+			body, err := c.GetReqBodyFull()
+			if err != nil {
+				return req, http.StatusBadRequest, err
+			}
+			req.Query = string(body)
+			return req, 0, nil
+		case "", "application/json", "application/graphql+json":
+			if err := c.ShouldBindJSON(&req); err != nil {
+				return req, http.StatusBadRequest, err
+			}
+			return req, 0, nil
+		default:
+			return req, http.StatusUnsupportedMediaType, fmt.Errorf("graphql: unsupported content type %q", mediaType)
+		}
+	default:
+		c.Writer.Header().Set("Allow", "GET, POST")
+		return req, http.StatusMethodNotAllowed, fmt.Errorf("graphql: method %s not allowed", c.Request.Method)
+	}
+}
+
+func resolvePersistedQuery(c *Context, req *GraphQLRequest, store PersistedQueryStore) error {
+	ext, ok := req.Extensions["persistedQuery"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	hash, _ := ext["sha256Hash"].(string)
+	if hash == "" {
+		return nil
+	}
+	if store == nil {
+		return errGraphQLPersistedUnsupported
+	}
+
+	if req.Query == "" {
+		query, found := store.Get(c.Context(), hash)
+		if !found {
+			return errGraphQLPersistedNotFound
+		}
+		req.Query = query
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(req.Query))
+	if hex.EncodeToString(sum[:]) != strings.ToLower(hash) {
+		return errGraphQLHashMismatch
+	}
+	return store.Put(c.Context(), strings.ToLower(hash), req.Query)
+}
+
+func writeGraphQLError(c *Context, status int, err error) {
+	c.AddError(err)
+	c.JSON(status, GraphQLResponse{Errors: []GraphQLError{{Message: err.Error()}}})
+	c.Abort()
+}
+
+// graphQLDocInfo 是对查询文本的轻量分析结果, 仅用于请求前的限流判断, 不做完整的语法校验
+type graphQLDocInfo struct {
+	operation string // 选中操作的类型: query / mutation / subscription
+	depth     int    // 最大选择集嵌套深度
+	fields    int    // 字段选择数量
+}
+
+// analyzeGraphQLQuery 通过词法扫描估算查询的深度与字段数, 并识别所选操作的类型.
+// 片段 (fragment) 的深度单独计算, 不会展开到引用位置.
+func analyzeGraphQLQuery(query, operationName string) graphQLDocInfo {
+	info := graphQLDocInfo{}
+	tokens := lexGraphQL(query)
+
+	braceDepth, parenDepth := 0, 0
+	operations := make(map[string]string)
+	firstOperation := ""
+	for i, tok := range tokens {
+		switch tok {
+		case "{":
+			if braceDepth == 0 && i == 0 {
+				// 简写形式的匿名查询 "{ ... }"
+				if firstOperation == "" {
+					firstOperation = "query"
+				}
+			}
+			braceDepth++
+			if braceDepth > info.depth {
+				info.depth = braceDepth
+			}
+			continue
+		case "}":
+			braceDepth--
+			continue
+		case "(":
+			parenDepth++
+			continue
+		case ")":
+			parenDepth--
+			continue
+		}
+		if !isGraphQLName(tok) {
+			continue
+		}
+
+		if braceDepth == 0 && parenDepth == 0 {
+			if tok == "query" || tok == "mutation" || tok == "subscription" {
+				name := ""
+				if i+1 < len(tokens) && isGraphQLName(tokens[i+1]) {
+					name = tokens[i+1]
+				}
+				operations[name] = tok
+				if firstOperation == "" {
+					firstOperation = tok
+				}
+			}
+			continue
+		}
+		if braceDepth == 0 || parenDepth > 0 {
+			continue
+		}
+
+		prev, next := "", ""
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+		if next == ":" || prev == "..." || prev == "on" || prev == "@" || prev == "$" {
+			continue // 别名、片段展开、类型条件、指令、变量
+		}
+		info.fields++
+	}
+
+	info.operation = firstOperation
+	if operationName != "" {
+		if op, ok := operations[operationName]; ok {
+			info.operation = op
+		}
+	}
+	if info.operation == "" {
+		info.operation = "query"
+	}
+	return info
+}
+
+// lexGraphQL 将查询拆分为名称与标点 token, 跳过字符串、注释与空白
+func lexGraphQL(src string) []string {
+	tokens := make([]string, 0, len(src)/4)
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case ch == '"':
+			if strings.HasPrefix(src[i:], `"""`) {
+				end := strings.Index(src[i+3:], `"""`)
+				if end < 0 {
+					return tokens
+				}
+				i += end + 6
+			} else {
+				i++
+				for i < len(src) && src[i] != '"' {
+					if src[i] == '\\' {
+						i++
+					}
+					i++
+				}
+				i++
+			}
+			tokens = append(tokens, `""`)
+		case ch == '.' && strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.IndexByte("{}()[]:!$=@|&", ch) >= 0:
+			tokens = append(tokens, src[i:i+1])
+			i++
+		case ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z'):
+			start := i
+			for i < len(src) && (src[i] == '_' || (src[i] >= 'a' && src[i] <= 'z') || (src[i] >= 'A' && src[i] <= 'Z') || (src[i] >= '0' && src[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, src[start:i])
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func isGraphQLName(tok string) bool {
+	if tok == "" {
+		return false
+	}
+	ch := tok[0]
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+const graphQLPlaygroundHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<link rel="stylesheet" href="https://unpkg.com/graphiql/graphiql.min.css">
+</head>
+<body style="margin:0">
+<div id="graphiql" style="height:100vh"></div>
+<script crossorigin src="https://unpkg.com/react/umd/react.production.min.js"></script>
+<script crossorigin src="https://unpkg.com/react-dom/umd/react-dom.production.min.js"></script>
+<script crossorigin src="https://unpkg.com/graphiql/graphiql.min.js"></script>
+<script>
+const fetcher = GraphiQL.createFetcher({ url: window.location.pathname });
+ReactDOM.render(React.createElement(GraphiQL, { fetcher: fetcher }), document.getElementById('graphiql'));
+</script>
+</body>
+</html>
+`
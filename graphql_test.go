@@ -0,0 +1,110 @@
+package touka
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func testGraphQLExecutor(calls *int) GraphQLExecutor {
+	return GraphQLExecutorFunc(func(ctx context.Context, req GraphQLRequest) *GraphQLResponse {
+		*calls++
+		if req.OperationName == "Broken" {
+			return &GraphQLResponse{Errors: []GraphQLError{{Message: "boom"}}}
+		}
+		return &GraphQLResponse{Data: map[string]any{"query": req.Query, "vars": req.Variables}}
+	})
+}
+
+func TestGraphQLGetAndPost(t *testing.T) {
+	var calls int
+	engine := New()
+	engine.ANY("/graphql", GraphQL(testGraphQLExecutor(&calls), GraphQLOptions{Playground: true}))
+
+	rr := PerformRequest(engine, http.MethodGet, "/graphql?query="+url.QueryEscape("{ user { name } }"), nil, nil)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"data"`) {
+		t.Fatalf("GET query: got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodGet, "/graphql?query="+url.QueryEscape("mutation { del }"), nil, nil)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected mutation over GET to be 405, got %d", rr.Code)
+	}
+
+	headers := http.Header{"Content-Type": []string{"application/json"}}
+	rr = PerformRequest(engine, http.MethodPost, "/graphql", strings.NewReader(`{"query":"mutation M($id: ID!) { del(id: $id) }","variables":{"id":"1"}}`), headers)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"id":"1"`) {
+		t.Fatalf("POST mutation: got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodGet, "/graphql", nil, http.Header{"Accept": []string{"text/html"}})
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "graphiql") {
+		t.Fatalf("expected playground page, got %d", rr.Code)
+	}
+	if calls != 2 {
+		t.Fatalf("expected executor to run twice, got %d", calls)
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	var calls int
+	engine := New()
+	engine.POST("/graphql", GraphQL(testGraphQLExecutor(&calls), GraphQLOptions{MaxDepth: 2, MaxComplexity: 3}))
+	headers := http.Header{"Content-Type": []string{"application/graphql"}}
+
+	rr := PerformRequest(engine, http.MethodPost, "/graphql", strings.NewReader(`{ a { b { c } } }`), headers)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "depth") {
+		t.Fatalf("expected depth rejection, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodPost, "/graphql", strings.NewReader(`{ a b c d }`), headers)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "complexity") {
+		t.Fatalf("expected complexity rejection, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	// 别名、参数、字符串与注释不计入复杂度
+	rr = PerformRequest(engine, http.MethodPost, "/graphql", strings.NewReader("{ x: a(arg: \"{ b c d }\") # e f\n b }"), headers)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected query within limits, got %d %s", rr.Code, rr.Body.String())
+	}
+	if calls != 1 {
+		t.Fatalf("expected executor to run once, got %d", calls)
+	}
+}
+
+func TestGraphQLPersistedQueries(t *testing.T) {
+	var calls int
+	engine := New()
+	store := NewMemoryPersistedQueryStore()
+	engine.POST("/graphql", GraphQL(testGraphQLExecutor(&calls), GraphQLOptions{PersistedQueries: store}))
+	headers := http.Header{"Content-Type": []string{"application/json"}}
+
+	query := "{ me }"
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])
+	ext := `"extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}`
+
+	rr := PerformRequest(engine, http.MethodPost, "/graphql", strings.NewReader(`{`+ext+`}`), headers)
+	if !strings.Contains(rr.Body.String(), "PersistedQueryNotFound") {
+		t.Fatalf("expected PersistedQueryNotFound, got %s", rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodPost, "/graphql", strings.NewReader(`{"query":"`+query+`",`+ext+`}`), headers)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("register persisted query: got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodPost, "/graphql", strings.NewReader(`{`+ext+`}`), headers)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `{ me }`) {
+		t.Fatalf("expected persisted query to execute, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ other }",`+ext+`}`), headers)
+	if !strings.Contains(rr.Body.String(), "does not match") {
+		t.Fatalf("expected hash mismatch, got %s", rr.Body.String())
+	}
+}
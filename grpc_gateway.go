@@ -0,0 +1,319 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCUnaryHandler 是一元 RPC 方法的实现.
+// req 是已解码的请求消息 (已注册生成代码时为具体类型, 否则为 *dynamicpb.Message).
+type GRPCUnaryHandler func(ctx context.Context, req proto.Message) (proto.Message, error)
+
+// GRPCHTTPRule 描述 RPC 方法到 HTTP 路由的映射, 语义参考 google.api.http.
+type GRPCHTTPRule struct {
+	Method     string // RPC 方法名, 例如 GetUser
+	HTTPMethod string // HTTP 方法, 例如 GET
+	Path       string // touka 路由语法, 例如 /v1/users/:user_id; 路径参数按字段名 (支持 a.b 形式) 写入请求消息
+	// Body 指定请求体映射: "*" 表示整个请求体映射到请求消息,
+	// 字段名表示映射到该字段, 空字符串表示不读取请求体 (字段来自路径与查询参数)
+	Body string
+}
+
+// GRPCService 描述一个要挂载到 touka 路由上的 protobuf 服务.
+type GRPCService struct {
+	Desc    protoreflect.ServiceDescriptor
+	Methods map[string]GRPCUnaryHandler // 以 RPC 方法名为键
+
+	// Rules 为可选的自定义映射; 未配置规则的方法默认注册为 POST /<service.FullName>/<Method>, Body 为 "*"
+	Rules []GRPCHTTPRule
+
+	MarshalOptions   protojson.MarshalOptions
+	UnmarshalOptions protojson.UnmarshalOptions
+}
+
+const (
+	grpcGatewayContentTypeJSON     = "application/json"
+	grpcGatewayContentTypeProtobuf = "application/x-protobuf"
+)
+
+// MountGRPCService 将 protobuf 服务挂载到引擎上, 以 JSON/HTTP 转码调用用户提供的实现.
+// 配置错误 (未知方法、流式方法、缺少实现等) 会在注册时 panic, 与路由注册的行为一致.
+func (engine *Engine) MountGRPCService(svc GRPCService) {
+	mountGRPCService(engine, svc)
+}
+
+// MountGRPCService 将 protobuf 服务挂载到路由组上, 参见 Engine.MountGRPCService
+func (group *RouterGroup) MountGRPCService(svc GRPCService) {
+	mountGRPCService(group, svc)
+}
+
+func mountGRPCService(router Router, svc GRPCService) {
+	if svc.Desc == nil {
+		panic("touka: grpc service descriptor must not be nil")
+	}
+
+	rulesByMethod := make(map[string][]GRPCHTTPRule, len(svc.Rules))
+	for _, rule := range svc.Rules {
+		rulesByMethod[rule.Method] = append(rulesByMethod[rule.Method], rule)
+	}
+
+	methods := svc.Desc.Methods()
+	for name, handler := range svc.Methods {
+		md := methods.ByName(protoreflect.Name(name))
+		if md == nil {
+			panic(fmt.Sprintf("touka: grpc service %s has no method %s", svc.Desc.FullName(), name))
+		}
+		if md.IsStreamingClient() || md.IsStreamingServer() {
+			panic(fmt.Sprintf("touka: grpc method %s is streaming, only unary methods can be transcoded", md.FullName()))
+		}
+		if handler == nil {
+			panic(fmt.Sprintf("touka: grpc method %s has nil handler", md.FullName()))
+		}
+
+		rules := rulesByMethod[name]
+		if len(rules) == 0 {
+			rules = []GRPCHTTPRule{{
+				Method:     name,
+				HTTPMethod: http.MethodPost,
+				Path:       "/" + string(svc.Desc.FullName()) + "/" + name,
+				Body:       "*",
+			}}
+		}
+		for _, rule := range rules {
+			if rule.HTTPMethod == "" || rule.Path == "" {
+				panic(fmt.Sprintf("touka: grpc rule for %s requires HTTPMethod and Path", md.FullName()))
+			}
+			router.Handle(strings.ToUpper(rule.HTTPMethod), rule.Path, grpcTranscodeHandler(svc, md, rule, handler))
+		}
+	}
+
+	for _, rule := range svc.Rules {
+		if _, ok := svc.Methods[rule.Method]; !ok {
+			panic(fmt.Sprintf("touka: grpc rule references method %s without handler", rule.Method))
+		}
+	}
+}
+
+func grpcTranscodeHandler(svc GRPCService, md protoreflect.MethodDescriptor, rule GRPCHTTPRule, handler GRPCUnaryHandler) HandlerFunc {
+	unmarshalOpts := svc.UnmarshalOptions
+	marshalOpts := svc.MarshalOptions
+	return func(c *Context) {
+		req := newGRPCMessage(md.Input())
+
+		if err := grpcDecodeBody(c, req, rule.Body, unmarshalOpts); err != nil {
+			c.AddClientError(err)
+			c.ErrorUseHandle(http.StatusBadRequest, err)
+			return
+		}
+		for _, p := range c.Params {
+			if err := setProtoFieldByPath(req.ProtoReflect(), p.Key, []string{p.Value}); err != nil {
+				c.AddClientError(err)
+				c.ErrorUseHandle(http.StatusBadRequest, err)
+				return
+			}
+		}
+		if rule.Body != "*" {
+			for key, values := range c.Request.URL.Query() {
+				if err := setProtoFieldByPath(req.ProtoReflect(), key, values); err != nil {
+					c.AddClientError(err)
+					c.ErrorUseHandle(http.StatusBadRequest, err)
+					return
+				}
+			}
+		}
+
+		resp, err := handler(c.Context(), req)
+		if err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(typedErrorStatus(err), err)
+			return
+		}
+		if resp == nil {
+			resp = newGRPCMessage(md.Output())
+		}
+
+		if grpcGatewayWantsProtobuf(c.Request) {
+			c.ProtoBuf(http.StatusOK, resp)
+			return
+		}
+		data, err := marshalOpts.Marshal(resp)
+		if err != nil {
+			errMsg := fmt.Errorf("failed to marshal protojson response: %w", err)
+			c.AddError(errMsg)
+			c.ErrorUseHandle(http.StatusInternalServerError, errMsg)
+			return
+		}
+		c.Raw(http.StatusOK, "application/json; charset=utf-8", data)
+	}
+}
+
+// newGRPCMessage 优先使用已注册的生成类型, 便于实现方直接做类型断言
+func newGRPCMessage(md protoreflect.MessageDescriptor) proto.Message {
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName()); err == nil {
+		return mt.New().Interface()
+	}
+	return dynamicpb.NewMessage(md)
+}
+
+func grpcDecodeBody(c *Context, req proto.Message, bodyField string, opts protojson.UnmarshalOptions) error {
+	if bodyField == "" {
+		return nil
+	}
+	data, err := c.GetReqBodyFull()
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	target := req.ProtoReflect()
+	if bodyField != "*" {
+		fd, parent, err := resolveProtoField(target, bodyField)
+		if err != nil {
+			return err
+		}
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return fmt.Errorf("grpc body field %s must be a singular message", bodyField)
+		}
+		target = parent.Mutable(fd).Message()
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	switch mediaType {
+	case grpcGatewayContentTypeProtobuf, "application/protobuf":
+		if err := proto.Unmarshal(data, target.Interface()); err != nil {
+			return fmt.Errorf("protobuf binding error: %w", err)
+		}
+	case "", grpcGatewayContentTypeJSON:
+		if err := opts.Unmarshal(data, target.Interface()); err != nil {
+			return fmt.Errorf("protojson binding error: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported content type: %s", mediaType)
+	}
+	return nil
+}
+
+func grpcGatewayWantsProtobuf(req *http.Request) bool {
+	accept := req.Header.Get("Accept")
+	return strings.Contains(accept, grpcGatewayContentTypeProtobuf) || strings.Contains(accept, "application/protobuf")
+}
+
+// resolveProtoField 解析 a.b.c 形式的字段路径, 返回最终字段及其所属消息 (中间消息按需创建)
+func resolveProtoField(msg protoreflect.Message, fieldPath string) (protoreflect.FieldDescriptor, protoreflect.Message, error) {
+	parts := strings.Split(fieldPath, ".")
+	current := msg
+	for i, part := range parts {
+		fields := current.Descriptor().Fields()
+		fd := fields.ByName(protoreflect.Name(part))
+		if fd == nil {
+			fd = fields.ByJSONName(part)
+		}
+		if fd == nil {
+			return nil, nil, fmt.Errorf("unknown field %q in %s", fieldPath, msg.Descriptor().FullName())
+		}
+		if i == len(parts)-1 {
+			return fd, current, nil
+		}
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return nil, nil, fmt.Errorf("field %q is not a singular message", part)
+		}
+		current = current.Mutable(fd).Message()
+	}
+	return nil, nil, errors.New("empty field path")
+}
+
+// setProtoFieldByPath 将字符串形式的值 (来自路径或查询参数) 写入消息字段
+func setProtoFieldByPath(msg protoreflect.Message, fieldPath string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	fd, parent, err := resolveProtoField(msg, fieldPath)
+	if err != nil {
+		return err
+	}
+	if fd.IsMap() || (fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind) {
+		return fmt.Errorf("field %q cannot be set from a string value", fieldPath)
+	}
+
+	if fd.IsList() {
+		list := parent.Mutable(fd).List()
+		for _, raw := range values {
+			v, err := parseProtoScalar(fd, raw)
+			if err != nil {
+				return fmt.Errorf("field %q: %w", fieldPath, err)
+			}
+			list.Append(v)
+		}
+		return nil
+	}
+
+	v, err := parseProtoScalar(fd, values[0])
+	if err != nil {
+		return fmt.Errorf("field %q: %w", fieldPath, err)
+	}
+	parent.Set(fd, v)
+	return nil
+}
+
+func parseProtoScalar(fd protoreflect.FieldDescriptor, raw string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(raw), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(raw)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(raw, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(raw, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		return protoreflect.ValueOfUint64(n), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(raw, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(raw, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(raw)
+		}
+		return protoreflect.ValueOfBytes(b), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(raw)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown enum value %q", raw)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+	}
+}
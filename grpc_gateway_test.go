@@ -0,0 +1,122 @@
+package touka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func testGreeterDescriptor(t *testing.T) protoreflect.ServiceDescriptor {
+	t.Helper()
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("greeter_test.proto"),
+		Package: proto.String("touka.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("HelloRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+					{Name: proto.String("times"), JsonName: proto.String("times"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				},
+			},
+			{
+				Name: proto.String("HelloReply"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("message"), JsonName: proto.String("message"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("SayHello"),
+				InputType:  proto.String(".touka.test.HelloRequest"),
+				OutputType: proto.String(".touka.test.HelloReply"),
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		t.Fatalf("build descriptor: %v", err)
+	}
+	return fd.Services().Get(0)
+}
+
+func testGreeterImpl(desc protoreflect.ServiceDescriptor) GRPCUnaryHandler {
+	return func(ctx context.Context, req proto.Message) (proto.Message, error) {
+		in := req.ProtoReflect()
+		name := in.Get(in.Descriptor().Fields().ByName("name")).String()
+		if name == "" {
+			return nil, NewHTTPError(http.StatusNotFound, errors.New("nobody to greet"))
+		}
+		times := in.Get(in.Descriptor().Fields().ByName("times")).Int()
+
+		out := dynamicpb.NewMessage(desc.Methods().Get(0).Output())
+		out.Set(out.Descriptor().Fields().ByName("message"), protoreflect.ValueOfString(strings.Repeat("hello "+name+" ", int(times))))
+		return out, nil
+	}
+}
+
+func TestMountGRPCServiceDefaultRoute(t *testing.T) {
+	desc := testGreeterDescriptor(t)
+	engine := New()
+	engine.MountGRPCService(GRPCService{
+		Desc:    desc,
+		Methods: map[string]GRPCUnaryHandler{"SayHello": testGreeterImpl(desc)},
+	})
+
+	headers := http.Header{"Content-Type": []string{"application/json"}}
+	rr := PerformRequest(engine, http.MethodPost, "/touka.test.Greeter/SayHello", strings.NewReader(`{"name":"touka","times":1}`), headers)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `hello touka`) {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+func TestMountGRPCServiceCustomRuleAndErrors(t *testing.T) {
+	desc := testGreeterDescriptor(t)
+	engine := New()
+	engine.MountGRPCService(GRPCService{
+		Desc:    desc,
+		Methods: map[string]GRPCUnaryHandler{"SayHello": testGreeterImpl(desc)},
+		Rules:   []GRPCHTTPRule{{Method: "SayHello", HTTPMethod: "get", Path: "/v1/hello/:name"}},
+	})
+
+	rr := PerformRequest(engine, http.MethodGet, "/v1/hello/iroha?times=2", nil, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if body := rr.Body.String(); strings.Count(body, "hello iroha") != 2 {
+		t.Fatalf("expected path and query params to be transcoded, got %s", body)
+	}
+
+	rr = PerformRequest(engine, http.MethodGet, "/v1/hello/iroha?times=abc", nil, nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad query value, got %d", rr.Code)
+	}
+}
+
+func TestMountGRPCServiceRejectsUnknownMethod(t *testing.T) {
+	desc := testGreeterDescriptor(t)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for unknown method")
+		}
+	}()
+	New().MountGRPCService(GRPCService{
+		Desc:    desc,
+		Methods: map[string]GRPCUnaryHandler{"Missing": testGreeterImpl(desc)},
+	})
+}
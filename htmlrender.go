@@ -0,0 +1,101 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+)
+
+// HTMLRenderer 是第三方模板引擎 (例如 pongo2、jet、templ) 的适配接口.
+// 设置为 Engine.HTMLRender 后, c.HTML、c.HTMLBuf 与 ErrorPages 通过它渲染, 返回的错误交给 ErrorHandler 处理
+type HTMLRenderer interface {
+	// Render 将名为 name 的模板以 data 渲染到 w, c 为当前请求
+	Render(c *Context, w io.Writer, name string, data any) error
+}
+
+// HTMLRenderFunc 将函数适配为 HTMLRenderer
+type HTMLRenderFunc func(c *Context, w io.Writer, name string, data any) error
+
+// Render 调用 f
+func (f HTMLRenderFunc) Render(c *Context, w io.Writer, name string, data any) error {
+	return f(c, w, name, data)
+}
+
+// HTMLLayout 为 HTMLRenderer 增加布局组合: 先渲染页面, 再以 LayoutData 渲染布局模板.
+// 适用于没有布局继承的模板引擎, 也可以统一多个引擎的布局写法
+type HTMLLayout struct {
+	Renderer HTMLRenderer // 渲染页面与布局的渲染器
+	Layout   string       // 默认的布局模板, 为空时不使用布局
+
+	// LayoutFor 返回页面 name 使用的布局, 返回空字符串时只渲染页面本身 (例如 htmx 的局部请求).
+	// 为 nil 时所有页面使用 Layout
+	LayoutFor func(c *Context, name string) string
+}
+
+// LayoutData 是 HTMLLayout 渲染布局模板时传入的数据
+type LayoutData struct {
+	Content template.HTML // 页面的渲染结果
+	Data    any           // 传给页面的数据
+	Context *Context
+
+	renderer HTMLRenderer
+}
+
+// Partial 使用同一个渲染器渲染局部模板 name, 供布局模板引入导航栏、页脚等片段
+func (d LayoutData) Partial(name string, data any) (template.HTML, error) {
+	return RenderHTML(d.renderer, d.Context, name, data)
+}
+
+// Render 实现 HTMLRenderer
+func (l *HTMLLayout) Render(c *Context, w io.Writer, name string, data any) error {
+	layout := l.Layout
+	if l.LayoutFor != nil {
+		layout = l.LayoutFor(c, name)
+	}
+	if layout == "" {
+		return l.Renderer.Render(c, w, name, data)
+	}
+	content, err := RenderHTML(l.Renderer, c, name, data)
+	if err != nil {
+		return err
+	}
+	return l.Renderer.Render(c, w, layout, LayoutData{Content: content, Data: data, Context: c, renderer: l.Renderer})
+}
+
+// RenderHTML 将模板 name 渲染为 template.HTML, 用于在布局或其他模板中组合局部模板.
+// 渲染结果由模板引擎负责转义, 不会再次转义
+func RenderHTML(r HTMLRenderer, c *Context, name string, data any) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := r.Render(c, &buf, name, data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// templateRenderer 将 html/template 适配为 HTMLRenderer
+type templateRenderer struct {
+	tpl *template.Template
+}
+
+func (r templateRenderer) Render(_ *Context, w io.Writer, name string, data any) error {
+	return r.tpl.ExecuteTemplate(w, name, data)
+}
+
+// htmlRenderer 返回 HTMLRender 对应的渲染器, 不支持的类型返回 nil
+func (engine *Engine) htmlRenderer() (HTMLRenderer, error) {
+	switch r := engine.HTMLRender.(type) {
+	case HTMLRenderer:
+		return r, nil
+	case *template.Template:
+		tpl, err := engine.htmlTemplate()
+		if err != nil {
+			return nil, err
+		}
+		return templateRenderer{tpl: tpl}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,143 @@
+package touka
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// stubViews 模拟第三方模板引擎: 按名称查找模板函数
+type stubViews map[string]func(data any) (string, error)
+
+func (v stubViews) renderer() HTMLRenderer {
+	return HTMLRenderFunc(func(c *Context, w io.Writer, name string, data any) error {
+		tpl, ok := v[name]
+		if !ok {
+			return fmt.Errorf("template %q not found", name)
+		}
+		out, err := tpl(data)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, out)
+		return err
+	})
+}
+
+func TestHTMLRendererAdapter(t *testing.T) {
+	views := stubViews{
+		"page": func(data any) (string, error) {
+			return fmt.Sprintf("<p>%s</p>", data.(H)["name"]), nil
+		},
+	}
+	engine := New()
+	engine.HTMLRender = views.renderer()
+	engine.GET("/stream", func(c *Context) {
+		c.HTML(http.StatusOK, "page", H{"name": "touka"})
+	})
+	engine.GET("/buf", func(c *Context) {
+		c.HTMLBuf(http.StatusCreated, "page", H{"name": "buf"})
+	})
+	if err := engine.Validate(); err != nil {
+		t.Fatalf("expected an HTMLRenderer to pass validation, got %v", err)
+	}
+
+	w := PerformRequest(engine, http.MethodGet, "/stream", nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "<p>touka</p>" || w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Fatalf("unexpected HTML response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+	w = PerformRequest(engine, http.MethodGet, "/buf", nil, nil)
+	if w.Code != http.StatusCreated || w.Body.String() != "<p>buf</p>" {
+		t.Fatalf("unexpected HTMLBuf response %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestHTMLRendererErrorUsesErrorHandler(t *testing.T) {
+	errBroken := errors.New("broken template")
+	views := stubViews{
+		"broken": func(any) (string, error) { return "", errBroken },
+	}
+	engine := New()
+	engine.HTMLRender = views.renderer()
+	var handled error
+	engine.SetErrorHandler(func(c *Context, code int, err error) {
+		handled = err
+		c.String(code, "error page")
+	})
+	engine.GET("/broken", func(c *Context) {
+		c.HTMLBuf(http.StatusOK, "broken", nil)
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/broken", nil, nil)
+	if w.Code != http.StatusInternalServerError || w.Body.String() != "error page" {
+		t.Fatalf("expected the error handler response, got %d %q", w.Code, w.Body.String())
+	}
+	if !errors.Is(handled, errBroken) {
+		t.Fatalf("expected the render error to reach the error handler, got %v", handled)
+	}
+}
+
+func TestHTMLLayout(t *testing.T) {
+	views := stubViews{
+		"page": func(data any) (string, error) {
+			return fmt.Sprintf("<p>%v</p>", data), nil
+		},
+		"nav": func(data any) (string, error) {
+			return fmt.Sprintf("<nav>%v</nav>", data), nil
+		},
+		"base": func(data any) (string, error) {
+			ld := data.(LayoutData)
+			nav, err := ld.Partial("nav", ld.Context.Request.URL.Path)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("<html>%s<main>%s</main></html>", nav, ld.Content), nil
+		},
+	}
+	engine := New()
+	engine.HTMLRender = &HTMLLayout{
+		Renderer: views.renderer(),
+		Layout:   "base",
+		LayoutFor: func(c *Context, name string) string {
+			if c.GetReqHeader("HX-Request") != "" {
+				return ""
+			}
+			return "base"
+		},
+	}
+	engine.GET("/home", func(c *Context) {
+		c.HTMLBuf(http.StatusOK, "page", "hello")
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/home", nil, nil)
+	if w.Body.String() != "<html><nav>/home</nav><main><p>hello</p></main></html>" {
+		t.Fatalf("unexpected layout output %q", w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/home", nil, http.Header{"Hx-Request": {"true"}})
+	if w.Body.String() != "<p>hello</p>" {
+		t.Fatalf("expected a partial render without the layout, got %q", w.Body.String())
+	}
+}
+
+func TestHTMLLayoutPageErrorSkipsLayout(t *testing.T) {
+	layoutCalled := false
+	views := stubViews{
+		"base": func(any) (string, error) {
+			layoutCalled = true
+			return "layout", nil
+		},
+	}
+	engine := New()
+	engine.HTMLRender = &HTMLLayout{Renderer: views.renderer(), Layout: "base"}
+	engine.GET("/missing", func(c *Context) {
+		c.HTMLBuf(http.StatusOK, "missing", nil)
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/missing", nil, nil)
+	if w.Code != http.StatusInternalServerError || layoutCalled {
+		t.Fatalf("expected a 500 without rendering the layout, got %d (layout called: %v)", w.Code, layoutCalled)
+	}
+}
@@ -0,0 +1,291 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrIdempotencyInFlight 表示相同 Idempotency-Key 的请求仍在处理中
+var ErrIdempotencyInFlight = errors.New("idempotency: request with the same key is in progress")
+
+// IdempotencyRecord 是被保存下来用于重放的响应
+type IdempotencyRecord struct {
+	Status      int
+	Header      http.Header
+	Body        []byte
+	Fingerprint string // 首次请求的指纹 (方法、路径与请求体的摘要)
+}
+
+// IdempotencyStore 保存幂等请求的响应.
+// Begin 为 key 占位: 占位成功返回 (nil, nil); 已有完成的记录时返回该记录;
+// 仍在处理中时返回 ErrIdempotencyInFlight.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, key string, ttl time.Duration) (*IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+	Release(ctx context.Context, key string) error
+}
+
+type idempotencyEntry struct {
+	record  *IdempotencyRecord // nil 表示处理中
+	expires time.Time
+}
+
+// MemoryIdempotencyStore 是进程内的 IdempotencyStore 实现
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]idempotencyEntry
+	nextSweep time.Time
+}
+
+// NewMemoryIdempotencyStore 创建一个进程内幂等存储
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]idempotencyEntry)}
+}
+
+func (s *MemoryIdempotencyStore) Begin(_ context.Context, key string, ttl time.Duration) (*IdempotencyRecord, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+		if entry.record == nil {
+			return nil, ErrIdempotencyInFlight
+		}
+		return entry.record, nil
+	}
+	// 定期清理过期记录, 避免每个请求都遍历全部记录
+	if now.After(s.nextSweep) {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(time.Minute)
+	}
+	s.entries[key] = idempotencyEntry{expires: now.Add(ttl)}
+	return nil, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	s.entries[key] = idempotencyEntry{record: record, expires: time.Now().Add(ttl)}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// IdempotencyOptions 配置 Idempotency 中间件
+type IdempotencyOptions struct {
+	// Store 保存响应, 为 nil 时使用进程内存储
+	Store IdempotencyStore
+	// TTL 为记录的保留时间, 默认 24 小时
+	TTL time.Duration
+	// MaxBodySize 为可保存的响应体上限, 超出时不保存 (重试会再次执行), 默认 1MB
+	MaxBodySize int
+	// Header 为读取幂等键的请求头, 默认 Idempotency-Key
+	Header string
+	// Methods 为需要处理的方法, 默认 POST 与 PATCH
+	Methods []string
+	// Required 为 true 时, 缺少幂等键的请求返回 400
+	Required bool
+	// KeyFunc 用于将幂等键限定到调用方 (例如拼接用户 ID), 默认直接使用请求头的值
+	KeyFunc func(c *Context, key string) string
+	// WaitInFlight 为 true 时, 相同键的并发请求通过引擎的 Locker 排队, 等待首个请求完成后重放其响应,
+	// 而不是返回 409
+	WaitInFlight bool
+	// LockTTL 为 WaitInFlight 使用的锁租约时长, 默认 30 秒
+	LockTTL time.Duration
+}
+
+// Idempotency 返回一个处理 Idempotency-Key 请求头的中间件.
+// 首个请求的响应 (状态码、响应头与响应体) 会被保存, TTL 内携带相同键的重试直接重放该响应,
+// 并附带 Idempotent-Replayed: true 响应头; 相同键的请求仍在处理时返回 409;
+// 相同键但请求内容不同时返回 422. 5xx 响应不会被保存, 以便客户端重试.
+func Idempotency(opts IdempotencyOptions) HandlerFunc {
+	if opts.Store == nil {
+		opts.Store = NewMemoryIdempotencyStore()
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 24 * time.Hour
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 1 << 20
+	}
+	if opts.Header == "" {
+		opts.Header = "Idempotency-Key"
+	}
+	if len(opts.Methods) == 0 {
+		opts.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = 30 * time.Second
+	}
+
+	return func(c *Context) {
+		if !slices.Contains(opts.Methods, c.Request.Method) {
+			c.Next()
+			return
+		}
+		key := c.GetReqHeader(opts.Header)
+		if key == "" {
+			if opts.Required {
+				c.ErrorUseHandle(http.StatusBadRequest, fmt.Errorf("missing %s header", opts.Header))
+				return
+			}
+			c.Next()
+			return
+		}
+		if opts.KeyFunc != nil {
+			key = opts.KeyFunc(c, key)
+		}
+
+		fingerprint, err := idempotencyFingerprint(c)
+		if err != nil {
+			if errors.Is(err, ErrBodyTooLarge) {
+				c.ErrorUseHandle(http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			c.ErrorUseHandle(http.StatusBadRequest, err)
+			return
+		}
+
+		if opts.WaitInFlight {
+			unlock, err := c.Lock("idempotency:"+key, opts.LockTTL)
+			if err != nil {
+				c.ErrorUseHandle(http.StatusServiceUnavailable, fmt.Errorf("idempotency lock: %w", err))
+				return
+			}
+			defer unlock()
+		}
+
+		ctx := c.Context()
+		record, err := opts.Store.Begin(ctx, key, opts.TTL)
+		switch {
+		case errors.Is(err, ErrIdempotencyInFlight):
+			c.AddClientError(err)
+			c.ErrorUseHandle(http.StatusConflict, err)
+			return
+		case err != nil:
+			c.ErrorUseHandle(http.StatusInternalServerError, fmt.Errorf("idempotency store: %w", err))
+			return
+		case record != nil:
+			if record.Fingerprint != fingerprint {
+				c.ErrorUseHandle(http.StatusUnprocessableEntity, errors.New("idempotency key reused with a different request"))
+				return
+			}
+			replayIdempotencyRecord(c, record)
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer, limit: c.bufferLimit(opts.MaxBodySize)}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			c.Writer = recorder.ResponseWriter
+			if !completed {
+				// 处理过程中 panic, 释放占位以便重试
+				if err := opts.Store.Release(context.WithoutCancel(ctx), key); err != nil {
+					c.AddError(fmt.Errorf("idempotency store: %w", err))
+				}
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		// 请求可能已被客户端取消, 保存与释放不应受其影响
+		storeCtx := context.WithoutCancel(ctx)
+		if status >= 500 || recorder.overflow || recorder.IsHijacked() {
+			err = opts.Store.Release(storeCtx, key)
+		} else {
+			err = opts.Store.Complete(storeCtx, key, &IdempotencyRecord{
+				Status:      status,
+				Header:      recorder.Header().Clone(),
+				Body:        recorder.body.Bytes(),
+				Fingerprint: fingerprint,
+			}, opts.TTL)
+		}
+		completed = true
+		if err != nil {
+			c.AddError(fmt.Errorf("idempotency store: %w", err))
+		}
+	}
+}
+
+func idempotencyFingerprint(c *Context) (string, error) {
+	body, err := c.GetReqBodyFull()
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Request.URL.RequestURI()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func replayIdempotencyRecord(c *Context, record *IdempotencyRecord) {
+	c.Writer.Header().Set("Idempotent-Replayed", "true")
+	writeRecordedResponse(c, record)
+	c.Abort()
+}
+
+// writeRecordedResponse 写出记录下来的响应
+func writeRecordedResponse(c *Context, record *IdempotencyRecord) {
+	header := c.Writer.Header()
+	for k, v := range record.Header {
+		header[k] = slices.Clone(v)
+	}
+	c.Writer.WriteHeader(record.Status)
+	if len(record.Body) > 0 {
+		if _, err := c.Writer.Write(record.Body); err != nil {
+			c.AddError(fmt.Errorf("replay recorded response: %w", err))
+		}
+	}
+}
+
+// idempotencyRecorder 在写出响应的同时记录响应体
+type idempotencyRecorder struct {
+	ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if !r.overflow {
+		if r.body.Len()+len(b) > r.limit {
+			r.overflow = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
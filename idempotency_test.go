@@ -0,0 +1,122 @@
+package touka
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	var calls int
+	engine := New()
+	engine.Use(Idempotency(IdempotencyOptions{}))
+	engine.POST("/charges", func(c *Context) {
+		calls++
+		c.SetHeader("X-Charge", "ch_1")
+		c.String(http.StatusCreated, "charged %d", calls)
+	})
+
+	headers := http.Header{"Idempotency-Key": []string{"k1"}}
+	first := PerformRequest(engine, http.MethodPost, "/charges", strings.NewReader("amount=10"), headers)
+	second := PerformRequest(engine, http.MethodPost, "/charges", strings.NewReader("amount=10"), headers)
+	if calls != 1 {
+		t.Fatalf("expected handler to run once, got %d", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("expected replayed response, got %d %q", second.Code, second.Body.String())
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" || second.Header().Get("X-Charge") != "ch_1" {
+		t.Fatalf("expected replayed headers, got %v", second.Header())
+	}
+
+	rr := PerformRequest(engine, http.MethodPost, "/charges", strings.NewReader("amount=99"), headers)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for reused key with different body, got %d", rr.Code)
+	}
+
+	// 没有幂等键时不做处理
+	PerformRequest(engine, http.MethodPost, "/charges", nil, nil)
+	if calls != 2 {
+		t.Fatalf("expected request without key to run handler, got %d calls", calls)
+	}
+}
+
+func TestIdempotencyConcurrentAndServerErrors(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	engine := New()
+	engine.Use(Idempotency(IdempotencyOptions{Store: store, Required: true}))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	engine.POST("/slow", func(c *Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+	var failures int
+	engine.POST("/flaky", func(c *Context) {
+		failures++
+		if failures == 1 {
+			c.Status(http.StatusServiceUnavailable)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	if rr := PerformRequest(engine, http.MethodPost, "/slow", nil, nil); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing key, got %d", rr.Code)
+	}
+
+	headers := http.Header{"Idempotency-Key": []string{"slow"}}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		PerformRequest(engine, http.MethodPost, "/slow", nil, headers)
+	}()
+	<-started
+	if rr := PerformRequest(engine, http.MethodPost, "/slow", nil, headers); rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for concurrent duplicate, got %d", rr.Code)
+	}
+	close(release)
+	wg.Wait()
+
+	headers = http.Header{"Idempotency-Key": []string{"flaky"}}
+	PerformRequest(engine, http.MethodPost, "/flaky", nil, headers)
+	if rr := PerformRequest(engine, http.MethodPost, "/flaky", nil, headers); rr.Code != http.StatusOK || failures != 2 {
+		t.Fatalf("expected 5xx response not to be stored, got %d after %d calls", rr.Code, failures)
+	}
+}
+
+func TestMemoryIdempotencyStoreSweepsPeriodically(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	ctx := context.Background()
+	if _, err := store.Begin(ctx, "expired", time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	// 下一次清理之前不再遍历全部记录
+	if _, err := store.Begin(ctx, "a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.entries["expired"]; !ok {
+		t.Fatal("expected expired records to be kept until the next sweep")
+	}
+	store.nextSweep = time.Time{}
+	if _, err := store.Begin(ctx, "b", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.entries["expired"]; ok {
+		t.Fatal("expected the sweep to remove expired records")
+	}
+	// 过期的记录在清理之前也不影响再次占位
+	if _, err := store.Begin(ctx, "c", time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if rec, err := store.Begin(ctx, "c", time.Minute); err != nil || rec != nil {
+		t.Fatalf("expected an expired key to be claimable, got %v %v", rec, err)
+	}
+}
@@ -0,0 +1,187 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// JSONOptions 配置 c.JSON、c.JSONBuf 与 ShouldBindJSON 的 JSON 编解码行为
+type JSONOptions struct {
+	// FieldNaming 在编码时转换对象成员名, 例如 SnakeCase 或 LowerCamelCase.
+	// 它作用于输出中的所有对象键 (包括 map 的键); 解码时自动启用忽略大小写、下划线与连字符的成员名匹配
+	FieldNaming func(name string) string
+	// OmitZero 省略值为零的结构体字段, 相当于为每个字段加上 omitzero 标签
+	OmitZero bool
+	// TimeFormat 为 time.Time 编码与解码使用的布局, 例如 time.DateTime; 为空时使用 RFC 3339
+	TimeFormat string
+	// EscapeHTML 将字符串中的 <、>、& 转义为 \u003c 等形式, 便于直接嵌入 HTML
+	EscapeHTML bool
+	// RejectUnknownFields 使 ShouldBindJSON 等同于 ShouldBindJSONStrict, 请求体包含结构体中不存在的成员时返回错误
+	RejectUnknownFields bool
+}
+
+// jsonConfig 是由 JSONOptions 预先构建的编解码选项
+type jsonConfig struct {
+	marshal   json.Options
+	unmarshal json.Options
+	naming    func(string) string
+
+	rejectUnknown bool // 解码时通过 ShouldBindJSONStrict 列出全部未知字段
+}
+
+// SetJSONOptions 设置引擎级的 JSON 编解码选项
+func (engine *Engine) SetJSONOptions(opts JSONOptions) {
+	var marshal, unmarshal []json.Options
+	if opts.OmitZero {
+		marshal = append(marshal, json.OmitZeroStructFields(true))
+	}
+	if opts.EscapeHTML {
+		marshal = append(marshal, jsontext.EscapeForHTML(true))
+	}
+	if opts.FieldNaming != nil {
+		unmarshal = append(unmarshal, json.MatchCaseInsensitiveNames(true))
+	}
+	if opts.RejectUnknownFields {
+		unmarshal = append(unmarshal, json.RejectUnknownMembers(true))
+	}
+	if layout := opts.TimeFormat; layout != "" {
+		marshal = append(marshal, json.WithMarshalers(json.MarshalToFunc(func(enc *jsontext.Encoder, t time.Time) error {
+			return enc.WriteToken(jsontext.String(t.Format(layout)))
+		})))
+		unmarshal = append(unmarshal, json.WithUnmarshalers(json.UnmarshalFromFunc(func(dec *jsontext.Decoder, t *time.Time) error {
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+			if tok.Kind() == 'n' {
+				return nil
+			}
+			if tok.Kind() != '"' {
+				return fmt.Errorf("cannot unmarshal JSON %s into time.Time", tok.Kind())
+			}
+			parsed, err := time.Parse(layout, tok.String())
+			if err != nil {
+				return err
+			}
+			*t = parsed
+			return nil
+		})))
+	}
+	engine.jsonConfig = &jsonConfig{
+		marshal:   json.JoinOptions(marshal...),
+		unmarshal: json.JoinOptions(unmarshal...),
+		naming:    opts.FieldNaming,
+
+		rejectUnknown: opts.RejectUnknownFields,
+	}
+}
+
+// marshalJSON 按引擎的 JSON 选项将 obj 编码写入 w, opts 为附加的选项 (例如缩进), 同名选项优先于引擎设置
+func (c *Context) marshalJSON(w io.Writer, obj any, opts ...json.Options) error {
+	var cfg *jsonConfig
+	if c.engine != nil {
+		cfg = c.engine.jsonConfig
+	}
+	if cfg == nil {
+		return json.MarshalWrite(w, obj, opts...)
+	}
+	marshal := cfg.marshal
+	if len(opts) > 0 {
+		marshal = json.JoinOptions(append([]json.Options{cfg.marshal}, opts...)...)
+	}
+	if cfg.naming == nil {
+		return json.MarshalWrite(w, obj, marshal)
+	}
+	data, err := json.Marshal(obj, marshal)
+	if err != nil {
+		return err
+	}
+	if data, err = renameJSONMembers(data, cfg.naming, marshal); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// unmarshalJSON 按引擎的 JSON 选项从 r 解码到 obj
+func (c *Context) unmarshalJSON(r io.Reader, obj any) error {
+	if c.engine == nil || c.engine.jsonConfig == nil {
+		return json.UnmarshalRead(r, obj)
+	}
+	return json.UnmarshalRead(r, obj, c.engine.jsonConfig.unmarshal)
+}
+
+// renameJSONMembers 对 JSON 中所有对象成员名应用 naming
+func renameJSONMembers(data []byte, naming func(string) string, opts json.Options) ([]byte, error) {
+	dec := jsontext.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	// 改名后可能出现重复的键, 由调用方的命名函数负责避免
+	enc := jsontext.NewEncoder(&out, opts, jsontext.AllowDuplicateNames(true))
+	for {
+		kind, length := dec.StackIndex(dec.StackDepth())
+		isName := kind == '{' && length%2 == 0 && dec.PeekKind() == '"'
+		tok, err := dec.ReadToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isName {
+			tok = jsontext.String(naming(tok.String()))
+		}
+		if err := enc.WriteToken(tok); err != nil {
+			return nil, err
+		}
+	}
+	return bytes.TrimRight(out.Bytes(), "\n"), nil
+}
+
+// SnakeCase 将 Go 风格的名称转换为 snake_case, 例如 UserID -> user_id, HTTPServer -> http_server
+func SnakeCase(name string) string {
+	return joinWords(name, "_")
+}
+
+// LowerCamelCase 将名称的首个单词转为小写, 例如 UserID -> userID, HTTPServer -> httpServer
+func LowerCamelCase(name string) string {
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsUpper(r) {
+			break
+		}
+		// 连续大写 (缩写) 的最后一个字母属于下一个单词
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
+
+// joinWords 按大小写边界拆分单词并以 sep 连接为小写
+func joinWords(name, sep string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteString(sep)
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONOptionsEncoding(t *testing.T) {
+	type payload struct {
+		UserID    int
+		HTMLNote  string
+		CreatedAt time.Time
+		Tags      map[string]int
+		Empty     string
+	}
+	engine := New()
+	engine.SetJSONOptions(JSONOptions{
+		FieldNaming: SnakeCase,
+		OmitZero:    true,
+		TimeFormat:  time.DateOnly,
+		EscapeHTML:  true,
+	})
+	engine.GET("/", func(c *Context) {
+		c.JSON(http.StatusOK, payload{
+			UserID:    1,
+			HTMLNote:  "<b>",
+			CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Tags:      map[string]int{"GoLang": 1},
+		})
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/", nil, nil)
+	want := `{"user_id":1,"html_note":"\u003cb\u003e","created_at":"2026-01-02","tags":{"go_lang":1}}`
+	if w.Body.String() != want {
+		t.Fatalf("unexpected body\n got %s\nwant %s", w.Body.String(), want)
+	}
+}
+
+func TestJSONOptionsDecoding(t *testing.T) {
+	type request struct {
+		UserID    int
+		CreatedAt time.Time
+	}
+	engine := New()
+	engine.SetJSONOptions(JSONOptions{FieldNaming: SnakeCase, TimeFormat: time.DateOnly, RejectUnknownFields: true})
+	engine.POST("/", func(c *Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.String(http.StatusBadRequest, "%s", err)
+			return
+		}
+		c.String(http.StatusOK, "%d %s", req.UserID, req.CreatedAt.Format(time.DateOnly))
+	})
+
+	w := PerformRequest(engine, http.MethodPost, "/", strings.NewReader(`{"user_id":7,"created_at":"2026-03-04"}`), nil)
+	if w.Code != http.StatusOK || w.Body.String() != "7 2026-03-04" {
+		t.Fatalf("unexpected %d %q", w.Code, w.Body.String())
+	}
+	w = PerformRequest(engine, http.MethodPost, "/", strings.NewReader(`{"user_id":7,"extra":true}`), nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown fields should be rejected, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestNamingFuncs(t *testing.T) {
+	cases := []struct{ in, snake, camel string }{
+		{"UserID", "user_id", "userID"},
+		{"HTTPServer", "http_server", "httpServer"},
+		{"ID", "id", "id"},
+		{"Name", "name", "name"},
+		{"OAuth2Token", "o_auth2_token", "oAuth2Token"},
+		{"already_snake", "already_snake", "already_snake"},
+	}
+	for _, tc := range cases {
+		if got := SnakeCase(tc.in); got != tc.snake {
+			t.Errorf("SnakeCase(%q) = %q, want %q", tc.in, got, tc.snake)
+		}
+		if got := LowerCamelCase(tc.in); got != tc.camel {
+			t.Errorf("LowerCamelCase(%q) = %q, want %q", tc.in, got, tc.camel)
+		}
+	}
+}
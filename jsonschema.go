@@ -0,0 +1,589 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-json-experiment/json"
+)
+
+// JSONSchema 是 JSON Schema 中常用关键字的子集, 用于请求体校验.
+// 不支持 $ref 等引用关键字.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty"`
+	MultipleOf           *float64               `json:"multipleOf,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+	AllOf                []*JSONSchema          `json:"allOf,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	OneOf                []*JSONSchema          `json:"oneOf,omitempty"`
+	Not                  *JSONSchema            `json:"not,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// SchemaViolation 描述一处校验失败, Path 为 JSON Pointer
+type SchemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError 包含请求体的全部校验失败项
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("schema validation failed: ")
+	for i, v := range e.Violations {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		if v.Path != "" {
+			b.WriteString(v.Path)
+			b.WriteString(": ")
+		}
+		b.WriteString(v.Message)
+	}
+	return b.String()
+}
+
+// ParseJSONSchema 从 JSON 文本解析 schema
+func ParseJSONSchema(data []byte) (*JSONSchema, error) {
+	var schema JSONSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	if err := schema.compile(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// compile 预编译 schema 中的正则表达式
+func (s *JSONSchema) compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" && s.pattern == nil {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("jsonschema: invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	for _, sub := range s.Properties {
+		if err := sub.compile(); err != nil {
+			return err
+		}
+	}
+	for _, group := range [][]*JSONSchema{s.AllOf, s.AnyOf, s.OneOf, {s.Items, s.Not}} {
+		for _, sub := range group {
+			if err := sub.compile(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate 校验一个已解码的 JSON 值 (map[string]any / []any / float64 / string / bool / nil),
+// 返回全部校验失败项
+func (s *JSONSchema) Validate(value any) []SchemaViolation {
+	var violations []SchemaViolation
+	s.validate(value, "", &violations)
+	return violations
+}
+
+func (s *JSONSchema) validate(value any, path string, out *[]SchemaViolation) {
+	if s == nil {
+		return
+	}
+	report := func(format string, args ...any) {
+		*out = append(*out, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !jsonSchemaTypeMatches(s.Type, value) {
+		report("expected %s, got %s", s.Type, jsonSchemaTypeOf(value))
+		return
+	}
+	if len(s.Enum) > 0 && !jsonSchemaEnumContains(s.Enum, value) {
+		report("value must be one of %v", s.Enum)
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.validateString(v, report)
+	case float64:
+		s.validateNumber(v, report)
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			report("must contain at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			report("must contain at most %d items", *s.MaxItems)
+		}
+		if s.UniqueItems && !jsonSchemaUnique(v) {
+			report("items must be unique")
+		}
+		for i, item := range v {
+			s.Items.validate(item, path+"/"+strconv.Itoa(i), out)
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*out = append(*out, SchemaViolation{Path: path + "/" + jsonPointerEscape(name), Message: "is required"})
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(v)) {
+			item := v[name]
+			sub, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*out = append(*out, SchemaViolation{Path: path + "/" + jsonPointerEscape(name), Message: "is not allowed"})
+				}
+				continue
+			}
+			sub.validate(item, path+"/"+jsonPointerEscape(name), out)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(value, path, out)
+	}
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for _, sub := range s.AnyOf {
+			if len(sub.Validate(value)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			report("must match at least one schema in anyOf")
+		}
+	}
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for _, sub := range s.OneOf {
+			if len(sub.Validate(value)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			report("must match exactly one schema in oneOf, matched %d", matched)
+		}
+	}
+	if s.Not != nil && len(s.Not.Validate(value)) == 0 {
+		report("must not match the schema in not")
+	}
+}
+
+func (s *JSONSchema) validateString(v string, report func(string, ...any)) {
+	if s.MinLength != nil || s.MaxLength != nil {
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			report("length must be at least %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			report("length must be at most %d", *s.MaxLength)
+		}
+	}
+	if s.Pattern != "" {
+		re := s.pattern
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(s.Pattern); err != nil {
+				report("invalid pattern %q", s.Pattern)
+				return
+			}
+		}
+		if !re.MatchString(v) {
+			report("must match pattern %q", s.Pattern)
+		}
+	}
+	if s.Format != "" && !jsonSchemaFormatValid(s.Format, v) {
+		report("must be a valid %s", s.Format)
+	}
+}
+
+func (s *JSONSchema) validateNumber(v float64, report func(string, ...any)) {
+	if s.Minimum != nil && v < *s.Minimum {
+		report("must be >= %v", *s.Minimum)
+	}
+	if s.Maximum != nil && v > *s.Maximum {
+		report("must be <= %v", *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+		report("must be > %v", *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && v >= *s.ExclusiveMaximum {
+		report("must be < %v", *s.ExclusiveMaximum)
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		if q := v / *s.MultipleOf; q != math.Trunc(q) {
+			report("must be a multiple of %v", *s.MultipleOf)
+		}
+	}
+}
+
+func jsonSchemaTypeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func jsonSchemaTypeMatches(want string, value any) bool {
+	got := jsonSchemaTypeOf(value)
+	return got == want || (want == "number" && got == "integer")
+}
+
+func jsonSchemaEnumContains(enum []any, value any) bool {
+	for _, candidate := range enum {
+		if reflect.DeepEqual(jsonSchemaNormalize(candidate), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonSchemaNormalize 将 Go 字面量 (如 int) 转换为 JSON 解码后的表示, 便于比较
+func jsonSchemaNormalize(v any) any {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	}
+	return v
+}
+
+func jsonSchemaUnique(items []any) bool {
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if reflect.DeepEqual(items[i], items[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+var jsonSchemaUUIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func jsonSchemaFormatValid(format, v string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(v)
+		return err == nil && addr.Address == v
+	case "uri", "url":
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, v)
+		return err == nil
+	case "uuid":
+		return jsonSchemaUUIDPattern.MatchString(v)
+	case "ipv4":
+		addr, err := netip.ParseAddr(v)
+		return err == nil && addr.Is4()
+	case "ipv6":
+		addr, err := netip.ParseAddr(v)
+		return err == nil && addr.Is6()
+	default:
+		// 未知格式仅作注解, 不参与校验
+		return true
+	}
+}
+
+func jsonPointerEscape(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// JSONSchemaFor 根据结构体类型生成 schema.
+// 字段名取自 json 标签; 非指针且未标记 omitempty/omitzero 的字段视为必填;
+// 额外约束可通过 jsonschema 标签声明, 例如 `jsonschema:"minLength=1,maxLength=64,format=email,enum=a|b"`
+func JSONSchemaFor[T any]() *JSONSchema {
+	schema := jsonSchemaForType(reflect.TypeFor[T](), make(map[reflect.Type]bool))
+	if err := schema.compile(); err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+var jsonSchemaTimeType = reflect.TypeFor[time.Time]()
+
+func jsonSchemaForType(t reflect.Type, seen map[reflect.Type]bool) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == jsonSchemaTimeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string"} // []byte 编码为 base64 字符串
+		}
+		return &JSONSchema{Type: "array", Items: jsonSchemaForType(t.Elem(), seen)}
+	case reflect.Map:
+		return &JSONSchema{Type: "object"}
+	case reflect.Struct:
+		if seen[t] {
+			// 递归类型不再展开
+			return &JSONSchema{Type: "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+		jsonSchemaAddFields(schema, t, seen)
+		return schema
+	default:
+		return &JSONSchema{}
+	}
+}
+
+func jsonSchemaAddFields(schema *JSONSchema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				jsonSchemaAddFields(schema, ft, seen)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := jsonSchemaForType(field.Type, seen)
+		if err := jsonSchemaApplyTag(prop, field.Tag.Get("jsonschema")); err != nil {
+			panic(fmt.Sprintf("touka: field %s.%s: %v", t.Name(), field.Name, err))
+		}
+		schema.Properties[name] = prop
+
+		optional := field.Type.Kind() == reflect.Pointer
+		for _, opt := range strings.Split(opts, ",") {
+			if opt == "omitempty" || opt == "omitzero" {
+				optional = true
+			}
+		}
+		if !optional {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func jsonSchemaApplyTag(s *JSONSchema, tag string) error {
+	if tag == "" {
+		return nil
+	}
+	for _, item := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(item, "=")
+		var err error
+		switch key {
+		case "minLength":
+			s.MinLength, err = jsonSchemaIntPtr(value)
+		case "maxLength":
+			s.MaxLength, err = jsonSchemaIntPtr(value)
+		case "minItems":
+			s.MinItems, err = jsonSchemaIntPtr(value)
+		case "maxItems":
+			s.MaxItems, err = jsonSchemaIntPtr(value)
+		case "minimum":
+			s.Minimum, err = jsonSchemaFloatPtr(value)
+		case "maximum":
+			s.Maximum, err = jsonSchemaFloatPtr(value)
+		case "exclusiveMinimum":
+			s.ExclusiveMinimum, err = jsonSchemaFloatPtr(value)
+		case "exclusiveMaximum":
+			s.ExclusiveMaximum, err = jsonSchemaFloatPtr(value)
+		case "multipleOf":
+			s.MultipleOf, err = jsonSchemaFloatPtr(value)
+		case "pattern":
+			s.Pattern = value
+		case "format":
+			s.Format = value
+		case "uniqueItems":
+			s.UniqueItems = true
+		case "enum":
+			for _, v := range strings.Split(value, "|") {
+				if s.Type == "integer" || s.Type == "number" {
+					f, perr := strconv.ParseFloat(v, 64)
+					if perr != nil {
+						return fmt.Errorf("invalid enum value %q", v)
+					}
+					s.Enum = append(s.Enum, f)
+					continue
+				}
+				s.Enum = append(s.Enum, v)
+			}
+		default:
+			return fmt.Errorf("unknown jsonschema tag option %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func jsonSchemaIntPtr(s string) (*int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func jsonSchemaFloatPtr(s string) (*float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// schemaValidationResponse 是默认错误处理器下 422 响应的结构
+type schemaValidationResponse struct {
+	Code       int               `json:"code"`
+	Message    string            `json:"message"`
+	Error      string            `json:"error"`
+	Violations []SchemaViolation `json:"violations"`
+}
+
+// ValidateJSONSchema 返回一个在处理器之前校验 JSON 请求体的中间件.
+// 请求体不是合法 JSON 时返回 400; 不满足 schema 时返回 422, 默认错误处理器下响应中会列出全部校验失败项;
+// 使用自定义错误处理器时, 可以通过 errors.As 取得 *SchemaValidationError.
+// 校验后请求体会被重置, 处理器可以正常绑定.
+func ValidateJSONSchema(schema *JSONSchema) HandlerFunc {
+	if schema == nil {
+		panic("touka: json schema must not be nil")
+	}
+	if err := schema.compile(); err != nil {
+		panic(err)
+	}
+	return func(c *Context) {
+		body, err := c.GetReqBodyFull()
+		if err != nil {
+			if errors.Is(err, ErrBodyTooLarge) {
+				c.ErrorUseHandle(http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			c.ErrorUseHandle(http.StatusBadRequest, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var value any
+		if err := json.Unmarshal(body, &value); err != nil {
+			c.ErrorUseHandle(http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+			return
+		}
+
+		violations := schema.Validate(value)
+		if len(violations) == 0 {
+			c.Next()
+			return
+		}
+
+		verr := &SchemaValidationError{Violations: violations}
+		c.AddError(verr)
+		if c.engine != nil && !c.engine.errorHandle.useDefault {
+			c.ErrorUseHandle(http.StatusUnprocessableEntity, verr)
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, schemaValidationResponse{
+			Code:       http.StatusUnprocessableEntity,
+			Message:    http.StatusText(http.StatusUnprocessableEntity),
+			Error:      "schema validation failed",
+			Violations: violations,
+		})
+		c.Abort()
+	}
+}
+
+// ValidateJSONSchemaFor 是 ValidateJSONSchema(JSONSchemaFor[T]()) 的简写
+func ValidateJSONSchemaFor[T any]() HandlerFunc {
+	return ValidateJSONSchema(JSONSchemaFor[T]())
+}
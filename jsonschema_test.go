@@ -0,0 +1,88 @@
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type testSignup struct {
+	Name  string   `json:"name" jsonschema:"minLength=2,maxLength=16"`
+	Email string   `json:"email" jsonschema:"format=email"`
+	Age   int      `json:"age,omitempty" jsonschema:"minimum=18"`
+	Role  string   `json:"role,omitempty" jsonschema:"enum=admin|user"`
+	Tags  []string `json:"tags,omitempty" jsonschema:"maxItems=2"`
+}
+
+func TestJSONSchemaForStruct(t *testing.T) {
+	schema := JSONSchemaFor[testSignup]()
+	if schema.Type != "object" || len(schema.Required) != 2 {
+		t.Fatalf("unexpected schema: type=%s required=%v", schema.Type, schema.Required)
+	}
+	if schema.Properties["tags"].Items.Type != "string" || *schema.Properties["age"].Minimum != 18 {
+		t.Fatalf("unexpected property schemas")
+	}
+
+	violations := schema.Validate(map[string]any{
+		"name": "x",
+		"age":  float64(12),
+		"role": "root",
+		"tags": []any{"a", "b", "c"},
+	})
+	paths := make(map[string]bool)
+	for _, v := range violations {
+		paths[v.Path] = true
+	}
+	for _, want := range []string{"/name", "/email", "/age", "/role", "/tags"} {
+		if !paths[want] {
+			t.Fatalf("expected violation at %s, got %+v", want, violations)
+		}
+	}
+}
+
+func TestValidateJSONSchemaMiddleware(t *testing.T) {
+	engine := New()
+	engine.POST("/signup", ValidateJSONSchemaFor[testSignup](), func(c *Context) {
+		var req testSignup
+		if err := c.ShouldBindJSON(&req); err != nil {
+			t.Errorf("body should be rewound: %v", err)
+		}
+		c.String(http.StatusOK, "%s", req.Name)
+	})
+	headers := http.Header{"Content-Type": []string{"application/json"}}
+
+	rr := PerformRequest(engine, http.MethodPost, "/signup", strings.NewReader(`{"name":"iroha","email":"i@example.com"}`), headers)
+	if rr.Code != http.StatusOK || rr.Body.String() != "iroha" {
+		t.Fatalf("expected valid body to pass, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodPost, "/signup", strings.NewReader(`{"name":1,"email":"nope"}`), headers)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, `"path":"/name"`) || !strings.Contains(body, `"path":"/email"`) {
+		t.Fatalf("expected all violations to be listed, got %s", body)
+	}
+
+	rr = PerformRequest(engine, http.MethodPost, "/signup", strings.NewReader(`{`), headers)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed JSON, got %d", rr.Code)
+	}
+}
+
+func TestParseJSONSchema(t *testing.T) {
+	schema, err := ParseJSONSchema([]byte(`{"type":"object","required":["id"],"additionalProperties":false,
+		"properties":{"id":{"type":"string","pattern":"^[a-z]+$"}}}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	violations := schema.Validate(map[string]any{"id": "ABC", "extra": true})
+	if len(violations) != 2 {
+		t.Fatalf("expected pattern and additionalProperties violations, got %+v", violations)
+	}
+
+	if _, err := ParseJSONSchema([]byte(`{"pattern":"("}`)); err == nil {
+		t.Fatal("expected invalid pattern to fail")
+	}
+}
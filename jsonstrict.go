@@ -0,0 +1,123 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// MetaStrictJSON 是声明路由使用严格 JSON 绑定的元数据键
+const MetaStrictJSON = "touka.strict_json"
+
+// StrictJSON 返回声明严格 JSON 绑定的路由元数据, 用于 WithMeta:
+//
+//	r.WithMeta(touka.StrictJSON()).POST("/users", createUser)
+//
+// 声明后该路由上的 ShouldBindJSON (以及 JSONHandler 等基于它的绑定) 等同于 ShouldBindJSONStrict
+func StrictJSON() (string, any) {
+	return MetaStrictJSON, true
+}
+
+// maxReportedUnknownFields 为 UnknownFieldsError 最多列出的字段数
+const maxReportedUnknownFields = 32
+
+// UnknownFieldsError 表示 JSON 请求体包含目标结构体中不存在的字段
+type UnknownFieldsError struct {
+	// Fields 为未知字段的 JSON Pointer, 例如 /role 或 /address/zip
+	Fields []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return "json binding error: unknown fields: " + strings.Join(e.Fields, ", ")
+}
+
+// ShouldBindJSONStrict 与 ShouldBindJSON 相同, 但请求体包含目标结构体中不存在的字段时返回 *UnknownFieldsError,
+// 其中列出所有未知字段. 适用于不应静默忽略多余字段的写接口 (例如防止客户端尝试写入 role 等字段)
+func (c *Context) ShouldBindJSONStrict(obj any) error {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
+	body := c.bindRequestBody()
+	if body == nil {
+		return errors.New("request body is empty")
+	}
+	data, err := c.readAllBody(body)
+	if err != nil {
+		return fmt.Errorf("json binding error: %w", err)
+	}
+
+	opts := json.RejectUnknownMembers(true)
+	if c.engine != nil && c.engine.jsonConfig != nil {
+		opts = json.JoinOptions(c.engine.jsonConfig.unmarshal, opts)
+	}
+	var unknown []string
+	for {
+		err := json.Unmarshal(data, obj, opts)
+		var semErr *json.SemanticError
+		if !errors.As(err, &semErr) || semErr.Err != json.ErrUnknownName {
+			if err != nil {
+				return fmt.Errorf("json binding error: %w", err)
+			}
+			break
+		}
+		// 移除该成员后重新解码, 以便一次列出所有未知字段
+		unknown = append(unknown, string(semErr.JSONPointer))
+		if len(unknown) >= maxReportedUnknownFields {
+			break
+		}
+		if data, err = removeJSONMember(data, semErr.JSONPointer); err != nil {
+			break
+		}
+	}
+	if len(unknown) > 0 {
+		return &UnknownFieldsError{Fields: unknown}
+	}
+	return nil
+}
+
+// strictJSONBinding 报告当前请求是否应使用严格 JSON 绑定
+func (c *Context) strictJSONBinding() bool {
+	if c.engine != nil && c.engine.jsonConfig != nil && c.engine.jsonConfig.rejectUnknown {
+		return true
+	}
+	v, _ := c.RouteMetaValue(MetaStrictJSON)
+	strict, _ := v.(bool)
+	return strict
+}
+
+// removeJSONMember 返回移除了 ptr 指向的对象成员后的 JSON
+func removeJSONMember(data []byte, ptr jsontext.Pointer) ([]byte, error) {
+	dec := jsontext.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	enc := jsontext.NewEncoder(&out, jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true))
+	for {
+		kind, length := dec.StackIndex(dec.StackDepth())
+		isName := kind == '{' && length%2 == 0 && dec.PeekKind() == '"'
+		tok, err := dec.ReadToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isName && dec.StackPointer() == ptr {
+			if err := dec.SkipValue(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := enc.WriteToken(tok); err != nil {
+			return nil, err
+		}
+	}
+	return out.Bytes(), nil
+}
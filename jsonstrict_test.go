@@ -0,0 +1,72 @@
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type strictUser struct {
+	Name    string `json:"name"`
+	Address struct {
+		City string `json:"city"`
+	} `json:"address"`
+}
+
+func TestShouldBindJSONStrictListsUnknownFields(t *testing.T) {
+	body := `{"name":"a","role":"admin","address":{"city":"x","zip":"1"},"extra":[1,{"y":2}]}`
+	c, _ := CreateTestContextWithRequest(nil, mustNewRequest(t, body))
+
+	var u strictUser
+	err := c.ShouldBindJSONStrict(&u)
+	var unknownErr *UnknownFieldsError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("expected UnknownFieldsError, got %v", err)
+	}
+	if !slices.Equal(unknownErr.Fields, []string{"/role", "/address/zip", "/extra"}) {
+		t.Fatalf("unexpected fields %v", unknownErr.Fields)
+	}
+
+	c, _ = CreateTestContextWithRequest(nil, mustNewRequest(t, `{"name":"a","address":{"city":"x"}}`))
+	if err := c.ShouldBindJSONStrict(&u); err != nil || u.Address.City != "x" {
+		t.Fatalf("valid payload should bind: %v", err)
+	}
+
+	c, _ = CreateTestContextWithRequest(nil, mustNewRequest(t, `{"name":1}`))
+	if err := c.ShouldBindJSONStrict(&u); err == nil || errors.As(err, &unknownErr) {
+		t.Fatalf("type errors should be reported as binding errors, got %v", err)
+	}
+}
+
+func mustNewRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestStrictJSONRouteMeta(t *testing.T) {
+	engine := New()
+	handler := JSONHandler(func(c *Context, u strictUser) (strictUser, error) { return u, nil })
+	engine.WithMeta(StrictJSON()).POST("/strict", handler)
+	engine.POST("/lenient", handler)
+
+	body := `{"name":"a","role":"admin"}`
+	w := PerformRequest(engine, http.MethodPost, "/strict", strings.NewReader(body), nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unknown fields: /role") {
+		t.Fatalf("strict route should reject unknown fields: %d %s", w.Code, w.Body.String())
+	}
+	if w := PerformRequest(engine, http.MethodPost, "/lenient", strings.NewReader(body), nil); w.Code != http.StatusOK {
+		t.Fatalf("other routes should stay lenient, got %d", w.Code)
+	}
+
+	engine.SetJSONOptions(JSONOptions{RejectUnknownFields: true})
+	if w := PerformRequest(engine, http.MethodPost, "/lenient", strings.NewReader(body), nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("engine option should make all routes strict, got %d", w.Code)
+	}
+}
@@ -0,0 +1,106 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// DefaultSecureJSONPrefix 是 SecureJSON 默认的响应前缀, 使响应无法作为 <script> 执行, 防止 JSON 劫持
+const DefaultSecureJSONPrefix = ")]}'\n"
+
+// SetSecureJSONPrefix 设置 SecureJSON 写在响应体之前的前缀, 为空时使用 DefaultSecureJSONPrefix
+func (engine *Engine) SetSecureJSONPrefix(prefix string) {
+	engine.secureJSONPrefix = prefix
+}
+
+// IndentedJSON 以缩进格式输出 JSON, 便于调试时阅读. 与 JSONBuf 一样先编码到缓冲区, 编码失败时返回 500
+func (c *Context) IndentedJSON(code int, obj any) {
+	c.renderJSONVariant(code, obj, "", nil, jsontext.WithIndent("    "))
+}
+
+// PureJSON 输出不转义 <、>、& 的 JSON, 即使引擎通过 JSONOptions.EscapeHTML 启用了 HTML 转义
+func (c *Context) PureJSON(code int, obj any) {
+	c.renderJSONVariant(code, obj, "", nil, jsontext.EscapeForHTML(false))
+}
+
+// SecureJSON 在 JSON 前加上前缀 (默认为 DefaultSecureJSONPrefix), 客户端需要去掉前缀后再解析
+func (c *Context) SecureJSON(code int, obj any) {
+	prefix := DefaultSecureJSONPrefix
+	if c.engine != nil && c.engine.secureJSONPrefix != "" {
+		prefix = c.engine.secureJSONPrefix
+	}
+	c.renderJSONVariant(code, obj, prefix, nil)
+}
+
+// AsciiJSON 输出只包含 ASCII 字符的 JSON, 非 ASCII 字符转义为 \uXXXX
+func (c *Context) AsciiJSON(code int, obj any) {
+	c.renderJSONVariant(code, obj, "", asciiJSON)
+}
+
+// renderJSONVariant 在 prefix 之后按引擎的 JSON 选项与附加选项编码 obj, transform 不为 nil 时对结果进行转换,
+// 成功后写入状态码与响应体
+func (c *Context) renderJSONVariant(code int, obj any, prefix string, transform func([]byte) []byte, opts ...json.Options) {
+	var buf bytes.Buffer
+	bw := c.budgetBuffer(&buf)
+	defer bw.release()
+	_, err := bw.Write([]byte(prefix))
+	if err == nil {
+		err = c.marshalJSON(bw, obj, opts...)
+	}
+	if err != nil {
+		c.renderError(fmt.Errorf("failed to marshal JSON: %w", err))
+		return
+	}
+	body := buf.Bytes()
+	if transform != nil {
+		body = transform(body)
+	}
+
+	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.Writer.WriteHeader(code)
+	c.writeResponseBody(body, "failed to write JSON response")
+}
+
+// asciiJSON 将 JSON 中的非 ASCII 字符转义为 \uXXXX, 超出基本平面的字符转义为代理对.
+// 合法的 JSON 中非 ASCII 字符只会出现在字符串内, 因此可以逐字节处理
+func asciiJSON(data []byte) []byte {
+	i := 0
+	for i < len(data) && data[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(data) {
+		return data
+	}
+	out := make([]byte, i, len(data)+len(data)/2)
+	copy(out, data[:i])
+	for i < len(data) {
+		if data[i] < utf8.RuneSelf {
+			out = append(out, data[i])
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		i += size
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			out = appendUnicodeEscape(out, r1)
+			r = r2
+		}
+		out = appendUnicodeEscape(out, r)
+	}
+	return out
+}
+
+func appendUnicodeEscape(out []byte, r rune) []byte {
+	const hex = "0123456789abcdef"
+	return append(out, '\\', 'u', hex[r>>12&0xf], hex[r>>8&0xf], hex[r>>4&0xf], hex[r&0xf])
+}
@@ -0,0 +1,49 @@
+package touka
+
+import (
+	"math"
+	"net/http"
+	"testing"
+)
+
+func TestJSONVariants(t *testing.T) {
+	engine := New()
+	engine.SetJSONOptions(JSONOptions{EscapeHTML: true, FieldNaming: SnakeCase})
+	data := struct {
+		UserName string
+		Note     string
+	}{"张三 😀", "<b>&</b>"}
+	engine.GET("/indented", func(c *Context) { c.IndentedJSON(http.StatusOK, H{"a": []int{1}}) })
+	engine.GET("/pure", func(c *Context) { c.PureJSON(http.StatusOK, data) })
+	engine.GET("/secure", func(c *Context) { c.SecureJSON(http.StatusOK, []int{1, 2}) })
+	engine.GET("/ascii", func(c *Context) { c.AsciiJSON(http.StatusOK, data) })
+	engine.GET("/invalid", func(c *Context) { c.IndentedJSON(http.StatusOK, math.Inf(1)) })
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/indented", "{\n    \"a\": [\n        1\n    ]\n}"},
+		{"/pure", `{"user_name":"张三 😀","note":"<b>&</b>"}`},
+		{"/secure", ")]}'\n[1,2]"},
+		{"/ascii", `{"user_name":"\u5f20\u4e09 \ud83d\ude00","note":"\u003cb\u003e\u0026\u003c/b\u003e"}`},
+	}
+	for _, tt := range tests {
+		w := PerformRequest(engine, http.MethodGet, tt.path, nil, nil)
+		if w.Code != http.StatusOK || w.Body.String() != tt.want {
+			t.Errorf("%s: %d\n got %q\nwant %q", tt.path, w.Code, w.Body.String(), tt.want)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("%s: Content-Type %q", tt.path, ct)
+		}
+	}
+
+	if w := PerformRequest(engine, http.MethodGet, "/invalid", nil, nil); w.Code != http.StatusInternalServerError {
+		t.Fatalf("encoding error should return 500, got %d", w.Code)
+	}
+
+	engine.SetSecureJSONPrefix("while(1);")
+	if w := PerformRequest(engine, http.MethodGet, "/secure", nil, nil); w.Body.String() != "while(1);[1,2]" {
+		t.Fatalf("custom prefix not used: %q", w.Body.String())
+	}
+}
@@ -0,0 +1,215 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrLoadShed 表示服务器过载, 请求在排队期限内未获得处理机会
+var ErrLoadShed = errors.New("server overloaded, request shed")
+
+// PriorityClass 是请求的优先级, 过载时低优先级的请求先被拒绝
+type PriorityClass int
+
+const (
+	PriorityLow PriorityClass = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical
+
+	numPriorityClasses = 4
+)
+
+func (p PriorityClass) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// MetaPriority 是声明路由优先级的元数据键
+const MetaPriority = "touka.priority"
+
+// RoutePriority 返回声明路由优先级的元数据, 用于 WithMeta:
+//
+//	r.WithMeta(touka.RoutePriority(touka.PriorityLow)).GET("/reports/export", export)
+func RoutePriority(class PriorityClass) (string, any) {
+	return MetaPriority, class
+}
+
+// LoadShedOptions 配置 LoadShedder
+type LoadShedOptions struct {
+	// MaxConcurrent 为同时处理的请求数上限, 必填
+	MaxConcurrent int
+	// QueueSize 为每个优先级的等待队列长度, 默认等于 MaxConcurrent; 队列已满时直接拒绝
+	QueueSize int
+	// QueueTimeout 覆盖各优先级的最长排队时间. 默认 low 100ms、normal 1s、high 2s、critical 5s;
+	// 设为负数表示该优先级不排队, 满载时直接拒绝
+	QueueTimeout map[PriorityClass]time.Duration
+	// Classify 计算请求的优先级, 默认读取 RoutePriority 元数据, 未声明时为 PriorityNormal
+	Classify func(c *Context) PriorityClass
+}
+
+// PriorityStats 是某个优先级的统计
+type PriorityStats struct {
+	Admitted uint64 // 累计获得处理的请求数 (包括排队后获得的)
+	Queued   uint64 // 累计进入排队的请求数
+	Rejected uint64 // 累计因队列已满或不排队而被拒绝的请求数
+	TimedOut uint64 // 累计排队超时或客户端取消的请求数
+	Waiting  int    // 当前排队数
+}
+
+// LoadShedder 限制并发处理的请求数, 超出时按优先级排队: 空出的处理名额总是先交给最高优先级的等待者,
+// 低优先级的请求排队期限更短, 因此在过载时最先被拒绝
+type LoadShedder struct {
+	max      int
+	queue    int
+	timeouts [numPriorityClasses]time.Duration
+	classify func(c *Context) PriorityClass
+
+	mu       sync.Mutex
+	inFlight int
+	waiters  [numPriorityClasses][]*shedWaiter
+	stats    [numPriorityClasses]PriorityStats
+}
+
+type shedWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// NewLoadShedder 创建 LoadShedder, 通过 Handler 作为中间件使用
+func NewLoadShedder(opts LoadShedOptions) *LoadShedder {
+	if opts.MaxConcurrent <= 0 {
+		panic("touka: load shedder MaxConcurrent must be positive")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = opts.MaxConcurrent
+	}
+	s := &LoadShedder{
+		max:      opts.MaxConcurrent,
+		queue:    opts.QueueSize,
+		timeouts: [numPriorityClasses]time.Duration{100 * time.Millisecond, time.Second, 2 * time.Second, 5 * time.Second},
+		classify: opts.Classify,
+	}
+	for class, d := range opts.QueueTimeout {
+		if class >= 0 && class < numPriorityClasses {
+			s.timeouts[class] = d
+		}
+	}
+	if s.classify == nil {
+		s.classify = routePriority
+	}
+	return s
+}
+
+// routePriority 读取路由上声明的优先级
+func routePriority(c *Context) PriorityClass {
+	if v, ok := c.RouteMetaValue(MetaPriority); ok {
+		if class, ok := v.(PriorityClass); ok {
+			return class
+		}
+	}
+	return PriorityNormal
+}
+
+// Handler 返回执行准入控制的中间件, 拒绝时返回 503 并设置 Retry-After
+func (s *LoadShedder) Handler() HandlerFunc {
+	return func(c *Context) {
+		class := min(max(s.classify(c), PriorityLow), PriorityCritical)
+		if !s.acquire(c, class) {
+			c.SetHeader("Retry-After", "1")
+			c.AddError(ErrLoadShed)
+			c.ErrorUseHandle(http.StatusServiceUnavailable, ErrLoadShed)
+			return
+		}
+		defer s.release()
+		c.Next()
+	}
+}
+
+// Stats 返回各优先级的统计与当前处理中的请求数
+func (s *LoadShedder) Stats() (stats map[PriorityClass]PriorityStats, inFlight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats = make(map[PriorityClass]PriorityStats, numPriorityClasses)
+	for class := range PriorityClass(numPriorityClasses) {
+		st := s.stats[class]
+		st.Waiting = len(s.waiters[class])
+		stats[class] = st
+	}
+	return stats, s.inFlight
+}
+
+func (s *LoadShedder) acquire(c *Context, class PriorityClass) bool {
+	s.mu.Lock()
+	if s.inFlight < s.max {
+		s.inFlight++
+		s.stats[class].Admitted++
+		s.mu.Unlock()
+		return true
+	}
+	timeout := s.timeouts[class]
+	if timeout < 0 || len(s.waiters[class]) >= s.queue {
+		s.stats[class].Rejected++
+		s.mu.Unlock()
+		return false
+	}
+	w := &shedWaiter{ready: make(chan struct{})}
+	s.waiters[class] = append(s.waiters[class], w)
+	s.stats[class].Queued++
+	s.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-w.ready:
+		return true
+	case <-timer.C:
+	case <-c.Context().Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w.granted {
+		// 超时与获得名额同时发生, 以获得名额为准
+		return true
+	}
+	if i := slices.Index(s.waiters[class], w); i >= 0 {
+		s.waiters[class] = slices.Delete(s.waiters[class], i, i+1)
+	}
+	s.stats[class].TimedOut++
+	return false
+}
+
+// release 将空出的名额交给最高优先级中最早的等待者, 没有等待者时归还名额
+func (s *LoadShedder) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for class := PriorityCritical; class >= PriorityLow; class-- {
+		if len(s.waiters[class]) == 0 {
+			continue
+		}
+		w := s.waiters[class][0]
+		s.waiters[class] = s.waiters[class][1:]
+		w.granted = true
+		s.stats[class].Admitted++
+		close(w.ready)
+		return
+	}
+	s.inFlight--
+}
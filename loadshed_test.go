@@ -0,0 +1,112 @@
+package touka
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadShedderPrefersHigherPriority(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedOptions{
+		MaxConcurrent: 1,
+		QueueTimeout:  map[PriorityClass]time.Duration{PriorityLow: 2 * time.Second, PriorityHigh: 2 * time.Second},
+	})
+	engine := New()
+	engine.Use(shedder.Handler())
+
+	hold := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) HandlerFunc {
+		return func(c *Context) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			c.Status(http.StatusOK)
+		}
+	}
+	engine.GET("/hold", func(c *Context) { <-hold })
+	engine.WithMeta(RoutePriority(PriorityLow)).GET("/low", record("low"))
+	engine.WithMeta(RoutePriority(PriorityHigh)).GET("/high", record("high"))
+
+	var wg sync.WaitGroup
+	get := func(path string) {
+		defer wg.Done()
+		PerformRequest(engine, http.MethodGet, path, nil, nil)
+	}
+	waitQueued := func(class PriorityClass, n int) {
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			stats, _ := shedder.Stats()
+			if stats[class].Waiting == n {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s requests were not queued: %+v", class, stats)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	wg.Add(1)
+	go get("/hold")
+	for {
+		if _, inFlight := shedder.Stats(); inFlight == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	wg.Add(1)
+	go get("/low")
+	waitQueued(PriorityLow, 1)
+	wg.Add(1)
+	go get("/high")
+	waitQueued(PriorityHigh, 1)
+
+	close(hold)
+	wg.Wait()
+	if len(order) != 2 || order[0] != "high" || order[1] != "low" {
+		t.Fatalf("expected high priority to run first, got %v", order)
+	}
+	stats, inFlight := shedder.Stats()
+	if inFlight != 0 || stats[PriorityHigh].Admitted != 1 || stats[PriorityLow].Queued != 1 {
+		t.Fatalf("unexpected stats %+v in flight %d", stats, inFlight)
+	}
+}
+
+func TestLoadShedderRejectsWhenQueueTimesOut(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedOptions{
+		MaxConcurrent: 1,
+		QueueTimeout:  map[PriorityClass]time.Duration{PriorityLow: -1, PriorityNormal: 10 * time.Millisecond},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c, _ := CreateTestContextWithRequest(nil, req)
+	if !shedder.acquire(c, PriorityNormal) {
+		t.Fatal("first request should be admitted")
+	}
+
+	engine := New()
+	engine.Use(shedder.Handler())
+	engine.GET("/", func(c *Context) { c.Status(http.StatusOK) })
+	engine.WithMeta(RoutePriority(PriorityLow)).GET("/low", func(c *Context) { c.Status(http.StatusOK) })
+
+	w := PerformRequest(engine, http.MethodGet, "/low", nil, nil)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("low priority should be rejected immediately, got %d", w.Code)
+	}
+	w = PerformRequest(engine, http.MethodGet, "/", nil, nil)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("normal priority should time out in queue, got %d", w.Code)
+	}
+	stats, _ := shedder.Stats()
+	if stats[PriorityLow].Rejected != 1 || stats[PriorityNormal].TimedOut != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	shedder.release()
+	if w := PerformRequest(engine, http.MethodGet, "/", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 after release, got %d", w.Code)
+	}
+}
@@ -0,0 +1,159 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrMemoryBudgetExceeded 表示请求在缓冲请求体、解析表单或缓冲响应时超出了内存预算
+var ErrMemoryBudgetExceeded = errors.New("request memory budget exceeded")
+
+// errBodyOverBudget 是缓冲请求体超出预算时返回的错误, 同时匹配 ErrBodyTooLarge,
+// 因此已有的 413 处理路径 (例如 Idempotency、ValidateJSONSchema) 无需修改
+var errBodyOverBudget = fmt.Errorf("%w: %w", ErrBodyTooLarge, ErrMemoryBudgetExceeded)
+
+// SetMemoryBudget 设置每个请求默认的内存预算 (字节), <= 0 表示不限制 (默认).
+// 预算只统计框架代为缓冲的内容: GetReqBodyFull 等读取的完整请求体、表单解析保留在内存中的字段,
+// 以及 JSONBuf、HTMLBuf 等先缓冲再写出的响应; 处理器自行分配的内存可以通过 c.ReserveMemory 计入.
+// 请求侧超出预算时返回 413, 缓冲响应超出预算时返回 507
+func (engine *Engine) SetMemoryBudget(limit int64) {
+	engine.memoryBudget = max(limit, 0)
+}
+
+// MemoryBudget 返回为后续处理链设置内存预算的中间件, 覆盖引擎的默认值, 例如为上传接口放宽预算:
+//
+//	r.POST("/import", touka.MemoryBudget(64<<20), importHandler)
+func MemoryBudget(limit int64) HandlerFunc {
+	return func(c *Context) {
+		c.memLimit = max(limit, 0)
+		c.Next()
+	}
+}
+
+// ReserveMemory 从请求的内存预算中预留 n 字节, 超出预算时不预留并返回 ErrMemoryBudgetExceeded.
+// 未设置预算时总是成功. 可以在多个 goroutine 中并发调用
+func (c *Context) ReserveMemory(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+	if used := c.memUsed.Add(n); c.memLimit > 0 && used > c.memLimit {
+		c.memUsed.Add(-n)
+		return ErrMemoryBudgetExceeded
+	}
+	return nil
+}
+
+// ReleaseMemory 归还通过 ReserveMemory 成功预留的 n 字节
+func (c *Context) ReleaseMemory(n int64) {
+	if n > 0 {
+		c.memUsed.Add(-n)
+	}
+}
+
+// MemoryUsage 返回请求当前已预留的内存与预算上限, limit 为 0 表示不限制
+func (c *Context) MemoryUsage() (used, limit int64) {
+	return c.memUsed.Load(), c.memLimit
+}
+
+// readAllBody 读取完整的请求体并计入内存预算
+func (c *Context) readAllBody(body io.Reader) ([]byte, error) {
+	if c.memLimit <= 0 {
+		return io.ReadAll(body)
+	}
+	return io.ReadAll(&budgetReader{c: c, r: body})
+}
+
+// budgetReader 在读取时按读到的字节数预留内存, 读取的内容由调用方保留到请求结束
+type budgetReader struct {
+	c *Context
+	r io.Reader
+}
+
+func (r *budgetReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 && r.c.ReserveMemory(int64(n)) != nil {
+		return 0, errBodyOverBudget
+	}
+	return n, err
+}
+
+// formMaxMemory 返回解析 multipart 表单时保留在内存中的上限 (默认 32MB, 可通过 SetMultipartMemory 调整),
+// 不超过剩余预算, 超出部分写入临时文件
+func (c *Context) formMaxMemory() int64 {
+	limit := int64(defaultMemory)
+	if c.engine != nil && c.engine.multipartMemory > 0 {
+		limit = c.engine.multipartMemory
+	}
+	if c.memLimit <= 0 {
+		return limit
+	}
+	return max(min(limit, c.memLimit-c.memUsed.Load()), 0)
+}
+
+// reserveForm 将解析得到的表单字段计入内存预算
+func (c *Context) reserveForm() error {
+	if c.memLimit <= 0 {
+		return nil
+	}
+	var n int64
+	for key, values := range c.Request.PostForm {
+		n += int64(len(key))
+		for _, v := range values {
+			n += int64(len(v))
+		}
+	}
+	if c.ReserveMemory(n) != nil {
+		return errBodyOverBudget
+	}
+	return nil
+}
+
+// bufferLimit 将中间件的响应缓冲上限收紧到剩余预算以内. Digest、PartialResponse 等中间件
+// 超出缓冲上限时会改为直接输出或放弃记录, 因此预算不足时响应照常写出
+func (c *Context) bufferLimit(limit int) int {
+	if c.memLimit <= 0 {
+		return limit
+	}
+	return int(max(min(int64(limit), c.memLimit-c.memUsed.Load()), 0))
+}
+
+// budgetWriter 在写入响应缓冲时预留内存, 由 release 在写出后归还
+type budgetWriter struct {
+	c        *Context
+	w        io.Writer
+	reserved int64
+}
+
+// budgetBuffer 返回计入内存预算的响应缓冲写入器
+func (c *Context) budgetBuffer(w io.Writer) *budgetWriter {
+	return &budgetWriter{c: c, w: w}
+}
+
+func (w *budgetWriter) Write(p []byte) (int, error) {
+	if err := w.c.ReserveMemory(int64(len(p))); err != nil {
+		return 0, err
+	}
+	w.reserved += int64(len(p))
+	return w.w.Write(p)
+}
+
+func (w *budgetWriter) release() {
+	w.c.ReleaseMemory(w.reserved)
+	w.reserved = 0
+}
+
+// renderError 处理缓冲渲染失败: 超出内存预算时返回 507, 其他错误返回 500
+func (c *Context) renderError(err error) {
+	c.AddError(err)
+	code := http.StatusInternalServerError
+	if errors.Is(err, ErrMemoryBudgetExceeded) {
+		code = http.StatusInsufficientStorage
+	}
+	c.ErrorUseHandle(code, err)
+}
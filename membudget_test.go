@@ -0,0 +1,102 @@
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMemoryBudgetRequestBody(t *testing.T) {
+	engine := New()
+	engine.SetMemoryBudget(64)
+	handler := func(c *Context) {
+		data, err := c.GetReqBodyFull()
+		if err != nil {
+			if errors.Is(err, ErrBodyTooLarge) && errors.Is(err, ErrMemoryBudgetExceeded) {
+				c.ErrorUseHandle(http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+		used, limit := c.MemoryUsage()
+		c.String(http.StatusOK, "%d %d/%d", len(data), used, limit)
+	}
+	engine.POST("/small", handler)
+	engine.POST("/large", MemoryBudget(1024), handler)
+
+	if w := PerformRequest(engine, http.MethodPost, "/small", strings.NewReader(strings.Repeat("a", 32)), nil); w.Body.String() != "32 32/64" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if w := PerformRequest(engine, http.MethodPost, "/small", strings.NewReader(strings.Repeat("a", 100)), nil); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 when the body exceeds the budget, got %d", w.Code)
+	}
+	if w := PerformRequest(engine, http.MethodPost, "/large", strings.NewReader(strings.Repeat("a", 100)), nil); w.Code != http.StatusOK {
+		t.Fatalf("expected the route budget to override the default, got %d", w.Code)
+	}
+}
+
+func TestMemoryBudgetForm(t *testing.T) {
+	engine := New()
+	engine.SetMemoryBudget(16)
+	engine.POST("/form", func(c *Context) {
+		var form struct {
+			Name string `form:"name"`
+		}
+		if err := c.ShouldBindForm(&form); err != nil {
+			c.String(http.StatusRequestEntityTooLarge, "%v", errors.Is(err, ErrMemoryBudgetExceeded))
+			return
+		}
+		c.String(http.StatusOK, "%s", form.Name)
+	})
+
+	headers := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+	body := url.Values{"name": {"touka"}}.Encode()
+	if w := PerformRequest(engine, http.MethodPost, "/form", strings.NewReader(body), headers); w.Body.String() != "touka" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	body = url.Values{"name": {strings.Repeat("x", 32)}}.Encode()
+	if w := PerformRequest(engine, http.MethodPost, "/form", strings.NewReader(body), headers); w.Body.String() != "true" {
+		t.Fatalf("expected the form to exceed the budget, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestMemoryBudgetBufferedResponse(t *testing.T) {
+	engine := New()
+	engine.SetMemoryBudget(32)
+	engine.GET("/json", func(c *Context) {
+		c.JSONBuf(http.StatusOK, map[string]string{"data": c.Query("data")})
+		if used, _ := c.MemoryUsage(); used != 0 {
+			t.Errorf("expected the response buffer to be released, got %d", used)
+		}
+	})
+
+	if w := PerformRequest(engine, http.MethodGet, "/json?data=ok", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/json?data="+strings.Repeat("x", 64), nil, nil); w.Code != http.StatusInsufficientStorage {
+		t.Fatalf("expected 507 when the buffered response exceeds the budget, got %d", w.Code)
+	}
+}
+
+func TestReserveMemory(t *testing.T) {
+	c, _ := CreateTestContext(nil)
+	if err := c.ReserveMemory(1 << 30); err != nil {
+		t.Fatalf("reservations must succeed without a budget: %v", err)
+	}
+	c.ReleaseMemory(1 << 30)
+
+	c.memLimit = 100
+	if err := c.ReserveMemory(60); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ReserveMemory(60); !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Fatalf("expected ErrMemoryBudgetExceeded, got %v", err)
+	}
+	c.ReleaseMemory(60)
+	if used, limit := c.MemoryUsage(); used != 0 || limit != 100 {
+		t.Fatalf("unexpected usage %d/%d", used, limit)
+	}
+}
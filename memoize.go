@@ -0,0 +1,177 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrMemoNotRegistered 表示 c.Memoized 使用的键没有通过 engine.Memoize 注册
+var ErrMemoNotRegistered = errors.New("memo not registered")
+
+// memoKeyPrefix 为记忆值在 MemoStore 中的键前缀
+const memoKeyPrefix = "memo:"
+
+// MemoStore 保存 Memoize 计算出的值. 与 CacheStore 不同, 值不经过序列化,
+// 因此可以直接保存解析好的公钥、功能配置等对象
+type MemoStore interface {
+	// Get 返回 key 对应的值, 不存在或已过期时 ok 为 false
+	Get(ctx context.Context, key string) (value any, ok bool, err error)
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+type memoEntry struct {
+	value   any
+	expires time.Time
+}
+
+// MemoryMemoStore 是进程内的 MemoStore 实现
+type MemoryMemoStore struct {
+	mu      sync.RWMutex
+	entries map[string]memoEntry
+}
+
+// NewMemoryMemoStore 创建进程内的 MemoStore
+func NewMemoryMemoStore() *MemoryMemoStore {
+	return &MemoryMemoStore{entries: make(map[string]memoEntry)}
+}
+
+func (s *MemoryMemoStore) Get(_ context.Context, key string) (any, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.entries[key]
+	if !ok || !time.Now().Before(e.expires) {
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+func (s *MemoryMemoStore) Set(_ context.Context, key string, value any, ttl time.Duration) error {
+	s.mu.Lock()
+	s.entries[key] = memoEntry{value: value, expires: time.Now().Add(ttl)}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryMemoStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// SetMemoStore 设置 Memoize 使用的存储, 默认为进程内实现
+func (engine *Engine) SetMemoStore(store MemoStore) {
+	if store == nil {
+		panic("touka: memo store must not be nil")
+	}
+	engine.memoStore = store
+}
+
+// Memo 是通过 engine.Memoize 注册的记忆值
+type Memo struct {
+	engine *Engine
+	key    string
+	ttl    time.Duration
+	fill   func(ctx context.Context) (any, error)
+
+	mu   sync.Mutex
+	call *memoCall
+}
+
+type memoCall struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// Memoize 注册名为 key 的记忆值: 首次读取或过期后调用 fill 计算, 并在存储中保留 ttl.
+// 并发的未命中只会调用一次 fill, 其他请求等待并共享结果; fill 返回错误时不会缓存.
+// 应在启动服务器之前注册, 重复注册同一个 key 时 panic:
+//
+//	r.Memoize("jwks", time.Hour, func(ctx context.Context) (any, error) {
+//	    return fetchJWKS(ctx, jwksURL)
+//	})
+//
+//	r.GET("/me", func(c *touka.Context) {
+//	    keys, err := c.Memoized("jwks")
+//	    ...
+//	})
+func (engine *Engine) Memoize(key string, ttl time.Duration, fill func(ctx context.Context) (any, error)) *Memo {
+	if fill == nil || ttl <= 0 {
+		panic("touka: memoize requires a fill function and a positive ttl")
+	}
+	if _, ok := engine.memos[key]; ok {
+		panic("touka: memo " + key + " already registered")
+	}
+	m := &Memo{engine: engine, key: key, ttl: ttl, fill: fill}
+	if engine.memos == nil {
+		engine.memos = make(map[string]*Memo)
+	}
+	engine.memos[key] = m
+	return m
+}
+
+// Memoized 返回通过 engine.Memoize 注册的记忆值, 需要计算时使用请求的 context
+func (c *Context) Memoized(key string) (any, error) {
+	m, ok := c.engine.memos[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMemoNotRegistered, key)
+	}
+	return m.Get(c.Context())
+}
+
+// Get 返回记忆值, 未命中时计算. ctx 结束时等待中的调用返回 ctx.Err(), 正在进行的计算不受影响
+func (m *Memo) Get(ctx context.Context) (any, error) {
+	store := m.engine.memoStore
+	storeKey := memoKeyPrefix + m.key
+	if v, ok, err := store.Get(ctx, storeKey); err == nil && ok {
+		return v, nil
+	}
+
+	m.mu.Lock()
+	call := m.call
+	if call == nil {
+		call = &memoCall{done: make(chan struct{})}
+		m.call = call
+		// 计算与发起的请求解耦, 避免首个请求被取消时所有等待者一起失败
+		go m.run(context.WithoutCancel(ctx), store, storeKey, call)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (m *Memo) run(ctx context.Context, store MemoStore, storeKey string, call *memoCall) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.value, call.err = nil, fmt.Errorf("memo %s fill panic: %v", m.key, r)
+		}
+		m.mu.Lock()
+		m.call = nil
+		m.mu.Unlock()
+		close(call.done)
+	}()
+	call.value, call.err = m.fill(ctx)
+	if call.err == nil {
+		// 写入失败只影响后续命中, 不影响本次结果
+		_ = store.Set(ctx, storeKey, call.value, m.ttl)
+	}
+}
+
+// Invalidate 使记忆值失效, 下次读取时重新计算
+func (m *Memo) Invalidate(ctx context.Context) error {
+	return m.engine.memoStore.Delete(ctx, memoKeyPrefix+m.key)
+}
@@ -0,0 +1,93 @@
+package touka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoizeSingleflight(t *testing.T) {
+	engine := New()
+	var fills atomic.Int32
+	release := make(chan struct{})
+	memo := engine.Memoize("config", time.Minute, func(ctx context.Context) (any, error) {
+		fills.Add(1)
+		<-release
+		return "v1", nil
+	})
+	engine.GET("/config", func(c *Context) {
+		v, err := c.Memoized("config")
+		if err != nil {
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+		c.String(http.StatusOK, "%v", v)
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+			if w.Body.String() != "v1" {
+				t.Errorf("unexpected body %q", w.Body.String())
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := fills.Load(); n != 1 {
+		t.Fatalf("expected a single fill for concurrent misses, got %d", n)
+	}
+
+	if w := PerformRequest(engine, http.MethodGet, "/config", nil, nil); w.Body.String() != "v1" || fills.Load() != 1 {
+		t.Fatalf("expected a cache hit, got %q after %d fills", w.Body.String(), fills.Load())
+	}
+	if err := memo.Invalidate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := memo.Get(context.Background()); err != nil || fills.Load() != 2 {
+		t.Fatalf("expected invalidation to refill, got %v after %d fills", err, fills.Load())
+	}
+}
+
+func TestMemoizeErrors(t *testing.T) {
+	engine := New()
+	var fail atomic.Bool
+	fail.Store(true)
+	memo := engine.Memoize("keys", time.Minute, func(ctx context.Context) (any, error) {
+		if fail.Load() {
+			return nil, errors.New("upstream down")
+		}
+		return 42, nil
+	})
+
+	if _, err := memo.Get(context.Background()); err == nil {
+		t.Fatal("expected the fill error")
+	}
+	fail.Store(false)
+	if v, err := memo.Get(context.Background()); err != nil || v != 42 {
+		t.Fatalf("errors must not be cached, got %v %v", v, err)
+	}
+
+	c, _ := CreateTestContext(nil)
+	c.engine = engine
+	if _, err := c.Memoized("missing"); !errors.Is(err, ErrMemoNotRegistered) {
+		t.Fatalf("expected ErrMemoNotRegistered, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected duplicate registration to panic")
+		}
+	}()
+	engine.Memoize("keys", time.Minute, func(ctx context.Context) (any, error) { return nil, nil })
+}
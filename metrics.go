@@ -0,0 +1,176 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenMetricsContentType 是 OpenMetrics 文本格式的 Content-Type
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// processStartTime 近似为进程启动时间
+var processStartTime = time.Now()
+
+// MetricsHandler 返回以 OpenMetrics 文本格式输出进程、Go 运行时、对象池与连接统计的处理器,
+// 可以直接被 Prometheus 抓取:
+//
+//	r.GET("/metrics", r.MetricsHandler())
+func (engine *Engine) MetricsHandler() HandlerFunc {
+	return func(c *Context) {
+		var buf bytes.Buffer
+		engine.WriteMetrics(&buf)
+		c.SetHeader("Content-Type", OpenMetricsContentType)
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.Write(buf.Bytes())
+	}
+}
+
+// WriteMetrics 以 OpenMetrics 文本格式将运行时指标写入 w, 以 "# EOF" 结尾.
+// 需要与其他指标合并输出时, 可以去掉最后一行后拼接
+func (engine *Engine) WriteMetrics(w io.Writer) error {
+	m := &metricsWriter{w: bufio.NewWriter(w)}
+
+	if rss, ok := processRSS(); ok {
+		m.gauge("process_resident_memory_bytes", "Resident memory size in bytes.", "", float64(rss))
+	}
+	m.gauge("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", "",
+		float64(processStartTime.UnixNano())/1e9)
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	m.gauge("go_goroutines", "Number of goroutines that currently exist.", "", float64(runtime.NumGoroutine()))
+	m.gauge("go_gomaxprocs", "Value of GOMAXPROCS.", "", float64(runtime.GOMAXPROCS(0)))
+	m.gauge("go_memstats_heap_alloc_bytes", "Bytes of allocated heap objects.", "", float64(ms.HeapAlloc))
+	m.gauge("go_memstats_heap_inuse_bytes", "Bytes in in-use heap spans.", "", float64(ms.HeapInuse))
+	m.gauge("go_memstats_sys_bytes", "Bytes of memory obtained from the OS.", "", float64(ms.Sys))
+	m.counter("go_memstats_mallocs", "Cumulative count of heap objects allocated.", "", float64(ms.Mallocs))
+	m.counter("go_gc_cycles", "Number of completed GC cycles.", "", float64(ms.NumGC))
+	m.header("go_gc_pause_seconds", "summary", "Stop-the-world pause durations of completed GC cycles.")
+	m.sample("go_gc_pause_seconds_count", "", float64(ms.NumGC))
+	m.sample("go_gc_pause_seconds_sum", "", float64(ms.PauseTotalNs)/1e9)
+	if ms.NumGC > 0 {
+		m.gauge("go_gc_last_pause_seconds", "Duration of the most recent GC pause.", "",
+			float64(ms.PauseNs[(ms.NumGC+255)%256])/1e9)
+	}
+
+	pools := []struct {
+		name     string
+		counters *poolCounters
+	}{
+		{"context", &engine.ctxPoolCounters},
+		{"ecw", &ecwPoolCounters},
+		{"skipped_nodes", &skippedNodesPoolCounters},
+	}
+	m.header("touka_pool_gets", "counter", "Objects taken from the pool.")
+	for _, p := range pools {
+		m.sample("touka_pool_gets_total", `pool="`+p.name+`"`, float64(p.counters.gets.Load()))
+	}
+	m.header("touka_pool_puts", "counter", "Objects returned to the pool.")
+	for _, p := range pools {
+		m.sample("touka_pool_puts_total", `pool="`+p.name+`"`, float64(p.counters.puts.Load()))
+	}
+	m.header("touka_pool_news", "counter", "Objects allocated because the pool was empty.")
+	for _, p := range pools {
+		m.sample("touka_pool_news_total", `pool="`+p.name+`"`, float64(p.counters.news.Load()))
+	}
+	m.header("touka_pool_trimmed", "counter", "Pooled objects whose oversized buffers were released.")
+	for _, p := range pools {
+		m.sample("touka_pool_trimmed_total", `pool="`+p.name+`"`, float64(p.counters.trimmed.Load()))
+	}
+	m.header("touka_pool_hit_ratio", "gauge", "Fraction of gets served by a pooled object.")
+	for _, p := range pools {
+		m.sample("touka_pool_hit_ratio", `pool="`+p.name+`"`, p.counters.snapshot().HitRatio())
+	}
+
+	m.gauge("touka_requests_in_flight", "Requests currently being handled.", "", float64(engine.requests.inFlight.Load()))
+	m.header("touka_requests", "counter", "Requests received by method, excluding requests filtered by the observability filter.")
+	for i := range engine.requests.byMethod {
+		method := "OTHER"
+		if i < len(statsMethods) {
+			method = statsMethods[i]
+		}
+		m.sample("touka_requests_total", `method="`+method+`"`, float64(engine.requests.byMethod[i].Load()))
+	}
+
+	clientErrs, serverErrs := engine.ErrorCounts()
+	m.header("touka_errors", "counter", "Errors collected on request contexts by class.")
+	m.sample("touka_errors_total", `class="client"`, float64(clientErrs))
+	m.sample("touka_errors_total", `class="server"`, float64(serverErrs))
+
+	conns := engine.ConnStats()
+	m.header("touka_connections", "gauge", "Current server connections by state.")
+	m.sample("touka_connections", `state="active"`, float64(conns.Active))
+	m.sample("touka_connections", `state="idle"`, float64(conns.Idle))
+	m.sample("touka_connections", `state="open"`, float64(conns.Open))
+	m.counter("touka_connections_accepted", "Accepted server connections.", "", float64(conns.Accepted))
+	m.counter("touka_connections_hijacked", "Hijacked server connections.", "", float64(conns.Hijacked))
+
+	fmt.Fprint(m.w, "# EOF\n")
+	if m.err != nil {
+		return m.err
+	}
+	return m.w.Flush()
+}
+
+// metricsWriter 输出 OpenMetrics 文本, 记录第一个写入错误
+type metricsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (m *metricsWriter) header(name, typ, help string) {
+	if m.err != nil {
+		return
+	}
+	_, m.err = fmt.Fprintf(m.w, "# TYPE %s %s\n# HELP %s %s\n", name, typ, name, help)
+}
+
+func (m *metricsWriter) sample(name, labels string, value float64) {
+	if m.err != nil {
+		return
+	}
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	_, m.err = fmt.Fprintf(m.w, "%s %s\n", name, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func (m *metricsWriter) gauge(name, help, labels string, value float64) {
+	m.header(name, "gauge", help)
+	m.sample(name, labels, value)
+}
+
+// counter 输出计数器, 样本名追加 _total 后缀
+func (m *metricsWriter) counter(name, help, labels string, value float64) {
+	m.header(name, "counter", help)
+	m.sample(name+"_total", labels, value)
+}
+
+// processRSS 读取进程的常驻内存大小, 仅在提供 /proc 的系统上可用
+func processRSS() (int64, bool) {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * int64(os.Getpagesize()), true
+}
@@ -0,0 +1,76 @@
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	engine := New()
+	engine.EnableStats()
+	engine.GET("/metrics", engine.MetricsHandler())
+	engine.GET("/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+	for range 3 {
+		PerformRequest(engine, http.MethodGet, "/ping", nil, nil)
+	}
+
+	w := PerformRequest(engine, http.MethodGet, "/metrics", nil, nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != OpenMetricsContentType {
+		t.Fatalf("unexpected metrics response %d %v", w.Code, w.Header())
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE go_goroutines gauge\n",
+		"# TYPE go_gc_cycles counter\n",
+		"go_gc_pause_seconds_sum ",
+		"process_start_time_seconds ",
+		`touka_pool_gets_total{pool="context"} 4` + "\n",
+		`touka_pool_hit_ratio{pool="context"} `,
+		`touka_connections{state="open"} 0` + "\n",
+		`touka_requests_total{method="GET"} 4` + "\n",
+		"touka_requests_in_flight 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+	if !strings.HasSuffix(body, "# EOF\n") {
+		t.Fatal("OpenMetrics output must end with # EOF")
+	}
+	// 每个 TYPE 只能出现一次
+	seen := map[string]bool{}
+	for line := range strings.SplitSeq(body, "\n") {
+		if strings.HasPrefix(line, "# TYPE ") {
+			name := strings.Fields(line)[2]
+			if seen[name] {
+				t.Errorf("duplicate metric family %s", name)
+			}
+			seen[name] = true
+		}
+	}
+}
+
+func TestPoolOptions(t *testing.T) {
+	engine := New()
+	engine.EnableStats()
+	engine.SetPoolOptions(PoolOptions{Prewarm: 2, MaxRetainedSliceCap: 4})
+	engine.GET("/errors", func(c *Context) {
+		for range 8 {
+			c.AddError(http.ErrAbortHandler)
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/ok", func(c *Context) { c.Status(http.StatusNoContent) })
+
+	PerformRequest(engine, http.MethodGet, "/ok", nil, nil)
+	PerformRequest(engine, http.MethodGet, "/errors", nil, nil)
+
+	stats := engine.PoolStats()["context"]
+	if stats.Gets != 2 || stats.Puts != 2 {
+		t.Fatalf("unexpected context pool stats %+v", stats)
+	}
+	if stats.Trimmed != 1 {
+		t.Fatalf("expected the oversized Errors slice to be trimmed once, got %d", stats.Trimmed)
+	}
+}
@@ -26,33 +26,38 @@ func (engine *Engine) UseChainIf(condition bool, factories ...MiddlewareXFunc) H
 	}
 
 	// 返回一个处理器，该处理器负责执行这个子链
-	// 这个实现通过临时替换 Context 的处理器链来注入子链，是健壮的
 	return func(c *Context) {
-		// 将当前的处理链和索引位置保存下来
-		originalHandlers := c.handlers
-		originalIndex := c.index
-
-		// 创建一个新的临时处理链
-		// 它由我们预先创建的 `middlewares` 和一个特殊的“恢复”处理器组成
-		subChain := make(HandlersChain, len(middlewares)+1)
-		copy(subChain, middlewares)
+		runSubChain(c, middlewares)
+	}
+}
 
-		// 在子链的末尾添加“恢复”处理器
-		//    当所有 `middlewares` 都执行完毕并调用了 Next() 后，这个函数会被执行
-		subChain[len(middlewares)] = func(ctx *Context) {
-			// 恢复原始的处理链状态
-			ctx.handlers = originalHandlers
-			ctx.index = originalIndex
-			// 继续执行原始处理链中 `UseChainIf` 之后的下一个处理器
-			ctx.Next()
-		}
+// runSubChain 在当前位置插入执行 middlewares 子链, 子链全部调用 Next 后继续原处理链.
+// 这个实现通过临时替换 Context 的处理器链来注入子链，是健壮的
+func runSubChain(c *Context, middlewares HandlersChain) {
+	// 将当前的处理链和索引位置保存下来
+	originalHandlers := c.handlers
+	originalIndex := c.index
 
-		// 将 Context 的处理器链临时替换新的的子链，并重置索引以从头开始
-		c.handlers = subChain
-		c.index = -1
+	// 创建一个新的临时处理链
+	// 它由我们预先创建的 `middlewares` 和一个特殊的“恢复”处理器组成
+	subChain := make(HandlersChain, len(middlewares)+1)
+	copy(subChain, middlewares)
 
-		c.Next()
+	// 在子链的末尾添加“恢复”处理器
+	//    当所有 `middlewares` 都执行完毕并调用了 Next() 后，这个函数会被执行
+	subChain[len(middlewares)] = func(ctx *Context) {
+		// 恢复原始的处理链状态
+		ctx.handlers = originalHandlers
+		ctx.index = originalIndex
+		// 继续执行原始处理链中子链之后的下一个处理器
+		ctx.Next()
 	}
+
+	// 将 Context 的处理器链临时替换新的的子链，并重置索引以从头开始
+	c.handlers = subChain
+	c.index = -1
+
+	c.Next()
 }
 
 // UseIf 是一个条件中间件包装器
@@ -0,0 +1,169 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"runtime/debug"
+)
+
+// DefaultMmapMinSize 是 MmapDir 默认的内存映射大小下限, 更小的文件按普通方式读取更快
+const DefaultMmapMinSize = 16 << 20
+
+// mmapWriteChunk 为写出映射内存时单次 Write 的大小
+const mmapWriteChunk = 4 << 20
+
+// MmapDir 与 http.Dir 相同, 但不小于 MinSize 的普通文件通过内存映射读取: 文件服务器直接写出映射的内存,
+// 省去逐块 read 的系统调用与复制, 适用于数 GB 的下载. 仅在支持的平台 (linux、darwin 与 BSD) 上映射,
+// 其他平台或映射失败时按普通文件读取.
+//
+// 文件在提供服务期间不应被截断或改写; 截断导致的访问错误会中止当前响应, 不会使进程崩溃:
+//
+//	r.StaticFS("/downloads", touka.MmapDir{Root: "/srv/downloads"})
+type MmapDir struct {
+	Root    string
+	MinSize int64 // 使用内存映射的文件大小下限, 0 表示 DefaultMmapMinSize
+}
+
+// Open 实现 http.FileSystem
+func (d MmapDir) Open(name string) (http.File, error) {
+	f, err := http.Dir(d.Root).Open(name)
+	if err != nil {
+		return nil, err
+	}
+	osFile, ok := f.(*os.File)
+	if !ok {
+		return f, nil
+	}
+	info, err := osFile.Stat()
+	if err != nil || !info.Mode().IsRegular() || info.Size() < cmp.Or(d.MinSize, DefaultMmapMinSize) || int64(int(info.Size())) != info.Size() {
+		return f, nil
+	}
+	data, err := mmapFile(osFile, int(info.Size()))
+	if err != nil {
+		return f, nil
+	}
+	return &mappedFile{f: osFile, data: data}, nil
+}
+
+// SetStaticMmap 为之后注册的 StaticDir 与 StaticFile 启用内存映射, 不小于 minSize 的文件按 MmapDir 的方式提供;
+// minSize 为 0 时关闭. 已注册的路由不受影响
+func (engine *Engine) SetStaticMmap(minSize int64) {
+	engine.staticMmapMinSize = minSize
+}
+
+// staticFileSystem 返回 StaticDir 与 StaticFile 使用的文件系统
+func (engine *Engine) staticFileSystem(root string) http.FileSystem {
+	if engine.staticMmapMinSize > 0 {
+		return MmapDir{Root: root, MinSize: engine.staticMmapMinSize}
+	}
+	return http.Dir(root)
+}
+
+// mappedFile 是通过内存映射读取的 http.File, 目录相关的方法交给底层的 *os.File
+type mappedFile struct {
+	f    *os.File
+	data []byte
+	off  int64
+}
+
+// errMmapFault 表示读取映射内存时发生访问错误, 通常是文件在映射后被截断
+var errMmapFault = errors.New("mmap: fault while reading mapped file")
+
+// guardFault 在 fn 访问映射内存出错时返回 errMmapFault, 而不是使进程崩溃
+func guardFault(fn func() error) (err error) {
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(interface{ Addr() uintptr }); !ok {
+				panic(r)
+			}
+			err = fmt.Errorf("%w: %v", errMmapFault, r)
+		}
+	}()
+	return fn()
+}
+
+func (m *mappedFile) Read(p []byte) (n int, err error) {
+	if m.off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	err = guardFault(func() error {
+		n = copy(p, m.data[m.off:])
+		return nil
+	})
+	m.off += int64(n)
+	return n, err
+}
+
+func (m *mappedFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += m.off
+	case io.SeekEnd:
+		offset += int64(len(m.data))
+	default:
+		return 0, errors.New("mmap: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("mmap: negative position")
+	}
+	m.off = offset
+	return offset, nil
+}
+
+// writeTo 从当前位置起将最多 n 字节的映射内存写入 w, 每次写出 mmapWriteChunk 字节
+func (m *mappedFile) writeTo(w io.Writer, n int64) (written int64, err error) {
+	end := min(m.off+n, int64(len(m.data)))
+	err = guardFault(func() error {
+		for m.off < end {
+			k, err := w.Write(m.data[m.off:min(m.off+mmapWriteChunk, end)])
+			m.off += int64(k)
+			written += int64(k)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return written, err
+}
+
+func (m *mappedFile) Readdir(count int) ([]fs.FileInfo, error) {
+	return m.f.Readdir(count)
+}
+
+func (m *mappedFile) Stat() (fs.FileInfo, error) {
+	return m.f.Stat()
+}
+
+func (m *mappedFile) Close() error {
+	err := munmapFile(m.data)
+	m.data = nil
+	return errors.Join(err, m.f.Close())
+}
+
+// readFromMapped 供文件服务器的 ResponseWriter 实现 io.ReaderFrom: http.ServeContent 以 io.CopyN 发送文件,
+// 来源为 mappedFile 时直接写出映射的内存. 不是 mappedFile 时返回 false
+func readFromMapped(w io.Writer, r io.Reader) (int64, bool, error) {
+	lr, ok := r.(*io.LimitedReader)
+	if !ok {
+		return 0, false, nil
+	}
+	m, ok := lr.R.(*mappedFile)
+	if !ok {
+		return 0, false, nil
+	}
+	n, err := m.writeTo(w, lr.N)
+	lr.N -= n
+	return n, true, err
+}
@@ -0,0 +1,23 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package touka
+
+import (
+	"errors"
+	"os"
+)
+
+const mmapSupported = false
+
+func mmapFile(*os.File, int) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func munmapFile([]byte) error {
+	return nil
+}
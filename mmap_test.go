@@ -0,0 +1,103 @@
+package touka
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeMmapTestFile(t *testing.T, dir, name string, size int) []byte {
+	t.Helper()
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMmapDirOpen(t *testing.T) {
+	dir := t.TempDir()
+	data := writeMmapTestFile(t, dir, "large.bin", 1<<20)
+	writeMmapTestFile(t, dir, "small.bin", 10)
+	fs := MmapDir{Root: dir, MinSize: 1 << 10}
+
+	f, err := fs.Open("/large.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, mapped := f.(*mappedFile); mapped != mmapSupported {
+		t.Fatalf("large file mapped = %t, want %t", mapped, mmapSupported)
+	}
+	if _, err := f.Seek(100, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(f)
+	if err != nil || !bytes.Equal(got, data[100:]) {
+		t.Fatalf("read %d bytes, err %v", len(got), err)
+	}
+
+	for _, name := range []string{"/small.bin", "/"} {
+		f, err := fs.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, mapped := f.(*mappedFile); mapped {
+			t.Errorf("%s should not be mapped", name)
+		}
+		f.Close()
+	}
+	if _, err := fs.Open("/missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing file err = %v", err)
+	}
+}
+
+func TestStaticMmap(t *testing.T) {
+	dir := t.TempDir()
+	data := writeMmapTestFile(t, dir, "video.bin", 3*mmapWriteChunk+123)
+	engine := New()
+	engine.SetStaticMmap(1 << 10)
+	engine.StaticDir("/files", dir)
+	engine.StaticFile("/video", filepath.Join(dir, "video.bin"))
+
+	for _, path := range []string{"/files/video.bin", "/video"} {
+		w := PerformRequest(engine, http.MethodGet, path, nil, nil)
+		if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), data) {
+			t.Fatalf("%s: got %d with %d bytes, want %d bytes", path, w.Code, w.Body.Len(), len(data))
+		}
+	}
+
+	w := PerformRequest(engine, http.MethodGet, "/files/video.bin", nil, http.Header{"Range": {"bytes=1000-1999"}})
+	if w.Code != http.StatusPartialContent || !bytes.Equal(w.Body.Bytes(), data[1000:2000]) {
+		t.Fatalf("range: got %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/files/missing.bin", nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("missing file: got %d", w.Code)
+	}
+}
+
+func TestMmapTruncatedFile(t *testing.T) {
+	if !mmapSupported {
+		t.Skip("mmap is not supported on this platform")
+	}
+	dir := t.TempDir()
+	writeMmapTestFile(t, dir, "data.bin", 1<<20)
+	f, err := MmapDir{Root: dir, MinSize: 1}.Open("/data.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := os.Truncate(filepath.Join(dir, "data.bin"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(f); !errors.Is(err, errMmapFault) {
+		t.Fatalf("reading a truncated file: err = %v, want errMmapFault", err)
+	}
+}
@@ -0,0 +1,325 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// MsgPack 向响应写入 MessagePack 数据, Content-Type 为 application/msgpack.
+// 对象先按 JSON 规则编码 (json 标签与 SetJSONOptions 同样生效), 编码失败时通过 ErrorUseHandle 返回 500
+func (c *Context) MsgPack(code int, obj any) {
+	c.renderBinary(code, obj, msgpackFormat{}, "application/msgpack", "MsgPack")
+}
+
+// ShouldBindMsgPack 将 MessagePack 格式的请求体绑定到对象, 请求体大小受 MaxRequestBodySize 限制,
+// 字段匹配与默认值规则与 ShouldBindJSON 相同
+func (c *Context) ShouldBindMsgPack(obj any) error {
+	return c.shouldBindBinary(obj, msgpackFormat{}, "msgpack")
+}
+
+// msgpackFormat 实现 MessagePack 规范中与 JSON 兼容的子集, 另外支持解码时间戳扩展 (类型 -1)
+type msgpackFormat struct{}
+
+func (msgpackFormat) appendNull(b []byte) []byte { return append(b, 0xc0) }
+
+func (msgpackFormat) appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+func (f msgpackFormat) appendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return f.appendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+}
+
+func (msgpackFormat) appendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+}
+
+func (msgpackFormat) appendFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+func (msgpackFormat) appendString(b []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n <= 31:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func (msgpackFormat) appendArray(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+}
+
+func (msgpackFormat) appendMap(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+}
+
+func (f msgpackFormat) decode(d *binaryReader, enc *jsontext.Encoder, depth int) error {
+	if depth > maxBinaryDepth {
+		return fmt.Errorf("%w: nesting exceeds %d levels", ErrBinaryCodec, maxBinaryDepth)
+	}
+	c, err := d.byte()
+	if err != nil {
+		return err
+	}
+	switch {
+	case c <= 0x7f:
+		return enc.WriteToken(jsontext.Uint(uint64(c)))
+	case c >= 0xe0:
+		return enc.WriteToken(jsontext.Int(int64(int8(c))))
+	case c <= 0x8f:
+		return f.decodeMap(d, enc, uint64(c&0x0f), depth)
+	case c <= 0x9f:
+		return f.decodeArray(d, enc, uint64(c&0x0f), depth)
+	case c <= 0xbf:
+		s, err := d.bytes(uint64(c & 0x1f))
+		if err != nil {
+			return err
+		}
+		return writeBinaryString(enc, s)
+	}
+
+	switch c {
+	case 0xc0:
+		return enc.WriteToken(jsontext.Null)
+	case 0xc2, 0xc3:
+		return enc.WriteToken(jsontext.Bool(c == 0xc3))
+	case 0xc4, 0xc5, 0xc6, 0xd9, 0xda, 0xdb:
+		n, err := d.uint(msgpackSize(c))
+		if err != nil {
+			return err
+		}
+		s, err := d.bytes(n)
+		if err != nil {
+			return err
+		}
+		if c >= 0xd9 {
+			return writeBinaryString(enc, s)
+		}
+		return writeBinaryBytes(enc, s)
+	case 0xca:
+		v, err := d.uint(4)
+		if err != nil {
+			return err
+		}
+		return writeBinaryFloat(enc, float64(math.Float32frombits(uint32(v))))
+	case 0xcb:
+		v, err := d.uint(8)
+		if err != nil {
+			return err
+		}
+		return writeBinaryFloat(enc, math.Float64frombits(v))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.uint(msgpackSize(c))
+		if err != nil {
+			return err
+		}
+		return enc.WriteToken(jsontext.Uint(v))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		v, err := d.uint(msgpackSize(c))
+		if err != nil {
+			return err
+		}
+		return enc.WriteToken(jsontext.Int(msgpackSignExtend(v, msgpackSize(c))))
+	case 0xdc, 0xdd:
+		n, err := d.uint(msgpackSize(c))
+		if err != nil {
+			return err
+		}
+		return f.decodeArray(d, enc, n, depth)
+	case 0xde, 0xdf:
+		n, err := d.uint(msgpackSize(c))
+		if err != nil {
+			return err
+		}
+		return f.decodeMap(d, enc, n, depth)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8, 0xc7, 0xc8, 0xc9:
+		return f.decodeExt(d, enc, c)
+	}
+	return fmt.Errorf("%w: unknown msgpack type 0x%02x", ErrBinaryCodec, c)
+}
+
+// msgpackSize 返回定长类型或长度前缀的字节数
+func msgpackSize(c byte) int {
+	switch c {
+	case 0xc4, 0xc7, 0xcc, 0xd0, 0xd9:
+		return 1
+	case 0xc5, 0xc8, 0xcd, 0xd1, 0xda, 0xdc, 0xde:
+		return 2
+	case 0xc6, 0xc9, 0xce, 0xd2, 0xdb, 0xdd, 0xdf:
+		return 4
+	}
+	return 8
+}
+
+func msgpackSignExtend(v uint64, size int) int64 {
+	switch size {
+	case 1:
+		return int64(int8(v))
+	case 2:
+		return int64(int16(v))
+	case 4:
+		return int64(int32(v))
+	}
+	return int64(v)
+}
+
+func (f msgpackFormat) decodeArray(d *binaryReader, enc *jsontext.Encoder, n uint64, depth int) error {
+	count, err := d.count(n, 1)
+	if err != nil {
+		return err
+	}
+	if err := enc.WriteToken(jsontext.BeginArray); err != nil {
+		return err
+	}
+	for range count {
+		if err := f.decode(d, enc, depth+1); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(jsontext.EndArray)
+}
+
+func (f msgpackFormat) decodeMap(d *binaryReader, enc *jsontext.Encoder, n uint64, depth int) error {
+	count, err := d.count(n, 2)
+	if err != nil {
+		return err
+	}
+	if err := enc.WriteToken(jsontext.BeginObject); err != nil {
+		return err
+	}
+	for range count {
+		key, err := f.decodeKey(d)
+		if err != nil {
+			return err
+		}
+		if err := writeBinaryKey(enc, key); err != nil {
+			return err
+		}
+		if err := f.decode(d, enc, depth+1); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(jsontext.EndObject)
+}
+
+// decodeKey 读取 map 的键, 只接受字符串与整数
+func (msgpackFormat) decodeKey(d *binaryReader) (any, error) {
+	c, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return uint64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0xa0 && c <= 0xbf:
+		s, err := d.bytes(uint64(c & 0x1f))
+		return string(s), err
+	}
+	switch c {
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(msgpackSize(c))
+		if err != nil {
+			return nil, err
+		}
+		s, err := d.bytes(n)
+		return string(s), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(msgpackSize(c))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		v, err := d.uint(msgpackSize(c))
+		return msgpackSignExtend(v, msgpackSize(c)), err
+	}
+	return nil, fmt.Errorf("%w: map keys must be strings or integers", ErrBinaryCodec)
+}
+
+// decodeExt 解码扩展类型, 只支持时间戳扩展
+func (msgpackFormat) decodeExt(d *binaryReader, enc *jsontext.Encoder, c byte) error {
+	var n uint64
+	switch c {
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		n = 1 << (c - 0xd4)
+	default:
+		var err error
+		if n, err = d.uint(msgpackSize(c)); err != nil {
+			return err
+		}
+	}
+	typ, err := d.byte()
+	if err != nil {
+		return err
+	}
+	data, err := d.bytes(n)
+	if err != nil {
+		return err
+	}
+	if int8(typ) != -1 {
+		return fmt.Errorf("%w: unsupported msgpack extension type %d", ErrBinaryCodec, int8(typ))
+	}
+	switch len(data) {
+	case 4:
+		return writeBinaryTime(enc, time.Unix(int64(binary.BigEndian.Uint32(data)), 0))
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return writeBinaryTime(enc, time.Unix(int64(v&0x3ffffffff), int64(v>>34)))
+	case 12:
+		nsec := binary.BigEndian.Uint32(data)
+		sec := int64(binary.BigEndian.Uint64(data[4:]))
+		return writeBinaryTime(enc, time.Unix(sec, int64(nsec)))
+	}
+	return fmt.Errorf("%w: invalid msgpack timestamp length %d", ErrBinaryCodec, len(data))
+}
@@ -0,0 +1,101 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// NDJSONContentType 是换行分隔 JSON 的 Content-Type
+const NDJSONContentType = "application/x-ndjson"
+
+var (
+	// ErrNDJSONRecordTooLarge 表示单条记录超出 NDJSONOptions.MaxRecordSize
+	ErrNDJSONRecordTooLarge = errors.New("ndjson: record too large")
+	// ErrNDJSONTooManyRecords 表示记录数超出 NDJSONOptions.MaxRecords
+	ErrNDJSONTooManyRecords = errors.New("ndjson: too many records")
+)
+
+// defaultNDJSONMaxRecordSize 为单条记录的默认大小上限
+const defaultNDJSONMaxRecordSize = 1 << 20
+
+// NDJSONOptions 配置 BindNDJSON
+type NDJSONOptions struct {
+	// MaxRecordSize 为单条记录 (一行) 的字节数上限, 默认 1MiB
+	MaxRecordSize int
+	// MaxRecords 为记录数上限, 0 表示不限制
+	MaxRecords int
+}
+
+// NDJSONError 描述解码某条记录时的错误
+type NDJSONError struct {
+	Line int // 出错记录所在的行号, 从 1 开始
+	Err  error
+}
+
+func (e *NDJSONError) Error() string {
+	return fmt.Sprintf("ndjson line %d: %v", e.Line, e.Err)
+}
+
+func (e *NDJSONError) Unwrap() error {
+	return e.Err
+}
+
+// BindNDJSON 逐条解码换行分隔的 JSON (NDJSON / JSON Lines) 请求体, 每解码一条记录调用一次 fn,
+// 请求体不会被整体缓冲, 适用于批量导入. 空行会被跳过, 每条记录按引擎的 JSON 选项解码并应用默认值.
+//
+// 返回成功交给 fn 处理的记录数. fn 返回错误、记录无法解码或超出限制时立即停止, 解码错误包装为 *NDJSONError.
+// 请求体仍受 MaxRequestBodySize 限制:
+//
+//	n, err := touka.BindNDJSON(c, touka.NDJSONOptions{MaxRecords: 100000}, func(u User) error {
+//	    return batch.Add(u)
+//	})
+func BindNDJSON[T any](c *Context, opts NDJSONOptions, fn func(item T) error) (int, error) {
+	if opts.MaxRecordSize <= 0 {
+		opts.MaxRecordSize = defaultNDJSONMaxRecordSize
+	}
+	body := c.bindRequestBody()
+	if body == nil {
+		return 0, errors.New("request body is empty")
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, min(opts.MaxRecordSize, 64*1024)), opts.MaxRecordSize)
+	count, line := 0, 0
+	for scanner.Scan() {
+		line++
+		record := bytes.TrimSpace(scanner.Bytes())
+		if len(record) == 0 {
+			continue
+		}
+		if err := c.Context().Err(); err != nil {
+			return count, err
+		}
+		if opts.MaxRecords > 0 && count >= opts.MaxRecords {
+			return count, ErrNDJSONTooManyRecords
+		}
+		var item T
+		if err := applyDefaults(&item); err != nil {
+			return count, err
+		}
+		if err := c.unmarshalJSON(bytes.NewReader(record), &item); err != nil {
+			return count, &NDJSONError{Line: line, Err: err}
+		}
+		if err := fn(item); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return count, &NDJSONError{Line: line + 1, Err: ErrNDJSONRecordTooLarge}
+		}
+		return count, err
+	}
+	return count, nil
+}
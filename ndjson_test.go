@@ -0,0 +1,62 @@
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type ndjsonItem struct {
+	Name string `json:"name"`
+	Qty  int    `json:"qty" default:"1"`
+}
+
+func TestBindNDJSON(t *testing.T) {
+	engine := New()
+	var items []ndjsonItem
+	engine.POST("/import", func(c *Context) {
+		n, err := BindNDJSON(c, NDJSONOptions{}, func(item ndjsonItem) error {
+			items = append(items, item)
+			return nil
+		})
+		if err != nil {
+			c.ErrorUseHandle(http.StatusBadRequest, err)
+			return
+		}
+		c.String(http.StatusOK, "%d", n)
+	})
+
+	body := "{\"name\":\"a\",\"qty\":2}\n\n{\"name\":\"b\"}\r\n"
+	w := PerformRequest(engine, http.MethodPost, "/import", strings.NewReader(body), nil)
+	if w.Code != http.StatusOK || w.Body.String() != "2" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if len(items) != 2 || items[0].Qty != 2 || items[1].Name != "b" || items[1].Qty != 1 {
+		t.Fatalf("unexpected items %+v", items)
+	}
+}
+
+func TestBindNDJSONLimits(t *testing.T) {
+	bind := func(body string, opts NDJSONOptions) (int, error) {
+		c, _ := CreateTestContext(nil)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		return BindNDJSON(c, opts, func(item ndjsonItem) error { return nil })
+	}
+
+	n, err := bind("{\"name\":\"a\"}\n{\"name\":\"b\"}\n{\"name\":\"c\"}\n", NDJSONOptions{MaxRecords: 2})
+	if n != 2 || !errors.Is(err, ErrNDJSONTooManyRecords) {
+		t.Fatalf("expected record limit after 2 records, got %d %v", n, err)
+	}
+
+	n, err = bind("{\"name\":\"a\"}\n{\"name\":\""+strings.Repeat("x", 64)+"\"}\n", NDJSONOptions{MaxRecordSize: 32})
+	var lineErr *NDJSONError
+	if n != 1 || !errors.Is(err, ErrNDJSONRecordTooLarge) || !errors.As(err, &lineErr) || lineErr.Line != 2 {
+		t.Fatalf("expected oversized record on line 2, got %d %v", n, err)
+	}
+
+	n, err = bind("{\"name\":\"a\"}\nnot json\n", NDJSONOptions{})
+	if n != 1 || !errors.As(err, &lineErr) || lineErr.Line != 2 {
+		t.Fatalf("expected decode error on line 2, got %d %v", n, err)
+	}
+}
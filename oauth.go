@@ -0,0 +1,705 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"cmp"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+var (
+	// ErrOAuthState 表示回调的 state 与登录时保存的不一致, 或登录流程已过期
+	ErrOAuthState = errors.New("oauth state mismatch")
+	// ErrOAuthDenied 表示用户拒绝授权或提供方返回了错误
+	ErrOAuthDenied = errors.New("oauth authorization denied")
+	// ErrNotLoggedIn 表示请求没有有效的登录会话
+	ErrNotLoggedIn = errors.New("not logged in")
+	// ErrOAuthCookieTooLarge 表示加密后的会话超过浏览器的 cookie 大小限制, 通常是 StoreTokens 保存的令牌过长
+	ErrOAuthCookieTooLarge = errors.New("oauth session cookie too large")
+)
+
+// maxOAuthCookieSize 为 cookie 名称与值的长度上限, 浏览器通常丢弃超过 4096 字节的 cookie 而不报错
+const maxOAuthCookieSize = 4000
+
+// oauthHTTPClient 是未设置 HTTPClient 时访问提供方端点的客户端, http.DefaultClient 没有超时,
+// 提供方无响应时会一直占用登录请求
+var oauthHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// currentUserKey 是当前用户在 Context.Keys 中的键
+const currentUserKey = "touka.user"
+
+// oauthFlowTTL 为登录流程 (从跳转到提供方到回调) 的有效期
+const oauthFlowTTL = 10 * time.Minute
+
+// User 是登录用户的基本信息
+type User struct {
+	ID       string         `json:"id"`
+	Provider string         `json:"provider"`
+	Email    string         `json:"email,omitempty"`
+	Name     string         `json:"name,omitempty"`
+	Picture  string         `json:"picture,omitempty"`
+	Claims   map[string]any `json:"claims,omitempty"` // 提供方返回的原始信息, 默认不保存到会话中
+}
+
+// OAuthToken 是从令牌端点获得的令牌
+type OAuthToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	IDToken      string    `json:"id_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"`
+}
+
+// Expired 报告访问令牌是否已过期 (预留 30 秒余量), 没有过期时间的令牌视为不过期
+func (t *OAuthToken) Expired() bool {
+	return !t.Expiry.IsZero() && time.Now().Add(30*time.Second).After(t.Expiry)
+}
+
+// OAuthProvider 描述一个 OAuth2 / OIDC 身份提供方
+type OAuthProvider struct {
+	Name        string
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	// Issuer 非空时按 OIDC 处理: 请求携带 nonce, 并校验 id_token 的 iss、aud 与 nonce
+	Issuer string
+	Scopes []string
+	// UserFromInfo 将 userinfo 响应 (或 id_token 的声明) 转换为 User, 为 nil 时按 OIDC 标准声明解析
+	UserFromInfo func(info map[string]any) *User
+}
+
+// GoogleProvider 返回 Google 的 OIDC 配置
+func GoogleProvider() OAuthProvider {
+	return OAuthProvider{
+		Name:        "google",
+		AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:    "https://oauth2.googleapis.com/token",
+		UserInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+		Issuer:      "https://accounts.google.com",
+		Scopes:      []string{"openid", "email", "profile"},
+	}
+}
+
+// GitHubProvider 返回 GitHub 的 OAuth2 配置 (GitHub 不支持 OIDC 登录)
+func GitHubProvider() OAuthProvider {
+	return OAuthProvider{
+		Name:        "github",
+		AuthURL:     "https://github.com/login/oauth/authorize",
+		TokenURL:    "https://github.com/login/oauth/access_token",
+		UserInfoURL: "https://api.github.com/user",
+		Scopes:      []string{"read:user", "user:email"},
+		UserFromInfo: func(info map[string]any) *User {
+			return &User{
+				ID:      claimString(info, "id"),
+				Email:   claimString(info, "email"),
+				Name:    cmp.Or(claimString(info, "name"), claimString(info, "login")),
+				Picture: claimString(info, "avatar_url"),
+			}
+		},
+	}
+}
+
+// DiscoverOIDC 通过 <issuer>/.well-known/openid-configuration 获取提供方配置, 适用于 Keycloak、Auth0、Azure AD 等.
+// 请求使用超时为 10 秒的客户端, 需要更短的期限时通过 ctx 控制
+func DiscoverOIDC(ctx context.Context, name, issuer string) (OAuthProvider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return OAuthProvider{}, err
+	}
+	resp, err := oauthHTTPClient.Do(req)
+	if err != nil {
+		return OAuthProvider{}, fmt.Errorf("oidc discovery: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return OAuthProvider{}, fmt.Errorf("oidc discovery: unexpected status %d", resp.StatusCode)
+	}
+	var doc struct {
+		Issuer      string `json:"issuer"`
+		AuthURL     string `json:"authorization_endpoint"`
+		TokenURL    string `json:"token_endpoint"`
+		UserInfoURL string `json:"userinfo_endpoint"`
+	}
+	if err := json.UnmarshalRead(io.LimitReader(resp.Body, 1<<20), &doc); err != nil {
+		return OAuthProvider{}, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if doc.Issuer != issuer || doc.AuthURL == "" || doc.TokenURL == "" {
+		return OAuthProvider{}, fmt.Errorf("oidc discovery: invalid configuration for issuer %q", issuer)
+	}
+	return OAuthProvider{
+		Name:        name,
+		AuthURL:     doc.AuthURL,
+		TokenURL:    doc.TokenURL,
+		UserInfoURL: doc.UserInfoURL,
+		Issuer:      doc.Issuer,
+		Scopes:      []string{"openid", "email", "profile"},
+	}, nil
+}
+
+// OAuthOptions 配置 OAuth 登录
+type OAuthOptions struct {
+	Provider     OAuthProvider
+	ClientID     string
+	ClientSecret string
+	// RedirectURL 为回调地址, 可以是站内路径 (通过 c.AbsoluteURL 转换) 或绝对地址, 必须与提供方登记的一致
+	RedirectURL string
+	// Secret 用于加密登录流程与会话 cookie, 至少 32 字节
+	Secret []byte
+	// CookieName 为会话 cookie 名称, 默认 touka_session
+	CookieName string
+	// SessionTTL 为会话有效期, 默认 24 小时
+	SessionTTL time.Duration
+	// StoreTokens 为 true 时将令牌保存在会话中, Session 中间件会在访问令牌过期时用刷新令牌自动续期
+	StoreTokens bool
+	// AfterLogin 为登录后默认跳转的地址, 默认 /; 登录链接的 ?next= 参数 (仅限站内路径) 优先
+	AfterLogin string
+	// OnLogin 在换取令牌并获得用户信息后调用, 可以修改 user (例如映射为本地用户 ID) 或返回错误拒绝登录
+	OnLogin func(c *Context, user *User, token *OAuthToken) error
+	// HTTPClient 用于访问令牌与 userinfo 端点, 默认使用超时为 10 秒的客户端
+	HTTPClient *http.Client
+}
+
+// OAuth 实现授权码 + PKCE 登录流程, 并以加密 cookie 保存会话
+type OAuth struct {
+	opts OAuthOptions
+	aead cipher.AEAD
+}
+
+// oauthFlow 是登录流程中保存在 cookie 里的状态
+type oauthFlow struct {
+	State    string    `json:"s"`
+	Verifier string    `json:"v"`
+	Nonce    string    `json:"n,omitempty"`
+	Next     string    `json:"r,omitempty"`
+	Expires  time.Time `json:"e"`
+}
+
+// oauthSession 是会话 cookie 的内容
+type oauthSession struct {
+	User    *User       `json:"u"`
+	Token   *OAuthToken `json:"t,omitempty"`
+	Expires time.Time   `json:"e"`
+}
+
+// NewOAuth 创建 OAuth 登录, 配置缺失时 panic:
+//
+//	auth := touka.NewOAuth(touka.OAuthOptions{
+//	    Provider:     touka.GoogleProvider(),
+//	    ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+//	    ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+//	    RedirectURL:  "/auth/callback",
+//	    Secret:       sessionKey,
+//	})
+//	r.Use(auth.Session())
+//	r.GET("/auth/login", auth.LoginHandler())
+//	r.GET("/auth/callback", auth.CallbackHandler())
+//	r.POST("/auth/logout", auth.LogoutHandler())
+//	r.GET("/dashboard", auth.RequireLogin(), dashboard)
+func NewOAuth(opts OAuthOptions) *OAuth {
+	p := opts.Provider
+	if p.AuthURL == "" || p.TokenURL == "" || opts.ClientID == "" || opts.RedirectURL == "" {
+		panic("touka: oauth requires provider endpoints, ClientID and RedirectURL")
+	}
+	if p.UserInfoURL == "" && p.Issuer == "" {
+		panic("touka: oauth provider requires UserInfoURL or an OIDC Issuer")
+	}
+	if len(opts.Secret) < 32 {
+		panic("touka: oauth Secret must be at least 32 bytes")
+	}
+	if opts.CookieName == "" {
+		opts.CookieName = "touka_session"
+	}
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = 24 * time.Hour
+	}
+	if opts.AfterLogin == "" {
+		opts.AfterLogin = "/"
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = oauthHTTPClient
+	}
+	key := sha256.Sum256(opts.Secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic("touka: oauth cipher: " + err.Error())
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic("touka: oauth cipher: " + err.Error())
+	}
+	return &OAuth{opts: opts, aead: aead}
+}
+
+// LoginHandler 返回发起登录的处理器: 生成 state、PKCE 校验码与 nonce, 保存在加密 cookie 中并跳转到提供方
+func (a *OAuth) LoginHandler() HandlerFunc {
+	return func(c *Context) {
+		flow := oauthFlow{
+			State:    randomToken(),
+			Verifier: randomToken(),
+			Next:     safeRedirectPath(c.Query("next")),
+			Expires:  time.Now().Add(oauthFlowTTL),
+		}
+		challenge := sha256.Sum256([]byte(flow.Verifier))
+		q := url.Values{
+			"response_type":         {"code"},
+			"client_id":             {a.opts.ClientID},
+			"redirect_uri":          {a.redirectURL(c)},
+			"state":                 {flow.State},
+			"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+			"code_challenge_method": {"S256"},
+		}
+		if len(a.opts.Provider.Scopes) > 0 {
+			q.Set("scope", strings.Join(a.opts.Provider.Scopes, " "))
+		}
+		if a.opts.Provider.Issuer != "" {
+			flow.Nonce = randomToken()
+			q.Set("nonce", flow.Nonce)
+		}
+		if err := a.writeCookie(c, a.flowCookieName(), flow, int(oauthFlowTTL.Seconds())); err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+		sep := "?"
+		if strings.Contains(a.opts.Provider.AuthURL, "?") {
+			sep = "&"
+		}
+		c.Redirect(http.StatusFound, a.opts.Provider.AuthURL+sep+q.Encode())
+	}
+}
+
+// CallbackHandler 返回处理提供方回调的处理器: 校验 state, 用授权码与 PKCE 校验码换取令牌,
+// 获取用户信息, 建立会话后跳转. state 不匹配或用户拒绝授权时返回 400 / 403
+func (a *OAuth) CallbackHandler() HandlerFunc {
+	return func(c *Context) {
+		var flow oauthFlow
+		ok := a.readCookie(c, a.flowCookieName(), &flow)
+		a.clearCookie(c, a.flowCookieName())
+		state := c.Query("state")
+		if !ok || time.Now().After(flow.Expires) || state == "" ||
+			subtle.ConstantTimeCompare([]byte(state), []byte(flow.State)) != 1 {
+			c.AddClientError(ErrOAuthState)
+			c.ErrorUseHandle(http.StatusBadRequest, ErrOAuthState)
+			return
+		}
+		if e := c.Query("error"); e != "" {
+			err := fmt.Errorf("%w: %s", ErrOAuthDenied, e)
+			c.AddClientError(err)
+			c.ErrorUseHandle(http.StatusForbidden, err)
+			return
+		}
+		code := c.Query("code")
+		if code == "" {
+			err := errors.New("oauth callback missing code")
+			c.AddClientError(err)
+			c.ErrorUseHandle(http.StatusBadRequest, err)
+			return
+		}
+
+		token, err := a.exchange(c.Context(), url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"redirect_uri":  {a.redirectURL(c)},
+			"code_verifier": {flow.Verifier},
+		})
+		if err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusBadGateway, err)
+			return
+		}
+		user, err := a.fetchUser(c.Context(), token, flow.Nonce)
+		if err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusBadGateway, err)
+			return
+		}
+		if a.opts.OnLogin != nil {
+			if err := a.opts.OnLogin(c, user, token); err != nil {
+				c.AddClientError(err)
+				c.ErrorUseHandle(http.StatusForbidden, err)
+				return
+			}
+		}
+		if err := a.Login(c, user, token); err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+		c.Redirect(http.StatusFound, cmp.Or(flow.Next, a.opts.AfterLogin))
+	}
+}
+
+// LogoutHandler 返回清除会话并跳转到 ?next= (仅限站内路径) 或 / 的处理器
+func (a *OAuth) LogoutHandler() HandlerFunc {
+	return func(c *Context) {
+		a.Logout(c)
+		c.Redirect(http.StatusSeeOther, cmp.Or(safeRedirectPath(c.Query("next")), "/"))
+	}
+}
+
+// Login 为 user 建立会话, 可用于自定义的登录流程. token 仅在设置了 StoreTokens 时保存
+func (a *OAuth) Login(c *Context, user *User, token *OAuthToken) error {
+	session := oauthSession{User: &User{
+		ID: user.ID, Provider: user.Provider, Email: user.Email, Name: user.Name, Picture: user.Picture,
+	}, Expires: time.Now().Add(a.opts.SessionTTL)}
+	if a.opts.StoreTokens {
+		session.Token = token
+	}
+	if err := a.writeCookie(c, a.opts.CookieName, session, int(a.opts.SessionTTL.Seconds())); err != nil {
+		return err
+	}
+	c.Set(currentUserKey, user)
+	return nil
+}
+
+// Logout 清除会话
+func (a *OAuth) Logout(c *Context) {
+	a.clearCookie(c, a.opts.CookieName)
+	c.Set(currentUserKey, (*User)(nil))
+}
+
+// Session 返回读取会话的中间件, 之后的处理器可以通过 c.CurrentUser() 获取当前用户.
+// 设置了 StoreTokens 时, 访问令牌过期会用刷新令牌续期并更新会话; 续期失败时会话中的令牌被清除, 用户仍保持登录
+func (a *OAuth) Session() HandlerFunc {
+	return func(c *Context) {
+		var session oauthSession
+		if a.readCookie(c, a.opts.CookieName, &session) && session.User != nil && time.Now().Before(session.Expires) {
+			if session.Token != nil && session.Token.Expired() && session.Token.RefreshToken != "" {
+				token, err := a.Refresh(c.Context(), session.Token.RefreshToken)
+				if err != nil {
+					c.AddError(fmt.Errorf("oauth refresh: %w", err))
+					session.Token = nil
+				} else {
+					session.Token = token
+				}
+				if err := a.writeCookie(c, a.opts.CookieName, session, int(time.Until(session.Expires).Seconds())); err != nil {
+					c.AddError(err)
+				}
+			}
+			c.Set(currentUserKey, session.User)
+			if session.Token != nil {
+				c.Set(oauthTokenKey, session.Token)
+			}
+		}
+		c.Next()
+	}
+}
+
+// oauthTokenKey 是当前会话令牌在 Context.Keys 中的键
+const oauthTokenKey = "touka.oauth_token"
+
+// RequireLogin 返回要求登录的中间件 (需在 Session 之后): 未登录的页面请求跳转到 loginPath (默认 /auth/login)
+// 并通过 ?next= 带上当前地址, 其他请求返回 401
+func (a *OAuth) RequireLogin(loginPath ...string) HandlerFunc {
+	login := "/auth/login"
+	if len(loginPath) > 0 && loginPath[0] != "" {
+		login = loginPath[0]
+	}
+	return func(c *Context) {
+		if c.CurrentUser() != nil {
+			c.Next()
+			return
+		}
+		if c.Request.Method == http.MethodGet && strings.Contains(c.Request.Header.Get("Accept"), "text/html") {
+			c.Redirect(http.StatusFound, login+"?"+url.Values{"next": {c.Request.URL.RequestURI()}}.Encode())
+			c.Abort()
+			return
+		}
+		c.AddClientError(ErrNotLoggedIn)
+		c.ErrorUseHandle(http.StatusUnauthorized, ErrNotLoggedIn)
+	}
+}
+
+// Refresh 用刷新令牌换取新的令牌. 提供方没有返回新的刷新令牌时沿用原来的
+func (a *OAuth) Refresh(ctx context.Context, refreshToken string) (*OAuthToken, error) {
+	token, err := a.exchange(ctx, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// CurrentUser 返回 OAuth 会话中的当前用户, 未登录时返回 nil
+func (c *Context) CurrentUser() *User {
+	if v, ok := c.Get(currentUserKey); ok {
+		user, _ := v.(*User)
+		return user
+	}
+	return nil
+}
+
+// OAuthToken 返回会话中保存的令牌, 仅在设置了 StoreTokens 时可用
+func (c *Context) OAuthToken() *OAuthToken {
+	if v, ok := c.Get(oauthTokenKey); ok {
+		token, _ := v.(*OAuthToken)
+		return token
+	}
+	return nil
+}
+
+// exchange 向令牌端点提交 params 并解析响应
+func (a *OAuth) exchange(ctx context.Context, params url.Values) (*OAuthToken, error) {
+	params.Set("client_id", a.opts.ClientID)
+	if a.opts.ClientSecret != "" {
+		params.Set("client_secret", a.opts.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.opts.Provider.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := a.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		IDToken      string `json:"id_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		Error        string `json:"error"`
+		Description  string `json:"error_description"`
+	}
+	if err := json.UnmarshalRead(io.LimitReader(resp.Body, 1<<20), &body); err != nil {
+		return nil, fmt.Errorf("oauth token response (status %d): %w", resp.StatusCode, err)
+	}
+	if body.Error != "" || resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return nil, fmt.Errorf("oauth token request failed (status %d): %s %s", resp.StatusCode, body.Error, body.Description)
+	}
+	token := &OAuthToken{
+		AccessToken:  body.AccessToken,
+		RefreshToken: body.RefreshToken,
+		TokenType:    body.TokenType,
+		IDToken:      body.IDToken,
+	}
+	if body.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// fetchUser 获取用户信息. OIDC 提供方的 id_token 直接来自令牌端点的 TLS 响应,
+// 按 OIDC Core 3.1.3.7 只校验 iss、aud、nonce 与有效期
+func (a *OAuth) fetchUser(ctx context.Context, token *OAuthToken, nonce string) (*User, error) {
+	p := a.opts.Provider
+	var info map[string]any
+	if p.Issuer != "" {
+		claims, err := a.verifyIDToken(token.IDToken, nonce)
+		if err != nil {
+			return nil, err
+		}
+		info = claims
+	}
+	if p.UserInfoURL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.UserInfoURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		req.Header.Set("Accept", "application/json")
+		resp, err := a.opts.HTTPClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("oauth userinfo: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("oauth userinfo: unexpected status %d", resp.StatusCode)
+		}
+		var userinfo map[string]any
+		if err := json.UnmarshalRead(io.LimitReader(resp.Body, 1<<20), &userinfo); err != nil {
+			return nil, fmt.Errorf("oauth userinfo: %w", err)
+		}
+		// userinfo 的 sub 必须与 id_token 一致 (OIDC Core 5.3.2)
+		if info != nil && claimString(userinfo, "sub") != claimString(info, "sub") {
+			return nil, errors.New("oauth userinfo: subject does not match id_token")
+		}
+		if info == nil {
+			info = userinfo
+		} else {
+			for k, v := range userinfo {
+				info[k] = v
+			}
+		}
+	}
+
+	var user *User
+	if p.UserFromInfo != nil {
+		user = p.UserFromInfo(info)
+	} else {
+		user = &User{
+			ID:      claimString(info, "sub"),
+			Email:   claimString(info, "email"),
+			Name:    claimString(info, "name"),
+			Picture: claimString(info, "picture"),
+		}
+	}
+	if user == nil || user.ID == "" {
+		return nil, errors.New("oauth: provider did not return a user id")
+	}
+	user.Provider = p.Name
+	user.Claims = info
+	return user, nil
+}
+
+// verifyIDToken 解析 id_token 的声明并校验 iss、aud、nonce 与 exp
+func (a *OAuth) verifyIDToken(idToken, nonce string) (map[string]any, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("oauth: missing or malformed id_token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("oauth: malformed id_token: %w", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("oauth: malformed id_token: %w", err)
+	}
+	if claimString(claims, "iss") != a.opts.Provider.Issuer {
+		return nil, errors.New("oauth: id_token issuer mismatch")
+	}
+	var audiences []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		audiences = []string{aud}
+	case []any:
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+	}
+	if !slices.Contains(audiences, a.opts.ClientID) {
+		return nil, errors.New("oauth: id_token audience mismatch")
+	}
+	if nonce != "" && subtle.ConstantTimeCompare([]byte(claimString(claims, "nonce")), []byte(nonce)) != 1 {
+		return nil, errors.New("oauth: id_token nonce mismatch")
+	}
+	if exp, ok := claims["exp"].(float64); !ok || time.Now().After(time.Unix(int64(exp), 0)) {
+		return nil, errors.New("oauth: id_token expired")
+	}
+	return claims, nil
+}
+
+func (a *OAuth) redirectURL(c *Context) string {
+	return c.AbsoluteURL(a.opts.RedirectURL)
+}
+
+func (a *OAuth) flowCookieName() string {
+	return a.opts.CookieName + "_flow"
+}
+
+// writeCookie 以 AES-GCM 加密 v 并写入 cookie, 超过 maxOAuthCookieSize 时返回 ErrOAuthCookieTooLarge 且不写入
+func (a *OAuth) writeCookie(c *Context, name string, v any, maxAge int) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, a.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := a.aead.Seal(nonce, nonce, data, []byte(name))
+	value := base64.RawURLEncoding.EncodeToString(sealed)
+	if len(name)+len(value) > maxOAuthCookieSize {
+		return fmt.Errorf("%w: %d bytes", ErrOAuthCookieTooLarge, len(name)+len(value))
+	}
+	c.SetCookieData(&http.Cookie{
+		Name:     name,
+		Value:    value,
+		MaxAge:   maxAge,
+		Secure:   c.ForwardedProto() == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// readCookie 解密 cookie 到 v, cookie 不存在或被篡改时返回 false
+func (a *OAuth) readCookie(c *Context, name string, v any) bool {
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return false
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(sealed) < a.aead.NonceSize() {
+		return false
+	}
+	nonce, ciphertext := sealed[:a.aead.NonceSize()], sealed[a.aead.NonceSize():]
+	data, err := a.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+func (a *OAuth) clearCookie(c *Context, name string) {
+	c.SetCookieData(&http.Cookie{Name: name, MaxAge: -1, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+}
+
+// randomToken 返回 32 字节的随机值, 以 base64url 编码
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// safeRedirectPath 只接受站内路径, 防止登录后被重定向到外部站点.
+// 浏览器会忽略控制字符并把反斜杠当作斜杠, /\t/evil.example 与 /\\evil.example 都会被解释为外部地址
+func safeRedirectPath(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		return ""
+	}
+	for i := 0; i < len(p); i++ {
+		if p[i] < 0x20 || p[i] == 0x7f || p[i] == '\\' {
+			return ""
+		}
+	}
+	u, err := url.Parse(p)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return ""
+	}
+	// 解码后的路径同样不能以 // 开头或包含反斜杠, 例如 /%2F/evil.example 与 /%5C/evil.example
+	if strings.HasPrefix(u.Path, "//") || strings.Contains(u.Path, "\\") {
+		return ""
+	}
+	return p
+}
+
+// claimString 以字符串形式读取声明, 数字声明 (例如 GitHub 的用户 ID) 会被格式化
+func claimString(claims map[string]any, name string) string {
+	switch v := claims[name].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+package touka
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// fakeOIDCProvider 模拟授权服务器: 记录授权请求中的 PKCE challenge 与 nonce, 并在换取令牌时校验
+func fakeOIDCProvider(t *testing.T) (*httptest.Server, *url.Values) {
+	var authorize url.Values
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+			if r.PostForm.Get("grant_type") == "authorization_code" &&
+				(r.PostForm.Get("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != authorize.Get("code_challenge")) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			claims, _ := json.Marshal(map[string]any{
+				"iss": srv.URL, "aud": "client-1", "sub": "u-1", "nonce": authorize.Get("nonce"),
+				"email": "iroha@example.com", "exp": time.Now().Add(time.Hour).Unix(),
+			})
+			idToken := "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"at-1","refresh_token":"rt-1","expires_in":3600,"id_token":"` + idToken + `"}`))
+		case "/userinfo":
+			if r.Header.Get("Authorization") != "Bearer at-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"sub":"u-1","name":"Iroha"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &authorize
+}
+
+func newTestOAuth(srv *httptest.Server) *OAuth {
+	return NewOAuth(OAuthOptions{
+		Provider: OAuthProvider{
+			Name:        "test",
+			AuthURL:     srv.URL + "/authorize",
+			TokenURL:    srv.URL + "/token",
+			UserInfoURL: srv.URL + "/userinfo",
+			Issuer:      srv.URL,
+			Scopes:      []string{"openid", "email"},
+		},
+		ClientID:    "client-1",
+		RedirectURL: "/auth/callback",
+		Secret:      []byte("0123456789abcdef0123456789abcdef"),
+	})
+}
+
+func cookieHeader(w *httptest.ResponseRecorder) http.Header {
+	var pairs []string
+	for _, c := range w.Result().Cookies() {
+		if c.MaxAge >= 0 {
+			pairs = append(pairs, c.Name+"="+c.Value)
+		}
+	}
+	return http.Header{"Cookie": {strings.Join(pairs, "; ")}}
+}
+
+func TestOAuthLoginFlow(t *testing.T) {
+	srv, authorize := fakeOIDCProvider(t)
+	auth := newTestOAuth(srv)
+	engine := New()
+	engine.Use(auth.Session())
+	engine.GET("/auth/login", auth.LoginHandler())
+	engine.GET("/auth/callback", auth.CallbackHandler())
+	engine.GET("/dash", auth.RequireLogin(), func(c *Context) {
+		u := c.CurrentUser()
+		c.String(http.StatusOK, "%s %s %s %s", u.Provider, u.ID, u.Email, u.Name)
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "http://app.example.com/auth/login?next=/dash", nil, nil)
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected a redirect to the provider, got %d", w.Code)
+	}
+	loc, _ := url.Parse(w.Header().Get("Location"))
+	*authorize = loc.Query()
+	if authorize.Get("code_challenge_method") != "S256" || authorize.Get("nonce") == "" ||
+		authorize.Get("redirect_uri") != "http://app.example.com/auth/callback" {
+		t.Fatalf("unexpected authorization request %v", loc)
+	}
+	flowCookies := cookieHeader(w)
+
+	w = PerformRequest(engine, http.MethodGet, "http://app.example.com/auth/callback?code=good-code&state=forged", nil, flowCookies)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a forged state to be rejected, got %d", w.Code)
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "http://app.example.com/auth/callback?code=good-code&state="+authorize.Get("state"), nil, flowCookies)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/dash" {
+		t.Fatalf("expected the callback to log in and redirect, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/dash", nil, cookieHeader(w))
+	if w.Code != http.StatusOK || w.Body.String() != "test u-1 iroha@example.com Iroha" {
+		t.Fatalf("unexpected session user %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestOAuthRequireLogin(t *testing.T) {
+	srv, _ := fakeOIDCProvider(t)
+	auth := newTestOAuth(srv)
+	engine := New()
+	engine.Use(auth.Session())
+	engine.GET("/dash", auth.RequireLogin(), func(c *Context) { c.Status(http.StatusOK) })
+
+	w := PerformRequest(engine, http.MethodGet, "/dash?tab=1", nil, http.Header{"Accept": {"text/html"}})
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/auth/login?next=%2Fdash%3Ftab%3D1" {
+		t.Fatalf("expected a login redirect, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/dash", nil, nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for non-page requests, got %d", w.Code)
+	}
+	tampered := http.Header{"Cookie": {"touka_session=" + base64.RawURLEncoding.EncodeToString(make([]byte, 64))}}
+	if w := PerformRequest(engine, http.MethodGet, "/dash", nil, tampered); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a forged session to be ignored, got %d", w.Code)
+	}
+}
+
+func TestSafeRedirectPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"/dash":                "/dash",
+		"//evil.example":       "",
+		"/\\evil.example":      "",
+		"https://evil.example": "",
+		"/\t/evil.com":         "",
+		"/\r\n/evil.com":       "",
+		"/%5C/evil.com":        "",
+		"/%2F/evil.com":        "",
+		"/dash?tab=1#top":      "/dash?tab=1#top",
+		"":                     "",
+	} {
+		if got := safeRedirectPath(in); got != want {
+			t.Errorf("safeRedirectPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOAuthLogoutRejectsEncodedExternalNext(t *testing.T) {
+	srv, _ := fakeOIDCProvider(t)
+	auth := newTestOAuth(srv)
+	engine := New()
+	engine.GET("/auth/logout", auth.LogoutHandler())
+
+	for _, next := range []string{"/%09/evil.com", "/%0D%0A/evil.com", "/%5C/evil.com"} {
+		w := PerformRequest(engine, http.MethodGet, "/auth/logout?next="+next, nil, nil)
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/" {
+			t.Fatalf("next=%s: expected a redirect to /, got %d %q", next, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestOAuthDefaultHTTPClientHasTimeout(t *testing.T) {
+	srv, _ := fakeOIDCProvider(t)
+	auth := newTestOAuth(srv)
+	if auth.opts.HTTPClient == http.DefaultClient || auth.opts.HTTPClient.Timeout <= 0 {
+		t.Fatalf("expected a default client with a timeout, got %+v", auth.opts.HTTPClient)
+	}
+}
+
+func TestOAuthLoginRejectsOversizedCookie(t *testing.T) {
+	srv, _ := fakeOIDCProvider(t)
+	auth := NewOAuth(OAuthOptions{
+		Provider:    OAuthProvider{AuthURL: srv.URL + "/authorize", TokenURL: srv.URL + "/token", Issuer: srv.URL},
+		ClientID:    "client-1",
+		RedirectURL: "/auth/callback",
+		Secret:      []byte("0123456789abcdef0123456789abcdef"),
+		StoreTokens: true,
+	})
+	engine := New()
+	var loginErr error
+	engine.GET("/login", func(c *Context) {
+		token := &OAuthToken{AccessToken: strings.Repeat("a", 2000), IDToken: strings.Repeat("i", 2000)}
+		loginErr = auth.Login(c, &User{ID: "1"}, token)
+		c.Status(http.StatusNoContent)
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/login", nil, nil)
+	if !errors.Is(loginErr, ErrOAuthCookieTooLarge) {
+		t.Fatalf("expected ErrOAuthCookieTooLarge, got %v", loginErr)
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Fatalf("expected no cookie to be written, got %v", w.Result().Cookies())
+	}
+}
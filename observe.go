@@ -0,0 +1,150 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ObservabilityFilter 决定哪些请求被访问日志、指标、追踪与请求转储等观测功能记录.
+// 在引擎上设置一次, 所有观测中间件通过 c.Observed 得到一致的结果, 无需分别配置 /healthz 等探测请求的排除规则
+type ObservabilityFilter struct {
+	// ExcludePaths 为不记录的路径, 支持 path.Match 通配 (例如 /debug/*) 以及以 /** 结尾的前缀匹配 (例如 /static/**)
+	ExcludePaths []string
+	// ExcludeStatus 为不记录的响应状态. 1 到 5 表示状态类别 (例如 2 表示 2xx), 其他值表示具体状态码 (例如 404).
+	// 状态只在响应写入后可知, 因此只影响响应完成后的判断, 例如访问日志
+	ExcludeStatus []int
+	// ExcludeHeaders 按请求头排除探测请求, 键为头部名称, 值为 path.Match 通配, 为空时只要求头部存在,
+	// 例如 {"User-Agent": "kube-probe/*", "X-Health-Check": ""}
+	ExcludeHeaders map[string]string
+	// Sample 按路径设置采样比例, 按顺序使用第一个匹配的规则
+	Sample []SampleRule
+	// SampleRatio 为不匹配 Sample 的请求的采样比例, 取值 (0, 1], 0 表示全部记录
+	SampleRatio float64
+}
+
+// SampleRule 为匹配 Path 的请求设置采样比例, Path 的写法与 ExcludePaths 相同. Ratio 为 0 时不记录
+type SampleRule struct {
+	Path  string
+	Ratio float64
+}
+
+// SetObservabilityFilter 设置引擎的观测过滤规则, 规则中的通配写法不合法时 panic.
+// 引擎的请求计数 (Stats 与 MetricsHandler 中的 touka_requests_total) 会排除被过滤的请求
+func (engine *Engine) SetObservabilityFilter(f ObservabilityFilter) {
+	patterns := append([]string(nil), f.ExcludePaths...)
+	for _, rule := range f.Sample {
+		patterns = append(patterns, rule.Path)
+	}
+	for _, pattern := range f.ExcludeHeaders {
+		patterns = append(patterns, pattern)
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+			panic(fmt.Sprintf("touka: invalid observability pattern %q: %v", pattern, err))
+		}
+	}
+	if f.SampleRatio < 0 || f.SampleRatio > 1 {
+		panic(fmt.Sprintf("touka: sample ratio %v out of range [0, 1]", f.SampleRatio))
+	}
+	engine.observeFilter = &f
+}
+
+// Observed 报告当前请求是否应被观测中间件记录. 路径、请求头与采样在首次调用时判定, 之后在请求内保持不变,
+// 同一请求要么被所有观测中间件记录, 要么都不记录. 响应写入后调用时还会按 ExcludeStatus 排除:
+//
+//	func AccessLog(c *touka.Context) {
+//		start := time.Now()
+//		c.Next()
+//		if c.Observed() {
+//			c.GetLogger().Infof("%s %s %d %v", c.Request.Method, c.Request.URL.Path, c.Writer.Status(), time.Since(start))
+//		}
+//	}
+func (c *Context) Observed() bool {
+	f := c.engine.observeFilter
+	if f == nil {
+		return true
+	}
+	if !c.requestObserved() {
+		return false
+	}
+	if c.Writer != nil && c.Writer.Written() {
+		status := c.Writer.Status()
+		for _, s := range f.ExcludeStatus {
+			if s == status || (s >= 1 && s <= 5 && status/100 == s) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// requestObserved 按请求本身 (路径、请求头与采样) 判定是否记录, 结果在请求内缓存
+func (c *Context) requestObserved() bool {
+	f := c.engine.observeFilter
+	if f == nil {
+		return true
+	}
+	if c.observed == 0 {
+		c.observed = -1
+		if f.matchRequest(c.Request) {
+			c.observed = 1
+		}
+	}
+	return c.observed > 0
+}
+
+func (f *ObservabilityFilter) matchRequest(req *http.Request) bool {
+	p := req.URL.Path
+	for _, pattern := range f.ExcludePaths {
+		if matchObservePath(pattern, p) {
+			return false
+		}
+	}
+	for name, pattern := range f.ExcludeHeaders {
+		values := req.Header.Values(name)
+		if pattern == "" && len(values) > 0 {
+			return false
+		}
+		for _, v := range values {
+			if ok, _ := path.Match(pattern, v); ok {
+				return false
+			}
+		}
+	}
+	ratio := f.SampleRatio
+	for _, rule := range f.Sample {
+		if matchObservePath(rule.Path, p) {
+			ratio = rule.Ratio
+			if ratio <= 0 {
+				return false
+			}
+			break
+		}
+	}
+	return ratio <= 0 || ratio >= 1 || rand.Float64() < ratio
+}
+
+// matchObservePath 按 path.Match 匹配路径, 以 /** 结尾的写法匹配该前缀本身及其下的所有路径
+func matchObservePath(pattern, p string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		// 取路径中与前缀段数相同的部分进行匹配, 前缀中也可以使用通配, 例如 /api/*/internal/**
+		n, i := strings.Count(prefix, "/")+1, 0
+		for ; i < len(p); i++ {
+			if p[i] == '/' {
+				if n--; n == 0 {
+					break
+				}
+			}
+		}
+		p, pattern = p[:i], prefix
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}
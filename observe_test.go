@@ -0,0 +1,103 @@
+package touka
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestObservabilityFilter(t *testing.T) {
+	engine := New()
+	engine.SetObservabilityFilter(ObservabilityFilter{
+		ExcludePaths:   []string{"/healthz", "/static/**", "/api/*/internal/**"},
+		ExcludeStatus:  []int{3, 404},
+		ExcludeHeaders: map[string]string{"User-Agent": "kube-probe/*", "X-Synthetic": ""},
+		Sample:         []SampleRule{{Path: "/noisy", Ratio: 0}, {Path: "/half", Ratio: 0.5}},
+	})
+	var observed map[string]bool
+	engine.Use(func(c *Context) {
+		before := c.Observed()
+		c.Next()
+		after := c.Observed()
+		if !before && after {
+			t.Errorf("%s: excluded request became observed", c.Request.URL.Path)
+		}
+		observed[c.Request.URL.Path] = after
+	})
+	engine.GET("/*path", func(c *Context) {
+		switch c.Param("path") {
+		case "/moved":
+			c.Redirect(http.StatusFound, "/")
+		case "/gone":
+			c.Status(http.StatusNotFound)
+		default:
+			c.Status(http.StatusOK)
+		}
+	})
+
+	tests := []struct {
+		path   string
+		header http.Header
+		want   bool
+	}{
+		{"/users", nil, true},
+		{"/healthz", nil, false},
+		{"/static", nil, false},
+		{"/static/css/app.css", nil, false},
+		{"/statics", nil, true},
+		{"/api/v1/internal/jobs", nil, false},
+		{"/api/v1/public", nil, true},
+		{"/moved", nil, false},
+		{"/gone", nil, false},
+		{"/users", http.Header{"User-Agent": {"kube-probe/1.29"}}, false},
+		{"/users", http.Header{"User-Agent": {"curl/8.0"}}, true},
+		{"/users", http.Header{"X-Synthetic": {"1"}}, false},
+		{"/noisy", nil, false},
+	}
+	for _, tt := range tests {
+		observed = map[string]bool{}
+		PerformRequest(engine, http.MethodGet, tt.path, nil, tt.header)
+		if got := observed[tt.path]; got != tt.want {
+			t.Errorf("%s %v: observed = %v, want %v", tt.path, tt.header, got, tt.want)
+		}
+	}
+
+	sampled := 0
+	for range 1000 {
+		observed = map[string]bool{}
+		PerformRequest(engine, http.MethodGet, "/half", nil, nil)
+		if observed["/half"] {
+			sampled++
+		}
+	}
+	if sampled < 350 || sampled > 650 {
+		t.Fatalf("expected about half of /half to be sampled, got %d/1000", sampled)
+	}
+}
+
+func TestObservabilityFilterStats(t *testing.T) {
+	engine := New()
+	engine.EnableStats()
+	engine.SetObservabilityFilter(ObservabilityFilter{ExcludePaths: []string{"/healthz"}})
+	engine.GET("/healthz", func(c *Context) { c.Status(http.StatusOK) })
+	engine.GET("/users", func(c *Context) { c.Status(http.StatusOK) })
+
+	for _, p := range []string{"/healthz", "/healthz", "/users"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, p, nil))
+	}
+	if s := engine.Stats(); s.Requests != 1 || s.Methods[http.MethodGet] != 1 {
+		t.Fatalf("filtered requests must not be counted: %+v", s)
+	}
+}
+
+func TestSetObservabilityFilterInvalid(t *testing.T) {
+	for _, f := range []ObservabilityFilter{
+		{ExcludePaths: []string{"/a/[b"}},
+		{ExcludeHeaders: map[string]string{"User-Agent": "["}},
+		{SampleRatio: 1.5},
+	} {
+		if catchPanic(func() { New().SetObservabilityFilter(f) }) == nil {
+			t.Errorf("expected panic for %+v", f)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrOriginNotAllowed 表示请求的 Origin 既不是同源, 也不在 SetAllowedOrigins 允许的范围内
+var ErrOriginNotAllowed = errors.New("origin not allowed")
+
+// SetAllowedOrigins 设置同源之外允许的来源, 由 c.OriginAllowed 与 CheckOrigin 共用,
+// WebSocket 握手与跨域接口应以此作为统一的来源策略.
+// 每项形如 https://example.com 或 https://*.example.com (匹配任意子域名), "*" 允许任意来源.
+// 未设置时只允许同源请求; 不带参数调用恢复默认
+func (engine *Engine) SetAllowedOrigins(origins ...string) error {
+	allowed := make([]string, 0, len(origins))
+	for _, o := range origins {
+		o = strings.TrimSpace(o)
+		if o == "*" {
+			allowed = append(allowed, o)
+			continue
+		}
+		raw, wildcard := o, false
+		if scheme, host, ok := strings.Cut(o, "://*."); ok {
+			raw, wildcard = scheme+"://"+host, true
+		}
+		normalized, ok := normalizeOrigin(raw)
+		if !ok {
+			return fmt.Errorf("invalid allowed origin %q: must be scheme://host[:port]", o)
+		}
+		if wildcard {
+			scheme, host, _ := strings.Cut(normalized, "://")
+			normalized = scheme + "://*." + host
+		}
+		allowed = append(allowed, normalized)
+	}
+	engine.allowedOrigins = allowed
+	return nil
+}
+
+// normalizeOrigin 将来源规范化为小写的 scheme://host[:port], 并去掉默认端口
+func normalizeOrigin(origin string) (string, bool) {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.User != nil ||
+		(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", false
+	}
+	scheme, host := strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	switch {
+	case scheme == "http" || scheme == "ws":
+		host = strings.TrimSuffix(host, ":80")
+	case scheme == "https" || scheme == "wss":
+		host = strings.TrimSuffix(host, ":443")
+	}
+	return scheme + "://" + host, true
+}
+
+// matchOrigin 判断规范化后的来源是否匹配 SetAllowedOrigins 中的一项
+func matchOrigin(pattern, origin string) bool {
+	if pattern == origin {
+		return true
+	}
+	scheme, host, ok := strings.Cut(pattern, "://*.")
+	if !ok || !strings.HasPrefix(origin, scheme+"://") {
+		return false
+	}
+	return strings.HasSuffix(origin[len(scheme)+3:], "."+host)
+}
+
+// OriginAllowed 判断请求的 Origin 是否允许: 与站点同源 (当前请求的 ForwardedProto 与 ForwardedHost,
+// 或 SetCanonicalURL 设置的地址), 或匹配 SetAllowedOrigins.
+// 没有 Origin 头部的请求 (非浏览器客户端) 视为允许; 浏览器在 WebSocket 握手与跨域请求中总会发送 Origin
+func (c *Context) OriginAllowed() bool {
+	raw := c.Request.Header.Get("Origin")
+	if raw == "" {
+		return true
+	}
+	origin, ok := normalizeOrigin(raw)
+	if !ok {
+		// "null" 等不透明来源只能通过 "*" 放行
+		return c.engine != nil && matchAnyOrigin(c.engine.allowedOrigins, "")
+	}
+	if self, ok := normalizeOrigin(c.ForwardedProto() + "://" + c.ForwardedHost()); ok && self == origin {
+		return true
+	}
+	if c.engine == nil {
+		return false
+	}
+	if u, err := url.Parse(c.engine.canonicalURL); err == nil && u.Host != "" {
+		if self, ok := normalizeOrigin(u.Scheme + "://" + u.Host); ok && self == origin {
+			return true
+		}
+	}
+	return matchAnyOrigin(c.engine.allowedOrigins, origin)
+}
+
+// matchAnyOrigin 判断来源是否匹配任意一项, origin 为空表示不透明来源, 只匹配 "*"
+func matchAnyOrigin(patterns []string, origin string) bool {
+	for _, p := range patterns {
+		if p == "*" || (origin != "" && matchOrigin(p, origin)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOrigin 返回按引擎来源策略检查 Origin 的中间件, 不允许的请求返回 403.
+// 放在 WebSocket 升级等路由之前, 使握手默认只接受同源页面发起的连接:
+//
+//	r.GET("/ws", touka.CheckOrigin(), wsHandler)
+//
+// 第三方升级器自带的来源检查 (例如 CheckOrigin 回调) 可以交给此中间件统一处理
+func CheckOrigin() HandlerFunc {
+	return func(c *Context) {
+		if !c.OriginAllowed() {
+			c.AddClientError(ErrOriginNotAllowed)
+			c.ErrorUseHandle(http.StatusForbidden, ErrOriginNotAllowed)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,86 @@
+package touka
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCheckOriginSameOriginByDefault(t *testing.T) {
+	engine := New()
+	engine.GET("/ws", CheckOrigin(), func(c *Context) { c.Status(http.StatusNoContent) })
+
+	cases := []struct {
+		origin string
+		want   int
+	}{
+		{"", http.StatusNoContent},
+		{"http://example.com", http.StatusNoContent},
+		{"HTTP://Example.com:80", http.StatusNoContent},
+		{"https://example.com", http.StatusForbidden},
+		{"http://evil.example", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		headers := http.Header{}
+		if tc.origin != "" {
+			headers.Set("Origin", tc.origin)
+		}
+		if w := PerformRequest(engine, http.MethodGet, "http://example.com/ws", nil, headers); w.Code != tc.want {
+			t.Errorf("origin %q: expected %d, got %d", tc.origin, tc.want, w.Code)
+		}
+	}
+	if client, _ := engine.ErrorCounts(); client != 3 {
+		t.Fatalf("rejections should count as client errors, got %d", client)
+	}
+}
+
+func TestSetAllowedOrigins(t *testing.T) {
+	engine := New()
+	if err := engine.SetAllowedOrigins("https://app.example.com:443", "https://*.partner.test"); err != nil {
+		t.Fatal(err)
+	}
+	engine.GET("/ws", CheckOrigin(), func(c *Context) { c.Status(http.StatusNoContent) })
+
+	cases := map[string]int{
+		"https://app.example.com":      http.StatusNoContent,
+		"https://a.b.partner.test":     http.StatusNoContent,
+		"https://partner.test":         http.StatusForbidden,
+		"http://a.partner.test":        http.StatusForbidden,
+		"https://evilpartner.test":     http.StatusForbidden,
+		"https://app.example.com:8443": http.StatusForbidden,
+	}
+	for origin, want := range cases {
+		w := PerformRequest(engine, http.MethodGet, "http://example.com/ws", nil, http.Header{"Origin": {origin}})
+		if w.Code != want {
+			t.Errorf("origin %q: expected %d, got %d", origin, want, w.Code)
+		}
+	}
+
+	if err := engine.SetAllowedOrigins("*"); err != nil {
+		t.Fatal(err)
+	}
+	if w := PerformRequest(engine, http.MethodGet, "http://example.com/ws", nil, http.Header{"Origin": {"null"}}); w.Code != http.StatusNoContent {
+		t.Fatalf("expected * to allow opaque origins, got %d", w.Code)
+	}
+
+	for _, invalid := range []string{"example.com", "https://example.com/app", "https://user@example.com"} {
+		if err := engine.SetAllowedOrigins(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestOriginAllowedCanonicalURL(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://internal:8080/ws", nil)
+	req.Header.Set("Origin", "https://www.example.com")
+	c, engine := CreateTestContextWithRequest(nil, req)
+	if c.OriginAllowed() {
+		t.Fatal("expected a foreign origin to be rejected")
+	}
+	if err := engine.SetCanonicalURL("https://www.example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if !c.OriginAllowed() {
+		t.Fatal("expected the canonical origin to be allowed")
+	}
+}
@@ -0,0 +1,174 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SortField 描述一个排序字段
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Page 是从查询参数解析出的分页与排序参数
+type Page struct {
+	Limit  int
+	Offset int
+	Cursor string // 使用游标分页时的游标, 与 Offset 互斥
+	Sort   []SortField
+}
+
+// PaginationOptions 配置 BindPagination 的默认值与约束
+type PaginationOptions struct {
+	// DefaultLimit 为未指定 limit 时的默认值, 默认 20
+	DefaultLimit int
+	// MaxLimit 为 limit 的上限, 超出时截断, 默认 100
+	MaxLimit int
+	// SortableFields 为允许排序的字段, 为空时不允许排序
+	SortableFields []string
+	// DefaultSort 为未指定 sort 时的排序
+	DefaultSort []SortField
+}
+
+// BindPagination 从查询参数解析分页与排序参数.
+// 支持的参数: limit, offset, page (从 1 开始, 换算为 offset), cursor, sort (例如 sort=name,-created_at).
+// 参数不合法时返回状态码为 400 的 *HTTPError
+func (c *Context) BindPagination(opts PaginationOptions) (Page, error) {
+	if opts.DefaultLimit <= 0 {
+		opts.DefaultLimit = 20
+	}
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = 100
+	}
+
+	page := Page{Limit: min(opts.DefaultLimit, opts.MaxLimit)}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return Page{}, NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid limit %q", raw))
+		}
+		page.Limit = min(limit, opts.MaxLimit)
+	}
+
+	page.Cursor = c.Query("cursor")
+	rawOffset, rawPage := c.Query("offset"), c.Query("page")
+	if page.Cursor != "" && (rawOffset != "" || rawPage != "") {
+		return Page{}, NewHTTPError(http.StatusBadRequest, errors.New("cursor cannot be combined with offset or page"))
+	}
+	switch {
+	case rawOffset != "":
+		offset, err := strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			return Page{}, NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid offset %q", rawOffset))
+		}
+		page.Offset = offset
+	case rawPage != "":
+		n, err := strconv.Atoi(rawPage)
+		// 页码过大时 (n-1)*Limit 会溢出为负数的偏移量
+		if err != nil || n < 1 || n-1 > math.MaxInt/page.Limit {
+			return Page{}, NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid page %q", rawPage))
+		}
+		page.Offset = (n - 1) * page.Limit
+	}
+
+	page.Sort = opts.DefaultSort
+	if raw := c.Query("sort"); raw != "" {
+		sort, err := parseSortFields(raw, opts.SortableFields)
+		if err != nil {
+			return Page{}, NewHTTPError(http.StatusBadRequest, err)
+		}
+		page.Sort = sort
+	}
+	return page, nil
+}
+
+func parseSortFields(raw string, allowed []string) ([]SortField, error) {
+	parts := strings.Split(raw, ",")
+	fields := make([]SortField, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := SortField{}
+		switch part[0] {
+		case '-':
+			field.Desc = true
+			part = part[1:]
+		case '+':
+			part = part[1:]
+		}
+		if !slices.Contains(allowed, part) {
+			return nil, fmt.Errorf("cannot sort by %q", part)
+		}
+		field.Field = part
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// SetTotalCount 设置 X-Total-Count 响应头
+func (c *Context) SetTotalCount(total int64) {
+	c.SetHeader("X-Total-Count", strconv.FormatInt(total, 10))
+}
+
+// SetPageHeaders 为 offset 分页设置 X-Total-Count 与 Link (first/prev/next/last) 响应头,
+// 链接保留当前请求的其他查询参数
+func (c *Context) SetPageHeaders(page Page, total int64) {
+	c.SetTotalCount(total)
+	if page.Limit <= 0 {
+		return
+	}
+
+	limit := int64(page.Limit)
+	offset := int64(page.Offset)
+	lastOffset := int64(0)
+	if total > 0 {
+		lastOffset = (total - 1) / limit * limit
+	}
+
+	links := make([]string, 0, 4)
+	links = append(links, c.pageLink("first", url.Values{"offset": {"0"}}, limit))
+	if offset > 0 {
+		links = append(links, c.pageLink("prev", url.Values{"offset": {strconv.FormatInt(max(offset-limit, 0), 10)}}, limit))
+	}
+	if offset+limit < total {
+		links = append(links, c.pageLink("next", url.Values{"offset": {strconv.FormatInt(offset+limit, 10)}}, limit))
+	}
+	links = append(links, c.pageLink("last", url.Values{"offset": {strconv.FormatInt(lastOffset, 10)}}, limit))
+	c.SetHeader("Link", strings.Join(links, ", "))
+}
+
+// SetCursorLink 为游标分页设置 rel="next" 的 Link 响应头, nextCursor 为空时表示没有下一页
+func (c *Context) SetCursorLink(page Page, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+	c.SetHeader("Link", c.pageLink("next", url.Values{"cursor": {nextCursor}}, int64(page.Limit)))
+}
+
+func (c *Context) pageLink(rel string, set url.Values, limit int64) string {
+	query := c.Request.URL.Query()
+	query.Del("page")
+	query.Del("offset")
+	query.Del("cursor")
+	for k, v := range set {
+		query[k] = v
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.FormatInt(limit, 10))
+	}
+	u := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=%q", u.String(), rel)
+}
@@ -0,0 +1,56 @@
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBindPagination(t *testing.T) {
+	opts := PaginationOptions{MaxLimit: 50, SortableFields: []string{"name", "created_at"}}
+
+	c, _ := CreateTestContextWithRequest(nil, httptest.NewRequest(http.MethodGet, "/users?limit=500&page=3&sort=-created_at,name", nil))
+	page, err := c.BindPagination(opts)
+	if err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	if page.Limit != 50 || page.Offset != 100 {
+		t.Fatalf("expected capped limit and page offset, got %+v", page)
+	}
+	if len(page.Sort) != 2 || !page.Sort[0].Desc || page.Sort[0].Field != "created_at" || page.Sort[1].Desc {
+		t.Fatalf("unexpected sort %+v", page.Sort)
+	}
+
+	for _, target := range []string{"/users?limit=-1", "/users?offset=x", "/users?sort=password", "/users?cursor=abc&offset=1", "/users?page=922337203685477580"} {
+		c, _ = CreateTestContextWithRequest(nil, httptest.NewRequest(http.MethodGet, target, nil))
+		_, err := c.BindPagination(opts)
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400 HTTPError, got %v", target, err)
+		}
+	}
+}
+
+func TestSetPageHeaders(t *testing.T) {
+	rr := httptest.NewRecorder()
+	c, _ := CreateTestContextWithRequest(rr, httptest.NewRequest(http.MethodGet, "/users?q=iro&offset=20&limit=10", nil))
+	page, _ := c.BindPagination(PaginationOptions{})
+	c.SetPageHeaders(page, 45)
+
+	if got := rr.Header().Get("X-Total-Count"); got != "45" {
+		t.Fatalf("unexpected total count %q", got)
+	}
+	link := rr.Header().Get("Link")
+	for _, want := range []string{
+		`</users?limit=10&offset=0&q=iro>; rel="first"`,
+		`</users?limit=10&offset=10&q=iro>; rel="prev"`,
+		`</users?limit=10&offset=30&q=iro>; rel="next"`,
+		`</users?limit=10&offset=40&q=iro>; rel="last"`,
+	} {
+		if !strings.Contains(link, want) {
+			t.Fatalf("expected Link to contain %s, got %s", want, link)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bufio"
+	"bytes"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// PartialResponseOptions 配置 PartialResponse 中间件
+type PartialResponseOptions struct {
+	// FieldsParam 为字段过滤的查询参数名, 默认 fields
+	FieldsParam string
+	// PrettyParam 为美化输出的查询参数名, 默认 pretty
+	PrettyParam string
+	// MaxBufferSize 为缓冲响应体的上限, 超出时回退为直接输出, 默认 1MB
+	MaxBufferSize int
+}
+
+// PartialResponse 返回一个对 JSON 响应做后处理的中间件:
+//   - ?fields=id,name,author.name 只保留指定字段, 顶层为数组时作用于每个元素
+//   - ?pretty 以缩进格式输出
+//
+// 仅在请求携带上述参数时缓冲响应. 非 JSON 响应、调用了 Flush 的流式响应、
+// 被劫持的连接以及超过 MaxBufferSize 的响应会回退为原样输出.
+func PartialResponse(opts PartialResponseOptions) HandlerFunc {
+	if opts.FieldsParam == "" {
+		opts.FieldsParam = "fields"
+	}
+	if opts.PrettyParam == "" {
+		opts.PrettyParam = "pretty"
+	}
+	if opts.MaxBufferSize <= 0 {
+		opts.MaxBufferSize = 1 << 20
+	}
+
+	return func(c *Context) {
+		query := c.Request.URL.Query()
+		fields := parseFieldSelection(query.Get(opts.FieldsParam))
+		_, pretty := query[opts.PrettyParam]
+		if pretty {
+			if v := query.Get(opts.PrettyParam); v != "" {
+				pretty, _ = strconv.ParseBool(v)
+			}
+		}
+		if fields == nil && !pretty {
+			c.Next()
+			return
+		}
+
+		bw := &bufferedJSONWriter{ResponseWriter: c.Writer, limit: c.bufferLimit(opts.MaxBufferSize)}
+		c.Writer = bw
+		defer func() {
+			c.Writer = bw.ResponseWriter
+		}()
+
+		c.Next()
+
+		if err := bw.finish(fields, pretty); err != nil {
+			c.AddError(err)
+		}
+	}
+}
+
+// fieldSelection 是字段过滤的树形表示, 空 map 表示选中整个字段
+type fieldSelection map[string]fieldSelection
+
+func parseFieldSelection(raw string) fieldSelection {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	root := fieldSelection{}
+	for field := range strings.SplitSeq(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		node := root
+		for part := range strings.SplitSeq(field, ".") {
+			next, ok := node[part]
+			if !ok {
+				next = fieldSelection{}
+				node[part] = next
+			}
+			node = next
+		}
+	}
+	return root
+}
+
+// filterJSON 按字段选择过滤 JSON 值, 保持原有的成员顺序
+func filterJSON(value jsontext.Value, sel fieldSelection) (jsontext.Value, error) {
+	if len(sel) == 0 {
+		return value, nil
+	}
+	switch value.Kind() {
+	case '{', '[':
+	default:
+		return value, nil
+	}
+
+	dec := jsontext.NewDecoder(bytes.NewReader(value))
+	var out bytes.Buffer
+	enc := jsontext.NewEncoder(&out)
+
+	open, err := dec.ReadToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := enc.WriteToken(open); err != nil {
+		return nil, err
+	}
+	isObject := open.Kind() == '{'
+	for dec.PeekKind() != '}' && dec.PeekKind() != ']' {
+		var name string
+		var sub fieldSelection
+		if isObject {
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return nil, err
+			}
+			name = tok.String()
+			var ok bool
+			if sub, ok = sel[name]; !ok {
+				if err := dec.SkipValue(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		} else {
+			sub = sel
+		}
+
+		member, err := dec.ReadValue()
+		if err != nil {
+			return nil, err
+		}
+		filtered, err := filterJSON(member, sub)
+		if err != nil {
+			return nil, err
+		}
+		if isObject {
+			if err := enc.WriteToken(jsontext.String(name)); err != nil {
+				return nil, err
+			}
+		}
+		if err := enc.WriteValue(filtered); err != nil {
+			return nil, err
+		}
+	}
+	closing, err := dec.ReadToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := enc.WriteToken(closing); err != nil {
+		return nil, err
+	}
+	return jsontext.Value(bytes.TrimRight(out.Bytes(), "\n")), nil
+}
+
+// bufferedJSONWriter 缓冲 JSON 响应以便在处理链结束后改写.
+// 遇到无法改写的响应时切换为直接输出
+type bufferedJSONWriter struct {
+	ResponseWriter
+	buf         bytes.Buffer
+	status      int
+	limit       int
+	passthrough bool
+}
+
+func (w *bufferedJSONWriter) WriteHeader(code int) {
+	if w.passthrough || (code >= 100 && code < 200) {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedJSONWriter) Write(b []byte) (int, error) {
+	if !w.passthrough {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if !isJSONContentType(w.Header().Get("Content-Type")) || w.buf.Len()+len(b) > w.limit {
+			if err := w.startPassthrough(); err != nil {
+				return 0, err
+			}
+		} else {
+			return w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// startPassthrough 将已缓冲的内容写出, 之后的写入直接透传
+func (w *bufferedJSONWriter) startPassthrough() error {
+	if w.passthrough {
+		return nil
+	}
+	w.passthrough = true
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() > 0 {
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+		return err
+	}
+	return nil
+}
+
+func (w *bufferedJSONWriter) Flush() {
+	// 流式响应无法改写, 直接回退
+	_ = w.startPassthrough()
+	w.ResponseWriter.Flush()
+}
+
+func (w *bufferedJSONWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.passthrough = true
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *bufferedJSONWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedJSONWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	return w.buf.Len()
+}
+
+func (w *bufferedJSONWriter) Written() bool {
+	if w.passthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.status != 0
+}
+
+// finish 改写并写出缓冲的响应
+func (w *bufferedJSONWriter) finish(fields fieldSelection, pretty bool) error {
+	if w.passthrough {
+		return nil
+	}
+	if w.status == 0 {
+		return nil
+	}
+	body := w.buf.Bytes()
+	if len(body) > 0 {
+		value := jsontext.Value(bytes.TrimSpace(body))
+		filtered, err := filterJSON(value, fields)
+		if err == nil && pretty {
+			err = filtered.Indent(jsontext.WithIndent("  "))
+		}
+		// 无法解析时原样输出
+		if err == nil {
+			body = filtered
+			if pretty {
+				body = append(body, '\n')
+			}
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(body) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,60 @@
+package touka
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPartialResponseFieldsAndPretty(t *testing.T) {
+	engine := New()
+	engine.Use(PartialResponse(PartialResponseOptions{}))
+	engine.GET("/users", func(c *Context) {
+		c.JSON(http.StatusOK, []H{
+			{"id": 1, "name": "iroha", "author": H{"name": "a", "email": "a@example.com"}, "secret": "x"},
+		})
+	})
+	engine.GET("/text", func(c *Context) {
+		c.String(http.StatusOK, "plain")
+	})
+
+	rr := PerformRequest(engine, http.MethodGet, "/users?fields=id,author.name", nil, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	body := rr.Body.String()
+	if strings.Contains(body, "secret") || strings.Contains(body, "email") || !strings.Contains(body, `"author":{"name":"a"}`) {
+		t.Fatalf("unexpected filtered body %s", body)
+	}
+	if got := rr.Header().Get("Content-Length"); got != strconv.Itoa(len(body)) {
+		t.Fatalf("expected Content-Length %d, got %s", len(body), got)
+	}
+
+	rr = PerformRequest(engine, http.MethodGet, "/users?pretty", nil, nil)
+	if !strings.Contains(rr.Body.String(), "\n  {") {
+		t.Fatalf("expected indented body, got %s", rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodGet, "/text?fields=id", nil, nil)
+	if rr.Body.String() != "plain" {
+		t.Fatalf("expected non-JSON response to pass through, got %q", rr.Body.String())
+	}
+}
+
+func TestPartialResponseStreamingFallback(t *testing.T) {
+	engine := New()
+	engine.Use(PartialResponse(PartialResponseOptions{}))
+	engine.GET("/stream", func(c *Context) {
+		c.Writer.Header().Set("Content-Type", "application/json")
+		c.Writer.WriteHeader(http.StatusOK)
+		c.Writer.Write([]byte(`{"a":1,`))
+		c.Writer.Flush()
+		c.Writer.Write([]byte(`"b":2}`))
+	})
+
+	rr := PerformRequest(engine, http.MethodGet, "/stream?fields=a", nil, nil)
+	if rr.Body.String() != `{"a":1,"b":2}` {
+		t.Fatalf("expected flushed response to be passed through, got %q", rr.Body.String())
+	}
+}
@@ -0,0 +1,155 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrPathNotAllowed 表示请求路径被 PathPolicy 拒绝
+var ErrPathNotAllowed = errors.New("request path not allowed")
+
+// EncodedSlashPolicy 控制路径中编码斜杠 (%2F) 的处理方式
+type EncodedSlashPolicy uint8
+
+const (
+	// EncodedSlashDecode 在路由前将 %2F 解码为 /, 与 net/http 的 URL.Path 一致 (默认)
+	EncodedSlashDecode EncodedSlashPolicy = iota
+	// EncodedSlashPreserve 路由时保留 %2F, 使 /files/:name 可以匹配 /files/a%2Fb,
+	// 参数值只解码一次, 其中的 %2F 解码为 /
+	EncodedSlashPreserve
+	// EncodedSlashReject 拒绝包含 %2F 的路径, 返回 400
+	EncodedSlashReject
+)
+
+// PathPolicy 控制路由前的路径规范化
+type PathPolicy struct {
+	// EncodedSlash 控制 %2F 的处理方式
+	EncodedSlash EncodedSlashPolicy
+	// CollapseSlashes 为 true 时, 路由前将连续的 / 合并为一个, 例如 //a///b 按 /a/b 路由
+	CollapseSlashes bool
+	// RejectDotSegments 为 true 时, 拒绝包含 . 或 .. 段 (包括编码形式) 的路径, 返回 400
+	RejectDotSegments bool
+	// NormalizeUnicode 为 true 时, 路由前将解码后的路径规范化为 Unicode NFC 形式,
+	// 使 /café 无论客户端发送预组合 (U+00E9) 还是分解 (e + U+0301) 的形式都能匹配, 路径参数也为 NFC 形式.
+	// 注册的路由路径需要是 NFC 形式, Validate 会报告不是的路由
+	NormalizeUnicode bool
+}
+
+// SetPathPolicy 设置路径规范化策略. 策略只影响路由匹配, 不会修改 c.Request.URL
+func (engine *Engine) SetPathPolicy(policy PathPolicy) {
+	engine.pathPolicy = policy
+}
+
+// checkPath 按 PathPolicy 检查请求路径
+func (engine *Engine) checkPath(req *http.Request) error {
+	policy := engine.pathPolicy
+	if req.URL == nil {
+		return nil
+	}
+	if policy.EncodedSlash == EncodedSlashReject && hasEncodedSlash(req.URL.EscapedPath()) {
+		return errors.Join(ErrPathNotAllowed, errors.New("encoded slash in path"))
+	}
+	if policy.RejectDotSegments && hasDotSegment(req.URL.Path) {
+		return errors.Join(ErrPathNotAllowed, errors.New("dot segment in path"))
+	}
+	return nil
+}
+
+// lookupPath 返回用于路由匹配的路径
+func (engine *Engine) lookupPath(req *http.Request) string {
+	path := routeLookupPath(req)
+	policy := engine.pathPolicy
+	if path == "" || req.URL == nil || req.Method == http.MethodConnect {
+		return path
+	}
+	if policy.EncodedSlash == EncodedSlashPreserve {
+		// 参数值随后会在 getValue 中解码一次
+		if escaped := req.URL.EscapedPath(); strings.IndexByte(escaped, '%') >= 0 {
+			path = decodeExceptSlash(escaped)
+		}
+	}
+	if policy.CollapseSlashes && strings.Contains(path, "//") {
+		path = collapseSlashes(path)
+	}
+	if policy.NormalizeUnicode && !isASCIIPath(path) {
+		path = norm.NFC.String(path)
+	}
+	return path
+}
+
+func isASCIIPath(path string) bool {
+	for i := 0; i < len(path); i++ {
+		if path[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePathPolicy 报告在 NormalizeUnicode 下永远无法匹配的路由
+func (engine *Engine) validatePathPolicy() []error {
+	if !engine.pathPolicy.NormalizeUnicode {
+		return nil
+	}
+	var errs []error
+	for _, r := range engine.routesInfo {
+		if !norm.NFC.IsNormalString(r.Path) {
+			errs = append(errs, fmt.Errorf("route %s %s is not in Unicode NFC form and cannot match normalized paths", r.Method, r.Path))
+		}
+	}
+	return errs
+}
+
+func hasEncodedSlash(escaped string) bool {
+	for i := 0; i+2 < len(escaped); i++ {
+		if escaped[i] == '%' && escaped[i+1] == '2' && (escaped[i+2] == 'F' || escaped[i+2] == 'f') {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeExceptSlash 逐段解码路径, 段内的 / 与 % 重新编码为 %2F 与 %25
+func decodeExceptSlash(escaped string) string {
+	segments := strings.Split(escaped, "/")
+	for i, seg := range segments {
+		decoded, err := url.PathUnescape(seg)
+		if err != nil {
+			return escaped
+		}
+		decoded = strings.ReplaceAll(decoded, "%", "%25")
+		segments[i] = strings.ReplaceAll(decoded, "/", "%2F")
+	}
+	return strings.Join(segments, "/")
+}
+
+func hasDotSegment(path string) bool {
+	for seg := range strings.SplitSeq(path, "/") {
+		if seg == "." || seg == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func collapseSlashes(path string) string {
+	var b strings.Builder
+	b.Grow(len(path))
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' && i > 0 && path[i-1] == '/' {
+			continue
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package touka
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newPathPolicyEngine(policy PathPolicy) *Engine {
+	engine := New()
+	engine.SetPathPolicy(policy)
+	engine.GET("/files/:name", func(c *Context) { c.String(http.StatusOK, "file:%s", c.Param("name")) })
+	engine.GET("/a/b", func(c *Context) { c.String(http.StatusOK, "ab") })
+	engine.GET("/static/*path", func(c *Context) { c.String(http.StatusOK, "static:%s", c.Param("path")) })
+	return engine
+}
+
+func TestPathPolicyEncodedSlash(t *testing.T) {
+	tests := []struct {
+		policy EncodedSlashPolicy
+		path   string
+		code   int
+		body   string
+	}{
+		{EncodedSlashDecode, "/files/a%2Fb", http.StatusNotFound, ""},
+		{EncodedSlashPreserve, "/files/a%2Fb", http.StatusOK, "file:a/b"},
+		{EncodedSlashPreserve, "/files/100%2525", http.StatusOK, "file:100%25"},
+		{EncodedSlashPreserve, "/files/plain", http.StatusOK, "file:plain"},
+		{EncodedSlashPreserve, "/static/x%2Fy/z", http.StatusOK, "static:/x/y/z"},
+		{EncodedSlashReject, "/files/a%2fb", http.StatusBadRequest, ""},
+		{EncodedSlashReject, "/files/ab", http.StatusOK, "file:ab"},
+	}
+	for _, tt := range tests {
+		engine := newPathPolicyEngine(PathPolicy{EncodedSlash: tt.policy})
+		w := PerformRequest(engine, http.MethodGet, tt.path, nil, nil)
+		if w.Code != tt.code {
+			t.Errorf("policy %d %s: expected %d, got %d", tt.policy, tt.path, tt.code, w.Code)
+			continue
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("policy %d %s: expected body %q, got %q", tt.policy, tt.path, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestPathPolicyCollapseSlashes(t *testing.T) {
+	serve := func(engine *Engine) *httptest.ResponseRecorder {
+		// 以绝对 URL 构造请求, 避免 //a 被解析为主机名
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com//a///b", nil))
+		return w
+	}
+	if w := serve(newPathPolicyEngine(PathPolicy{})); w.Code == http.StatusOK {
+		t.Fatalf("duplicate slashes should not match by default")
+	}
+
+	w := serve(newPathPolicyEngine(PathPolicy{CollapseSlashes: true}))
+	if w.Code != http.StatusOK || w.Body.String() != "ab" {
+		t.Fatalf("expected collapsed path to match, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestPathPolicyRejectDotSegments(t *testing.T) {
+	engine := newPathPolicyEngine(PathPolicy{RejectDotSegments: true})
+	for _, path := range []string{"/static/../etc/passwd", "/static/%2e%2e/etc", "/static/./x"} {
+		if w := PerformRequest(engine, http.MethodGet, path, nil, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, w.Code)
+		}
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/static/..hidden", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("names starting with dots should be allowed, got %d", w.Code)
+	}
+}
+
+func TestPathPolicyNormalizeUnicode(t *testing.T) {
+	newEngine := func(normalize bool) *Engine {
+		engine := New()
+		engine.SetPathPolicy(PathPolicy{NormalizeUnicode: normalize})
+		engine.GET("/café/:name", func(c *Context) { c.String(http.StatusOK, "%s|%s", c.Param("name"), c.FullPath()) })
+		return engine
+	}
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/caf%C3%A9/x", http.StatusOK, "x|/café/:name"},
+		{"/café/x", http.StatusOK, "x|/café/:name"},
+		{"/cafe%CC%81/x", http.StatusOK, "x|/café/:name"},
+		{"/caf%C3%A9/Jose%CC%81", http.StatusOK, "José|/café/:name"},
+	}
+	for _, tt := range tests {
+		w := PerformRequest(newEngine(true), http.MethodGet, tt.path, nil, nil)
+		if w.Code != tt.code || w.Body.String() != tt.body {
+			t.Errorf("%s: got %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+
+	if w := PerformRequest(newEngine(false), http.MethodGet, "/cafe%CC%81/x", nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("decomposed path should not match without normalization, got %d", w.Code)
+	}
+	w := PerformRequest(newEngine(true), http.MethodGet, "/CAFE%CC%81/x", nil, nil)
+	if loc, _ := url.PathUnescape(w.Header().Get("Location")); w.Code != http.StatusMovedPermanently || loc != "/café/x" {
+		t.Errorf("expected case-fixed redirect to /café/x, got %d %q", w.Code, loc)
+	}
+
+	engine := newEngine(true)
+	engine.GET("/cafe\u0301/menu", func(c *Context) {})
+	if err := engine.Validate(); err == nil || !strings.Contains(err.Error(), "not in Unicode NFC form") {
+		t.Errorf("Validate should report routes not in NFC form, got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 声明式策略: 分组通过 WithMeta 声明 CORS、认证与限流策略, 由全局注册一次的 Enforce* 中间件按匹配到的路由读取.
+// 子分组与路由继承上级的策略, 再次声明同一策略时以更具体的为准. 策略是普通的路由元数据,
+// 因此会出现在 GetRouterInfo 与 CLIListRoutes 的结果中:
+//
+//	r.Use(touka.EnforceCORS(), touka.EnforceAuth(authenticators), touka.EnforceRateLimit())
+//
+//	api := r.Group("/api").(touka.MetaRouter).
+//		WithMeta(touka.AllowCORS(touka.CORSPolicy{AllowOrigins: []string{"https://app.example.com"}})).
+//		WithMeta(touka.RequireAuth("apikey")).
+//		WithMeta(touka.Throttle(touka.RateLimitOptions{Limit: 100}))
+//	api.WithMeta(touka.AllowAnonymous()).GET("/health", health)
+
+// 策略的元数据键
+const (
+	MetaCORS      = "touka.cors"      // 值为 CORSPolicy
+	MetaAuth      = "touka.auth"      // 值为 AuthPolicy
+	MetaRateLimit = "touka.ratelimit" // 值为 RateLimitOptions
+)
+
+// CORSPolicy 是路由的跨域资源共享策略
+type CORSPolicy struct {
+	// AllowOrigins 为允许的来源, 例如 https://app.example.com; "*" 允许任意来源
+	AllowOrigins []string
+	// AllowMethods 为预检请求允许的方法, 为空时允许预检请求所询问的方法
+	AllowMethods []string
+	// AllowHeaders 为预检请求允许的请求头, 为空时允许预检请求所询问的请求头
+	AllowHeaders []string
+	// ExposeHeaders 为允许浏览器读取的响应头
+	ExposeHeaders []string
+	// AllowCredentials 允许携带 Cookie 等凭据; 此时 "*" 会回显请求的来源而不是返回 *
+	AllowCredentials bool
+	// MaxAge 为预检结果的缓存时长, 0 表示不设置
+	MaxAge time.Duration
+}
+
+// AuthPolicy 是路由的认证策略
+type AuthPolicy struct {
+	// Authenticator 为 EnforceAuth 中注册的认证器名称, 为空表示允许匿名访问
+	Authenticator string
+}
+
+// AllowCORS 返回声明 CORS 策略的元数据, 用于 WithMeta
+func AllowCORS(policy CORSPolicy) (string, any) {
+	if len(policy.AllowOrigins) == 0 {
+		panic("touka: CORS policy requires at least one allowed origin")
+	}
+	return MetaCORS, policy
+}
+
+// RequireAuth 返回声明认证策略的元数据, 用于 WithMeta. name 为 EnforceAuth 中注册的认证器名称
+func RequireAuth(name string) (string, any) {
+	if name == "" {
+		panic("touka: RequireAuth requires an authenticator name")
+	}
+	return MetaAuth, AuthPolicy{Authenticator: name}
+}
+
+// AllowAnonymous 返回允许匿名访问的认证策略, 用于在需要认证的分组中开放个别路由
+func AllowAnonymous() (string, any) {
+	return MetaAuth, AuthPolicy{}
+}
+
+// Throttle 返回声明限流策略的元数据, 用于 WithMeta. opts.Prefix 为空时每个路由单独计数,
+// 设置相同的 Prefix 可以让多个路由共享配额
+func Throttle(opts RateLimitOptions) (string, any) {
+	if opts.Limit <= 0 {
+		panic("touka: rate limit must be positive")
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	return MetaRateLimit, opts
+}
+
+// EnforceCORS 返回一个中间件, 按匹配路由上通过 AllowCORS 声明的策略处理跨域请求.
+// 预检请求 (带 Access-Control-Request-Method 的 OPTIONS) 按所询问方法对应的路由查找策略,
+// 允许时返回 204, 否则返回 403; 未声明策略的路由不受影响. 需要作为全局中间件注册, 才能处理未注册 OPTIONS 路由的预检请求
+func EnforceCORS() HandlerFunc {
+	return func(c *Context) {
+		origin := c.Request.Header.Get("Origin")
+		requestMethod := c.Request.Header.Get("Access-Control-Request-Method")
+		preflight := c.Request.Method == http.MethodOptions && requestMethod != ""
+
+		meta := c.RouteMeta()
+		if preflight && c.engine != nil {
+			meta = c.engine.lookupRouteMeta(requestMethod, c.engine.lookupPath(c.Request))
+		}
+		policy, ok := meta[MetaCORS].(CORSPolicy)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		allowOrigin, allowed := policy.allowOrigin(origin)
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if !allowed || (len(policy.AllowMethods) > 0 && !slices.Contains(policy.AllowMethods, requestMethod)) {
+				c.ErrorUseHandle(http.StatusForbidden, fmt.Errorf("cors: preflight from %q for %s is not allowed", origin, requestMethod))
+				return
+			}
+			policy.writeOriginHeaders(h, allowOrigin)
+			h.Set("Access-Control-Allow-Methods", strings.Join(cmpSlice(policy.AllowMethods, []string{requestMethod}), ", "))
+			if headers := cmpSlice(policy.AllowHeaders, splitHeaderList(c.Request.Header.Get("Access-Control-Request-Headers"))); len(headers) > 0 {
+				h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+			}
+			if policy.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+			}
+			c.Status(http.StatusNoContent)
+			c.Abort()
+			return
+		}
+
+		if allowed {
+			policy.writeOriginHeaders(h, allowOrigin)
+			if len(policy.ExposeHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposeHeaders, ", "))
+			}
+		}
+		c.Next()
+	}
+}
+
+// allowOrigin 返回 Access-Control-Allow-Origin 的值以及来源是否被允许
+func (p CORSPolicy) allowOrigin(origin string) (string, bool) {
+	for _, o := range p.AllowOrigins {
+		if o == "*" {
+			if p.AllowCredentials {
+				return origin, true
+			}
+			return "*", true
+		}
+		if strings.EqualFold(o, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+func (p CORSPolicy) writeOriginHeaders(h http.Header, allowOrigin string) {
+	h.Set("Access-Control-Allow-Origin", allowOrigin)
+	if p.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// cmpSlice 返回第一个非空切片
+func cmpSlice(a, b []string) []string {
+	if len(a) > 0 {
+		return a
+	}
+	return b
+}
+
+// splitHeaderList 拆分逗号分隔的头部列表
+func splitHeaderList(v string) []string {
+	var out []string
+	for part := range strings.SplitSeq(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// EnforceAuth 返回一个中间件, 按匹配路由上通过 RequireAuth 声明的认证器名称执行 authenticators 中对应的中间件,
+// 例如 APIKeyAuth 或 OAuth.RequireLogin 返回的处理器. 认证器调用 Next 表示通过, 否则应自行终止请求.
+// 未声明认证策略或声明了 AllowAnonymous 的路由直接放行; 策略引用了未注册的认证器时返回 500
+func EnforceAuth(authenticators map[string]HandlerFunc) HandlerFunc {
+	for name, h := range authenticators {
+		if name == "" || h == nil {
+			panic("touka: EnforceAuth requires named, non-nil authenticators")
+		}
+	}
+	return func(c *Context) {
+		policy, ok := c.RouteMeta()[MetaAuth].(AuthPolicy)
+		if !ok || policy.Authenticator == "" {
+			c.Next()
+			return
+		}
+		auth, ok := authenticators[policy.Authenticator]
+		if !ok {
+			err := fmt.Errorf("touka: route %s %s requires unknown authenticator %q", c.Request.Method, c.FullPath(), policy.Authenticator)
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+		runSubChain(c, HandlersChain{auth})
+	}
+}
+
+// EnforceRateLimit 返回一个中间件, 按匹配路由上通过 Throttle 声明的策略限流, 行为与 RateLimit 相同.
+// 策略未设置 Prefix 时以方法与路由模式区分计数
+func EnforceRateLimit() HandlerFunc {
+	return func(c *Context) {
+		opts, ok := c.RouteMeta()[MetaRateLimit].(RateLimitOptions)
+		if !ok {
+			c.Next()
+			return
+		}
+		if opts.Prefix == "" {
+			opts.Prefix = "ratelimit:" + c.Request.Method + " " + c.FullPath() + ":"
+		}
+		limitRequest(c, opts)
+	}
+}
+
+// lookupRouteMeta 返回 method 与请求路径匹配到的路由的元数据
+func (engine *Engine) lookupRouteMeta(method, path string) RouteMeta {
+	if len(engine.routeMeta) == 0 {
+		return nil
+	}
+	for _, tree := range engine.methodTrees {
+		if tree.method != method {
+			continue
+		}
+		skipped := GetTempSkippedNodes()
+		*skipped = (*skipped)[:0]
+		var params Params
+		value := tree.root.getValue(path, &params, skipped, false)
+		PutTempSkippedNodes(skipped)
+		engine.resolveRouteVariant(method, &value, &params)
+		if value.handlers == nil {
+			return nil
+		}
+		return engine.routeMeta[routeKey{method: method, path: value.fullPath}]
+	}
+	return nil
+}
+
+// policySummary 返回路由上声明的策略的简短描述, 用于路由列表
+func policySummary(meta RouteMeta) string {
+	var parts []string
+	if p, ok := meta[MetaCORS].(CORSPolicy); ok {
+		parts = append(parts, "cors="+strings.Join(p.AllowOrigins, ","))
+	}
+	if p, ok := meta[MetaAuth].(AuthPolicy); ok {
+		parts = append(parts, "auth="+cmp.Or(p.Authenticator, "anonymous"))
+	}
+	if p, ok := meta[MetaRateLimit].(RateLimitOptions); ok {
+		parts = append(parts, fmt.Sprintf("ratelimit=%d/%s", p.Limit, p.Window))
+	}
+	return strings.Join(parts, " ")
+}
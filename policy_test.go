@@ -0,0 +1,116 @@
+package touka
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func policyEngine() *Engine {
+	r := New()
+	r.Use(EnforceCORS(), EnforceAuth(map[string]HandlerFunc{
+		"token": func(c *Context) {
+			if c.Request.Header.Get("Authorization") != "Bearer secret" {
+				c.ErrorUseHandle(http.StatusUnauthorized, ErrAPIKeyInvalid)
+				return
+			}
+			c.Next()
+		},
+	}), EnforceRateLimit())
+
+	api := r.Group("/api").(MetaRouter).
+		WithMeta(AllowCORS(CORSPolicy{AllowOrigins: []string{"https://app.example.com"}, ExposeHeaders: []string{"X-Total"}, MaxAge: time.Hour})).
+		WithMeta(RequireAuth("token"))
+	api.GET("/items/:id", func(c *Context) { c.String(http.StatusOK, "item") })
+	api.WithMeta(AllowAnonymous()).GET("/health", func(c *Context) { c.String(http.StatusOK, "ok") })
+	api.WithMeta(Throttle(RateLimitOptions{Limit: 1})).GET("/limited", func(c *Context) { c.String(http.StatusOK, "ok") })
+	api.WithMeta(RequireAuth("missing")).GET("/misconfigured", func(c *Context) {})
+	r.GET("/public", func(c *Context) { c.String(http.StatusOK, "public") })
+	return r
+}
+
+func TestPolicyAuthInheritance(t *testing.T) {
+	r := policyEngine()
+	auth := http.Header{"Authorization": {"Bearer secret"}}
+
+	if w := PerformRequest(r, http.MethodGet, "/api/items/1", nil, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated request: got %d", w.Code)
+	}
+	if w := PerformRequest(r, http.MethodGet, "/api/items/1", nil, auth); w.Code != http.StatusOK || w.Body.String() != "item" {
+		t.Errorf("authenticated request: got %d %q", w.Code, w.Body.String())
+	}
+	if w := PerformRequest(r, http.MethodGet, "/api/health", nil, nil); w.Code != http.StatusOK {
+		t.Errorf("AllowAnonymous should override the group policy, got %d", w.Code)
+	}
+	if w := PerformRequest(r, http.MethodGet, "/public", nil, nil); w.Code != http.StatusOK {
+		t.Errorf("routes without policy should pass, got %d", w.Code)
+	}
+	if w := PerformRequest(r, http.MethodGet, "/api/misconfigured", nil, auth); w.Code != http.StatusInternalServerError {
+		t.Errorf("unknown authenticator: got %d", w.Code)
+	}
+}
+
+func TestPolicyRateLimit(t *testing.T) {
+	r := policyEngine()
+	auth := http.Header{"Authorization": {"Bearer secret"}}
+	if w := PerformRequest(r, http.MethodGet, "/api/limited", nil, auth); w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "1" {
+		t.Fatalf("first request: %d %v", w.Code, w.Header())
+	}
+	if w := PerformRequest(r, http.MethodGet, "/api/limited", nil, auth); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second request: got %d", w.Code)
+	}
+	// 其他路由不共享该配额, 也没有限流头部
+	if w := PerformRequest(r, http.MethodGet, "/api/items/1", nil, auth); w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "" {
+		t.Errorf("unthrottled route: %d %v", w.Code, w.Header())
+	}
+}
+
+func TestPolicyCORS(t *testing.T) {
+	r := policyEngine()
+
+	preflight := http.Header{
+		"Origin":                         {"https://app.example.com"},
+		"Access-Control-Request-Method":  {"GET"},
+		"Access-Control-Request-Headers": {"Authorization, X-Trace"},
+	}
+	w := PerformRequest(r, http.MethodOptions, "/api/items/7", nil, preflight)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("preflight: got %d", w.Code)
+	}
+	h := w.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://app.example.com" || h.Get("Access-Control-Allow-Methods") != "GET" ||
+		h.Get("Access-Control-Allow-Headers") != "Authorization, X-Trace" || h.Get("Access-Control-Max-Age") != "3600" {
+		t.Errorf("preflight headers: %v", h)
+	}
+
+	preflight.Set("Origin", "https://evil.example.com")
+	if w := PerformRequest(r, http.MethodOptions, "/api/items/7", nil, preflight); w.Code != http.StatusForbidden {
+		t.Errorf("disallowed origin preflight: got %d", w.Code)
+	}
+
+	w = PerformRequest(r, http.MethodGet, "/api/health", nil, http.Header{"Origin": {"https://app.example.com"}})
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || w.Header().Get("Access-Control-Expose-Headers") != "X-Total" {
+		t.Errorf("simple request headers: %v", w.Header())
+	}
+	w = PerformRequest(r, http.MethodGet, "/public", nil, http.Header{"Origin": {"https://app.example.com"}})
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("route without CORS policy should not get CORS headers: %v", w.Header())
+	}
+}
+
+func TestPolicyShowsInRouteList(t *testing.T) {
+	var buf bytes.Buffer
+	if err := CLIListRoutes(policyEngine(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	for line := range strings.SplitSeq(buf.String(), "\n") {
+		if strings.Contains(line, "/api/limited") && !strings.Contains(line, "auth=token ratelimit=1/1m0s") {
+			t.Errorf("policy column missing: %q", line)
+		}
+		if strings.Contains(line, "/api/health") && !strings.Contains(line, "auth=anonymous") {
+			t.Errorf("policy column missing: %q", line)
+		}
+	}
+}
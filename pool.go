@@ -0,0 +1,110 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import "sync/atomic"
+
+// poolCounters 统计对象池的使用情况, 命中率为 1 - news/gets
+type poolCounters struct {
+	gets    atomic.Uint64
+	puts    atomic.Uint64
+	news    atomic.Uint64
+	trimmed atomic.Uint64
+}
+
+func (p *poolCounters) snapshot() PoolStats {
+	return PoolStats{Gets: p.gets.Load(), Puts: p.puts.Load(), News: p.news.Load(), Trimmed: p.trimmed.Load()}
+}
+
+var (
+	ecwPoolCounters          poolCounters // errorResponseWriterPool
+	skippedNodesPoolCounters poolCounters // TempSkippedNodesPool
+
+	// poolStatsEnabled 控制是否统计进程内共享对象池的取用次数, 任一引擎调用 EnableStats 后开启
+	poolStatsEnabled atomic.Bool
+)
+
+// PoolStats 是对象池的累计统计
+type PoolStats struct {
+	Gets    uint64 // 从池中取出的次数
+	Puts    uint64 // 放回池中的次数
+	News    uint64 // 池为空时新建对象的次数
+	Trimmed uint64 // 放回前因缓冲区超出上限而被释放缓冲区的次数
+}
+
+// HitRatio 返回由池中已有对象满足的取用比例
+func (s PoolStats) HitRatio() float64 {
+	if s.Gets == 0 || s.News > s.Gets {
+		return 0
+	}
+	return float64(s.Gets-s.News) / float64(s.Gets)
+}
+
+// PoolOptions 配置引擎的对象池
+type PoolOptions struct {
+	// Prewarm 为预先创建并放入池中的 Context 与 ecw 对象数, 用于削减启动后第一波流量的分配.
+	// sync.Pool 可能在 GC 时丢弃对象, 预热只影响启动初期
+	Prewarm int
+	// MaxRetainedSliceCap 限制 Context 放回池中时保留的 Params、SkippedNodes 与 Errors 切片容量,
+	// 超出时释放该切片, 避免个别异常请求撑大的缓冲区被长期持有; 0 表示不限制.
+	// 低于默认容量 (Params 为路由的最大参数数, SkippedNodes 为 256) 时按默认容量处理
+	MaxRetainedSliceCap int
+}
+
+// SetPoolOptions 配置引擎的对象池, 应在启动服务前调用
+func (engine *Engine) SetPoolOptions(opts PoolOptions) {
+	engine.poolMaxSliceCap = max(opts.MaxRetainedSliceCap, 0)
+	if opts.Prewarm > 0 {
+		contexts := make([]*Context, opts.Prewarm)
+		writers := make([]*errorCapturingResponseWriter, opts.Prewarm)
+		for i := range opts.Prewarm {
+			contexts[i] = engine.pool.Get().(*Context)
+			writers[i] = errorResponseWriterPool.Get().(*errorCapturingResponseWriter)
+		}
+		for i := range opts.Prewarm {
+			engine.pool.Put(contexts[i])
+			errorResponseWriterPool.Put(writers[i])
+		}
+	}
+}
+
+// PoolStats 返回引擎使用的对象池统计, 键为 context、ecw 与 skipped_nodes.
+// ecw 与 skipped_nodes 为进程内所有引擎共享. Gets 与 Puts 只在调用 EnableStats 后统计
+func (engine *Engine) PoolStats() map[string]PoolStats {
+	return map[string]PoolStats{
+		"context":       engine.ctxPoolCounters.snapshot(),
+		"ecw":           ecwPoolCounters.snapshot(),
+		"skipped_nodes": skippedNodesPoolCounters.snapshot(),
+	}
+}
+
+// releaseContext 将 Context 放回池中, 停止 OnClientGone 的监听, 并按 MaxRetainedSliceCap 释放过大的切片
+func (engine *Engine) releaseContext(c *Context) {
+	if c.rootWriter != nil && c.rootWriter.gone != nil {
+		c.rootWriter.gone.close()
+	}
+	if limit := engine.poolMaxSliceCap; limit > 0 {
+		trimmed := false
+		if cap(c.Params) > max(limit, int(engine.maxParams)) {
+			c.Params = nil
+			trimmed = true
+		}
+		if cap(c.SkippedNodes) > max(limit, MaxSkippedNodesCap) {
+			c.SkippedNodes = nil
+			trimmed = true
+		}
+		if cap(c.Errors) > limit {
+			c.Errors = nil
+			trimmed = true
+		}
+		if trimmed {
+			engine.ctxPoolCounters.trimmed.Add(1)
+		}
+	}
+	if engine.statsEnabled {
+		engine.ctxPoolCounters.puts.Add(1)
+	}
+	engine.pool.Put(c)
+}
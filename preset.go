@@ -0,0 +1,190 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fenthope/reco"
+)
+
+// Mode 表示引擎的运行模式
+type Mode string
+
+const (
+	DebugMode   Mode = "debug"   // 开发模式: 调试日志, 模板热重载
+	ReleaseMode Mode = "release" // 生产模式: Info 级别 JSON 日志
+	TestMode    Mode = "test"    // 测试模式: 仅输出警告及以上日志
+)
+
+// EnvironmentVar 是设置部署环境的环境变量, New 创建引擎时读取
+const EnvironmentVar = "TOUKA_ENV"
+
+// 常用的部署环境名称, 也可以使用任意自定义名称
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+)
+
+// 生产预设的服务器超时. 不设置 WriteTimeout, 以免截断 SSE 等长连接响应
+const (
+	productionReadHeaderTimeout = 10 * time.Second
+	productionReadTimeout       = 30 * time.Second
+	productionIdleTimeout       = 120 * time.Second
+)
+
+// NewProduction 创建适合生产环境的 Engine:
+//   - ReleaseMode, Info 级别 JSON 格式日志
+//   - Recovery 中间件
+//   - 服务器的 ReadHeaderTimeout/ReadTimeout/IdleTimeout 默认值 (可被 ServerConfigurator 覆盖)
+func NewProduction() *Engine {
+	engine := New()
+	engine.SetMode(ReleaseMode)
+	engine.SetServerConfigurator(productionServerDefaults)
+	engine.Use(Recovery())
+	return engine
+}
+
+// NewDevelopment 创建适合本地开发的 Engine:
+//   - DebugMode, Debug 级别文本日志
+//   - Recovery 中间件
+//   - 通过 LoadHTMLGlob 加载的模板在每次渲染时重新解析
+//   - 注册 GET /debug/routes, 以 JSON 列出已注册的路由
+func NewDevelopment() *Engine {
+	engine := New()
+	engine.SetMode(DebugMode)
+	engine.Use(Recovery())
+	engine.GET("/debug/routes", func(c *Context) {
+		c.JSON(http.StatusOK, c.engine.GetRouterInfo())
+	})
+	return engine
+}
+
+// productionServerDefaults 只填充未设置的超时
+func productionServerDefaults(srv *http.Server) {
+	if srv.ReadHeaderTimeout == 0 {
+		srv.ReadHeaderTimeout = productionReadHeaderTimeout
+	}
+	if srv.ReadTimeout == 0 {
+		srv.ReadTimeout = productionReadTimeout
+	}
+	if srv.IdleTimeout == 0 {
+		srv.IdleTimeout = productionIdleTimeout
+	}
+}
+
+// SetMode 设置运行模式, 并相应调整日志级别与格式以及模板热重载.
+// 日志调整仅对默认的 reco 日志实现生效
+func (engine *Engine) SetMode(mode Mode) {
+	var level reco.Level
+	var output reco.OutputMode
+	switch mode {
+	case DebugMode:
+		level, output = reco.LevelDebug, reco.ModeText
+	case ReleaseMode:
+		level, output = reco.LevelInfo, reco.ModeJSON
+	case TestMode:
+		level, output = reco.LevelWarn, reco.ModeText
+	default:
+		panic("touka: unknown mode " + string(mode))
+	}
+	engine.mode = mode
+	engine.templateReload = mode == DebugMode
+	if engine.LogReco != nil {
+		engine.LogReco.SetLevel(level)
+		engine.LogReco.SetOutputMode(output)
+	}
+}
+
+// Mode 返回当前运行模式, 未设置时为 DebugMode
+func (engine *Engine) Mode() Mode {
+	if engine.mode == "" {
+		return DebugMode
+	}
+	return engine.mode
+}
+
+// SetEnvironment 设置部署环境, 例如 production 或 staging, 名称不区分大小写. 传入空字符串恢复默认
+func (engine *Engine) SetEnvironment(env string) {
+	engine.environment = env
+}
+
+// Environment 返回部署环境 (小写). 依次取自 SetEnvironment、创建引擎时的 TOUKA_ENV 环境变量,
+// 都未设置时由运行模式推导: DebugMode 为 development, ReleaseMode 为 production, TestMode 为 test
+func (engine *Engine) Environment() string {
+	if engine.environment != "" {
+		return strings.ToLower(engine.environment)
+	}
+	switch engine.Mode() {
+	case ReleaseMode:
+		return EnvProduction
+	case TestMode:
+		return EnvTest
+	}
+	return EnvDevelopment
+}
+
+// OnlyIn 将中间件限定在指定的部署环境中, 其他环境下直接跳过, 适用于请求转储、故障注入、pprof 等调试用中间件:
+//
+//	r.Use(touka.OnlyIn(touka.EnvDevelopment, touka.EnvStaging)(dumpRequests, chaos))
+//
+// 环境在每个请求开始处理时判断, 因此可以在注册路由之后再调用 SetEnvironment
+func OnlyIn(envs ...string) func(handlers ...HandlerFunc) HandlerFunc {
+	envs = slices.Clone(envs)
+	for i, env := range envs {
+		envs[i] = strings.ToLower(env)
+	}
+	return func(handlers ...HandlerFunc) HandlerFunc {
+		chain := slices.Clone(HandlersChain(handlers))
+		return func(c *Context) {
+			if len(chain) == 0 || c.engine == nil || !slices.Contains(envs, c.engine.Environment()) {
+				c.Next()
+				return
+			}
+			runSubChain(c, chain)
+		}
+	}
+}
+
+// SetTemplateReload 设置是否在每次渲染时重新解析通过 LoadHTMLGlob 加载的模板
+func (engine *Engine) SetTemplateReload(enable bool) {
+	engine.templateReload = enable
+}
+
+// SetFuncMap 设置 LoadHTMLGlob 解析模板时注册的函数, 需在 LoadHTMLGlob 之前调用.
+// 内置的模板函数 (例如 flashes、fragment、cspNonce、sriAttr 与 sanitize) 总是可用, 同名时以 funcMap 为准.
+// 日期、大小、地址等常用函数可以通过 UseTemplateFuncs 启用
+func (engine *Engine) SetFuncMap(funcMap template.FuncMap) {
+	engine.funcMap = funcMap
+}
+
+// LoadHTMLGlob 解析匹配 pattern 的模板并设置为 HTMLRender, 解析失败时 panic.
+// 开启模板热重载时, 每次渲染都会重新解析, 修改模板无需重启
+func (engine *Engine) LoadHTMLGlob(pattern string) {
+	engine.HTMLRender = template.Must(engine.parseGlob(pattern))
+	engine.htmlGlob = pattern
+}
+
+func (engine *Engine) parseGlob(pattern string) (*template.Template, error) {
+	return template.New("").Funcs(FlashFuncMap()).Funcs(CacheFuncMap()).Funcs(CSPFuncMap()).Funcs(engine.assetFuncMap()).Funcs(engine.sanitizeFuncMap()).Funcs(engine.tplFuncs).Funcs(engine.funcMap).ParseGlob(pattern)
+}
+
+// htmlTemplate 返回用于渲染的模板
+func (engine *Engine) htmlTemplate() (*template.Template, error) {
+	tpl, ok := engine.HTMLRender.(*template.Template)
+	if !ok {
+		return nil, nil
+	}
+	if engine.templateReload && engine.htmlGlob != "" {
+		return engine.parseGlob(engine.htmlGlob)
+	}
+	return tpl, nil
+}
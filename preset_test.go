@@ -0,0 +1,144 @@
+package touka
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fenthope/reco"
+)
+
+func TestSetModeAdjustsLogger(t *testing.T) {
+	engine := New()
+	if engine.Mode() != DebugMode {
+		t.Fatalf("expected default mode %q, got %q", DebugMode, engine.Mode())
+	}
+
+	engine.SetMode(ReleaseMode)
+	if engine.LogReco.GetLevel() != reco.LevelInfo || engine.LogReco.GetOutputMode() != reco.ModeJSON {
+		t.Fatalf("release mode should use info level JSON logs")
+	}
+	engine.SetMode(TestMode)
+	if engine.LogReco.GetLevel() != reco.LevelWarn {
+		t.Fatalf("test mode should use warn level logs")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for unknown mode")
+		}
+	}()
+	engine.SetMode("staging")
+}
+
+func TestNewProductionServerDefaults(t *testing.T) {
+	engine := NewProduction()
+	if engine.Mode() != ReleaseMode {
+		t.Fatalf("expected release mode, got %q", engine.Mode())
+	}
+
+	srv := &http.Server{ReadTimeout: time.Second}
+	applyMainServerConfig(engine, srv, false)
+	if srv.ReadTimeout != time.Second {
+		t.Fatalf("explicit ReadTimeout should be kept, got %v", srv.ReadTimeout)
+	}
+	if srv.ReadHeaderTimeout != productionReadHeaderTimeout || srv.IdleTimeout != productionIdleTimeout {
+		t.Fatalf("unexpected timeouts: header=%v idle=%v", srv.ReadHeaderTimeout, srv.IdleTimeout)
+	}
+	if srv.WriteTimeout != 0 {
+		t.Fatalf("WriteTimeout should stay unset, got %v", srv.WriteTimeout)
+	}
+
+	engine.GET("/panic", func(c *Context) { panic("boom") })
+	w := PerformRequest(engine, http.MethodGet, "/panic", nil, nil)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected recovery to return 500, got %d", w.Code)
+	}
+}
+
+func TestNewDevelopmentDebugRoutes(t *testing.T) {
+	engine := NewDevelopment()
+	engine.GET("/users/:id", func(c *Context) {})
+
+	w := PerformRequest(engine, http.MethodGet, "/debug/routes", nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "/users/:id") {
+		t.Fatalf("expected route listing, got %s", w.Body.String())
+	}
+}
+
+func TestLoadHTMLGlobReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "index.html")
+	writeTemplate := func(content string) {
+		if err := os.WriteFile(file, []byte(`{{define "index"}}`+content+`{{end}}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, reload := range []bool{false, true} {
+		writeTemplate("v1")
+		engine := New()
+		engine.SetTemplateReload(reload)
+		engine.LoadHTMLGlob(filepath.Join(dir, "*.html"))
+		engine.GET("/", func(c *Context) { c.HTMLBuf(http.StatusOK, "index", nil) })
+
+		writeTemplate("v2")
+		w := PerformRequest(engine, http.MethodGet, "/", nil, nil)
+		want := "v1"
+		if reload {
+			want = "v2"
+		}
+		if w.Body.String() != want {
+			t.Fatalf("reload=%v: expected %q, got %q", reload, want, w.Body.String())
+		}
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	t.Setenv(EnvironmentVar, "")
+	engine := New()
+	if env := engine.Environment(); env != EnvDevelopment {
+		t.Fatalf("expected debug mode to default to development, got %q", env)
+	}
+	engine.SetMode(ReleaseMode)
+	if env := engine.Environment(); env != EnvProduction {
+		t.Fatalf("expected release mode to default to production, got %q", env)
+	}
+	engine.SetEnvironment("Staging")
+	if env := engine.Environment(); env != EnvStaging {
+		t.Fatalf("expected explicit environment, got %q", env)
+	}
+
+	t.Setenv(EnvironmentVar, "qa")
+	if env := New().Environment(); env != "qa" {
+		t.Fatalf("expected %s to configure the environment, got %q", EnvironmentVar, env)
+	}
+}
+
+func TestOnlyIn(t *testing.T) {
+	engine := New()
+	engine.SetEnvironment(EnvProduction)
+	debugOnly := OnlyIn(EnvDevelopment, EnvStaging)
+	engine.Use(debugOnly(
+		func(c *Context) { c.SetHeader("X-Debug", "1"); c.Next() },
+		func(c *Context) { c.SetHeader("X-Chaos", "1"); c.Next() },
+	))
+	engine.GET("/", func(c *Context) { c.Status(http.StatusNoContent) })
+
+	w := PerformRequest(engine, http.MethodGet, "/", nil, nil)
+	if w.Code != http.StatusNoContent || w.Header().Get("X-Debug") != "" {
+		t.Fatalf("debug middleware must be skipped in production: %d %v", w.Code, w.Header())
+	}
+
+	engine.SetEnvironment("STAGING")
+	w = PerformRequest(engine, http.MethodGet, "/", nil, nil)
+	if w.Code != http.StatusNoContent || w.Header().Get("X-Debug") != "1" || w.Header().Get("X-Chaos") != "1" {
+		t.Fatalf("debug middleware must run in staging: %d %v", w.Code, w.Header())
+	}
+}
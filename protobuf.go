@@ -0,0 +1,45 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtoBuf 向响应写入 protobuf 二进制编码的消息, Content-Type 为 application/x-protobuf.
+// 先完整编码再写入响应, 编码失败时通过 ErrorUseHandle 返回 500
+func (c *Context) ProtoBuf(code int, msg proto.Message) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		c.renderError(fmt.Errorf("failed to marshal protobuf: %w", err))
+		return
+	}
+	c.Writer.Header().Set("Content-Type", grpcGatewayContentTypeProtobuf)
+	c.Writer.WriteHeader(code)
+	c.writeResponseBody(data, "failed to write protobuf response")
+}
+
+// ShouldBindProtobuf 将 protobuf 二进制编码的请求体解码到 msg, 请求体大小受 MaxRequestBodySize 限制
+func (c *Context) ShouldBindProtobuf(msg proto.Message) error {
+	data, err := c.readBindBody()
+	if err != nil {
+		return fmt.Errorf("protobuf binding error: %w", err)
+	}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("protobuf binding error: %w", err)
+	}
+	return nil
+}
+
+// shouldBindProtobufAny 供 ShouldBind 按 Content-Type 调用, obj 必须实现 proto.Message
+func (c *Context) shouldBindProtobufAny(obj any) error {
+	msg, ok := obj.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf binding error: %T does not implement proto.Message", obj)
+	}
+	return c.ShouldBindProtobuf(msg)
+}
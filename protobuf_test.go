@@ -0,0 +1,68 @@
+package touka
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtoBufRender(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.ProtoBuf(http.StatusCreated, wrapperspb.String("touka"))
+
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("code=%d content-type=%q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var got wrapperspb.StringValue
+	if err := proto.Unmarshal(w.Body.Bytes(), &got); err != nil || got.GetValue() != "touka" {
+		t.Errorf("decoded %q, err %v", got.GetValue(), err)
+	}
+}
+
+func TestShouldBindProtobuf(t *testing.T) {
+	data, err := proto.Marshal(wrapperspb.Int64(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, contentType := range []string{"application/x-protobuf", "application/protobuf"} {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+		req.Header.Set("Content-Type", contentType)
+		c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+		var msg wrapperspb.Int64Value
+		if err := c.ShouldBind(&msg); err != nil || msg.GetValue() != 42 {
+			t.Errorf("%s: value=%d err=%v", contentType, msg.GetValue(), err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+	var notProto struct{ Value int64 }
+	if err := c.ShouldBind(&notProto); err == nil || !strings.Contains(err.Error(), "proto.Message") {
+		t.Errorf("expected proto.Message error, got %v", err)
+	}
+}
+
+func TestShouldBindProtobufErrors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte{0x0a, 0x05, 'a'}))
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+	var msg wrapperspb.StringValue
+	if err := c.ShouldBindProtobuf(&msg); err == nil {
+		t.Error("expected error for truncated message")
+	}
+
+	data, _ := proto.Marshal(wrapperspb.String(strings.Repeat("x", 64)))
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	c, _ = CreateTestContextWithRequest(httptest.NewRecorder(), req)
+	c.SetMaxRequestBodySize(16)
+	if err := c.ShouldBindProtobuf(&msg); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("expected ErrBodyTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrRateLimited 表示请求超出限流配额
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitOptions 配置 RateLimit 中间件
+type RateLimitOptions struct {
+	// Limit 为每个窗口允许的请求数, 必填
+	Limit int
+	// Window 为窗口时长, 默认 1 分钟
+	Window time.Duration
+	// KeyFunc 计算限流键, 默认使用客户端 IP
+	KeyFunc func(c *Context) string
+	// Prefix 为限流键的前缀, 用于区分多个 RateLimit 实例, 默认 ratelimit:
+	Prefix string
+	// FailClosed 为 true 时, Limiter 出错会拒绝请求 (返回 503); 默认放行并记录错误
+	FailClosed bool
+}
+
+// RateLimit 返回一个通过引擎的 Limiter 限流的中间件.
+// 响应携带 X-RateLimit-Limit/Remaining/Reset 头部, 超出配额时返回 429 并设置 Retry-After
+func RateLimit(opts RateLimitOptions) HandlerFunc {
+	if opts.Limit <= 0 {
+		panic("touka: rate limit must be positive")
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "ratelimit:"
+	}
+
+	return func(c *Context) {
+		limitRequest(c, opts)
+	}
+}
+
+// limitRequest 按 opts 对当前请求计数, 放行时调用 c.Next
+func limitRequest(c *Context, opts RateLimitOptions) {
+	key := c.ClientIP()
+	if opts.KeyFunc != nil {
+		key = opts.KeyFunc(c)
+	}
+	result, err := c.Limit(opts.Prefix+key, opts.Limit, opts.Window)
+	if err != nil {
+		err = fmt.Errorf("rate limiter: %w", err)
+		if opts.FailClosed {
+			c.ErrorUseHandle(http.StatusServiceUnavailable, err)
+			return
+		}
+		c.AddError(err)
+		c.Next()
+		return
+	}
+
+	if !applyLimitResult(c, result) {
+		return
+	}
+	c.Next()
+}
+
+// applyLimitResult 设置 X-RateLimit-* 头部, 超出配额时设置 Retry-After 并返回 429, 返回是否放行
+func applyLimitResult(c *Context, result LimitResult) bool {
+	reset := strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds())))
+	c.SetHeader("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.SetHeader("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.SetHeader("X-RateLimit-Reset", reset)
+	if !result.Allowed {
+		c.SetHeader("Retry-After", reset)
+		c.ErrorUseHandle(http.StatusTooManyRequests, ErrRateLimited)
+		return false
+	}
+	return true
+}
@@ -6,6 +6,7 @@ package touka
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net"
@@ -77,9 +78,16 @@ func defaultPanicHandler(c *Context, r any) {
 		}
 	}
 	redactedRequest := strings.Join(headers, "\r\n")
+	panicErr := NewPanicError(r)
 	// 使用英文记录日志
 	log.Printf("[Recovery] Panic recovered:\nPanic: %v\nRequest:\n%s\nStack:\n%s",
-		r, redactedRequest, string(debug.Stack()))
+		r, redactedRequest, string(panicErr.Stack))
+
+	var handlerErr error = errPanicInternal
+	if c.engine != nil && c.engine.panicAsError {
+		c.AddError(panicErr)
+		handlerErr = panicErr
+	}
 
 	// 在发送 500 错误响应之前，检查响应是否已经开始写入
 	// 如果 c.Writer.Written() 返回 true，说明响应头已经发送，
@@ -94,7 +102,7 @@ func defaultPanicHandler(c *Context, r any) {
 	// 尝试发送 500 Internal Server Error 响应
 	// 使用框架提供的统一错误处理器（如果可用）
 	if c.engine != nil && c.engine.errorHandle.handler != nil {
-		c.engine.errorHandle.handler(c, http.StatusInternalServerError, errors.New("Internal Panic Error"))
+		c.engine.errorHandle.handler(c, http.StatusInternalServerError, handlerErr)
 	} else {
 		// 如果框架错误处理器不可用，提供一个备用的简单响应
 		// 返回英文错误信息
@@ -105,6 +113,34 @@ func defaultPanicHandler(c *Context, r any) {
 	c.Abort()
 }
 
+// errPanicInternal 是 panic 未转换为 *PanicError 时交给 ErrorHandler 的错误
+var errPanicInternal = errors.New("Internal Panic Error")
+
+// PanicError 是由恢复的 panic 转换而来的错误, 携带 panic 时的堆栈.
+// 其 Error() 包含 panic 的值, 可能含有内部信息, 不应直接返回给客户端
+type PanicError struct {
+	Value any    // recover() 返回的值
+	Stack []byte // panic 时的 goroutine 堆栈
+}
+
+// NewPanicError 以 recover() 返回的值创建 PanicError, 并记录当前堆栈.
+// 应在 defer 的 recover 所在函数中调用, 以保留引发 panic 的调用栈
+func NewPanicError(value any) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap 在 panic 的值是 error 时返回它
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
 // isBrokenPipeError 检查 recover() 捕获的值是否表示一个由客户端断开连接引起的网络错误
 // 这对于防止在已关闭的连接上写入响应至关重要
 func isBrokenPipeError(r any) bool {
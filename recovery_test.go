@@ -0,0 +1,52 @@
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPanicAsError(t *testing.T) {
+	engine := New()
+	engine.Use(Recovery())
+	engine.SetPanicAsError(true)
+
+	var got error
+	engine.SetErrorHandler(func(c *Context, code int, err error) {
+		got = err
+		c.String(code, "handled")
+	})
+	engine.GET("/panic", func(c *Context) {
+		panic(errors.New("boom"))
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/panic", nil, nil)
+	if w.Code != http.StatusInternalServerError || w.Body.String() != "handled" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	var panicErr *PanicError
+	if !errors.As(got, &panicErr) {
+		t.Fatalf("expected *PanicError, got %T", got)
+	}
+	if panicErr.Error() != "panic: boom" || !strings.Contains(string(panicErr.Stack), "TestPanicAsError") {
+		t.Fatalf("unexpected panic error %q", panicErr.Error())
+	}
+	if _, server := engine.ErrorCounts(); server != 1 {
+		t.Fatalf("expected the panic to be recorded as a server error, got %d", server)
+	}
+}
+
+func TestPanicAsErrorDefaultHandlerHidesValue(t *testing.T) {
+	engine := New()
+	engine.Use(Recovery())
+	engine.SetPanicAsError(true)
+	engine.GET("/panic", func(c *Context) {
+		panic("secret dsn")
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/panic", nil, nil)
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "secret") {
+		t.Fatalf("panic value leaked to client: %d %q", w.Code, w.Body.String())
+	}
+}
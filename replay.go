@@ -0,0 +1,168 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrReplayTimestampMissing = errors.New("replay: timestamp missing or malformed")
+	ErrReplayTimestampExpired = errors.New("replay: timestamp outside tolerance window")
+	ErrReplayNonceMissing     = errors.New("replay: nonce missing or malformed")
+	ErrReplayDetected         = errors.New("replay: nonce already used")
+)
+
+// maxNonceLength 限制 nonce 长度, 避免存储被超长值撑大
+const maxNonceLength = 128
+
+// NonceStore 记录已使用的 nonce
+type NonceStore interface {
+	// UseNonce 记录 nonce, 若该 nonce 在 ttl 内首次出现则返回 true
+	UseNonce(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore 是进程内的 NonceStore 实现
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	nextSweep time.Time
+}
+
+// NewMemoryNonceStore 创建一个进程内 nonce 存储
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{entries: make(map[string]time.Time)}
+}
+
+func (s *MemoryNonceStore) UseNonce(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expire, ok := s.entries[nonce]; ok && now.Before(expire) {
+		return false, nil
+	}
+	// 定期清理过期记录, 避免每个请求都遍历全部 nonce
+	if now.After(s.nextSweep) {
+		for k, expire := range s.entries {
+			if !now.Before(expire) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(time.Minute)
+	}
+	s.entries[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// ReplayProtectionOptions 配置请求重放防护
+type ReplayProtectionOptions struct {
+	// TimestampHeader 为携带请求时间的头部, 值为 Unix 秒或 RFC 3339 时间, 默认 X-Timestamp
+	TimestampHeader string
+	// NonceHeader 为携带一次性随机值的头部, 默认 X-Nonce
+	NonceHeader string
+	// Tolerance 为允许的时钟偏差, 默认 DefaultWebhookTolerance
+	Tolerance time.Duration
+	// Store 记录已使用的 nonce, 默认使用进程内存储; 多实例部署时应替换为共享存储
+	Store NonceStore
+	// Verifier 在记录 nonce 之前校验请求签名, 签名应覆盖时间戳与 nonce.
+	// 先校验签名可以防止攻击者伪造请求预先占用合法客户端的 nonce
+	Verifier WebhookVerifier
+	// Scope 返回 nonce 的作用域 (例如 API Key), 不同作用域的 nonce 互不冲突
+	Scope func(c *Context) string
+}
+
+// ReplayProtection 返回拒绝重放请求的中间件: 时间戳超出窗口或 nonce 已被使用的请求返回 401.
+// 与 WebhookVerifier 配合可用于自定义签名方案的 API
+func ReplayProtection(opts ReplayProtectionOptions) HandlerFunc {
+	if opts.TimestampHeader == "" {
+		opts.TimestampHeader = "X-Timestamp"
+	}
+	if opts.NonceHeader == "" {
+		opts.NonceHeader = "X-Nonce"
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = DefaultWebhookTolerance
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryNonceStore()
+	}
+
+	reject := func(c *Context, err error) {
+		c.AddClientError(err)
+		c.ErrorUseHandle(http.StatusUnauthorized, err)
+	}
+
+	return func(c *Context) {
+		ts, err := parseReplayTimestamp(c.Request.Header.Get(opts.TimestampHeader))
+		if err != nil {
+			reject(c, err)
+			return
+		}
+		if diff := time.Since(ts); diff < -opts.Tolerance || diff > opts.Tolerance {
+			reject(c, ErrReplayTimestampExpired)
+			return
+		}
+		nonce := c.Request.Header.Get(opts.NonceHeader)
+		if nonce == "" || len(nonce) > maxNonceLength {
+			reject(c, ErrReplayNonceMissing)
+			return
+		}
+
+		if opts.Verifier != nil {
+			body, err := c.GetReqBodyFull()
+			if err != nil {
+				if errors.Is(err, ErrBodyTooLarge) {
+					c.ErrorUseHandle(http.StatusRequestEntityTooLarge, err)
+					return
+				}
+				c.ErrorUseHandle(http.StatusBadRequest, err)
+				return
+			}
+			// 重置请求体, 使后续处理器可以正常绑定
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if err := opts.Verifier.Verify(c.Request, body); err != nil {
+				reject(c, err)
+				return
+			}
+		}
+
+		key := nonce
+		if opts.Scope != nil {
+			key = opts.Scope(c) + "\x00" + nonce
+		}
+		// 时间戳窗口两侧各为 Tolerance, nonce 至少需要保留到时间戳失效为止
+		first, err := opts.Store.UseNonce(c.Context(), key, 2*opts.Tolerance)
+		if err != nil {
+			c.ErrorUseHandle(http.StatusInternalServerError, fmt.Errorf("replay: nonce store: %w", err))
+			return
+		}
+		if !first {
+			reject(c, ErrReplayDetected)
+			return
+		}
+		c.Next()
+	}
+}
+
+func parseReplayTimestamp(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, ErrReplayTimestampMissing
+	}
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, ErrReplayTimestampMissing
+}
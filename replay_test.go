@@ -0,0 +1,73 @@
+package touka
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplayProtection(t *testing.T) {
+	engine := New()
+	engine.Use(ReplayProtection(ReplayProtectionOptions{
+		Scope: func(c *Context) string { return c.GetReqHeader("X-API-Key") },
+	}))
+	engine.POST("/transfer", func(c *Context) { c.Status(http.StatusOK) })
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := func(ts, nonce, key string) http.Header {
+		return http.Header{"X-Timestamp": {ts}, "X-Nonce": {nonce}, "X-Api-Key": {key}}
+	}
+	cases := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{"first use", headers(now, "n1", "a"), http.StatusOK},
+		{"replayed nonce", headers(now, "n1", "a"), http.StatusUnauthorized},
+		{"same nonce in another scope", headers(now, "n1", "b"), http.StatusOK},
+		{"rfc3339 timestamp", headers(time.Now().UTC().Format(time.RFC3339), "n2", "a"), http.StatusOK},
+		{"stale timestamp", headers(strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10), "n3", "a"), http.StatusUnauthorized},
+		{"future timestamp", headers(strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10), "n4", "a"), http.StatusUnauthorized},
+		{"missing timestamp", headers("", "n5", "a"), http.StatusUnauthorized},
+		{"missing nonce", headers(now, "", "a"), http.StatusUnauthorized},
+		{"oversized nonce", headers(now, strings.Repeat("x", maxNonceLength+1), "a"), http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if w := PerformRequest(engine, http.MethodPost, "/transfer", nil, tc.header); w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestReplayProtectionVerifiesBeforeConsumingNonce(t *testing.T) {
+	engine := New()
+	engine.Use(ReplayProtection(ReplayProtectionOptions{
+		Verifier: WebhookVerifierFunc(func(r *http.Request, body []byte) error {
+			if r.Header.Get("X-Signature") != "sig:"+string(body) {
+				return errors.New("bad signature")
+			}
+			return nil
+		}),
+	}))
+	engine.POST("/", func(c *Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s", body)
+	})
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	forged := http.Header{"X-Timestamp": {ts}, "X-Nonce": {"n"}, "X-Signature": {"forged"}}
+	if w := PerformRequest(engine, http.MethodPost, "/", strings.NewReader("data"), forged); w.Code != http.StatusUnauthorized {
+		t.Fatalf("forged request should be rejected, got %d", w.Code)
+	}
+	valid := http.Header{"X-Timestamp": {ts}, "X-Nonce": {"n"}, "X-Signature": {"sig:data"}}
+	w := PerformRequest(engine, http.MethodPost, "/", strings.NewReader("data"), valid)
+	if w.Code != http.StatusOK || w.Body.String() != "data" {
+		t.Fatalf("forged request must not consume the nonce: %d %q", w.Code, w.Body.String())
+	}
+}
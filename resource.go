@@ -0,0 +1,157 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// 资源控制器的各个动作拆分为独立接口, 控制器只需实现需要的部分.
+type (
+	// ResourceIndexer 处理 GET /resources
+	ResourceIndexer interface{ Index(c *Context) }
+	// ResourceShower 处理 GET /resources/:id
+	ResourceShower interface{ Show(c *Context) }
+	// ResourceCreator 处理 POST /resources
+	ResourceCreator interface{ Create(c *Context) }
+	// ResourceUpdater 处理 PUT/PATCH /resources/:id
+	ResourceUpdater interface{ Update(c *Context) }
+	// ResourceDeleter 处理 DELETE /resources/:id
+	ResourceDeleter interface{ Delete(c *Context) }
+)
+
+// ResourceController 是实现了全部 CRUD 动作的控制器接口
+type ResourceController interface {
+	ResourceIndexer
+	ResourceShower
+	ResourceCreator
+	ResourceUpdater
+	ResourceDeleter
+}
+
+// Resource 表示一个已注册的资源, 可以在其下继续注册嵌套资源
+type Resource struct {
+	router     Router
+	collection string // 集合路径, 例如 /users
+	param      string // 成员参数名, 例如 id
+}
+
+type resourceConfig struct {
+	param    string
+	handlers HandlersChain
+}
+
+// ResourceOption 用于配置资源注册行为
+type ResourceOption func(*resourceConfig)
+
+// WithResourceParam 设置成员路由的参数名 (默认 "id", 嵌套资源默认为 "<资源名>_id")
+func WithResourceParam(name string) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.param = name
+	}
+}
+
+// WithResourceMiddleware 为该资源的所有路由添加中间件
+func WithResourceMiddleware(handlers ...HandlerFunc) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.handlers = append(cfg.handlers, handlers...)
+	}
+}
+
+// Resource 将控制器映射到约定式的 REST 路由上
+//
+//	GET    /users          -> Index
+//	POST   /users          -> Create
+//	GET    /users/:id      -> Show
+//	PUT    /users/:id      -> Update
+//	PATCH  /users/:id      -> Update
+//	DELETE /users/:id      -> Delete
+//
+// controller 至少需要实现 ResourceIndexer/ResourceShower/ResourceCreator/ResourceUpdater/ResourceDeleter 之一
+func (engine *Engine) Resource(relativePath string, controller any, opts ...ResourceOption) *Resource {
+	return registerResource(engine, relativePath, "id", controller, opts)
+}
+
+// Resource 在路由组下注册资源, 参见 Engine.Resource
+func (group *RouterGroup) Resource(relativePath string, controller any, opts ...ResourceOption) *Resource {
+	return registerResource(group, relativePath, "id", controller, opts)
+}
+
+// Resource 注册嵌套资源, 例如 /users/:id/posts/:posts_id
+// 嵌套资源的默认参数名为 "<资源名>_id", 以避免与父资源的参数冲突
+func (res *Resource) Resource(relativePath string, controller any, opts ...ResourceOption) *Resource {
+	nestedPath := res.MemberPath() + "/" + strings.Trim(relativePath, "/")
+	defaultParam := path.Base("/"+strings.Trim(relativePath, "/")) + "_id"
+	return registerResource(res.router, nestedPath, defaultParam, controller, opts)
+}
+
+// Path 返回资源的集合路径 (相对于注册时的 Router)
+func (res *Resource) Path() string {
+	return res.collection
+}
+
+// MemberPath 返回资源的成员路径, 例如 /users/:id
+func (res *Resource) MemberPath() string {
+	return res.collection + "/:" + res.param
+}
+
+// Param 返回成员路由的参数名
+func (res *Resource) Param() string {
+	return res.param
+}
+
+func registerResource(router Router, relativePath, defaultParam string, controller any, opts []ResourceOption) *Resource {
+	if controller == nil {
+		panic("touka: resource controller must not be nil")
+	}
+	cfg := resourceConfig{param: defaultParam}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	if cfg.param == "" || strings.ContainsAny(cfg.param, "/:*") {
+		panic("touka: invalid resource param name: " + cfg.param)
+	}
+
+	collection := "/" + strings.Trim(relativePath, "/")
+	res := &Resource{router: router, collection: collection, param: cfg.param}
+	member := res.MemberPath()
+
+	chain := func(h HandlerFunc) []HandlerFunc {
+		handlers := make([]HandlerFunc, 0, len(cfg.handlers)+1)
+		handlers = append(handlers, cfg.handlers...)
+		return append(handlers, h)
+	}
+
+	registered := false
+	if ctrl, ok := controller.(ResourceIndexer); ok {
+		router.Handle(http.MethodGet, collection, chain(ctrl.Index)...)
+		registered = true
+	}
+	if ctrl, ok := controller.(ResourceCreator); ok {
+		router.Handle(http.MethodPost, collection, chain(ctrl.Create)...)
+		registered = true
+	}
+	if ctrl, ok := controller.(ResourceShower); ok {
+		router.Handle(http.MethodGet, member, chain(ctrl.Show)...)
+		registered = true
+	}
+	if ctrl, ok := controller.(ResourceUpdater); ok {
+		router.Handle(http.MethodPut, member, chain(ctrl.Update)...)
+		router.Handle(http.MethodPatch, member, chain(ctrl.Update)...)
+		registered = true
+	}
+	if ctrl, ok := controller.(ResourceDeleter); ok {
+		router.Handle(http.MethodDelete, member, chain(ctrl.Delete)...)
+		registered = true
+	}
+	if !registered {
+		panic("touka: resource controller for " + collection + " implements no resource actions")
+	}
+	return res
+}
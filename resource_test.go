@@ -0,0 +1,80 @@
+package touka
+
+import (
+	"net/http"
+	"testing"
+)
+
+type testUserController struct{}
+
+func (testUserController) Index(c *Context)  { c.String(http.StatusOK, "index") }
+func (testUserController) Show(c *Context)   { c.String(http.StatusOK, "show %s", c.Param("id")) }
+func (testUserController) Create(c *Context) { c.String(http.StatusCreated, "create") }
+func (testUserController) Update(c *Context) { c.String(http.StatusOK, "update %s", c.Param("id")) }
+func (testUserController) Delete(c *Context) { c.Status(http.StatusNoContent) }
+
+type testPostController struct{}
+
+func (testPostController) Show(c *Context) {
+	c.String(http.StatusOK, "user %s post %s", c.Param("id"), c.Param("posts_id"))
+}
+
+func TestResourceRegistersConventionalRoutes(t *testing.T) {
+	engine := New()
+	users := engine.Resource("/users", testUserController{})
+	users.Resource("posts", testPostController{})
+
+	cases := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{http.MethodGet, "/users", http.StatusOK, "index"},
+		{http.MethodPost, "/users", http.StatusCreated, "create"},
+		{http.MethodGet, "/users/7", http.StatusOK, "show 7"},
+		{http.MethodPut, "/users/7", http.StatusOK, "update 7"},
+		{http.MethodPatch, "/users/7", http.StatusOK, "update 7"},
+		{http.MethodDelete, "/users/7", http.StatusNoContent, ""},
+		{http.MethodGet, "/users/7/posts/9", http.StatusOK, "user 7 post 9"},
+	}
+	for _, tc := range cases {
+		rr := PerformRequest(engine, tc.method, tc.path, nil, nil)
+		if rr.Code != tc.code || rr.Body.String() != tc.body {
+			t.Fatalf("%s %s: expected %d %q, got %d %q", tc.method, tc.path, tc.code, tc.body, rr.Code, rr.Body.String())
+		}
+	}
+
+	// 未实现的动作不应注册路由
+	rr := PerformRequest(engine, http.MethodGet, "/users/7/posts", nil, nil)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected unimplemented nested index to be 404, got %d", rr.Code)
+	}
+}
+
+func TestResourceInGroupWithOptions(t *testing.T) {
+	engine := New()
+	api := engine.Group("/api")
+	var hits int
+	res := api.(*RouterGroup).Resource("/users", testPostController{},
+		WithResourceParam("uid"),
+		WithResourceMiddleware(func(c *Context) { hits++; c.Next() }),
+	)
+	if res.MemberPath() != "/users/:uid" {
+		t.Fatalf("unexpected member path %q", res.MemberPath())
+	}
+
+	rr := PerformRequest(engine, http.MethodGet, "/api/users/3", nil, nil)
+	if rr.Code != http.StatusOK || hits != 1 {
+		t.Fatalf("expected middleware to run once and 200, got hits=%d code=%d", hits, rr.Code)
+	}
+}
+
+func TestResourcePanicsWithoutActions(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for controller without actions")
+		}
+	}()
+	New().Resource("/empty", struct{}{})
+}
@@ -7,6 +7,7 @@ package touka
 import (
 	"bufio"
 	"errors"
+	"io"
 	"log"
 	"net"
 	"net/http"
@@ -33,6 +34,7 @@ type responseWriterImpl struct {
 	size     int
 	status   int // 0 表示尚未写入状态码
 	hijacked bool
+	gone     *clientGoneWatch // 通过 OnClientGone 注册的断开回调, 写入失败时触发
 }
 
 // NewResponseWriter 创建并返回一个 responseWriterImpl 实例
@@ -59,6 +61,9 @@ func (rw *responseWriterImpl) reset(w http.ResponseWriter) {
 	rw.status = 0
 	rw.size = 0
 	rw.hijacked = false
+	if rw.gone != nil {
+		rw.gone = nil
+	}
 }
 
 func (rw *responseWriterImpl) WriteHeader(statusCode int) {
@@ -88,6 +93,33 @@ func (rw *responseWriterImpl) Write(b []byte) (int, error) {
 	}
 	n, err := rw.ResponseWriter.Write(b)
 	rw.size += n
+	if err != nil && rw.gone != nil {
+		rw.gone.fire()
+	}
+	return n, err
+}
+
+// ReadFrom 实现 io.ReaderFrom, 底层 ResponseWriter 支持时直接交给它:
+// net/http 在 HTTP/1.x 明文连接上发送 *os.File 时使用 sendfile, 文件内容不经过用户态缓冲区.
+// 替换了 c.Writer 的中间件不会转发 ReadFrom, 此时退回普通的复制
+func (rw *responseWriterImpl) ReadFrom(r io.Reader) (int64, error) {
+	if rw.hijacked {
+		return 0, errors.New("http: response already hijacked")
+	}
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	var n int64
+	var err error
+	if rf, ok := rw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(struct{ io.Writer }{rw.ResponseWriter}, r)
+	}
+	rw.size += int(n)
+	if err != nil && rw.gone != nil {
+		rw.gone.fire()
+	}
 	return n, err
 }
 
@@ -136,6 +168,9 @@ func (rw *responseWriterImpl) Flush() {
 			// 记录捕获到的 panic 信息，这表明底层连接可能已经关闭或失效
 			// 使用 log.Printf 记录，并包含堆栈信息，便于调试
 			log.Printf("Recovered from panic during responseWriterImpl.Flush for request: %v\nStack: %s", r, debug.Stack())
+			if rw.gone != nil {
+				rw.gone.fire()
+			}
 			// 捕获后，不继续传播 panic，允许请求的 goroutine 优雅退出
 		}
 	}()
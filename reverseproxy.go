@@ -972,7 +972,7 @@ func (p *reverseProxyHandler) writeLocalOptionsResponse(c *Context) {
 
 	if c.engine != nil {
 		if c.Request != nil && c.Request.RequestURI != "*" {
-			if allow := c.engine.allowedMethodsForPath(routeLookupPath(c.Request), c.allowedMethodsBuf[:0]); len(allow) > 0 {
+			if allow := c.engine.allowedMethodsForPath(c.engine.lookupPath(c.Request), c.allowedMethodsBuf[:0]); len(allow) > 0 {
 				c.allowedMethodsBuf = allow[:0]
 				allowHeader := c.allowHeaderBuf[:0]
 				for i, method := range allow {
@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+// MetaDoc 是路由文档的元数据键, 值为 RouteDoc
+const MetaDoc = "touka.doc"
+
+// RouteDoc 是路由的说明与示例, 会写入 CLIGenerateOpenAPI 生成的文档
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// Request 为请求体示例
+	Request *RouteExample
+	// Responses 为按状态码区分的响应示例
+	Responses map[int]RouteExample
+}
+
+// RouteExample 是一个请求或响应示例
+type RouteExample struct {
+	Description string
+	// ContentType 为示例的媒体类型, 为空时为 application/json
+	ContentType string
+	// Value 为示例内容, 按 JSON 编码写入文档
+	Value any
+}
+
+// Describe 返回附加路由文档的元数据, 用于 WithMeta:
+//
+//	r.WithMeta(touka.Describe(touka.RouteDoc{
+//		Summary: "创建用户",
+//		Request: &touka.RouteExample{Value: touka.H{"name": "alice"}},
+//		Responses: map[int]touka.RouteExample{
+//			http.StatusCreated: {Description: "已创建", Value: touka.H{"id": 1, "name": "alice"}},
+//		},
+//	})).POST("/users", createUser)
+func Describe(doc RouteDoc) (string, any) {
+	return MetaDoc, doc
+}
+
+// Doc 返回通过 Describe 附加的路由文档
+func (r RouteInfo) Doc() (RouteDoc, bool) {
+	doc, ok := r.Meta[MetaDoc].(RouteDoc)
+	return doc, ok
+}
+
+func (e RouteExample) contentType() string {
+	if e.ContentType == "" {
+		return "application/json"
+	}
+	return e.ContentType
+}
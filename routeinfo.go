@@ -0,0 +1,125 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// WildcardKind 是路由路径中通配段的类型
+type WildcardKind string
+
+const (
+	WildcardParam    WildcardKind = "param"     // :name, 匹配单个路径段
+	WildcardCatchAll WildcardKind = "catch-all" // *name, 匹配剩余的全部路径
+)
+
+// RouteWildcard 描述路由路径中的一个通配段
+type RouteWildcard struct {
+	Name     string
+	Kind     WildcardKind
+	Optional bool // :name?, 请求路径中可以省略
+}
+
+// RouteSource 是路由的注册位置
+type RouteSource struct {
+	File string
+	Line int
+}
+
+func (s RouteSource) String() string {
+	if s.File == "" {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
+
+// toukaPkgPrefix 为本包函数名的前缀, 查找注册位置时跳过这些栈帧
+var toukaPkgPrefix = reflect.TypeFor[Engine]().PkgPath() + "."
+
+// registrationSite 返回调用链中第一个位于本包之外 (或本包测试文件中) 的栈帧,
+// 使 Mount 系列等内部注册的路由也指向应用中的调用处
+func registrationSite() RouteSource {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, toukaPkgPrefix) || strings.HasSuffix(f.File, "_test.go") {
+			return RouteSource{File: f.File, Line: f.Line}
+		}
+		if !more {
+			return RouteSource{}
+		}
+	}
+}
+
+// routeWildcards 按出现顺序返回路径中的通配段
+func routeWildcards(path string) []RouteWildcard {
+	var wildcards []RouteWildcard
+	for seg := range strings.SplitSeq(path, "/") {
+		if name, ok := optionalParamName(seg); ok {
+			wildcards = append(wildcards, RouteWildcard{Name: name, Kind: WildcardParam, Optional: true})
+			continue
+		}
+		s, ok := parseRouteSegment(seg)
+		if !ok {
+			continue
+		}
+		for i, name := range s.names {
+			kind := WildcardParam
+			if i == 0 && s.catchAll {
+				kind = WildcardCatchAll
+			}
+			wildcards = append(wildcards, RouteWildcard{Name: name, Kind: kind})
+		}
+	}
+	return wildcards
+}
+
+// middlewareNames 返回处理器链中除最终处理器外各中间件的名称
+func middlewareNames(handlers HandlersChain) []string {
+	if len(handlers) < 2 {
+		return nil
+	}
+	names := make([]string, 0, len(handlers)-1)
+	for _, h := range handlers[:len(handlers)-1] {
+		names = append(names, getHandlerName(h))
+	}
+	return names
+}
+
+// annotateRoutePanic 为注册路由时的 panic (例如路由重复或通配符冲突) 补充注册位置,
+// 重复注册时同时给出先前注册的位置. 必须以 defer 直接调用
+func (engine *Engine) annotateRoutePanic(method, path string, site RouteSource) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	msg := fmt.Sprintf("%v (%s %s registered at %s)", r, method, path, site)
+	for _, route := range engine.routesInfo {
+		if route.Method == method && route.Path == path {
+			msg += fmt.Sprintf("; previously registered at %s", route.Source)
+			break
+		}
+	}
+	panic(msg)
+}
+
+// GetRouterInfoByGroup 返回在指定分组及其子分组中注册的路由, group 为 Group 时使用的路径, 例如 "/api"
+func (engine *Engine) GetRouterInfoByGroup(group string) []RouteInfo {
+	group = resolveRoutePath("/", group)
+	prefix := strings.TrimSuffix(group, "/") + "/"
+	var routes []RouteInfo
+	for _, route := range engine.routesInfo {
+		if route.Group == group || strings.HasPrefix(route.Group, prefix) {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
@@ -0,0 +1,73 @@
+package touka
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func routeInfoMiddleware(c *Context) { c.Next() }
+
+func TestRouteInfoDetails(t *testing.T) {
+	r := New()
+	api := r.Group("/api", routeInfoMiddleware)
+	_, _, line, _ := runtime.Caller(0)
+	api.GET("/users/:id/files/*path", cliTestHandler)
+
+	routes := r.GetRouterInfo()
+	if len(routes) != 1 {
+		t.Fatalf("expected 1 route, got %d", len(routes))
+	}
+	info := routes[0]
+	if filepath.Base(info.Source.File) != "routeinfo_test.go" || info.Source.Line != line+1 {
+		t.Errorf("Source = %s, want routeinfo_test.go:%d", info.Source, line+1)
+	}
+	want := []RouteWildcard{{Name: "id", Kind: WildcardParam}, {Name: "path", Kind: WildcardCatchAll}}
+	if !slices.Equal(info.Wildcards, want) {
+		t.Errorf("Wildcards = %+v, want %+v", info.Wildcards, want)
+	}
+	if len(info.Middleware) == 0 || !strings.HasSuffix(info.Middleware[len(info.Middleware)-1], "routeInfoMiddleware") {
+		t.Errorf("Middleware = %v", info.Middleware)
+	}
+	if slices.ContainsFunc(info.Middleware, func(name string) bool { return strings.HasSuffix(name, "cliTestHandler") }) {
+		t.Errorf("final handler should not be listed as middleware: %v", info.Middleware)
+	}
+}
+
+func TestGetRouterInfoByGroup(t *testing.T) {
+	r := New()
+	r.GET("/", cliTestHandler)
+	api := r.Group("/api")
+	api.GET("/ping", cliTestHandler)
+	api.Group("/v1").GET("/users", cliTestHandler)
+	r.Group("/apix").GET("/other", cliTestHandler)
+
+	var paths []string
+	for _, route := range r.GetRouterInfoByGroup("/api") {
+		paths = append(paths, route.Path)
+	}
+	if !slices.Equal(paths, []string{"/api/ping", "/api/v1/users"}) {
+		t.Errorf("paths = %v", paths)
+	}
+	if got := r.GetRouterInfoByGroup("api/v1"); len(got) != 1 || got[0].Path != "/api/v1/users" {
+		t.Errorf("nested group routes = %+v", got)
+	}
+}
+
+func TestDuplicateRouteReportsSources(t *testing.T) {
+	r := New()
+	_, file, line, _ := runtime.Caller(0)
+	r.GET("/dup", cliTestHandler)
+
+	recv := catchPanic(func() { r.GET("/dup", cliTestHandler) })
+	msg := fmt.Sprint(recv)
+	if !strings.Contains(msg, "already registered") {
+		t.Fatalf("unexpected panic: %v", recv)
+	}
+	if !strings.Contains(msg, fmt.Sprintf("previously registered at %s:%d", file, line+1)) {
+		t.Errorf("panic should include the original registration site: %s", msg)
+	}
+}
@@ -0,0 +1,63 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import "maps"
+
+// RouteMeta 是附加在路由上的元数据, 中间件可以在请求时通过 c.RouteMeta() 读取,
+// 也会出现在 GetRouterInfo 的结果中
+type RouteMeta map[string]any
+
+// routeKey 标识一个已注册的路由
+type routeKey struct {
+	method string
+	path   string
+}
+
+// with 返回加入 key 后的副本, 不修改原元数据
+func (m RouteMeta) with(key string, value any) RouteMeta {
+	out := make(RouteMeta, len(m)+1)
+	maps.Copy(out, m)
+	out[key] = value
+	return out
+}
+
+// WithMeta 返回一个附加了元数据的路由器, 通过它注册的路由及其子组都会携带该元数据:
+//
+//	r.WithMeta(touka.Consumes("application/json")).POST("/users", createUser)
+//
+//	api := r.Group("/api").(touka.MetaRouter).WithMeta("auth", "required")
+func (engine *Engine) WithMeta(key string, value any) MetaRouter {
+	return &RouterGroup{
+		Handlers: engine.combineHandlers(engine.globalHandlers, nil),
+		basePath: "/",
+		engine:   engine,
+		meta:     RouteMeta{key: value},
+	}
+}
+
+// WithMeta 返回一个附加了元数据的子路由器, 路径前缀与中间件与当前组相同
+func (group *RouterGroup) WithMeta(key string, value any) MetaRouter {
+	return &RouterGroup{
+		Handlers: group.engine.combineHandlers(group.Handlers, nil),
+		basePath: group.basePath,
+		engine:   group.engine,
+		meta:     group.meta.with(key, value),
+	}
+}
+
+// RouteMeta 返回当前匹配路由的元数据, 未匹配到路由或没有元数据时返回 nil
+func (c *Context) RouteMeta() RouteMeta {
+	if c.engine == nil || c.fullPath == "" || c.Request == nil {
+		return nil
+	}
+	return c.engine.routeMeta[routeKey{method: c.Request.Method, path: c.fullPath}]
+}
+
+// RouteMetaValue 返回当前匹配路由上 key 对应的元数据
+func (c *Context) RouteMetaValue(key string) (any, bool) {
+	v, ok := c.RouteMeta()[key]
+	return v, ok
+}
@@ -0,0 +1,131 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MetaRouteName 是路由名称的元数据键, 值为 string
+const MetaRouteName = "touka.name"
+
+// RouteName 返回为路由命名的元数据, 用于 WithMeta. 命名路由可以通过 URLFor 或模板函数 urlFor 反向生成地址:
+//
+//	r.WithMeta(touka.RouteName("user")).GET("/users/:id", showUser)
+//
+// 同一名称只能对应一个路径 (例如同一路径的 GET 与 HEAD), 否则注册时 panic
+func RouteName(name string) (string, any) {
+	return MetaRouteName, name
+}
+
+// nameRoute 记录命名路由的路径
+func (engine *Engine) nameRoute(name, absolutePath string) {
+	if prev, ok := engine.namedRoutes[name]; ok {
+		if prev != absolutePath {
+			panic(fmt.Sprintf("touka: route name %q already used by %s", name, prev))
+		}
+		return
+	}
+	if engine.namedRoutes == nil {
+		engine.namedRoutes = make(map[string]string)
+	}
+	engine.namedRoutes[name] = absolutePath
+}
+
+// URLFor 按名称生成路由地址. params 为交替的参数名与值, 路径中的参数被替换并转义,
+// 其余参数按顺序作为查询参数追加:
+//
+//	engine.URLFor("user", "id", 42, "tab", "posts") // /users/42?tab=posts
+//
+// 可选参数 :name? 未给出时, 该路径段及其后的可选参数一并省略.
+// 路由不存在、缺少路径参数或 params 不成对时返回错误
+func (engine *Engine) URLFor(name string, params ...any) (string, error) {
+	pattern, ok := engine.namedRoutes[name]
+	if !ok {
+		return "", fmt.Errorf("urlfor: unknown route %q", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("urlfor: route %q: params must be name/value pairs", name)
+	}
+	values := make(map[string]string, len(params)/2)
+	var order []string
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("urlfor: route %q: param name %v is not a string", name, params[i])
+		}
+		if _, dup := values[key]; !dup {
+			order = append(order, key)
+		}
+		values[key] = fmt.Sprint(params[i+1])
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if param, ok := optionalParamName(seg); ok {
+			v, ok := values[param]
+			if !ok {
+				// 省略可选参数时其后的可选参数也必须省略
+				for _, rest := range segments[i+1:] {
+					next, _ := optionalParamName(rest)
+					if _, given := values[next]; given {
+						return "", fmt.Errorf("urlfor: route %q: parameter %q requires %q", name, next, param)
+					}
+				}
+				segments = segments[:i]
+				break
+			}
+			delete(values, param)
+			segments[i] = url.PathEscape(v)
+			continue
+		}
+		s, ok := parseRouteSegment(seg)
+		if !ok {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString(s.prefix)
+		for j, param := range s.names {
+			v, ok := values[param]
+			if !ok {
+				return "", fmt.Errorf("urlfor: route %q: missing parameter %q", name, param)
+			}
+			delete(values, param)
+			if j > 0 {
+				sb.WriteString(s.seps[j-1])
+			}
+			if j == 0 && s.catchAll {
+				// 通配参数可以包含多段路径, 逐段转义
+				parts := strings.Split(strings.TrimPrefix(v, "/"), "/")
+				for k, part := range parts {
+					parts[k] = url.PathEscape(part)
+				}
+				sb.WriteString(strings.Join(parts, "/"))
+				continue
+			}
+			sb.WriteString(url.PathEscape(v))
+		}
+		sb.WriteString(s.suffix)
+		segments[i] = sb.String()
+	}
+	out := cmp.Or(strings.Join(segments, "/"), "/")
+
+	var query url.Values
+	for _, key := range order {
+		if v, ok := values[key]; ok {
+			if query == nil {
+				query = make(url.Values)
+			}
+			query.Set(key, v)
+		}
+	}
+	if query != nil {
+		out += "?" + query.Encode()
+	}
+	return out, nil
+}
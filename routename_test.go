@@ -0,0 +1,59 @@
+package touka
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestURLFor(t *testing.T) {
+	engine := New()
+	engine.WithMeta(RouteName("user")).GET("/users/:id", func(c *Context) {})
+	engine.WithMeta(RouteName("user")).HEAD("/users/:id", func(c *Context) {})
+	api := engine.Group("/api").(MetaRouter)
+	api.WithMeta(RouteName("file")).GET("/files/*path", func(c *Context) {})
+
+	tests := []struct {
+		name   string
+		route  string
+		params []any
+		want   string
+	}{
+		{"path param", "user", []any{"id", 42}, "/users/42"},
+		{"escaped param", "user", []any{"id", "a b/c"}, "/users/a%20b%2Fc"},
+		{"extra params become query", "user", []any{"id", 1, "tab", "posts", "q", "x&y"}, "/users/1?q=x%26y&tab=posts"},
+		{"catch-all keeps slashes", "file", []any{"path", "docs/read me.md"}, "/api/files/docs/read%20me.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.URLFor(tt.route, tt.params...)
+			if err != nil || got != tt.want {
+				t.Fatalf("URLFor = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	for _, params := range [][]any{{"id"}, {}, {1, 2}} {
+		if _, err := engine.URLFor("user", params...); err == nil {
+			t.Errorf("URLFor(user, %v) should fail", params)
+		}
+	}
+	if _, err := engine.URLFor("missing"); err == nil {
+		t.Error("unknown route name should fail")
+	}
+}
+
+func TestRouteNameConflictPanics(t *testing.T) {
+	engine := New()
+	engine.WithMeta(RouteName("home")).GET("/", func(c *Context) {})
+	rec := catchPanic(func() {
+		engine.WithMeta(RouteName("home")).GET("/index", func(c *Context) {})
+	})
+	if rec == nil || !strings.Contains(fmt.Sprint(rec), `route name "home"`) {
+		t.Fatalf("expected duplicate name panic, got %v", rec)
+	}
+	if w := PerformRequest(engine, http.MethodGet, "/", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("named route should still be served, got %d", w.Code)
+	}
+}
@@ -0,0 +1,285 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// routeSegment 是路径段中从第一个通配符开始的部分, 例如 :name.:ext 或 *path.json
+type routeSegment struct {
+	prefix   string   // 通配符之前的字面量, 已去除转义
+	catchAll bool     // 通配符为 *name
+	names    []string // 参数名, 第一个参数也是路由树中的参数
+	seps     []string // names[i] 与 names[i+1] 之间的字面量
+	suffix   string   // 最后一个参数之后的字面量
+}
+
+// composite 报告路径段是否需要在匹配后拆分参数
+func (s *routeSegment) composite() bool {
+	return len(s.names) > 1 || s.suffix != ""
+}
+
+// parseRouteSegment 解析路径段, 段中没有通配符时返回 false. 通配符之后出现 '.' 或其他通配符时,
+// 参数名只包含字母、数字与下划线, 其后的字符作为字面量, 例如 :name.:ext 与 *path.json
+func parseRouteSegment(seg string) (routeSegment, bool) {
+	start := wildcardIndex(seg)
+	if start < 0 {
+		return routeSegment{}, false
+	}
+	s := routeSegment{
+		prefix:   strings.ReplaceAll(seg[:start], `\:`, ":"),
+		catchAll: seg[start] == '*',
+	}
+	body := seg[start+1:]
+	if !strings.ContainsAny(body, `.:*\`) {
+		s.names = []string{body}
+		return s, true
+	}
+
+	pos := 0
+	for {
+		n := 0
+		for pos+n < len(body) && isParamNameByte(body[pos+n]) {
+			n++
+		}
+		if n == 0 {
+			panic(fmt.Sprintf("touka: wildcard in path segment %q must have a name", seg))
+		}
+		s.names = append(s.names, body[pos:pos+n])
+		pos += n
+
+		var lit strings.Builder
+		for pos < len(body) && body[pos] != ':' && body[pos] != '*' {
+			if body[pos] == '\\' && pos+1 < len(body) && body[pos+1] == ':' {
+				pos++
+			}
+			lit.WriteByte(body[pos])
+			pos++
+		}
+		if pos == len(body) {
+			s.suffix = lit.String()
+			return s, true
+		}
+		if body[pos] == '*' {
+			panic(fmt.Sprintf("touka: catch-all must be the first wildcard in path segment %q", seg))
+		}
+		if lit.Len() == 0 {
+			panic(fmt.Sprintf("touka: wildcards in path segment %q must be separated by a literal", seg))
+		}
+		s.seps = append(s.seps, lit.String())
+		pos++
+	}
+}
+
+// wildcardIndex 返回路径段中第一个未转义的通配符的位置, 没有时返回 -1
+func wildcardIndex(seg string) int {
+	for i := 0; i < len(seg); i++ {
+		if seg[i] == '\\' {
+			i++
+			continue
+		}
+		if seg[i] == ':' || seg[i] == '*' {
+			return i
+		}
+	}
+	return -1
+}
+
+func isParamNameByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// split 将路由树中第一个参数的值按字面量拆分为各个参数的值. 从右向左匹配,
+// 因此 :name.:ext 匹配 a.tar.gz 时 name 为 a.tar, ext 为 gz
+func (s *routeSegment) split(value string) ([]string, bool) {
+	v, ok := strings.CutSuffix(value, s.suffix)
+	if !ok {
+		return nil, false
+	}
+	values := make([]string, len(s.names))
+	for i := len(s.names) - 1; i > 0; i-- {
+		sep := s.seps[i-1]
+		j := strings.LastIndex(v, sep)
+		if j < 0 {
+			return nil, false
+		}
+		part := v[j+len(sep):]
+		// 捕获所有参数只有第一个参数可以跨越路径段
+		if part == "" || strings.Contains(part, "/") {
+			return nil, false
+		}
+		values[i], v = part, v[:j]
+	}
+	// 捕获所有参数的值以 / 开头, 因此只有普通参数可能为空
+	if v == "" {
+		return nil, false
+	}
+	values[0] = v
+	return values, true
+}
+
+// optionalParamName 报告路径段是否为可选参数 :name?, 并返回参数名
+func optionalParamName(seg string) (string, bool) {
+	name, ok := strings.CutSuffix(seg, "?")
+	if !ok || len(name) < 2 || name[0] != ':' {
+		return "", false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isParamNameByte(name[i]) {
+			return "", false
+		}
+	}
+	return name[1:], true
+}
+
+// expandOptionalParams 展开路径末尾的可选参数, 返回由短到长的路径与可选参数名, 没有可选参数时返回 nil.
+// 例如 /archive/:year/:month?/:day? 展开为 /archive/:year、/archive/:year/:month 与 /archive/:year/:month/:day
+func expandOptionalParams(pattern string) (paths, optional []string) {
+	parts := strings.Split(pattern, "/")
+	first := -1
+	for i, part := range parts {
+		name, ok := optionalParamName(part)
+		if !ok {
+			if first >= 0 {
+				panic(fmt.Sprintf("touka: optional parameters in path '%s' must be the trailing segments", pattern))
+			}
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		parts[i] = ":" + name
+		optional = append(optional, name)
+	}
+	if first < 0 {
+		return nil, nil
+	}
+	for i := first; i <= len(parts); i++ {
+		paths = append(paths, cmp.Or(strings.Join(parts[:i], "/"), "/"))
+	}
+	return paths, optional
+}
+
+// routeVariant 是路由树路径与注册路径不同的路由, 例如 /img/:name.:ext 在路由树中为 /img/:name,
+// /archive/:year/:month? 在路由树中为 /archive/:year 与 /archive/:year/:month
+type routeVariant struct {
+	pattern  string         // 注册时的路径, 作为 FullPath
+	segments []routeSegment // 匹配后需要拆分参数的路径段
+	absent   []string       // 树路径中省略的可选参数, 匹配后取空值
+	handlers HandlersChain
+}
+
+// splitRoutePattern 返回 pattern 在路由树中使用的路径以及需要在匹配后拆分参数的路径段
+func splitRoutePattern(pattern string) (string, []routeSegment) {
+	var segments []routeSegment
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		s, ok := parseRouteSegment(part)
+		if !ok || !s.composite() {
+			continue
+		}
+		segments = append(segments, s)
+		kind := ":"
+		if s.catchAll {
+			kind = "*"
+		}
+		parts[i] = part[:wildcardIndex(part)] + kind + s.names[0]
+	}
+	if segments == nil {
+		return pattern, nil
+	}
+	return strings.Join(parts, "/"), segments
+}
+
+// addRouteVariant 记录路由变体, 返回是否需要将 treePath 插入路由树. 同一树路径可以对应多个变体,
+// 例如 /files/*path.json 与 /files/*path.xml, 匹配时按注册顺序使用第一个能够拆分参数的变体.
+// 不需要拆分参数的变体总能匹配, 因此不能与其他变体共用树路径
+func (engine *Engine) addRouteVariant(method, treePath string, variant *routeVariant) bool {
+	key := routeKey{method: method, path: treePath}
+	variants, exists := engine.routeVariants[key]
+	if !exists {
+		for _, info := range engine.routesInfo {
+			if info.Method == method && info.Path == treePath {
+				panic(fmt.Sprintf("touka: route %s conflicts with existing route %s", variant.pattern, treePath))
+			}
+		}
+	}
+	for _, v := range variants {
+		if v.pattern == variant.pattern {
+			panic(fmt.Sprintf("touka: handlers are already registered for path '%s'", variant.pattern))
+		}
+		if len(v.segments) == 0 || len(variant.segments) == 0 {
+			panic(fmt.Sprintf("touka: route %s conflicts with existing route %s", variant.pattern, v.pattern))
+		}
+	}
+	if engine.routeVariants == nil {
+		engine.routeVariants = make(map[routeKey][]*routeVariant)
+	}
+	engine.routeVariants[key] = append(variants, variant)
+	return !exists
+}
+
+// resolveRouteVariant 对路由树匹配到的路由应用注册时的路径模式: 拆分参数并替换处理链与 FullPath.
+// 没有变体能够拆分参数时清空 value.handlers, 按未匹配处理
+func (engine *Engine) resolveRouteVariant(method string, value *nodeValue, params *Params) {
+	if value.handlers == nil || len(engine.routeVariants) == 0 {
+		return
+	}
+	variants, ok := engine.routeVariants[routeKey{method: method, path: value.fullPath}]
+	if !ok {
+		return
+	}
+	for _, v := range variants {
+		if v.apply(params) {
+			value.handlers = v.handlers
+			value.fullPath = v.pattern
+			return
+		}
+	}
+	value.handlers = nil
+}
+
+// apply 拆分变体中的参数并补充省略的可选参数, 全部成功时才修改 params
+func (v *routeVariant) apply(params *Params) bool {
+	if params == nil {
+		return len(v.segments) == 0
+	}
+	type result struct {
+		index  int
+		values []string
+	}
+	results := make([]result, 0, len(v.segments))
+	for _, s := range v.segments {
+		index := -1
+		for i, p := range *params {
+			if p.Key == s.names[0] {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return false
+		}
+		values, ok := s.split((*params)[index].Value)
+		if !ok {
+			return false
+		}
+		results = append(results, result{index, values})
+	}
+	for i, r := range results {
+		s := &v.segments[i]
+		(*params)[r.index].Value = r.values[0]
+		for j := 1; j < len(s.names); j++ {
+			*params = append(*params, Param{Key: s.names[j], Value: r.values[j]})
+		}
+	}
+	for _, name := range v.absent {
+		*params = append(*params, Param{Key: name})
+	}
+	return true
+}
@@ -0,0 +1,215 @@
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRouteExtensionParams(t *testing.T) {
+	engine := New()
+	engine.GET("/img/:name.:ext", func(c *Context) {
+		c.String(http.StatusOK, "img %s %s %s", c.Param("name"), c.Param("ext"), c.FullPath())
+	})
+	engine.GET("/files/*path.json", func(c *Context) {
+		c.String(http.StatusOK, "json %s", c.Param("path"))
+	})
+	engine.GET("/files/*path.xml", func(c *Context) {
+		c.String(http.StatusOK, "xml %s", c.Param("path"))
+	})
+	engine.GET("/raw/*path.:ext", func(c *Context) {
+		c.String(http.StatusOK, "raw %s %s", c.Param("path"), c.Param("ext"))
+	})
+	engine.GET("/v/:major.:minor.:patch", func(c *Context) {
+		c.String(http.StatusOK, "%s-%s-%s", c.Param("major"), c.Param("minor"), c.Param("patch"))
+	})
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/img/logo.png", http.StatusOK, "img logo png /img/:name.:ext"},
+		{"/img/a.tar.gz", http.StatusOK, "img a.tar gz /img/:name.:ext"},
+		{"/img/logo", http.StatusNotFound, ""},
+		{"/img/.png", http.StatusNotFound, ""},
+		{"/img/logo.", http.StatusNotFound, ""},
+		{"/files/docs/a.json", http.StatusOK, "json /docs/a"},
+		{"/files/docs/a.xml", http.StatusOK, "xml /docs/a"},
+		{"/files/docs/a.txt", http.StatusNotFound, ""},
+		{"/files/.json", http.StatusOK, "json /"},
+		{"/raw/x/y.tar.gz", http.StatusOK, "raw /x/y.tar gz"},
+		{"/raw/x.y/z", http.StatusNotFound, ""},
+		{"/v/1.2.3", http.StatusOK, "1-2-3"},
+		{"/v/1.2", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := PerformRequest(engine, http.MethodGet, tt.path, nil, nil)
+			if w.Code != tt.code {
+				t.Fatalf("status = %d, want %d", w.Code, tt.code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Fatalf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestRouteExtensionParamsMeta(t *testing.T) {
+	engine := New()
+	engine.WithMeta(RouteName("image")).GET("/img/:name.:ext", func(c *Context) {
+		name, _ := c.RouteMetaValue(MetaRouteName)
+		c.String(http.StatusOK, "%v", name)
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/img/logo.png", nil, nil)
+	if w.Body.String() != "image" {
+		t.Fatalf("route meta = %q, want image", w.Body.String())
+	}
+
+	got, err := engine.URLFor("image", "name", "my logo", "ext", "png", "size", 2)
+	if want := "/img/my%20logo.png?size=2"; err != nil || got != want {
+		t.Fatalf("URLFor = %q, %v; want %q", got, err, want)
+	}
+	if _, err := engine.URLFor("image", "name", "logo"); err == nil {
+		t.Fatal("URLFor without ext should fail")
+	}
+
+	wildcards := routeWildcards("/raw/*path.:ext")
+	if len(wildcards) != 2 || wildcards[0] != (RouteWildcard{Name: "path", Kind: WildcardCatchAll}) ||
+		wildcards[1] != (RouteWildcard{Name: "ext", Kind: WildcardParam}) {
+		t.Fatalf("routeWildcards = %+v", wildcards)
+	}
+}
+
+func TestRouteExtensionParamsConflicts(t *testing.T) {
+	handler := func(c *Context) {}
+	tests := []struct {
+		name     string
+		register func(engine *Engine)
+		want     string
+	}{
+		{"plain after extension", func(engine *Engine) {
+			engine.GET("/img/:name.:ext", handler)
+			engine.GET("/img/:name", handler)
+		}, "capture extensions"},
+		{"extension after plain", func(engine *Engine) {
+			engine.GET("/img/:name", handler)
+			engine.GET("/img/:name.:ext", handler)
+		}, "conflicts with existing route"},
+		{"duplicate", func(engine *Engine) {
+			engine.GET("/img/:name.:ext", handler)
+			engine.GET("/img/:name.:ext", handler)
+		}, "already registered"},
+		{"adjacent wildcards", func(engine *Engine) {
+			engine.GET("/img/:name:ext", handler)
+		}, "separated by a literal"},
+		{"catch-all in the middle", func(engine *Engine) {
+			engine.GET("/img/:name.*ext", handler)
+		}, "catch-all must be the first"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := catchPanic(func() { tt.register(New()) })
+			msg, _ := rec.(string)
+			if !strings.Contains(msg, tt.want) {
+				t.Fatalf("panic = %v, want message containing %q", rec, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteOptionalParams(t *testing.T) {
+	engine := New()
+	engine.WithMeta(RouteName("archive")).GET("/archive/:year/:month?/:day?", func(c *Context) {
+		_, hasDay := c.Params.Get("day")
+		c.String(http.StatusOK, "%s|%s|%s|%t|%s", c.Param("year"), c.Param("month"), c.Param("day"), hasDay, c.FullPath())
+	})
+	engine.GET("/docs/:page?", func(c *Context) {
+		c.String(http.StatusOK, "page=%s", c.Param("page"))
+	})
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/archive/2024", http.StatusOK, "2024|||true|/archive/:year/:month?/:day?"},
+		{"/archive/2024/05", http.StatusOK, "2024|05||true|/archive/:year/:month?/:day?"},
+		{"/archive/2024/05/17", http.StatusOK, "2024|05|17|true|/archive/:year/:month?/:day?"},
+		{"/archive/2024/05/17/x", http.StatusNotFound, ""},
+		{"/docs", http.StatusOK, "page="},
+		{"/docs/intro", http.StatusOK, "page=intro"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := PerformRequest(engine, http.MethodGet, tt.path, nil, nil)
+			if w.Code != tt.code {
+				t.Fatalf("status = %d, want %d", w.Code, tt.code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Fatalf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		params []any
+		want   string
+	}{
+		{[]any{"year", 2024}, "/archive/2024"},
+		{[]any{"year", 2024, "month", 5}, "/archive/2024/5"},
+		{[]any{"year", 2024, "month", 5, "day", 17, "lang", "en"}, "/archive/2024/5/17?lang=en"},
+	} {
+		if got, err := engine.URLFor("archive", tt.params...); err != nil || got != tt.want {
+			t.Errorf("URLFor(%v) = %q, %v; want %q", tt.params, got, err, tt.want)
+		}
+	}
+	if _, err := engine.URLFor("archive", "year", 2024, "day", 17); err == nil {
+		t.Error("URLFor with day but without month should fail")
+	}
+
+	wildcards := routeWildcards("/archive/:year/:month?")
+	if len(wildcards) != 2 || wildcards[1] != (RouteWildcard{Name: "month", Kind: WildcardParam, Optional: true}) {
+		t.Fatalf("routeWildcards = %+v", wildcards)
+	}
+}
+
+func TestRouteOptionalParamsConflicts(t *testing.T) {
+	handler := func(c *Context) {}
+	tests := []struct {
+		name     string
+		register func(engine *Engine)
+		want     string
+	}{
+		{"not trailing", func(engine *Engine) {
+			engine.GET("/archive/:year?/posts", handler)
+		}, "must be the trailing segments"},
+		{"plain after optional", func(engine *Engine) {
+			engine.GET("/archive/:year/:month?", handler)
+			engine.GET("/archive/:year", handler)
+		}, "optional parameters"},
+		{"optional after plain", func(engine *Engine) {
+			engine.GET("/archive/:year", handler)
+			engine.GET("/archive/:year/:month?", handler)
+		}, "conflicts with existing route"},
+		{"overlapping optional", func(engine *Engine) {
+			engine.GET("/archive/:year/:month?", handler)
+			engine.GET("/archive/:year/:month/:day?", handler)
+		}, "conflicts with existing route"},
+		{"duplicate", func(engine *Engine) {
+			engine.GET("/archive/:year/:month?", handler)
+			engine.GET("/archive/:year/:month?", handler)
+		}, "already registered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := catchPanic(func() { tt.register(New()) })
+			msg, _ := rec.(string)
+			if !strings.Contains(msg, tt.want) {
+				t.Fatalf("panic = %v, want message containing %q", rec, tt.want)
+			}
+		})
+	}
+}
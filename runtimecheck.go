@@ -0,0 +1,172 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// 运行时检查读取的系统文件, 测试中可以替换
+var (
+	cgroupRoot     = "/sys/fs/cgroup"
+	procLimitsPath = "/proc/self/limits"
+)
+
+const (
+	// memoryLimitRatio 为推荐的 GOMEMLIMIT 占容器内存上限的比例, 为非堆内存与突发分配留出余量
+	memoryLimitRatio = 0.9
+	// minOpenFiles 为建议的最小打开文件数上限, 低于它时高并发下容易出现 "too many open files"
+	minOpenFiles = 4096
+)
+
+// RuntimeReport 是容器资源限制与 Go 运行时设置的对照
+type RuntimeReport struct {
+	// CPUQuota 为 cgroup 限制的 CPU 核数, 0 表示未限制
+	CPUQuota float64
+	// GOMAXPROCS 为当前值, RecommendedGOMAXPROCS 为按 CPUQuota 向上取整的推荐值 (未限制时等于当前值)
+	GOMAXPROCS            int
+	RecommendedGOMAXPROCS int
+	// MemoryLimit 为 cgroup 内存上限 (字节), 0 表示未限制
+	MemoryLimit int64
+	// GoMemoryLimit 为当前的软内存上限 (GOMEMLIMIT), math.MaxInt64 表示未设置
+	GoMemoryLimit int64
+	// RecommendedGoMemoryLimit 为 MemoryLimit 的 90%, 未限制时为 0
+	RecommendedGoMemoryLimit int64
+	// OpenFiles 为打开文件数的软上限, 0 表示无法获取
+	OpenFiles uint64
+	// Warnings 为发现的配置问题
+	Warnings []string
+}
+
+// CheckRuntime 检测 cgroup 的 CPU 与内存限制以及打开文件数上限, 与当前的 GOMAXPROCS、GOMEMLIMIT 对照.
+// 非 Linux 系统或未运行在容器中时只报告运行时的当前值
+func CheckRuntime() RuntimeReport {
+	r := RuntimeReport{
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		GoMemoryLimit: debug.SetMemoryLimit(-1),
+		CPUQuota:      cgroupCPUQuota(),
+		MemoryLimit:   cgroupMemoryLimit(),
+		OpenFiles:     openFilesLimit(),
+	}
+
+	r.RecommendedGOMAXPROCS = r.GOMAXPROCS
+	if r.CPUQuota > 0 {
+		r.RecommendedGOMAXPROCS = max(1, min(int(math.Ceil(r.CPUQuota)), runtime.NumCPU()))
+		if r.GOMAXPROCS != r.RecommendedGOMAXPROCS {
+			r.Warnings = append(r.Warnings, fmt.Sprintf(
+				"GOMAXPROCS is %d but the container CPU quota is %.2f cores; recommended GOMAXPROCS=%d",
+				r.GOMAXPROCS, r.CPUQuota, r.RecommendedGOMAXPROCS))
+		}
+	}
+
+	if r.MemoryLimit > 0 {
+		r.RecommendedGoMemoryLimit = int64(float64(r.MemoryLimit) * memoryLimitRatio)
+		if r.GoMemoryLimit == math.MaxInt64 {
+			r.Warnings = append(r.Warnings, fmt.Sprintf(
+				"container memory limit is %d MiB but GOMEMLIMIT is not set; recommended GOMEMLIMIT=%dMiB",
+				r.MemoryLimit>>20, r.RecommendedGoMemoryLimit>>20))
+		} else if r.GoMemoryLimit > r.MemoryLimit {
+			r.Warnings = append(r.Warnings, fmt.Sprintf(
+				"GOMEMLIMIT (%d MiB) exceeds the container memory limit (%d MiB); recommended GOMEMLIMIT=%dMiB",
+				r.GoMemoryLimit>>20, r.MemoryLimit>>20, r.RecommendedGoMemoryLimit>>20))
+		}
+	}
+
+	if r.OpenFiles > 0 && r.OpenFiles < minOpenFiles {
+		r.Warnings = append(r.Warnings, fmt.Sprintf(
+			"open file limit is %d; raise it to at least %d (ulimit -n / LimitNOFILE) to serve many concurrent connections",
+			r.OpenFiles, minOpenFiles))
+	}
+	return r
+}
+
+// Apply 按推荐值设置 GOMAXPROCS 与软内存上限, 并返回实际做出的调整.
+// 通过 GOMAXPROCS 或 GOMEMLIMIT 环境变量显式设置的值不会被覆盖; 打开文件数上限需要在进程外调整
+func (r RuntimeReport) Apply() []string {
+	var applied []string
+	if r.RecommendedGOMAXPROCS != r.GOMAXPROCS && os.Getenv("GOMAXPROCS") == "" {
+		runtime.GOMAXPROCS(r.RecommendedGOMAXPROCS)
+		applied = append(applied, fmt.Sprintf("GOMAXPROCS=%d", r.RecommendedGOMAXPROCS))
+	}
+	if r.RecommendedGoMemoryLimit > 0 && os.Getenv("GOMEMLIMIT") == "" &&
+		(r.GoMemoryLimit == math.MaxInt64 || r.GoMemoryLimit > r.MemoryLimit) {
+		debug.SetMemoryLimit(r.RecommendedGoMemoryLimit)
+		applied = append(applied, fmt.Sprintf("GOMEMLIMIT=%dMiB", r.RecommendedGoMemoryLimit>>20))
+	}
+	return applied
+}
+
+// cgroupCPUQuota 读取 cgroup v2 的 cpu.max 或 v1 的 cfs 配额, 返回核数
+func cgroupCPUQuota() float64 {
+	if data, err := os.ReadFile(filepath.Join(cgroupRoot, "cpu.max")); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 && fields[0] != "max" {
+			return cpuQuota(fields[0], fields[1])
+		}
+		return 0
+	}
+	quota, err1 := os.ReadFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_quota_us"))
+	period, err2 := os.ReadFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_period_us"))
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	return cpuQuota(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+func cpuQuota(quota, period string) float64 {
+	q, err1 := strconv.ParseFloat(quota, 64)
+	p, err2 := strconv.ParseFloat(period, 64)
+	if err1 != nil || err2 != nil || q <= 0 || p <= 0 {
+		return 0
+	}
+	return q / p
+}
+
+// cgroupMemoryLimit 读取 cgroup v2 的 memory.max 或 v1 的 memory.limit_in_bytes
+func cgroupMemoryLimit() int64 {
+	data, err := os.ReadFile(filepath.Join(cgroupRoot, "memory.max"))
+	if err != nil {
+		if data, err = os.ReadFile(filepath.Join(cgroupRoot, "memory", "memory.limit_in_bytes")); err != nil {
+			return 0
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	// v1 未限制时为接近 MaxInt64 的按页对齐值
+	if err != nil || n <= 0 || n >= math.MaxInt64/2 {
+		return 0
+	}
+	return n
+}
+
+// openFilesLimit 从 /proc/self/limits 读取打开文件数的软上限
+func openFilesLimit() uint64 {
+	data, err := os.ReadFile(procLimitsPath)
+	if err != nil {
+		return 0
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		rest, ok := strings.CutPrefix(line, "Max open files")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return 0
+		}
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package touka
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func fakeRuntimeFiles(t *testing.T, files map[string]string) {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldRoot, oldLimits := cgroupRoot, procLimitsPath
+	cgroupRoot, procLimitsPath = filepath.Join(dir, "cgroup"), filepath.Join(dir, "limits")
+	t.Cleanup(func() { cgroupRoot, procLimitsPath = oldRoot, oldLimits })
+}
+
+func TestCheckRuntimeCgroupV2(t *testing.T) {
+	fakeRuntimeFiles(t, map[string]string{
+		"cgroup/cpu.max":    "150000 100000\n",
+		"cgroup/memory.max": "536870912\n",
+		"limits":            "Limit                     Soft Limit           Hard Limit           Units\nMax open files            1024                 1048576              files\n",
+	})
+	procs, memLimit := runtime.GOMAXPROCS(0), debug.SetMemoryLimit(-1)
+	t.Cleanup(func() {
+		runtime.GOMAXPROCS(procs)
+		debug.SetMemoryLimit(memLimit)
+	})
+	debug.SetMemoryLimit(math.MaxInt64)
+	t.Setenv("GOMAXPROCS", "")
+	t.Setenv("GOMEMLIMIT", "")
+
+	r := CheckRuntime()
+	if r.CPUQuota != 1.5 || r.MemoryLimit != 512<<20 || r.OpenFiles != 1024 {
+		t.Fatalf("unexpected detection %+v", r)
+	}
+	if want := min(2, runtime.NumCPU()); r.RecommendedGOMAXPROCS != want {
+		t.Fatalf("expected GOMAXPROCS %d to be recommended, got %d", want, r.RecommendedGOMAXPROCS)
+	}
+	warnings := strings.Join(r.Warnings, "\n")
+	if !strings.Contains(warnings, "GOMEMLIMIT=460MiB") || !strings.Contains(warnings, "open file limit is 1024") {
+		t.Fatalf("unexpected warnings %q", warnings)
+	}
+
+	r.Apply()
+	if got := debug.SetMemoryLimit(-1); got != r.RecommendedGoMemoryLimit {
+		t.Fatalf("expected the memory limit to be applied, got %d", got)
+	}
+	if runtime.GOMAXPROCS(0) != r.RecommendedGOMAXPROCS {
+		t.Fatalf("expected GOMAXPROCS to be applied, got %d", runtime.GOMAXPROCS(0))
+	}
+}
+
+func TestCheckRuntimeUnlimited(t *testing.T) {
+	fakeRuntimeFiles(t, map[string]string{
+		"cgroup/cpu/cpu.cfs_quota_us":         "-1\n",
+		"cgroup/cpu/cpu.cfs_period_us":        "100000\n",
+		"cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n",
+	})
+	r := CheckRuntime()
+	if r.CPUQuota != 0 || r.MemoryLimit != 0 || r.OpenFiles != 0 || len(r.Warnings) != 0 {
+		t.Fatalf("expected no limits or warnings, got %+v", r)
+	}
+	if applied := r.Apply(); len(applied) != 0 {
+		t.Fatalf("nothing should be applied, got %v", applied)
+	}
+}
@@ -0,0 +1,229 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"html/template"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SanitizePolicy 是 HTML 清理的白名单. 不在白名单中的标签被移除但保留其文本,
+// script、style 等标签连同内容一起移除; 不在白名单中的属性与 on* 事件属性总是被移除
+type SanitizePolicy struct {
+	// Elements 为允许的标签及其允许的属性, 例如 {"a": {"href", "title"}, "p": nil}
+	Elements map[string][]string
+	// GlobalAttributes 为所有允许的标签都可以使用的属性
+	GlobalAttributes []string
+	// URLSchemes 为 href、src 等链接属性允许的协议, 为空时为 http、https 与 mailto; 相对地址总是允许
+	URLSchemes []string
+	// LinkRel 非空时为所有 a 标签设置 rel 属性, 例如 "nofollow noopener"
+	LinkRel string
+}
+
+// DefaultSanitizePolicy 返回适合评论、帖子等用户内容的策略: 允许常见的文本格式、列表、引用、代码、表格、链接与图片
+func DefaultSanitizePolicy() SanitizePolicy {
+	text := []string{"p", "br", "hr", "b", "i", "em", "strong", "u", "s", "del", "ins", "sub", "sup", "small", "mark",
+		"h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "li", "dl", "dt", "dd", "blockquote", "code", "pre", "kbd",
+		"table", "thead", "tbody", "tfoot", "tr", "th", "td", "caption", "span", "div"}
+	elements := make(map[string][]string, len(text)+3)
+	for _, tag := range text {
+		elements[tag] = nil
+	}
+	elements["a"] = []string{"href", "title"}
+	elements["img"] = []string{"src", "alt", "title", "width", "height"}
+	elements["abbr"] = []string{"title"}
+	elements["th"] = []string{"colspan", "rowspan"}
+	elements["td"] = []string{"colspan", "rowspan"}
+	return SanitizePolicy{Elements: elements, LinkRel: "nofollow noopener noreferrer"}
+}
+
+// Sanitizer 按 SanitizePolicy 清理 HTML, 可以在多个 goroutine 中并发使用
+type Sanitizer struct {
+	elements map[string]map[string]bool
+	schemes  []string
+	linkRel  string
+}
+
+// NewSanitizer 按策略创建清理器
+func NewSanitizer(policy SanitizePolicy) *Sanitizer {
+	s := &Sanitizer{
+		elements: make(map[string]map[string]bool, len(policy.Elements)),
+		schemes:  policy.URLSchemes,
+		linkRel:  policy.LinkRel,
+	}
+	if len(s.schemes) == 0 {
+		s.schemes = []string{"http", "https", "mailto"}
+	}
+	for tag, attrs := range policy.Elements {
+		tag = strings.ToLower(tag)
+		if sanitizeDropContent[tag] {
+			continue // 这些标签的内容不是普通文本, 不能安全地保留
+		}
+		allowed := make(map[string]bool, len(attrs)+len(policy.GlobalAttributes))
+		for _, a := range attrs {
+			allowed[strings.ToLower(a)] = true
+		}
+		for _, a := range policy.GlobalAttributes {
+			allowed[strings.ToLower(a)] = true
+		}
+		s.elements[tag] = allowed
+	}
+	return s
+}
+
+// sanitizeDropContent 为连同内容一起移除的标签
+var sanitizeDropContent = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "noscript": true,
+	"template": true, "textarea": true, "title": true, "svg": true, "math": true, "xmp": true,
+	"noembed": true, "noframes": true, "plaintext": true, "frameset": true, "frame": true,
+}
+
+// sanitizeURLAttrs 为值是链接的属性
+var sanitizeURLAttrs = map[string]bool{
+	"href": true, "src": true, "cite": true, "action": true, "formaction": true, "poster": true, "background": true, "longdesc": true,
+}
+
+// sanitizeVoid 为没有结束标签的元素
+var sanitizeVoid = map[string]bool{
+	"br": true, "hr": true, "img": true, "wbr": true, "col": true, "area": true, "source": true, "track": true,
+}
+
+// Sanitize 清理 HTML 片段, 返回只包含白名单标签与属性的 HTML. 未闭合的标签会被补全
+func (s *Sanitizer) Sanitize(input string) string {
+	var sb strings.Builder
+	z := html.NewTokenizer(strings.NewReader(input))
+	var open []string // 已输出且尚未闭合的标签
+	skip := ""        // 正在跳过内容的标签
+	skipDepth := 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break // io.EOF 或输入中的错误, 已输出的部分仍然是安全的
+		}
+		tok := z.Token()
+		if skip != "" {
+			switch {
+			case tt == html.StartTagToken && tok.Data == skip:
+				skipDepth++
+			case tt == html.EndTagToken && tok.Data == skip:
+				if skipDepth--; skipDepth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			sb.WriteString(html.EscapeString(tok.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if sanitizeDropContent[tok.Data] {
+				if tt == html.StartTagToken {
+					skip, skipDepth = tok.Data, 1
+				}
+				continue
+			}
+			allowed, ok := s.elements[tok.Data]
+			if !ok {
+				continue
+			}
+			s.writeStartTag(&sb, tok, allowed)
+			if sanitizeVoid[tok.Data] {
+				continue
+			}
+			if tt == html.SelfClosingTagToken {
+				sb.WriteString("</" + tok.Data + ">")
+				continue
+			}
+			open = append(open, tok.Data)
+		case html.EndTagToken:
+			i := len(open) - 1
+			for i >= 0 && open[i] != tok.Data {
+				i--
+			}
+			if i < 0 {
+				continue
+			}
+			// 闭合该标签以及其中尚未闭合的标签
+			for j := len(open) - 1; j >= i; j-- {
+				sb.WriteString("</" + open[j] + ">")
+			}
+			open = open[:i]
+		}
+		// 注释与 DOCTYPE 被丢弃
+	}
+	for j := len(open) - 1; j >= 0; j-- {
+		sb.WriteString("</" + open[j] + ">")
+	}
+	return sb.String()
+}
+
+func (s *Sanitizer) writeStartTag(sb *strings.Builder, tok html.Token, allowed map[string]bool) {
+	sb.WriteString("<" + tok.Data)
+	seen := make(map[string]bool, len(tok.Attr))
+	for _, attr := range tok.Attr {
+		key := attr.Key
+		if attr.Namespace != "" || !allowed[key] || strings.HasPrefix(key, "on") || seen[key] {
+			continue
+		}
+		if tok.Data == "a" && key == "rel" && s.linkRel != "" {
+			continue
+		}
+		if sanitizeURLAttrs[key] && !s.allowedURL(attr.Val) {
+			continue
+		}
+		seen[key] = true
+		sb.WriteString(" " + key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if tok.Data == "a" && s.linkRel != "" {
+		sb.WriteString(` rel="` + html.EscapeString(s.linkRel) + `"`)
+	}
+	sb.WriteString(">")
+}
+
+// allowedURL 报告链接是否为相对地址或使用允许的协议
+func (s *Sanitizer) allowedURL(raw string) bool {
+	// 含有控制字符 (例如 "java\tscript:", 浏览器会忽略其中的空白) 的地址 url.Parse 会返回错误
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || slices.Contains(s.schemes, strings.ToLower(u.Scheme))
+}
+
+var defaultSanitizer = NewSanitizer(DefaultSanitizePolicy())
+
+// SetSanitizer 设置 c.SanitizedHTML 与模板函数 sanitize 使用的清理器, nil 表示使用 DefaultSanitizePolicy
+func (engine *Engine) SetSanitizer(s *Sanitizer) {
+	engine.sanitizer = s
+}
+
+func (engine *Engine) htmlSanitizer() *Sanitizer {
+	if engine == nil || engine.sanitizer == nil {
+		return defaultSanitizer
+	}
+	return engine.sanitizer
+}
+
+// SanitizedHTML 按引擎的清理策略清理用户提交的 HTML, 返回的内容可以直接在模板中输出而不会被再次转义
+func (c *Context) SanitizedHTML(s string) template.HTML {
+	return template.HTML(c.engine.htmlSanitizer().Sanitize(s))
+}
+
+// sanitizeFuncMap 返回模板函数 sanitize, 按引擎的清理策略清理用户内容:
+//
+//	<div class="comment">{{sanitize .Comment.Body}}</div>
+func (engine *Engine) sanitizeFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"sanitize": func(s string) template.HTML {
+			return template.HTML(engine.htmlSanitizer().Sanitize(s))
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package touka
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeDefaultPolicy(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"allowed markup", `<p>Hello <b>world</b></p>`, `<p>Hello <b>world</b></p>`},
+		{"script removed with content", `a<script>alert(1)</script>b`, `ab`},
+		{"nested drop content", `<svg><svg></svg><p>x</p></svg>ok`, `ok`},
+		{"event handler stripped", `<p onclick="x()" id="a">t</p>`, `<p>t</p>`},
+		{"unknown tag keeps text", `<blink>hi</blink> <font color=red>there</font>`, `hi there`},
+		{"javascript link dropped", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"entity encoded scheme", `<a href="&#106;avascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"control char in scheme", "<a href=\"java\tscript:alert(1)\">x</a>", `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"link rel enforced", `<a href="https://example.com/?a=1&b=2" rel="me" title="t">x</a>`, `<a href="https://example.com/?a=1&amp;b=2" title="t" rel="nofollow noopener noreferrer">x</a>`},
+		{"relative link kept", `<a href="/u/1">x</a>`, `<a href="/u/1" rel="nofollow noopener noreferrer">x</a>`},
+		{"unclosed tags closed", `<p><b>bold<i>both`, `<p><b>bold<i>both</i></b></p>`},
+		{"stray end tag ignored", `</div>text</b>`, `text`},
+		{"void element", `line<br>next<img src="/a.png" onerror="x()">`, `line<br>next<img src="/a.png">`},
+		{"comment dropped", `a<!-- <script>x</script> -->b`, `ab`},
+		{"text escaped", `1 < 2 & "q"`, `1 &lt; 2 &amp; &#34;q&#34;`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultSanitizer.Sanitize(tt.in); got != tt.want {
+				t.Fatalf("Sanitize(%q)\n got  %q\n want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeCustomPolicy(t *testing.T) {
+	s := NewSanitizer(SanitizePolicy{
+		Elements:         map[string][]string{"A": {"HREF"}, "span": nil, "script": nil},
+		GlobalAttributes: []string{"class"},
+		URLSchemes:       []string{"https"},
+	})
+	in := `<span class="x" style="color:red">a</span><a href="http://e.com" class="l">b</a><a href="https://e.com">c</a><script>d</script>`
+	want := `<span class="x">a</span><a class="l">b</a><a href="https://e.com">c</a>`
+	if got := s.Sanitize(in); got != want {
+		t.Fatalf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestSanitizedHTMLAndTemplateFunc(t *testing.T) {
+	c, engine := CreateTestContext(httptest.NewRecorder())
+	if got := c.SanitizedHTML(`<em onclick="x">hi</em>`); got != `<em>hi</em>` {
+		t.Fatalf("SanitizedHTML = %q", got)
+	}
+
+	engine.SetSanitizer(NewSanitizer(SanitizePolicy{Elements: map[string][]string{"b": nil}}))
+	tpl := template.Must(template.New("page").Funcs(engine.sanitizeFuncMap()).Parse(`<div>{{sanitize .}}</div>`))
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, `<b>ok</b><em>plain</em><script>x</script>`); err != nil {
+		t.Fatal(err)
+	}
+	if got := sb.String(); got != `<div><b>ok</b>plain</div>` {
+		t.Fatalf("template output = %q", got)
+	}
+}
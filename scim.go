@@ -0,0 +1,538 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+const (
+	SCIMSchemaUser  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup = "urn:ietf:params:scim:schemas:core:2.0:Group"
+
+	scimSchemaList     = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimSchemaPatchOp  = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	scimSchemaError    = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimSchemaSPConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimContentType    = "application/scim+json"
+)
+
+var (
+	// ErrSCIMNotFound 表示资源不存在, 存储应在 Get、Replace 与 Delete 中返回
+	ErrSCIMNotFound = errors.New("scim: resource not found")
+	// ErrSCIMConflict 表示唯一属性 (例如 userName) 冲突, 存储应在 Create 与 Replace 中返回
+	ErrSCIMConflict = errors.New("scim: uniqueness conflict")
+	// ErrSCIMInvalidFilter 表示过滤表达式无法解析
+	ErrSCIMInvalidFilter = errors.New("scim: invalid filter")
+	// ErrSCIMInvalidPath 表示 PATCH 路径无法解析或指向不可修改的属性
+	ErrSCIMInvalidPath = errors.New("scim: invalid path")
+	// ErrSCIMNoTarget 表示 PATCH 路径没有匹配任何值
+	ErrSCIMNoTarget = errors.New("scim: no target")
+	// ErrSCIMInvalidValue 表示请求中的值或操作无效
+	ErrSCIMInvalidValue = errors.New("scim: invalid value")
+)
+
+// SCIMResource 是一个 SCIM 资源的 JSON 表示. 属性保持客户端提交的形式,
+// 由存储决定如何映射到本地的用户或组模型
+type SCIMResource map[string]any
+
+// ID 返回资源的 id
+func (r SCIMResource) ID() string {
+	id, _ := r["id"].(string)
+	return id
+}
+
+// SCIMListQuery 是列表请求的参数
+type SCIMListQuery struct {
+	// Filter 为 ?filter= 解析后的过滤器, 未指定时为 nil
+	Filter *SCIMFilter
+	// StartIndex 为从 1 开始的起始位置
+	StartIndex int
+	// Count 为最多返回的资源数, 已按 SCIMOptions.MaxResults 截断; 为 0 时只需返回总数
+	Count int
+}
+
+// SCIMStore 是 SCIM 资源的存储后端. 资源的 id 与 meta.created、meta.lastModified 由存储维护,
+// meta.resourceType 与 meta.location 由处理器填写
+type SCIMStore interface {
+	// List 返回满足查询的一页资源及满足过滤条件的总数
+	List(ctx context.Context, q SCIMListQuery) (resources []SCIMResource, total int, err error)
+	// Get 返回资源, 不存在时返回 ErrSCIMNotFound
+	Get(ctx context.Context, id string) (SCIMResource, error)
+	// Create 分配 id 并保存资源
+	Create(ctx context.Context, res SCIMResource) (SCIMResource, error)
+	// Replace 以 res 整体替换已有资源
+	Replace(ctx context.Context, id string, res SCIMResource) (SCIMResource, error)
+	// Delete 删除资源
+	Delete(ctx context.Context, id string) error
+}
+
+// SCIMOptions 配置 SCIM 2.0 端点
+type SCIMOptions struct {
+	// Users 为 /Users 的存储, 必填
+	Users SCIMStore
+	// Groups 为 /Groups 的存储, 为 nil 时不注册 /Groups
+	Groups SCIMStore
+	// MaxResults 为单页最多返回的资源数, 默认 100
+	MaxResults int
+	// Middleware 为所有 SCIM 路由添加的中间件, 通常是校验身份提供方令牌的 APIKeyAuth 或 StreamAuth
+	Middleware []HandlerFunc
+}
+
+// MountSCIM 在 relativePath 下注册 SCIM 2.0 (RFC 7644) 用户供应端点:
+//
+//	GET    /scim/v2/ServiceProviderConfig
+//	GET    /scim/v2/Users?filter=userName eq "bjensen"&startIndex=1&count=10
+//	POST   /scim/v2/Users
+//	GET    /scim/v2/Users/:id
+//	PUT    /scim/v2/Users/:id
+//	PATCH  /scim/v2/Users/:id
+//	DELETE /scim/v2/Users/:id
+//
+// 设置 Groups 时以相同的方式注册 /Groups. PATCH 在引擎的 Locker 下读取、应用操作并整体替换资源,
+// 存储只需实现整体读写. 错误以 SCIM 错误响应返回
+func (engine *Engine) MountSCIM(relativePath string, opts SCIMOptions) {
+	mountSCIM(engine, relativePath, opts)
+}
+
+// MountSCIM 在路由组下注册 SCIM 端点, 参见 Engine.MountSCIM
+func (group *RouterGroup) MountSCIM(relativePath string, opts SCIMOptions) {
+	mountSCIM(group, relativePath, opts)
+}
+
+func mountSCIM(router Router, relativePath string, opts SCIMOptions) {
+	if opts.Users == nil {
+		panic("touka: scim users store must not be nil")
+	}
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = 100
+	}
+
+	base := "/" + strings.Trim(relativePath, "/")
+	if base == "/" {
+		base = ""
+	}
+	chain := func(h HandlerFunc) []HandlerFunc {
+		return append(slices.Clone(opts.Middleware), h)
+	}
+
+	router.GET(base+"/ServiceProviderConfig", chain(func(c *Context) {
+		scimJSON(c, http.StatusOK, map[string]any{
+			"schemas":               []string{scimSchemaSPConfig},
+			"patch":                 map[string]any{"supported": true},
+			"bulk":                  map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+			"filter":                map[string]any{"supported": true, "maxResults": opts.MaxResults},
+			"changePassword":        map[string]any{"supported": false},
+			"sort":                  map[string]any{"supported": false},
+			"etag":                  map[string]any{"supported": false},
+			"authenticationSchemes": []any{},
+		})
+	})...)
+
+	resources := []struct {
+		name, schema string
+		store        SCIMStore
+	}{
+		{"User", SCIMSchemaUser, opts.Users},
+		{"Group", SCIMSchemaGroup, opts.Groups},
+	}
+	for _, r := range resources {
+		if r.store == nil {
+			continue
+		}
+		rt := &scimResourceType{name: r.name, schema: r.schema, store: r.store, maxResults: opts.MaxResults}
+		collection := base + "/" + r.name + "s"
+		member := collection + "/:id"
+		router.GET(collection, chain(rt.list)...)
+		router.POST(collection, chain(rt.create)...)
+		router.GET(member, chain(rt.get)...)
+		router.PUT(member, chain(rt.replace)...)
+		router.PATCH(member, chain(rt.patch)...)
+		router.DELETE(member, chain(rt.delete)...)
+	}
+}
+
+// scimResourceType 是一种资源 (User 或 Group) 的处理器集合
+type scimResourceType struct {
+	name       string
+	schema     string
+	store      SCIMStore
+	maxResults int
+}
+
+func (rt *scimResourceType) list(c *Context) {
+	q := SCIMListQuery{StartIndex: 1, Count: rt.maxResults}
+	if f := c.Query("filter"); f != "" {
+		filter, err := ParseSCIMFilter(f)
+		if err != nil {
+			scimError(c, err)
+			return
+		}
+		q.Filter = filter
+	}
+	if v := c.Query("startIndex"); v != "" {
+		// 小于 1 的值按 1 处理 (RFC 7644 3.4.2.4)
+		if n, err := strconv.Atoi(v); err == nil && n > 1 {
+			q.StartIndex = n
+		}
+	}
+	if v := c.Query("count"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			q.Count = min(max(n, 0), rt.maxResults)
+		}
+	}
+
+	items, total, err := rt.store.List(c.Context(), q)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	for _, res := range items {
+		rt.decorate(c, res)
+	}
+	if items == nil {
+		items = []SCIMResource{}
+	}
+	scimJSON(c, http.StatusOK, map[string]any{
+		"schemas":      []string{scimSchemaList},
+		"totalResults": total,
+		"startIndex":   q.StartIndex,
+		"itemsPerPage": len(items),
+		"Resources":    items,
+	})
+}
+
+func (rt *scimResourceType) get(c *Context) {
+	res, err := rt.store.Get(c.Context(), c.Param("id"))
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	rt.respond(c, http.StatusOK, res)
+}
+
+func (rt *scimResourceType) create(c *Context) {
+	var res SCIMResource
+	if err := scimBind(c, &res); err != nil {
+		scimError(c, err)
+		return
+	}
+	if res == nil {
+		scimError(c, fmt.Errorf("%w: resource must be an object", ErrSCIMInvalidValue))
+		return
+	}
+	delete(res, "id")
+	delete(res, "meta")
+	if _, ok := res["schemas"]; !ok {
+		res["schemas"] = []any{rt.schema}
+	}
+	created, err := rt.store.Create(c.Context(), res)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	rt.decorate(c, created)
+	c.SetHeader("Location", created["meta"].(map[string]any)["location"].(string))
+	scimJSON(c, http.StatusCreated, created)
+}
+
+func (rt *scimResourceType) replace(c *Context) {
+	var res SCIMResource
+	if err := scimBind(c, &res); err != nil {
+		scimError(c, err)
+		return
+	}
+	if res == nil {
+		scimError(c, fmt.Errorf("%w: resource must be an object", ErrSCIMInvalidValue))
+		return
+	}
+	id := c.Param("id")
+	res["id"] = id
+	delete(res, "meta")
+	updated, err := rt.store.Replace(c.Context(), id, res)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	rt.respond(c, http.StatusOK, updated)
+}
+
+func (rt *scimResourceType) patch(c *Context) {
+	var req struct {
+		Schemas    []string      `json:"schemas"`
+		Operations []SCIMPatchOp `json:"Operations"`
+	}
+	if err := scimBind(c, &req); err != nil {
+		scimError(c, err)
+		return
+	}
+	if !slices.Contains(req.Schemas, scimSchemaPatchOp) || len(req.Operations) == 0 {
+		scimError(c, fmt.Errorf("%w: request must be a PatchOp message with at least one operation", ErrSCIMInvalidValue))
+		return
+	}
+
+	id := c.Param("id")
+	unlock, err := c.Lock("scim:"+rt.name+":"+id, time.Minute)
+	if err != nil {
+		c.AddError(err)
+		c.ErrorUseHandle(http.StatusServiceUnavailable, err)
+		return
+	}
+	defer unlock()
+
+	res, err := rt.store.Get(c.Context(), id)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	if err := ApplySCIMPatch(res, req.Operations); err != nil {
+		scimError(c, err)
+		return
+	}
+	res["id"] = id
+	updated, err := rt.store.Replace(c.Context(), id, res)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	rt.respond(c, http.StatusOK, updated)
+}
+
+func (rt *scimResourceType) delete(c *Context) {
+	if err := rt.store.Delete(c.Context(), c.Param("id")); err != nil {
+		scimError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (rt *scimResourceType) respond(c *Context, code int, res SCIMResource) {
+	rt.decorate(c, res)
+	scimJSON(c, code, res)
+}
+
+// decorate 填写 meta.resourceType 与 meta.location
+func (rt *scimResourceType) decorate(c *Context, res SCIMResource) {
+	meta, ok := res["meta"].(map[string]any)
+	if !ok {
+		meta = make(map[string]any)
+		res["meta"] = meta
+	}
+	meta["resourceType"] = rt.name
+	collection := c.FullPath()
+	if i := strings.LastIndex(collection, "/"+rt.name+"s"); i >= 0 {
+		collection = collection[:i+len(rt.name)+2]
+	}
+	meta["location"] = c.AbsoluteURL(collection + "/" + res.ID())
+}
+
+// scimBind 读取请求体中的 JSON, SCIM 客户端通常使用 application/scim+json
+func scimBind(c *Context, v any) error {
+	body := c.bindRequestBody()
+	if body == nil {
+		return fmt.Errorf("%w: request body is empty", ErrSCIMInvalidValue)
+	}
+	data, err := c.readAllBody(body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %w", errSCIMInvalidSyntax, err)
+	}
+	return nil
+}
+
+// errSCIMInvalidSyntax 表示请求体不是有效的 JSON
+var errSCIMInvalidSyntax = errors.New("scim: invalid syntax")
+
+// scimJSON 以 application/scim+json 写入响应. SCIM 属性名由协议规定, 因此不使用引擎的 JSON 命名配置
+func scimJSON(c *Context, code int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		c.AddError(fmt.Errorf("scim: marshal response: %w", err))
+		c.ErrorUseHandle(http.StatusInternalServerError, err)
+		return
+	}
+	c.Raw(code, scimContentType, data)
+}
+
+// scimError 将错误映射为 SCIM 错误响应 (RFC 7644 3.12)
+func scimError(c *Context, err error) {
+	code, scimType := http.StatusInternalServerError, ""
+	switch {
+	case errors.Is(err, ErrSCIMNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrSCIMConflict):
+		code, scimType = http.StatusConflict, "uniqueness"
+	case errors.Is(err, ErrSCIMInvalidPath):
+		code, scimType = http.StatusBadRequest, "invalidPath"
+	case errors.Is(err, ErrSCIMInvalidFilter):
+		code, scimType = http.StatusBadRequest, "invalidFilter"
+	case errors.Is(err, ErrSCIMNoTarget):
+		code, scimType = http.StatusBadRequest, "noTarget"
+	case errors.Is(err, ErrSCIMInvalidValue):
+		code, scimType = http.StatusBadRequest, "invalidValue"
+	case errors.Is(err, errSCIMInvalidSyntax):
+		code, scimType = http.StatusBadRequest, "invalidSyntax"
+	case errors.Is(err, ErrBodyTooLarge):
+		code = http.StatusRequestEntityTooLarge
+	}
+
+	detail := err.Error()
+	if code >= http.StatusInternalServerError {
+		c.AddError(err)
+		detail = http.StatusText(code)
+	} else {
+		c.AddClientError(err)
+	}
+	body := map[string]any{
+		"schemas": []string{scimSchemaError},
+		"status":  strconv.Itoa(code),
+		"detail":  detail,
+	}
+	if scimType != "" {
+		body["scimType"] = scimType
+	}
+	scimJSON(c, code, body)
+	c.Abort()
+}
+
+// MemorySCIMStore 是进程内的 SCIMStore 实现, 适用于测试与原型. 过滤通过 SCIMFilter.Match 完成,
+// userName 存在时要求唯一 (不区分大小写)
+type MemorySCIMStore struct {
+	mu        sync.RWMutex
+	resources map[string]SCIMResource
+	order     []string
+}
+
+// NewMemorySCIMStore 创建进程内的 SCIMStore
+func NewMemorySCIMStore() *MemorySCIMStore {
+	return &MemorySCIMStore{resources: make(map[string]SCIMResource)}
+}
+
+func (s *MemorySCIMStore) List(_ context.Context, q SCIMListQuery) ([]SCIMResource, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matched []SCIMResource
+	for _, id := range s.order {
+		res := s.resources[id]
+		if q.Filter == nil || q.Filter.Match(res) {
+			matched = append(matched, res)
+		}
+	}
+	total := len(matched)
+	start := min(max(q.StartIndex, 1)-1, total)
+	end := min(start+q.Count, total)
+	page := make([]SCIMResource, 0, end-start)
+	for _, res := range matched[start:end] {
+		page = append(page, cloneSCIMResource(res))
+	}
+	return page, total, nil
+}
+
+func (s *MemorySCIMStore) Get(_ context.Context, id string) (SCIMResource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res, ok := s.resources[id]
+	if !ok {
+		return nil, ErrSCIMNotFound
+	}
+	return cloneSCIMResource(res), nil
+}
+
+func (s *MemorySCIMStore) Create(_ context.Context, res SCIMResource) (SCIMResource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conflicts(res, "") {
+		return nil, ErrSCIMConflict
+	}
+	res = cloneSCIMResource(res)
+	id := randomHex(16)
+	now := time.Now().UTC().Format(time.RFC3339)
+	res["id"] = id
+	res["meta"] = map[string]any{"created": now, "lastModified": now}
+	s.resources[id] = res
+	s.order = append(s.order, id)
+	return cloneSCIMResource(res), nil
+}
+
+func (s *MemorySCIMStore) Replace(_ context.Context, id string, res SCIMResource) (SCIMResource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.resources[id]
+	if !ok {
+		return nil, ErrSCIMNotFound
+	}
+	if s.conflicts(res, id) {
+		return nil, ErrSCIMConflict
+	}
+	res = cloneSCIMResource(res)
+	res["id"] = id
+	meta := map[string]any{"lastModified": time.Now().UTC().Format(time.RFC3339)}
+	if oldMeta, ok := old["meta"].(map[string]any); ok {
+		meta["created"] = oldMeta["created"]
+	}
+	res["meta"] = meta
+	s.resources[id] = res
+	return cloneSCIMResource(res), nil
+}
+
+func (s *MemorySCIMStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.resources[id]; !ok {
+		return ErrSCIMNotFound
+	}
+	delete(s.resources, id)
+	s.order = slices.DeleteFunc(s.order, func(v string) bool { return v == id })
+	return nil
+}
+
+// conflicts 报告 res 的 userName 是否与 id 之外的资源重复
+func (s *MemorySCIMStore) conflicts(res SCIMResource, id string) bool {
+	name, _ := res["userName"].(string)
+	if name == "" {
+		return false
+	}
+	for otherID, other := range s.resources {
+		if n, _ := other["userName"].(string); otherID != id && strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneSCIMResource 深拷贝资源, 避免调用方 (例如 PATCH) 的修改影响存储中的数据
+func cloneSCIMResource(res SCIMResource) SCIMResource {
+	return cloneSCIMValue(map[string]any(res)).(map[string]any)
+}
+
+func cloneSCIMValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = cloneSCIMValue(e)
+		}
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, e := range v {
+			l[i] = cloneSCIMValue(e)
+		}
+		return l
+	}
+	return v
+}
@@ -0,0 +1,153 @@
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	user := map[string]any{
+		"userName": "BJensen",
+		"active":   true,
+		"name":     map[string]any{"familyName": "Jensen"},
+		"emails": []any{
+			map[string]any{"type": "work", "value": "bjensen@example.com"},
+			map[string]any{"type": "home", "value": "babs@example.org"},
+		},
+		"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": map[string]any{"employeeNumber": "701984"},
+	}
+	cases := []struct {
+		filter string
+		want   bool
+	}{
+		{`userName eq "bjensen"`, true},
+		{`userName ne "bjensen"`, false},
+		{`name.familyName sw "jen" and active eq true`, true},
+		{`emails co "example.org"`, true},
+		{`emails[type eq "work" and value ew "@example.com"]`, true},
+		{`emails[type eq "other"]`, false},
+		{`not (active eq true) or title pr`, false},
+		{`title pr or userName eq "nobody" or name.familyName eq "JENSEN"`, true},
+		{`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber eq "701984"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen"`, true},
+		{`title eq null`, true},
+	}
+	for _, tc := range cases {
+		f, err := ParseSCIMFilter(tc.filter)
+		if err != nil {
+			t.Errorf("%s: %v", tc.filter, err)
+			continue
+		}
+		if got := f.Match(user); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.filter, tc.want, got)
+		}
+	}
+
+	for _, bad := range []string{``, `userName`, `userName xx "a"`, `userName eq "a`, `(userName eq "a"`, `userName eq "a" and`, `emails[type eq "work"`} {
+		if _, err := ParseSCIMFilter(bad); !errors.Is(err, ErrSCIMInvalidFilter) {
+			t.Errorf("%q: expected ErrSCIMInvalidFilter, got %v", bad, err)
+		}
+	}
+}
+
+func TestApplySCIMPatch(t *testing.T) {
+	res := map[string]any{
+		"userName": "bjensen",
+		"active":   true,
+		"name":     map[string]any{"givenName": "Barbara"},
+		"emails":   []any{map[string]any{"type": "work", "value": "old@example.com"}},
+	}
+	err := ApplySCIMPatch(res, []SCIMPatchOp{
+		{Op: "Replace", Path: "active", Value: false},
+		{Op: "replace", Value: map[string]any{"name.familyName": "Jensen", "displayName": "Babs"}},
+		{Op: "replace", Path: `emails[type eq "work"].value`, Value: "new@example.com"},
+		{Op: "add", Path: "emails", Value: []any{map[string]any{"type": "home", "value": "babs@example.org"}}},
+		{Op: "add", Path: `addresses[type eq "work"].locality`, Value: "Hollywood"},
+		{Op: "add", Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", Value: "Tour"},
+		{Op: "remove", Path: `emails[type eq "home"]`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(res, json.Deterministic(true))
+	want := `{"active":false,"addresses":[{"locality":"Hollywood","type":"work"}],"displayName":"Babs",` +
+		`"emails":[{"type":"work","value":"new@example.com"}],"name":{"familyName":"Jensen","givenName":"Barbara"},` +
+		`"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User":{"department":"Tour"},"userName":"bjensen"}`
+	if string(got) != want {
+		t.Fatalf("unexpected result\n got %s\nwant %s", got, want)
+	}
+
+	for _, tc := range []struct {
+		op   SCIMPatchOp
+		want error
+	}{
+		{SCIMPatchOp{Op: "remove"}, ErrSCIMNoTarget},
+		{SCIMPatchOp{Op: "replace", Path: `emails[type eq "other"]`, Value: map[string]any{}}, ErrSCIMNoTarget},
+		{SCIMPatchOp{Op: "replace", Path: "id", Value: "x"}, ErrSCIMInvalidPath},
+		{SCIMPatchOp{Op: "replace", Path: `emails[type eq`, Value: "x"}, ErrSCIMInvalidPath},
+		{SCIMPatchOp{Op: "move", Path: "active"}, ErrSCIMInvalidValue},
+	} {
+		if err := ApplySCIMPatch(res, []SCIMPatchOp{tc.op}); !errors.Is(err, tc.want) {
+			t.Errorf("%+v: expected %v, got %v", tc.op, tc.want, err)
+		}
+	}
+}
+
+func TestMountSCIM(t *testing.T) {
+	engine := New()
+	engine.MountSCIM("/scim/v2", SCIMOptions{Users: NewMemorySCIMStore(), MaxResults: 10})
+	scimHeaders := http.Header{"Content-Type": {scimContentType}}
+	decode := func(body string) map[string]any {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(body), &m); err != nil {
+			t.Fatalf("invalid response %q: %v", body, err)
+		}
+		return m
+	}
+
+	w := PerformRequest(engine, http.MethodPost, "http://idp.example.com/scim/v2/Users",
+		strings.NewReader(`{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"bjensen","active":true}`), scimHeaders)
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Type") != scimContentType {
+		t.Fatalf("unexpected create response %d %s", w.Code, w.Body.String())
+	}
+	created := decode(w.Body.String())
+	id := created["id"].(string)
+	if loc := w.Header().Get("Location"); loc != "http://idp.example.com/scim/v2/Users/"+id {
+		t.Fatalf("unexpected Location %q", loc)
+	}
+
+	w = PerformRequest(engine, http.MethodPost, "/scim/v2/Users", strings.NewReader(`{"userName":"BJENSEN"}`), scimHeaders)
+	if body := decode(w.Body.String()); w.Code != http.StatusConflict || body["scimType"] != "uniqueness" {
+		t.Fatalf("expected a uniqueness error, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodPatch, "/scim/v2/Users/"+id, strings.NewReader(
+		`{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"active","value":false}]}`), scimHeaders)
+	if body := decode(w.Body.String()); w.Code != http.StatusOK || body["active"] != false || body["userName"] != "bjensen" {
+		t.Fatalf("unexpected patch response %d %s", w.Code, w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`userName eq "bjensen" and active eq false`), nil, nil)
+	list := decode(w.Body.String())
+	if w.Code != http.StatusOK || list["totalResults"] != float64(1) || len(list["Resources"].([]any)) != 1 {
+		t.Fatalf("unexpected list response %d %s", w.Code, w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`userName zz "x"`), nil, nil)
+	if body := decode(w.Body.String()); w.Code != http.StatusBadRequest || body["scimType"] != "invalidFilter" {
+		t.Fatalf("expected invalidFilter, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w := PerformRequest(engine, http.MethodDelete, "/scim/v2/Users/"+id, nil, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected delete status %d", w.Code)
+	}
+	w = PerformRequest(engine, http.MethodGet, "/scim/v2/Users/"+id, nil, nil)
+	if body := decode(w.Body.String()); w.Code != http.StatusNotFound || body["status"] != "404" {
+		t.Fatalf("expected a SCIM 404, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,403 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+// SCIMFilter 是解析后的 SCIM 过滤表达式 (RFC 7644 3.4.2.2). Op 取值:
+//
+//	"eq" "ne" "co" "sw" "ew" "gt" "ge" "lt" "le"  比较 Attr 与 Value
+//	"pr"                                         Attr 存在且非空
+//	"and" "or"                                   Left 与 Right 的逻辑组合
+//	"not"                                        对 Left 取反
+//	"[]"                                         值路径, 例如 emails[type eq "work"]: Attr 为 emails, Left 为括号内的过滤器
+//
+// Value 为 string、float64、bool 或 nil. 存储可以将其翻译为数据库查询, 也可以直接用 Match 在内存中过滤
+type SCIMFilter struct {
+	Op    string
+	Attr  string
+	Value any
+	Left  *SCIMFilter
+	Right *SCIMFilter
+}
+
+// ParseSCIMFilter 解析 SCIM 过滤表达式, 语法错误时返回包装了 ErrSCIMInvalidFilter 的错误
+func ParseSCIMFilter(s string) (*SCIMFilter, error) {
+	p := &scimFilterParser{s: s}
+	f, err := p.parseOr()
+	if err == nil && p.peek() != "" {
+		err = fmt.Errorf("unexpected %q", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSCIMInvalidFilter, err)
+	}
+	return f, nil
+}
+
+// Match 报告资源是否满足过滤器. 属性名不区分大小写, 字符串比较不区分大小写 (SCIM 属性默认 caseExact 为 false)
+func (f *SCIMFilter) Match(res map[string]any) bool {
+	switch f.Op {
+	case "and":
+		return f.Left.Match(res) && f.Right.Match(res)
+	case "or":
+		return f.Left.Match(res) || f.Right.Match(res)
+	case "not":
+		return !f.Left.Match(res)
+	case "[]":
+		container, path := scimContainer(res, f.Attr, false)
+		if container == nil {
+			return false
+		}
+		v, _ := scimGet(container, path)
+		list, ok := v.([]any)
+		if !ok {
+			list = []any{v}
+		}
+		for _, item := range list {
+			if m, ok := item.(map[string]any); ok && f.Left.Match(m) {
+				return true
+			}
+		}
+		return false
+	case "pr":
+		for _, v := range scimValues(res, f.Attr) {
+			if s, ok := v.(string); v != nil && (!ok || s != "") {
+				return true
+			}
+		}
+		return false
+	case "ne":
+		return !(&SCIMFilter{Op: "eq", Attr: f.Attr, Value: f.Value}).Match(res)
+	}
+
+	values := scimValues(res, f.Attr)
+	if f.Value == nil && f.Op == "eq" {
+		return len(values) == 0
+	}
+	for _, v := range values {
+		if scimCompare(f.Op, v, f.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// scimCompare 比较属性值 a 与过滤器中的值 b
+func scimCompare(op string, a, b any) bool {
+	switch b := b.(type) {
+	case string:
+		as, ok := a.(string)
+		if !ok {
+			return false
+		}
+		as, bs := strings.ToLower(as), strings.ToLower(b)
+		switch op {
+		case "eq":
+			return as == bs
+		case "co":
+			return strings.Contains(as, bs)
+		case "sw":
+			return strings.HasPrefix(as, bs)
+		case "ew":
+			return strings.HasSuffix(as, bs)
+		case "gt":
+			return as > bs
+		case "ge":
+			return as >= bs
+		case "lt":
+			return as < bs
+		case "le":
+			return as <= bs
+		}
+	case float64:
+		an, ok := a.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return an == b
+		case "gt":
+			return an > b
+		case "ge":
+			return an >= b
+		case "lt":
+			return an < b
+		case "le":
+			return an <= b
+		}
+	case bool:
+		ab, ok := a.(bool)
+		return ok && op == "eq" && ab == b
+	}
+	return false
+}
+
+// scimValues 返回属性路径指向的全部值. 路径可以带有扩展 schema 的 URN 前缀;
+// 多值属性展开为各个元素, 多值复杂属性在未指定子属性时取其 value 子属性
+func scimValues(res map[string]any, attr string) []any {
+	container, path := scimContainer(res, attr, false)
+	if container == nil {
+		return nil
+	}
+	name, sub, _ := strings.Cut(path, ".")
+	v, ok := scimGet(container, name)
+	if !ok {
+		return nil
+	}
+	var values []any
+	if list, ok := v.([]any); ok {
+		values = list
+	} else {
+		values = []any{v}
+	}
+	key := sub
+	if key == "" {
+		key = "value"
+	}
+	out := make([]any, 0, len(values))
+	for _, item := range values {
+		if m, ok := item.(map[string]any); ok {
+			if sv, ok := scimGet(m, key); ok {
+				out = append(out, sv)
+			}
+			continue
+		}
+		if sub == "" && item != nil {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// scimContainer 拆分属性路径中的 URN 前缀, 返回属性所在的对象与剩余路径.
+// 核心 schema 的属性位于资源顶层; create 为 true 时会创建缺失的扩展对象
+func scimContainer(res map[string]any, attr string, create bool) (map[string]any, string) {
+	if !strings.HasPrefix(strings.ToLower(attr), "urn:") {
+		return res, attr
+	}
+	i := strings.LastIndex(attr, ":")
+	schema, path := attr[:i], attr[i+1:]
+	if scimCoreSchema(schema) {
+		return res, path
+	}
+	if v, ok := scimGet(res, schema); ok {
+		m, _ := v.(map[string]any)
+		return m, path
+	}
+	if !create {
+		return nil, path
+	}
+	m := make(map[string]any)
+	res[schema] = m
+	return m, path
+}
+
+func scimCoreSchema(schema string) bool {
+	return strings.EqualFold(schema, SCIMSchemaUser) || strings.EqualFold(schema, SCIMSchemaGroup)
+}
+
+// scimKey 返回 m 中与 name 不区分大小写相等的键, 不存在时返回 name 本身
+func scimKey(m map[string]any, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+func scimGet(m map[string]any, name string) (any, bool) {
+	v, ok := m[scimKey(m, name)]
+	return v, ok
+}
+
+// scimFilterParser 是递归下降的过滤表达式解析器, and 的优先级高于 or
+type scimFilterParser struct {
+	s   string
+	pos int
+}
+
+func (p *scimFilterParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// peek 返回下一个词法单元而不消费它
+func (p *scimFilterParser) peek() string {
+	save := p.pos
+	tok, _ := p.next()
+	p.pos = save
+	return tok
+}
+
+// next 读取下一个词法单元: 括号、带引号的字符串或由非空白字符组成的单词
+func (p *scimFilterParser) next() (string, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return "", nil
+	}
+	start := p.pos
+	switch c := p.s[p.pos]; {
+	case c == '(' || c == ')' || c == '[' || c == ']':
+		p.pos++
+	case c == '"':
+		p.pos++
+		for p.pos < len(p.s) && p.s[p.pos] != '"' {
+			if p.s[p.pos] == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		if p.pos >= len(p.s) {
+			return "", fmt.Errorf("unterminated string at %d", start)
+		}
+		p.pos++
+	default:
+		for p.pos < len(p.s) && !strings.ContainsRune(" ()[]\"", rune(p.s[p.pos])) {
+			p.pos++
+		}
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *scimFilterParser) expect(tok string) error {
+	got, err := p.next()
+	if err != nil {
+		return err
+	}
+	if got != tok {
+		return fmt.Errorf("expected %q, got %q", tok, got)
+	}
+	return nil
+}
+
+func (p *scimFilterParser) parseOr() (*SCIMFilter, error) {
+	left, err := p.parseAnd()
+	for err == nil && strings.EqualFold(p.peek(), "or") {
+		p.next()
+		var right *SCIMFilter
+		if right, err = p.parseAnd(); err == nil {
+			left = &SCIMFilter{Op: "or", Left: left, Right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *scimFilterParser) parseAnd() (*SCIMFilter, error) {
+	left, err := p.parseUnary()
+	for err == nil && strings.EqualFold(p.peek(), "and") {
+		p.next()
+		var right *SCIMFilter
+		if right, err = p.parseUnary(); err == nil {
+			left = &SCIMFilter{Op: "and", Left: left, Right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *scimFilterParser) parseUnary() (*SCIMFilter, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end of filter")
+	case strings.EqualFold(tok, "not"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseGroup(")")
+		if err != nil {
+			return nil, err
+		}
+		return &SCIMFilter{Op: "not", Left: f}, nil
+	case tok == "(":
+		return p.parseGroup(")")
+	case strings.ContainsAny(tok, "()[]\""):
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+
+	attr := tok
+	if p.peek() == "[" {
+		p.next()
+		f, err := p.parseGroup("]")
+		if err != nil {
+			return nil, err
+		}
+		return &SCIMFilter{Op: "[]", Attr: attr, Left: f}, nil
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op = strings.ToLower(op)
+	switch op {
+	case "pr":
+		return &SCIMFilter{Op: "pr", Attr: attr}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+	raw, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := scimParseValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &SCIMFilter{Op: op, Attr: attr, Value: value}, nil
+}
+
+// parseGroup 解析括号内的表达式并消费右括号
+func (p *scimFilterParser) parseGroup(closing string) (*SCIMFilter, error) {
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(closing); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// scimParseValue 解析比较值: JSON 字符串、数字、true、false 或 null
+func scimParseValue(raw string) (any, error) {
+	switch strings.ToLower(raw) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "":
+		return nil, fmt.Errorf("missing comparison value")
+	}
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal([]byte(raw), &s); err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return s, nil
+	}
+	n, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q", raw)
+	}
+	return n, nil
+}
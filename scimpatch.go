@@ -0,0 +1,217 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// SCIMPatchOp 是 PATCH 请求中的一个操作 (RFC 7644 3.5.2)
+type SCIMPatchOp struct {
+	// Op 为 add、replace 或 remove, 不区分大小写
+	Op string `json:"op"`
+	// Path 为目标属性, 例如 active、name.givenName、emails[type eq "work"].value; add 与 replace 可以省略
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// scimPath 是解析后的 PATCH 路径: attr[filter].sub
+type scimPath struct {
+	attr   string
+	filter *SCIMFilter
+	sub    string
+}
+
+// ApplySCIMPatch 依次将 ops 应用到资源上, 任一操作失败时返回包装了
+// ErrSCIMInvalidPath、ErrSCIMNoTarget 或 ErrSCIMInvalidValue 的错误, 此时资源可能已被部分修改
+func ApplySCIMPatch(res map[string]any, ops []SCIMPatchOp) error {
+	for i, op := range ops {
+		if err := applySCIMPatchOp(res, strings.ToLower(op.Op), op.Path, op.Value); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func applySCIMPatchOp(res map[string]any, op, path string, value any) error {
+	switch op {
+	case "add", "replace", "remove":
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrSCIMInvalidValue, op)
+	}
+	if path == "" {
+		if op == "remove" {
+			return fmt.Errorf("%w: remove requires a path", ErrSCIMNoTarget)
+		}
+		// 没有 path 时 value 的每个成员都是一个目标, 成员名本身也可以是路径 (例如 name.givenName)
+		m, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: value must be an object when path is omitted", ErrSCIMInvalidValue)
+		}
+		for k, v := range m {
+			if ext, ok := v.(map[string]any); ok && strings.HasPrefix(strings.ToLower(k), "urn:") && !scimCoreSchema(k) {
+				for sk, sv := range ext {
+					if err := applySCIMPatchOp(res, op, k+":"+sk, sv); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if strings.EqualFold(k, "schemas") {
+				continue
+			}
+			if err := applySCIMPatchOp(res, op, k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	container, rest := scimContainer(res, path, op != "remove")
+	if container == nil {
+		return nil
+	}
+	p, err := parseSCIMPath(rest)
+	if err != nil {
+		return err
+	}
+	if p.filter != nil {
+		return applySCIMFiltered(container, op, p, value)
+	}
+
+	key := scimKey(container, p.attr)
+	if p.sub != "" {
+		parent, ok := container[key].(map[string]any)
+		if !ok {
+			if _, exists := container[key]; exists {
+				return fmt.Errorf("%w: %s is not a complex attribute", ErrSCIMInvalidPath, p.attr)
+			}
+			if op == "remove" {
+				return nil
+			}
+			parent = make(map[string]any)
+			container[key] = parent
+		}
+		if op == "remove" {
+			delete(parent, scimKey(parent, p.sub))
+		} else {
+			scimSet(parent, p.sub, value, op == "add")
+		}
+		return nil
+	}
+	if op == "remove" {
+		delete(container, key)
+		return nil
+	}
+	scimSet(container, p.attr, value, op == "add")
+	return nil
+}
+
+// scimSet 写入属性: 复杂属性合并子属性, add 对多值属性追加元素, 其余情况直接替换
+func scimSet(m map[string]any, name string, value any, add bool) {
+	key := scimKey(m, name)
+	switch old := m[key].(type) {
+	case []any:
+		if add {
+			if list, ok := value.([]any); ok {
+				m[key] = append(old, list...)
+			} else {
+				m[key] = append(old, value)
+			}
+			return
+		}
+	case map[string]any:
+		if nv, ok := value.(map[string]any); ok {
+			for k, v := range nv {
+				old[scimKey(old, k)] = v
+			}
+			return
+		}
+	}
+	m[key] = value
+}
+
+// applySCIMFiltered 处理带值过滤器的路径, 例如 emails[type eq "work"].value
+func applySCIMFiltered(container map[string]any, op string, p scimPath, value any) error {
+	key := scimKey(container, p.attr)
+	list, _ := container[key].([]any)
+	matched := 0
+	kept := list[:0:0]
+	for _, item := range list {
+		elem, ok := item.(map[string]any)
+		if !ok || !p.filter.Match(elem) {
+			kept = append(kept, item)
+			continue
+		}
+		matched++
+		switch {
+		case op == "remove" && p.sub == "":
+			continue
+		case op == "remove":
+			delete(elem, scimKey(elem, p.sub))
+		case p.sub != "":
+			elem[scimKey(elem, p.sub)] = value
+		default:
+			if nv, ok := value.(map[string]any); ok {
+				maps.Copy(elem, nv)
+			} else {
+				return fmt.Errorf("%w: value for %s must be an object", ErrSCIMInvalidValue, p.attr)
+			}
+		}
+		kept = append(kept, elem)
+	}
+
+	if matched == 0 {
+		// 目录服务 (例如 Entra ID) 常对尚不存在的元素发送 addresses[type eq "work"].streetAddress,
+		// 过滤器为简单的 eq 时按过滤条件创建该元素
+		if op != "remove" && p.sub != "" && p.filter.Op == "eq" && !strings.Contains(p.filter.Attr, ".") {
+			container[key] = append(list, map[string]any{p.filter.Attr: p.filter.Value, p.sub: value})
+			return nil
+		}
+		if op == "remove" {
+			return nil
+		}
+		return fmt.Errorf("%w: no values of %s match the filter", ErrSCIMNoTarget, p.attr)
+	}
+	if len(kept) == 0 {
+		delete(container, key)
+	} else {
+		container[key] = kept
+	}
+	return nil
+}
+
+// parseSCIMPath 解析不带 URN 前缀的路径 attr、attr.sub、attr[filter] 或 attr[filter].sub
+func parseSCIMPath(path string) (scimPath, error) {
+	var p scimPath
+	attr, rest, hasFilter := strings.Cut(path, "[")
+	if !hasFilter {
+		p.attr, p.sub, _ = strings.Cut(path, ".")
+	} else {
+		end := strings.LastIndex(rest, "]")
+		if end < 0 {
+			return p, fmt.Errorf("%w: unterminated filter in %q", ErrSCIMInvalidPath, path)
+		}
+		f, err := ParseSCIMFilter(rest[:end])
+		if err != nil {
+			return p, fmt.Errorf("%w: %w", ErrSCIMInvalidPath, err)
+		}
+		p.attr, p.filter = attr, f
+		if tail := rest[end+1:]; tail != "" {
+			sub, ok := strings.CutPrefix(tail, ".")
+			if !ok {
+				return p, fmt.Errorf("%w: unexpected %q in %q", ErrSCIMInvalidPath, tail, path)
+			}
+			p.sub = sub
+		}
+	}
+	if p.attr == "" || strings.Contains(p.sub, ".") || slices.Contains([]string{"id", "meta"}, strings.ToLower(p.attr)) {
+		return p, fmt.Errorf("%w: %q", ErrSCIMInvalidPath, path)
+	}
+	return p, nil
+}
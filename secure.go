@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSPNoncePlaceholder 是 ContentSecurityPolicy 中 nonce 的占位符, 每个请求替换为 'nonce-<随机值>'
+const CSPNoncePlaceholder = "{nonce}"
+
+// cspNonceKey 是当前请求 CSP nonce 在 Context.Keys 中的键
+const cspNonceKey = "touka.cspnonce"
+
+// SecureHeadersOptions 配置 SecureHeaders, 字段为空时不设置对应的响应头
+type SecureHeadersOptions struct {
+	// ContentSecurityPolicy 为 Content-Security-Policy 的值, 其中的 {nonce} 替换为当前请求的 nonce,
+	// 例如 "default-src 'self'; script-src {nonce} 'strict-dynamic'"
+	ContentSecurityPolicy string
+	// ReportOnly 为 true 时改为设置 Content-Security-Policy-Report-Only, 只报告不拦截
+	ReportOnly bool
+	// FrameOptions 为 X-Frame-Options 的值, 例如 DENY 或 SAMEORIGIN
+	FrameOptions string
+	// ContentTypeNosniff 为 true 时设置 X-Content-Type-Options: nosniff
+	ContentTypeNosniff bool
+	// ReferrerPolicy 为 Referrer-Policy 的值, 例如 strict-origin-when-cross-origin
+	ReferrerPolicy string
+	// HSTSMaxAge 大于 0 时, 对 HTTPS 请求设置 Strict-Transport-Security
+	HSTSMaxAge time.Duration
+	// HSTSIncludeSubdomains 为 HSTS 附加 includeSubDomains
+	HSTSIncludeSubdomains bool
+}
+
+// SecureHeaders 返回设置安全相关响应头的中间件. 策略中包含 {nonce} 时为每个请求生成 nonce,
+// 处理器与模板可以通过 c.CSPNonce() 或模板函数 cspNonce 取得同一个值, 从而启用严格的 CSP:
+//
+//	r.Use(touka.SecureHeaders(touka.SecureHeadersOptions{
+//	    ContentSecurityPolicy: "default-src 'self'; script-src {nonce} 'strict-dynamic'; object-src 'none'",
+//	    FrameOptions:          "DENY",
+//	    ContentTypeNosniff:    true,
+//	}))
+//
+//	<script nonce="{{cspNonce .ctx}}">...</script>
+func SecureHeaders(opts SecureHeadersOptions) HandlerFunc {
+	cspHeader := "Content-Security-Policy"
+	if opts.ReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	useNonce := strings.Contains(opts.ContentSecurityPolicy, CSPNoncePlaceholder)
+	var hsts string
+	if opts.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(opts.HSTSMaxAge/time.Second), 10)
+		if opts.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *Context) {
+		h := c.Writer.Header()
+		if policy := opts.ContentSecurityPolicy; policy != "" {
+			if useNonce {
+				policy = strings.ReplaceAll(policy, CSPNoncePlaceholder, "'nonce-"+c.CSPNonce()+"'")
+			}
+			h.Set(cspHeader, policy)
+		}
+		if opts.FrameOptions != "" {
+			h.Set("X-Frame-Options", opts.FrameOptions)
+		}
+		if opts.ContentTypeNosniff {
+			h.Set("X-Content-Type-Options", "nosniff")
+		}
+		if opts.ReferrerPolicy != "" {
+			h.Set("Referrer-Policy", opts.ReferrerPolicy)
+		}
+		if hsts != "" && c.Request.TLS != nil {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// CSPNonce 返回当前请求的 CSP nonce, 首次调用时生成 (128 位随机值, base64 编码).
+// 与 SecureHeaders 一起使用时, 返回值与 Content-Security-Policy 头中的 nonce 相同
+func (c *Context) CSPNonce() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if nonce, ok := c.Keys[cspNonceKey].(string); ok {
+		return nonce
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	nonce := base64.StdEncoding.EncodeToString(b)
+	if c.Keys == nil {
+		c.Keys = make(map[string]any)
+	}
+	c.Keys[cspNonceKey] = nonce
+	return nonce
+}
+
+// CSPFuncMap 返回读取 CSP nonce 的模板函数, LoadHTMLGlob 会自动注册它:
+//
+//	<script nonce="{{cspNonce .ctx}}">...</script>
+func CSPFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"cspNonce": func(c *Context) string {
+			return c.CSPNonce()
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package touka
+
+import (
+	"crypto/tls"
+	"html"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSecureHeadersCSPNonce(t *testing.T) {
+	engine := New()
+	engine.HTMLRender = template.Must(template.New("page").Funcs(CSPFuncMap()).Parse(`<script nonce="{{cspNonce .ctx}}"></script>`))
+	engine.Use(SecureHeaders(SecureHeadersOptions{
+		ContentSecurityPolicy: "script-src {nonce} 'strict-dynamic'",
+		FrameOptions:          "DENY",
+		ContentTypeNosniff:    true,
+		ReferrerPolicy:        "no-referrer",
+		HSTSMaxAge:            365 * 24 * time.Hour,
+	}))
+	engine.GET("/", func(c *Context) {
+		c.HTMLBuf(http.StatusOK, "page", H{"ctx": c})
+	})
+
+	seen := map[string]bool{}
+	for range 2 {
+		w := PerformRequest(engine, http.MethodGet, "/", nil, nil)
+		csp := w.Header().Get("Content-Security-Policy")
+		nonce, ok := strings.CutPrefix(csp, "script-src 'nonce-")
+		if !ok {
+			t.Fatalf("unexpected CSP %q", csp)
+		}
+		nonce, _, _ = strings.Cut(nonce, "'")
+		if len(nonce) < 22 || seen[nonce] {
+			t.Fatalf("nonce %q is not a fresh random value", nonce)
+		}
+		seen[nonce] = true
+		// html/template 会把 base64 中的 + 转义为 &#43;, 浏览器读取属性时会还原
+		if want := `<script nonce="` + nonce + `"></script>`; html.UnescapeString(w.Body.String()) != want {
+			t.Fatalf("body = %q, want %q", w.Body.String(), want)
+		}
+		if w.Header().Get("X-Frame-Options") != "DENY" || w.Header().Get("X-Content-Type-Options") != "nosniff" || w.Header().Get("Referrer-Policy") != "no-referrer" {
+			t.Fatalf("missing secure headers: %v", w.Header())
+		}
+		if w.Header().Get("Strict-Transport-Security") != "" {
+			t.Fatal("HSTS must not be sent over plain HTTP")
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=31536000" {
+		t.Fatalf("unexpected HSTS %q", got)
+	}
+}
+
+func TestSecureHeadersReportOnlyWithoutNonce(t *testing.T) {
+	engine := New()
+	engine.Use(SecureHeaders(SecureHeadersOptions{ContentSecurityPolicy: "default-src 'self'", ReportOnly: true}))
+	engine.GET("/", func(c *Context) {
+		if _, ok := c.Get(cspNonceKey); ok {
+			t.Error("nonce should not be generated when the policy has no placeholder")
+		}
+		c.Status(http.StatusNoContent)
+	})
+	w := PerformRequest(engine, http.MethodGet, "/", nil, nil)
+	if w.Header().Get("Content-Security-Policy") != "" || w.Header().Get("Content-Security-Policy-Report-Only") != "default-src 'self'" {
+		t.Fatalf("unexpected headers: %v", w.Header())
+	}
+}
+
+func TestCSPNonceStablePerRequest(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	first := c.CSPNonce()
+	if first == "" || c.CSPNonce() != first {
+		t.Fatalf("nonce should be stable within a request")
+	}
+}
@@ -0,0 +1,24 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"io"
+	"os"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/iox"
+)
+
+// copyBody 将 r 写入响应体.
+// r 为 *os.File 且 c.Writer 实现 io.ReaderFrom 时直接调用 ReadFrom: *os.File 的 WriteTo 会把文件包装为普通 Reader,
+// net/http 因此无法识别出文件, 只能经由缓冲区复制; 直接交给 ReadFrom 可以在 HTTP/1.x 明文连接上使用 sendfile
+func (c *Context) copyBody(r io.Reader) (int64, error) {
+	if f, ok := r.(*os.File); ok {
+		if rf, ok := c.Writer.(io.ReaderFrom); ok {
+			return rf.ReadFrom(f)
+		}
+	}
+	return iox.Copy(c.Writer, r)
+}
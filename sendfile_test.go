@@ -0,0 +1,82 @@
+package touka
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFromRecorder 模拟 net/http 的 ResponseWriter: 记录 ReadFrom 收到的来源是否为文件
+type readFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFromFile bool
+}
+
+func (w *readFromRecorder) ReadFrom(r io.Reader) (int64, error) {
+	switch src := r.(type) {
+	case *os.File:
+		w.readFromFile = true
+	case *io.LimitedReader:
+		_, w.readFromFile = src.R.(*os.File)
+	}
+	return io.Copy(w.ResponseRecorder, r)
+}
+
+func TestFileResponsesReachReadFrom(t *testing.T) {
+	dir := t.TempDir()
+	body := []byte("sendfile body")
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), body, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	engine := New()
+	var size int
+	engine.Use(func(c *Context) {
+		c.Next()
+		size = c.Writer.Size()
+	})
+	engine.StaticDir("/static", dir)
+	engine.GET("/file", func(c *Context) {
+		c.File(filepath.Join(dir, "a.txt"))
+	})
+	engine.GET("/body", func(c *Context) {
+		c.SetRespBodyFile(http.StatusOK, filepath.Join(dir, "a.txt"))
+	})
+
+	for _, path := range []string{"/static/a.txt", "/file", "/body"} {
+		w := &readFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != string(body) {
+			t.Fatalf("%s: unexpected response %d %q", path, w.Code, w.Body.String())
+		}
+		if !w.readFromFile {
+			t.Fatalf("%s: expected the file to reach ReadFrom", path)
+		}
+		if size != len(body) {
+			t.Fatalf("%s: expected Size %d, got %d", path, len(body), size)
+		}
+	}
+}
+
+func TestFileResponseWrappedWriterFallsBack(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("wrapped"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	engine := New()
+	engine.Use(ErrorPages(ErrorPageOptions{Statuses: []int{http.StatusNotFound}}))
+	engine.GET("/file", func(c *Context) {
+		c.File(filepath.Join(dir, "a.txt"))
+	})
+
+	w := &readFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/file", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "wrapped" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if w.readFromFile {
+		t.Fatal("expected a wrapping middleware to disable the ReadFrom path")
+	}
+}
@@ -46,6 +46,7 @@ type runConfig struct {
 	mode                runMode
 	shutdownDefaultSet  bool
 	shutdownTimeoutSet  bool
+	autoTune            bool
 }
 
 type RunOption interface {
@@ -182,10 +183,46 @@ func WithShutdownContext(ctx context.Context) RunOption {
 	})
 }
 
+// WithAutoTune makes Run apply the GOMAXPROCS and GOMEMLIMIT recommendations
+// from CheckRuntime before serving. Values set explicitly through the
+// GOMAXPROCS or GOMEMLIMIT environment variables are left untouched.
+func WithAutoTune() RunOption {
+	return runOptionFunc(func(cfg *runConfig) error {
+		cfg.autoTune = true
+		return nil
+	})
+}
+
+// logRuntimeCheck logs container resource findings at startup and, with
+// WithAutoTune, applies the recommended runtime settings.
+func logRuntimeCheck(cfg runConfig) {
+	report := CheckRuntime()
+	if cfg.autoTune {
+		if applied := report.Apply(); len(applied) > 0 {
+			log.Printf("Touka runtime tuned: %s", strings.Join(applied, ", "))
+			report = CheckRuntime()
+		}
+	}
+	for _, warning := range report.Warnings {
+		log.Printf("Touka runtime warning: %s", warning)
+	}
+}
+
 func serveServer(srv *http.Server, serveTLS bool) error {
 	if serveTLS {
 		return srv.ListenAndServeTLS("", "")
 	}
+	if engine, ok := srv.Handler.(*Engine); ok && engine.smugglingGuard != nil && engine.smugglingGuard.framingChecks() {
+		addr := srv.Addr
+		if addr == "" {
+			addr = ":http"
+		}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return err
+		}
+		return srv.Serve(engine.smugglingGuard.Listener(ln))
+	}
 	return srv.ListenAndServe()
 }
 
@@ -267,6 +304,7 @@ func buildMainServer(engine *Engine, cfg runConfig) *http.Server {
 	}
 	applyServerProtocols(server, effectiveServerProtocols(engine, serveTLS))
 	applyMainServerConfig(engine, server, serveTLS)
+	engine.TrackConns(server)
 	return server
 }
 
@@ -324,6 +362,11 @@ func buildRedirectServer(engine *Engine, cfg runConfig) (*http.Server, error) {
 	}
 
 	redirectHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if engine.acmeChallenge && strings.HasPrefix(r.URL.Path, acmeChallengePrefix) {
+			// HTTP-01 验证必须在明文端口上完成
+			engine.ServeHTTP(w, r)
+			return
+		}
 		host, statusCode, ok := redirectTargetHost(r, cfg)
 		if !ok {
 			http.Error(w, http.StatusText(statusCode), statusCode)
@@ -478,25 +521,22 @@ func gracefulServe(servers []*http.Server, serveTLS []bool, timeout time.Duratio
 // signal-aware graceful shutdown and request-context cancellation semantics.
 // Add WithTLS(...) to run HTTPS; this is independent from graceful shutdown.
 func (engine *Engine) Run(opts ...RunOption) error {
-	cfg := defaultRunConfig()
-	for _, opt := range opts {
-		if opt == nil {
-			continue
-		}
-		if err := opt.apply(&cfg); err != nil {
-			return err
-		}
-	}
-	if cfg.httpRedirectAddr != "" {
-		cfg.mode = runModeHTTPSRedirect
-	} else if cfg.tlsConfig != nil {
-		cfg.mode = runModeHTTPS
+	cfg, err := resolveRunConfig(opts)
+	if err != nil {
+		return err
 	}
-	if err := validateRunConfig(cfg); err != nil {
+	if err := engine.validate(cfg); err != nil {
 		return err
 	}
 
 	serveTLS := cfg.mode != runModeHTTP
+	logRuntimeCheck(cfg)
+	for _, warning := range engine.ConfigWarnings() {
+		log.Printf("Touka config warning: %s", warning)
+	}
+
+	stopSignals := engine.listenSignals()
+	defer stopSignals()
 
 	mainServer := buildMainServer(engine, cfg)
 	servers := []*http.Server{mainServer}
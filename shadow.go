@@ -0,0 +1,196 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ShadowHeader 标记影子请求, 影子服务可据此避免产生副作用 (例如跳过扣款、发信)
+const ShadowHeader = "X-Shadow-Request"
+
+// ShadowResult 是一次影子请求的结果, 用于与主响应对比
+type ShadowResult struct {
+	Method        string
+	Path          string
+	PrimaryStatus int
+	ShadowStatus  int // 影子请求失败时为 0
+	ShadowBody    []byte
+	Latency       time.Duration
+	Err           error
+}
+
+// ShadowOptions 配置影子流量
+type ShadowOptions struct {
+	// Upstream 为影子服务的基础地址, 例如 http://canary.internal:8080, 必填
+	Upstream string
+	// Percentage 为随机复制的请求比例 (0~100)
+	Percentage float64
+	// Header 非空时, 携带该请求头的请求总是被复制, 便于手工触发
+	Header string
+	// Filter 返回 false 的请求不会被复制
+	Filter func(c *Context) bool
+	// MaxBodySize 为可复制的请求体上限, 默认 1MB, 更大的请求不会被复制
+	MaxBodySize int64
+	// MaxResponseSize 为 ShadowResult.ShadowBody 保留的上限, 默认 64KB
+	MaxResponseSize int64
+	// Timeout 为单个影子请求的超时, 默认 5 秒
+	Timeout time.Duration
+	// MaxInFlight 为同时进行的影子请求上限, 默认 100, 超出时丢弃
+	MaxInFlight int64
+	// OnResult 在影子请求完成后调用 (在独立的 goroutine 中)
+	OnResult func(ShadowResult)
+}
+
+// Shadow 返回将选中的请求复制到影子服务的中间件.
+// 影子请求在主请求处理完成后通过引擎的 HTTPClient 异步发送, 其结果与错误不会影响主响应.
+func Shadow(opts ShadowOptions) HandlerFunc {
+	upstream, err := url.Parse(opts.Upstream)
+	if err != nil || upstream.Scheme == "" || upstream.Host == "" {
+		panic("touka: invalid shadow upstream " + opts.Upstream)
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 1 << 20
+	}
+	if opts.MaxResponseSize <= 0 {
+		opts.MaxResponseSize = 64 << 10
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = 100
+	}
+	var inFlight atomic.Int64
+
+	return func(c *Context) {
+		if !shadowSelected(c, &opts) {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if hasRequestBody(c.Request) {
+			if c.Request.ContentLength > opts.MaxBodySize {
+				c.Next()
+				return
+			}
+			original := c.Request.Body
+			buf, err := io.ReadAll(io.LimitReader(original, opts.MaxBodySize+1))
+			if err != nil || int64(len(buf)) > opts.MaxBodySize {
+				// 放回已读取的部分, 处理器仍能读到完整的请求体
+				c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(buf), original), original}
+				c.Next()
+				return
+			}
+			body = buf
+			c.Request.Body = readCloser{bytes.NewReader(buf), original}
+		}
+
+		req := buildShadowRequest(c.Request, upstream, body)
+		c.Next()
+
+		if inFlight.Add(1) > opts.MaxInFlight {
+			inFlight.Add(-1)
+			return
+		}
+		client := c.Client()
+		primary := c.Writer.Status()
+		go func() {
+			defer inFlight.Add(-1)
+			result := sendShadowRequest(client.Do, req, opts.Timeout, opts.MaxResponseSize)
+			result.PrimaryStatus = primary
+			if opts.OnResult != nil {
+				opts.OnResult(result)
+			}
+		}()
+	}
+}
+
+func shadowSelected(c *Context, opts *ShadowOptions) bool {
+	if c.Request.Header.Get(ShadowHeader) != "" {
+		return false // 避免影子服务再次复制
+	}
+	if opts.Filter != nil && !opts.Filter(c) {
+		return false
+	}
+	if opts.Header != "" && c.Request.Header.Get(opts.Header) != "" {
+		return true
+	}
+	return opts.Percentage > 0 && rand.Float64()*100 < opts.Percentage
+}
+
+// buildShadowRequest 复制请求的方法、路径、头部与请求体, 不持有原请求的任何可变状态
+func buildShadowRequest(r *http.Request, upstream *url.URL, body []byte) *http.Request {
+	target := *upstream
+	target.Path = strings.TrimSuffix(upstream.Path, "/") + r.URL.Path
+	target.RawPath = ""
+	if r.URL.RawPath != "" {
+		target.RawPath = strings.TrimSuffix(upstream.EscapedPath(), "/") + r.URL.RawPath
+	}
+	target.RawQuery = r.URL.RawQuery
+
+	header := r.Header.Clone()
+	removeHopByHopHeaders(header)
+	header.Del("Content-Length")
+	header.Set(ShadowHeader, "1")
+	appendXForwardedFor(header, reverseProxyClientIP(r.RemoteAddr))
+	if header.Get("X-Forwarded-Host") == "" {
+		header.Set("X-Forwarded-Host", r.Host)
+	}
+
+	req := &http.Request{
+		Method:        r.Method,
+		URL:           &target,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Host:          target.Host,
+		ContentLength: int64(len(body)),
+	}
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	return req
+}
+
+func sendShadowRequest(do func(*http.Request) (*http.Response, error), req *http.Request, timeout time.Duration, maxResponse int64) ShadowResult {
+	result := ShadowResult{Method: req.Method, Path: req.URL.Path}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := do(req.WithContext(ctx))
+	if err != nil {
+		result.Latency = time.Since(start)
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+	result.ShadowStatus = resp.StatusCode
+	result.ShadowBody, result.Err = io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	// 丢弃剩余部分以便复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponse))
+	result.Latency = time.Since(start)
+	return result
+}
+
+// readCloser 组合 Reader 与原始请求体的 Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,117 @@
+package touka
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShadowMirrorsSelectedRequests(t *testing.T) {
+	type seen struct {
+		method, uri, body, marker string
+	}
+	received := make(chan seen, 1)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- seen{r.Method, r.URL.RequestURI(), string(body), r.Header.Get(ShadowHeader)}
+		w.WriteHeader(http.StatusTeapot)
+		io.WriteString(w, "shadow")
+	}))
+	defer shadow.Close()
+
+	results := make(chan ShadowResult, 1)
+	engine := New()
+	engine.Use(Shadow(ShadowOptions{
+		Upstream: shadow.URL + "/v2",
+		Header:   "X-Mirror",
+		OnResult: func(r ShadowResult) { results <- r },
+	}))
+	engine.POST("/orders", func(c *Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, "primary:%s", body)
+	})
+
+	w := PerformRequest(engine, http.MethodPost, "/orders?x=1", strings.NewReader("payload"), http.Header{"X-Mirror": {"1"}})
+	if w.Code != http.StatusCreated || w.Body.String() != "primary:payload" {
+		t.Fatalf("primary response affected: %d %q", w.Code, w.Body.String())
+	}
+
+	select {
+	case got := <-received:
+		if got != (seen{http.MethodPost, "/v2/orders?x=1", "payload", "1"}) {
+			t.Fatalf("unexpected shadow request %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("shadow request was not sent")
+	}
+	r := <-results
+	if r.PrimaryStatus != http.StatusCreated || r.ShadowStatus != http.StatusTeapot || string(r.ShadowBody) != "shadow" || r.Err != nil {
+		t.Fatalf("unexpected result %+v", r)
+	}
+}
+
+func TestShadowSkipsUnselectedAndOversizedRequests(t *testing.T) {
+	hits := make(chan struct{}, 4)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits <- struct{}{}
+	}))
+	defer shadow.Close()
+
+	engine := New()
+	engine.Use(Shadow(ShadowOptions{Upstream: shadow.URL, Header: "X-Mirror", MaxBodySize: 4}))
+	engine.POST("/", func(c *Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s", body)
+	})
+
+	// 未命中选择条件
+	PerformRequest(engine, http.MethodPost, "/", strings.NewReader("a"), nil)
+	// 请求体超过上限, 处理器仍应读到完整内容
+	req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader("0123456789")))
+	req.ContentLength = -1
+	req.Header.Set("X-Mirror", "1")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Body.String() != "0123456789" {
+		t.Fatalf("handler should receive the full body, got %q", w.Body.String())
+	}
+	// 已经是影子请求的不再复制
+	PerformRequest(engine, http.MethodPost, "/", strings.NewReader("b"), http.Header{"X-Mirror": {"1"}, ShadowHeader: {"1"}})
+
+	select {
+	case <-hits:
+		t.Fatal("no request should be mirrored")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestShadowFailureDoesNotAffectPrimary(t *testing.T) {
+	release := make(chan struct{})
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer shadow.Close()
+	defer close(release)
+
+	results := make(chan ShadowResult, 1)
+	engine := New()
+	engine.Use(Shadow(ShadowOptions{
+		Upstream:   shadow.URL,
+		Percentage: 100,
+		Timeout:    200 * time.Millisecond,
+		OnResult:   func(r ShadowResult) { results <- r },
+	}))
+	engine.GET("/", func(c *Context) { c.String(http.StatusOK, "ok") })
+
+	start := time.Now()
+	w := PerformRequest(engine, http.MethodGet, "/", nil, nil)
+	if w.Code != http.StatusOK || time.Since(start) > 150*time.Millisecond {
+		t.Fatalf("primary should not wait for shadow: %d after %v", w.Code, time.Since(start))
+	}
+	if r := <-results; r.Err == nil || r.ShadowStatus != 0 {
+		t.Fatalf("expected timeout error, got %+v", r)
+	}
+}
@@ -0,0 +1,81 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"syscall"
+)
+
+// SignalHandler 处理一个收到的系统信号
+type SignalHandler func(sig os.Signal)
+
+// OnSignal 为系统信号注册处理器, 例如将 SIGHUP 映射为配置/日志重载, SIGUSR2 映射为二进制热升级.
+// 处理器在 Run 期间生效, 同一信号的多个处理器按注册顺序执行.
+// SIGINT 与 SIGTERM 由框架的优雅停机负责, 不能在此注册
+func (engine *Engine) OnSignal(sig os.Signal, handler SignalHandler) {
+	if sig == nil || handler == nil {
+		panic("touka: signal and handler must not be nil")
+	}
+	if sig == os.Interrupt || sig == syscall.SIGINT || sig == syscall.SIGTERM {
+		panic("touka: SIGINT and SIGTERM are reserved for graceful shutdown")
+	}
+	engine.signalMu.Lock()
+	defer engine.signalMu.Unlock()
+	if engine.signalHandlers == nil {
+		engine.signalHandlers = make(map[os.Signal][]SignalHandler)
+	}
+	engine.signalHandlers[sig] = append(engine.signalHandlers[sig], handler)
+}
+
+// listenSignals 开始分发通过 OnSignal 注册的信号, 返回停止函数
+func (engine *Engine) listenSignals() (stop func()) {
+	engine.signalMu.Lock()
+	sigs := make([]os.Signal, 0, len(engine.signalHandlers))
+	for sig := range engine.signalHandlers {
+		sigs = append(sigs, sig)
+	}
+	engine.signalMu.Unlock()
+	if len(sigs) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				engine.dispatchSignal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+func (engine *Engine) dispatchSignal(sig os.Signal) {
+	engine.signalMu.Lock()
+	handlers := append([]SignalHandler(nil), engine.signalHandlers[sig]...)
+	engine.signalMu.Unlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					engine.logger.Errorf("panic in signal handler for %v: %v\n%s", sig, r, debug.Stack())
+				}
+			}()
+			handler(sig)
+		}()
+	}
+}
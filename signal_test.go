@@ -0,0 +1,51 @@
+package touka
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestOnSignalDispatchesRegisteredHandlers(t *testing.T) {
+	engine := New()
+	got := make(chan os.Signal, 2)
+	engine.OnSignal(syscall.SIGHUP, func(sig os.Signal) {
+		panic("handler panic must not stop later handlers")
+	})
+	engine.OnSignal(syscall.SIGHUP, func(sig os.Signal) {
+		got <- sig
+	})
+
+	stop := engine.listenSignals()
+	defer stop()
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("failed to find current process: %v", err)
+	}
+	if err := proc.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("failed to send SIGHUP: %v", err)
+	}
+	select {
+	case sig := <-got:
+		if sig != syscall.SIGHUP {
+			t.Fatalf("expected SIGHUP, got %v", sig)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("signal handler was not called")
+	}
+}
+
+func TestOnSignalRejectsShutdownSignals(t *testing.T) {
+	for _, sig := range []os.Signal{os.Interrupt, syscall.SIGTERM} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected panic when registering %v", sig)
+				}
+			}()
+			New().OnSignal(sig, func(os.Signal) {})
+		}()
+	}
+}
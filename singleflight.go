@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SingleflightOptions 配置 Singleflight 中间件
+type SingleflightOptions struct {
+	// KeyFunc 计算合并键, 默认为方法与请求 URI
+	KeyFunc func(c *Context) string
+	// Methods 为需要合并的方法, 默认 GET 与 HEAD
+	Methods []string
+	// MaxBodySize 为可共享的响应体上限, 超出时等待者各自执行, 默认 1MB
+	MaxBodySize int
+	// Distributed 为 true 时, 领头请求还会通过引擎的 Locker 在集群内串行化相同键的请求,
+	// 配合下游缓存可以避免缓存击穿
+	Distributed bool
+	// LockTTL 为分布式锁的租约时长, 默认 30 秒
+	LockTTL time.Duration
+}
+
+type flightCall struct {
+	done   chan struct{}
+	record *IdempotencyRecord // nil 表示响应无法共享
+}
+
+// Singleflight 返回一个合并并发相同请求的中间件: 同一时刻只有一个请求执行处理链,
+// 其余等待者直接复用它的响应 (状态码、响应头与响应体). 5xx、超过 MaxBodySize 或被劫持的响应不会被共享,
+// 等待者会各自执行处理链
+func Singleflight(opts SingleflightOptions) HandlerFunc {
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = func(c *Context) string { return c.Request.Method + " " + c.Request.URL.RequestURI() }
+	}
+	if len(opts.Methods) == 0 {
+		opts.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 1 << 20
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = 30 * time.Second
+	}
+
+	var mu sync.Mutex
+	calls := make(map[string]*flightCall)
+
+	return func(c *Context) {
+		if !slices.Contains(opts.Methods, c.Request.Method) {
+			c.Next()
+			return
+		}
+		key := opts.KeyFunc(c)
+
+		mu.Lock()
+		if call, ok := calls[key]; ok {
+			mu.Unlock()
+			select {
+			case <-call.done:
+			case <-c.Context().Done():
+				c.AbortWithStatus(http.StatusServiceUnavailable)
+				return
+			}
+			if call.record != nil {
+				writeRecordedResponse(c, call.record)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		call := &flightCall{done: make(chan struct{})}
+		calls[key] = call
+		mu.Unlock()
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer, limit: c.bufferLimit(opts.MaxBodySize)}
+		c.Writer = recorder
+		defer func() {
+			c.Writer = recorder.ResponseWriter
+			mu.Lock()
+			delete(calls, key)
+			mu.Unlock()
+			close(call.done)
+		}()
+
+		if opts.Distributed {
+			unlock, err := c.Lock("singleflight:"+key, opts.LockTTL)
+			if err != nil {
+				c.ErrorUseHandle(http.StatusServiceUnavailable, fmt.Errorf("singleflight lock: %w", err))
+				return
+			}
+			defer unlock()
+		}
+
+		c.Next()
+
+		status := recorder.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status < 500 && !recorder.overflow && !recorder.IsHijacked() {
+			call.record = &IdempotencyRecord{
+				Status: status,
+				Header: recorder.Header().Clone(),
+				Body:   recorder.body.Bytes(),
+			}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"encoding/xml"
+	"hash/fnv"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RobotsGroup 是 robots.txt 中针对一组 User-agent 的规则
+type RobotsGroup struct {
+	UserAgents []string // 为空时为 "*"
+	Allow      []string
+	Disallow   []string
+	CrawlDelay int // 秒, 0 表示不输出
+}
+
+// RobotsPolicy 描述 robots.txt 的内容
+type RobotsPolicy struct {
+	// Raw 非空时直接作为 robots.txt 的内容, 忽略其他字段
+	Raw    string
+	Groups []RobotsGroup
+	// Sitemaps 为 sitemap 地址, 站内路径会通过 c.AbsoluteURL 转换为绝对地址
+	Sitemaps []string
+}
+
+// render 生成 robots.txt 的内容
+func (p RobotsPolicy) render(c *Context) string {
+	if p.Raw != "" {
+		return p.Raw
+	}
+	var b strings.Builder
+	for i, g := range p.Groups {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		agents := g.UserAgents
+		if len(agents) == 0 {
+			agents = []string{"*"}
+		}
+		for _, ua := range agents {
+			b.WriteString("User-agent: " + ua + "\n")
+		}
+		for _, path := range g.Allow {
+			b.WriteString("Allow: " + path + "\n")
+		}
+		for _, path := range g.Disallow {
+			b.WriteString("Disallow: " + path + "\n")
+		}
+		if g.CrawlDelay > 0 {
+			b.WriteString("Crawl-delay: " + strconv.Itoa(g.CrawlDelay) + "\n")
+		}
+	}
+	if len(p.Sitemaps) > 0 && len(p.Groups) > 0 {
+		b.WriteByte('\n')
+	}
+	for _, s := range p.Sitemaps {
+		b.WriteString("Sitemap: " + c.AbsoluteURL(s) + "\n")
+	}
+	return b.String()
+}
+
+// Robots 注册 GET /robots.txt:
+//
+//	r.Robots(touka.RobotsPolicy{
+//	    Groups:   []touka.RobotsGroup{{Disallow: []string{"/admin/"}}},
+//	    Sitemaps: []string{"/sitemap.xml"},
+//	})
+//
+// 固定内容可以直接使用 RobotsPolicy{Raw: "..."}
+func (engine *Engine) Robots(policy RobotsPolicy) {
+	engine.GET("/robots.txt", func(c *Context) {
+		c.SetHeader("Content-Type", "text/plain; charset=utf-8")
+		c.SetHeader("Cache-Control", "public, max-age=3600")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.Write([]byte(policy.render(c)))
+	})
+}
+
+// Favicon 注册 GET /favicon.ico, 内容在注册时从 path 读取, 读取失败时 panic
+func (engine *Engine) Favicon(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic("touka: read favicon: " + err.Error())
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	engine.FaviconBytes(data, contentType)
+}
+
+// FaviconBytes 以 data 注册 GET /favicon.ico, contentType 为空时根据内容推断.
+// 响应支持 ETag 条件请求, 并允许客户端缓存一天
+func (engine *Engine) FaviconBytes(data []byte, contentType string) {
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	modTime := time.Now()
+	h := fnv.New64a()
+	h.Write(data)
+	etag := `"` + strconv.FormatUint(h.Sum64(), 16) + `"`
+	engine.GET("/favicon.ico", func(c *Context) {
+		c.SetHeader("Content-Type", contentType)
+		c.SetHeader("Cache-Control", "public, max-age=86400")
+		c.SetHeader("ETag", etag)
+		http.ServeContent(c.Writer, c.Request, "favicon.ico", modTime, bytes.NewReader(data))
+	})
+}
+
+// MetaSitemap 是将路由加入 sitemap 的元数据键
+const MetaSitemap = "touka.sitemap"
+
+// SitemapURL 是 sitemap 中的一个地址
+type SitemapURL struct {
+	// Loc 为页面地址, 站内路径会通过 c.AbsoluteURL 转换为绝对地址
+	Loc        string
+	LastMod    time.Time
+	ChangeFreq string  // always、hourly、daily、weekly、monthly、yearly、never
+	Priority   float64 // 0.0 ~ 1.0, 0 表示不输出
+}
+
+// InSitemap 返回将路由加入 sitemap 的元数据, 用于 WithMeta. 只有不含路径参数的 GET 路由会被收录,
+// 带参数的页面应通过 SitemapOptions.Entries 动态提供:
+//
+//	r.WithMeta(touka.InSitemap("weekly", 0.8)).GET("/about", about)
+func InSitemap(changeFreq string, priority float64) (string, any) {
+	return MetaSitemap, SitemapURL{ChangeFreq: changeFreq, Priority: priority}
+}
+
+// SitemapOptions 配置 sitemap.xml
+type SitemapOptions struct {
+	// Path 为 sitemap 的路径, 默认 /sitemap.xml
+	Path string
+	// Entries 在每次请求时提供动态地址, 例如文章列表
+	Entries func(c *Context) ([]SitemapURL, error)
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name         `xml:"urlset"`
+	XMLNS   string           `xml:"xmlns,attr"`
+	URLs    []sitemapURLElem `xml:"url"`
+}
+
+type sitemapURLElem struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+// Sitemap 注册 sitemap.xml, 内容由通过 InSitemap 标记的路由与 Entries 提供的动态地址组成
+func (engine *Engine) Sitemap(opts SitemapOptions) {
+	if opts.Path == "" {
+		opts.Path = "/sitemap.xml"
+	}
+	engine.GET(opts.Path, func(c *Context) {
+		urls := engine.sitemapRoutes()
+		if opts.Entries != nil {
+			dynamic, err := opts.Entries(c)
+			if err != nil {
+				c.AddError(err)
+				c.ErrorUseHandle(http.StatusInternalServerError, err)
+				return
+			}
+			urls = append(urls, dynamic...)
+		}
+
+		set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9", URLs: make([]sitemapURLElem, len(urls))}
+		for i, u := range urls {
+			elem := sitemapURLElem{Loc: c.AbsoluteURL(u.Loc), ChangeFreq: u.ChangeFreq}
+			if !u.LastMod.IsZero() {
+				elem.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+			}
+			if u.Priority > 0 {
+				elem.Priority = strconv.FormatFloat(u.Priority, 'f', 1, 64)
+			}
+			set.URLs[i] = elem
+		}
+		out, err := xml.Marshal(set)
+		if err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+		c.SetHeader("Content-Type", "application/xml; charset=utf-8")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.Write([]byte(xml.Header))
+		_, _ = c.Writer.Write(out)
+	})
+}
+
+// sitemapRoutes 返回通过 InSitemap 标记的静态 GET 路由, 按路径排序
+func (engine *Engine) sitemapRoutes() []SitemapURL {
+	var urls []SitemapURL
+	for _, route := range engine.routesInfo {
+		if route.Method != http.MethodGet || strings.ContainsAny(route.Path, ":*") {
+			continue
+		}
+		entry, ok := route.Meta[MetaSitemap].(SitemapURL)
+		if !ok {
+			continue
+		}
+		entry.Loc = route.Path
+		urls = append(urls, entry)
+	}
+	sort.Slice(urls, func(i, j int) bool { return urls[i].Loc < urls[j].Loc })
+	return urls
+}
@@ -0,0 +1,70 @@
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRobots(t *testing.T) {
+	engine := New()
+	engine.Robots(RobotsPolicy{
+		Groups: []RobotsGroup{
+			{Disallow: []string{"/admin/"}},
+			{UserAgents: []string{"BadBot"}, Disallow: []string{"/"}, CrawlDelay: 10},
+		},
+		Sitemaps: []string{"/sitemap.xml"},
+	})
+	w := PerformRequest(engine, http.MethodGet, "http://example.com/robots.txt", nil, nil)
+	want := "User-agent: *\nDisallow: /admin/\n\nUser-agent: BadBot\nDisallow: /\nCrawl-delay: 10\n\nSitemap: http://example.com/sitemap.xml\n"
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Fatalf("unexpected robots.txt %d:\n%s", w.Code, w.Body.String())
+	}
+}
+
+func TestFaviconBytes(t *testing.T) {
+	engine := New()
+	engine.FaviconBytes([]byte("\x00\x00\x01\x00icon"), "image/x-icon")
+	w := PerformRequest(engine, http.MethodGet, "/favicon.ico", nil, nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/x-icon" || etag == "" {
+		t.Fatalf("unexpected favicon response %d %v", w.Code, w.Header())
+	}
+	w = PerformRequest(engine, http.MethodGet, "/favicon.ico", nil, http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching ETag, got %d", w.Code)
+	}
+}
+
+func TestSitemap(t *testing.T) {
+	engine := New()
+	handler := func(c *Context) { c.String(http.StatusOK, "page") }
+	engine.WithMeta(InSitemap("weekly", 0.8)).GET("/about", handler)
+	engine.WithMeta(InSitemap("daily", 1)).GET("/", handler)
+	engine.WithMeta(InSitemap("daily", 1)).GET("/posts/:id", handler) // 带参数的路由不收录
+	engine.GET("/private", handler)
+	engine.Sitemap(SitemapOptions{
+		Entries: func(c *Context) ([]SitemapURL, error) {
+			return []SitemapURL{{Loc: "/posts/1", LastMod: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}}, nil
+		},
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "http://example.com/sitemap.xml", nil, nil)
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/xml") {
+		t.Fatalf("unexpected sitemap response %d %v", w.Code, w.Header())
+	}
+	for _, want := range []string{
+		`<url><loc>http://example.com/</loc><changefreq>daily</changefreq><priority>1.0</priority></url>`,
+		`<url><loc>http://example.com/about</loc><changefreq>weekly</changefreq><priority>0.8</priority></url>`,
+		`<url><loc>http://example.com/posts/1</loc><lastmod>2026-01-02T00:00:00Z</lastmod></url>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("sitemap missing %s\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "/private") || strings.Contains(body, ":id") {
+		t.Fatalf("sitemap should not include unmarked or parameterized routes:\n%s", body)
+	}
+}
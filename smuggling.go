@@ -0,0 +1,391 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// SmugglingReason 表示请求被 SmugglingGuard 拒绝的原因
+type SmugglingReason string
+
+const (
+	SmugglingAmbiguousLength    SmugglingReason = "ambiguous_length"     // 同时携带 Transfer-Encoding 与 Content-Length, 或多个 Content-Length
+	SmugglingInvalidTransfer    SmugglingReason = "invalid_transfer"     // HTTP/1.0 请求携带 Transfer-Encoding
+	SmugglingObsFold            SmugglingReason = "obs_fold"             // 头部使用了 obs-fold 续行
+	SmugglingMethodNotAllowed   SmugglingReason = "method_not_allowed"   // 方法不在允许列表中
+	SmugglingProtocolNotAllowed SmugglingReason = "protocol_not_allowed" // 协议版本不在允许列表中
+	SmugglingUnexpectedBody     SmugglingReason = "unexpected_body"      // GET/HEAD/TRACE 请求携带了请求体
+)
+
+var smugglingReasons = []SmugglingReason{
+	SmugglingAmbiguousLength,
+	SmugglingInvalidTransfer,
+	SmugglingObsFold,
+	SmugglingMethodNotAllowed,
+	SmugglingProtocolNotAllowed,
+	SmugglingUnexpectedBody,
+}
+
+// ErrRequestSmuggling 表示请求因可能的请求走私被拒绝
+var ErrRequestSmuggling = errors.New("request rejected by smuggling guard")
+
+// SmugglingGuardOptions 配置 SmugglingGuard
+type SmugglingGuardOptions struct {
+	// RejectAmbiguousFraming 拒绝同时携带 Transfer-Encoding 与 Content-Length、携带多个 Content-Length,
+	// 以及在 HTTP/1.0 中使用 Transfer-Encoding 的请求.
+	// net/http 会在交给处理器之前规范化这些头部, 因此该检查在连接层进行, 仅对明文 HTTP/1.x 生效
+	RejectAmbiguousFraming bool
+	// RejectObsFold 拒绝使用 obs-fold (以空白开头的续行) 的头部, 同样在连接层检查
+	RejectObsFold bool
+	// AllowedMethods 为允许的请求方法, 为空时不限制
+	AllowedMethods []string
+	// AllowedProtocols 为允许的协议版本, 例如 "HTTP/1.1", "HTTP/2.0"; 为空时不限制
+	AllowedProtocols []string
+	// RejectUnexpectedBody 拒绝携带请求体的 GET/HEAD/TRACE 请求
+	RejectUnexpectedBody bool
+	// OnReject 在拒绝请求时调用, 可用于接入指标或审计日志
+	OnReject func(reason SmugglingReason, remoteAddr string)
+}
+
+// SmugglingGuard 在路由之前拒绝可能被用于请求走私的请求, 并按原因计数.
+// 通过 engine.SetSmugglingGuard 启用; Run 启动明文 HTTP 服务器时会自动包装监听器以进行连接层检查,
+// 自行创建 http.Server 时可以使用 Listener 方法
+type SmugglingGuard struct {
+	opts     SmugglingGuardOptions
+	counters [6]atomic.Uint64 // 与 smugglingReasons 一一对应
+}
+
+// NewSmugglingGuard 创建 SmugglingGuard
+func NewSmugglingGuard(opts SmugglingGuardOptions) *SmugglingGuard {
+	// 复制后再规范化, 不修改调用方的切片
+	opts.AllowedMethods = slices.Clone(opts.AllowedMethods)
+	for i, m := range opts.AllowedMethods {
+		opts.AllowedMethods[i] = strings.ToUpper(m)
+	}
+	return &SmugglingGuard{opts: opts}
+}
+
+// SetSmugglingGuard 为引擎设置请求走私防护, 传入 nil 关闭
+func (engine *Engine) SetSmugglingGuard(guard *SmugglingGuard) {
+	engine.smugglingGuard = guard
+}
+
+// Stats 返回各拒绝原因的累计次数
+func (g *SmugglingGuard) Stats() map[SmugglingReason]uint64 {
+	stats := make(map[SmugglingReason]uint64, len(smugglingReasons))
+	for i, reason := range smugglingReasons {
+		stats[reason] = g.counters[i].Load()
+	}
+	return stats
+}
+
+func (g *SmugglingGuard) record(reason SmugglingReason, remoteAddr string) {
+	if i := slices.Index(smugglingReasons, reason); i >= 0 {
+		g.counters[i].Add(1)
+	}
+	if g.opts.OnReject != nil {
+		g.opts.OnReject(reason, remoteAddr)
+	}
+}
+
+// checkRequest 在路由前检查请求, 拒绝时写入响应并返回 true
+func (g *SmugglingGuard) checkRequest(c *Context) bool {
+	req := c.Request
+	var reason SmugglingReason
+	var code int
+	switch {
+	case len(g.opts.AllowedMethods) > 0 && !slices.Contains(g.opts.AllowedMethods, req.Method):
+		reason, code = SmugglingMethodNotAllowed, http.StatusMethodNotAllowed
+		c.SetHeader("Allow", strings.Join(g.opts.AllowedMethods, ", "))
+	case len(g.opts.AllowedProtocols) > 0 && !slices.Contains(g.opts.AllowedProtocols, req.Proto):
+		reason, code = SmugglingProtocolNotAllowed, http.StatusHTTPVersionNotSupported
+	case g.opts.RejectUnexpectedBody && hasRequestBody(req) &&
+		(req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodTrace):
+		reason, code = SmugglingUnexpectedBody, http.StatusBadRequest
+	default:
+		return false
+	}
+	g.record(reason, req.RemoteAddr)
+	c.SetHeader("Connection", "close")
+	c.ErrorUseHandle(code, fmt.Errorf("%w: %s", ErrRequestSmuggling, reason))
+	return true
+}
+
+// framingChecks 表示是否需要连接层检查
+func (g *SmugglingGuard) framingChecks() bool {
+	return g.opts.RejectAmbiguousFraming || g.opts.RejectObsFold
+}
+
+// Listener 包装监听器, 在 net/http 解析之前检查明文 HTTP/1.x 请求的原始头部.
+// 不应在 TLS 监听器之外包装, 加密后的字节流无法检查
+func (g *SmugglingGuard) Listener(ln net.Listener) net.Listener {
+	if !g.framingChecks() {
+		return ln
+	}
+	return &smugglingListener{Listener: ln, guard: g}
+}
+
+type smugglingListener struct {
+	net.Listener
+	guard *SmugglingGuard
+}
+
+func (l *smugglingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &smugglingConn{Conn: conn, guard: l.guard}, nil
+}
+
+// smugglingConn 在读取时跟踪 HTTP/1.x 报文边界并检查每个请求的头部.
+// 发现问题时返回读错误, net/http 会以 400 响应并关闭连接
+type smugglingConn struct {
+	net.Conn
+	guard  *SmugglingGuard
+	parser framingParser
+	err    error
+}
+
+func (c *smugglingConn) Read(b []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		if reason, bad := c.parser.feed(b[:n], &c.guard.opts); bad {
+			c.guard.record(reason, c.RemoteAddr().String())
+			c.err = fmt.Errorf("%w: %s", ErrRequestSmuggling, reason)
+			return 0, c.err
+		}
+	}
+	return n, err
+}
+
+const (
+	framingMaxHeaderBytes = http.DefaultMaxHeaderBytes + 4096
+	framingMaxLineBytes   = 4096
+)
+
+type framingState uint8
+
+const (
+	framingHeaders framingState = iota
+	framingFixedBody
+	framingChunkSize
+	framingChunkData
+	framingChunkDataEnd
+	framingTrailers
+	framingPassthrough
+)
+
+// framingParser 是 HTTP/1.x 请求流的增量解析器, 只用于定位报文边界和检查头部.
+// 遇到无法确定边界的情况 (协议升级、CONNECT、HTTP/2 前导、格式错误) 时转为透传,
+// 交由 net/http 处理
+type framingParser struct {
+	state     framingState
+	buf       []byte
+	remaining int64
+}
+
+func (p *framingParser) feed(data []byte, opts *SmugglingGuardOptions) (SmugglingReason, bool) {
+	for len(data) > 0 {
+		switch p.state {
+		case framingPassthrough:
+			return "", false
+
+		case framingHeaders:
+			if len(p.buf) == 0 {
+				// 忽略请求之间多余的空行
+				data = bytes.TrimLeft(data, "\r\n")
+				if len(data) == 0 {
+					return "", false
+				}
+			}
+			start := len(p.buf)
+			p.buf = append(p.buf, data...)
+			end := headerBlockEnd(p.buf, max(start-3, 0))
+			if end < 0 {
+				if len(p.buf) > framingMaxHeaderBytes {
+					p.passthrough()
+				}
+				return "", false
+			}
+			data = data[end-start:]
+			if reason, bad := p.parseHeaders(p.buf[:end], opts); bad {
+				return reason, true
+			}
+			p.buf = p.buf[:0]
+
+		case framingFixedBody:
+			n := min(int64(len(data)), p.remaining)
+			data = data[n:]
+			p.remaining -= n
+			if p.remaining == 0 {
+				p.state = framingHeaders
+			}
+
+		case framingChunkSize, framingTrailers, framingChunkDataEnd:
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				p.buf = append(p.buf, data...)
+				if len(p.buf) > framingMaxLineBytes {
+					p.passthrough()
+				}
+				return "", false
+			}
+			line := append(p.buf, data[:i]...)
+			data = data[i+1:]
+			p.buf = p.buf[:0]
+			p.endLine(bytes.TrimRight(line, "\r"))
+
+		case framingChunkData:
+			n := min(int64(len(data)), p.remaining)
+			data = data[n:]
+			p.remaining -= n
+			if p.remaining == 0 {
+				p.state = framingChunkDataEnd
+			}
+		}
+	}
+	return "", false
+}
+
+func (p *framingParser) passthrough() {
+	p.state = framingPassthrough
+	p.buf = nil
+}
+
+// endLine 处理分块编码中的一行
+func (p *framingParser) endLine(line []byte) {
+	switch p.state {
+	case framingChunkSize:
+		if i := bytes.IndexByte(line, ';'); i >= 0 {
+			line = line[:i]
+		}
+		size, err := strconv.ParseInt(string(bytes.TrimSpace(line)), 16, 64)
+		switch {
+		case err != nil || size < 0:
+			p.passthrough()
+		case size == 0:
+			p.state = framingTrailers
+		default:
+			p.state, p.remaining = framingChunkData, size
+		}
+	case framingChunkDataEnd:
+		if len(line) != 0 {
+			p.passthrough()
+			return
+		}
+		p.state = framingChunkSize
+	case framingTrailers:
+		if len(line) == 0 {
+			p.state = framingHeaders
+		}
+	}
+}
+
+// headerBlockEnd 返回头部块结束 (空行之后) 的位置, 未结束时返回 -1
+func headerBlockEnd(buf []byte, from int) int {
+	for i := from; i < len(buf); i++ {
+		if buf[i] != '\n' {
+			continue
+		}
+		if i+1 < len(buf) && buf[i+1] == '\n' {
+			return i + 2
+		}
+		if i+2 < len(buf) && buf[i+1] == '\r' && buf[i+2] == '\n' {
+			return i + 3
+		}
+	}
+	return -1
+}
+
+// parseHeaders 检查一个完整的请求头部块, 并确定请求体的边界
+func (p *framingParser) parseHeaders(block []byte, opts *SmugglingGuardOptions) (SmugglingReason, bool) {
+	lines := strings.Split(strings.ReplaceAll(string(block), "\r\n", "\n"), "\n")
+	requestLine := strings.Fields(lines[0])
+	if len(requestLine) != 3 {
+		p.passthrough()
+		return "", false
+	}
+	method, proto := requestLine[0], requestLine[2]
+	if method == "PRI" || !strings.HasPrefix(proto, "HTTP/1.") {
+		p.passthrough()
+		return "", false
+	}
+
+	var contentLengths []string
+	var transferEncoding string
+	var upgrade bool
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if opts.RejectObsFold {
+				return SmugglingObsFold, true
+			}
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.EqualFold(name, "Content-Length"):
+			contentLengths = append(contentLengths, value)
+		case strings.EqualFold(name, "Transfer-Encoding"):
+			if transferEncoding != "" {
+				transferEncoding += ","
+			}
+			transferEncoding += value
+		case strings.EqualFold(name, "Upgrade"):
+			upgrade = true
+		}
+	}
+
+	if opts.RejectAmbiguousFraming {
+		if transferEncoding != "" && len(contentLengths) > 0 || len(contentLengths) > 1 {
+			return SmugglingAmbiguousLength, true
+		}
+		if transferEncoding != "" && proto == "HTTP/1.0" {
+			return SmugglingInvalidTransfer, true
+		}
+	}
+
+	switch {
+	case method == http.MethodConnect || upgrade:
+		// 之后的字节流可能不再是 HTTP/1.x 报文
+		p.passthrough()
+	case transferEncoding != "":
+		codings := strings.Split(transferEncoding, ",")
+		if !strings.EqualFold(strings.TrimSpace(codings[len(codings)-1]), "chunked") {
+			p.passthrough()
+			break
+		}
+		p.state = framingChunkSize
+	case len(contentLengths) > 0:
+		n, err := strconv.ParseInt(contentLengths[0], 10, 64)
+		if err != nil || n < 0 {
+			p.passthrough()
+			break
+		}
+		if n > 0 {
+			p.state, p.remaining = framingFixedBody, n
+		}
+	}
+	return "", false
+}
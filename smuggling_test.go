@@ -0,0 +1,190 @@
+package touka
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSmugglingGuardRequestChecks(t *testing.T) {
+	var rejected []SmugglingReason
+	methods := []string{"get", "post"}
+	guard := NewSmugglingGuard(SmugglingGuardOptions{
+		AllowedMethods:       methods,
+		RejectUnexpectedBody: true,
+		OnReject: func(reason SmugglingReason, remoteAddr string) {
+			rejected = append(rejected, reason)
+		},
+	})
+	if methods[0] != "get" || methods[1] != "post" {
+		t.Fatalf("expected the caller's AllowedMethods to be left untouched, got %v", methods)
+	}
+	engine := New()
+	engine.SetSmugglingGuard(guard)
+	engine.ANY("/", func(c *Context) { c.String(http.StatusOK, "ok") })
+
+	w := PerformRequest(engine, http.MethodPost, "/", strings.NewReader("x"), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected POST to pass, got %d", w.Code)
+	}
+
+	w = PerformRequest(engine, "PROPFIND", "/", nil, nil)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, POST" {
+		t.Fatalf("expected 405 with Allow, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	if w.Header().Get("Connection") != "close" {
+		t.Fatal("rejected requests should close the connection")
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/", strings.NewReader("body"), nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected GET with body to be rejected, got %d", w.Code)
+	}
+
+	if len(rejected) != 2 || rejected[0] != SmugglingMethodNotAllowed || rejected[1] != SmugglingUnexpectedBody {
+		t.Fatalf("unexpected OnReject calls: %v", rejected)
+	}
+	stats := guard.Stats()
+	if stats[SmugglingMethodNotAllowed] != 1 || stats[SmugglingUnexpectedBody] != 1 || stats[SmugglingObsFold] != 0 {
+		t.Fatalf("unexpected stats: %v", stats)
+	}
+}
+
+func TestSmugglingGuardProtocols(t *testing.T) {
+	engine := New()
+	engine.SetSmugglingGuard(NewSmugglingGuard(SmugglingGuardOptions{AllowedProtocols: []string{"HTTP/1.1"}}))
+	engine.GET("/", func(c *Context) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/1.0", 1, 0
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusHTTPVersionNotSupported {
+		t.Fatalf("expected 505, got %d", w.Code)
+	}
+}
+
+// startGuardedServer 在连接层启用 guard 并返回服务地址
+func startGuardedServer(t *testing.T, guard *SmugglingGuard) string {
+	t.Helper()
+	engine := New()
+	engine.SetSmugglingGuard(guard)
+	engine.ANY("/", func(c *Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s", body)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: engine}
+	go srv.Serve(guard.Listener(ln))
+	t.Cleanup(func() { srv.Close() })
+	return ln.Addr().String()
+}
+
+// rawRoundTrip 发送原始请求并读取全部响应的状态码, 直到连接关闭或超时
+func rawRoundTrip(t *testing.T, addr, raw string, responses int) []int {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.WriteString(conn, raw); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	var codes []int
+	for range responses {
+		resp, err := http.ReadResponse(br, nil)
+		if err != nil {
+			break
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		codes = append(codes, resp.StatusCode)
+	}
+	return codes
+}
+
+func TestSmugglingGuardListener(t *testing.T) {
+	guard := NewSmugglingGuard(SmugglingGuardOptions{RejectAmbiguousFraming: true, RejectObsFold: true})
+	addr := startGuardedServer(t, guard)
+
+	tests := []struct {
+		name  string
+		raw   string
+		codes []int
+	}{
+		{
+			name:  "TE and CL",
+			raw:   "POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 5\r\nTransfer-Encoding: chunked\r\n\r\n0\r\n\r\n",
+			codes: []int{http.StatusBadRequest},
+		},
+		{
+			name:  "duplicate CL",
+			raw:   "POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 1\r\nContent-Length: 1\r\n\r\nx",
+			codes: []int{http.StatusBadRequest},
+		},
+		{
+			name:  "obs-fold",
+			raw:   "GET / HTTP/1.1\r\nHost: a\r\nX-A: foo\r\n bar\r\n\r\n",
+			codes: []int{http.StatusBadRequest},
+		},
+		{
+			name:  "TE on HTTP/1.0",
+			raw:   "POST / HTTP/1.0\r\nHost: a\r\nTransfer-Encoding: chunked\r\n\r\n0\r\n\r\n",
+			codes: []int{http.StatusBadRequest},
+		},
+		{
+			// 请求体中看似头部的内容不应被误判
+			name: "pipelined bodies",
+			raw: "POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 12\r\n\r\n\r\n folded\r\n\r\n" +
+				"POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding: chunked\r\n\r\n9;ext=1\r\n\r\n folded\r\n0\r\nX-Trailer: 1\r\n\r\n" +
+				"GET / HTTP/1.1\r\nHost: a\r\nConnection: close\r\n\r\n",
+			codes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes := rawRoundTrip(t, addr, tt.raw, len(tt.codes)+1)
+			if len(codes) != len(tt.codes) {
+				t.Fatalf("expected responses %v, got %v", tt.codes, codes)
+			}
+			for i := range codes {
+				if codes[i] != tt.codes[i] {
+					t.Fatalf("expected responses %v, got %v", tt.codes, codes)
+				}
+			}
+		})
+	}
+
+	stats := guard.Stats()
+	if stats[SmugglingAmbiguousLength] != 2 || stats[SmugglingObsFold] != 1 || stats[SmugglingInvalidTransfer] != 1 {
+		t.Fatalf("unexpected stats: %v", stats)
+	}
+}
+
+func TestFramingParserByteByByte(t *testing.T) {
+	opts := &SmugglingGuardOptions{RejectAmbiguousFraming: true, RejectObsFold: true}
+	raw := "POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n0\r\n\r\n" +
+		"POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 5\r\nTransfer-Encoding: chunked\r\n\r\n"
+	var p framingParser
+	for i := 0; i < len(raw); i++ {
+		if reason, bad := p.feed([]byte{raw[i]}, opts); bad {
+			if reason != SmugglingAmbiguousLength || i != len(raw)-1 {
+				t.Fatalf("unexpected rejection %q at byte %d", reason, i)
+			}
+			return
+		}
+	}
+	t.Fatal("expected the second request to be rejected")
+}
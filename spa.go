@@ -0,0 +1,97 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// UnMatchFSOptions 配置 UnMatchFS 在文件不存在时的回退
+type UnMatchFSOptions struct {
+	// SPAIndex 为单页应用的入口文件 (例如 "/index.html"). 设置后, 对不存在的页面路径 (没有扩展名,
+	// 或 Accept 包含 text/html) 的 GET/HEAD 请求以 200 返回该文件, 由前端路由处理
+	SPAIndex string
+	// SPAExclude 为不做 SPA 回退的路径前缀, 例如 "/api/", 这些路径仍按未匹配处理
+	SPAExclude []string
+	// NotFoundFile 为自定义 404 页面 (例如 "/404.html"), 其余不存在的路径以 404 状态返回该文件
+	NotFoundFile string
+}
+
+// fsFallback 在文件服务器返回 404 时提供 SPA index 或自定义 404 页面
+type fsFallback struct {
+	fs   http.FileSystem
+	opts UnMatchFSOptions
+}
+
+// serve 尝试处理文件不存在的请求, 成功写出响应时返回 true
+func (f *fsFallback) serve(c *Context) bool {
+	if f.opts.SPAIndex != "" && f.wantsSPAIndex(c.Request) {
+		if serveFSFile(c, f.fs, f.opts.SPAIndex, http.StatusOK) {
+			return true
+		}
+	}
+	if f.opts.NotFoundFile != "" {
+		return serveFSFile(c, f.fs, f.opts.NotFoundFile, http.StatusNotFound)
+	}
+	return false
+}
+
+// wantsSPAIndex 判断请求是否是前端路由页面而不是缺失的静态资源
+func (f *fsFallback) wantsSPAIndex(r *http.Request) bool {
+	p := r.URL.Path
+	for _, prefix := range f.opts.SPAExclude {
+		if strings.HasPrefix(p, prefix) {
+			return false
+		}
+	}
+	return path.Ext(p) == "" || strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// serveFSFile 以 status 返回 fs 中的 name, 文件不存在或是目录时返回 false.
+// 200 时使用 http.ServeContent 以支持条件请求; 其他状态码直接写出内容
+func serveFSFile(c *Context, fs http.FileSystem, name string, status int) bool {
+	f, err := fs.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	header := c.Writer.Header()
+	// 文件服务器返回 404 时可能已设置 text/plain, 需要覆盖
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		header.Set("Content-Type", ct)
+	} else {
+		header.Del("Content-Type")
+	}
+	if status == http.StatusOK {
+		// 直接提供文件而不经过 http.FileServer, 避免其将 /index.html 重定向到 /
+		http.ServeContent(c.Writer, c.Request, path.Base(name), info.ModTime(), f)
+		return true
+	}
+	header.Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	header.Set("Cache-Control", "no-cache")
+	c.Writer.WriteHeader(status)
+	if c.Request.Method != http.MethodHead {
+		_, _ = io.Copy(c.Writer, f)
+	}
+	return true
+}
+
+// unmatchedFSHandleFunc 返回带有回退处理的 UnMatchFS 文件服务处理器
+func unmatchedFSHandleFunc(fsHandle http.Handler, fallback *fsFallback) HandlerFunc {
+	return func(c *Context) {
+		fileServerHandleServe(c, fsHandle, fallback)
+		c.Abort()
+	}
+}
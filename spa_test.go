@@ -0,0 +1,51 @@
+package touka
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUnMatchFSOptions(t *testing.T) {
+	fsys := http.FS(fstest.MapFS{
+		"index.html":  {Data: []byte("<app>")},
+		"404.html":    {Data: []byte("<missing>")},
+		"assets/a.js": {Data: []byte("js")},
+	})
+	engine := New()
+	engine.SetUnMatchFSOptions(fsys, UnMatchFSOptions{
+		SPAIndex:     "/index.html",
+		SPAExclude:   []string{"/api/"},
+		NotFoundFile: "/404.html",
+	})
+	engine.GET("/api/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+
+	cases := []struct {
+		path   string
+		accept string
+		code   int
+		body   string
+	}{
+		{"/assets/a.js", "", http.StatusOK, "js"},
+		{"/users/42", "", http.StatusOK, "<app>"},
+		{"/settings.profile", "text/html,application/xhtml+xml", http.StatusOK, "<app>"},
+		{"/assets/missing.js", "*/*", http.StatusNotFound, "<missing>"},
+		{"/api/unknown", "text/html", http.StatusNotFound, "<missing>"},
+		{"/api/ping", "", http.StatusOK, "pong"},
+	}
+	for _, tc := range cases {
+		var header http.Header
+		if tc.accept != "" {
+			header = http.Header{"Accept": {tc.accept}}
+		}
+		w := PerformRequest(engine, http.MethodGet, tc.path, nil, header)
+		if w.Code != tc.code || w.Body.String() != tc.body {
+			t.Errorf("%s: got %d %q, want %d %q", tc.path, w.Code, w.Body.String(), tc.code, tc.body)
+		}
+	}
+
+	w := PerformRequest(engine, http.MethodGet, "/users/42", nil, nil)
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Fatalf("unexpected SPA index Content-Type %q", ct)
+	}
+}
@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// statsMethods 为单独计数的请求方法, 其他方法计入 OTHER
+var statsMethods = [...]string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace,
+}
+
+// requestCounters 统计引擎处理中的请求数与按方法的累计请求数
+type requestCounters struct {
+	inFlight atomic.Int64
+	byMethod [len(statsMethods) + 1]atomic.Uint64
+}
+
+func (r *requestCounters) begin() {
+	r.inFlight.Add(1)
+}
+
+// count 按方法累计请求数, 被 ObservabilityFilter 过滤的请求不计入
+func (r *requestCounters) count(method string) {
+	i := len(statsMethods)
+	for j, m := range statsMethods {
+		if m == method {
+			i = j
+			break
+		}
+	}
+	r.byMethod[i].Add(1)
+}
+
+func (r *requestCounters) end() {
+	r.inFlight.Add(-1)
+}
+
+// EnableStats 开启请求统计: 处理中与按方法的请求数 (Stats、StatsHandler 与 Metrics), 以及对象池的取用次数 (PoolStats).
+// 统计在每个请求上更新共享的原子计数器, 默认关闭以免拖慢请求处理; 应在启动服务前调用
+func (engine *Engine) EnableStats() {
+	engine.statsEnabled = true
+	poolStatsEnabled.Store(true)
+}
+
+// EngineStats 是引擎负载的快照
+type EngineStats struct {
+	Started       time.Time            `json:"started"`        // 引擎创建时间
+	Uptime        time.Duration        `json:"-"`              // 自创建以来的时长
+	UptimeSeconds float64              `json:"uptime_seconds"` // Uptime 的秒数, 便于 JSON 消费方使用
+	InFlight      int64                `json:"in_flight"`      // 正在处理的请求数
+	Requests      uint64               `json:"requests"`       // 累计请求数, 不含被 ObservabilityFilter 过滤的请求
+	Methods       map[string]uint64    `json:"methods"`        // 按方法的累计请求数, 非标准方法计入 OTHER
+	ClientErrors  uint64               `json:"client_errors"`  // 参见 ErrorCounts
+	ServerErrors  uint64               `json:"server_errors"`
+	Goroutines    int                  `json:"goroutines"`
+	Connections   ConnStats            `json:"connections"`
+	Pools         map[string]PoolStats `json:"pools"`
+}
+
+// Stats 返回引擎当前的负载统计, 可以在程序中据此调节上游流量, 也可以通过 StatsHandler 对外提供.
+// InFlight、Requests 与 Methods 只在调用 EnableStats 后统计
+func (engine *Engine) Stats() EngineStats {
+	s := EngineStats{
+		Started:     engine.started,
+		Uptime:      time.Since(engine.started),
+		InFlight:    engine.requests.inFlight.Load(),
+		Methods:     make(map[string]uint64, len(statsMethods)+1),
+		Goroutines:  runtime.NumGoroutine(),
+		Connections: engine.ConnStats(),
+		Pools:       engine.PoolStats(),
+	}
+	s.UptimeSeconds = s.Uptime.Seconds()
+	for i := range engine.requests.byMethod {
+		name := "OTHER"
+		if i < len(statsMethods) {
+			name = statsMethods[i]
+		}
+		n := engine.requests.byMethod[i].Load()
+		s.Methods[name] = n
+		s.Requests += n
+	}
+	s.ClientErrors, s.ServerErrors = engine.ErrorCounts()
+	return s
+}
+
+// StatsHandler 返回以 JSON 输出 Stats 的处理器. 统计中包含内部状态, 应挂载在受保护的管理路由上:
+//
+//	admin := r.Group("/admin", touka.APIKeyAuth(opts))
+//	admin.GET("/stats", r.StatsHandler())
+func (engine *Engine) StatsHandler() HandlerFunc {
+	return func(c *Context) {
+		c.SetHeader("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, engine.Stats())
+	}
+}
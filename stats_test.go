@@ -0,0 +1,63 @@
+package touka
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+)
+
+func TestEngineStats(t *testing.T) {
+	engine := New()
+	engine.EnableStats()
+	entered, release := make(chan struct{}), make(chan struct{})
+	engine.GET("/slow", func(c *Context) {
+		close(entered)
+		<-release
+		c.Status(http.StatusOK)
+	})
+	engine.POST("/items", func(c *Context) { c.Status(http.StatusCreated) })
+	engine.GET("/stats", engine.StatsHandler())
+
+	done := make(chan struct{})
+	go func() {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		close(done)
+	}()
+	<-entered
+	PerformRequest(engine, http.MethodPost, "/items", nil, nil)
+	PerformRequest(engine, "PROPFIND", "/items", nil, nil)
+
+	s := engine.Stats()
+	if s.InFlight != 1 || s.Methods[http.MethodGet] != 1 || s.Methods[http.MethodPost] != 1 || s.Methods["OTHER"] != 1 || s.Requests != 3 {
+		t.Fatalf("unexpected stats while a request is in flight: %+v", s)
+	}
+	close(release)
+	<-done
+
+	w := PerformRequest(engine, http.MethodGet, "/stats", nil, nil)
+	var got struct {
+		InFlight int64             `json:"in_flight"`
+		Methods  map[string]uint64 `json:"methods"`
+		Uptime   float64           `json:"uptime_seconds"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid stats response %q: %v", w.Body.String(), err)
+	}
+	// 统计请求本身处于处理中
+	if got.InFlight != 1 || got.Methods[http.MethodGet] != 2 || got.Uptime <= 0 {
+		t.Fatalf("unexpected stats response %s", w.Body.String())
+	}
+}
+
+func TestEngineStatsDisabledByDefault(t *testing.T) {
+	engine := New()
+	engine.GET("/ping", func(c *Context) { c.Status(http.StatusOK) })
+	PerformRequest(engine, http.MethodGet, "/ping", nil, nil)
+
+	s := engine.Stats()
+	if s.Requests != 0 || s.InFlight != 0 || s.Pools["context"].Gets != 0 || s.Pools["context"].Puts != 0 {
+		t.Fatalf("expected no request counting without EnableStats, got %+v", s)
+	}
+}
@@ -0,0 +1,115 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ErrBindTimeout 表示绑定时读取请求体的耗时超过了 SetBindTimeout 设置的上限.
+// 它是状态码为 408 的 *HTTPError, 类型化处理器与 BindFormOrRender 遇到它时以 408 交给 ErrorHandler
+var ErrBindTimeout = NewHTTPError(http.StatusRequestTimeout, errors.New("request body read timed out"))
+
+// ErrRenderTimeout 表示模板渲染的耗时超过了 SetRenderTimeout 设置的上限
+var ErrRenderTimeout = errors.New("template rendering timed out")
+
+// SetBindTimeout 限制绑定请求体 (ShouldBind* 与 PostForm 等) 时读取请求体累计花费的时间, 0 表示不限制.
+// 只统计阻塞在读取上的时间, 与处理函数自身的耗时无关; 底层连接支持时通过读超时打断阻塞的读取,
+// 因此缓慢发送请求体的客户端不会长期占用工作协程. 超时后读取返回 ErrBindTimeout
+func (engine *Engine) SetBindTimeout(d time.Duration) {
+	engine.bindTimeout = max(d, 0)
+}
+
+// SetRenderTimeout 限制 HTML 与 HTMLBuf 渲染模板的时间, 0 表示不限制. 超时后模板的下一次输出失败,
+// 渲染以 ErrRenderTimeout 中止并通过 ErrorHandler 返回 500
+func (engine *Engine) SetRenderTimeout(d time.Duration) {
+	engine.renderTimeout = max(d, 0)
+}
+
+// bindRequestBody 返回供绑定读取的请求体: 在 prepareRequestBody 的基础上应用 SetBindTimeout 的读取时间上限
+func (c *Context) bindRequestBody() io.ReadCloser {
+	body := c.prepareRequestBody()
+	if body == nil || body == http.NoBody || c.engine == nil || c.engine.bindTimeout <= 0 {
+		return body
+	}
+	if tb, ok := body.(*timedBody); ok {
+		return tb
+	}
+	tb := &timedBody{
+		ReadCloser: body,
+		c:          c,
+		remaining:  c.engine.bindTimeout,
+		rc:         http.NewResponseController(UnwrapResponseWriter(c.Writer)),
+	}
+	c.Request.Body = tb
+	return tb
+}
+
+// timedBody 统计阻塞在 Read 上的累计时间, 超过上限后返回 ErrBindTimeout
+type timedBody struct {
+	io.ReadCloser
+	c         *Context
+	remaining time.Duration
+	rc        *http.ResponseController
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, ErrBindTimeout
+	}
+	start := time.Now()
+	// 连接不支持读超时 (例如测试中的 ResponseRecorder) 时只能在两次读取之间检查
+	deadline := b.rc.SetReadDeadline(start.Add(b.remaining)) == nil
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= time.Since(start)
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		b.remaining = 0
+		// 保留已过期的读超时, 使 net/http 写响应前丢弃剩余请求体的读取立即失败并在响应后关闭连接.
+		// net/http 在连接读取出错时会取消请求的 context, 但此时连接仍然可写;
+		// 解除取消, 使 ErrorHandler 能够返回 408 而不是视为客户端已断开
+		ctx := context.WithoutCancel(b.c.Request.Context())
+		b.c.Request = b.c.Request.WithContext(ctx)
+		b.c.ctx = ctx
+		return n, ErrBindTimeout
+	}
+	if deadline {
+		// 清除读超时, 避免影响绑定之后对连接的读取
+		b.rc.SetReadDeadline(time.Time{})
+	}
+	return n, err
+}
+
+// bindErrorStatus 返回绑定失败时的状态码: 读取请求体超时为 408, 其他为 fallback
+func bindErrorStatus(err error, fallback int) int {
+	if errors.Is(err, ErrBindTimeout) {
+		return http.StatusRequestTimeout
+	}
+	return fallback
+}
+
+// renderWriter 返回渲染模板时使用的 Writer: 设置了 SetRenderTimeout 时, 超过期限后的写入返回 ErrRenderTimeout
+func (c *Context) renderWriter(w io.Writer) io.Writer {
+	if c.engine == nil || c.engine.renderTimeout <= 0 {
+		return w
+	}
+	return &deadlineWriter{w: w, deadline: time.Now().Add(c.engine.renderTimeout)}
+}
+
+type deadlineWriter struct {
+	w        io.Writer
+	deadline time.Time
+}
+
+func (w *deadlineWriter) Write(p []byte) (int, error) {
+	if time.Now().After(w.deadline) {
+		return 0, ErrRenderTimeout
+	}
+	return w.w.Write(p)
+}
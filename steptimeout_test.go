@@ -0,0 +1,134 @@
+package touka
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowReader 每次读取前等待 delay, 每次只返回一个字节
+type slowReader struct {
+	data  string
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestBindTimeoutSlowBody(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+	engine := New()
+	engine.SetBindTimeout(50 * time.Millisecond)
+	engine.POST("/typed", JSONHandler(func(c *Context, req payload) (payload, error) {
+		return req, nil
+	}))
+	var bindErr error
+	engine.POST("/plain", func(c *Context) {
+		var p payload
+		bindErr = c.ShouldBindJSON(&p)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/typed", &slowReader{data: `{"name":"alice"}`, delay: 20 * time.Millisecond})
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestTimeout {
+		t.Fatalf("expected 408, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/plain", &slowReader{data: `{"name":"alice"}`, delay: 20 * time.Millisecond})
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	if !errors.Is(bindErr, ErrBindTimeout) {
+		t.Fatalf("expected ErrBindTimeout, got %v", bindErr)
+	}
+}
+
+func TestBindTimeoutExcludesHandlerTime(t *testing.T) {
+	engine := New()
+	engine.SetBindTimeout(50 * time.Millisecond)
+	var bindErr error
+	engine.POST("/", func(c *Context) {
+		time.Sleep(80 * time.Millisecond) // 处理函数自身的耗时不计入
+		var p struct {
+			Name string `json:"name"`
+		}
+		bindErr = c.ShouldBindJSON(&p)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"alice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	if bindErr != nil {
+		t.Fatalf("unexpected error: %v", bindErr)
+	}
+}
+
+func TestBindTimeoutStalledConnection(t *testing.T) {
+	engine := New()
+	engine.SetBindTimeout(100 * time.Millisecond)
+	engine.POST("/", JSONHandler(func(c *Context, req H) (H, error) {
+		return req, nil
+	}))
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// 声明的请求体长度大于实际发送的内容, 随后停止发送
+	fmt.Fprint(conn, "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n{\"a\":")
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestTimeout {
+		t.Fatalf("expected 408, got %d", res.StatusCode)
+	}
+}
+
+func TestRenderTimeout(t *testing.T) {
+	engine := New()
+	engine.SetRenderTimeout(50 * time.Millisecond)
+	engine.HTMLRender = template.Must(template.New("page").Funcs(template.FuncMap{
+		"slow": func() string {
+			time.Sleep(20 * time.Millisecond)
+			return "x"
+		},
+	}).Parse(`{{range .}}{{slow}}{{end}}`))
+	engine.GET("/slow", func(c *Context) {
+		c.HTMLBuf(http.StatusOK, "page", make([]int, 10))
+	})
+	engine.GET("/fast", func(c *Context) {
+		c.HTMLBuf(http.StatusOK, "page", make([]int, 1))
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/slow", nil, nil)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	w = PerformRequest(engine, http.MethodGet, "/fast", nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "x" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+}
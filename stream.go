@@ -0,0 +1,241 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"encoding/csv"
+	"iter"
+	"mime"
+	"net/http"
+	"time"
+)
+
+const (
+	// streamFlushRows 为流式响应每写入多少行刷新一次
+	streamFlushRows = 64
+	// streamFlushInterval 为流式响应两次刷新之间的最长间隔, 避免慢速生产者的数据滞留在缓冲区
+	streamFlushInterval = time.Second
+)
+
+// attachmentDisposition 生成以 name 为文件名的 Content-Disposition, 非 ASCII 文件名按 RFC 2231 编码
+func attachmentDisposition(name string) string {
+	if v := mime.FormatMediaType("attachment", map[string]string{"filename": name}); v != "" {
+		return v
+	}
+	return "attachment"
+}
+
+// rowStream 管理逐行写出的流式响应: 第一行写出前才发送响应头, 定期刷新, 并在客户端断开后停止
+type rowStream struct {
+	c         *Context
+	started   bool
+	rows      int
+	lastFlush time.Time
+	flush     func() error // 刷新格式层的缓冲 (例如 csv.Writer), 可以为 nil
+}
+
+// begin 在写出第一行之前调用
+func (s *rowStream) begin() error {
+	if err := s.c.Context().Err(); err != nil {
+		return err
+	}
+	if !s.started {
+		s.started = true
+		s.lastFlush = time.Now()
+		s.c.Status(http.StatusOK)
+	}
+	return nil
+}
+
+// wrote 在写出一行之后调用, 按行数或间隔刷新
+func (s *rowStream) wrote() error {
+	s.rows++
+	if s.rows%streamFlushRows != 0 && time.Since(s.lastFlush) < streamFlushInterval {
+		return nil
+	}
+	return s.flushNow()
+}
+
+func (s *rowStream) flushNow() error {
+	if s.flush != nil {
+		if err := s.flush(); err != nil {
+			return err
+		}
+	}
+	s.c.Writer.Flush()
+	s.lastFlush = time.Now()
+	return nil
+}
+
+// finish 结束流: 生产者出错且尚未写出任何内容时交给 ErrorHandler, 否则只记录错误
+func (s *rowStream) finish(err error) error {
+	if err == nil && !s.started {
+		s.c.Status(http.StatusOK)
+	}
+	if s.started || err == nil {
+		if flushErr := s.flushNow(); err == nil {
+			err = flushErr
+		}
+	}
+	if err != nil {
+		s.c.AddError(err)
+		if !s.started {
+			s.c.Writer.Header().Del("Content-Disposition")
+			s.c.ErrorUseHandle(http.StatusInternalServerError, err)
+		}
+	}
+	return err
+}
+
+// NDJSONStream 以换行分隔 JSON (application/x-ndjson) 流式输出数据, 适用于导出接口.
+// produce 通过 emit 逐条写出记录, 每条记录按引擎的 JSON 选项编码为一行;
+// 客户端断开后 emit 返回 context 错误, produce 应停止生产并返回该错误.
+//
+// 响应头在第一条记录写出时发送, produce 在此之前返回错误时交给 ErrorHandler 处理;
+// 之后的错误只能记录到 c.Errors, 客户端会收到被截断的流. 返回 produce 或写入过程中的错误:
+//
+//	c.NDJSONStream(func(emit func(v any) error) error {
+//	    for rows.Next() {
+//	        var u User
+//	        if err := rows.Scan(&u.ID, &u.Name); err != nil {
+//	            return err
+//	        }
+//	        if err := emit(u); err != nil {
+//	            return err
+//	        }
+//	    }
+//	    return rows.Err()
+//	})
+func (c *Context) NDJSONStream(produce func(emit func(v any) error) error) error {
+	c.SetHeader("Content-Type", NDJSONContentType)
+	c.SetHeader("X-Content-Type-Options", "nosniff")
+	s := &rowStream{c: c}
+	err := produce(func(v any) error {
+		if err := s.begin(); err != nil {
+			return err
+		}
+		if err := c.marshalJSON(c.Writer, v); err != nil {
+			return err
+		}
+		if _, err := c.Writer.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+		return s.wrote()
+	})
+	return s.finish(err)
+}
+
+// JSONArrayStream 以 JSON 数组 (application/json) 流式输出数据, 用法与 NDJSONStream 相同,
+// 适用于只接受普通 JSON 的客户端. 没有任何记录时输出 [];
+// 第一条记录写出后出错时数组不会闭合, 客户端解析会失败而不是收到不完整的结果
+func (c *Context) JSONArrayStream(produce func(emit func(v any) error) error) error {
+	c.SetHeader("Content-Type", "application/json; charset=utf-8")
+	c.SetHeader("X-Content-Type-Options", "nosniff")
+	s := &rowStream{c: c}
+	err := produce(func(v any) error {
+		if err := s.begin(); err != nil {
+			return err
+		}
+		sep := []byte{','}
+		if s.rows == 0 {
+			sep[0] = '['
+		}
+		if _, err := c.Writer.Write(sep); err != nil {
+			return err
+		}
+		if err := c.marshalJSON(c.Writer, v); err != nil {
+			return err
+		}
+		return s.wrote()
+	})
+	if err == nil {
+		end := "]"
+		if s.rows == 0 {
+			end = "[]"
+		}
+		if err = s.begin(); err == nil {
+			_, err = c.Writer.Write([]byte(end))
+		}
+	}
+	return s.finish(err)
+}
+
+// EmitSeq 将迭代器转换为 NDJSONStream 与 JSONArrayStream 的生产者, 客户端断开后停止迭代:
+//
+//	c.NDJSONStream(touka.EmitSeq(store.IterUsers(ctx)))
+func EmitSeq[T any](seq iter.Seq[T]) func(emit func(v any) error) error {
+	return func(emit func(v any) error) error {
+		for v := range seq {
+			if err := emit(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// EmitChan 将 channel 转换为 NDJSONStream 与 JSONArrayStream 的生产者, 写出 channel 中的值直到其被关闭.
+// ctx 被取消时停止并返回 ctx 的错误, 通常传入 c.Context(); 发送方应在停止接收后自行退出:
+//
+//	rows := make(chan Row)
+//	go export(ctx, rows) // 完成后 close(rows)
+//	c.JSONArrayStream(touka.EmitChan(c.Context(), rows))
+func EmitChan[T any](ctx context.Context, ch <-chan T) func(emit func(v any) error) error {
+	return func(emit func(v any) error) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case v, ok := <-ch:
+				if !ok {
+					return nil
+				}
+				if err := emit(v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// CSVStream 以 CSV (text/csv) 流式输出数据, 用法与 NDJSONStream 相同.
+// filename 非空时设置 Content-Disposition 使浏览器下载为该文件; header 非空时作为第一行在第一条记录之前写出
+func (c *Context) CSVStream(filename string, header []string, produce func(emit func(record []string) error) error) error {
+	c.SetHeader("Content-Type", "text/csv; charset=utf-8")
+	c.SetHeader("X-Content-Type-Options", "nosniff")
+	if filename != "" {
+		c.SetHeader("Content-Disposition", attachmentDisposition(filename))
+	}
+	w := csv.NewWriter(c.Writer)
+	s := &rowStream{c: c, flush: func() error {
+		w.Flush()
+		return w.Error()
+	}}
+	err := produce(func(record []string) error {
+		if !s.started && len(header) > 0 {
+			if err := s.begin(); err != nil {
+				return err
+			}
+			if err := w.Write(header); err != nil {
+				return err
+			}
+		}
+		if err := s.begin(); err != nil {
+			return err
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+		return s.wrote()
+	})
+	// 没有任何记录时仍然输出表头
+	if err == nil && !s.started && len(header) > 0 {
+		if err = s.begin(); err == nil {
+			err = w.Write(header)
+		}
+	}
+	return s.finish(err)
+}
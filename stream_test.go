@@ -0,0 +1,121 @@
+package touka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestNDJSONStream(t *testing.T) {
+	engine := New()
+	engine.GET("/export", func(c *Context) {
+		c.NDJSONStream(func(emit func(v any) error) error {
+			for i := range 3 {
+				if err := emit(H{"id": i}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	engine.GET("/fail", func(c *Context) {
+		c.NDJSONStream(func(emit func(v any) error) error {
+			return errors.New("query failed")
+		})
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/export", nil, nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != NDJSONContentType {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+	if w.Body.String() != "{\"id\":0}\n{\"id\":1}\n{\"id\":2}\n" {
+		t.Fatalf("unexpected body %q", w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/fail", nil, nil)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected error before the first record to use the error handler, got %d", w.Code)
+	}
+}
+
+func TestJSONArrayStream(t *testing.T) {
+	engine := New()
+	engine.GET("/seq", func(c *Context) {
+		c.JSONArrayStream(EmitSeq(slices.Values([]int{1, 2, 3})))
+	})
+	engine.GET("/empty", func(c *Context) {
+		c.JSONArrayStream(EmitSeq(slices.Values([]int(nil))))
+	})
+	engine.GET("/chan", func(c *Context) {
+		ch := make(chan H)
+		go func() {
+			defer close(ch)
+			for i := range 2 {
+				ch <- H{"id": i}
+			}
+		}()
+		c.NDJSONStream(EmitChan(c.Context(), ch))
+	})
+	engine.GET("/fail", func(c *Context) {
+		c.JSONArrayStream(func(emit func(v any) error) error {
+			if err := emit(1); err != nil {
+				return err
+			}
+			return errors.New("query failed")
+		})
+	})
+
+	tests := []struct {
+		path, contentType, body string
+	}{
+		{"/seq", "application/json; charset=utf-8", "[1,2,3]"},
+		{"/empty", "application/json; charset=utf-8", "[]"},
+		{"/chan", NDJSONContentType, "{\"id\":0}\n{\"id\":1}\n"},
+		{"/fail", "application/json; charset=utf-8", "[1"},
+	}
+	for _, tt := range tests {
+		w := PerformRequest(engine, http.MethodGet, tt.path, nil, nil)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != tt.contentType || w.Body.String() != tt.body {
+			t.Errorf("%s: got %d %q %q, want %q %q", tt.path, w.Code, w.Header().Get("Content-Type"), w.Body.String(), tt.contentType, tt.body)
+		}
+	}
+}
+
+func TestEmitChanStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := EmitChan(ctx, make(chan int))(func(v any) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestCSVStream(t *testing.T) {
+	engine := New()
+	engine.GET("/users.csv", func(c *Context) {
+		c.CSVStream("用户.csv", []string{"id", "name"}, func(emit func(record []string) error) error {
+			if err := emit([]string{"1", "a,b"}); err != nil {
+				return err
+			}
+			return emit([]string{"2", "c"})
+		})
+	})
+	engine.GET("/empty.csv", func(c *Context) {
+		c.CSVStream("", []string{"id"}, func(emit func(record []string) error) error { return nil })
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/users.csv", nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "id,name\n1,\"a,b\"\n2,c\n" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != "attachment; filename*=utf-8''%E7%94%A8%E6%88%B7.csv" {
+		t.Fatalf("unexpected Content-Disposition %q", got)
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/empty.csv", nil, nil)
+	if w.Body.String() != "id\n" {
+		t.Fatalf("expected header row for empty export, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,168 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+var (
+	// ErrStreamTokenMissing 表示流式端点的请求没有携带令牌
+	ErrStreamTokenMissing = errors.New("stream token missing")
+	// ErrStreamTokenInvalid 表示令牌未通过校验
+	ErrStreamTokenInvalid = errors.New("stream token invalid")
+	// ErrStreamAuthTimeout 表示在期限内没有收到认证消息
+	ErrStreamAuthTimeout = errors.New("stream auth message timeout")
+)
+
+// streamPrincipalKey 是认证主体在 Context.Keys 中的键
+const streamPrincipalKey = "touka.stream_principal"
+
+// TokenValidator 校验令牌并返回认证主体 (例如用户 ID 或 claims).
+// 令牌无效时返回错误, 可以包装 ErrStreamTokenInvalid; 其他错误按服务端错误处理
+type TokenValidator func(c *Context, token string) (principal any, err error)
+
+// StreamAuthOptions 配置 StreamAuth
+type StreamAuthOptions struct {
+	// Validate 校验令牌, 必填
+	Validate TokenValidator
+	// QueryParam 为携带令牌的查询参数, 默认 access_token.
+	// EventSource 与浏览器 WebSocket 无法设置请求头部, 只能通过查询参数传递令牌
+	QueryParam string
+	// KeepQuery 为 true 时保留 URL 中的令牌. 默认从请求 URL 中移除, 避免后续中间件、日志或反向代理看到令牌
+	KeepQuery bool
+}
+
+// StreamAuth 返回在流开始之前认证 SSE 与 WebSocket 端点的中间件.
+// 令牌依次取自 Authorization: Bearer 头部与 QueryParam 查询参数, 缺失或无效时返回 401 并设置
+// WWW-Authenticate, 通过后处理器可以用 c.StreamPrincipal() 取得认证主体:
+//
+//	r.GET("/events", touka.StreamAuth(touka.StreamAuthOptions{Validate: verify}), func(c *touka.Context) {
+//	    user, _ := c.StreamPrincipal()
+//	    c.EventStream(...)
+//	})
+func StreamAuth(opts StreamAuthOptions) HandlerFunc {
+	if opts.Validate == nil {
+		panic("touka: stream auth Validate must not be nil")
+	}
+	if opts.QueryParam == "" {
+		opts.QueryParam = "access_token"
+	}
+	return func(c *Context) {
+		token, fromQuery := bearerToken(c.Request.Header.Get("Authorization")), false
+		if token == "" {
+			token, fromQuery = c.Query(opts.QueryParam), true
+		}
+		if fromQuery && !opts.KeepQuery {
+			stripQueryParam(c, opts.QueryParam)
+		}
+		if token == "" {
+			streamUnauthorized(c, ErrStreamTokenMissing)
+			return
+		}
+		principal, err := opts.Validate(c, token)
+		switch {
+		case errors.Is(err, ErrStreamTokenInvalid):
+			streamUnauthorized(c, err)
+			return
+		case err != nil:
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusInternalServerError, fmt.Errorf("failed to validate stream token: %w", err))
+			return
+		}
+		c.Set(streamPrincipalKey, principal)
+		c.Next()
+	}
+}
+
+// StreamPrincipal 返回 StreamAuth 或 FirstMessageAuth 认证得到的主体
+func (c *Context) StreamPrincipal() (any, bool) {
+	return c.Get(streamPrincipalKey)
+}
+
+// streamUnauthorized 在流开始之前以 401 拒绝请求
+func streamUnauthorized(c *Context, err error) {
+	c.SetHeader("WWW-Authenticate", `Bearer error="invalid_token"`)
+	c.AddClientError(err)
+	c.ErrorUseHandle(http.StatusUnauthorized, err)
+}
+
+// bearerToken 从 Authorization 头部取出 Bearer 令牌
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// stripQueryParam 从请求 URL 中移除查询参数, 并使查询缓存失效
+func stripQueryParam(c *Context, name string) {
+	query := c.Request.URL.Query()
+	if !query.Has(name) {
+		return
+	}
+	query.Del(name)
+	c.Request.URL.RawQuery = query.Encode()
+	if c.Request.RequestURI != "" {
+		c.Request.RequestURI = c.Request.URL.RequestURI()
+	}
+	c.queryCache = nil
+}
+
+// FirstMessageAuth 在 WebSocket 等双向流建立后, 以客户端发送的第一条消息完成认证, 适用于不便把令牌放进 URL 的场景.
+// read 读取一条消息, 应在 ctx 结束时返回; 消息为 {"token":"..."} 形式的 JSON 或令牌本身.
+// 超过 timeout (默认 10s) 未收到消息时返回 ErrStreamAuthTimeout. 认证成功后主体同样可以通过 c.StreamPrincipal() 取得;
+// 此时响应已经开始, 失败时调用方应关闭连接 (WebSocket 使用 1008 Policy Violation)
+func FirstMessageAuth(c *Context, timeout time.Duration, read func(ctx context.Context) ([]byte, error), validate TokenValidator) (any, error) {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), timeout)
+	defer cancel()
+
+	msg, err := read(ctx)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = ErrStreamAuthTimeout
+		}
+		c.AddClientError(err)
+		return nil, err
+	}
+	token := string(bytes.TrimSpace(msg))
+	if strings.HasPrefix(token, "{") {
+		var payload struct {
+			Token string `json:"token"`
+		}
+		if err := json.Unmarshal(msg, &payload); err != nil {
+			c.AddClientError(ErrStreamTokenInvalid)
+			return nil, ErrStreamTokenInvalid
+		}
+		token = payload.Token
+	}
+	if token == "" {
+		c.AddClientError(ErrStreamTokenMissing)
+		return nil, ErrStreamTokenMissing
+	}
+	principal, err := validate(c, token)
+	if err != nil {
+		if errors.Is(err, ErrStreamTokenInvalid) {
+			c.AddClientError(err)
+		} else {
+			c.AddError(err)
+		}
+		return nil, err
+	}
+	c.Set(streamPrincipalKey, principal)
+	return principal, nil
+}
@@ -0,0 +1,90 @@
+package touka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func verifyStreamToken(c *Context, token string) (any, error) {
+	if token == "secret" {
+		return "alice", nil
+	}
+	if token == "broken" {
+		return nil, errors.New("token store unavailable")
+	}
+	return nil, ErrStreamTokenInvalid
+}
+
+func TestStreamAuth(t *testing.T) {
+	engine := New()
+	engine.GET("/events", StreamAuth(StreamAuthOptions{Validate: verifyStreamToken}), func(c *Context) {
+		user, _ := c.StreamPrincipal()
+		c.String(http.StatusOK, "%v %s", user, c.Request.URL.RawQuery)
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/events?access_token=secret&topic=a", nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "alice topic=a" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/events", nil, http.Header{"Authorization": {"Bearer secret"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected bearer header to be accepted, got %d", w.Code)
+	}
+
+	for path, want := range map[string]int{
+		"/events":                     http.StatusUnauthorized,
+		"/events?access_token=guess":  http.StatusUnauthorized,
+		"/events?access_token=broken": http.StatusInternalServerError,
+	} {
+		w := PerformRequest(engine, http.MethodGet, path, nil, nil)
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+		if want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: expected WWW-Authenticate header", path)
+		}
+	}
+}
+
+func TestStreamAuthKeepQuery(t *testing.T) {
+	engine := New()
+	engine.GET("/events", StreamAuth(StreamAuthOptions{Validate: verifyStreamToken, QueryParam: "t", KeepQuery: true}), func(c *Context) {
+		c.String(http.StatusOK, "%s", c.Query("t"))
+	})
+	if w := PerformRequest(engine, http.MethodGet, "/events?t=secret", nil, nil); w.Body.String() != "secret" {
+		t.Fatalf("expected the token to stay in the query, got %q", w.Body.String())
+	}
+}
+
+func TestFirstMessageAuth(t *testing.T) {
+	c, _ := CreateTestContext(nil)
+	message := func(msg string) func(ctx context.Context) ([]byte, error) {
+		return func(ctx context.Context) ([]byte, error) { return []byte(msg), nil }
+	}
+
+	principal, err := FirstMessageAuth(c, 0, message(`{"token":"secret"}`), verifyStreamToken)
+	if err != nil || principal != "alice" {
+		t.Fatalf("unexpected result %v %v", principal, err)
+	}
+	if p, _ := c.StreamPrincipal(); p != "alice" {
+		t.Fatalf("expected principal to be stored, got %v", p)
+	}
+	if _, err := FirstMessageAuth(c, 0, message(" guess\n"), verifyStreamToken); !errors.Is(err, ErrStreamTokenInvalid) {
+		t.Fatalf("expected invalid token, got %v", err)
+	}
+	if _, err := FirstMessageAuth(c, 0, message(`{}`), verifyStreamToken); !errors.Is(err, ErrStreamTokenMissing) {
+		t.Fatalf("expected missing token, got %v", err)
+	}
+
+	block := func(ctx context.Context) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if _, err := FirstMessageAuth(c, 10*time.Millisecond, block, verifyStreamToken); !errors.Is(err, ErrStreamAuthTimeout) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrTenantNotFound 表示无法从请求中解析出租户, 或租户不存在
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// tenantKey 是 Tenant 在 Context.Keys 中的键
+const tenantKey = "touka.tenant"
+
+// Tenant 表示当前请求所属的租户
+type Tenant struct {
+	ID       string
+	Name     string
+	Metadata map[string]any
+}
+
+// TenantResolver 从请求中解析租户.
+// 无法解析时返回 (nil, nil), 交由后续的解析器或 Tenancy 的 Optional 配置处理
+type TenantResolver interface {
+	ResolveTenant(c *Context) (*Tenant, error)
+}
+
+// TenantResolverFunc 是 TenantResolver 的函数适配器
+type TenantResolverFunc func(c *Context) (*Tenant, error)
+
+func (f TenantResolverFunc) ResolveTenant(c *Context) (*Tenant, error) {
+	return f(c)
+}
+
+// TenantFromSubdomain 从子域名解析租户, 例如 baseDomain 为 example.com 时 acme.example.com 解析为 acme
+func TenantFromSubdomain(baseDomain string) TenantResolver {
+	suffix := "." + strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+	return TenantResolverFunc(func(c *Context) (*Tenant, error) {
+		host := c.Request.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(host)
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || sub == "" || strings.Contains(sub, ".") {
+			return nil, nil
+		}
+		return &Tenant{ID: sub}, nil
+	})
+}
+
+// TenantFromHeader 从请求头解析租户, 例如 X-Tenant-ID
+func TenantFromHeader(header string) TenantResolver {
+	return TenantResolverFunc(func(c *Context) (*Tenant, error) {
+		if id := strings.TrimSpace(c.GetReqHeader(header)); id != "" {
+			return &Tenant{ID: id}, nil
+		}
+		return nil, nil
+	})
+}
+
+// TenantFromParam 从路径参数解析租户, 例如路由 /t/:tenant/... 中的 tenant
+func TenantFromParam(name string) TenantResolver {
+	return TenantResolverFunc(func(c *Context) (*Tenant, error) {
+		if id := c.Param(name); id != "" {
+			return &Tenant{ID: id}, nil
+		}
+		return nil, nil
+	})
+}
+
+// ChainTenantResolvers 依次尝试多个解析器, 返回第一个解析出的租户
+func ChainTenantResolvers(resolvers ...TenantResolver) TenantResolver {
+	return TenantResolverFunc(func(c *Context) (*Tenant, error) {
+		for _, r := range resolvers {
+			tenant, err := r.ResolveTenant(c)
+			if err != nil || tenant != nil {
+				return tenant, err
+			}
+		}
+		return nil, nil
+	})
+}
+
+// TenancyOptions 配置 Tenancy 中间件
+type TenancyOptions struct {
+	// Resolver 从请求中解析租户, 必填
+	Resolver TenantResolver
+	// Lookup 根据解析出的租户 ID 加载完整的租户信息, 返回 ErrTenantNotFound 时响应 404.
+	// 为 nil 时直接使用解析器返回的租户
+	Lookup func(ctx context.Context, id string) (*Tenant, error)
+	// Optional 为 true 时允许请求不属于任何租户
+	Optional bool
+	// ScopeLogger 为 true 时, 当前请求的日志会附加 [tenant=<id>] 前缀
+	ScopeLogger bool
+}
+
+// Tenancy 返回一个解析租户并存入 Context 的中间件, 处理器中可通过 c.Tenant() 获取.
+// 无法解析租户且未设置 Optional 时返回 404
+func Tenancy(opts TenancyOptions) HandlerFunc {
+	if opts.Resolver == nil {
+		panic("touka: tenancy resolver must not be nil")
+	}
+	return func(c *Context) {
+		tenant, err := opts.Resolver.ResolveTenant(c)
+		if err == nil && tenant != nil && opts.Lookup != nil {
+			tenant, err = opts.Lookup(c.Context(), tenant.ID)
+		}
+		switch {
+		case errors.Is(err, ErrTenantNotFound) || (err == nil && tenant == nil && !opts.Optional):
+			c.ErrorUseHandle(http.StatusNotFound, ErrTenantNotFound)
+			return
+		case err != nil:
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusInternalServerError, fmt.Errorf("failed to resolve tenant: %w", err))
+			return
+		}
+
+		if tenant != nil {
+			c.Set(tenantKey, tenant)
+			if opts.ScopeLogger {
+				c.SetLogger(&prefixLogger{Logger: c.GetLogger(), prefix: "[tenant=" + strings.ReplaceAll(tenant.ID, "%", "%%") + "] "})
+			}
+		}
+		c.Next()
+	}
+}
+
+// Tenant 返回 Tenancy 中间件解析出的租户, 不存在时返回 nil
+func (c *Context) Tenant() *Tenant {
+	if v, ok := c.Get(tenantKey); ok {
+		tenant, _ := v.(*Tenant)
+		return tenant
+	}
+	return nil
+}
+
+// TenantScopedKey 为按请求计算键的函数 (如限流、计量的键函数) 加上租户前缀,
+// 使不同租户之间互不影响
+func TenantScopedKey(key func(c *Context) string) func(c *Context) string {
+	return func(c *Context) string {
+		k := key(c)
+		if tenant := c.Tenant(); tenant != nil {
+			return tenant.ID + ":" + k
+		}
+		return k
+	}
+}
+
+// prefixLogger 为每条日志加上固定前缀
+type prefixLogger struct {
+	Logger
+	prefix string
+}
+
+func (l *prefixLogger) Debugf(format string, args ...any) { l.Logger.Debugf(l.prefix+format, args...) }
+func (l *prefixLogger) Infof(format string, args ...any)  { l.Logger.Infof(l.prefix+format, args...) }
+func (l *prefixLogger) Warnf(format string, args ...any)  { l.Logger.Warnf(l.prefix+format, args...) }
+func (l *prefixLogger) Errorf(format string, args ...any) { l.Logger.Errorf(l.prefix+format, args...) }
+func (l *prefixLogger) Fatalf(format string, args ...any) { l.Logger.Fatalf(l.prefix+format, args...) }
+func (l *prefixLogger) Panicf(format string, args ...any) { l.Logger.Panicf(l.prefix+format, args...) }
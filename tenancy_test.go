@@ -0,0 +1,93 @@
+package touka
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testRecordLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testRecordLogger) record(format string, args ...any) {
+	l.mu.Lock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+}
+
+func (l *testRecordLogger) Debugf(format string, args ...any) { l.record(format, args...) }
+func (l *testRecordLogger) Infof(format string, args ...any)  { l.record(format, args...) }
+func (l *testRecordLogger) Warnf(format string, args ...any)  { l.record(format, args...) }
+func (l *testRecordLogger) Errorf(format string, args ...any) { l.record(format, args...) }
+func (l *testRecordLogger) Fatalf(format string, args ...any) { l.record(format, args...) }
+func (l *testRecordLogger) Panicf(format string, args ...any) { l.record(format, args...) }
+
+func TestTenancyResolvers(t *testing.T) {
+	logger := &testRecordLogger{}
+	engine := New()
+	engine.SetLogger(logger)
+	engine.Use(Tenancy(TenancyOptions{
+		Resolver:    ChainTenantResolvers(TenantFromHeader("X-Tenant-ID"), TenantFromSubdomain("example.com")),
+		ScopeLogger: true,
+	}))
+	engine.GET("/whoami", func(c *Context) {
+		c.Infof("hello")
+		c.String(http.StatusOK, "%s", c.Tenant().ID)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Host = "acme.example.com:8080"
+	rr := httptest.NewRecorder()
+	engine.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.String() != "acme" {
+		t.Fatalf("expected subdomain tenant, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodGet, "/whoami", nil, http.Header{"X-Tenant-Id": []string{"globex"}})
+	if rr.Body.String() != "globex" {
+		t.Fatalf("expected header tenant, got %q", rr.Body.String())
+	}
+
+	rr = PerformRequest(engine, http.MethodGet, "/whoami", nil, nil)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without tenant, got %d", rr.Code)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.lines) < 2 || !strings.HasPrefix(logger.lines[0], "[tenant=acme] hello") {
+		t.Fatalf("expected tenant-scoped log lines, got %v", logger.lines)
+	}
+}
+
+func TestTenancyLookupAndParam(t *testing.T) {
+	engine := New()
+	tenancy := Tenancy(TenancyOptions{
+		Resolver: TenantFromParam("tenant"),
+		Lookup: func(_ context.Context, id string) (*Tenant, error) {
+			if id != "acme" {
+				return nil, ErrTenantNotFound
+			}
+			return &Tenant{ID: id, Name: "ACME Corp"}, nil
+		},
+	})
+	engine.GET("/t/:tenant/info", tenancy, func(c *Context) {
+		key := TenantScopedKey(func(c *Context) string { return "ratelimit" })(c)
+		c.String(http.StatusOK, "%s %s", c.Tenant().Name, key)
+	})
+
+	rr := PerformRequest(engine, http.MethodGet, "/t/acme/info", nil, nil)
+	if rr.Body.String() != "ACME Corp acme:ratelimit" {
+		t.Fatalf("unexpected body %q", rr.Body.String())
+	}
+	rr = PerformRequest(engine, http.MethodGet, "/t/unknown/info", nil, nil)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown tenant, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,47 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+)
+
+// TLS 返回当前连接的 TLS 状态, 明文连接 (包括由代理终止 TLS 的连接) 返回 nil
+func (c *Context) TLS() *tls.ConnectionState {
+	return c.Request.TLS
+}
+
+// IsTLS 报告客户端是否通过 TLS 访问.
+// 直接的 TLS 连接总是返回 true; 请求来自可信代理 (参见 SetTrustedProxies) 时
+// 还会采信 Forwarded 的 proto=https 或 X-Forwarded-Proto: https
+func (c *Context) IsTLS() bool {
+	return c.Request.TLS != nil || c.ForwardedProto() == "https"
+}
+
+// TLSServerName 返回客户端通过 SNI 请求的服务器名称, 非 TLS 连接或客户端未发送 SNI 时返回空字符串
+func (c *Context) TLSServerName() string {
+	if c.Request.TLS == nil {
+		return ""
+	}
+	return c.Request.TLS.ServerName
+}
+
+// ClientCert 返回客户端在 mTLS 握手中提供的叶子证书, 未提供时返回 nil.
+// 由代理终止 TLS 时证书不会到达本服务, 需要代理以头部等方式转发
+func (c *Context) ClientCert() *x509.Certificate {
+	if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return c.Request.TLS.PeerCertificates[0]
+}
+
+// ClientCertCN 返回客户端证书的 Subject Common Name, 未提供证书时返回空字符串
+func (c *Context) ClientCertCN() string {
+	if cert := c.ClientCert(); cert != nil {
+		return cert.Subject.CommonName
+	}
+	return ""
+}
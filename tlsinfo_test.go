@@ -0,0 +1,61 @@
+package touka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextTLSHelpers(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	req.TLS = &tls.ConnectionState{
+		ServerName:       "api.example.com",
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "client-1"}}},
+	}
+	c, _ := CreateTestContextWithRequest(nil, req)
+	if c.TLS() == nil || !c.IsTLS() || c.TLSServerName() != "api.example.com" || c.ClientCertCN() != "client-1" {
+		t.Fatalf("unexpected TLS helpers: tls=%v server=%q cn=%q", c.TLS(), c.TLSServerName(), c.ClientCertCN())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	c, _ = CreateTestContextWithRequest(nil, req)
+	if c.TLS() != nil || c.IsTLS() || c.TLSServerName() != "" || c.ClientCert() != nil || c.ClientCertCN() != "" {
+		t.Fatal("plain request should report no TLS information")
+	}
+}
+
+func TestIsTLSBehindTrustedProxy(t *testing.T) {
+	engine := New()
+	engine.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "%t %s", c.IsTLS(), c.ClientIP())
+	})
+	serve := func(remoteAddr string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-For", "198.51.100.9")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// 未配置可信代理: 不采信协议头部, IP 头部保持原有行为
+	if got := serve("10.0.0.2:1234"); got != "false 198.51.100.9" {
+		t.Fatalf("without trusted proxies: %q", got)
+	}
+	if err := engine.SetTrustedProxies([]string{"10.0.0.0/8", "::1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := serve("10.0.0.2:1234"); got != "true 198.51.100.9" {
+		t.Fatalf("from trusted proxy: %q", got)
+	}
+	if got := serve("203.0.113.5:1234"); got != "false 203.0.113.5" {
+		t.Fatalf("from untrusted peer: %q", got)
+	}
+	if err := engine.SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Fatal("expected error for invalid trusted proxy")
+	}
+}
@@ -38,13 +38,27 @@ type Router interface {
 	ANY(relativePath string, handlers ...HandlerFunc) // 注册所有HTTP方法
 }
 
+// MetaRouter 是支持附加路由元数据的 Router, *Engine 与 Group 返回的路由器都实现了它.
+// 自定义的 Router 实现不必实现该接口, 需要时通过类型断言使用:
+//
+//	api := r.Group("/api").(touka.MetaRouter).WithMeta("auth", "required")
+type MetaRouter interface {
+	Router
+	WithMeta(key string, value any) MetaRouter // 返回附加了元数据的路由器,之后注册的路由携带该元数据
+}
+
 // RouteInfo 包含一个已注册路由的详细信息。
 // 由 Router.GetRouters() 方法返回。
 type RouteInfo struct {
-	Method  string // HTTP 方法 (GET, POST, PUT, DELETE 等)
-	Path    string // 路由路径
-	Handler string // 处理函数名称
-	Group   string // 路由分组
+	Method  string    // HTTP 方法 (GET, POST, PUT, DELETE 等)
+	Path    string    // 路由路径
+	Handler string    // 处理函数名称
+	Group   string    // 路由分组
+	Meta    RouteMeta // 路由元数据
+
+	Source     RouteSource     // 注册位置 (文件与行号)
+	Wildcards  []RouteWildcard // 路径中的通配段
+	Middleware []string        // 处理器链中最终处理器之前的中间件名称 (包括全局中间件)
 }
 
 // 维护一个Methods列表
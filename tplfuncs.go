@@ -0,0 +1,241 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLocale 描述模板函数 date 使用的语言: 月份与星期的名称, 以及各个样式对应的布局.
+// 布局使用 time 包的写法, 其中的 January、Jan、Monday 与 Mon 会被替换为本语言的名称
+type DateLocale struct {
+	Months        [12]string
+	ShortMonths   [12]string
+	Weekdays      [7]string // 从星期日开始
+	ShortWeekdays [7]string
+	// Styles 为样式名 (例如 short、medium、long、full) 到布局的映射
+	Styles map[string]string
+}
+
+// dateLocales 为内置的日期语言
+var dateLocales = map[string]DateLocale{
+	"en": {
+		Months:        [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		ShortMonths:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		Weekdays:      [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		ShortWeekdays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		Styles: map[string]string{
+			"short":  "1/2/06",
+			"medium": "Jan 2, 2006",
+			"long":   "January 2, 2006 15:04",
+			"full":   "Monday, January 2, 2006 15:04:05 MST",
+		},
+	},
+	"zh": {
+		Months:        [12]string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"},
+		ShortMonths:   [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		Weekdays:      [7]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+		ShortWeekdays: [7]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"},
+		Styles: map[string]string{
+			"short":  "2006/1/2",
+			"medium": "2006年1月2日",
+			"long":   "2006年1月2日 15:04",
+			"full":   "2006年1月2日 Monday 15:04:05 MST",
+		},
+	},
+}
+
+// format 按布局格式化时间, 布局可以是样式名或 time 包的布局
+func (l DateLocale) format(t time.Time, layout string) string {
+	if style, ok := l.Styles[layout]; ok {
+		layout = style
+	}
+	var sb strings.Builder
+	for layout != "" {
+		// 依次尝试较长的名称, 避免 Jan 匹配 January 的前缀
+		i, token := len(layout), ""
+		for _, name := range []string{"January", "Monday", "Jan", "Mon"} {
+			if j := strings.Index(layout, name); j >= 0 && j < i {
+				i, token = j, name
+			}
+		}
+		sb.WriteString(t.Format(layout[:i]))
+		if token == "" {
+			break
+		}
+		switch token {
+		case "January":
+			sb.WriteString(l.Months[t.Month()-1])
+		case "Jan":
+			sb.WriteString(l.ShortMonths[t.Month()-1])
+		case "Monday":
+			sb.WriteString(l.Weekdays[t.Weekday()])
+		case "Mon":
+			sb.WriteString(l.ShortWeekdays[t.Weekday()])
+		}
+		layout = layout[i+len(token):]
+	}
+	return sb.String()
+}
+
+// TemplateFuncsOptions 配置 UseTemplateFuncs 注册的模板函数
+type TemplateFuncsOptions struct {
+	// Locale 为 date 未指定语言时使用的语言, 为空时为 en
+	Locale string
+	// Locales 为额外的日期语言, 键为语言标签 (例如 de 或 pt-BR), 同名时覆盖内置的 en 与 zh
+	Locales map[string]DateLocale
+	// Location 为 date 格式化前转换到的时区, 为 nil 时保留时间本身的时区
+	Location *time.Location
+}
+
+// UseTemplateFuncs 为 LoadHTMLGlob 解析的模板注册常用的函数库, 需在 LoadHTMLGlob 之前调用:
+//
+//	humanizeBytes 1536                 -> 1.5 KiB
+//	humanizeDuration .Elapsed          -> 2h 5m
+//	date .CreatedAt "long"             -> January 2, 2006 15:04
+//	date .CreatedAt "medium" "zh-CN"   -> 2006年1月2日
+//	date .CreatedAt "2006-01-02"       -> 任意 time 包布局
+//	urlFor "user" "id" .ID             -> /users/42, 参见 URLFor
+//	json .Data                         -> 可安全嵌入 <script> 的 JSON
+//
+// SetFuncMap 中的同名函数优先
+func (engine *Engine) UseTemplateFuncs(opts TemplateFuncsOptions) {
+	engine.tplFuncs = engine.templateFuncMap(opts)
+}
+
+func (engine *Engine) templateFuncMap(opts TemplateFuncsOptions) template.FuncMap {
+	locales := make(map[string]DateLocale, len(dateLocales)+len(opts.Locales))
+	for tag, l := range dateLocales {
+		locales[tag] = l
+	}
+	for tag, l := range opts.Locales {
+		locales[strings.ToLower(tag)] = l
+	}
+	defaultLocale := opts.Locale
+	if defaultLocale == "" {
+		defaultLocale = "en"
+	}
+
+	return template.FuncMap{
+		"humanizeBytes":    humanizeBytes,
+		"humanizeDuration": humanizeDuration,
+		"date": func(t time.Time, layout string, locale ...string) (string, error) {
+			tag := defaultLocale
+			if len(locale) > 0 && locale[0] != "" {
+				tag = locale[0]
+			}
+			l, ok := lookupDateLocale(locales, tag)
+			if !ok {
+				return "", fmt.Errorf("date: unknown locale %q", tag)
+			}
+			if opts.Location != nil {
+				t = t.In(opts.Location)
+			}
+			return l.format(t, layout), nil
+		},
+		"urlFor": engine.URLFor,
+		"json":   templateJSON,
+	}
+}
+
+// lookupDateLocale 按语言标签查找语言, 找不到时回退到主语言, 例如 zh-CN -> zh
+func lookupDateLocale(locales map[string]DateLocale, tag string) (DateLocale, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	for {
+		if l, ok := locales[tag]; ok {
+			return l, true
+		}
+		i := strings.LastIndexByte(tag, '-')
+		if i < 0 {
+			return DateLocale{}, false
+		}
+		tag = tag[:i]
+	}
+}
+
+// humanizeBytes 以二进制单位格式化字节数, 例如 1536 -> 1.5 KiB
+func humanizeBytes(n any) (string, error) {
+	var size float64
+	switch v := n.(type) {
+	case int:
+		size = float64(v)
+	case int64:
+		size = float64(v)
+	case uint64:
+		size = float64(v)
+	case int32:
+		size = float64(v)
+	case uint32:
+		size = float64(v)
+	case uint:
+		size = float64(v)
+	case float64:
+		size = v
+	default:
+		return "", fmt.Errorf("humanizeBytes: unsupported type %T", n)
+	}
+	sign := ""
+	if size < 0 {
+		sign, size = "-", -size
+	}
+	if size < 1024 {
+		return sign + strconv.FormatFloat(size, 'f', -1, 64) + " B", nil
+	}
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	exp := min(int(math.Log(size)/math.Log(1024)), len(units))
+	value := size / math.Pow(1024, float64(exp))
+	if value >= 1023.95 && exp < len(units) {
+		// 四舍五入后达到 1024 时进位, 避免输出 1024.0 KiB
+		value /= 1024
+		exp++
+	}
+	return sign + strconv.FormatFloat(value, 'f', 1, 64) + " " + units[exp-1], nil
+}
+
+// humanizeDuration 以最大的两个单位格式化时长, 例如 2h 5m、3m 20s、350ms
+func humanizeDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d < time.Second {
+		return sign + d.Round(time.Millisecond).String()
+	}
+	units := []struct {
+		d    time.Duration
+		name string
+	}{{24 * time.Hour, "d"}, {time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}}
+	var parts []string
+	for _, u := range units {
+		if d < u.d && len(parts) == 0 {
+			continue
+		}
+		n := d / u.d
+		d -= n * u.d
+		if n > 0 {
+			parts = append(parts, strconv.FormatInt(int64(n), 10)+u.name)
+		}
+		if len(parts) == 2 || (len(parts) == 1 && n == 0) {
+			break
+		}
+	}
+	return sign + strings.Join(parts, " ")
+}
+
+// templateJSON 将 v 编码为可以直接嵌入 <script> 的 JSON. encoding/json 会转义 <、>、& 与 U+2028/U+2029,
+// 内容不会提前结束 script 标签
+func templateJSON(v any) (template.JS, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(data), nil
+}
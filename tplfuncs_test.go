@@ -0,0 +1,112 @@
+package touka
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHumanizeBytes(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{int64(1048575), "1.0 MiB"},
+		{uint64(5) << 30, "5.0 GiB"},
+		{-2048, "-2.0 KiB"},
+	}
+	for _, tt := range tests {
+		if got, err := humanizeBytes(tt.in); err != nil || got != tt.want {
+			t.Errorf("humanizeBytes(%v) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := humanizeBytes("1"); err == nil {
+		t.Error("strings should be rejected")
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{350 * time.Millisecond, "350ms"},
+		{200 * time.Second, "3m 20s"},
+		{2*time.Hour + 5*time.Minute + 30*time.Second, "2h 5m"},
+		{2*time.Hour + 30*time.Second, "2h"},
+		{27 * time.Hour, "1d 3h"},
+		{-90 * time.Second, "-1m 30s"},
+	}
+	for _, tt := range tests {
+		if got := humanizeDuration(tt.in); got != tt.want {
+			t.Errorf("humanizeDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateFuncsDate(t *testing.T) {
+	engine := New()
+	funcs := engine.templateFuncMap(TemplateFuncsOptions{
+		Location: time.UTC,
+		Locales: map[string]DateLocale{"de": {
+			Months:   [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+			Weekdays: [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+			Styles:   map[string]string{"long": "Monday, 2. January 2006"},
+		}},
+	})
+	date := funcs["date"].(func(time.Time, string, ...string) (string, error))
+	ts := time.Date(2026, time.March, 2, 15, 4, 5, 0, time.FixedZone("CST", 8*3600))
+
+	tests := []struct {
+		layout string
+		locale []string
+		want   string
+	}{
+		{"medium", nil, "Mar 2, 2026"},
+		{"full", nil, "Monday, March 2, 2026 07:04:05 UTC"},
+		{"medium", []string{"zh-CN"}, "2026年3月2日"},
+		{"full", []string{"zh_Hans_CN"}, "2026年3月2日 星期一 07:04:05 UTC"},
+		{"Mon Jan 2", []string{"zh"}, "周一 3月 2"},
+		{"long", []string{"de-AT"}, "Montag, 2. März 2026"},
+		{"2006-01-02", nil, "2026-03-02"},
+	}
+	for _, tt := range tests {
+		if got, err := date(ts, tt.layout, tt.locale...); err != nil || got != tt.want {
+			t.Errorf("date(%q, %v) = %q, %v; want %q", tt.layout, tt.locale, got, err, tt.want)
+		}
+	}
+	if _, err := date(ts, "short", "fr"); err == nil {
+		t.Error("unknown locale should fail")
+	}
+}
+
+func TestUseTemplateFuncs(t *testing.T) {
+	dir := t.TempDir()
+	page := `<a href="{{urlFor "user" "id" .ID}}">{{humanizeBytes .Size}}</a><script>var data = {{json .Data}};</script>`
+	if err := os.WriteFile(filepath.Join(dir, "page.html"), []byte(page), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := New()
+	engine.UseTemplateFuncs(TemplateFuncsOptions{})
+	engine.LoadHTMLGlob(filepath.Join(dir, "*.html"))
+	engine.WithMeta(RouteName("user")).GET("/users/:id", func(c *Context) {
+		c.HTML(http.StatusOK, "page.html", H{"ID": c.Param("id"), "Size": 2048, "Data": H{"name": "</script><b>"}})
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/users/7", nil, nil)
+	body := w.Body.String()
+	if !strings.Contains(body, `<a href="/users/7">2.0 KiB</a>`) {
+		t.Fatalf("unexpected body %q", body)
+	}
+	if strings.Contains(body, "</script><b>") || !strings.Contains(body, `{"name":"\u003c/script\u003e\u003cb\u003e"}`) {
+		t.Fatalf("JSON not escaped for script context: %q", body)
+	}
+}
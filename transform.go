@@ -0,0 +1,161 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTransformNotSupported 可由 FileTransformer 返回, 表示不处理该文件或参数组合, 此时按原样提供文件
+var ErrTransformNotSupported = errors.New("transform: not supported")
+
+// FileTransformer 对静态文件做按需转换, 例如生成缩略图或转换图片格式.
+// touka 不包含任何编解码器, 转换逻辑由使用者提供
+type FileTransformer interface {
+	// Transform 读取 src (名为 name 的原始文件) 并按 params 生成转换结果与其 Content-Type
+	Transform(ctx context.Context, name string, src io.Reader, params url.Values) (data []byte, contentType string, err error)
+}
+
+// FileTransformerFunc 是 FileTransformer 的函数适配器
+type FileTransformerFunc func(ctx context.Context, name string, src io.Reader, params url.Values) ([]byte, string, error)
+
+func (f FileTransformerFunc) Transform(ctx context.Context, name string, src io.Reader, params url.Values) ([]byte, string, error) {
+	return f(ctx, name, src, params)
+}
+
+// TransformOptions 配置 StaticTransform
+type TransformOptions struct {
+	// Transformer 执行转换, 必填
+	Transformer FileTransformer
+	// Params 为触发转换的查询参数, 例如 []string{"w", "h", "format"}; 请求不含其中任何一个时按原样提供文件.
+	// 只有这些参数会传给 Transformer 并参与缓存键, 其他参数被忽略
+	Params []string
+	// Extensions 限制参与转换的文件扩展名 (含 ".", 不区分大小写), 为空时不限制
+	Extensions []string
+	// MaxSourceSize 为原始文件的大小上限, 超出时按原样提供; <= 0 表示不限制
+	MaxSourceSize int64
+	// CacheTTL 为转换结果在引擎 CacheStore 中的缓存时间, 默认 1 小时
+	CacheTTL time.Duration
+}
+
+// StaticTransform 返回为静态文件提供按需转换的中间件, 与 StaticDir/StaticFS 配合使用:
+//
+//	img := r.Group("/img", touka.StaticTransform(http.Dir("./images"), touka.TransformOptions{
+//	    Transformer: thumbnailer,
+//	    Params:      []string{"w", "format"},
+//	}))
+//	img.StaticDir("/", "./images")
+//
+// 请求 /img/cat.jpg?w=200&format=webp 时, 中间件从 fs 打开 cat.jpg 交给 Transformer, 结果按
+// 文件路径、修改时间与参数缓存在引擎的 CacheStore 中 (并发的相同请求只转换一次), 响应支持 Range 与条件请求.
+// 文件路径取自 *filepath 参数, 不存在的文件与不需要转换的请求交给后续的静态文件处理器
+func StaticTransform(fs http.FileSystem, opts TransformOptions) HandlerFunc {
+	if fs == nil || opts.Transformer == nil || len(opts.Params) == 0 {
+		panic("touka: static transform requires a file system, a transformer and trigger params")
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = time.Hour
+	}
+	return func(c *Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+		params := transformParams(c.Request.URL.Query(), opts.Params)
+		if params == nil {
+			c.Next()
+			return
+		}
+		name := c.Param("filepath")
+		if name == "" {
+			name = c.Request.URL.Path
+		}
+		name = path.Clean("/" + name)
+		if !transformExtensionAllowed(name, opts.Extensions) {
+			c.Next()
+			return
+		}
+
+		f, err := fs.Open(name)
+		if err != nil {
+			c.Next()
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil || info.IsDir() || (opts.MaxSourceSize > 0 && info.Size() > opts.MaxSourceSize) {
+			c.Next()
+			return
+		}
+
+		key := "transform:" + name + "?" + params.Encode() + "@" +
+			strconv.FormatInt(info.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(info.Size(), 36)
+		cached, err := c.Cache().Fetch(c.Context(), key, opts.CacheTTL, func() (string, error) {
+			data, contentType, err := opts.Transformer.Transform(c.Context(), name, f, params)
+			if err != nil {
+				return "", err
+			}
+			// 缓存值为 Content-Type 与内容, 以换行分隔
+			return contentType + "\n" + string(data), nil
+		})
+		if errors.Is(err, ErrTransformNotSupported) {
+			c.Next()
+			return
+		}
+		if err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+
+		contentType, data, _ := strings.Cut(cached, "\n")
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte(data))
+		if contentType != "" {
+			c.SetHeader("Content-Type", contentType)
+		}
+		c.SetHeader("ETag", `"`+strconv.FormatUint(h.Sum64(), 36)+`"`)
+		http.ServeContent(c.Writer, c.Request, path.Base(name), info.ModTime(), bytes.NewReader([]byte(data)))
+		c.Abort()
+	}
+}
+
+// transformParams 取出触发转换的参数, 不含任何触发参数时返回 nil
+func transformParams(query url.Values, names []string) url.Values {
+	var params url.Values
+	for _, name := range names {
+		if v, ok := query[name]; ok {
+			if params == nil {
+				params = url.Values{}
+			}
+			params[name] = v
+		}
+	}
+	return params
+}
+
+func transformExtensionAllowed(name string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	ext := path.Ext(name)
+	for _, allowed := range extensions {
+		if strings.EqualFold(ext, allowed) {
+			return true
+		}
+	}
+	return false
+}
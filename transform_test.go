@@ -0,0 +1,58 @@
+package touka
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticTransform(t *testing.T) {
+	fsys := http.FS(fstest.MapFS{
+		"cat.txt": {Data: []byte("meow")},
+	})
+	var calls atomic.Int32
+	transformer := FileTransformerFunc(func(ctx context.Context, name string, src io.Reader, params url.Values) ([]byte, string, error) {
+		calls.Add(1)
+		if params.Get("format") == "raw" {
+			return nil, "", ErrTransformNotSupported
+		}
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return nil, "", err
+		}
+		return []byte(strings.ToUpper(string(data)) + ":" + params.Get("w")), "text/x-upper", nil
+	})
+
+	engine := New()
+	engine.Use(StaticTransform(fsys, TransformOptions{Transformer: transformer, Params: []string{"w", "format"}}))
+	engine.StaticFS("/files", fsys)
+
+	for range 2 {
+		w := PerformRequest(engine, http.MethodGet, "/files/cat.txt?w=200&ignored=1", nil, nil)
+		if w.Code != http.StatusOK || w.Body.String() != "MEOW:200" || w.Header().Get("Content-Type") != "text/x-upper" {
+			t.Fatalf("unexpected transformed response %d %q %v", w.Code, w.Body.String(), w.Header())
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected the result to be cached, transformer called %d times", calls.Load())
+	}
+
+	w := PerformRequest(engine, http.MethodGet, "/files/cat.txt?w=200", nil, http.Header{"Range": {"bytes=0-3"}})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "MEOW" {
+		t.Fatalf("expected range support, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/files/cat.txt", nil, nil)
+	if w.Body.String() != "meow" {
+		t.Fatalf("expected the original file without trigger params, got %q", w.Body.String())
+	}
+	w = PerformRequest(engine, http.MethodGet, "/files/cat.txt?format=raw", nil, nil)
+	if w.Body.String() != "meow" {
+		t.Fatalf("expected unsupported transforms to fall back to the original file, got %q", w.Body.String())
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // Used as a workaround since we can't compare functions or their addresses
@@ -947,6 +949,38 @@ func TestFindCaseInsensitivePathWithStaticAndParamRoutesDoesNotPanicOnMiss(t *te
 	}
 }
 
+func TestTreeFindCaseInsensitivePathNormalized(t *testing.T) {
+	tree := &node{}
+	routes := [...]string{
+		"/café/:name",
+		"/u/äpfêl/",
+		"/v/Öpfêl",
+	}
+	for _, route := range routes {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	// decomposed (NFD) forms only match after NFC normalization
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"/CAFE\u0301/x", "/café/x"},
+		{"/u/A\u0308PFE\u0302L/", "/u/äpfêl/"},
+		{"/u/A\u0308PFE\u0302L", "/u/äpfêl/"},
+		{"/v/o\u0308pfe\u0302l", "/v/Öpfêl"},
+	}
+	for _, test := range tests {
+		if out, found := tree.findCaseInsensitivePath(test.in, true); found {
+			t.Errorf("%q: decomposed path should not match, got %q", test.in, out)
+		}
+		out, found := tree.findCaseInsensitivePath(norm.NFC.String(test.in), true)
+		if !found || string(out) != test.out {
+			t.Errorf("%q: got %q, %t; want %q", test.in, out, found, test.out)
+		}
+	}
+}
+
 func TestTreeInvalidParamsType(t *testing.T) {
 	tree := &node{}
 	// add a child with wildcard
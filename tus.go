@@ -0,0 +1,518 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// TusVersion 是支持的 tus 协议版本
+const TusVersion = "1.0.0"
+
+// tusContentType 是 PATCH 请求必须使用的 Content-Type
+const tusContentType = "application/offset+octet-stream"
+
+var (
+	ErrTusUploadNotFound  = errors.New("tus: upload not found")
+	ErrTusOffsetMismatch  = errors.New("tus: upload offset does not match")
+	ErrTusUploadExpired   = errors.New("tus: upload expired")
+	ErrTusInvalidLength   = errors.New("tus: invalid Upload-Length")
+	ErrTusInvalidMetadata = errors.New("tus: invalid Upload-Metadata")
+)
+
+// TusUpload 描述一个可续传上传的状态
+type TusUpload struct {
+	ID        string            `json:"id"`
+	Size      int64             `json:"size"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at,omitzero"`
+}
+
+// Complete 报告上传是否已经接收了全部内容
+func (u TusUpload) Complete() bool {
+	return u.Offset >= u.Size
+}
+
+// TusStore 是 tus 上传的存储后端. 同一上传的 WriteChunk 调用由处理器通过引擎的 Locker 串行化
+type TusStore interface {
+	// Create 创建一个新上传, upload.ID 已由处理器生成
+	Create(ctx context.Context, upload TusUpload) error
+	// Get 返回上传的当前状态, 不存在时返回 ErrTusUploadNotFound
+	Get(ctx context.Context, id string) (TusUpload, error)
+	// WriteChunk 从 offset 处追加 r 的内容并返回写入的字节数.
+	// 读取出错时也应保留已写入的部分, 以便客户端从新的偏移量续传
+	WriteChunk(ctx context.Context, id string, offset int64, r io.Reader) (int64, error)
+	// SetExpiration 更新上传的过期时间, 处理器在每次写入成功的 PATCH 后调用以顺延仍在进行的上传
+	SetExpiration(ctx context.Context, id string, expiresAt time.Time) error
+	// Terminate 删除上传及其内容
+	Terminate(ctx context.Context, id string) error
+}
+
+// TusOptions 配置 tus 上传处理器
+type TusOptions struct {
+	// Store 为存储后端, 必填
+	Store TusStore
+	// MaxSize 限制单个上传的大小, <= 0 表示不限制
+	MaxSize int64
+	// Expiration 为未完成上传的有效期, 每次成功的 PATCH 都会顺延, 默认 24 小时
+	Expiration time.Duration
+	// OnComplete 在上传接收完最后一个分片后调用, 可在其中将文件移入最终位置
+	OnComplete func(c *Context, upload TusUpload)
+	// Middleware 为所有 tus 路由添加的中间件, 例如鉴权
+	Middleware []HandlerFunc
+}
+
+// MountTus 在 relativePath 下注册兼容 tus.io 1.0.0 的可续传上传处理器,
+// 支持 creation、expiration 与 termination 扩展:
+//
+//	OPTIONS /files       -> 协议能力
+//	POST    /files       -> 创建上传, Location 指向 /files/<id>
+//	HEAD    /files/:id   -> 查询当前偏移量
+//	PATCH   /files/:id   -> 从 Upload-Offset 处追加内容
+//	DELETE  /files/:id   -> 终止上传
+func (engine *Engine) MountTus(relativePath string, opts TusOptions) {
+	mountTus(engine, relativePath, opts)
+}
+
+// MountTus 在路由组下注册 tus 上传处理器, 参见 Engine.MountTus
+func (group *RouterGroup) MountTus(relativePath string, opts TusOptions) {
+	mountTus(group, relativePath, opts)
+}
+
+func mountTus(router Router, relativePath string, opts TusOptions) {
+	if opts.Store == nil {
+		panic("touka: tus store must not be nil")
+	}
+	if opts.Expiration <= 0 {
+		opts.Expiration = 24 * time.Hour
+	}
+
+	collection := "/" + strings.Trim(relativePath, "/")
+	member := strings.TrimSuffix(collection, "/") + "/:id"
+	chain := func(h HandlerFunc) []HandlerFunc {
+		handlers := make([]HandlerFunc, 0, len(opts.Middleware)+2)
+		handlers = append(handlers, opts.Middleware...)
+		return append(handlers, tusResumable, h)
+	}
+
+	router.OPTIONS(collection, append(append([]HandlerFunc{}, opts.Middleware...), func(c *Context) {
+		c.SetHeader("Tus-Resumable", TusVersion)
+		c.SetHeader("Tus-Version", TusVersion)
+		c.SetHeader("Tus-Extension", "creation,expiration,termination")
+		if opts.MaxSize > 0 {
+			c.SetHeader("Tus-Max-Size", strconv.FormatInt(opts.MaxSize, 10))
+		}
+		c.Status(http.StatusNoContent)
+	})...)
+	router.POST(collection, chain(func(c *Context) { tusCreate(c, opts) })...)
+	router.HEAD(member, chain(func(c *Context) { tusHead(c, opts) })...)
+	router.PATCH(member, chain(func(c *Context) { tusPatch(c, opts) })...)
+	router.DELETE(member, chain(func(c *Context) {
+		// 与 PATCH 使用同一把锁, 避免进行中的 PATCH 在终止后重新写入 .info
+		unlock, err := c.Lock("tus:"+c.Param("id"), opts.Expiration)
+		if err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(http.StatusServiceUnavailable, err)
+			return
+		}
+		defer unlock()
+		if err := opts.Store.Terminate(c.Context(), c.Param("id")); err != nil {
+			tusError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})...)
+}
+
+// tusResumable 校验 Tus-Resumable 请求头并在响应中声明协议版本
+func tusResumable(c *Context) {
+	c.SetHeader("Tus-Resumable", TusVersion)
+	if c.Request.Header.Get("Tus-Resumable") != TusVersion {
+		c.SetHeader("Tus-Version", TusVersion)
+		err := fmt.Errorf("tus: unsupported Tus-Resumable %q", c.Request.Header.Get("Tus-Resumable"))
+		c.AddClientError(err)
+		c.ErrorUseHandle(http.StatusPreconditionFailed, err)
+		return
+	}
+	c.Next()
+}
+
+func tusCreate(c *Context, opts TusOptions) {
+	size, err := strconv.ParseInt(c.Request.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		c.AddClientError(ErrTusInvalidLength)
+		c.ErrorUseHandle(http.StatusBadRequest, ErrTusInvalidLength)
+		return
+	}
+	if opts.MaxSize > 0 && size > opts.MaxSize {
+		c.AddClientError(ErrBodyTooLarge)
+		c.ErrorUseHandle(http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+		return
+	}
+	metadata, err := parseTusMetadata(c.Request.Header.Get("Upload-Metadata"))
+	if err != nil {
+		c.AddClientError(err)
+		c.ErrorUseHandle(http.StatusBadRequest, err)
+		return
+	}
+
+	upload := TusUpload{
+		ID:        randomHex(16),
+		Size:      size,
+		Metadata:  metadata,
+		ExpiresAt: time.Now().Add(opts.Expiration),
+	}
+	if err := opts.Store.Create(c.Context(), upload); err != nil {
+		tusError(c, err)
+		return
+	}
+	c.SetHeader("Location", path.Join(c.Request.URL.Path, upload.ID))
+	c.SetHeader("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+
+	if size == 0 && opts.OnComplete != nil {
+		opts.OnComplete(c, upload)
+	}
+}
+
+func tusHead(c *Context, opts TusOptions) {
+	upload, err := tusLookup(c, opts.Store)
+	if err != nil {
+		tusError(c, err)
+		return
+	}
+	c.SetHeader("Cache-Control", "no-store")
+	c.SetHeader("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.SetHeader("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	if len(upload.Metadata) > 0 {
+		c.SetHeader("Upload-Metadata", formatTusMetadata(upload.Metadata))
+	}
+	if !upload.Complete() && !upload.ExpiresAt.IsZero() {
+		c.SetHeader("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+	c.Status(http.StatusOK)
+}
+
+func tusPatch(c *Context, opts TusOptions) {
+	if ct := c.Request.Header.Get("Content-Type"); ct != tusContentType {
+		err := fmt.Errorf("tus: PATCH requires Content-Type %s", tusContentType)
+		c.AddClientError(err)
+		c.ErrorUseHandle(http.StatusUnsupportedMediaType, err)
+		return
+	}
+	offset, err := strconv.ParseInt(c.Request.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		err = errors.New("tus: invalid Upload-Offset")
+		c.AddClientError(err)
+		c.ErrorUseHandle(http.StatusBadRequest, err)
+		return
+	}
+
+	id := c.Param("id")
+	unlock, err := c.Lock("tus:"+id, opts.Expiration)
+	if err != nil {
+		c.AddError(err)
+		c.ErrorUseHandle(http.StatusServiceUnavailable, err)
+		return
+	}
+	defer unlock()
+
+	upload, err := tusLookup(c, opts.Store)
+	if err != nil {
+		tusError(c, err)
+		return
+	}
+	if offset != upload.Offset {
+		tusError(c, ErrTusOffsetMismatch)
+		return
+	}
+	remaining := upload.Size - upload.Offset
+	if c.Request.ContentLength > remaining {
+		c.AddClientError(ErrBodyTooLarge)
+		c.ErrorUseHandle(http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+		return
+	}
+
+	var written int64
+	if body := c.prepareRequestBody(); body != nil {
+		// 多出的内容不会写入, 客户端据 Upload-Offset 判断
+		written, err = opts.Store.WriteChunk(c.Context(), id, offset, io.LimitReader(body, remaining))
+	}
+	upload.Offset += written
+	if err != nil {
+		// 已写入的部分保留, 客户端可通过 HEAD 获取新的偏移量后续传
+		c.AddError(fmt.Errorf("tus: write chunk: %w", err))
+		if written == 0 {
+			tusError(c, err)
+			return
+		}
+	}
+	if written > 0 && !upload.Complete() {
+		// 仍有进展的上传顺延有效期, 避免较慢的上传被当作过期清理
+		expiresAt := time.Now().Add(opts.Expiration)
+		if err := opts.Store.SetExpiration(c.Context(), id, expiresAt); err != nil {
+			c.AddError(fmt.Errorf("tus: extend expiration: %w", err))
+		} else {
+			upload.ExpiresAt = expiresAt
+		}
+	}
+
+	c.SetHeader("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	if !upload.Complete() && !upload.ExpiresAt.IsZero() {
+		c.SetHeader("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+	c.Status(http.StatusNoContent)
+
+	if upload.Complete() && opts.OnComplete != nil {
+		opts.OnComplete(c, upload)
+	}
+}
+
+// tusLookup 返回路径参数指定的上传, 已过期的未完成上传会被终止
+func tusLookup(c *Context, store TusStore) (TusUpload, error) {
+	upload, err := store.Get(c.Context(), c.Param("id"))
+	if err != nil {
+		return upload, err
+	}
+	if !upload.Complete() && !upload.ExpiresAt.IsZero() && time.Now().After(upload.ExpiresAt) {
+		_ = store.Terminate(c.Context(), upload.ID)
+		return upload, ErrTusUploadExpired
+	}
+	return upload, nil
+}
+
+// tusError 将存储错误映射为 tus 规定的状态码
+func tusError(c *Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrTusUploadNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrTusUploadExpired):
+		code = http.StatusGone
+	case errors.Is(err, ErrTusOffsetMismatch):
+		code = http.StatusConflict
+	case errors.Is(err, ErrBodyTooLarge):
+		code = http.StatusRequestEntityTooLarge
+	}
+	c.AddError(err)
+	c.ErrorUseHandle(code, err)
+}
+
+// parseTusMetadata 解析 Upload-Metadata: "filename d29ybGQ=,is_confidential"
+func parseTusMetadata(v string) (map[string]string, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	metadata := make(map[string]string)
+	for pair := range strings.SplitSeq(v, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, ErrTusInvalidMetadata
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, ErrTusInvalidMetadata
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
+
+func formatTusMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k
+		if metadata[k] != "" {
+			pairs[i] += " " + base64.StdEncoding.EncodeToString([]byte(metadata[k]))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// FileTusStore 将上传保存在本地目录中: <id> 为内容, <id>.info 为 JSON 格式的状态
+type FileTusStore struct {
+	dir string
+	mu  sync.Mutex // 保护 .info 文件的读写
+}
+
+// NewFileTusStore 创建以 dir 为存储目录的 FileTusStore, 目录不存在时会被创建
+func NewFileTusStore(dir string) (*FileTusStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileTusStore{dir: dir}, nil
+}
+
+// Path 返回上传内容的文件路径, 可在 OnComplete 中用于移动或读取文件
+func (s *FileTusStore) Path(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+func (s *FileTusStore) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".info")
+}
+
+// validID 拒绝可能逃出存储目录的 ID
+func (s *FileTusStore) validID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\.`)
+}
+
+func (s *FileTusStore) Create(_ context.Context, upload TusUpload) error {
+	if !s.validID(upload.ID) {
+		return ErrTusUploadNotFound
+	}
+	f, err := os.OpenFile(s.Path(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeInfo(upload)
+}
+
+func (s *FileTusStore) Get(_ context.Context, id string) (TusUpload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readInfo(id)
+}
+
+func (s *FileTusStore) WriteChunk(_ context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	s.mu.Lock()
+	upload, err := s.readInfo(id)
+	s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if offset != upload.Offset {
+		return 0, ErrTusOffsetMismatch
+	}
+
+	f, err := os.OpenFile(s.Path(id), os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	// 截断上次中断时可能残留的、未记录在 .info 中的内容
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return 0, err
+	}
+	n, copyErr := io.Copy(f, r)
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload.Offset += n
+	if err := s.writeInfo(upload); err != nil {
+		return 0, err
+	}
+	return n, copyErr
+}
+
+func (s *FileTusStore) SetExpiration(_ context.Context, id string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, err := s.readInfo(id)
+	if err != nil {
+		return err
+	}
+	upload.ExpiresAt = expiresAt
+	return s.writeInfo(upload)
+}
+
+func (s *FileTusStore) Terminate(_ context.Context, id string) error {
+	if !s.validID(id) {
+		return ErrTusUploadNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrTusUploadNotFound
+	}
+	if rmErr := os.Remove(s.Path(id)); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// RemoveExpired 删除在 now 之前过期的未完成上传, 可由定时任务调用
+func (s *FileTusStore) RemoveExpired(ctx context.Context, now time.Time) error {
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*.info"))
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		id := strings.TrimSuffix(filepath.Base(m), ".info")
+		upload, err := s.Get(ctx, id)
+		if err != nil {
+			continue
+		}
+		if !upload.Complete() && !upload.ExpiresAt.IsZero() && now.After(upload.ExpiresAt) {
+			if err := s.Terminate(ctx, id); err != nil && !errors.Is(err, ErrTusUploadNotFound) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *FileTusStore) readInfo(id string) (TusUpload, error) {
+	var upload TusUpload
+	if !s.validID(id) {
+		return upload, ErrTusUploadNotFound
+	}
+	data, err := os.ReadFile(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return upload, ErrTusUploadNotFound
+	}
+	if err != nil {
+		return upload, err
+	}
+	err = json.Unmarshal(data, &upload)
+	return upload, err
+}
+
+// writeInfo 通过临时文件加重命名原子地更新状态
+func (s *FileTusStore) writeInfo(upload TusUpload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	tmp := s.infoPath(upload.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.infoPath(upload.ID))
+}
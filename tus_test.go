@@ -0,0 +1,199 @@
+package touka
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTusTestEngine(t *testing.T, opts TusOptions) (*Engine, *FileTusStore) {
+	t.Helper()
+	store, err := NewFileTusStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Store = store
+	engine := New()
+	engine.MountTus("/files", opts)
+	return engine, store
+}
+
+func TestTusUploadFlow(t *testing.T) {
+	var completed TusUpload
+	engine, store := newTusTestEngine(t, TusOptions{
+		MaxSize:    1 << 20,
+		OnComplete: func(c *Context, upload TusUpload) { completed = upload },
+	})
+
+	w := PerformRequest(engine, http.MethodOptions, "/files", nil, nil)
+	if w.Code != http.StatusNoContent || w.Header().Get("Tus-Version") != TusVersion || w.Header().Get("Tus-Max-Size") != "1048576" {
+		t.Fatalf("unexpected OPTIONS response %d %v", w.Code, w.Header())
+	}
+
+	w = PerformRequest(engine, http.MethodPost, "/files", nil, http.Header{
+		"Tus-Resumable":   {TusVersion},
+		"Upload-Length":   {"11"},
+		"Upload-Metadata": {"filename aGVsbG8udHh0,draft"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: unexpected %d %s", w.Code, w.Body.String())
+	}
+	location := w.Header().Get("Location")
+	if !strings.HasPrefix(location, "/files/") || w.Header().Get("Upload-Expires") == "" {
+		t.Fatalf("unexpected create headers %v", w.Header())
+	}
+
+	patch := func(offset, chunk string) *http.Response {
+		w := PerformRequest(engine, http.MethodPatch, location, strings.NewReader(chunk), http.Header{
+			"Tus-Resumable": {TusVersion},
+			"Upload-Offset": {offset},
+			"Content-Type":  {tusContentType},
+		})
+		return w.Result()
+	}
+	if resp := patch("0", "hello "); resp.StatusCode != http.StatusNoContent || resp.Header.Get("Upload-Offset") != "6" {
+		t.Fatalf("first patch: %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := patch("0", "again"); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("stale offset: expected 409, got %d", resp.StatusCode)
+	}
+
+	w = PerformRequest(engine, http.MethodHead, location, nil, http.Header{"Tus-Resumable": {TusVersion}})
+	if w.Code != http.StatusOK || w.Header().Get("Upload-Offset") != "6" || w.Header().Get("Upload-Length") != "11" ||
+		w.Header().Get("Upload-Metadata") != "draft,filename aGVsbG8udHh0" {
+		t.Fatalf("unexpected HEAD response %d %v", w.Code, w.Header())
+	}
+
+	if resp := patch("6", "world"); resp.StatusCode != http.StatusNoContent || resp.Header.Get("Upload-Offset") != "11" {
+		t.Fatalf("second patch: %d %v", resp.StatusCode, resp.Header)
+	}
+	if !completed.Complete() || completed.Metadata["filename"] != "hello.txt" {
+		t.Fatalf("unexpected completed upload %+v", completed)
+	}
+	data, err := os.ReadFile(store.Path(completed.ID))
+	if err != nil || string(data) != "hello world" {
+		t.Fatalf("unexpected content %q, %v", data, err)
+	}
+
+	w = PerformRequest(engine, http.MethodDelete, location, nil, http.Header{"Tus-Resumable": {TusVersion}})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: unexpected %d", w.Code)
+	}
+	w = PerformRequest(engine, http.MethodHead, location, nil, http.Header{"Tus-Resumable": {TusVersion}})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after termination, got %d", w.Code)
+	}
+}
+
+func TestTusRejectsInvalidRequests(t *testing.T) {
+	engine, _ := newTusTestEngine(t, TusOptions{MaxSize: 10})
+
+	w := PerformRequest(engine, http.MethodPost, "/files", nil, http.Header{"Upload-Length": {"5"}})
+	if w.Code != http.StatusPreconditionFailed || w.Header().Get("Tus-Version") != TusVersion {
+		t.Fatalf("missing Tus-Resumable: unexpected %d", w.Code)
+	}
+	w = PerformRequest(engine, http.MethodPost, "/files", nil, http.Header{"Tus-Resumable": {TusVersion}, "Upload-Length": {"11"}})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized upload: expected 413, got %d", w.Code)
+	}
+
+	w = PerformRequest(engine, http.MethodPost, "/files", nil, http.Header{"Tus-Resumable": {TusVersion}, "Upload-Length": {"5"}})
+	location := w.Header().Get("Location")
+	w = PerformRequest(engine, http.MethodPatch, location, strings.NewReader("abc"), http.Header{
+		"Tus-Resumable": {TusVersion},
+		"Upload-Offset": {"0"},
+		"Content-Type":  {"application/octet-stream"},
+	})
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("wrong content type: expected 415, got %d", w.Code)
+	}
+	w = PerformRequest(engine, http.MethodPatch, location, strings.NewReader("abcdefgh"), http.Header{
+		"Tus-Resumable": {TusVersion},
+		"Upload-Offset": {"0"},
+		"Content-Type":  {tusContentType},
+	})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("chunk past Upload-Length: expected 413, got %d", w.Code)
+	}
+}
+
+func TestTusExpiration(t *testing.T) {
+	engine, store := newTusTestEngine(t, TusOptions{Expiration: time.Millisecond})
+	w := PerformRequest(engine, http.MethodPost, "/files", nil, http.Header{"Tus-Resumable": {TusVersion}, "Upload-Length": {"5"}})
+	location := w.Header().Get("Location")
+	time.Sleep(5 * time.Millisecond)
+
+	w = PerformRequest(engine, http.MethodHead, location, nil, http.Header{"Tus-Resumable": {TusVersion}})
+	if w.Code != http.StatusGone {
+		t.Fatalf("expected 410 for expired upload, got %d", w.Code)
+	}
+	id := strings.TrimPrefix(location, "/files/")
+	if _, err := store.Get(context.Background(), id); err != ErrTusUploadNotFound {
+		t.Fatalf("expired upload should be removed, got %v", err)
+	}
+}
+
+func TestTusPatchExtendsExpiration(t *testing.T) {
+	engine, store := newTusTestEngine(t, TusOptions{Expiration: time.Hour})
+	w := PerformRequest(engine, http.MethodPost, "/files", nil, http.Header{"Tus-Resumable": {TusVersion}, "Upload-Length": {"10"}})
+	location := w.Header().Get("Location")
+	id := strings.TrimPrefix(location, "/files/")
+	// 模拟一个即将过期但仍在上传的文件
+	if err := store.SetExpiration(context.Background(), id, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	w = PerformRequest(engine, http.MethodPatch, location, strings.NewReader("hello"), http.Header{
+		"Tus-Resumable": {TusVersion},
+		"Upload-Offset": {"0"},
+		"Content-Type":  {tusContentType},
+	})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("patch: unexpected %d", w.Code)
+	}
+	upload, err := store.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(upload.ExpiresAt) < 30*time.Minute {
+		t.Fatalf("expected PATCH to extend the expiration, got %v", upload.ExpiresAt)
+	}
+	if w.Header().Get("Upload-Expires") != upload.ExpiresAt.UTC().Format(http.TimeFormat) {
+		t.Fatalf("expected Upload-Expires %v, got %q", upload.ExpiresAt, w.Header().Get("Upload-Expires"))
+	}
+}
+
+func TestTusTerminateWaitsForUploadLock(t *testing.T) {
+	engine, store := newTusTestEngine(t, TusOptions{})
+	w := PerformRequest(engine, http.MethodPost, "/files", nil, http.Header{"Tus-Resumable": {TusVersion}, "Upload-Length": {"10"}})
+	location := w.Header().Get("Location")
+	id := strings.TrimPrefix(location, "/files/")
+
+	// 模拟进行中的 PATCH 持有锁
+	unlock, err := engine.locker.Lock(context.Background(), "tus:"+id, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodDelete, location, nil).WithContext(ctx)
+	req.Header.Set("Tus-Resumable", TusVersion)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code == http.StatusNoContent {
+		t.Fatal("expected DELETE to wait for the lock")
+	}
+	if _, err := store.Get(context.Background(), id); err != nil {
+		t.Fatalf("upload should survive while locked, got %v", err)
+	}
+
+	unlock()
+	w = PerformRequest(engine, http.MethodDelete, location, nil, http.Header{"Tus-Resumable": {TusVersion}})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected DELETE to succeed after unlock, got %d", w.Code)
+	}
+}
@@ -0,0 +1,212 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/go-json-experiment/json"
+)
+
+// HTTPError 是携带 HTTP 状态码的错误.
+// 处理函数返回 HTTPError 时, 框架会使用其中的状态码交给 ErrorHandler 处理.
+type HTTPError struct {
+	Code int
+	Err  error
+}
+
+// NewHTTPError 创建一个携带状态码的错误.
+// 如果 err 为 nil, 则使用状态码对应的描述作为错误信息.
+func NewHTTPError(code int, err error) *HTTPError {
+	if err == nil {
+		err = errors.New(http.StatusText(code))
+	}
+	return &HTTPError{Code: code, Err: err}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Validator 可由请求结构体实现, 类型化处理器在绑定完成后会调用 Validate 进行校验.
+type Validator interface {
+	Validate() error
+}
+
+// TypedHandlerFunc 是类型化处理函数, 接收已绑定的请求对象并返回响应对象.
+type TypedHandlerFunc[Req, Resp any] func(c *Context, req Req) (Resp, error)
+
+// JSONHandler 将类型化处理函数适配为 HandlerFunc.
+// 它依次完成: 绑定请求 -> 校验 (如果 Req 实现了 Validator) -> 调用 fn -> 以 200 渲染 JSON 响应.
+//
+//   - 绑定失败返回 400
+//   - 校验失败返回 422
+//   - fn 返回 *HTTPError 时使用其状态码, 其他错误返回 500
+//
+// 示例:
+//
+//	type CreateUserReq struct {
+//	    Name string `json:"name"`
+//	}
+//
+//	r.POST("/users", touka.JSONHandler(func(c *touka.Context, req CreateUserReq) (User, error) {
+//	    return svc.Create(c, req.Name)
+//	}))
+func JSONHandler[Req, Resp any](fn TypedHandlerFunc[Req, Resp]) HandlerFunc {
+	return JSONHandlerWithStatus(http.StatusOK, fn)
+}
+
+// JSONHandlerWithStatus 与 JSONHandler 相同, 但允许指定成功时的状态码 (例如 201).
+// 当 code 为 204 时不写入响应体.
+func JSONHandlerWithStatus[Req, Resp any](code int, fn TypedHandlerFunc[Req, Resp]) HandlerFunc {
+	if fn == nil {
+		panic("touka: typed handler func must not be nil")
+	}
+	h := func(c *Context) {
+		var req Req
+		if err := bindTypedRequest(c, &req); err != nil {
+			c.AddClientError(err)
+			c.ErrorUseHandle(bindErrorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+		if err := validateTypedRequest(&req); err != nil {
+			c.AddClientError(err)
+			c.ErrorUseHandle(http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		resp, err := fn(c, req)
+		if err != nil {
+			c.AddError(err)
+			c.ErrorUseHandle(typedErrorStatus(err), err)
+			return
+		}
+		if c.Writer.Written() || c.IsAborted() {
+			// 处理函数已自行写入响应
+			return
+		}
+		if code == http.StatusNoContent {
+			c.Status(code)
+			return
+		}
+		c.JSON(code, resp)
+	}
+	typedHandlers.Store(handlerAddr(h), typedHandler{h: h, types: RouteTypes{
+		Request:  reflect.TypeFor[Req](),
+		Response: reflect.TypeFor[Resp](),
+		Status:   code,
+	}})
+	return h
+}
+
+// MetaTypes 是类型化处理器的请求与响应类型的元数据键, 值为 RouteTypes.
+// 以 JSONHandler 或 JSONHandlerWithStatus 作为最后一个处理器的路由会自动附加
+const MetaTypes = "touka.types"
+
+// RouteTypes 是类型化处理器的请求与响应类型, CLIGenerateOpenAPI 据此生成请求体与响应的 schema
+type RouteTypes struct {
+	Request  reflect.Type
+	Response reflect.Type
+	Status   int // 成功时的状态码
+}
+
+// MarshalJSON 以类型名输出, 供路由列表等以 JSON 展示元数据的场景使用
+func (t RouteTypes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"request": t.Request.String(), "response": t.Response.String(), "status": t.Status})
+}
+
+// Types 返回类型化处理器的请求与响应类型
+func (r RouteInfo) Types() (RouteTypes, bool) {
+	t, ok := r.Meta[MetaTypes].(RouteTypes)
+	return t, ok
+}
+
+// typedHandlers 记录 JSONHandler 创建的处理器的类型, 键为闭包对象的地址.
+// 值中保留处理器本身, 使该地址在记录存在期间不会被其他闭包复用
+var typedHandlers sync.Map // map[uintptr]typedHandler
+
+type typedHandler struct {
+	h     HandlerFunc
+	types RouteTypes
+}
+
+// handlerAddr 返回函数值指向的闭包对象地址, 同一函数的不同闭包实例地址不同
+func handlerAddr(h HandlerFunc) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&h))
+}
+
+// typedRouteTypes 返回 h 由 JSONHandler 创建时记录的类型
+func typedRouteTypes(h HandlerFunc) (RouteTypes, bool) {
+	if h == nil {
+		return RouteTypes{}, false
+	}
+	v, ok := typedHandlers.Load(handlerAddr(h))
+	if !ok {
+		return RouteTypes{}, false
+	}
+	return v.(typedHandler).types, true
+}
+
+// bindTypedRequest 根据请求是否携带 body 选择绑定来源.
+// 没有 body 的请求 (GET/HEAD/DELETE 等) 从查询参数绑定, 其余按 Content-Type 分发给 ShouldBind.
+func bindTypedRequest(c *Context, req any) error {
+	target := reflect.ValueOf(req).Elem()
+	if target.Kind() == reflect.Struct && target.NumField() == 0 {
+		return nil // struct{} 等无需绑定
+	}
+
+	if hasRequestBody(c.Request) {
+		if c.Request.Header.Get("Content-Type") == "" {
+			return c.ShouldBindJSON(req)
+		}
+		return c.ShouldBind(req)
+	}
+
+	if target.Kind() != reflect.Struct {
+		return nil
+	}
+	if err := bindForm(c.Request.URL.Query(), req); err != nil {
+		return fmt.Errorf("query binding error: %w", err)
+	}
+	return nil
+}
+
+func hasRequestBody(req *http.Request) bool {
+	if req == nil || req.Body == nil || req.Body == http.NoBody {
+		return false
+	}
+	return req.ContentLength != 0
+}
+
+func validateTypedRequest(req any) error {
+	if v, ok := req.(Validator); ok {
+		return v.Validate()
+	}
+	// 值接收者实现的 Validator
+	if v, ok := reflect.ValueOf(req).Elem().Interface().(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+func typedErrorStatus(err error) int {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code > 0 {
+		return httpErr.Code
+	}
+	return http.StatusInternalServerError
+}
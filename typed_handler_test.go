@@ -0,0 +1,79 @@
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type typedCreateReq struct {
+	Name string `json:"name" form:"name"`
+}
+
+func (r typedCreateReq) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type typedCreateResp struct {
+	Greeting string `json:"greeting"`
+}
+
+func TestJSONHandlerBindsValidatesAndRenders(t *testing.T) {
+	engine := New()
+	engine.POST("/users", JSONHandlerWithStatus(http.StatusCreated, func(c *Context, req typedCreateReq) (typedCreateResp, error) {
+		return typedCreateResp{Greeting: "hello " + req.Name}, nil
+	}))
+
+	headers := http.Header{"Content-Type": []string{"application/json"}}
+	rr := PerformRequest(engine, http.MethodPost, "/users", strings.NewReader(`{"name":"touka"}`), headers)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"greeting":"hello touka"`) {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	rr = PerformRequest(engine, http.MethodPost, "/users", strings.NewReader(`{"name":""}`), headers)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected validation status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+
+	rr = PerformRequest(engine, http.MethodPost, "/users", strings.NewReader(`{bad`), headers)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected bind status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestJSONHandlerBindsQueryWithoutBody(t *testing.T) {
+	engine := New()
+	engine.GET("/greet", JSONHandler(func(c *Context, req typedCreateReq) (typedCreateResp, error) {
+		return typedCreateResp{Greeting: "hi " + req.Name}, nil
+	}))
+
+	rr := PerformRequest(engine, http.MethodGet, "/greet?name=iroha", nil, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"greeting":"hi iroha"`) {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+func TestJSONHandlerUsesHTTPErrorStatus(t *testing.T) {
+	engine := New()
+	engine.GET("/missing", JSONHandler(func(c *Context, req struct{}) (typedCreateResp, error) {
+		return typedCreateResp{}, NewHTTPError(http.StatusNotFound, errors.New("no such user"))
+	}))
+
+	rr := PerformRequest(engine, http.MethodGet, "/missing", nil, nil)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, "no such user") {
+		t.Fatalf("expected error detail in body, got %s", body)
+	}
+}
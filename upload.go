@@ -0,0 +1,249 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrUploadTooLarge 表示上传的文件超过了 SetUploadLimits 设置的大小限制, 它包装了 ErrBodyTooLarge
+	ErrUploadTooLarge = fmt.Errorf("%w: uploaded file too large", ErrBodyTooLarge)
+	// ErrUnsafeFilename 表示上传文件的文件名无法安全地用作保存路径, 例如 ".." 或空文件名
+	ErrUnsafeFilename = errors.New("unsafe upload filename")
+)
+
+// SetUploadLimits 配置 multipart 表单中上传文件的大小限制: perFile 为单个文件的上限, total 为同一请求中所有文件的总上限,
+// 小于等于 0 表示不限制. 解析表单时边读取边检查, 超出限制的请求体不会被完整读入内存或临时文件.
+// 该限制与 MaxRequestBodySize 相互独立, 后者仍然限制整个请求体的大小
+func (engine *Engine) SetUploadLimits(perFile, total int64) {
+	engine.maxUploadFileSize = perFile
+	engine.maxUploadTotalSize = total
+}
+
+// SetMultipartMemory 设置解析 multipart 表单时保留在内存中的上限, 超出部分 (包括较大的文件) 写入临时文件.
+// 小于等于 0 时使用默认的 32MB; 设置了内存预算时, 实际上限不超过剩余预算
+func (engine *Engine) SetMultipartMemory(n int64) {
+	engine.multipartMemory = max(n, 0)
+}
+
+// FormFile 返回 multipart 表单中字段 name 的第一个文件, 字段不存在时返回 http.ErrMissingFile.
+// 表单按 SetMultipartMemory 与 MaxRequestBodySize 解析, 文件超过 SetUploadLimits 的限制时返回包装了 ErrUploadTooLarge 的错误
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	files := form.File[name]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+	return files[0], nil
+}
+
+// MultipartForm 解析并返回 multipart 表单, 包括普通字段与上传的文件. 与 PostForm、ShouldBindForm 共用解析结果
+func (c *Context) MultipartForm() (*multipart.Form, error) {
+	if c.Request.MultipartForm == nil {
+		c.bindRequestBody()
+		if err := c.parseMultipartForm(); err != nil {
+			return nil, fmt.Errorf("parse multipart form error: %w", err)
+		}
+		if err := c.reserveForm(); err != nil {
+			return nil, fmt.Errorf("parse multipart form error: %w", err)
+		}
+		c.formCache = c.Request.PostForm
+	}
+	if err := c.checkUploads(); err != nil {
+		return nil, err
+	}
+	return c.Request.MultipartForm, nil
+}
+
+// SaveUploadedFile 将上传的文件保存到 dst. dst 为已存在的目录或以路径分隔符结尾时,
+// 文件保存在该目录下, 文件名取客户端提供的文件名中的最后一段, 无法安全使用时返回 ErrUnsafeFilename.
+// 缺少的上级目录会被创建; 内容先写入同目录下的临时文件再重命名, 失败时不会留下不完整的文件
+func (c *Context) SaveUploadedFile(fh *multipart.FileHeader, dst string) error {
+	if fi, err := os.Stat(dst); (err == nil && fi.IsDir()) || strings.HasSuffix(dst, "/") || strings.HasSuffix(dst, string(filepath.Separator)) {
+		name, err := safeUploadName(fh.Filename)
+		if err != nil {
+			return err
+		}
+		dst = filepath.Join(dst, name)
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // 重命名成功后为空操作
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// safeUploadName 返回客户端文件名的最后一段, 同时按 / 与 \ 拆分, 拒绝空名、"." 与 ".." 以及包含控制字符的名称
+func safeUploadName(filename string) (string, error) {
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "", fmt.Errorf("%w: %q", ErrUnsafeFilename, filename)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return "", fmt.Errorf("%w: %q", ErrUnsafeFilename, filename)
+		}
+	}
+	return name, nil
+}
+
+// checkUploads 检查已解析的 multipart 表单中的文件是否超过上传限制
+func (c *Context) checkUploads() error {
+	if c.engine == nil || c.Request.MultipartForm == nil {
+		return nil
+	}
+	perFile, total := c.engine.maxUploadFileSize, c.engine.maxUploadTotalSize
+	if perFile <= 0 && total <= 0 {
+		return nil
+	}
+	var sum int64
+	for field, files := range c.Request.MultipartForm.File {
+		for _, fh := range files {
+			if perFile > 0 && fh.Size > perFile {
+				return fmt.Errorf("%w: %s (%q) is %d bytes, limit is %d", ErrUploadTooLarge, field, fh.Filename, fh.Size, perFile)
+			}
+			sum += fh.Size
+		}
+	}
+	if total > 0 && sum > total {
+		return fmt.Errorf("%w: uploads total %d bytes, limit is %d", ErrUploadTooLarge, sum, total)
+	}
+	return nil
+}
+
+// uploadLimitOverhead 是读取时为分段头部与普通字段预留的余量.
+// 读取阶段按 "限制 + 余量" 中止请求, 解析后的 checkUploads 再按文件的实际大小精确检查
+const uploadLimitOverhead = 1 << 20
+
+// parseMultipartForm 解析 multipart 表单, 设置了 SetUploadLimits 时在读取请求体的过程中检查大小
+func (c *Context) parseMultipartForm() error {
+	if r := c.newUploadLimitReader(); r != nil {
+		body := c.Request.Body
+		c.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{r, body}
+		defer func() { c.Request.Body = body }()
+	}
+	return c.Request.ParseMultipartForm(c.formMaxMemory())
+}
+
+// uploadLimitReader 在读取 multipart 请求体时检查单个分段与整个请求体的大小
+type uploadLimitReader struct {
+	r       io.Reader
+	delim   []byte // 分段之间的分隔符 "\r\n--<boundary>"
+	perPart int64  // 单个分段的上限, 0 表示不限制
+	total   int64  // 整个请求体的上限, 0 表示不限制
+	read    int64
+	partLen int64  // 当前分段已读取的字节数
+	buf     []byte // 上次读取的末尾 (可能包含不完整的分隔符) 与本次读取的数据
+}
+
+func (c *Context) newUploadLimitReader() *uploadLimitReader {
+	if c.engine == nil || c.Request.Body == nil {
+		return nil
+	}
+	perFile, total := c.engine.maxUploadFileSize, c.engine.maxUploadTotalSize
+	if perFile <= 0 && total <= 0 {
+		return nil
+	}
+	_, params, err := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if err != nil || params["boundary"] == "" {
+		return nil
+	}
+	r := &uploadLimitReader{r: c.Request.Body, delim: []byte("\r\n--" + params["boundary"])}
+	if perFile > 0 {
+		r.perPart = perFile + uploadLimitOverhead
+	}
+	if total > 0 {
+		r.total = total + uploadLimitOverhead
+	}
+	return r
+}
+
+func (r *uploadLimitReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n == 0 {
+		return n, err
+	}
+	r.read += int64(n)
+	if r.total > 0 && r.read > r.total {
+		return 0, fmt.Errorf("%w: multipart body exceeds %d bytes", ErrUploadTooLarge, r.total)
+	}
+	if r.perPart > 0 {
+		if err := r.countParts(p[:n]); err != nil {
+			return 0, err
+		}
+	}
+	return n, err
+}
+
+// countParts 按分隔符拆分数据, 累计当前分段的长度
+func (r *uploadLimitReader) countParts(data []byte) error {
+	tail := len(r.buf)
+	r.buf = append(r.buf, data...)
+	start := 0
+	for {
+		i := bytes.Index(r.buf[start:], r.delim)
+		if i < 0 {
+			break
+		}
+		// 分隔符之前的数据属于当前分段, 其中 tail 之前的部分已经计入 partLen
+		if end := start + i; end > tail {
+			r.partLen += int64(end - max(start, tail))
+		}
+		if r.partLen > r.perPart {
+			return fmt.Errorf("%w: multipart part exceeds %d bytes", ErrUploadTooLarge, r.perPart)
+		}
+		r.partLen = 0
+		start += i + len(r.delim)
+		tail = max(tail, start)
+	}
+	r.partLen += int64(len(r.buf) - max(start, tail))
+	if r.partLen > r.perPart {
+		return fmt.Errorf("%w: multipart part exceeds %d bytes", ErrUploadTooLarge, r.perPart)
+	}
+	// 只保留可能是分隔符开头的末尾部分, 这部分已经计入 partLen
+	keep := min(len(r.buf)-start, len(r.delim)-1)
+	r.buf = append(r.buf[:0], r.buf[len(r.buf)-keep:]...)
+	return nil
+}
@@ -0,0 +1,257 @@
+package touka
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func newUploadRequest(t *testing.T, fields map[string]string, files map[string][]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	for field, contents := range files {
+		for i, content := range contents {
+			fw, err := mw.CreateFormFile(field, field+string(rune('a'+i))+".txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.WriteString(fw, content)
+		}
+	}
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestShouldBindFormFiles(t *testing.T) {
+	var form struct {
+		Name    string                  `form:"name"`
+		Avatar  *multipart.FileHeader   `form:"avatar"`
+		Photos  []*multipart.FileHeader `form:"photos"`
+		Missing *multipart.FileHeader   `form:"missing"`
+	}
+	req := newUploadRequest(t, map[string]string{"name": "alice"}, map[string][]string{
+		"avatar": {"avatar-data"},
+		"photos": {"one", "two"},
+	})
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+	if err := c.ShouldBindForm(&form); err != nil {
+		t.Fatalf("ShouldBindForm: %v", err)
+	}
+	if form.Name != "alice" || form.Missing != nil {
+		t.Fatalf("unexpected form: %+v", form)
+	}
+	if form.Avatar == nil || form.Avatar.Filename != "avatara.txt" || form.Avatar.Size != int64(len("avatar-data")) {
+		t.Fatalf("avatar not bound: %+v", form.Avatar)
+	}
+	if len(form.Photos) != 2 {
+		t.Fatalf("expected 2 photos, got %d", len(form.Photos))
+	}
+	f, err := form.Photos[1].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if data, _ := io.ReadAll(f); string(data) != "two" {
+		t.Fatalf("unexpected photo content %q", data)
+	}
+}
+
+func TestShouldBindFormFilesIgnoredForURLEncoded(t *testing.T) {
+	var form struct {
+		Name   string                `form:"name"`
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("name=bob&avatar=x"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+	if err := c.ShouldBindForm(&form); err != nil {
+		t.Fatalf("ShouldBindForm: %v", err)
+	}
+	if form.Name != "bob" || form.Avatar != nil {
+		t.Fatalf("unexpected form: %+v", form)
+	}
+}
+
+func TestUploadLimits(t *testing.T) {
+	type uploadForm struct {
+		Photos []*multipart.FileHeader `form:"photos"`
+	}
+	tests := []struct {
+		name           string
+		perFile, total int64
+		wantErr        bool
+	}{
+		{"unlimited", 0, 0, false},
+		{"within limits", 4, 8, false},
+		{"file too large", 3, 0, true},
+		{"total too large", 0, 7, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := New()
+			engine.SetUploadLimits(tt.perFile, tt.total)
+			var bindErr error
+			engine.POST("/upload", func(c *Context) {
+				var form uploadForm
+				bindErr = c.ShouldBindForm(&form)
+			})
+			req := newUploadRequest(t, nil, map[string][]string{"photos": {"abcd", "efgh"}})
+			engine.ServeHTTP(httptest.NewRecorder(), req)
+			if tt.wantErr {
+				if !errors.Is(bindErr, ErrUploadTooLarge) || !errors.Is(bindErr, ErrBodyTooLarge) {
+					t.Fatalf("expected ErrUploadTooLarge, got %v", bindErr)
+				}
+			} else if bindErr != nil {
+				t.Fatalf("unexpected error: %v", bindErr)
+			}
+		})
+	}
+}
+
+func TestFormFileAndSaveUploadedFile(t *testing.T) {
+	dir := t.TempDir()
+	engine := New()
+	engine.SetMultipartMemory(1) // 文件内容写入临时文件
+	engine.POST("/upload", func(c *Context) {
+		fh, err := c.FormFile("avatar")
+		if err != nil {
+			c.ErrorUseHandle(http.StatusBadRequest, err)
+			return
+		}
+		if _, err := c.FormFile("missing"); !errors.Is(err, http.ErrMissingFile) {
+			t.Errorf("expected ErrMissingFile, got %v", err)
+		}
+		if c.PostForm("name") != "alice" {
+			t.Errorf("form fields should be shared with PostForm")
+		}
+		if err := c.SaveUploadedFile(fh, dir+"/"); err != nil {
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+		if err := c.SaveUploadedFile(fh, filepath.Join(dir, "nested", "copy.txt")); err != nil {
+			c.ErrorUseHandle(http.StatusInternalServerError, err)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newUploadRequest(t, map[string]string{"name": "alice"}, map[string][]string{"avatar": {"hello"}}))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, name := range []string{"avatara.txt", filepath.Join("nested", "copy.txt")} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != "hello" {
+			t.Fatalf("%s: got %q, %v", name, data, err)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("unexpected files left in upload dir: %v", entries)
+	}
+}
+
+func TestSaveUploadedFileUnsafeName(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	for _, name := range []string{"../../etc/passwd", `..\..\evil.txt`, "a/b/c.txt"} {
+		if got, err := safeUploadName(name); err != nil || strings.ContainsAny(got, `/\`) || got == ".." {
+			t.Fatalf("safeUploadName(%q) = %q, %v", name, got, err)
+		}
+	}
+	for _, name := range []string{"", "..", "/", "a/..", "bad\x00name"} {
+		err := c.SaveUploadedFile(&multipart.FileHeader{Filename: name}, dir)
+		if !errors.Is(err, ErrUnsafeFilename) {
+			t.Fatalf("%q: expected ErrUnsafeFilename, got %v", name, err)
+		}
+	}
+}
+
+// countingReader 记录已被读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func TestUploadLimitsStopReadingEarly(t *testing.T) {
+	const size = 8 << 20
+	tests := []struct {
+		name           string
+		perFile, total int64
+	}{
+		{"file too large", 1 << 10, 0},
+		{"total too large", 0, 1 << 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := New()
+			engine.SetUploadLimits(tt.perFile, tt.total)
+			var formErr error
+			engine.POST("/upload", func(c *Context) {
+				_, formErr = c.MultipartForm()
+			})
+			req := newUploadRequest(t, map[string]string{"name": "alice"}, map[string][]string{"photos": {strings.Repeat("x", size)}})
+			body := &countingReader{r: req.Body}
+			req.Body = io.NopCloser(body)
+			engine.ServeHTTP(httptest.NewRecorder(), req)
+			if !errors.Is(formErr, ErrUploadTooLarge) {
+				t.Fatalf("expected ErrUploadTooLarge, got %v", formErr)
+			}
+			if body.n >= size/2 {
+				t.Fatalf("expected parsing to stop early, read %d of %d bytes", body.n, size)
+			}
+		})
+	}
+}
+
+func TestUploadLimitReaderSplitReads(t *testing.T) {
+	engine := New()
+	engine.SetUploadLimits(4, 0)
+	var formErr error
+	var names []string
+	engine.POST("/upload", func(c *Context) {
+		names = nil
+		form, err := c.MultipartForm()
+		formErr = err
+		if err == nil {
+			for _, fh := range form.File["photos"] {
+				names = append(names, fh.Filename)
+			}
+		}
+	})
+	// 逐字节读取, 分隔符跨越多次读取
+	req := newUploadRequest(t, map[string]string{"name": "alice"}, map[string][]string{"photos": {"abcd", "efgh"}})
+	req.Body = io.NopCloser(iotest.OneByteReader(req.Body))
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	if formErr != nil || len(names) != 2 {
+		t.Fatalf("expected every part within the limit to parse, got %v %v", names, formErr)
+	}
+
+	req = newUploadRequest(t, nil, map[string][]string{"photos": {"abcd", strings.Repeat("z", uploadLimitOverhead+5)}})
+	req.Body = io.NopCloser(iotest.OneByteReader(req.Body))
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	if !errors.Is(formErr, ErrUploadTooLarge) || !strings.Contains(formErr.Error(), "multipart part exceeds") {
+		t.Fatalf("expected the reader to reject the part, got %v", formErr)
+	}
+}
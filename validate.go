@@ -0,0 +1,144 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// Validate 检查引擎配置与启动选项, 返回汇总后的全部问题, 没有问题时返回 nil.
+// Run 会在启动服务器前自动调用, 也可以在测试或部署前单独调用以提前发现错误配置:
+//   - 处理器链中包含 nil 处理器的路由, 或启用 PathPolicy.NormalizeUnicode 时不是 NFC 形式的路由
+//   - HTMLRender 为不支持的类型
+//   - 信任代理头部的配置问题, 例如头部名称无效
+//   - TLS 配置问题, 例如证书缺少私钥、版本范围无效
+//   - 相互冲突的选项, 例如启用了 UnMatchFS 却未提供文件系统, 或没有可用的 HTTP 协议
+//
+// 不妨碍启动但可能不符合预期的配置由 ConfigWarnings 报告
+func (engine *Engine) Validate(opts ...RunOption) error {
+	cfg, err := resolveRunConfig(opts)
+	if err != nil {
+		return err
+	}
+	return engine.validate(cfg)
+}
+
+// resolveRunConfig 应用启动选项并推导运行模式
+func resolveRunConfig(opts []RunOption) (runConfig, error) {
+	cfg := defaultRunConfig()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt.apply(&cfg); err != nil {
+			return cfg, err
+		}
+	}
+	if cfg.httpRedirectAddr != "" {
+		cfg.mode = runModeHTTPSRedirect
+	} else if cfg.tlsConfig != nil {
+		cfg.mode = runModeHTTPS
+	}
+	return cfg, nil
+}
+
+func (engine *Engine) validate(cfg runConfig) error {
+	var errs []error
+	if err := validateRunConfig(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, engine.routeErrors...)
+	errs = append(errs, engine.validatePathPolicy()...)
+	errs = append(errs, engine.validateRendering()...)
+	errs = append(errs, engine.validateProxyHeaders()...)
+	errs = append(errs, engine.validateProtocols(cfg.mode != runModeHTTP)...)
+	if cfg.tlsConfig != nil {
+		errs = append(errs, validateTLSConfig(cfg.tlsConfig)...)
+	}
+	if engine.unMatchFS.ServeUnmatchedAsFS && engine.unMatchFS.FSForUnmatched == nil {
+		errs = append(errs, errors.New("UnMatchFS is enabled without a file system"))
+	}
+	return errors.Join(errs...)
+}
+
+func (engine *Engine) validateRendering() []error {
+	var errs []error
+	switch engine.HTMLRender.(type) {
+	case nil, *template.Template, HTMLRenderer:
+	default:
+		errs = append(errs, fmt.Errorf("HTMLRender of type %T is not supported, use *html/template.Template or HTMLRenderer", engine.HTMLRender))
+	}
+	return errs
+}
+
+// ConfigWarnings 返回不妨碍启动但可能不符合预期的配置, Run 启动时将其写入日志:
+//   - 开启了模板热重载, 但 HTMLRender 不是通过 LoadHTMLGlob 加载的, 热重载不会生效
+//   - 按 RemoteIPHeaders 解析客户端 IP 却没有通过 SetTrustedProxies 限定代理, 任何客户端都可以伪造 ClientIP
+//
+// 处理器是否渲染 HTML 无法在启动前判断, 未设置 HTMLRender 时 c.HTML 在请求时报告错误
+func (engine *Engine) ConfigWarnings() []string {
+	var warnings []string
+	if engine.templateReload && engine.HTMLRender != nil && engine.htmlGlob == "" {
+		warnings = append(warnings, "template reload is enabled but templates were not loaded with LoadHTMLGlob, reload does not apply")
+	}
+	if engine.ForwardByClientIP && len(engine.RemoteIPHeaders) > 0 && len(engine.trustedProxies) == 0 {
+		warnings = append(warnings, "ForwardByClientIP trusts "+strings.Join(engine.RemoteIPHeaders, ", ")+" from any peer, call SetTrustedProxies so clients cannot spoof ClientIP")
+	}
+	return warnings
+}
+
+func (engine *Engine) validateProxyHeaders() []error {
+	if !engine.ForwardByClientIP {
+		return nil
+	}
+	if len(engine.RemoteIPHeaders) == 0 {
+		return []error{errors.New("ForwardByClientIP is enabled but RemoteIPHeaders is empty")}
+	}
+	var errs []error
+	for _, header := range engine.RemoteIPHeaders {
+		if !httpguts.ValidHeaderFieldName(header) {
+			errs = append(errs, fmt.Errorf("invalid remote IP header name %q", header))
+		}
+	}
+	return errs
+}
+
+func (engine *Engine) validateProtocols(serveTLS bool) []error {
+	p := engine.Protocols
+	if serveTLS && engine.useDefaultProtocols {
+		// HTTPS 下默认协议集会启用 HTTP/1.1 与 HTTP/2
+		return nil
+	}
+	if serveTLS && !p.Http1 && !p.Http2 {
+		return []error{errors.New("no protocol is enabled for HTTPS, enable Http1 or Http2")}
+	}
+	if !serveTLS && !p.Http1 && !p.Http2_Cleartext {
+		return []error{errors.New("no protocol is enabled for plain HTTP, enable Http1 or Http2_Cleartext")}
+	}
+	return nil
+}
+
+func validateTLSConfig(cfg *tls.Config) []error {
+	var errs []error
+	for i, cert := range cfg.Certificates {
+		if len(cert.Certificate) == 0 {
+			errs = append(errs, fmt.Errorf("tls certificate #%d has no certificate chain", i))
+		}
+		if cert.PrivateKey == nil {
+			errs = append(errs, fmt.Errorf("tls certificate #%d has no private key", i))
+		}
+	}
+	if cfg.MinVersion != 0 && cfg.MaxVersion != 0 && cfg.MinVersion > cfg.MaxVersion {
+		errs = append(errs, fmt.Errorf("tls MinVersion %s is greater than MaxVersion %s",
+			tls.VersionName(cfg.MinVersion), tls.VersionName(cfg.MaxVersion)))
+	}
+	return errs
+}
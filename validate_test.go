@@ -0,0 +1,97 @@
+package touka
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateCleanEngine(t *testing.T) {
+	engine := New()
+	engine.GET("/", func(c *Context) {})
+	if err := engine.Validate(); err != nil {
+		t.Fatalf("expected no validation errors, got %v", err)
+	}
+	if err := engine.Validate(WithTLS(&tls.Config{})); err != nil {
+		t.Fatalf("expected no validation errors with TLS, got %v", err)
+	}
+}
+
+func TestValidateAggregatesErrors(t *testing.T) {
+	engine := New()
+	engine.GET("/broken", func(c *Context) {}, nil)
+	engine.HTMLRender = "not a template"
+	engine.SetRemoteIPHeaders([]string{"X-Forwarded-For", "Bad Header"})
+	engine.SetProtocols(&ProtocolsConfig{Http2: true})
+
+	err := engine.Validate(WithTLS(&tls.Config{
+		Certificates: []tls.Certificate{{}},
+		MinVersion:   tls.VersionTLS13,
+		MaxVersion:   tls.VersionTLS12,
+	}))
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		"route GET /broken has 1 nil handler(s)",
+		"HTMLRender of type string is not supported",
+		`invalid remote IP header name "Bad Header"`,
+		"tls certificate #0 has no certificate chain",
+		"tls certificate #0 has no private key",
+		"tls MinVersion TLS 1.3 is greater than MaxVersion TLS 1.2",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got:\n%v", want, err)
+		}
+	}
+
+	// 纯 HTTP 下仅启用 HTTP/2 时没有可用的协议
+	if err := engine.Validate(); err == nil || !strings.Contains(err.Error(), "no protocol is enabled for plain HTTP") {
+		t.Fatalf("expected plain HTTP protocol error, got %v", err)
+	}
+}
+
+func TestValidateProxyHeaders(t *testing.T) {
+	engine := New()
+	engine.SetRemoteIPHeaders(nil)
+	if err := engine.Validate(); err == nil {
+		t.Fatal("expected error for ForwardByClientIP without headers")
+	}
+	engine.SetForwardByClientIP(false)
+	if err := engine.Validate(); err != nil {
+		t.Fatalf("expected no error when ForwardByClientIP is disabled, got %v", err)
+	}
+}
+
+func TestRunFailsFastOnValidationErrors(t *testing.T) {
+	engine := New()
+	engine.Handle(http.MethodGet, "/broken", nil)
+	if err := engine.Run(WithAddr("127.0.0.1:0")); err == nil || !strings.Contains(err.Error(), "nil handler") {
+		t.Fatalf("expected Run to fail validation, got %v", err)
+	}
+}
+
+func TestConfigWarnings(t *testing.T) {
+	engine := NewDevelopment()
+	engine.HTMLRender = HTMLRenderFunc(func(c *Context, w io.Writer, name string, data any) error { return nil })
+	if err := engine.Validate(); err != nil {
+		t.Fatalf("expected a custom renderer with template reload to pass validation, got %v", err)
+	}
+	warnings := strings.Join(engine.ConfigWarnings(), "\n")
+	if !strings.Contains(warnings, "reload does not apply") {
+		t.Errorf("expected a template reload warning, got %q", warnings)
+	}
+	if !strings.Contains(warnings, "SetTrustedProxies") {
+		t.Errorf("expected a trusted proxy warning, got %q", warnings)
+	}
+
+	if err := engine.SetTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	engine.SetMode(ReleaseMode)
+	if warnings := engine.ConfigWarnings(); len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %q", warnings)
+	}
+}
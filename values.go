@@ -0,0 +1,227 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrMissingParam 表示请求中不存在所需的查询参数或路径参数
+var ErrMissingParam = errors.New("parameter missing")
+
+// QueryArray 返回查询参数 key 的全部值, 例如 ?id=1&id=2 返回 [1 2]. 与 ShouldBindQuery 一致,
+// key 不存在时也接受 key[] 的写法 (?id[]=1&id[]=2)
+func (c *Context) QueryArray(key string) []string {
+	values, _ := c.GetQueryArray(key)
+	return values
+}
+
+// GetQueryArray 与 QueryArray 相同, 另外返回参数是否存在
+func (c *Context) GetQueryArray(key string) ([]string, bool) {
+	return lookupArray(c.queryValues(), key)
+}
+
+// QueryMap 返回形如 key[name]=value 的查询参数组成的 map, 例如 ?filter[status]=open&filter[tag]=go
+// 返回 map[status:open tag:go]. 同一个 name 出现多次时取第一个值
+func (c *Context) QueryMap(key string) map[string]string {
+	m, _ := c.GetQueryMap(key)
+	return m
+}
+
+// GetQueryMap 与 QueryMap 相同, 另外返回是否存在至少一个匹配的参数
+func (c *Context) GetQueryMap(key string) (map[string]string, bool) {
+	return lookupMap(c.queryValues(), key)
+}
+
+// GetQuery 返回查询参数 key 的第一个值以及参数是否存在, 用于区分参数不存在与值为空
+func (c *Context) GetQuery(key string) (string, bool) {
+	values, ok := c.queryValues()[key]
+	if !ok || len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// PostFormArray 返回请求体表单字段 key 的全部值, 规则与 QueryArray 相同
+func (c *Context) PostFormArray(key string) []string {
+	values, _ := c.GetPostFormArray(key)
+	return values
+}
+
+// GetPostFormArray 与 PostFormArray 相同, 另外返回字段是否存在
+func (c *Context) GetPostFormArray(key string) ([]string, bool) {
+	return lookupArray(c.postFormValues(), key)
+}
+
+// PostFormMap 返回形如 key[name]=value 的请求体表单字段组成的 map, 规则与 QueryMap 相同
+func (c *Context) PostFormMap(key string) map[string]string {
+	m, _ := c.GetPostFormMap(key)
+	return m
+}
+
+// GetPostFormMap 与 PostFormMap 相同, 另外返回是否存在至少一个匹配的字段
+func (c *Context) GetPostFormMap(key string) (map[string]string, bool) {
+	return lookupMap(c.postFormValues(), key)
+}
+
+// GetPostForm 返回请求体表单字段 key 的第一个值以及字段是否存在
+func (c *Context) GetPostForm(key string) (string, bool) {
+	values, ok := c.postFormValues()[key]
+	if !ok || len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+func lookupArray(values url.Values, key string) ([]string, bool) {
+	if v, ok := values[key]; ok {
+		return v, true
+	}
+	v, ok := values[key+"[]"]
+	return v, ok
+}
+
+func lookupMap(values url.Values, key string) (map[string]string, bool) {
+	m := make(map[string]string)
+	prefix := key + "["
+	for k, v := range values {
+		name, ok := strings.CutPrefix(k, prefix)
+		if !ok || len(v) == 0 {
+			continue
+		}
+		// 只接受 key[name] 形式, key[] 与 key[a][b] 等嵌套写法不属于 map
+		name, ok = strings.CutSuffix(name, "]")
+		if !ok || name == "" || strings.ContainsAny(name, "[]") {
+			continue
+		}
+		m[name] = v[0]
+	}
+	return m, len(m) > 0
+}
+
+// QueryInt 将查询参数 key 解析为 int. 参数不存在或不合法时返回 def (省略时为 0),
+// 需要区分这两种情况时使用 GetQueryInt
+func (c *Context) QueryInt(key string, def ...int) int {
+	if v, err := c.GetQueryInt(key); err == nil {
+		return v
+	}
+	return defaultValue(def)
+}
+
+// GetQueryInt 将查询参数 key 解析为 int. 参数不存在时返回的错误匹配 ErrMissingParam,
+// 所有错误都是状态码为 400 的 *HTTPError, 可以直接交给 ErrorUseHandle 或类型化处理器返回
+func (c *Context) GetQueryInt(key string) (int, error) {
+	raw, ok := c.GetQuery(key)
+	return parseParam("query", key, raw, ok, strconv.Atoi)
+}
+
+// QueryInt64 将查询参数 key 解析为 int64, 规则与 QueryInt 相同
+func (c *Context) QueryInt64(key string, def ...int64) int64 {
+	if v, err := c.GetQueryInt64(key); err == nil {
+		return v
+	}
+	return defaultValue(def)
+}
+
+// GetQueryInt64 将查询参数 key 解析为 int64, 错误与 GetQueryInt 相同
+func (c *Context) GetQueryInt64(key string) (int64, error) {
+	raw, ok := c.GetQuery(key)
+	return parseParam("query", key, raw, ok, parseInt64)
+}
+
+// QueryBool 将查询参数 key 解析为 bool, 接受 strconv.ParseBool 支持的写法 (1、t、true、0、f、false 等), 规则与 QueryInt 相同
+func (c *Context) QueryBool(key string, def ...bool) bool {
+	if v, err := c.GetQueryBool(key); err == nil {
+		return v
+	}
+	return defaultValue(def)
+}
+
+// GetQueryBool 将查询参数 key 解析为 bool, 错误与 GetQueryInt 相同
+func (c *Context) GetQueryBool(key string) (bool, error) {
+	raw, ok := c.GetQuery(key)
+	return parseParam("query", key, raw, ok, strconv.ParseBool)
+}
+
+// QueryFloat 将查询参数 key 解析为 float64, 规则与 QueryInt 相同
+func (c *Context) QueryFloat(key string, def ...float64) float64 {
+	if v, err := c.GetQueryFloat(key); err == nil {
+		return v
+	}
+	return defaultValue(def)
+}
+
+// GetQueryFloat 将查询参数 key 解析为 float64, 错误与 GetQueryInt 相同. NaN 与 Inf 视为不合法
+func (c *Context) GetQueryFloat(key string) (float64, error) {
+	raw, ok := c.GetQuery(key)
+	return parseParam("query", key, raw, ok, parseFiniteFloat)
+}
+
+// ParamInt 将路径参数 key 解析为 int, 例如路由 /users/:id 中的 id. 参数不存在或不合法时返回 def (省略时为 0)
+func (c *Context) ParamInt(key string, def ...int) int {
+	if v, err := c.GetParamInt(key); err == nil {
+		return v
+	}
+	return defaultValue(def)
+}
+
+// GetParamInt 将路径参数 key 解析为 int, 错误与 GetQueryInt 相同
+func (c *Context) GetParamInt(key string) (int, error) {
+	raw, ok := c.Params.Get(key)
+	return parseParam("path", key, raw, ok, strconv.Atoi)
+}
+
+// ParamInt64 将路径参数 key 解析为 int64, 规则与 ParamInt 相同
+func (c *Context) ParamInt64(key string, def ...int64) int64 {
+	if v, err := c.GetParamInt64(key); err == nil {
+		return v
+	}
+	return defaultValue(def)
+}
+
+// GetParamInt64 将路径参数 key 解析为 int64, 错误与 GetQueryInt 相同
+func (c *Context) GetParamInt64(key string) (int64, error) {
+	raw, ok := c.Params.Get(key)
+	return parseParam("path", key, raw, ok, parseInt64)
+}
+
+func parseParam[T any](source, key, raw string, ok bool, parse func(string) (T, error)) (T, error) {
+	var zero T
+	if !ok {
+		return zero, NewHTTPError(http.StatusBadRequest, fmt.Errorf("%s parameter %q: %w", source, key, ErrMissingParam))
+	}
+	v, err := parse(raw)
+	if err != nil {
+		return zero, NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid %s parameter %q: %w", source, key, err))
+	}
+	return v, nil
+}
+
+// defaultValue 返回可选的默认值, 未提供时为零值
+func defaultValue[T any](def []T) T {
+	if len(def) > 0 {
+		return def[0]
+	}
+	var zero T
+	return zero
+}
+
+func parseInt64(s string) (int64, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseFiniteFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return 0, fmt.Errorf("strconv.ParseFloat: parsing %q: not a finite number", s)
+	}
+	return f, err
+}
@@ -0,0 +1,108 @@
+package touka
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryArrayAndMap(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?id=1&id=2&tag[]=a&tag[]=b&filter[status]=open&filter[tag]=go&filter[]=x&filter[a][b]=y&empty=", nil)
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+
+	if got := c.QueryArray("id"); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Fatalf("QueryArray(id) = %v", got)
+	}
+	if got := c.QueryArray("tag"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("QueryArray(tag) = %v", got)
+	}
+	if _, ok := c.GetQueryArray("missing"); ok {
+		t.Fatal("missing parameter reported as present")
+	}
+	if got := c.QueryMap("filter"); !reflect.DeepEqual(got, map[string]string{"status": "open", "tag": "go"}) {
+		t.Fatalf("QueryMap(filter) = %v", got)
+	}
+	if m, ok := c.GetQueryMap("missing"); ok || len(m) != 0 {
+		t.Fatalf("GetQueryMap(missing) = %v, %v", m, ok)
+	}
+	if v, ok := c.GetQuery("empty"); !ok || v != "" {
+		t.Fatalf("GetQuery(empty) = %q, %v", v, ok)
+	}
+	if _, ok := c.GetQuery("missing"); ok {
+		t.Fatal("GetQuery(missing) reported as present")
+	}
+}
+
+func TestPostFormArrayAndMap(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/?q=query-only", strings.NewReader("role=admin&role=dev&meta[env]=prod&meta[region]=eu"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c, _ := CreateTestContextWithRequest(httptest.NewRecorder(), req)
+
+	if got := c.PostFormArray("role"); !reflect.DeepEqual(got, []string{"admin", "dev"}) {
+		t.Fatalf("PostFormArray(role) = %v", got)
+	}
+	if got := c.PostFormMap("meta"); !reflect.DeepEqual(got, map[string]string{"env": "prod", "region": "eu"}) {
+		t.Fatalf("PostFormMap(meta) = %v", got)
+	}
+	if v, ok := c.GetPostForm("role"); !ok || v != "admin" {
+		t.Fatalf("GetPostForm(role) = %q, %v", v, ok)
+	}
+	if _, ok := c.GetPostForm("q"); ok {
+		t.Fatal("query parameters must not be visible as form fields")
+	}
+}
+
+func TestTypedQueryAndParamGetters(t *testing.T) {
+	engine := New()
+	engine.GET("/users/:id", func(c *Context) {
+		if got := c.QueryInt("page"); got != 3 {
+			t.Errorf("QueryInt(page) = %d", got)
+		}
+		if got := c.QueryInt("missing", 20); got != 20 {
+			t.Errorf("QueryInt default = %d", got)
+		}
+		if got := c.QueryInt("bad", 7); got != 7 {
+			t.Errorf("QueryInt(bad) = %d, want default", got)
+		}
+		if got := c.QueryInt64("big"); got != 1<<40 {
+			t.Errorf("QueryInt64(big) = %d", got)
+		}
+		if !c.QueryBool("verbose") || c.QueryBool("missing") || !c.QueryBool("missing", true) {
+			t.Error("QueryBool returned unexpected values")
+		}
+		if got := c.QueryFloat("ratio"); got != 0.5 {
+			t.Errorf("QueryFloat(ratio) = %v", got)
+		}
+		if got := c.QueryFloat("nan", 1); got != 1 {
+			t.Errorf("QueryFloat(nan) = %v, want default", got)
+		}
+		if got := c.ParamInt("id"); got != 42 {
+			t.Errorf("ParamInt(id) = %d", got)
+		}
+		if got := c.ParamInt64("missing", -1); got != -1 {
+			t.Errorf("ParamInt64 default = %d", got)
+		}
+
+		_, err := c.GetQueryInt("missing")
+		var httpErr *HTTPError
+		if !errors.Is(err, ErrMissingParam) || !errors.As(err, &httpErr) || httpErr.Code != http.StatusBadRequest {
+			t.Errorf("GetQueryInt(missing) = %v", err)
+		}
+		_, err = c.GetQueryInt("bad")
+		if err == nil || errors.Is(err, ErrMissingParam) || !errors.As(err, &httpErr) || httpErr.Code != http.StatusBadRequest {
+			t.Errorf("GetQueryInt(bad) = %v", err)
+		}
+		if _, err := c.GetParamInt("missing"); !errors.Is(err, ErrMissingParam) {
+			t.Errorf("GetParamInt(missing) = %v", err)
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/users/42?page=3&bad=x&big=1099511627776&verbose=true&ratio=0.5&nan=NaN", nil, nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+}
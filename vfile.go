@@ -0,0 +1,240 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VirtualFile 是不落盘的文件, 例如动态生成的报表. 内容由 Data 或 Open 提供, 二者都设置时使用 Open.
+// 通过 c.ServeVirtual 或挂载到 VirtualFS 提供时支持 Range 与条件请求
+type VirtualFile struct {
+	// Name 为文件名或路径, 用于推断 Content-Type 与在 VirtualFS 中定位
+	Name    string
+	ModTime time.Time
+	// ContentType 非空时覆盖根据扩展名推断的类型, 只对 ServeVirtual 生效
+	ContentType string
+	Data        []byte
+	// Open 每次提供文件时打开新的内容读取器, 适用于较大的内容
+	Open func() (io.ReadSeekCloser, error)
+}
+
+// open 返回文件内容的读取器与长度
+func (f *VirtualFile) open() (io.ReadSeekCloser, int64, error) {
+	if f.Open == nil {
+		return nopReadSeekCloser{bytes.NewReader(f.Data)}, int64(len(f.Data)), nil
+	}
+	rsc, err := f.Open()
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := rsc.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = rsc.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		rsc.Close()
+		return nil, 0, err
+	}
+	return rsc, size, nil
+}
+
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopReadSeekCloser) Close() error { return nil }
+
+// ServeVirtual 将虚拟文件作为响应发送, 与磁盘文件一样支持 Range、If-Modified-Since 与 If-None-Match (需自行设置 ETag)
+func (c *Context) ServeVirtual(f VirtualFile) {
+	rsc, _, err := f.open()
+	if err != nil {
+		c.AddError(err)
+		c.ErrorUseHandle(http.StatusInternalServerError, err)
+		return
+	}
+	defer rsc.Close()
+	if f.ContentType != "" {
+		c.SetHeader("Content-Type", f.ContentType)
+	}
+	http.ServeContent(c.Writer, c.Request, path.Base(f.Name), f.ModTime, rsc)
+	c.Abort()
+}
+
+// VirtualFS 是由虚拟文件组成的 http.FileSystem, 可以交给 StaticFS、SetUnMatchFS 等使用.
+// 目录由文件路径隐式构成; 找不到的路径交给 Fallback (如果设置). 并发安全, 可以在运行时替换文件
+type VirtualFS struct {
+	// Fallback 在虚拟文件中找不到路径时使用, 例如叠加在磁盘目录之上
+	Fallback http.FileSystem
+
+	mu    sync.RWMutex
+	files map[string]VirtualFile
+}
+
+// NewVirtualFS 创建包含 files 的 VirtualFS
+func NewVirtualFS(files ...VirtualFile) *VirtualFS {
+	vfs := &VirtualFS{files: make(map[string]VirtualFile, len(files))}
+	for _, f := range files {
+		vfs.Add(f)
+	}
+	return vfs
+}
+
+// Add 加入或替换文件, 路径取自 f.Name
+func (vfs *VirtualFS) Add(f VirtualFile) {
+	name := path.Clean("/" + f.Name)
+	vfs.mu.Lock()
+	if vfs.files == nil {
+		vfs.files = make(map[string]VirtualFile)
+	}
+	vfs.files[name] = f
+	vfs.mu.Unlock()
+}
+
+// Remove 移除文件
+func (vfs *VirtualFS) Remove(name string) {
+	vfs.mu.Lock()
+	delete(vfs.files, path.Clean("/"+name))
+	vfs.mu.Unlock()
+}
+
+// Open 实现 http.FileSystem
+func (vfs *VirtualFS) Open(name string) (http.File, error) {
+	name = path.Clean("/" + name)
+	vfs.mu.RLock()
+	f, ok := vfs.files[name]
+	var children []fs.FileInfo
+	var pending map[string]VirtualFile
+	if !ok {
+		children, pending = vfs.childrenLocked(name)
+	}
+	vfs.mu.RUnlock()
+
+	if ok {
+		rsc, size, err := f.open()
+		if err != nil {
+			return nil, err
+		}
+		return &virtualHTTPFile{ReadSeekCloser: rsc, info: virtualFileInfo{name: path.Base(name), size: size, modTime: f.ModTime}}, nil
+	}
+	if children != nil || name == "/" {
+		if vfs.Fallback != nil {
+			// 目录同时存在于 Fallback 时交给 Fallback, 以便列出其中的文件
+			if dir, err := vfs.Fallback.Open(name); err == nil {
+				return dir, nil
+			}
+		}
+		// 由 Open 提供内容的文件在释放锁后打开以获得长度, 打开失败时长度保持为 0
+		for i, info := range children {
+			if f, ok := pending[info.Name()]; ok {
+				if rsc, size, err := f.open(); err == nil {
+					rsc.Close()
+					children[i] = virtualFileInfo{name: info.Name(), size: size, modTime: info.ModTime()}
+				}
+			}
+		}
+		return &virtualHTTPDir{info: virtualFileInfo{name: path.Base(name), dir: true}, children: children}, nil
+	}
+	if vfs.Fallback != nil {
+		return vfs.Fallback.Open(name)
+	}
+	return nil, fs.ErrNotExist
+}
+
+// childrenLocked 返回目录 dir 下的直接子项, dir 不存在时返回 nil.
+// 由 Open 提供内容的文件按名称记录在 pending 中, 其长度需要打开后才能得知
+func (vfs *VirtualFS) childrenLocked(dir string) (children []fs.FileInfo, pending map[string]VirtualFile) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	seen := map[string]bool{}
+	for name, f := range vfs.files {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		child, sub, isDir := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		info := virtualFileInfo{name: child, modTime: f.ModTime, dir: isDir && sub != ""}
+		if !info.dir {
+			if f.Open != nil {
+				if pending == nil {
+					pending = make(map[string]VirtualFile)
+				}
+				pending[child] = f
+			} else {
+				info.size = int64(len(f.Data))
+			}
+		}
+		children = append(children, info)
+	}
+	slices.SortFunc(children, func(a, b fs.FileInfo) int { return strings.Compare(a.Name(), b.Name()) })
+	return children, pending
+}
+
+type virtualFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i virtualFileInfo) Name() string       { return i.name }
+func (i virtualFileInfo) Size() int64        { return i.size }
+func (i virtualFileInfo) ModTime() time.Time { return i.modTime }
+func (i virtualFileInfo) IsDir() bool        { return i.dir }
+func (i virtualFileInfo) Sys() any           { return nil }
+func (i virtualFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+type virtualHTTPFile struct {
+	io.ReadSeekCloser
+	info virtualFileInfo
+}
+
+func (f *virtualHTTPFile) Readdir(int) ([]fs.FileInfo, error) {
+	return nil, errors.New("not a directory")
+}
+
+func (f *virtualHTTPFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+type virtualHTTPDir struct {
+	info     virtualFileInfo
+	children []fs.FileInfo
+	offset   int
+}
+
+func (d *virtualHTTPDir) Read([]byte) (int, error)       { return 0, errors.New("is a directory") }
+func (d *virtualHTTPDir) Seek(int64, int) (int64, error) { return 0, errors.New("is a directory") }
+func (d *virtualHTTPDir) Close() error                   { return nil }
+func (d *virtualHTTPDir) Stat() (fs.FileInfo, error)     { return d.info, nil }
+
+func (d *virtualHTTPDir) Readdir(count int) ([]fs.FileInfo, error) {
+	rest := d.children[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.children)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n := min(count, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
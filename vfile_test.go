@@ -0,0 +1,79 @@
+package touka
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeVirtual(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	engine := New()
+	engine.GET("/report", func(c *Context) {
+		c.ServeVirtual(VirtualFile{Name: "report.csv", ModTime: modTime, Data: []byte("a,b\n1,2\n")})
+	})
+
+	w := PerformRequest(engine, http.MethodGet, "/report", nil, http.Header{"Range": {"bytes=4-6"}})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "1,2" {
+		t.Fatalf("expected partial content, got %d %q", w.Code, w.Body.String())
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("unexpected Content-Type %q", w.Header().Get("Content-Type"))
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/report", nil, http.Header{"If-Modified-Since": {modTime.Format(http.TimeFormat)}})
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", w.Code)
+	}
+}
+
+type seekCloser struct{ *strings.Reader }
+
+func (seekCloser) Close() error { return nil }
+
+func TestVirtualFS(t *testing.T) {
+	vfs := NewVirtualFS(
+		VirtualFile{Name: "maps/world.json", Data: []byte(`{"v":1}`)},
+		VirtualFile{Name: "big.txt", Open: func() (io.ReadSeekCloser, error) {
+			return seekCloser{strings.NewReader("0123456789")}, nil
+		}},
+	)
+	engine := New()
+	engine.StaticFS("/v", vfs)
+
+	w := PerformRequest(engine, http.MethodGet, "/v/maps/world.json", nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != `{"v":1}` {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	w = PerformRequest(engine, http.MethodGet, "/v/big.txt", nil, http.Header{"Range": {"bytes=-3"}})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "789" {
+		t.Fatalf("expected range over factory content, got %d %q", w.Code, w.Body.String())
+	}
+	w = PerformRequest(engine, http.MethodGet, "/v/maps/", nil, nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "world.json") {
+		t.Fatalf("expected directory listing, got %d %q", w.Code, w.Body.String())
+	}
+
+	root, err := vfs.Open("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, err := root.Readdir(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := map[string]int64{}
+	for _, info := range infos {
+		sizes[info.Name()] = info.Size()
+	}
+	if sizes["big.txt"] != 10 {
+		t.Fatalf("expected size of Open-backed file in listing, got %v", sizes)
+	}
+
+	vfs.Remove("maps/world.json")
+	if w := PerformRequest(engine, http.MethodGet, "/v/maps/world.json", nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected removed file to be gone, got %d", w.Code)
+	}
+}
@@ -0,0 +1,389 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+var (
+	ErrWebhookSignatureMissing = errors.New("webhook: signature missing")
+	ErrWebhookSignatureInvalid = errors.New("webhook: signature invalid")
+	ErrWebhookTimestampExpired = errors.New("webhook: timestamp outside tolerance window")
+)
+
+// DefaultWebhookTolerance 为基于时间戳的签名方案默认允许的时钟偏差
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// webhookPayloadKey 是原始请求体在 Context.Keys 中的键
+const webhookPayloadKey = "touka.webhook.payload"
+
+// WebhookVerifier 校验 webhook 请求的签名, body 为完整的原始请求体
+type WebhookVerifier interface {
+	Verify(r *http.Request, body []byte) error
+}
+
+// WebhookVerifierFunc 是 WebhookVerifier 的函数适配器
+type WebhookVerifierFunc func(r *http.Request, body []byte) error
+
+func (f WebhookVerifierFunc) Verify(r *http.Request, body []byte) error {
+	return f(r, body)
+}
+
+// GitHubWebhookVerifier 校验 GitHub 的 X-Hub-Signature-256 签名 (sha256=<hex>)
+func GitHubWebhookVerifier(secret string) WebhookVerifier {
+	return WebhookVerifierFunc(func(r *http.Request, body []byte) error {
+		sig := r.Header.Get("X-Hub-Signature-256")
+		if sig == "" {
+			return ErrWebhookSignatureMissing
+		}
+		hexSig, ok := strings.CutPrefix(sig, "sha256=")
+		if !ok || !webhookHMACEqual(secret, body, hexSig) {
+			return ErrWebhookSignatureInvalid
+		}
+		return nil
+	})
+}
+
+// StripeWebhookVerifier 校验 Stripe 的 Stripe-Signature 签名 (t=<unix>,v1=<hex>), 并检查时间戳窗口.
+// tolerance <= 0 时使用 DefaultWebhookTolerance
+func StripeWebhookVerifier(secret string, tolerance time.Duration) WebhookVerifier {
+	if tolerance <= 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	return WebhookVerifierFunc(func(r *http.Request, body []byte) error {
+		header := r.Header.Get("Stripe-Signature")
+		if header == "" {
+			return ErrWebhookSignatureMissing
+		}
+		var timestamp string
+		var signatures []string
+		for _, part := range strings.Split(header, ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch k {
+			case "t":
+				timestamp = v
+			case "v1":
+				signatures = append(signatures, v)
+			}
+		}
+		if timestamp == "" || len(signatures) == 0 {
+			return ErrWebhookSignatureMissing
+		}
+		if err := checkWebhookTimestamp(timestamp, tolerance); err != nil {
+			return err
+		}
+		signed := make([]byte, 0, len(timestamp)+1+len(body))
+		signed = append(append(append(signed, timestamp...), '.'), body...)
+		for _, sig := range signatures {
+			if webhookHMACEqual(secret, signed, sig) {
+				return nil
+			}
+		}
+		return ErrWebhookSignatureInvalid
+	})
+}
+
+// SlackWebhookVerifier 校验 Slack 的 X-Slack-Signature 签名 (v0=<hex>), 并检查 X-Slack-Request-Timestamp 窗口.
+// tolerance <= 0 时使用 DefaultWebhookTolerance
+func SlackWebhookVerifier(secret string, tolerance time.Duration) WebhookVerifier {
+	if tolerance <= 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	return WebhookVerifierFunc(func(r *http.Request, body []byte) error {
+		sig := r.Header.Get("X-Slack-Signature")
+		timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+		if sig == "" || timestamp == "" {
+			return ErrWebhookSignatureMissing
+		}
+		if err := checkWebhookTimestamp(timestamp, tolerance); err != nil {
+			return err
+		}
+		hexSig, ok := strings.CutPrefix(sig, "v0=")
+		if !ok {
+			return ErrWebhookSignatureInvalid
+		}
+		signed := make([]byte, 0, len(timestamp)+4+len(body))
+		signed = append(append(append(signed, "v0:"+timestamp...), ':'), body...)
+		if !webhookHMACEqual(secret, signed, hexSig) {
+			return ErrWebhookSignatureInvalid
+		}
+		return nil
+	})
+}
+
+func webhookHMACEqual(secret string, payload []byte, hexSig string) bool {
+	expected, err := hex.DecodeString(hexSig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func checkWebhookTimestamp(ts string, tolerance time.Duration) error {
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrWebhookSignatureInvalid
+	}
+	diff := time.Since(time.Unix(sec, 0))
+	if diff < -tolerance || diff > tolerance {
+		return ErrWebhookTimestampExpired
+	}
+	return nil
+}
+
+// WebhookDeliveryStore 记录已处理的投递 ID, 用于丢弃重复投递与重放
+type WebhookDeliveryStore interface {
+	// MarkDelivered 记录投递 ID, 若该 ID 在 ttl 内首次出现则返回 true
+	MarkDelivered(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	// Release 删除投递 ID 的记录, 处理失败时调用, 以便提供方重试时再次分发
+	Release(ctx context.Context, id string) error
+}
+
+// MemoryWebhookDeliveryStore 是进程内的 WebhookDeliveryStore 实现
+type MemoryWebhookDeliveryStore struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	nextSweep time.Time
+}
+
+// NewMemoryWebhookDeliveryStore 创建一个进程内投递记录存储
+func NewMemoryWebhookDeliveryStore() *MemoryWebhookDeliveryStore {
+	return &MemoryWebhookDeliveryStore{entries: make(map[string]time.Time)}
+}
+
+func (s *MemoryWebhookDeliveryStore) MarkDelivered(_ context.Context, id string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expire, ok := s.entries[id]; ok && now.Before(expire) {
+		return false, nil
+	}
+	// 定期清理过期记录, 避免每个请求都遍历全部投递记录
+	if now.After(s.nextSweep) {
+		for k, expire := range s.entries {
+			if !now.Before(expire) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(time.Minute)
+	}
+	s.entries[id] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemoryWebhookDeliveryStore) Release(_ context.Context, id string) error {
+	s.mu.Lock()
+	delete(s.entries, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// WebhookConfig 配置 webhook 分发器
+type WebhookConfig struct {
+	// Verifier 校验请求签名, 为 nil 时不校验
+	Verifier WebhookVerifier
+	// EventType 从请求中提取事件类型
+	EventType func(c *Context, body []byte) string
+	// DeliveryID 从请求中提取投递 ID, 与 Deliveries 配合用于去重
+	DeliveryID func(c *Context, body []byte) string
+	// Deliveries 记录已处理的投递, 为 nil 时不去重
+	Deliveries WebhookDeliveryStore
+	// DeliveryTTL 为投递记录的保留时间, 默认 24 小时
+	DeliveryTTL time.Duration
+}
+
+// GitHubWebhookConfig 返回 GitHub webhook 的默认配置 (X-GitHub-Event / X-GitHub-Delivery)
+func GitHubWebhookConfig(secret string) WebhookConfig {
+	return WebhookConfig{
+		Verifier:   GitHubWebhookVerifier(secret),
+		EventType:  func(c *Context, _ []byte) string { return c.GetReqHeader("X-GitHub-Event") },
+		DeliveryID: func(c *Context, _ []byte) string { return c.GetReqHeader("X-GitHub-Delivery") },
+	}
+}
+
+// StripeWebhookConfig 返回 Stripe webhook 的默认配置, 事件类型与 ID 取自请求体的 type / id 字段
+func StripeWebhookConfig(secret string) WebhookConfig {
+	return WebhookConfig{
+		Verifier:   StripeWebhookVerifier(secret, 0),
+		EventType:  webhookJSONField("type"),
+		DeliveryID: webhookJSONField("id"),
+	}
+}
+
+// SlackWebhookConfig 返回 Slack Events API 的默认配置, 事件类型取自 event.type (无则取 type)
+func SlackWebhookConfig(secret string) WebhookConfig {
+	return WebhookConfig{
+		Verifier: SlackWebhookVerifier(secret, 0),
+		EventType: func(_ *Context, body []byte) string {
+			var payload struct {
+				Type  string `json:"type"`
+				Event struct {
+					Type string `json:"type"`
+				} `json:"event"`
+			}
+			_ = json.Unmarshal(body, &payload)
+			if payload.Event.Type != "" {
+				return payload.Event.Type
+			}
+			return payload.Type
+		},
+		DeliveryID: webhookJSONField("event_id"),
+	}
+}
+
+func webhookJSONField(name string) func(*Context, []byte) string {
+	return func(_ *Context, body []byte) string {
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return ""
+		}
+		s, _ := payload[name].(string)
+		return s
+	}
+}
+
+// WebhookDispatcher 校验 webhook 请求并按事件类型分发到对应的处理器
+type WebhookDispatcher struct {
+	config   WebhookConfig
+	handlers map[string]HandlersChain
+	fallback HandlersChain
+}
+
+// NewWebhookDispatcher 创建一个 webhook 分发器
+func NewWebhookDispatcher(config WebhookConfig) *WebhookDispatcher {
+	if config.DeliveryTTL <= 0 {
+		config.DeliveryTTL = 24 * time.Hour
+	}
+	return &WebhookDispatcher{
+		config:   config,
+		handlers: make(map[string]HandlersChain),
+	}
+}
+
+// On 为事件类型注册处理器
+func (d *WebhookDispatcher) On(event string, handlers ...HandlerFunc) *WebhookDispatcher {
+	d.handlers[event] = append(d.handlers[event], handlers...)
+	return d
+}
+
+// OnUnknown 为未注册的事件类型注册处理器, 未设置时未知事件直接返回 204
+func (d *WebhookDispatcher) OnUnknown(handlers ...HandlerFunc) *WebhookDispatcher {
+	d.fallback = append(d.fallback, handlers...)
+	return d
+}
+
+// Handler 返回可挂载到路由上的处理器.
+// 签名校验失败返回 401; 重复投递返回 200 且不再分发.
+// 处理器 panic 或响应 5xx 时释放投递记录, 提供方重试时会再次分发.
+// 处理器中可以通过 WebhookPayload 获取原始请求体, 或再次读取 c.Request.Body
+func (d *WebhookDispatcher) Handler() HandlerFunc {
+	return func(c *Context) {
+		body, err := c.GetReqBodyFull()
+		if err != nil {
+			if errors.Is(err, ErrBodyTooLarge) {
+				c.ErrorUseHandle(http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			c.ErrorUseHandle(http.StatusBadRequest, err)
+			return
+		}
+		// 重置请求体, 使后续处理器可以正常绑定
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Set(webhookPayloadKey, body)
+
+		if d.config.Verifier != nil {
+			if err := d.config.Verifier.Verify(c.Request, body); err != nil {
+				c.AddClientError(err)
+				c.ErrorUseHandle(http.StatusUnauthorized, err)
+				return
+			}
+		}
+
+		id := ""
+		if d.config.Deliveries != nil && d.config.DeliveryID != nil {
+			if id = d.config.DeliveryID(c, body); id != "" {
+				first, err := d.config.Deliveries.MarkDelivered(c.Context(), id, d.config.DeliveryTTL)
+				if err != nil {
+					c.ErrorUseHandle(http.StatusInternalServerError, fmt.Errorf("webhook: delivery store: %w", err))
+					return
+				}
+				if !first {
+					c.Status(http.StatusOK)
+					c.Abort()
+					return
+				}
+			}
+		}
+
+		event := ""
+		if d.config.EventType != nil {
+			event = d.config.EventType(c, body)
+		}
+		chain, ok := d.handlers[event]
+		if !ok {
+			chain = d.fallback
+		}
+		if len(chain) == 0 {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		if id != "" {
+			delivered := false
+			defer func() {
+				if !delivered {
+					// 请求可能已被客户端取消, 释放记录不应受其影响
+					if err := d.config.Deliveries.Release(context.WithoutCancel(c.Context()), id); err != nil {
+						c.AddError(fmt.Errorf("webhook: delivery store: %w", err))
+					}
+				}
+			}()
+			runWebhookChain(c, chain)
+			delivered = c.Writer.Status() < http.StatusInternalServerError
+			return
+		}
+		runWebhookChain(c, chain)
+	}
+}
+
+// runWebhookChain 以独立的处理链执行 chain: 其中的 c.Next() 只推进该链, 不会执行外层路由的处理器.
+// chain 中止时外层处理链同样中止
+func runWebhookChain(c *Context, chain HandlersChain) {
+	handlers, index := c.handlers, c.index
+	defer func() {
+		aborted := c.IsAborted()
+		c.handlers, c.index = handlers, index
+		if aborted {
+			c.Abort()
+		}
+	}()
+	c.handlers, c.index = chain, -1
+	c.Next()
+}
+
+// WebhookPayload 返回 webhook 分发器已读取的原始请求体
+func WebhookPayload(c *Context) []byte {
+	if v, ok := c.Get(webhookPayloadKey); ok {
+		body, _ := v.([]byte)
+		return body
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package touka
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testWebhookSign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookDispatcherGitHub(t *testing.T) {
+	cfg := GitHubWebhookConfig("s3cret")
+	cfg.Deliveries = NewMemoryWebhookDeliveryStore()
+
+	var pushes int
+	dispatcher := NewWebhookDispatcher(cfg).On("push", func(c *Context) {
+		var payload struct {
+			Ref string `json:"ref"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			t.Errorf("body should be rewound for binding: %v", err)
+		}
+		if string(WebhookPayload(c)) == "" || payload.Ref != "main" {
+			t.Errorf("unexpected payload %q", payload.Ref)
+		}
+		pushes++
+		c.Status(http.StatusAccepted)
+	})
+
+	engine := New()
+	engine.POST("/hooks/github", dispatcher.Handler())
+
+	body := `{"ref":"main"}`
+	headers := http.Header{
+		"Content-Type":        []string{"application/json"},
+		"X-Github-Event":      []string{"push"},
+		"X-Github-Delivery":   []string{"d-1"},
+		"X-Hub-Signature-256": []string{"sha256=" + testWebhookSign("s3cret", body)},
+	}
+	rr := PerformRequest(engine, http.MethodPost, "/hooks/github", strings.NewReader(body), headers)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+
+	// 重复投递不再分发
+	rr = PerformRequest(engine, http.MethodPost, "/hooks/github", strings.NewReader(body), headers)
+	if rr.Code != http.StatusOK || pushes != 1 {
+		t.Fatalf("expected duplicate delivery to be skipped, got %d pushes=%d", rr.Code, pushes)
+	}
+
+	headers.Set("X-Hub-Signature-256", "sha256="+testWebhookSign("wrong", body))
+	headers.Set("X-Github-Delivery", "d-2")
+	rr = PerformRequest(engine, http.MethodPost, "/hooks/github", strings.NewReader(body), headers)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for bad signature, got %d", rr.Code)
+	}
+}
+
+func TestStripeAndSlackVerifiers(t *testing.T) {
+	body := []byte(`{"id":"evt_1","type":"charge.succeeded"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	stripe := StripeWebhookVerifier("whsec", 0)
+	req, _ := http.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Stripe-Signature", "t="+now+",v1="+testWebhookSign("whsec", now+"."+string(body)))
+	if err := stripe.Verify(req, body); err != nil {
+		t.Fatalf("stripe: %v", err)
+	}
+	req.Header.Set("Stripe-Signature", "t="+stale+",v1="+testWebhookSign("whsec", stale+"."+string(body)))
+	if err := stripe.Verify(req, body); err != ErrWebhookTimestampExpired {
+		t.Fatalf("stripe: expected expired timestamp, got %v", err)
+	}
+
+	slack := SlackWebhookVerifier("slk", 0)
+	req.Header.Set("X-Slack-Request-Timestamp", now)
+	req.Header.Set("X-Slack-Signature", "v0="+testWebhookSign("slk", "v0:"+now+":"+string(body)))
+	if err := slack.Verify(req, body); err != nil {
+		t.Fatalf("slack: %v", err)
+	}
+	if err := slack.Verify(req, []byte("tampered")); err != ErrWebhookSignatureInvalid {
+		t.Fatalf("slack: expected invalid signature, got %v", err)
+	}
+
+	if got := StripeWebhookConfig("x").EventType(nil, body); got != "charge.succeeded" {
+		t.Fatalf("stripe event type: got %q", got)
+	}
+}
+
+func TestWebhookDispatcherReleasesFailedDelivery(t *testing.T) {
+	cfg := GitHubWebhookConfig("s3cret")
+	cfg.Deliveries = NewMemoryWebhookDeliveryStore()
+
+	attempts := 0
+	dispatcher := NewWebhookDispatcher(cfg).On("push", func(c *Context) {
+		attempts++
+		if attempts == 1 {
+			c.Status(http.StatusServiceUnavailable)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine := New()
+	engine.POST("/hooks/github", dispatcher.Handler())
+
+	body := `{"ref":"main"}`
+	headers := http.Header{
+		"X-Github-Event":      []string{"push"},
+		"X-Github-Delivery":   []string{"d-1"},
+		"X-Hub-Signature-256": []string{"sha256=" + testWebhookSign("s3cret", body)},
+	}
+	for i, want := range []int{http.StatusServiceUnavailable, http.StatusNoContent, http.StatusOK} {
+		rr := PerformRequest(engine, http.MethodPost, "/hooks/github", strings.NewReader(body), headers)
+		if rr.Code != want {
+			t.Fatalf("delivery %d: expected %d, got %d", i, want, rr.Code)
+		}
+	}
+	if attempts != 2 {
+		t.Fatalf("expected the retry after a 5xx to be dispatched once more, got %d attempts", attempts)
+	}
+}
+
+func TestWebhookDispatcherChainIsolated(t *testing.T) {
+	var order []string
+	dispatcher := NewWebhookDispatcher(WebhookConfig{
+		EventType: func(*Context, []byte) string { return "ping" },
+	}).On("ping",
+		func(c *Context) {
+			order = append(order, "mw")
+			c.Next()
+			order = append(order, "mw-after")
+		},
+		func(c *Context) {
+			order = append(order, "handler")
+			c.Status(http.StatusNoContent)
+		},
+	)
+	engine := New()
+	engine.POST("/hooks", dispatcher.Handler(), func(c *Context) {
+		order = append(order, "route")
+	})
+
+	PerformRequest(engine, http.MethodPost, "/hooks", strings.NewReader("{}"), nil)
+	if got := strings.Join(order, ","); got != "mw,handler,mw-after,route" {
+		t.Fatalf("unexpected handler order %q", got)
+	}
+}
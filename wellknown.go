@@ -0,0 +1,114 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// WellKnown 在 /.well-known/<name> 下注册 GET 处理器 (RFC 8615), name 可以包含路径参数
+func (engine *Engine) WellKnown(name string, handlers ...HandlerFunc) {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		panic("touka: well-known name must not be empty")
+	}
+	engine.GET("/.well-known/"+name, handlers...)
+}
+
+// wellKnownJSON 注册返回固定 JSON 的 well-known 端点, 内容在注册时编码, 编码失败时 panic
+func (engine *Engine) wellKnownJSON(name string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("touka: encode /.well-known/" + name + ": " + err.Error())
+	}
+	engine.WellKnown(name, func(c *Context) {
+		c.SetHeader("Content-Type", "application/json")
+		c.SetHeader("Cache-Control", "public, max-age=3600")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.Write(data)
+	})
+}
+
+// SecurityTxt 描述 security.txt (RFC 9116) 的内容
+type SecurityTxt struct {
+	Contact            []string  // 必填, 例如 mailto:security@example.com 或 https 地址
+	Expires            time.Time // 必填, 建议不超过一年
+	Encryption         []string
+	Acknowledgments    []string
+	PreferredLanguages []string
+	Canonical          []string
+	Policy             []string
+	Hiring             []string
+}
+
+// String 生成 security.txt 文本
+func (s SecurityTxt) String() string {
+	var b strings.Builder
+	field := func(name string, values []string) {
+		for _, v := range values {
+			b.WriteString(name + ": " + v + "\n")
+		}
+	}
+	field("Contact", s.Contact)
+	b.WriteString("Expires: " + s.Expires.UTC().Format(time.RFC3339) + "\n")
+	field("Encryption", s.Encryption)
+	field("Acknowledgments", s.Acknowledgments)
+	if len(s.PreferredLanguages) > 0 {
+		b.WriteString("Preferred-Languages: " + strings.Join(s.PreferredLanguages, ", ") + "\n")
+	}
+	field("Canonical", s.Canonical)
+	field("Policy", s.Policy)
+	field("Hiring", s.Hiring)
+	return b.String()
+}
+
+// SecurityTxt 注册 /.well-known/security.txt. Contact 为空或未设置 Expires 时 panic
+func (engine *Engine) SecurityTxt(s SecurityTxt) {
+	if len(s.Contact) == 0 || s.Expires.IsZero() {
+		panic("touka: security.txt requires Contact and Expires")
+	}
+	content := s.String()
+	engine.WellKnown("security.txt", func(c *Context) {
+		c.SetHeader("Content-Type", "text/plain; charset=utf-8")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.Write([]byte(content))
+	})
+}
+
+// ChangePasswordURL 注册 /.well-known/change-password, 将密码管理器引导到修改密码页面
+func (engine *Engine) ChangePasswordURL(location string) {
+	engine.WellKnown("change-password", func(c *Context) {
+		c.Redirect(http.StatusFound, location)
+	})
+}
+
+// AssetLinkStatement 是 Android Digital Asset Links 中的一条声明
+type AssetLinkStatement struct {
+	Relation []string        `json:"relation"`
+	Target   AssetLinkTarget `json:"target"`
+}
+
+// AssetLinkTarget 是声明的目标应用或站点
+type AssetLinkTarget struct {
+	Namespace              string   `json:"namespace"` // android_app 或 web
+	PackageName            string   `json:"package_name,omitempty"`
+	SHA256CertFingerprints []string `json:"sha256_cert_fingerprints,omitempty"`
+	Site                   string   `json:"site,omitempty"`
+}
+
+// AssetLinks 注册 /.well-known/assetlinks.json
+func (engine *Engine) AssetLinks(statements []AssetLinkStatement) {
+	engine.wellKnownJSON("assetlinks.json", statements)
+}
+
+// AppleAppSiteAssociation 注册 /.well-known/apple-app-site-association.
+// v 为任意可编码为 JSON 的结构 (applinks、webcredentials 等), 以 application/json 返回且不做重定向, 符合 Apple 的要求
+func (engine *Engine) AppleAppSiteAssociation(v any) {
+	engine.wellKnownJSON("apple-app-site-association", v)
+}
@@ -0,0 +1,60 @@
+package touka
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWellKnownEndpoints(t *testing.T) {
+	engine := New()
+	engine.SecurityTxt(SecurityTxt{
+		Contact:            []string{"mailto:security@example.com"},
+		Expires:            time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		PreferredLanguages: []string{"en", "zh"},
+	})
+	engine.ChangePasswordURL("/account/password")
+	engine.AssetLinks([]AssetLinkStatement{{
+		Relation: []string{"delegate_permission/common.handle_all_urls"},
+		Target:   AssetLinkTarget{Namespace: "android_app", PackageName: "com.example", SHA256CertFingerprints: []string{"AB:CD"}},
+	}})
+	engine.AppleAppSiteAssociation(map[string]any{"applinks": map[string]any{"details": []any{}}})
+	engine.WellKnown("openid-configuration", func(c *Context) { c.String(http.StatusOK, "oidc") })
+
+	w := PerformRequest(engine, http.MethodGet, "/.well-known/security.txt", nil, nil)
+	want := "Contact: mailto:security@example.com\nExpires: 2027-01-01T00:00:00Z\nPreferred-Languages: en, zh\n"
+	if w.Code != http.StatusOK || w.Body.String() != want || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("unexpected security.txt %d %q", w.Code, w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/.well-known/change-password", nil, nil)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/account/password" {
+		t.Fatalf("unexpected change-password response %d %v", w.Code, w.Header())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/.well-known/assetlinks.json", nil, nil)
+	if w.Header().Get("Content-Type") != "application/json" ||
+		w.Body.String() != `[{"relation":["delegate_permission/common.handle_all_urls"],"target":{"namespace":"android_app","package_name":"com.example","sha256_cert_fingerprints":["AB:CD"]}}]` {
+		t.Fatalf("unexpected assetlinks.json %q", w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/.well-known/apple-app-site-association", nil, nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" || !strings.Contains(w.Body.String(), "applinks") {
+		t.Fatalf("unexpected apple-app-site-association %d %q", w.Code, w.Body.String())
+	}
+
+	w = PerformRequest(engine, http.MethodGet, "/.well-known/openid-configuration", nil, nil)
+	if w.Body.String() != "oidc" {
+		t.Fatalf("unexpected custom well-known response %q", w.Body.String())
+	}
+}
+
+func TestSecurityTxtRequiresContactAndExpires(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for incomplete security.txt")
+		}
+	}()
+	New().SecurityTxt(SecurityTxt{Contact: []string{"mailto:a@example.com"}})
+}
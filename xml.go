@@ -0,0 +1,62 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+// Copyright 2026 WJQSERVER. All rights reserved.
+// All rights reserved by WJQSERVER, related rights can be exercised by the infinite-iroha organization.
+package touka
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// XML 向响应写入 XML 数据, Content-Type 为 application/xml. 实现 xml.Marshaler 的类型按其自定义方式编码.
+// 与 JSONBuf 一样先编码到缓冲区, 编码失败时通过 ErrorUseHandle 返回 500
+func (c *Context) XML(code int, obj any) {
+	var buf bytes.Buffer
+	bw := c.budgetBuffer(&buf)
+	defer bw.release()
+	if err := xml.NewEncoder(bw).Encode(obj); err != nil {
+		c.renderError(fmt.Errorf("failed to marshal XML: %w", err))
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	c.Writer.WriteHeader(code)
+	c.writeResponseBody(buf.Bytes(), "failed to write XML response")
+}
+
+// ShouldBindXML 将 XML 格式的请求体绑定到对象, 请求体大小受 MaxRequestBodySize 限制
+func (c *Context) ShouldBindXML(obj any) error {
+	if err := applyDefaults(obj); err != nil {
+		return err
+	}
+	body := c.bindRequestBody()
+	if body == nil {
+		return errors.New("request body is empty")
+	}
+	if err := xml.NewDecoder(body).Decode(obj); err != nil {
+		return fmt.Errorf("XML binding error: %w", err)
+	}
+	return nil
+}
+
+// MarshalXML 使 H 可以用于 c.XML: 每个键编码为一个子元素, 键按字典序排列.
+// 作为顶层对象时根元素为 <map>
+func (h H) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if start.Name.Local == "H" {
+		start.Name = xml.Name{Local: "map"}
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(h)) {
+		if err := e.EncodeElement(h[key], xml.StartElement{Name: xml.Name{Local: key}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
@@ -0,0 +1,80 @@
+package touka
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type xmlUser struct {
+	XMLName xml.Name `xml:"user"`
+	ID      int      `xml:"id,attr"`
+	Name    string   `xml:"name"`
+	Role    string   `xml:"role" default:"member"`
+}
+
+// xmlUpper 自定义 XML 编码, 输出大写的文本
+type xmlUpper string
+
+func (u xmlUpper) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(strings.ToUpper(string(u)), start)
+}
+
+func TestXMLRender(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  any
+		want string
+	}{
+		{"struct", xmlUser{ID: 1, Name: "touka"}, `<user id="1"><name>touka</name><role></role></user>`},
+		{"marshaler", struct {
+			XMLName xml.Name `xml:"greeting"`
+			Text    xmlUpper `xml:"text"`
+		}{Text: "hi"}, `<greeting><text>HI</text></greeting>`},
+		{"H", H{"b": 2, "a": "x"}, `<map><a>x</a><b>2</b></map>`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := CreateTestContext(w)
+		c.XML(http.StatusCreated, tt.obj)
+		if w.Code != http.StatusCreated || w.Header().Get("Content-Type") != "application/xml; charset=utf-8" || w.Body.String() != tt.want {
+			t.Errorf("%s: got %d %q %q, want %q", tt.name, w.Code, w.Header().Get("Content-Type"), w.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestXMLRenderError(t *testing.T) {
+	engine := New()
+	engine.GET("/", func(c *Context) {
+		c.XML(http.StatusOK, map[string]int{"a": 1})
+	})
+	w := PerformRequest(engine, http.MethodGet, "/", nil, nil)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 for unsupported type, got %d", w.Code)
+	}
+}
+
+func TestShouldBindXML(t *testing.T) {
+	engine := New()
+	engine.POST("/", func(c *Context) {
+		var u xmlUser
+		if err := c.ShouldBind(&u); err != nil {
+			c.String(http.StatusBadRequest, "%v", err)
+			return
+		}
+		c.String(http.StatusOK, "%d %s %s", u.ID, u.Name, u.Role)
+	})
+
+	for _, ct := range []string{"application/xml", "text/xml; charset=utf-8"} {
+		w := PerformRequest(engine, http.MethodPost, "/", strings.NewReader(`<user id="7"><name>iroha</name></user>`), http.Header{"Content-Type": {ct}})
+		if w.Code != http.StatusOK || w.Body.String() != "7 iroha member" {
+			t.Errorf("%s: got %d %q", ct, w.Code, w.Body.String())
+		}
+	}
+	w := PerformRequest(engine, http.MethodPost, "/", strings.NewReader(`<user>`), http.Header{"Content-Type": {"application/xml"}})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "XML binding error") {
+		t.Errorf("malformed XML: got %d %q", w.Code, w.Body.String())
+	}
+}